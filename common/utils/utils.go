@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"strconv"
 	"strings"
+	"time"
 	"unicode"
 )
 
@@ -85,6 +86,34 @@ func ParseIntID(idStr string) (int, error) {
 	return strconv.Atoi(idStr)
 }
 
+// ParseDate accepts RFC 3339 or a plain YYYY-MM-DD date; with endOfDay a
+// plain date covers the whole day
+func ParseDate(v string, endOfDay bool) (time.Time, error) {
+	if t, err := time.Parse(time.RFC3339, v); err == nil {
+		return t, nil
+	}
+	t, err := time.ParseInLocation("2006-01-02", v, time.Local)
+	if err != nil {
+		return time.Time{}, err
+	}
+	if endOfDay {
+		t = t.Add(24*time.Hour - time.Nanosecond)
+	}
+	return t, nil
+}
+
+// AbsoluteURL resolves a stored URL such as /uploads/a.jpg against base;
+// absolute and empty URLs are returned as they are
+func AbsoluteURL(base, u string) string {
+	if u == "" || strings.Contains(u, "://") {
+		return u
+	}
+	if strings.HasPrefix(u, "//") {
+		return "https:" + u
+	}
+	return strings.TrimRight(base, "/") + "/" + strings.TrimLeft(u, "/")
+}
+
 // ValidatePageAndLimit validates and normalizes pagination parameters
 func ValidatePageAndLimit(page, limit int) (int, int) {
 	if page <= 0 {
@@ -1,7 +1,12 @@
 package config
 
 import (
+	"fmt"
 	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
 
 	"github.com/spf13/viper"
 )
@@ -11,6 +16,11 @@ type Config struct {
 	Database  DatabaseConfig  `mapstructure:"database"`
 	Redis     RedisConfig     `mapstructure:"redis"`
 	JWT       JWTConfig       `mapstructure:"jwt"`
+	Auth      AuthConfig      `mapstructure:"auth"`
+	Mail      MailConfig      `mapstructure:"mail"`
+	Scheduler SchedulerConfig `mapstructure:"scheduler"`
+	Media     MediaConfig     `mapstructure:"media"`
+	Storage   StorageConfig   `mapstructure:"storage"`
 	App       AppConfig       `mapstructure:"app"`
 	Analytics AnalyticsConfig `mapstructure:"analytics"`
 }
@@ -18,6 +28,16 @@ type Config struct {
 type ServerConfig struct {
 	Port int    `mapstructure:"port"`
 	Host string `mapstructure:"host"`
+	// BaseURL is the public address of the API, used in file and feed URLs
+	BaseURL string `mapstructure:"base_url"`
+}
+
+// PublicURL returns BaseURL, or the local server address when it is unset
+func (s ServerConfig) PublicURL() string {
+	if s.BaseURL != "" {
+		return strings.TrimRight(s.BaseURL, "/")
+	}
+	return fmt.Sprintf("http://localhost:%d", s.Port)
 }
 
 type DatabaseConfig struct {
@@ -37,7 +57,145 @@ type RedisConfig struct {
 }
 
 type JWTConfig struct {
-	Secret string `mapstructure:"secret"`
+	Secret          string        `mapstructure:"secret"`
+	AccessTokenTTL  time.Duration `mapstructure:"access_token_ttl"`
+	RefreshTokenTTL time.Duration `mapstructure:"refresh_token_ttl"`
+}
+
+// AuthConfig controls account registration and login throttling.
+// RegistrationMode is one of "open", "invite" or "closed".
+type AuthConfig struct {
+	RegistrationMode   string        `mapstructure:"registration_mode"`
+	InviteTTL          time.Duration `mapstructure:"invite_ttl"`
+	LoginMaxFailures   int           `mapstructure:"login_max_failures"`
+	LoginIPMaxFailures int           `mapstructure:"login_ip_max_failures"`
+	LoginLockoutBase   time.Duration `mapstructure:"login_lockout_base"`
+	LoginLockoutMax    time.Duration `mapstructure:"login_lockout_max"`
+	LoginFailureWindow time.Duration `mapstructure:"login_failure_window"`
+
+	// Password reset and email verification links point at the frontend,
+	// which posts the token back to the API
+	PasswordResetTTL         time.Duration `mapstructure:"password_reset_ttl"`
+	PasswordResetURL         string        `mapstructure:"password_reset_url"`
+	EmailVerificationTTL     time.Duration `mapstructure:"email_verification_ttl"`
+	EmailVerificationURL     string        `mapstructure:"email_verification_url"`
+	RequireEmailVerification bool          `mapstructure:"require_email_verification"`
+}
+
+// MailConfig selects the mailer. Driver is one of "smtp", "file" or "log".
+type MailConfig struct {
+	Driver   string `mapstructure:"driver"`
+	Host     string `mapstructure:"host"`
+	Port     int    `mapstructure:"port"`
+	Username string `mapstructure:"username"`
+	Password string `mapstructure:"password"`
+	From     string `mapstructure:"from"`
+	FileDir  string `mapstructure:"file_dir"`
+}
+
+// SchedulerConfig controls the background worker that runs scheduled
+// publish/unpublish jobs. Jobs stuck in "running" longer than LockTimeout
+// (e.g. after a crash) are picked up again.
+type SchedulerConfig struct {
+	Enabled     bool          `mapstructure:"enabled"`
+	Interval    time.Duration `mapstructure:"interval"`
+	BatchSize   int           `mapstructure:"batch_size"`
+	LockTimeout time.Duration `mapstructure:"lock_timeout"`
+}
+
+// MediaConfig controls the processing, limits and serving of uploaded media
+type MediaConfig struct {
+	ProcessingEnabled bool `mapstructure:"processing_enabled"`
+	// ImageWidths are the widths JPEG/PNG images get a resized copy at, when
+	// narrower than the original
+	ImageWidths []int `mapstructure:"image_widths"`
+	JPEGQuality int   `mapstructure:"jpeg_quality"`
+	// WebP adds lossless WebP copies of the resized images
+	WebP bool `mapstructure:"webp"`
+	// MaxPixels leaves larger images unprocessed
+	MaxPixels   int           `mapstructure:"max_pixels"`
+	Interval    time.Duration `mapstructure:"interval"`
+	BatchSize   int           `mapstructure:"batch_size"`
+	LockTimeout time.Duration `mapstructure:"lock_timeout"`
+	// ReconcileGrace protects newer files and media from reconciliation cleanup
+	ReconcileGrace time.Duration `mapstructure:"reconcile_grace"`
+	// Upload limits per type in MB
+	MaxImageMB    int `mapstructure:"max_image_mb"`
+	MaxVideoMB    int `mapstructure:"max_video_mb"`
+	MaxDocumentMB int `mapstructure:"max_document_mb"`
+	MaxSVGMB      int `mapstructure:"max_svg_mb"`
+	// SVGPolicy is "sanitize" (strip scripts and external references),
+	// "download" (serve as an attachment) or "reject"
+	SVGPolicy string `mapstructure:"svg_policy"`
+	// UserQuotaMB caps the originals each user may upload unless their
+	// account sets its own quota; 0 is unlimited
+	UserQuotaMB int `mapstructure:"user_quota_mb"`
+	// ChunkDir stages resumable upload chunks, a temp directory by default;
+	// replicas serving the same uploads must share it
+	ChunkDir    string `mapstructure:"chunk_dir"`
+	ChunkSizeMB int    `mapstructure:"chunk_size_mb"`
+	// MaxResumableMB limits videos sent as resumable uploads
+	MaxResumableMB int `mapstructure:"max_resumable_mb"`
+	// UploadSessionTTL expires resumable uploads idle for longer
+	UploadSessionTTL time.Duration `mapstructure:"upload_session_ttl"`
+	// SigningKey signs the URLs of private media; derived from the JWT
+	// secret by default
+	SigningKey string `mapstructure:"signing_key"`
+	// SignedURLTTL is how long signed URLs are valid by default
+	SignedURLTTL time.Duration `mapstructure:"signed_url_ttl"`
+}
+
+// StorageConfig selects where media files are kept. Driver is "local" or
+// "s3"; the S3 backend is also set up under "local" when a bucket is
+// configured, so files can be migrated in either direction. Private media are
+// kept apart in PrivateDir, or in S3.PrivateBucket when it is set.
+type StorageConfig struct {
+	Driver string `mapstructure:"driver"`
+	// LocalDir defaults to ./uploads
+	LocalDir string `mapstructure:"local_dir"`
+	// PrivateDir defaults to ./private_uploads; it must not be served
+	PrivateDir string          `mapstructure:"private_dir"`
+	S3         S3StorageConfig `mapstructure:"s3"`
+}
+
+// S3StorageConfig addresses a bucket on S3 or a compatible server. PathStyle
+// is needed by most self-hosted servers such as MinIO; PublicURL (a CDN or
+// the bucket website) defaults to the bucket URL.
+type S3StorageConfig struct {
+	Endpoint  string `mapstructure:"endpoint"`
+	Region    string `mapstructure:"region"`
+	Bucket    string `mapstructure:"bucket"`
+	AccessKey string `mapstructure:"access_key"`
+	SecretKey string `mapstructure:"secret_key"`
+	PathStyle bool   `mapstructure:"path_style"`
+	PublicURL string `mapstructure:"public_url"`
+	// PrivateBucket keeps private media; it must not be publicly readable
+	PrivateBucket string `mapstructure:"private_bucket"`
+}
+
+// Dir returns LocalDir, or the uploads directory below the working directory
+func (s StorageConfig) Dir() string {
+	if s.LocalDir != "" {
+		return s.LocalDir
+	}
+	cwd, err := os.Getwd()
+	if err != nil {
+		return "./uploads"
+	}
+	return filepath.Join(cwd, "uploads")
+}
+
+// PrivatePath returns PrivateDir, or the private_uploads directory below the
+// working directory
+func (s StorageConfig) PrivatePath() string {
+	if s.PrivateDir != "" {
+		return s.PrivateDir
+	}
+	cwd, err := os.Getwd()
+	if err != nil {
+		return "./private_uploads"
+	}
+	return filepath.Join(cwd, "private_uploads")
 }
 
 type AppConfig struct {
@@ -68,6 +226,7 @@ func LoadConfig() *Config {
 	// Server
 	viper.BindEnv("server.port", "SERVER_PORT")
 	viper.BindEnv("server.host", "SERVER_HOST")
+	viper.BindEnv("server.base_url", "BASE_URL")
 
 	// Database
 	viper.BindEnv("database.host", "DB_HOST")
@@ -85,6 +244,73 @@ func LoadConfig() *Config {
 
 	// JWT
 	viper.BindEnv("jwt.secret", "JWT_SECRET")
+	viper.BindEnv("jwt.access_token_ttl", "JWT_ACCESS_TOKEN_TTL")
+	viper.BindEnv("jwt.refresh_token_ttl", "JWT_REFRESH_TOKEN_TTL")
+
+	// Auth
+	viper.BindEnv("auth.registration_mode", "AUTH_REGISTRATION_MODE")
+	viper.BindEnv("auth.invite_ttl", "AUTH_INVITE_TTL")
+	viper.BindEnv("auth.login_max_failures", "AUTH_LOGIN_MAX_FAILURES")
+	viper.BindEnv("auth.login_ip_max_failures", "AUTH_LOGIN_IP_MAX_FAILURES")
+	viper.BindEnv("auth.login_lockout_base", "AUTH_LOGIN_LOCKOUT_BASE")
+	viper.BindEnv("auth.login_lockout_max", "AUTH_LOGIN_LOCKOUT_MAX")
+	viper.BindEnv("auth.login_failure_window", "AUTH_LOGIN_FAILURE_WINDOW")
+	viper.BindEnv("auth.password_reset_ttl", "AUTH_PASSWORD_RESET_TTL")
+	viper.BindEnv("auth.password_reset_url", "AUTH_PASSWORD_RESET_URL")
+	viper.BindEnv("auth.email_verification_ttl", "AUTH_EMAIL_VERIFICATION_TTL")
+	viper.BindEnv("auth.email_verification_url", "AUTH_EMAIL_VERIFICATION_URL")
+	viper.BindEnv("auth.require_email_verification", "AUTH_REQUIRE_EMAIL_VERIFICATION")
+
+	// Mail
+	viper.BindEnv("mail.driver", "MAIL_DRIVER")
+	viper.BindEnv("mail.host", "MAIL_HOST")
+	viper.BindEnv("mail.port", "MAIL_PORT")
+	viper.BindEnv("mail.username", "MAIL_USERNAME")
+	viper.BindEnv("mail.password", "MAIL_PASSWORD")
+	viper.BindEnv("mail.from", "MAIL_FROM")
+	viper.BindEnv("mail.file_dir", "MAIL_FILE_DIR")
+
+	// Scheduler
+	viper.BindEnv("scheduler.enabled", "SCHEDULER_ENABLED")
+	viper.BindEnv("scheduler.interval", "SCHEDULER_INTERVAL")
+	viper.BindEnv("scheduler.batch_size", "SCHEDULER_BATCH_SIZE")
+	viper.BindEnv("scheduler.lock_timeout", "SCHEDULER_LOCK_TIMEOUT")
+
+	// Media
+	viper.BindEnv("media.processing_enabled", "MEDIA_PROCESSING_ENABLED")
+	viper.BindEnv("media.image_widths", "MEDIA_IMAGE_WIDTHS")
+	viper.BindEnv("media.jpeg_quality", "MEDIA_JPEG_QUALITY")
+	viper.BindEnv("media.webp", "MEDIA_WEBP")
+	viper.BindEnv("media.max_pixels", "MEDIA_MAX_PIXELS")
+	viper.BindEnv("media.interval", "MEDIA_PROCESSING_INTERVAL")
+	viper.BindEnv("media.batch_size", "MEDIA_PROCESSING_BATCH_SIZE")
+	viper.BindEnv("media.lock_timeout", "MEDIA_PROCESSING_LOCK_TIMEOUT")
+	viper.BindEnv("media.reconcile_grace", "MEDIA_RECONCILE_GRACE")
+	viper.BindEnv("media.max_image_mb", "MEDIA_MAX_IMAGE_MB")
+	viper.BindEnv("media.max_video_mb", "MEDIA_MAX_VIDEO_MB")
+	viper.BindEnv("media.max_document_mb", "MEDIA_MAX_DOCUMENT_MB")
+	viper.BindEnv("media.max_svg_mb", "MEDIA_MAX_SVG_MB")
+	viper.BindEnv("media.svg_policy", "MEDIA_SVG_POLICY")
+	viper.BindEnv("media.user_quota_mb", "MEDIA_USER_QUOTA_MB")
+	viper.BindEnv("media.chunk_dir", "MEDIA_CHUNK_DIR")
+	viper.BindEnv("media.chunk_size_mb", "MEDIA_CHUNK_SIZE_MB")
+	viper.BindEnv("media.max_resumable_mb", "MEDIA_MAX_RESUMABLE_MB")
+	viper.BindEnv("media.upload_session_ttl", "MEDIA_UPLOAD_SESSION_TTL")
+	viper.BindEnv("media.signing_key", "MEDIA_SIGNING_KEY")
+	viper.BindEnv("media.signed_url_ttl", "MEDIA_SIGNED_URL_TTL")
+
+	// Storage
+	viper.BindEnv("storage.driver", "STORAGE_DRIVER")
+	viper.BindEnv("storage.local_dir", "UPLOAD_DIR")
+	viper.BindEnv("storage.private_dir", "PRIVATE_UPLOAD_DIR")
+	viper.BindEnv("storage.s3.endpoint", "S3_ENDPOINT")
+	viper.BindEnv("storage.s3.region", "S3_REGION")
+	viper.BindEnv("storage.s3.bucket", "S3_BUCKET")
+	viper.BindEnv("storage.s3.access_key", "S3_ACCESS_KEY")
+	viper.BindEnv("storage.s3.secret_key", "S3_SECRET_KEY")
+	viper.BindEnv("storage.s3.path_style", "S3_PATH_STYLE")
+	viper.BindEnv("storage.s3.public_url", "S3_PUBLIC_URL")
+	viper.BindEnv("storage.s3.private_bucket", "S3_PRIVATE_BUCKET")
 
 	// App
 	viper.BindEnv("app.name", "APP_NAME")
@@ -103,6 +329,49 @@ func LoadConfig() *Config {
 	viper.SetDefault("redis.host", "localhost")
 	viper.SetDefault("redis.port", 6379)
 	viper.SetDefault("redis.db", 0)
+	viper.SetDefault("jwt.access_token_ttl", "15m")
+	viper.SetDefault("jwt.refresh_token_ttl", "720h")
+	viper.SetDefault("auth.registration_mode", "invite")
+	viper.SetDefault("auth.invite_ttl", "168h")
+	viper.SetDefault("auth.login_max_failures", 5)
+	viper.SetDefault("auth.login_ip_max_failures", 20)
+	viper.SetDefault("auth.login_lockout_base", "1m")
+	viper.SetDefault("auth.login_lockout_max", "1h")
+	viper.SetDefault("auth.login_failure_window", "15m")
+	viper.SetDefault("auth.password_reset_ttl", "1h")
+	viper.SetDefault("auth.password_reset_url", "http://localhost:2003/reset-password")
+	viper.SetDefault("auth.email_verification_ttl", "48h")
+	viper.SetDefault("auth.email_verification_url", "http://localhost:2003/verify-email")
+	viper.SetDefault("auth.require_email_verification", false)
+	viper.SetDefault("mail.driver", "log")
+	viper.SetDefault("mail.port", 587)
+	viper.SetDefault("mail.from", "no-reply@localhost")
+	viper.SetDefault("mail.file_dir", "./tmp/mail")
+	viper.SetDefault("scheduler.enabled", true)
+	viper.SetDefault("scheduler.interval", "30s")
+	viper.SetDefault("scheduler.batch_size", 20)
+	viper.SetDefault("scheduler.lock_timeout", "5m")
+	viper.SetDefault("media.processing_enabled", true)
+	viper.SetDefault("media.image_widths", []int{320, 640, 1024, 1600})
+	viper.SetDefault("media.jpeg_quality", 82)
+	viper.SetDefault("media.webp", true)
+	viper.SetDefault("media.max_pixels", 50000000)
+	viper.SetDefault("media.interval", "5s")
+	viper.SetDefault("media.batch_size", 4)
+	viper.SetDefault("media.lock_timeout", "10m")
+	viper.SetDefault("media.reconcile_grace", "168h")
+	viper.SetDefault("media.max_image_mb", 50)
+	viper.SetDefault("media.max_video_mb", 50)
+	viper.SetDefault("media.max_document_mb", 50)
+	viper.SetDefault("media.max_svg_mb", 2)
+	viper.SetDefault("media.svg_policy", "sanitize")
+	viper.SetDefault("media.user_quota_mb", 0)
+	viper.SetDefault("media.chunk_size_mb", 8)
+	viper.SetDefault("media.max_resumable_mb", 2048)
+	viper.SetDefault("media.upload_session_ttl", "24h")
+	viper.SetDefault("media.signed_url_ttl", "15m")
+	viper.SetDefault("storage.driver", "local")
+	viper.SetDefault("storage.s3.region", "us-east-1")
 	viper.SetDefault("app.debug", true)
 
 	var config Config
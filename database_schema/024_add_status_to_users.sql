@@ -0,0 +1,11 @@
+-- +goose Up
+ALTER TABLE users ADD COLUMN IF NOT EXISTS status VARCHAR(20) NOT NULL DEFAULT 'active';
+
+-- Carry over accounts previously deactivated through is_active
+UPDATE users SET status = 'disabled' WHERE is_active = false;
+
+CREATE INDEX IF NOT EXISTS idx_users_status ON users(status);
+
+-- +goose Down
+DROP INDEX IF EXISTS idx_users_status;
+ALTER TABLE users DROP COLUMN IF EXISTS status;
@@ -0,0 +1,8 @@
+-- +goose Up
+ALTER TABLE users ADD COLUMN IF NOT EXISTS email_verified_at TIMESTAMP NULL;
+
+-- Accounts created before verification existed are treated as verified
+UPDATE users SET email_verified_at = created_at WHERE email_verified_at IS NULL;
+
+-- +goose Down
+ALTER TABLE users DROP COLUMN IF EXISTS email_verified_at;
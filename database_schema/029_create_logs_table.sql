@@ -0,0 +1,22 @@
+-- +goose Up
+CREATE TABLE IF NOT EXISTS logs (
+    id SERIAL PRIMARY KEY,
+    user_id INTEGER REFERENCES users(id) ON DELETE SET NULL,
+    action VARCHAR(50) NOT NULL,
+    description TEXT,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+ALTER TABLE logs ADD COLUMN IF NOT EXISTS entity_type VARCHAR(50);
+ALTER TABLE logs ADD COLUMN IF NOT EXISTS entity_id VARCHAR(255);
+ALTER TABLE logs ADD COLUMN IF NOT EXISTS changes JSONB DEFAULT '{}';
+ALTER TABLE logs ADD COLUMN IF NOT EXISTS ip VARCHAR(100);
+ALTER TABLE logs ADD COLUMN IF NOT EXISTS user_agent TEXT;
+
+CREATE INDEX IF NOT EXISTS idx_logs_user_id ON logs(user_id);
+CREATE INDEX IF NOT EXISTS idx_logs_action ON logs(action);
+CREATE INDEX IF NOT EXISTS idx_logs_entity ON logs(entity_type, entity_id);
+CREATE INDEX IF NOT EXISTS idx_logs_created_at ON logs(created_at);
+
+-- +goose Down
+DROP TABLE IF EXISTS logs;
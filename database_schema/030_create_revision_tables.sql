@@ -0,0 +1,45 @@
+-- +goose Up
+CREATE TABLE IF NOT EXISTS article_revisions (
+    id SERIAL PRIMARY KEY,
+    entity_id VARCHAR(255) NOT NULL,
+    version INTEGER NOT NULL,
+    title VARCHAR(255),
+    snapshot JSONB NOT NULL,
+    user_id INTEGER REFERENCES users(id) ON DELETE SET NULL,
+    summary TEXT,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE (entity_id, version)
+);
+
+CREATE TABLE IF NOT EXISTS project_revisions (
+    id SERIAL PRIMARY KEY,
+    entity_id VARCHAR(255) NOT NULL,
+    version INTEGER NOT NULL,
+    title VARCHAR(255),
+    snapshot JSONB NOT NULL,
+    user_id INTEGER REFERENCES users(id) ON DELETE SET NULL,
+    summary TEXT,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE (entity_id, version)
+);
+
+CREATE TABLE IF NOT EXISTS page_revisions (
+    id SERIAL PRIMARY KEY,
+    entity_id VARCHAR(255) NOT NULL,
+    version INTEGER NOT NULL,
+    title VARCHAR(255),
+    snapshot JSONB NOT NULL,
+    user_id INTEGER REFERENCES users(id) ON DELETE SET NULL,
+    summary TEXT,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE (entity_id, version)
+);
+
+CREATE INDEX IF NOT EXISTS idx_article_revisions_entity_id ON article_revisions(entity_id);
+CREATE INDEX IF NOT EXISTS idx_project_revisions_entity_id ON project_revisions(entity_id);
+CREATE INDEX IF NOT EXISTS idx_page_revisions_entity_id ON page_revisions(entity_id);
+
+-- +goose Down
+DROP TABLE IF EXISTS page_revisions;
+DROP TABLE IF EXISTS project_revisions;
+DROP TABLE IF EXISTS article_revisions;
@@ -0,0 +1,31 @@
+-- +goose Up
+CREATE TABLE IF NOT EXISTS scheduled_jobs (
+    id SERIAL PRIMARY KEY,
+    entity_type VARCHAR(50) NOT NULL,
+    entity_id VARCHAR(255) NOT NULL,
+    action VARCHAR(50) NOT NULL,
+    run_at TIMESTAMP NOT NULL,
+    status VARCHAR(20) NOT NULL DEFAULT 'pending',
+    attempts INTEGER NOT NULL DEFAULT 0,
+    last_error TEXT,
+    locked_by VARCHAR(255),
+    locked_at TIMESTAMP,
+    executed_at TIMESTAMP,
+    created_by INTEGER REFERENCES users(id) ON DELETE SET NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_scheduled_jobs_entity ON scheduled_jobs(entity_type, entity_id);
+CREATE INDEX IF NOT EXISTS idx_scheduled_jobs_due ON scheduled_jobs(status, run_at);
+
+-- Projects and pages get a publish date like articles
+ALTER TABLE projects ADD COLUMN IF NOT EXISTS published_at TIMESTAMP;
+ALTER TABLE pages ADD COLUMN IF NOT EXISTS published_at TIMESTAMP;
+UPDATE projects SET published_at = created_at WHERE status = 'published' AND published_at IS NULL;
+UPDATE pages SET published_at = created_at WHERE status = 'published' AND published_at IS NULL;
+
+-- +goose Down
+ALTER TABLE pages DROP COLUMN IF EXISTS published_at;
+ALTER TABLE projects DROP COLUMN IF EXISTS published_at;
+DROP TABLE IF EXISTS scheduled_jobs;
@@ -0,0 +1,13 @@
+-- +goose Up
+-- Public listings require a publish date; older published rows may lack one
+UPDATE articles SET published_at = created_at WHERE status = 'published' AND published_at IS NULL;
+UPDATE posts SET published_at = created_at WHERE status = 'published' AND published_at IS NULL;
+
+CREATE INDEX IF NOT EXISTS idx_articles_status_published_at ON articles(status, published_at);
+CREATE INDEX IF NOT EXISTS idx_projects_status_published_at ON projects(status, published_at);
+CREATE INDEX IF NOT EXISTS idx_pages_status_published_at ON pages(status, published_at);
+
+-- +goose Down
+DROP INDEX IF EXISTS idx_pages_status_published_at;
+DROP INDEX IF EXISTS idx_projects_status_published_at;
+DROP INDEX IF EXISTS idx_articles_status_published_at;
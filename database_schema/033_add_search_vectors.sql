@@ -0,0 +1,256 @@
+-- +goose Up
+-- Full-text search over articles, projects, experiences and pages.
+-- Weights: A title, B excerpt/description and tag/category names, C body.
+-- Vectors are maintained by triggers, including when tags or categories are
+-- attached, detached or renamed.
+
+-- Created by GORM's AutoMigrate; declared here for goose-only setups
+CREATE TABLE IF NOT EXISTS project_categories (
+    project_id UUID REFERENCES projects(id) ON DELETE CASCADE,
+    category_id INTEGER REFERENCES categories(id) ON DELETE CASCADE,
+    PRIMARY KEY (project_id, category_id)
+);
+
+ALTER TABLE articles ADD COLUMN IF NOT EXISTS search_vector tsvector;
+ALTER TABLE projects ADD COLUMN IF NOT EXISTS search_vector tsvector;
+ALTER TABLE experiences ADD COLUMN IF NOT EXISTS search_vector tsvector;
+ALTER TABLE pages ADD COLUMN IF NOT EXISTS search_vector tsvector;
+
+-- +goose StatementBegin
+CREATE OR REPLACE FUNCTION search_strip_html(body TEXT) RETURNS TEXT AS $$
+    SELECT regexp_replace(coalesce(body, ''), '<[^>]*>', ' ', 'g');
+$$ LANGUAGE sql IMMUTABLE;
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+CREATE OR REPLACE FUNCTION articles_search_vector_update() RETURNS trigger AS $$
+DECLARE
+    terms TEXT;
+BEGIN
+    SELECT coalesce(string_agg(name, ' '), '') INTO terms FROM (
+        SELECT t.name FROM article_tags tg JOIN tags t ON t.id = tg.tag_id WHERE tg.article_id = NEW.id
+        UNION ALL
+        SELECT c.name FROM article_categories ac JOIN categories c ON c.id = ac.category_id WHERE ac.article_id = NEW.id
+    ) names;
+
+    NEW.search_vector :=
+        setweight(to_tsvector('english', coalesce(NEW.title, '')), 'A') ||
+        setweight(to_tsvector('english', coalesce(NEW.excerpt, '')), 'B') ||
+        setweight(to_tsvector('english', terms), 'B') ||
+        setweight(to_tsvector('english', search_strip_html(NEW.content)), 'C');
+    RETURN NEW;
+END
+$$ LANGUAGE plpgsql;
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+CREATE OR REPLACE FUNCTION projects_search_vector_update() RETURNS trigger AS $$
+DECLARE
+    terms TEXT;
+BEGIN
+    SELECT coalesce(string_agg(name, ' '), '') INTO terms FROM (
+        SELECT t.name FROM project_tags tg JOIN tags t ON t.id = tg.tag_id WHERE tg.project_id = NEW.id
+        UNION ALL
+        SELECT t.name FROM project_technologies pt JOIN tags t ON t.id = pt.tag_id WHERE pt.project_id = NEW.id
+        UNION ALL
+        SELECT c.name FROM project_categories pc JOIN categories c ON c.id = pc.category_id WHERE pc.project_id = NEW.id
+        UNION ALL
+        SELECT c.name FROM categories c WHERE c.id = NEW.category_id
+    ) names;
+
+    NEW.search_vector :=
+        setweight(to_tsvector('english', coalesce(NEW.title, '')), 'A') ||
+        setweight(to_tsvector('english', coalesce(NEW.description, '')), 'B') ||
+        setweight(to_tsvector('english', terms), 'B') ||
+        setweight(to_tsvector('english', search_strip_html(NEW.content)), 'C');
+    RETURN NEW;
+END
+$$ LANGUAGE plpgsql;
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+CREATE OR REPLACE FUNCTION experiences_search_vector_update() RETURNS trigger AS $$
+DECLARE
+    terms TEXT;
+    duties TEXT := '';
+BEGIN
+    SELECT coalesce(string_agg(t.name, ' '), '') INTO terms
+    FROM experience_technologies et JOIN tags t ON t.id = et.tag_id WHERE et.experience_id = NEW.id;
+
+    IF jsonb_typeof(NEW.responsibilities) = 'array' THEN
+        SELECT coalesce(string_agg(value, ' '), '') INTO duties FROM jsonb_array_elements_text(NEW.responsibilities);
+    END IF;
+
+    NEW.search_vector :=
+        setweight(to_tsvector('english', coalesce(NEW.title, '') || ' ' || coalesce(NEW.company, '')), 'A') ||
+        setweight(to_tsvector('english', coalesce(NEW.location, '') || ' ' || terms), 'B') ||
+        setweight(to_tsvector('english', search_strip_html(NEW.description) || ' ' || duties), 'C');
+    RETURN NEW;
+END
+$$ LANGUAGE plpgsql;
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+CREATE OR REPLACE FUNCTION pages_search_vector_update() RETURNS trigger AS $$
+BEGIN
+    NEW.search_vector :=
+        setweight(to_tsvector('english', coalesce(NEW.title, '')), 'A') ||
+        setweight(to_tsvector('english', search_strip_html(NEW.content)), 'C');
+    RETURN NEW;
+END
+$$ LANGUAGE plpgsql;
+-- +goose StatementEnd
+
+DROP TRIGGER IF EXISTS articles_search_vector_trigger ON articles;
+CREATE TRIGGER articles_search_vector_trigger BEFORE INSERT OR UPDATE ON articles
+    FOR EACH ROW EXECUTE FUNCTION articles_search_vector_update();
+DROP TRIGGER IF EXISTS projects_search_vector_trigger ON projects;
+CREATE TRIGGER projects_search_vector_trigger BEFORE INSERT OR UPDATE ON projects
+    FOR EACH ROW EXECUTE FUNCTION projects_search_vector_update();
+DROP TRIGGER IF EXISTS experiences_search_vector_trigger ON experiences;
+CREATE TRIGGER experiences_search_vector_trigger BEFORE INSERT OR UPDATE ON experiences
+    FOR EACH ROW EXECUTE FUNCTION experiences_search_vector_update();
+DROP TRIGGER IF EXISTS pages_search_vector_trigger ON pages;
+CREATE TRIGGER pages_search_vector_trigger BEFORE INSERT OR UPDATE ON pages
+    FOR EACH ROW EXECUTE FUNCTION pages_search_vector_update();
+
+-- Attaching or detaching tags/categories refreshes the owning row
+-- +goose StatementBegin
+CREATE OR REPLACE FUNCTION article_terms_changed() RETURNS trigger AS $$
+BEGIN
+    IF TG_OP = 'DELETE' THEN
+        UPDATE articles SET search_vector = NULL WHERE id = OLD.article_id;
+    ELSE
+        UPDATE articles SET search_vector = NULL WHERE id = NEW.article_id;
+    END IF;
+    RETURN NULL;
+END
+$$ LANGUAGE plpgsql;
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+CREATE OR REPLACE FUNCTION project_terms_changed() RETURNS trigger AS $$
+BEGIN
+    IF TG_OP = 'DELETE' THEN
+        UPDATE projects SET search_vector = NULL WHERE id = OLD.project_id;
+    ELSE
+        UPDATE projects SET search_vector = NULL WHERE id = NEW.project_id;
+    END IF;
+    RETURN NULL;
+END
+$$ LANGUAGE plpgsql;
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+CREATE OR REPLACE FUNCTION experience_terms_changed() RETURNS trigger AS $$
+BEGIN
+    IF TG_OP = 'DELETE' THEN
+        UPDATE experiences SET search_vector = NULL WHERE id = OLD.experience_id;
+    ELSE
+        UPDATE experiences SET search_vector = NULL WHERE id = NEW.experience_id;
+    END IF;
+    RETURN NULL;
+END
+$$ LANGUAGE plpgsql;
+-- +goose StatementEnd
+
+DROP TRIGGER IF EXISTS article_tags_search_trigger ON article_tags;
+CREATE TRIGGER article_tags_search_trigger AFTER INSERT OR DELETE ON article_tags
+    FOR EACH ROW EXECUTE FUNCTION article_terms_changed();
+DROP TRIGGER IF EXISTS article_categories_search_trigger ON article_categories;
+CREATE TRIGGER article_categories_search_trigger AFTER INSERT OR DELETE ON article_categories
+    FOR EACH ROW EXECUTE FUNCTION article_terms_changed();
+DROP TRIGGER IF EXISTS project_tags_search_trigger ON project_tags;
+CREATE TRIGGER project_tags_search_trigger AFTER INSERT OR DELETE ON project_tags
+    FOR EACH ROW EXECUTE FUNCTION project_terms_changed();
+DROP TRIGGER IF EXISTS project_technologies_search_trigger ON project_technologies;
+CREATE TRIGGER project_technologies_search_trigger AFTER INSERT OR DELETE ON project_technologies
+    FOR EACH ROW EXECUTE FUNCTION project_terms_changed();
+DROP TRIGGER IF EXISTS project_categories_search_trigger ON project_categories;
+CREATE TRIGGER project_categories_search_trigger AFTER INSERT OR DELETE ON project_categories
+    FOR EACH ROW EXECUTE FUNCTION project_terms_changed();
+DROP TRIGGER IF EXISTS experience_technologies_search_trigger ON experience_technologies;
+CREATE TRIGGER experience_technologies_search_trigger AFTER INSERT OR DELETE ON experience_technologies
+    FOR EACH ROW EXECUTE FUNCTION experience_terms_changed();
+
+-- Renaming a tag or category refreshes every row that uses it
+-- +goose StatementBegin
+CREATE OR REPLACE FUNCTION tag_renamed() RETURNS trigger AS $$
+BEGIN
+    UPDATE articles SET search_vector = NULL
+    WHERE id IN (SELECT article_id FROM article_tags WHERE tag_id = NEW.id);
+    UPDATE projects SET search_vector = NULL
+    WHERE id IN (SELECT project_id FROM project_tags WHERE tag_id = NEW.id
+                 UNION SELECT project_id FROM project_technologies WHERE tag_id = NEW.id);
+    UPDATE experiences SET search_vector = NULL
+    WHERE id IN (SELECT experience_id FROM experience_technologies WHERE tag_id = NEW.id);
+    RETURN NULL;
+END
+$$ LANGUAGE plpgsql;
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+CREATE OR REPLACE FUNCTION category_renamed() RETURNS trigger AS $$
+BEGIN
+    UPDATE articles SET search_vector = NULL
+    WHERE id IN (SELECT article_id FROM article_categories WHERE category_id = NEW.id);
+    UPDATE projects SET search_vector = NULL
+    WHERE category_id = NEW.id
+       OR id IN (SELECT project_id FROM project_categories WHERE category_id = NEW.id);
+    RETURN NULL;
+END
+$$ LANGUAGE plpgsql;
+-- +goose StatementEnd
+
+DROP TRIGGER IF EXISTS tags_search_trigger ON tags;
+CREATE TRIGGER tags_search_trigger AFTER UPDATE OF name ON tags
+    FOR EACH ROW WHEN (OLD.name IS DISTINCT FROM NEW.name) EXECUTE FUNCTION tag_renamed();
+DROP TRIGGER IF EXISTS categories_search_trigger ON categories;
+CREATE TRIGGER categories_search_trigger AFTER UPDATE OF name ON categories
+    FOR EACH ROW WHEN (OLD.name IS DISTINCT FROM NEW.name) EXECUTE FUNCTION category_renamed();
+
+-- Backfill; the row triggers compute the vectors
+UPDATE articles SET search_vector = NULL;
+UPDATE projects SET search_vector = NULL;
+UPDATE experiences SET search_vector = NULL;
+UPDATE pages SET search_vector = NULL;
+
+CREATE INDEX IF NOT EXISTS idx_articles_search_vector ON articles USING GIN (search_vector);
+CREATE INDEX IF NOT EXISTS idx_projects_search_vector ON projects USING GIN (search_vector);
+CREATE INDEX IF NOT EXISTS idx_experiences_search_vector ON experiences USING GIN (search_vector);
+CREATE INDEX IF NOT EXISTS idx_pages_search_vector ON pages USING GIN (search_vector);
+
+-- +goose Down
+DROP TRIGGER IF EXISTS categories_search_trigger ON categories;
+DROP TRIGGER IF EXISTS tags_search_trigger ON tags;
+DROP TRIGGER IF EXISTS experience_technologies_search_trigger ON experience_technologies;
+DROP TRIGGER IF EXISTS project_categories_search_trigger ON project_categories;
+DROP TRIGGER IF EXISTS project_technologies_search_trigger ON project_technologies;
+DROP TRIGGER IF EXISTS project_tags_search_trigger ON project_tags;
+DROP TRIGGER IF EXISTS article_categories_search_trigger ON article_categories;
+DROP TRIGGER IF EXISTS article_tags_search_trigger ON article_tags;
+DROP TRIGGER IF EXISTS pages_search_vector_trigger ON pages;
+DROP TRIGGER IF EXISTS experiences_search_vector_trigger ON experiences;
+DROP TRIGGER IF EXISTS projects_search_vector_trigger ON projects;
+DROP TRIGGER IF EXISTS articles_search_vector_trigger ON articles;
+
+DROP FUNCTION IF EXISTS category_renamed();
+DROP FUNCTION IF EXISTS tag_renamed();
+DROP FUNCTION IF EXISTS experience_terms_changed();
+DROP FUNCTION IF EXISTS project_terms_changed();
+DROP FUNCTION IF EXISTS article_terms_changed();
+DROP FUNCTION IF EXISTS pages_search_vector_update();
+DROP FUNCTION IF EXISTS experiences_search_vector_update();
+DROP FUNCTION IF EXISTS projects_search_vector_update();
+DROP FUNCTION IF EXISTS articles_search_vector_update();
+DROP FUNCTION IF EXISTS search_strip_html(TEXT);
+
+DROP INDEX IF EXISTS idx_pages_search_vector;
+DROP INDEX IF EXISTS idx_experiences_search_vector;
+DROP INDEX IF EXISTS idx_projects_search_vector;
+DROP INDEX IF EXISTS idx_articles_search_vector;
+
+ALTER TABLE pages DROP COLUMN IF EXISTS search_vector;
+ALTER TABLE experiences DROP COLUMN IF EXISTS search_vector;
+ALTER TABLE projects DROP COLUMN IF EXISTS search_vector;
+ALTER TABLE articles DROP COLUMN IF EXISTS search_vector;
@@ -0,0 +1,10 @@
+-- +goose Up
+-- Cursor pagination seeks on (creation time, id)
+CREATE INDEX IF NOT EXISTS idx_page_views_timestamp_id ON page_views(timestamp, id);
+CREATE INDEX IF NOT EXISTS idx_content_views_timestamp_id ON content_views(timestamp, id);
+CREATE INDEX IF NOT EXISTS idx_media_uploaded_at_id ON media(uploaded_at, id);
+
+-- +goose Down
+DROP INDEX IF EXISTS idx_media_uploaded_at_id;
+DROP INDEX IF EXISTS idx_content_views_timestamp_id;
+DROP INDEX IF EXISTS idx_page_views_timestamp_id;
@@ -0,0 +1,27 @@
+-- +goose Up
+-- Typed SEO block; see models.SEO for the keys
+ALTER TABLE articles ADD COLUMN IF NOT EXISTS seo JSONB NOT NULL DEFAULT '{}';
+ALTER TABLE projects ADD COLUMN IF NOT EXISTS seo JSONB NOT NULL DEFAULT '{}';
+ALTER TABLE pages ADD COLUMN IF NOT EXISTS seo JSONB NOT NULL DEFAULT '{}';
+
+-- Carry over SEO values kept in the free-form metadata so far
+UPDATE articles SET seo = jsonb_strip_nulls(jsonb_build_object(
+    'metaTitle', COALESCE(metadata->>'metaTitle', metadata->>'seoTitle'),
+    'metaDescription', COALESCE(metadata->>'metaDescription', metadata->>'seoDescription'),
+    'canonicalUrl', metadata->>'canonicalUrl',
+    'ogImage', metadata->>'ogImage'
+))
+WHERE jsonb_typeof(metadata) = 'object';
+
+UPDATE projects SET seo = jsonb_strip_nulls(jsonb_build_object(
+    'metaTitle', COALESCE(metadata->>'metaTitle', metadata->>'seoTitle'),
+    'metaDescription', COALESCE(metadata->>'metaDescription', metadata->>'seoDescription'),
+    'canonicalUrl', metadata->>'canonicalUrl',
+    'ogImage', metadata->>'ogImage'
+))
+WHERE jsonb_typeof(metadata) = 'object';
+
+-- +goose Down
+ALTER TABLE pages DROP COLUMN IF EXISTS seo;
+ALTER TABLE projects DROP COLUMN IF EXISTS seo;
+ALTER TABLE articles DROP COLUMN IF EXISTS seo;
@@ -0,0 +1,28 @@
+-- +goose Up
+-- Image dimensions, generated variants and background processing state
+ALTER TABLE media ADD COLUMN IF NOT EXISTS width INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE media ADD COLUMN IF NOT EXISTS height INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE media ADD COLUMN IF NOT EXISTS variants JSONB NOT NULL DEFAULT '[]';
+ALTER TABLE media ADD COLUMN IF NOT EXISTS processing_status VARCHAR(20) NOT NULL DEFAULT '';
+ALTER TABLE media ADD COLUMN IF NOT EXISTS processing_error TEXT NOT NULL DEFAULT '';
+ALTER TABLE media ADD COLUMN IF NOT EXISTS processing_attempts INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE media ADD COLUMN IF NOT EXISTS processing_started_at TIMESTAMP WITH TIME ZONE;
+ALTER TABLE media ADD COLUMN IF NOT EXISTS processed_at TIMESTAMP WITH TIME ZONE;
+
+CREATE INDEX IF NOT EXISTS idx_media_processing_status ON media(processing_status)
+    WHERE processing_status IN ('pending', 'processing');
+
+-- Images uploaded so far get their metadata stripped and variants generated
+UPDATE media SET processing_status = 'pending'
+WHERE mime_type IN ('image/jpeg', 'image/png', 'image/gif') AND processing_status = '';
+
+-- +goose Down
+DROP INDEX IF EXISTS idx_media_processing_status;
+ALTER TABLE media DROP COLUMN IF EXISTS processed_at;
+ALTER TABLE media DROP COLUMN IF EXISTS processing_started_at;
+ALTER TABLE media DROP COLUMN IF EXISTS processing_attempts;
+ALTER TABLE media DROP COLUMN IF EXISTS processing_error;
+ALTER TABLE media DROP COLUMN IF EXISTS processing_status;
+ALTER TABLE media DROP COLUMN IF EXISTS variants;
+ALTER TABLE media DROP COLUMN IF EXISTS height;
+ALTER TABLE media DROP COLUMN IF EXISTS width;
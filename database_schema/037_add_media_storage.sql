@@ -0,0 +1,27 @@
+-- +goose Up
+-- Media files live in a storage backend under a key; everything so far is on
+-- the local disk below the uploads directory
+ALTER TABLE media ADD COLUMN IF NOT EXISTS storage VARCHAR(20) NOT NULL DEFAULT 'local';
+ALTER TABLE media ADD COLUMN IF NOT EXISTS storage_key TEXT NOT NULL DEFAULT '';
+
+UPDATE media SET storage_key = substring(file_url from '/uploads/(.*)$')
+WHERE storage_key = '' AND file_url LIKE '%/uploads/%';
+
+-- Variants are addressed by key instead of local path
+UPDATE media SET variants = (
+    SELECT COALESCE(jsonb_agg((v - 'path') || jsonb_build_object('key', substring(v->>'url' from '/uploads/(.*)$'))), '[]'::jsonb)
+    FROM jsonb_array_elements(variants) AS v
+)
+WHERE jsonb_array_length(variants) > 0;
+
+CREATE INDEX IF NOT EXISTS idx_media_storage ON media(storage);
+
+-- +goose Down
+DROP INDEX IF EXISTS idx_media_storage;
+UPDATE media SET variants = (
+    SELECT COALESCE(jsonb_agg((v - 'key') || jsonb_build_object('path', '')), '[]'::jsonb)
+    FROM jsonb_array_elements(variants) AS v
+)
+WHERE jsonb_array_length(variants) > 0;
+ALTER TABLE media DROP COLUMN IF EXISTS storage_key;
+ALTER TABLE media DROP COLUMN IF EXISTS storage;
@@ -0,0 +1,56 @@
+-- +goose Up
+-- Folders form a tree; path is the slash-joined chain of names and the key
+-- prefix new uploads into the folder get
+CREATE TABLE IF NOT EXISTS media_folders (
+    id SERIAL PRIMARY KEY,
+    name VARCHAR(255) NOT NULL,
+    parent_id INTEGER REFERENCES media_folders(id) ON DELETE RESTRICT,
+    path TEXT NOT NULL UNIQUE,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+CREATE INDEX IF NOT EXISTS idx_media_folders_parent_id ON media_folders(parent_id);
+
+ALTER TABLE media ADD COLUMN IF NOT EXISTS title TEXT NOT NULL DEFAULT '';
+ALTER TABLE media ADD COLUMN IF NOT EXISTS alt_text TEXT NOT NULL DEFAULT '';
+ALTER TABLE media ADD COLUMN IF NOT EXISTS caption TEXT NOT NULL DEFAULT '';
+ALTER TABLE media ADD COLUMN IF NOT EXISTS credit TEXT NOT NULL DEFAULT '';
+ALTER TABLE media ADD COLUMN IF NOT EXISTS folder_id INTEGER REFERENCES media_folders(id) ON DELETE SET NULL;
+CREATE INDEX IF NOT EXISTS idx_media_folder_id ON media(folder_id);
+
+CREATE TABLE IF NOT EXISTS media_tags (
+    media_id INTEGER NOT NULL REFERENCES media(id) ON DELETE CASCADE,
+    tag_id INTEGER NOT NULL REFERENCES tags(id) ON DELETE CASCADE,
+    PRIMARY KEY (media_id, tag_id)
+);
+CREATE INDEX IF NOT EXISTS idx_media_tags_tag_id ON media_tags(tag_id);
+
+-- Turn the folder form field of past uploads, which ended up in the storage
+-- key, into folders: every directory and its parents
+WITH dirs AS (
+    SELECT DISTINCT string_to_array(regexp_replace(storage_key, '/[^/]*$', ''), '/') AS parts
+    FROM media
+    WHERE storage_key LIKE '%/%'
+)
+INSERT INTO media_folders (name, path)
+SELECT DISTINCT parts[n], array_to_string(parts[1:n], '/')
+FROM dirs, generate_series(1, array_length(parts, 1)) AS n
+ON CONFLICT (path) DO NOTHING;
+
+UPDATE media_folders f SET parent_id = p.id
+FROM media_folders p
+WHERE f.path LIKE '%/%' AND p.path = regexp_replace(f.path, '/[^/]*$', '') AND f.parent_id IS NULL;
+
+UPDATE media m SET folder_id = f.id
+FROM media_folders f
+WHERE m.storage_key LIKE '%/%' AND f.path = regexp_replace(m.storage_key, '/[^/]*$', '') AND m.folder_id IS NULL;
+
+-- +goose Down
+DROP TABLE IF EXISTS media_tags;
+DROP INDEX IF EXISTS idx_media_folder_id;
+ALTER TABLE media DROP COLUMN IF EXISTS folder_id;
+ALTER TABLE media DROP COLUMN IF EXISTS credit;
+ALTER TABLE media DROP COLUMN IF EXISTS caption;
+ALTER TABLE media DROP COLUMN IF EXISTS alt_text;
+ALTER TABLE media DROP COLUMN IF EXISTS title;
+DROP TABLE IF EXISTS media_folders;
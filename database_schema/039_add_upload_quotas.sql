@@ -0,0 +1,8 @@
+-- +goose Up
+-- NULL uses the configured default quota, 0 is unlimited
+ALTER TABLE users ADD COLUMN IF NOT EXISTS upload_quota_mb INTEGER;
+CREATE INDEX IF NOT EXISTS idx_media_uploaded_by ON media(uploaded_by);
+
+-- +goose Down
+DROP INDEX IF EXISTS idx_media_uploaded_by;
+ALTER TABLE users DROP COLUMN IF EXISTS upload_quota_mb;
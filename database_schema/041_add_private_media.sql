@@ -0,0 +1,11 @@
+-- +goose Up
+-- Private media are kept in the private storage backend and only served
+-- through signed URLs
+ALTER TABLE media ADD COLUMN IF NOT EXISTS private BOOLEAN NOT NULL DEFAULT FALSE;
+CREATE INDEX IF NOT EXISTS idx_media_private ON media(private);
+ALTER TABLE upload_sessions ADD COLUMN IF NOT EXISTS private BOOLEAN NOT NULL DEFAULT FALSE;
+
+-- +goose Down
+ALTER TABLE upload_sessions DROP COLUMN IF EXISTS private;
+DROP INDEX IF EXISTS idx_media_private;
+ALTER TABLE media DROP COLUMN IF EXISTS private;
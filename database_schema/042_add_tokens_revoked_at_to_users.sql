@@ -0,0 +1,7 @@
+-- +goose Up
+-- Access tokens issued before this are rejected, so logging out everywhere
+-- takes effect immediately rather than when the tokens expire
+ALTER TABLE users ADD COLUMN IF NOT EXISTS tokens_revoked_at TIMESTAMP;
+
+-- +goose Down
+ALTER TABLE users DROP COLUMN IF EXISTS tokens_revoked_at;
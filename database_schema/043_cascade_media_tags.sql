@@ -0,0 +1,13 @@
+-- +goose Up
+-- media_tags may have been created by the ORM before 038 ran, with foreign
+-- keys that block deleting tagged media or tags; recreate them cascading
+ALTER TABLE media_tags DROP CONSTRAINT IF EXISTS fk_media_tags_media;
+ALTER TABLE media_tags DROP CONSTRAINT IF EXISTS fk_media_tags_tag;
+ALTER TABLE media_tags ADD CONSTRAINT fk_media_tags_media FOREIGN KEY (media_id) REFERENCES media(id) ON DELETE CASCADE;
+ALTER TABLE media_tags ADD CONSTRAINT fk_media_tags_tag FOREIGN KEY (tag_id) REFERENCES tags(id) ON DELETE CASCADE;
+
+-- +goose Down
+ALTER TABLE media_tags DROP CONSTRAINT IF EXISTS fk_media_tags_media;
+ALTER TABLE media_tags DROP CONSTRAINT IF EXISTS fk_media_tags_tag;
+ALTER TABLE media_tags ADD CONSTRAINT fk_media_tags_media FOREIGN KEY (media_id) REFERENCES media(id);
+ALTER TABLE media_tags ADD CONSTRAINT fk_media_tags_tag FOREIGN KEY (tag_id) REFERENCES tags(id);
@@ -0,0 +1,22 @@
+-- +goose Up
+-- search_strip_html left unclosed tags such as "<img src=x onerror=..." in
+-- place; strip a tag up to its ">" or the end of the text. A "<" that does
+-- not start a tag ("a < b") is kept.
+-- +goose StatementBegin
+CREATE OR REPLACE FUNCTION search_strip_html(body TEXT) RETURNS TEXT AS $$
+    SELECT regexp_replace(coalesce(body, ''), '<[a-zA-Z/!?][^>]*(>|$)', ' ', 'g');
+$$ LANGUAGE sql IMMUTABLE;
+-- +goose StatementEnd
+
+-- The row triggers recompute the vectors
+UPDATE articles SET search_vector = NULL;
+UPDATE projects SET search_vector = NULL;
+UPDATE experiences SET search_vector = NULL;
+UPDATE pages SET search_vector = NULL;
+
+-- +goose Down
+-- +goose StatementBegin
+CREATE OR REPLACE FUNCTION search_strip_html(body TEXT) RETURNS TEXT AS $$
+    SELECT regexp_replace(coalesce(body, ''), '<[^>]*>', ' ', 'g');
+$$ LANGUAGE sql IMMUTABLE;
+-- +goose StatementEnd
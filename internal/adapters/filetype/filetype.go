@@ -0,0 +1,142 @@
+// Package filetype identifies uploaded files by their content. Detection
+// only knows the types the media library accepts, so anything it cannot
+// name is refused.
+package filetype
+
+import (
+	"bytes"
+	"encoding/xml"
+	"strings"
+)
+
+// Media types the library accepts
+const (
+	JPEG = "image/jpeg"
+	PNG  = "image/png"
+	GIF  = "image/gif"
+	WebP = "image/webp"
+	SVG  = "image/svg+xml"
+	PDF  = "application/pdf"
+	MP4  = "video/mp4"
+	WebM = "video/webm"
+	Ogg  = "video/ogg"
+)
+
+// extensions maps accepted file extensions to the type their content must have
+var extensions = map[string]string{
+	".jpg":  JPEG,
+	".jpeg": JPEG,
+	".jpe":  JPEG,
+	".png":  PNG,
+	".gif":  GIF,
+	".webp": WebP,
+	".svg":  SVG,
+	".pdf":  PDF,
+	".mp4":  MP4,
+	".m4v":  MP4,
+	".webm": WebM,
+	".ogv":  Ogg,
+	".ogg":  Ogg,
+}
+
+// canonical is the extension stored files of each type get
+var canonical = map[string]string{
+	JPEG: ".jpg",
+	PNG:  ".png",
+	GIF:  ".gif",
+	WebP: ".webp",
+	SVG:  ".svg",
+	PDF:  ".pdf",
+	MP4:  ".mp4",
+	WebM: ".webm",
+	Ogg:  ".ogv",
+}
+
+// Detect returns the media type of data from its leading bytes, or "" when
+// it is not one of the accepted types
+func Detect(data []byte) string {
+	switch {
+	case bytes.HasPrefix(data, []byte{0xFF, 0xD8, 0xFF}):
+		return JPEG
+	case bytes.HasPrefix(data, []byte("\x89PNG\r\n\x1a\n")):
+		return PNG
+	case bytes.HasPrefix(data, []byte("GIF87a")), bytes.HasPrefix(data, []byte("GIF89a")):
+		return GIF
+	case len(data) >= 12 && bytes.Equal(data[:4], []byte("RIFF")) && bytes.Equal(data[8:12], []byte("WEBP")):
+		return WebP
+	case bytes.HasPrefix(data, []byte("%PDF-")):
+		return PDF
+	case len(data) >= 12 && bytes.Equal(data[4:8], []byte("ftyp")):
+		// ISO base media; QuickTime movies share the container but not the type
+		if bytes.Equal(data[8:12], []byte("qt  ")) {
+			return ""
+		}
+		return MP4
+	case bytes.HasPrefix(data, []byte{0x1A, 0x45, 0xDF, 0xA3}):
+		// EBML; Matroska files other than WebM name another doctype
+		if bytes.Contains(data[:min(len(data), 64)], []byte("webm")) {
+			return WebM
+		}
+		return ""
+	case bytes.HasPrefix(data, []byte("OggS")):
+		return Ogg
+	case isSVG(data):
+		return SVG
+	}
+	return ""
+}
+
+// ForExtension returns the type files with extension ext must have, or ""
+// when the extension is not accepted
+func ForExtension(ext string) string {
+	return extensions[strings.ToLower(ext)]
+}
+
+// Extension returns the extension stored files of mimeType get
+func Extension(mimeType string) string {
+	return canonical[mimeType]
+}
+
+// Category groups a type as image, video or document
+func Category(mimeType string) string {
+	switch {
+	case strings.HasPrefix(mimeType, "image/"):
+		return "image"
+	case strings.HasPrefix(mimeType, "video/"):
+		return "video"
+	case mimeType == PDF:
+		return "document"
+	}
+	return "file"
+}
+
+// isSVG reports whether data is an XML document whose root element is svg
+func isSVG(data []byte) bool {
+	data = bytes.TrimPrefix(data, []byte("\xef\xbb\xbf"))
+	head := bytes.TrimLeft(data[:min(len(data), 1024)], " \t\r\n")
+	if !bytes.HasPrefix(head, []byte("<")) {
+		return false
+	}
+	d := xml.NewDecoder(bytes.NewReader(data))
+	d.Strict = false
+	for {
+		tok, err := d.RawToken()
+		if err != nil {
+			return false
+		}
+		switch t := tok.(type) {
+		case xml.StartElement:
+			return t.Name.Local == "svg"
+		case xml.CharData:
+			if len(bytes.TrimSpace(t)) > 0 {
+				return false
+			}
+		case xml.ProcInst, xml.Comment, xml.Directive:
+		default:
+			return false
+		}
+		if d.InputOffset() > 64*1024 {
+			return false
+		}
+	}
+}
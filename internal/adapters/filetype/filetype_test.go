@@ -0,0 +1,73 @@
+package filetype
+
+import "testing"
+
+var (
+	jpegData = []byte("\xff\xd8\xff\xe0\x00\x10JFIF\x00")
+	pngData  = []byte("\x89PNG\r\n\x1a\n\x00\x00\x00\rIHDR")
+	svgData  = []byte(`<svg xmlns="http://www.w3.org/2000/svg"><rect/></svg>`)
+)
+
+func TestDetect(t *testing.T) {
+	tests := []struct {
+		name string
+		data string
+		want string
+	}{
+		{"jpeg", string(jpegData), JPEG},
+		{"png", string(pngData), PNG},
+		{"gif87a", "GIF87a\x01\x00", GIF},
+		{"gif89a", "GIF89a\x01\x00", GIF},
+		{"webp", "RIFF\x24\x00\x00\x00WEBPVP8 ", WebP},
+		{"riff that is not webp", "RIFF\x24\x00\x00\x00WAVEfmt ", ""},
+		{"pdf", "%PDF-1.7\n", PDF},
+		{"mp4", "\x00\x00\x00\x18ftypmp42\x00\x00\x00\x00", MP4},
+		{"quicktime", "\x00\x00\x00\x14ftypqt  \x00\x00\x00\x00", ""},
+		{"webm", "\x1a\x45\xdf\xa3\x9f\x42\x86\x81\x01\x42\x82\x84webm", WebM},
+		{"matroska", "\x1a\x45\xdf\xa3\x9f\x42\x86\x81\x01\x42\x82\x88matroska", ""},
+		{"ogg", "OggS\x00\x02", Ogg},
+		{"svg", string(svgData), SVG},
+		{"svg with prolog", "\xef\xbb\xbf<?xml version=\"1.0\"?>\n<!-- logo -->\n<!DOCTYPE svg>\n<svg/>", SVG},
+		{"html", "<!DOCTYPE html><html><svg/></html>", ""},
+		{"xml with another root", `<?xml version="1.0"?><feed/>`, ""},
+		{"text before svg", "hello <svg/>", ""},
+		{"script", "#!/bin/sh\necho hi", ""},
+		{"executable", "MZ\x90\x00", ""},
+		{"empty", "", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Detect([]byte(tt.data)); got != tt.want {
+				t.Fatalf("Detect = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestExtensionMustMatchContent(t *testing.T) {
+	tests := []struct {
+		name  string
+		ext   string
+		data  []byte
+		match bool
+	}{
+		{"jpeg as .jpg", ".jpg", jpegData, true},
+		{"jpeg as .JPEG", ".JPEG", jpegData, true},
+		{"png as .png", ".png", pngData, true},
+		{"svg as .svg", ".svg", svgData, true},
+		{"png as .jpg", ".jpg", pngData, false},
+		{"svg as .png", ".png", svgData, false},
+		{"svg as .jpg", ".jpg", svgData, false},
+		{"jpeg as .svg", ".svg", jpegData, false},
+		{"html as .pdf", ".pdf", []byte("<html><script>alert(1)</script></html>"), false},
+		{"jpeg as .html", ".html", jpegData, false},
+		{"jpeg without extension", "", jpegData, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ForExtension(tt.ext) == Detect(tt.data) && Detect(tt.data) != ""; got != tt.match {
+				t.Fatalf("ForExtension(%q) = %q, Detect = %q; match = %v, want %v", tt.ext, ForExtension(tt.ext), Detect(tt.data), got, tt.match)
+			}
+		})
+	}
+}
@@ -0,0 +1,200 @@
+package filetype
+
+import (
+	"bytes"
+	"encoding/xml"
+	"errors"
+	"io"
+	"regexp"
+	"strings"
+)
+
+var ErrInvalidSVG = errors.New("invalid svg document")
+
+// svgElements are the elements sanitized SVGs keep. Anything else is
+// dropped with its children: scripts, foreignObject (HTML), animations (they
+// can rewrite attributes after sanitizing) and editor-specific elements.
+var svgElements = map[string]bool{}
+
+func init() {
+	for _, name := range strings.Fields(`
+		svg g defs symbol use title desc a switch view
+		path rect circle ellipse line polyline polygon
+		text tspan textPath image style
+		linearGradient radialGradient stop pattern clipPath mask marker
+		filter feBlend feColorMatrix feComponentTransfer feComposite
+		feConvolveMatrix feDiffuseLighting feDisplacementMap feDistantLight
+		feDropShadow feFlood feFuncA feFuncB feFuncG feFuncR feGaussianBlur
+		feImage feMerge feMergeNode feMorphology feOffset fePointLight
+		feSpecularLighting feSpotLight feTile feTurbulence`) {
+		svgElements[name] = true
+	}
+}
+
+// svgNamespaces are the namespace declarations sanitized SVGs keep
+var svgNamespaces = map[string]bool{
+	"http://www.w3.org/2000/svg":   true,
+	"http://www.w3.org/1999/xlink": true,
+}
+
+// dataImage matches embedded raster images, the only non-local reference
+// an image may keep
+var dataImage = regexp.MustCompile(`^data:image/(png|jpeg|gif|webp);base64,[A-Za-z0-9+/=\s]*$`)
+
+// cssURL finds url(...) references in attribute values and style sheets
+var cssURL = regexp.MustCompile(`(?i)url\(\s*['"]?\s*([^'")\s]*)`)
+
+// SanitizeSVG rewrites an SVG keeping only known-safe elements and
+// attributes. Scripts, event handlers, animations and every reference to
+// something outside the document (external hrefs, url(), @import) are
+// removed. Documents that are not well-formed SVG, including those relying
+// on DTD entities, fail with ErrInvalidSVG.
+func SanitizeSVG(data []byte) ([]byte, error) {
+	d := xml.NewDecoder(bytes.NewReader(bytes.TrimPrefix(data, []byte("\xef\xbb\xbf"))))
+	var out bytes.Buffer
+	skip := 0
+	sawRoot := false
+	// RawToken leaves matching end tags to the caller
+	var open []xml.Name
+	// Style sheets are checked whole, as text and CDATA may come in pieces
+	var style *bytes.Buffer
+
+	for {
+		tok, err := d.RawToken()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, ErrInvalidSVG
+		}
+
+		switch t := tok.(type) {
+		case xml.StartElement:
+			if !sawRoot && (t.Name.Local != "svg" || t.Name.Space != "") {
+				return nil, ErrInvalidSVG
+			}
+			sawRoot = true
+			open = append(open, t.Name)
+			if skip > 0 || t.Name.Space != "" || !svgElements[t.Name.Local] {
+				skip++
+				continue
+			}
+			out.WriteString("<" + t.Name.Local)
+			for _, a := range t.Attr {
+				if value, ok := safeAttr(t.Name.Local, a); ok {
+					out.WriteString(" " + attrName(a.Name) + `="`)
+					xml.EscapeText(&out, []byte(value))
+					out.WriteString(`"`)
+				}
+			}
+			out.WriteString(">")
+			if t.Name.Local == "style" {
+				style = &bytes.Buffer{}
+			}
+
+		case xml.EndElement:
+			if len(open) == 0 || open[len(open)-1] != t.Name {
+				return nil, ErrInvalidSVG
+			}
+			open = open[:len(open)-1]
+			if skip > 0 {
+				skip--
+				continue
+			}
+			if style != nil {
+				if safeCSS(style.String()) {
+					xml.EscapeText(&out, style.Bytes())
+				}
+				style = nil
+			}
+			out.WriteString("</" + t.Name.Local + ">")
+			if len(open) == 0 {
+				return out.Bytes(), nil
+			}
+
+		case xml.CharData:
+			if skip > 0 || len(open) == 0 {
+				continue
+			}
+			if style != nil {
+				style.Write(t)
+				continue
+			}
+			xml.EscapeText(&out, t)
+
+		case xml.Comment, xml.ProcInst, xml.Directive:
+			// Dropped, including any DOCTYPE
+		}
+	}
+	return nil, ErrInvalidSVG
+}
+
+// safeAttr returns the value to keep for an attribute, or false to drop it
+func safeAttr(element string, a xml.Attr) (string, bool) {
+	name, value := strings.ToLower(a.Name.Local), strings.TrimSpace(a.Value)
+	lower := strings.ToLower(value)
+
+	switch a.Name.Space {
+	case "":
+		if name == "xmlns" {
+			return value, svgNamespaces[value]
+		}
+	case "xmlns":
+		return value, svgNamespaces[value]
+	case "xlink", "xml":
+	default:
+		// Editor metadata (inkscape:, sodipodi:, ...)
+		return "", false
+	}
+
+	if strings.HasPrefix(name, "on") {
+		return "", false
+	}
+	if strings.Contains(lower, "javascript:") || strings.Contains(lower, "vbscript:") {
+		return "", false
+	}
+	if name == "href" {
+		if strings.HasPrefix(value, "#") {
+			return value, true
+		}
+		if (element == "image" || element == "feImage") && dataImage.MatchString(value) {
+			return value, true
+		}
+		return "", false
+	}
+	if name == "style" && !safeCSS(value) {
+		return "", false
+	}
+	if !localURLs(value) {
+		return "", false
+	}
+	return a.Value, true
+}
+
+// safeCSS rejects style sheets that import or reference external resources.
+// CSS escapes could spell out url( or @import, so any backslash is refused.
+func safeCSS(css string) bool {
+	lower := strings.ToLower(css)
+	if strings.Contains(lower, "\\") || strings.Contains(lower, "@import") || strings.Contains(lower, "expression(") ||
+		strings.Contains(lower, "javascript:") || strings.Contains(lower, "behavior:") {
+		return false
+	}
+	return localURLs(css)
+}
+
+// localURLs reports whether every url() in s points into the document
+func localURLs(s string) bool {
+	for _, m := range cssURL.FindAllStringSubmatch(s, -1) {
+		if !strings.HasPrefix(m[1], "#") {
+			return false
+		}
+	}
+	return true
+}
+
+func attrName(n xml.Name) string {
+	if n.Space != "" {
+		return n.Space + ":" + n.Local
+	}
+	return n.Local
+}
@@ -0,0 +1,172 @@
+package filetype
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+const svgOpen = `<svg xmlns="http://www.w3.org/2000/svg" xmlns:xlink="http://www.w3.org/1999/xlink">`
+
+func TestSanitizeSVG(t *testing.T) {
+	tests := []struct {
+		name string
+		in   string
+		// keep must survive sanitizing, drop must not
+		keep []string
+		drop []string
+	}{
+		{
+			name: "script element",
+			in:   svgOpen + `<script>alert(1)</script><rect width="10"/></svg>`,
+			keep: []string{`<rect width="10">`},
+			drop: []string{"script", "alert"},
+		},
+		{
+			name: "script in CDATA",
+			in:   svgOpen + `<script type="text/javascript"><![CDATA[fetch("//evil")]]></script></svg>`,
+			drop: []string{"script", "fetch"},
+		},
+		{
+			name: "namespaced script",
+			in:   svgOpen + `<h:script xmlns:h="http://www.w3.org/1999/xhtml">alert(1)</h:script></svg>`,
+			drop: []string{"script", "alert", "xhtml"},
+		},
+		{
+			name: "event handlers",
+			in:   `<svg xmlns="http://www.w3.org/2000/svg" onload="alert(1)"><rect ONCLICK="alert(2)" onMouseOver="alert(3)" fill="red"/></svg>`,
+			keep: []string{`fill="red"`},
+			drop: []string{"onload", "ONCLICK", "onMouseOver", "alert"},
+		},
+		{
+			name: "javascript href",
+			in:   svgOpen + `<a href="javascript:alert(1)"><text>a</text></a><a xlink:href=" JaVaScRiPt:alert(2)"><text>b</text></a></svg>`,
+			keep: []string{"<a>", "<text>a</text>", "<text>b</text>"},
+			drop: []string{"javascript", "JaVaScRiPt", "alert"},
+		},
+		{
+			name: "javascript in another attribute",
+			in:   svgOpen + `<rect fill="javascript:alert(1)" width="1"/></svg>`,
+			keep: []string{`width="1"`},
+			drop: []string{"javascript"},
+		},
+		{
+			name: "external hrefs",
+			in:   svgOpen + `<use href="https://evil.example/sprite.svg#icon"/><image xlink:href="/private/photo.jpg"/><image href="data:text/html;base64,PHNjcmlwdD4="/></svg>`,
+			keep: []string{"<use>", "<image>"},
+			drop: []string{"evil.example", "/private/photo.jpg", "data:text/html"},
+		},
+		{
+			name: "local references",
+			in:   svgOpen + `<use href="#icon"/><image href="data:image/png;base64,iVBORw0KGgo="/><rect fill="url(#grad)"/></svg>`,
+			keep: []string{`href="#icon"`, `href="data:image/png;base64,iVBORw0KGgo="`, `fill="url(#grad)"`},
+		},
+		{
+			name: "external url in attributes",
+			in:   svgOpen + `<rect fill="url(https://evil.example/x)" filter="url( '//evil.example/f' )" style="fill: url(http://evil.example/s)" width="2"/></svg>`,
+			keep: []string{`width="2"`},
+			drop: []string{"evil.example", "fill=", "filter=", "style="},
+		},
+		{
+			name: "style sheet with import",
+			in:   svgOpen + `<style>@import url(https://evil.example/x.css); rect { fill: red }</style></svg>`,
+			keep: []string{"<style></style>"},
+			drop: []string{"@import", "evil.example"},
+		},
+		{
+			name: "style sheet with external url",
+			in:   svgOpen + `<style><![CDATA[rect { fill: url(//evil.example/p) }]]></style></svg>`,
+			drop: []string{"evil.example"},
+		},
+		{
+			name: "style sheet with escapes",
+			in:   svgOpen + `<style>rect { background: \75rl(//evil.example/p) }</style></svg>`,
+			drop: []string{"evil.example", `\75`},
+		},
+		{
+			name: "safe style sheet",
+			in:   svgOpen + `<style>rect { fill: url(#grad) } circle { stroke: blue }</style></svg>`,
+			keep: []string{"<style>rect { fill: url(#grad) } circle { stroke: blue }</style>"},
+		},
+		{
+			name: "foreign object",
+			in:   svgOpen + `<foreignObject><div xmlns="http://www.w3.org/1999/xhtml"><iframe src="https://evil.example"/></div></foreignObject><circle r="1"/></svg>`,
+			keep: []string{`<circle r="1">`},
+			drop: []string{"foreignObject", "div", "iframe", "evil.example"},
+		},
+		{
+			name: "foreign namespaces",
+			in:   `<svg xmlns="http://www.w3.org/2000/svg" xmlns:inkscape="http://www.inkscape.org/namespaces/inkscape" xmlns:x="http://evil.example/ns"><inkscape:grid/><rect inkscape:label="layer" x:onload="alert(1)" height="3"/></svg>`,
+			keep: []string{`<rect height="3">`},
+			drop: []string{"inkscape", "evil.example", "label", "alert"},
+		},
+		{
+			name: "animations",
+			in:   svgOpen + `<a><set attributeName="href" to="javascript:alert(1)"/><animate attributeName="href" values="javascript:alert(2)"/><text>x</text></a></svg>`,
+			keep: []string{"<text>x</text>"},
+			drop: []string{"set", "animate", "javascript"},
+		},
+		{
+			name: "comments and processing instructions",
+			in:   `<?xml version="1.0"?><?xml-stylesheet href="https://evil.example/x.css"?>` + svgOpen + `<!-- <script>alert(1)</script> --></svg>`,
+			drop: []string{"xml-stylesheet", "evil.example", "script"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			out, err := SanitizeSVG([]byte(tt.in))
+			if err != nil {
+				t.Fatalf("SanitizeSVG: %v", err)
+			}
+			got := string(out)
+			for _, s := range tt.keep {
+				if !strings.Contains(got, s) {
+					t.Errorf("output lost %q:\n%s", s, got)
+				}
+			}
+			for _, s := range tt.drop {
+				if strings.Contains(got, s) {
+					t.Errorf("output still has %q:\n%s", s, got)
+				}
+			}
+			if Detect(out) != SVG {
+				t.Errorf("output is not detected as SVG:\n%s", got)
+			}
+		})
+	}
+}
+
+func TestSanitizeSVGRejects(t *testing.T) {
+	tests := []struct {
+		name string
+		in   string
+	}{
+		{"internal entity", `<!DOCTYPE svg [<!ENTITY x "hello">]>` + svgOpen + `<text>&x;</text></svg>`},
+		{"external entity", `<!DOCTYPE svg [<!ENTITY x SYSTEM "file:///etc/passwd">]>` + svgOpen + `<text>&x;</text></svg>`},
+		{"entity expansion", `<!DOCTYPE svg [<!ENTITY a "aaaa"><!ENTITY b "&a;&a;&a;&a;">]>` + svgOpen + `<text>&b;</text></svg>`},
+		{"html root", `<html><svg xmlns="http://www.w3.org/2000/svg"/></html>`},
+		{"namespaced root", `<x:svg xmlns:x="http://www.w3.org/2000/svg"/>`},
+		{"unclosed", svgOpen + `<rect>`},
+		{"mismatched tags", svgOpen + `<g></rect></svg>`},
+		{"not xml", `GIF89a`},
+		{"empty", ``},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			out, err := SanitizeSVG([]byte(tt.in))
+			if !errors.Is(err, ErrInvalidSVG) {
+				t.Fatalf("SanitizeSVG = %q, %v; want ErrInvalidSVG", out, err)
+			}
+		})
+	}
+}
+
+func TestSanitizeSVGStopsAtRootEnd(t *testing.T) {
+	out, err := SanitizeSVG([]byte("\xef\xbb\xbf" + svgOpen + `<rect/></svg><script>alert(1)</script>`))
+	if err != nil {
+		t.Fatalf("SanitizeSVG: %v", err)
+	}
+	if strings.Contains(string(out), "script") {
+		t.Fatalf("content after the root survived: %s", out)
+	}
+}
@@ -1,20 +1,43 @@
 package http
 
 import (
+	"crypto/rand"
+	"crypto/sha256"
+	"fmt"
 	"net/http"
 	"strconv"
+	"time"
 	"web-porto-backend/common/response"
 	"web-porto-backend/common/utils"
+	"web-porto-backend/internal/auth"
+	"web-porto-backend/internal/domain/models"
+	"web-porto-backend/internal/services/audit"
 
 	"github.com/gin-gonic/gin"
 )
 
 // HTTPAdapter handles HTTP-specific concerns
-type HTTPAdapter struct{}
+type HTTPAdapter struct {
+	// cursorKey signs pagination cursors
+	cursorKey []byte
+}
 
-// NewHTTPAdapter creates a new HTTP adapter
+// NewHTTPAdapter creates a new HTTP adapter. Cursors it signs are only valid
+// for this process until WithCursorSecret sets a shared key.
 func NewHTTPAdapter() *HTTPAdapter {
-	return &HTTPAdapter{}
+	key := make([]byte, 32)
+	if _, err := rand.Read(key); err != nil {
+		panic(fmt.Sprintf("Failed to generate cursor key: %v", err))
+	}
+	return &HTTPAdapter{cursorKey: key}
+}
+
+// WithCursorSecret derives the cursor signing key from secret, so cursors
+// survive restarts and work across replicas
+func (h *HTTPAdapter) WithCursorSecret(secret string) *HTTPAdapter {
+	sum := sha256.Sum256([]byte("pagination-cursor:" + secret))
+	h.cursorKey = sum[:]
+	return h
 }
 
 // SendSuccessResponse sends a successful response
@@ -70,3 +93,57 @@ func (h *HTTPAdapter) GetUserContext(c *gin.Context) (userID interface{}, userna
 	role = c.GetString("role")
 	return
 }
+
+// HasPermission reports whether the authenticated user holds the given permission
+func (h *HTTPAdapter) HasPermission(c *gin.Context, permission string) bool {
+	return auth.HasPermission(c.GetStringSlice("permissions"), permission)
+}
+
+// AuthorizePublish rejects with 403 when status moves content to "published"
+// (now or scheduled) and the user lacks the given publish permission.
+// Returns false if rejected.
+func (h *HTTPAdapter) AuthorizePublish(c *gin.Context, status string, permission string) bool {
+	if (status != "published" && status != "scheduled") || h.HasPermission(c, permission) {
+		return true
+	}
+	c.JSON(http.StatusForbidden, response.NewErrorResponse("Insufficient permissions", "missing permission "+permission))
+	return false
+}
+
+// StatusFilter resolves the ?status= (or ?filter[status]=) query of a public
+// content listing. Without it the listing shows what visitors may see
+// (models.StatusPublished); any other status, or "all", requires the given
+// permission. Returns false after responding when the filter is invalid or not
+// allowed.
+func (h *HTTPAdapter) StatusFilter(c *gin.Context, permission string) (string, bool) {
+	status := c.DefaultQuery("filter[status]", c.DefaultQuery("status", models.StatusPublished))
+	switch status {
+	case models.StatusPublished:
+		return status, true
+	case models.StatusDraft, models.StatusScheduled, models.StatusPrivate, models.StatusAll:
+	default:
+		c.JSON(http.StatusBadRequest, response.NewErrorResponse("Invalid status filter", "status must be one of draft, published, scheduled, private, all"))
+		return "", false
+	}
+	if !h.HasPermission(c, permission) {
+		c.JSON(http.StatusForbidden, response.NewErrorResponse("Insufficient permissions", "missing permission "+permission))
+		return "", false
+	}
+	return status, true
+}
+
+// CanView reports whether the request may see a single content item: anyone
+// sees published content whose publish date has passed, anything else needs
+// the given permission
+func (h *HTTPAdapter) CanView(c *gin.Context, permission string, status string, publishedAt *time.Time) bool {
+	return models.IsPubliclyVisible(status, publishedAt) || h.HasPermission(c, permission)
+}
+
+// AuditActor returns who is making the request, for audit log entries
+func (h *HTTPAdapter) AuditActor(c *gin.Context) audit.Actor {
+	return audit.Actor{
+		UserID:    c.GetInt("user_id"),
+		IP:        c.ClientIP(),
+		UserAgent: c.Request.UserAgent(),
+	}
+}
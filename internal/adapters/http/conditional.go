@@ -0,0 +1,50 @@
+package http
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// SendCacheable writes a generated document with an ETag, Last-Modified and
+// a public Cache-Control of maxAge, answering conditional requests with 304.
+// A zero updated time omits Last-Modified.
+func SendCacheable(c *gin.Context, contentType string, body []byte, updated time.Time, maxAge time.Duration) {
+	sum := sha256.Sum256(body)
+	etag := `"` + hex.EncodeToString(sum[:16]) + `"`
+	c.Header("ETag", etag)
+	c.Header("Cache-Control", fmt.Sprintf("public, max-age=%d", int(maxAge.Seconds())))
+	if !updated.IsZero() {
+		c.Header("Last-Modified", updated.UTC().Format(http.TimeFormat))
+	}
+	if notModified(c.Request, etag, updated) {
+		c.Status(http.StatusNotModified)
+		return
+	}
+	c.Data(http.StatusOK, contentType, body)
+}
+
+// notModified evaluates If-None-Match, falling back to If-Modified-Since
+// when the client sent no entity tag (RFC 9110 section 13.2.2)
+func notModified(r *http.Request, etag string, updated time.Time) bool {
+	if inm := r.Header.Get("If-None-Match"); inm != "" {
+		for _, candidate := range strings.Split(inm, ",") {
+			candidate = strings.TrimPrefix(strings.TrimSpace(candidate), "W/")
+			if candidate == etag || candidate == "*" {
+				return true
+			}
+		}
+		return false
+	}
+	if ims := r.Header.Get("If-Modified-Since"); ims != "" && !updated.IsZero() {
+		if t, err := http.ParseTime(ims); err == nil {
+			return !updated.Truncate(time.Second).After(t)
+		}
+	}
+	return false
+}
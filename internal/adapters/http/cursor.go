@@ -0,0 +1,109 @@
+package http
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+	"web-porto-backend/common/response"
+	"web-porto-backend/common/utils"
+	"web-porto-backend/internal/domain/dto"
+
+	"github.com/gin-gonic/gin"
+)
+
+// errInvalidCursor hides why a cursor was rejected
+var errInvalidCursor = errors.New("cursor is malformed or was not issued by this API")
+
+// cursorPayload is the signed body of a cursor
+type cursorPayload struct {
+	Time     int64 `json:"t"`
+	ID       int64 `json:"i"`
+	Backward bool  `json:"b,omitempty"`
+}
+
+// UsesCursor reports whether a list request asked for cursor pagination
+// (?cursor=, empty for the first page) instead of ?page=
+func (h *HTTPAdapter) UsesCursor(c *gin.Context) bool {
+	_, ok := c.GetQuery("cursor")
+	return ok
+}
+
+// CursorQuery reads ?cursor= and ?limit= of a cursor paginated list. Returns
+// false after responding 400 when the cursor fails verification.
+func (h *HTTPAdapter) CursorQuery(c *gin.Context) (dto.CursorQuery, bool) {
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "10"))
+	q := dto.CursorQuery{Limit: utils.NewPagination(1, limit).Limit}
+	if raw := c.Query("cursor"); raw != "" {
+		cursor, err := h.DecodeCursor(raw)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, response.NewErrorResponse("Invalid cursor", err.Error()))
+			return q, false
+		}
+		q.Cursor = cursor
+	}
+	return q, true
+}
+
+// CursorPagination builds the pagination block of a cursor paginated list
+func (h *HTTPAdapter) CursorPagination(q dto.CursorQuery, result dto.CursorResult) dto.PaginationResponse {
+	p := dto.PaginationResponse{
+		PageSize:    q.Limit,
+		HasNext:     result.Next != nil,
+		HasPrevious: result.Prev != nil,
+	}
+	if result.Next != nil {
+		p.NextCursor = h.EncodeCursor(*result.Next)
+	}
+	if result.Prev != nil {
+		p.PrevCursor = h.EncodeCursor(*result.Prev)
+	}
+	return p
+}
+
+// EncodeCursor returns the opaque, signed form of a cursor
+func (h *HTTPAdapter) EncodeCursor(cursor dto.Cursor) string {
+	body, _ := json.Marshal(cursorPayload{
+		Time:     cursor.CreatedAt.UnixMicro(),
+		ID:       cursor.ID,
+		Backward: cursor.Backward,
+	})
+	encoded := base64.RawURLEncoding.EncodeToString(body)
+	return encoded + "." + base64.RawURLEncoding.EncodeToString(h.signCursor(encoded))
+}
+
+// DecodeCursor verifies and decodes a cursor made by EncodeCursor
+func (h *HTTPAdapter) DecodeCursor(raw string) (*dto.Cursor, error) {
+	encoded, sig, ok := strings.Cut(raw, ".")
+	if !ok {
+		return nil, errInvalidCursor
+	}
+	given, err := base64.RawURLEncoding.DecodeString(sig)
+	if err != nil || !hmac.Equal(given, h.signCursor(encoded)) {
+		return nil, errInvalidCursor
+	}
+	body, err := base64.RawURLEncoding.DecodeString(encoded)
+	if err != nil {
+		return nil, errInvalidCursor
+	}
+	var payload cursorPayload
+	if err := json.Unmarshal(body, &payload); err != nil {
+		return nil, errInvalidCursor
+	}
+	return &dto.Cursor{
+		CreatedAt: time.UnixMicro(payload.Time).UTC(),
+		ID:        payload.ID,
+		Backward:  payload.Backward,
+	}, nil
+}
+
+func (h *HTTPAdapter) signCursor(encoded string) []byte {
+	mac := hmac.New(sha256.New, h.cursorKey)
+	mac.Write([]byte(encoded))
+	return mac.Sum(nil)[:16]
+}
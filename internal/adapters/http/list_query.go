@@ -0,0 +1,194 @@
+package http
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"reflect"
+	"regexp"
+	"strings"
+	"web-porto-backend/common/response"
+	"web-porto-backend/common/utils"
+	"web-porto-backend/internal/domain/dto"
+
+	"github.com/gin-gonic/gin"
+)
+
+// fieldName is the shape of every API field name a list query may mention
+var fieldName = regexp.MustCompile(`^[a-zA-Z][a-zA-Z0-9]{0,63}$`)
+
+// filterKey matches filter[name] and filter[name][from|to]
+var filterKey = regexp.MustCompile(`^filter\[([^\]]*)\](?:\[([^\]]*)\])?$`)
+
+// ListQuery parses the sort=, filter[...]= and fields= parameters of a list
+// endpoint (see dto.ListQuery). Returns false after responding 400 when they
+// are malformed; whether a field may be used is up to the repository.
+func (h *HTTPAdapter) ListQuery(c *gin.Context) (dto.ListQuery, bool) {
+	q, err := ParseListQuery(c.Request.URL.Query())
+	if err != nil {
+		c.JSON(http.StatusBadRequest, response.NewErrorResponse("Invalid list query", err.Error()))
+		return dto.ListQuery{}, false
+	}
+	return q, true
+}
+
+// ParseListQuery parses list query parameters from a query string
+func ParseListQuery(values url.Values) (dto.ListQuery, error) {
+	q := dto.ListQuery{
+		Filters: map[string][]string{},
+		Ranges:  map[string]dto.DateRange{},
+	}
+
+	for _, name := range splitList(values["sort"]) {
+		field := dto.SortField{Field: name}
+		if strings.HasPrefix(name, "-") {
+			field = dto.SortField{Field: name[1:], Desc: true}
+		} else if strings.HasPrefix(name, "+") {
+			field.Field = name[1:]
+		}
+		if !fieldName.MatchString(field.Field) {
+			return q, fmt.Errorf("invalid sort field %q", name)
+		}
+		q.Sort = append(q.Sort, field)
+	}
+
+	for _, name := range splitList(values["fields"]) {
+		if !fieldName.MatchString(name) {
+			return q, fmt.Errorf("invalid field %q", name)
+		}
+		q.Fields = append(q.Fields, name)
+	}
+
+	for key, vals := range values {
+		if !strings.HasPrefix(key, "filter[") {
+			continue
+		}
+		m := filterKey.FindStringSubmatch(key)
+		if m == nil || !fieldName.MatchString(m[1]) {
+			return q, fmt.Errorf("invalid filter %q", key)
+		}
+		name, bound := m[1], m[2]
+		if bound == "" {
+			if list := splitList(vals); len(list) > 0 {
+				q.Filters[name] = append(q.Filters[name], list...)
+			}
+			continue
+		}
+
+		value := strings.TrimSpace(vals[len(vals)-1])
+		if value == "" {
+			continue
+		}
+		rng := q.Ranges[name]
+		switch bound {
+		case "from":
+			t, err := utils.ParseDate(value, false)
+			if err != nil {
+				return q, fmt.Errorf("invalid date in %s: use YYYY-MM-DD or RFC3339", key)
+			}
+			rng.From = &t
+		case "to":
+			t, err := utils.ParseDate(value, true)
+			if err != nil {
+				return q, fmt.Errorf("invalid date in %s: use YYYY-MM-DD or RFC3339", key)
+			}
+			rng.To = &t
+		default:
+			return q, fmt.Errorf("invalid filter %q: date bounds are [from] and [to]", key)
+		}
+		q.Ranges[name] = rng
+	}
+
+	return q, nil
+}
+
+// splitList flattens repeated and comma separated values, dropping blanks
+func splitList(vals []string) []string {
+	var out []string
+	for _, v := range vals {
+		for _, item := range strings.Split(v, ",") {
+			if item = strings.TrimSpace(item); item != "" {
+				out = append(out, item)
+			}
+		}
+	}
+	return out
+}
+
+// SelectFields trims each item of a list response to the given JSON fields
+// (a sparse fieldset); "id" is always kept. Fields must exist on the item
+// type. Without fields the items are returned untouched.
+func SelectFields(items interface{}, fields []string) (interface{}, error) {
+	v := reflect.ValueOf(items)
+	if len(fields) == 0 || v.Kind() != reflect.Slice || v.Len() == 0 {
+		return items, nil
+	}
+
+	known := jsonFields(v.Index(0))
+	keep := map[string]bool{"id": true}
+	for _, f := range fields {
+		if !known[f] {
+			return nil, fmt.Errorf("%w: unknown field %q", dto.ErrInvalidListQuery, f)
+		}
+		keep[f] = true
+	}
+
+	out := make([]map[string]json.RawMessage, 0, v.Len())
+	for i := 0; i < v.Len(); i++ {
+		raw, err := json.Marshal(v.Index(i).Interface())
+		if err != nil {
+			return nil, err
+		}
+		var all map[string]json.RawMessage
+		if err := json.Unmarshal(raw, &all); err != nil {
+			return nil, err
+		}
+		item := make(map[string]json.RawMessage, len(keep))
+		for name, value := range all {
+			if keep[name] {
+				item[name] = value
+			}
+		}
+		out = append(out, item)
+	}
+	return out, nil
+}
+
+// jsonFields lists the JSON names of a struct value's exported fields
+func jsonFields(v reflect.Value) map[string]bool {
+	for v.Kind() == reflect.Interface || v.Kind() == reflect.Ptr {
+		v = v.Elem()
+	}
+	names := map[string]bool{}
+	if v.Kind() != reflect.Struct {
+		return names
+	}
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if f.PkgPath != "" {
+			continue
+		}
+		name := strings.Split(f.Tag.Get("json"), ",")[0]
+		switch name {
+		case "-":
+			continue
+		case "":
+			name = f.Name
+		}
+		names[name] = true
+	}
+	return names
+}
+
+// ListQueryError responds 400 and returns true when err rejects a list query
+// field the endpoint does not support
+func (h *HTTPAdapter) ListQueryError(c *gin.Context, err error) bool {
+	if !errors.Is(err, dto.ErrInvalidListQuery) {
+		return false
+	}
+	c.JSON(http.StatusBadRequest, response.NewErrorResponse("Invalid list query", err.Error()))
+	return true
+}
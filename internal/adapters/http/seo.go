@@ -0,0 +1,19 @@
+package http
+
+import (
+	"net/http"
+	"web-porto-backend/common/response"
+	"web-porto-backend/internal/domain/dto"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ValidSEO validates the SEO block of a create or update request. Returns
+// false after responding 400 when it is invalid.
+func (h *HTTPAdapter) ValidSEO(c *gin.Context, seo *dto.SEO) bool {
+	if err := seo.Validate(); err != nil {
+		c.JSON(http.StatusBadRequest, response.NewErrorResponse("Invalid SEO metadata", err.Error()))
+		return false
+	}
+	return true
+}
@@ -0,0 +1,93 @@
+// Package imaging decodes, orients, resizes and re-encodes uploaded images
+// using the standard library only, plus a lossless WebP encoder.
+package imaging
+
+import (
+	"bytes"
+	"errors"
+	"image"
+	"image/draw"
+	_ "image/gif" // GIF sources are probed for their dimensions
+	"image/jpeg"
+	"image/png"
+	"io"
+)
+
+// Supported source formats, as reported by image.DecodeConfig
+const (
+	FormatJPEG = "jpeg"
+	FormatPNG  = "png"
+	FormatGIF  = "gif"
+)
+
+var (
+	ErrUnsupportedFormat = errors.New("unsupported image format")
+	ErrTooLarge          = errors.New("image has too many pixels")
+)
+
+// Info describes an image as it is displayed, i.e. after EXIF orientation
+type Info struct {
+	Format      string
+	Width       int
+	Height      int
+	Orientation int
+}
+
+// Probe reads the format and displayed dimensions without decoding pixels
+func Probe(data []byte) (Info, error) {
+	cfg, format, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return Info{}, err
+	}
+	if format != FormatJPEG && format != FormatPNG && format != FormatGIF {
+		return Info{}, ErrUnsupportedFormat
+	}
+	info := Info{Format: format, Width: cfg.Width, Height: cfg.Height, Orientation: 1}
+	if format == FormatJPEG {
+		info.Orientation = Orientation(data)
+		if info.Orientation >= 5 {
+			info.Width, info.Height = info.Height, info.Width
+		}
+	}
+	return info, nil
+}
+
+// Decode decodes data and applies its EXIF orientation. Images with more than
+// maxPixels pixels are refused before decoding (0 disables the check).
+func Decode(data []byte, maxPixels int) (*image.RGBA, Info, error) {
+	info, err := Probe(data)
+	if err != nil {
+		return nil, info, err
+	}
+	if maxPixels > 0 && info.Width*info.Height > maxPixels {
+		return nil, info, ErrTooLarge
+	}
+
+	src, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, info, err
+	}
+	return Orient(toRGBA(src), info.Orientation), info, nil
+}
+
+// EncodeJPEG writes img as a baseline JPEG
+func EncodeJPEG(w io.Writer, img image.Image, quality int) error {
+	return jpeg.Encode(w, img, &jpeg.Options{Quality: quality})
+}
+
+// EncodePNG writes img as a PNG at the best compression level
+func EncodePNG(w io.Writer, img image.Image) error {
+	enc := png.Encoder{CompressionLevel: png.BestCompression}
+	return enc.Encode(w, img)
+}
+
+// toRGBA converts any decoded image to premultiplied RGBA at origin 0,0
+func toRGBA(src image.Image) *image.RGBA {
+	if rgba, ok := src.(*image.RGBA); ok && rgba.Rect.Min == (image.Point{}) {
+		return rgba
+	}
+	b := src.Bounds()
+	dst := image.NewRGBA(image.Rect(0, 0, b.Dx(), b.Dy()))
+	draw.Draw(dst, dst.Rect, src, b.Min, draw.Src)
+	return dst
+}
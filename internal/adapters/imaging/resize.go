@@ -0,0 +1,111 @@
+package imaging
+
+import "image"
+
+// contrib is the share of one source row or column in a target pixel
+type contrib struct {
+	index  int
+	weight float32
+}
+
+// FitWidth returns the height matching width for an image of w x h
+func FitWidth(w, h, width int) int {
+	height := (h*width + w/2) / w
+	if height < 1 {
+		height = 1
+	}
+	return height
+}
+
+// Resize scales src to w x h by area averaging, which keeps downscaled photos
+// free of aliasing. Works on premultiplied pixels so transparent edges do not
+// darken.
+func Resize(src *image.RGBA, w, h int) *image.RGBA {
+	sw, sh := src.Rect.Dx(), src.Rect.Dy()
+	dst := image.NewRGBA(image.Rect(0, 0, w, h))
+	if sw == 0 || sh == 0 || w == 0 || h == 0 {
+		return dst
+	}
+	cols := contributions(sw, w)
+	rows := contributions(sh, h)
+
+	line := make([]float32, w*4)
+	acc := make([]float32, w*4)
+	lastRow := -1
+	for y := 0; y < h; y++ {
+		for i := range acc {
+			acc[i] = 0
+		}
+		for _, r := range rows[y] {
+			if r.index != lastRow {
+				resizeLine(src, r.index, cols, line)
+				lastRow = r.index
+			}
+			for i, v := range line {
+				acc[i] += v * r.weight
+			}
+		}
+		out := dst.Pix[y*dst.Stride : y*dst.Stride+w*4]
+		for i, v := range acc {
+			out[i] = clamp8(v)
+		}
+	}
+	return dst
+}
+
+// resizeLine scales source row y horizontally into line
+func resizeLine(src *image.RGBA, y int, cols [][]contrib, line []float32) {
+	row := src.Pix[y*src.Stride:]
+	for x, cs := range cols {
+		var r, g, b, a float32
+		for _, c := range cs {
+			p := row[c.index*4 : c.index*4+4]
+			r += float32(p[0]) * c.weight
+			g += float32(p[1]) * c.weight
+			b += float32(p[2]) * c.weight
+			a += float32(p[3]) * c.weight
+		}
+		line[x*4], line[x*4+1], line[x*4+2], line[x*4+3] = r, g, b, a
+	}
+}
+
+// contributions maps each of the n target pixels to the source pixels it
+// covers out of size, weighted by overlap and normalized to 1
+func contributions(size, n int) [][]contrib {
+	scale := float64(size) / float64(n)
+	out := make([][]contrib, n)
+	for i := range out {
+		start, end := float64(i)*scale, float64(i+1)*scale
+		var cs []contrib
+		var total float64
+		for j := int(start); j < size && float64(j) < end; j++ {
+			lo, hi := float64(j), float64(j+1)
+			if lo < start {
+				lo = start
+			}
+			if hi > end {
+				hi = end
+			}
+			if hi <= lo {
+				continue
+			}
+			cs = append(cs, contrib{index: j, weight: float32(hi - lo)})
+			total += hi - lo
+		}
+		for k := range cs {
+			cs[k].weight /= float32(total)
+		}
+		out[i] = cs
+	}
+	return out
+}
+
+func clamp8(v float32) uint8 {
+	switch {
+	case v <= 0:
+		return 0
+	case v >= 255:
+		return 255
+	}
+	return uint8(v + 0.5)
+}
@@ -0,0 +1,159 @@
+package imaging
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+)
+
+var errMalformed = errors.New("malformed image data")
+
+var pngSignature = []byte("\x89PNG\r\n\x1a\n")
+
+// StripMetadata removes EXIF (including GPS), XMP, IPTC and comments from a
+// JPEG or PNG without re-encoding it. Color profiles are kept, and so is the
+// orientation of a rotated JPEG, in an Exif segment of its own. Other formats
+// are returned unchanged.
+func StripMetadata(data []byte) ([]byte, error) {
+	switch {
+	case len(data) > 2 && data[0] == 0xff && data[1] == 0xd8:
+		return stripJPEG(data)
+	case bytes.HasPrefix(data, pngSignature):
+		return stripPNG(data)
+	}
+	return data, nil
+}
+
+type jpegSegment struct {
+	marker  byte
+	start   int // offset of the 0xff of the marker
+	end     int // offset just past the segment
+	payload []byte
+}
+
+// jpegSegments lists the header segments of a JPEG up to (not including) the
+// start of scan
+func jpegSegments(data []byte) []jpegSegment {
+	var segs []jpegSegment
+	if len(data) < 4 || data[0] != 0xff || data[1] != 0xd8 {
+		return nil
+	}
+	pos := 2
+	for pos+4 <= len(data) {
+		if data[pos] != 0xff {
+			return segs
+		}
+		marker := data[pos+1]
+		if marker == 0xff {
+			// Fill byte before a marker
+			pos++
+			continue
+		}
+		if marker == 0xda || marker == 0xd9 {
+			return segs
+		}
+		length := int(binary.BigEndian.Uint16(data[pos+2:]))
+		if length < 2 || pos+2+length > len(data) {
+			return segs
+		}
+		segs = append(segs, jpegSegment{
+			marker:  marker,
+			start:   pos,
+			end:     pos + 2 + length,
+			payload: data[pos+4 : pos+2+length],
+		})
+		pos += 2 + length
+	}
+	return segs
+}
+
+// keepJPEGSegment keeps everything but application data and comments; of the
+// APPn segments JFIF (APP0), ICC profiles (APP2) and Adobe color info (APP14)
+// stay because decoders need them to render colors correctly
+func keepJPEGSegment(seg jpegSegment) bool {
+	switch {
+	case seg.marker == 0xe0, seg.marker == 0xee:
+		return true
+	case seg.marker == 0xe2:
+		return bytes.HasPrefix(seg.payload, []byte("ICC_PROFILE\x00"))
+	case seg.marker >= 0xe1 && seg.marker <= 0xef, seg.marker == 0xfe:
+		return false
+	}
+	return true
+}
+
+func stripJPEG(data []byte) ([]byte, error) {
+	segs := jpegSegments(data)
+	if len(segs) == 0 {
+		return nil, errMalformed
+	}
+	rest := segs[len(segs)-1].end
+	// Skip fill bytes so rest points at the SOS marker
+	for rest+1 < len(data) && data[rest] == 0xff && data[rest+1] == 0xff {
+		rest++
+	}
+	if rest+1 >= len(data) || data[rest] != 0xff || data[rest+1] != 0xda {
+		return nil, errMalformed
+	}
+
+	orientation := Orientation(data)
+	out := make([]byte, 0, len(data))
+	out = append(out, 0xff, 0xd8)
+	for i, seg := range segs {
+		// JFIF has to stay the first segment
+		if orientation != 1 && (i > 0 || seg.marker != 0xe0) {
+			out = append(out, orientationSegment(orientation)...)
+			orientation = 1
+		}
+		if keepJPEGSegment(seg) {
+			out = append(out, data[seg.start:seg.end]...)
+		}
+	}
+	return append(out, data[rest:]...), nil
+}
+
+// orientationSegment is an APP1 Exif segment holding only the orientation tag
+func orientationSegment(orientation int) []byte {
+	return []byte{
+		0xff, 0xe1, 0x00, 0x22,
+		'E', 'x', 'i', 'f', 0x00, 0x00,
+		'M', 'M', 0x00, '*', 0x00, 0x00, 0x00, 0x08,
+		0x00, 0x01, // one entry
+		0x01, 0x12, 0x00, 0x03, 0x00, 0x00, 0x00, 0x01, 0x00, byte(orientation), 0x00, 0x00,
+		0x00, 0x00, 0x00, 0x00, // no next IFD
+	}
+}
+
+// pngMetadataChunks are the ancillary chunks dropped from PNGs
+var pngMetadataChunks = map[string]bool{
+	"tEXt": true,
+	"zTXt": true,
+	"iTXt": true,
+	"eXIf": true,
+	"tIME": true,
+}
+
+func stripPNG(data []byte) ([]byte, error) {
+	out := make([]byte, 0, len(data))
+	out = append(out, pngSignature...)
+	pos := len(pngSignature)
+	for pos < len(data) {
+		if pos+12 > len(data) {
+			return nil, errMalformed
+		}
+		length := int(binary.BigEndian.Uint32(data[pos:]))
+		end := pos + 12 + length
+		if length < 0 || end > len(data) {
+			return nil, errMalformed
+		}
+		chunk := string(data[pos+4 : pos+8])
+		if !pngMetadataChunks[chunk] {
+			out = append(out, data[pos:end]...)
+		}
+		pos = end
+		if chunk == "IEND" {
+			break
+		}
+	}
+	return out, nil
+}
@@ -0,0 +1,537 @@
+package imaging
+
+import (
+	"encoding/binary"
+	"errors"
+	"image"
+	"io"
+	"sort"
+)
+
+// WebP output is lossless (VP8L). It uses the subtract-green and predictor
+// transforms and run-length backward references; no color cache and a single
+// set of prefix codes. That is enough to beat PNG on most graphics and
+// screenshots but usually not JPEG on photos, so callers should keep the
+// smaller of the two.
+
+const (
+	vp8lMaxDimension = 1 << 14
+	// predictorBits is log2 of the block size of the predictor transform;
+	// every block uses the same mode so the largest size is the cheapest
+	predictorBits = 9
+	// predictorMode 7 predicts each pixel as the average of left and top
+	predictorMode = 7
+
+	numLiteralCodes  = 256
+	numLengthCodes   = 24
+	numDistanceCodes = 40
+	maxRunLength     = 4096
+	minRunLength     = 3
+	// distance codes 1 and 2 are the pixel above and the pixel to the left
+	distanceCodeUp   = 1
+	distanceCodeLeft = 2
+
+	maxCodeLength       = 15
+	maxCodeLengthLength = 7
+)
+
+var errWebPTooLarge = errors.New("image too large for WebP")
+
+// codeLengthOrder is the order code length code lengths are written in
+var codeLengthOrder = [19]int{17, 18, 0, 1, 2, 3, 4, 5, 16, 6, 7, 8, 9, 10, 11, 12, 13, 14, 15}
+
+// EncodeWebP writes img as a lossless WebP
+func EncodeWebP(w io.Writer, img *image.RGBA) error {
+	width, height := img.Rect.Dx(), img.Rect.Dy()
+	if width < 1 || height < 1 || width > vp8lMaxDimension || height > vp8lMaxDimension {
+		return errWebPTooLarge
+	}
+
+	argb := make([]uint32, width*height)
+	hasAlpha := false
+	for y := 0; y < height; y++ {
+		row := img.Pix[y*img.Stride:]
+		for x := 0; x < width; x++ {
+			r, g, b, a := unpremultiply(row[x*4], row[x*4+1], row[x*4+2], row[x*4+3])
+			if a != 0xff {
+				hasAlpha = true
+			}
+			argb[y*width+x] = uint32(a)<<24 | uint32(r)<<16 | uint32(g)<<8 | uint32(b)
+		}
+	}
+
+	bw := &bitWriter{}
+	bw.write(0x2f, 8)
+	bw.write(uint32(width-1), 14)
+	bw.write(uint32(height-1), 14)
+	if hasAlpha {
+		bw.write(1, 1)
+	} else {
+		bw.write(0, 1)
+	}
+	bw.write(0, 3) // version
+
+	// Subtract green
+	bw.write(1, 1)
+	bw.write(2, 2)
+	subtractGreen(argb)
+
+	// Predictor, one mode for all blocks
+	bw.write(1, 1)
+	bw.write(0, 2)
+	bw.write(predictorBits-2, 3)
+	blocksX := (width + (1 << predictorBits) - 1) >> predictorBits
+	blocksY := (height + (1 << predictorBits) - 1) >> predictorBits
+	modes := make([]uint32, blocksX*blocksY)
+	for i := range modes {
+		modes[i] = 0xff000000 | predictorMode<<8
+	}
+	writeEntropyImage(bw, modes, blocksX, false)
+	predict(argb, width, height)
+
+	bw.write(0, 1) // no more transforms
+	writeEntropyImage(bw, argb, width, true)
+	data := bw.bytes()
+
+	chunk := len(data) + len(data)&1
+	header := make([]byte, 20)
+	copy(header, "RIFF")
+	binary.LittleEndian.PutUint32(header[4:], uint32(4+8+chunk))
+	copy(header[8:], "WEBPVP8L")
+	binary.LittleEndian.PutUint32(header[16:], uint32(len(data)))
+	if _, err := w.Write(header); err != nil {
+		return err
+	}
+	if len(data)&1 == 1 {
+		data = append(data, 0)
+	}
+	_, err := w.Write(data)
+	return err
+}
+
+func unpremultiply(r, g, b, a uint8) (uint8, uint8, uint8, uint8) {
+	switch a {
+	case 0xff:
+		return r, g, b, a
+	case 0:
+		return 0, 0, 0, 0
+	}
+	un := func(c uint8) uint8 {
+		v := (uint32(c)*0xff + uint32(a)/2) / uint32(a)
+		if v > 0xff {
+			v = 0xff
+		}
+		return uint8(v)
+	}
+	return un(r), un(g), un(b), a
+}
+
+func subtractGreen(argb []uint32) {
+	for i, p := range argb {
+		g := (p >> 8) & 0xff
+		r := ((p >> 16) - g) & 0xff
+		b := (p - g) & 0xff
+		argb[i] = p&0xff00ff00 | r<<16 | b
+	}
+}
+
+// predict replaces every pixel by its residual from the predictor transform,
+// working backwards so predictions still see the original neighbours
+func predict(argb []uint32, width, height int) {
+	for y := height - 1; y >= 0; y-- {
+		for x := width - 1; x >= 0; x-- {
+			i := y*width + x
+			var pred uint32
+			switch {
+			case x == 0 && y == 0:
+				pred = 0xff000000
+			case y == 0:
+				pred = argb[i-1]
+			case x == 0:
+				pred = argb[i-width]
+			default:
+				pred = average2(argb[i-1], argb[i-width])
+			}
+			argb[i] = subPixels(argb[i], pred)
+		}
+	}
+}
+
+func average2(a, b uint32) uint32 {
+	return (((a ^ b) & 0xfefefefe) >> 1) + (a & b)
+}
+
+// subPixels subtracts b from a per channel, modulo 256
+func subPixels(a, b uint32) uint32 {
+	var out uint32
+	for shift := uint(0); shift < 32; shift += 8 {
+		out |= ((a>>shift - b>>shift) & 0xff) << shift
+	}
+	return out
+}
+
+// token is a literal pixel or a backward reference; kept small as there
+// can be one per pixel
+type token struct {
+	pixel    uint32
+	length   uint16 // 0 for literals
+	distance uint8  // distance code
+}
+
+// writeEntropyImage writes an entropy-coded image. The main image carries a
+// bit for meta prefix codes; transform sub-images do not.
+func writeEntropyImage(bw *bitWriter, argb []uint32, width int, main bool) {
+	tokens := tokenize(argb, width)
+
+	green := make([]uint32, numLiteralCodes+numLengthCodes)
+	red := make([]uint32, numLiteralCodes)
+	blue := make([]uint32, numLiteralCodes)
+	alpha := make([]uint32, numLiteralCodes)
+	dist := make([]uint32, numDistanceCodes)
+	for _, t := range tokens {
+		if t.length == 0 {
+			green[(t.pixel>>8)&0xff]++
+			red[(t.pixel>>16)&0xff]++
+			blue[t.pixel&0xff]++
+			alpha[t.pixel>>24]++
+			continue
+		}
+		sym, _, _ := prefixEncode(int(t.length))
+		green[numLiteralCodes+sym]++
+		sym, _, _ = prefixEncode(int(t.distance))
+		dist[sym]++
+	}
+
+	bw.write(0, 1) // no color cache
+	if main {
+		bw.write(0, 1) // no meta prefix codes
+	}
+	codes := [5]*prefixCode{}
+	for i, histogram := range [][]uint32{green, red, blue, alpha, dist} {
+		codes[i] = newPrefixCode(histogram, maxCodeLength)
+		codes[i].writeHeader(bw)
+	}
+
+	for _, t := range tokens {
+		if t.length == 0 {
+			codes[0].writeSymbol(bw, int(t.pixel>>8)&0xff)
+			codes[1].writeSymbol(bw, int(t.pixel>>16)&0xff)
+			codes[2].writeSymbol(bw, int(t.pixel)&0xff)
+			codes[3].writeSymbol(bw, int(t.pixel>>24))
+			continue
+		}
+		sym, extraBits, extra := prefixEncode(int(t.length))
+		codes[0].writeSymbol(bw, numLiteralCodes+sym)
+		bw.write(extra, extraBits)
+		sym, extraBits, extra = prefixEncode(int(t.distance))
+		codes[4].writeSymbol(bw, sym)
+		bw.write(extra, extraBits)
+	}
+}
+
+// tokenize turns pixels into literals and runs copied from the pixel to the
+// left or the row above, whichever is longer
+func tokenize(argb []uint32, width int) []token {
+	tokens := make([]token, 0, len(argb)/2)
+	for i := 0; i < len(argb); {
+		left, up := 0, 0
+		if i > 0 {
+			left = matchLength(argb, i, 1)
+		}
+		if i >= width {
+			up = matchLength(argb, i, width)
+		}
+		switch {
+		case up >= minRunLength && up >= left:
+			tokens = append(tokens, token{length: uint16(up), distance: distanceCodeUp})
+			i += up
+		case left >= minRunLength:
+			tokens = append(tokens, token{length: uint16(left), distance: distanceCodeLeft})
+			i += left
+		default:
+			tokens = append(tokens, token{pixel: argb[i]})
+			i++
+		}
+	}
+	return tokens
+}
+
+func matchLength(argb []uint32, i, distance int) int {
+	n := 0
+	for i+n < len(argb) && n < maxRunLength && argb[i+n] == argb[i+n-distance] {
+		n++
+	}
+	return n
+}
+
+// prefixEncode splits a length or distance code (>= 1) into a prefix symbol
+// and extra bits
+func prefixEncode(v int) (symbol int, extraBits uint, extra uint32) {
+	d := v - 1
+	if d < 4 {
+		return d, 0, 0
+	}
+	h := uint(0)
+	for d>>(h+1) != 0 {
+		h++
+	}
+	second := (d >> (h - 1)) & 1
+	return int(2*h) + second, h - 1, uint32(d & (1<<(h-1) - 1))
+}
+
+// prefixCode is a canonical Huffman code
+type prefixCode struct {
+	lengths []uint8
+	codes   []uint32
+	// single is set when one symbol is used; it is written with zero bits
+	single bool
+}
+
+func newPrefixCode(histogram []uint32, maxLength int) *prefixCode {
+	pc := &prefixCode{lengths: huffmanLengths(histogram, maxLength)}
+	used := 0
+	for _, l := range pc.lengths {
+		if l > 0 {
+			used++
+		}
+	}
+	pc.single = used <= 1
+	pc.codes = canonicalCodes(pc.lengths)
+	return pc
+}
+
+func (pc *prefixCode) writeSymbol(bw *bitWriter, symbol int) {
+	if pc.single {
+		return
+	}
+	bw.write(pc.codes[symbol], uint(pc.lengths[symbol]))
+}
+
+// writeHeader writes the code as a simple code when at most two symbols
+// below 256 are used, otherwise as code lengths
+func (pc *prefixCode) writeHeader(bw *bitWriter) {
+	var symbols []int
+	for s, l := range pc.lengths {
+		if l > 0 {
+			symbols = append(symbols, s)
+		}
+	}
+	if len(symbols) == 0 {
+		symbols = []int{0}
+	}
+	if len(symbols) <= 2 && symbols[len(symbols)-1] < 256 {
+		bw.write(1, 1)
+		bw.write(uint32(len(symbols)-1), 1)
+		if symbols[0] < 2 {
+			bw.write(0, 1)
+			bw.write(uint32(symbols[0]), 1)
+		} else {
+			bw.write(1, 1)
+			bw.write(uint32(symbols[0]), 8)
+		}
+		if len(symbols) == 2 {
+			bw.write(uint32(symbols[1]), 8)
+		}
+		return
+	}
+
+	bw.write(0, 1)
+	tokens, extras := codeLengthTokens(pc.lengths)
+	histogram := make([]uint32, len(codeLengthOrder))
+	for _, t := range tokens {
+		histogram[t]++
+	}
+	clc := newPrefixCode(histogram, maxCodeLengthLength)
+	count := 4
+	for i, sym := range codeLengthOrder {
+		if clc.lengths[sym] > 0 && i+1 > count {
+			count = i + 1
+		}
+	}
+	bw.write(uint32(count-4), 4)
+	for _, sym := range codeLengthOrder[:count] {
+		bw.write(uint32(clc.lengths[sym]), 3)
+	}
+	bw.write(0, 1) // code lengths cover the whole alphabet
+	for i, t := range tokens {
+		clc.writeSymbol(bw, t)
+		switch t {
+		case 17:
+			bw.write(extras[i], 3)
+		case 18:
+			bw.write(extras[i], 7)
+		}
+	}
+}
+
+// codeLengthTokens run-length codes lengths: 0-15 are literal lengths, 17
+// and 18 are runs of 3-10 and 11-138 zeros
+func codeLengthTokens(lengths []uint8) ([]int, []uint32) {
+	var tokens []int
+	var extras []uint32
+	for i := 0; i < len(lengths); {
+		if lengths[i] != 0 {
+			tokens = append(tokens, int(lengths[i]))
+			extras = append(extras, 0)
+			i++
+			continue
+		}
+		run := 0
+		for i+run < len(lengths) && lengths[i+run] == 0 {
+			run++
+		}
+		i += run
+		for run > 0 {
+			switch {
+			case run >= 11:
+				n := run
+				if n > 138 {
+					n = 138
+				}
+				tokens = append(tokens, 18)
+				extras = append(extras, uint32(n-11))
+				run -= n
+			case run >= 3:
+				tokens = append(tokens, 17)
+				extras = append(extras, uint32(run-3))
+				run = 0
+			default:
+				tokens = append(tokens, 0)
+				extras = append(extras, 0)
+				run--
+			}
+		}
+	}
+	return tokens, extras
+}
+
+// huffmanLengths builds code lengths no longer than maxLength. Rare symbols
+// are made more frequent until the tree is shallow enough.
+func huffmanLengths(histogram []uint32, maxLength int) []uint8 {
+	lengths := make([]uint8, len(histogram))
+	var used []int
+	for s, n := range histogram {
+		if n > 0 {
+			used = append(used, s)
+		}
+	}
+	switch len(used) {
+	case 0:
+		return lengths
+	case 1:
+		lengths[used[0]] = 1
+		return lengths
+	}
+
+	for floor := uint32(1); ; floor *= 2 {
+		type node struct {
+			weight uint64
+			parent int
+		}
+		nodes := make([]node, 0, 2*len(used))
+		for _, s := range used {
+			w := histogram[s]
+			if w < floor {
+				w = floor
+			}
+			nodes = append(nodes, node{weight: uint64(w), parent: -1})
+		}
+		leaves := make([]int, len(used))
+		for i := range leaves {
+			leaves[i] = i
+		}
+		sort.SliceStable(leaves, func(a, b int) bool { return nodes[leaves[a]].weight < nodes[leaves[b]].weight })
+
+		// Two-queue Huffman construction: leaves sorted by weight, internal
+		// nodes are created in non-decreasing weight order
+		var internal []int
+		li, ii := 0, 0
+		pop := func() int {
+			if li < len(leaves) && (ii >= len(internal) || nodes[leaves[li]].weight <= nodes[internal[ii]].weight) {
+				li++
+				return leaves[li-1]
+			}
+			ii++
+			return internal[ii-1]
+		}
+		for n := len(used); n > 1; n-- {
+			a, b := pop(), pop()
+			nodes = append(nodes, node{weight: nodes[a].weight + nodes[b].weight, parent: -1})
+			parent := len(nodes) - 1
+			nodes[a].parent, nodes[b].parent = parent, parent
+			internal = append(internal, parent)
+		}
+
+		depth := make([]int, len(nodes))
+		for i := len(nodes) - 2; i >= 0; i-- {
+			depth[i] = depth[nodes[i].parent] + 1
+		}
+		longest := 0
+		for i := range used {
+			if depth[i] > longest {
+				longest = depth[i]
+			}
+		}
+		if longest <= maxLength {
+			for i, s := range used {
+				lengths[s] = uint8(depth[i])
+			}
+			return lengths
+		}
+	}
+}
+
+// canonicalCodes assigns canonical Huffman codes, bit-reversed because the
+// bit writer is LSB first while codes are read MSB first
+func canonicalCodes(lengths []uint8) []uint32 {
+	var count [maxCodeLength + 1]uint32
+	for _, l := range lengths {
+		count[l]++
+	}
+	count[0] = 0
+	var next [maxCodeLength + 2]uint32
+	code := uint32(0)
+	for l := 1; l <= maxCodeLength; l++ {
+		code = (code + count[l-1]) << 1
+		next[l] = code
+	}
+	codes := make([]uint32, len(lengths))
+	for s, l := range lengths {
+		if l == 0 {
+			continue
+		}
+		c := next[l]
+		next[l]++
+		var rev uint32
+		for i := uint8(0); i < l; i++ {
+			rev = rev<<1 | (c>>i)&1
+		}
+		codes[s] = rev
+	}
+	return codes
+}
+
+// bitWriter packs bits LSB first
+type bitWriter struct {
+	buf  []byte
+	acc  uint64
+	bits uint
+}
+
+func (w *bitWriter) write(v uint32, n uint) {
+	w.acc |= uint64(v) << w.bits
+	w.bits += n
+	for w.bits >= 8 {
+		w.buf = append(w.buf, byte(w.acc))
+		w.acc >>= 8
+		w.bits -= 8
+	}
+}
+
+func (w *bitWriter) bytes() []byte {
+	if w.bits > 0 {
+		w.buf = append(w.buf, byte(w.acc))
+		w.acc, w.bits = 0, 0
+	}
+	return w.buf
+}
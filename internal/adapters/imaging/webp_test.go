@@ -0,0 +1,98 @@
+package imaging
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"math/bits"
+	"math/rand"
+	"testing"
+
+	"golang.org/x/image/webp"
+)
+
+func newRGBA(w, h int, pixel func(x, y int) color.RGBA) *image.RGBA {
+	img := image.NewRGBA(image.Rect(0, 0, w, h))
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			img.SetRGBA(x, y, pixel(x, y))
+		}
+	}
+	return img
+}
+
+// premultiplied returns a valid premultiplied color of r, g, b at alpha a
+func premultiplied(r, g, b, a uint8) color.RGBA {
+	c := color.NRGBA{R: r, G: g, B: b, A: a}
+	r32, g32, b32, a32 := c.RGBA()
+	return color.RGBA{R: uint8(r32 >> 8), G: uint8(g32 >> 8), B: uint8(b32 >> 8), A: uint8(a32 >> 8)}
+}
+
+func TestEncodeWebPRoundTrip(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	noise := newRGBA(97, 61, func(int, int) color.RGBA {
+		return color.RGBA{R: uint8(rng.Intn(256)), G: uint8(rng.Intn(256)), B: uint8(rng.Intn(256)), A: 0xff}
+	})
+	tests := []struct {
+		name string
+		img  *image.RGBA
+	}{
+		{"single pixel", newRGBA(1, 1, func(int, int) color.RGBA { return color.RGBA{R: 200, G: 10, B: 99, A: 0xff} })},
+		{"solid", newRGBA(64, 64, func(int, int) color.RGBA { return color.RGBA{R: 30, G: 60, B: 90, A: 0xff} })},
+		{"gradient", newRGBA(300, 200, func(x, y int) color.RGBA {
+			return color.RGBA{R: uint8(x), G: uint8(y), B: uint8(x + y), A: 0xff}
+		})},
+		{"noise", noise},
+		{"repeated rows", newRGBA(50, 40, func(x, y int) color.RGBA {
+			return color.RGBA{R: uint8(x * 37), G: uint8(x * 11), B: uint8(y / 10), A: 0xff}
+		})},
+		{"wider than a predictor block", newRGBA(1100, 3, func(x, y int) color.RGBA {
+			return color.RGBA{R: uint8(x / 5), G: uint8(y * 80), B: uint8(x % 7), A: 0xff}
+		})},
+		// Geometric symbol frequencies need codes longer than 15 bits unless
+		// the code lengths are limited
+		{"skewed histogram", newRGBA(4096, 16, func(x, y int) color.RGBA {
+			v := uint8(bits.TrailingZeros32(uint32(y*4096 + x + 1)))
+			return color.RGBA{R: v * 13, G: v * 7, B: v, A: 0xff}
+		})},
+		{"transparency", newRGBA(40, 40, func(x, y int) color.RGBA {
+			return premultiplied(uint8(x*6), uint8(y*6), 128, uint8(x*y%256))
+		})},
+		{"sub-image", noise.SubImage(image.Rect(10, 20, 45, 50)).(*image.RGBA)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			if err := EncodeWebP(&buf, tt.img); err != nil {
+				t.Fatalf("EncodeWebP: %v", err)
+			}
+			decoded, err := webp.Decode(bytes.NewReader(buf.Bytes()))
+			if err != nil {
+				t.Fatalf("decoding: %v", err)
+			}
+
+			b := tt.img.Bounds()
+			if got := decoded.Bounds(); got.Dx() != b.Dx() || got.Dy() != b.Dy() {
+				t.Fatalf("decoded size %v, want %v", got.Size(), b.Size())
+			}
+			for y := 0; y < b.Dy(); y++ {
+				for x := 0; x < b.Dx(); x++ {
+					src := tt.img.RGBAAt(b.Min.X+x, b.Min.Y+y)
+					r, g, bl, a := unpremultiply(src.R, src.G, src.B, src.A)
+					want := color.NRGBA{R: r, G: g, B: bl, A: a}
+					if got := color.NRGBAModel.Convert(decoded.At(x, y)).(color.NRGBA); got != want {
+						t.Fatalf("pixel (%d, %d) = %v, want %v", x, y, got, want)
+					}
+				}
+			}
+		})
+	}
+}
+
+func TestEncodeWebPRejectsBadSizes(t *testing.T) {
+	for _, r := range []image.Rectangle{image.Rect(0, 0, 0, 5), image.Rect(0, 0, vp8lMaxDimension+1, 1)} {
+		if err := EncodeWebP(&bytes.Buffer{}, image.NewRGBA(r)); err != errWebPTooLarge {
+			t.Errorf("EncodeWebP of %v = %v", r.Size(), err)
+		}
+	}
+}
@@ -0,0 +1,64 @@
+package mail
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+	applog "web-porto-backend/common/logger"
+)
+
+// FileMailer writes every message as an .eml file into a directory. Useful for
+// local development and tests where the mail content needs to be inspected.
+type FileMailer struct {
+	dir  string
+	from string
+}
+
+func NewFileMailer(dir, from string) *FileMailer {
+	if dir == "" {
+		dir = filepath.Join("tmp", "mail")
+	}
+	return &FileMailer{dir: dir, from: from}
+}
+
+func (m *FileMailer) Send(msg Message) error {
+	data, err := buildMessage(m.from, msg)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(m.dir, 0o755); err != nil {
+		return err
+	}
+
+	name := fmt.Sprintf("%s-%s.eml", time.Now().Format("20060102T150405"), randomHex(4))
+	path := filepath.Join(m.dir, name)
+	if err := os.WriteFile(path, data, 0o600); err != nil {
+		return err
+	}
+	applog.GetLogger().Info("mail written to file", applog.Fields{"path": path, "to": msg.To, "subject": msg.Subject})
+	return nil
+}
+
+// LogMailer only logs the message, including its text body. Never use it in
+// production: the body contains reset and verification links.
+type LogMailer struct {
+	from string
+}
+
+func NewLogMailer(from string) *LogMailer {
+	return &LogMailer{from: from}
+}
+
+func (m *LogMailer) Send(msg Message) error {
+	if len(msg.To) == 0 {
+		return fmt.Errorf("mail: no recipients")
+	}
+	applog.GetLogger().Info("mail (log driver)", applog.Fields{
+		"from":    m.from,
+		"to":      msg.To,
+		"subject": msg.Subject,
+		"body":    msg.Text,
+	})
+	return nil
+}
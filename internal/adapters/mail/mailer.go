@@ -0,0 +1,132 @@
+package mail
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"mime"
+	"mime/quotedprintable"
+	"strings"
+	"time"
+)
+
+// Drivers selectable through Config.Driver
+const (
+	DriverSMTP = "smtp"
+	DriverFile = "file"
+	DriverLog  = "log"
+)
+
+// Message is a single outgoing email. HTML is optional; when set the message
+// is sent as multipart/alternative with Text as the fallback part.
+type Message struct {
+	To      []string
+	Subject string
+	Text    string
+	HTML    string
+}
+
+// Mailer sends transactional email
+type Mailer interface {
+	Send(msg Message) error
+}
+
+// Config selects and configures a Mailer
+type Config struct {
+	Driver   string
+	Host     string
+	Port     int
+	Username string
+	Password string
+	From     string
+	FileDir  string
+}
+
+// NewMailer returns the mailer for cfg.Driver. Unknown or empty drivers fall
+// back to the log mailer so local setups work without SMTP.
+func NewMailer(cfg Config) Mailer {
+	switch strings.ToLower(cfg.Driver) {
+	case DriverSMTP:
+		return NewSMTPMailer(cfg.Host, cfg.Port, cfg.Username, cfg.Password, cfg.From)
+	case DriverFile:
+		return NewFileMailer(cfg.FileDir, cfg.From)
+	default:
+		return NewLogMailer(cfg.From)
+	}
+}
+
+// buildMessage renders msg as an RFC 5322 message
+func buildMessage(from string, msg Message) ([]byte, error) {
+	if len(msg.To) == 0 {
+		return nil, fmt.Errorf("mail: no recipients")
+	}
+	for _, addr := range append([]string{from}, msg.To...) {
+		if strings.ContainsAny(addr, "\r\n") {
+			return nil, fmt.Errorf("mail: invalid address %q", addr)
+		}
+	}
+
+	var buf bytes.Buffer
+	writeHeader := func(key, value string) {
+		buf.WriteString(key + ": " + value + "\r\n")
+	}
+	writeHeader("From", from)
+	writeHeader("To", strings.Join(msg.To, ", "))
+	writeHeader("Subject", mime.QEncoding.Encode("utf-8", msg.Subject))
+	writeHeader("Date", time.Now().Format(time.RFC1123Z))
+	writeHeader("Message-ID", messageID(from))
+	writeHeader("MIME-Version", "1.0")
+
+	if msg.HTML == "" {
+		writeHeader("Content-Type", `text/plain; charset="utf-8"`)
+		writeHeader("Content-Transfer-Encoding", "quoted-printable")
+		buf.WriteString("\r\n")
+		if err := writeQuotedPrintable(&buf, msg.Text); err != nil {
+			return nil, err
+		}
+		return buf.Bytes(), nil
+	}
+
+	boundary := randomHex(12)
+	writeHeader("Content-Type", `multipart/alternative; boundary="`+boundary+`"`)
+	buf.WriteString("\r\n")
+	parts := []struct{ contentType, body string }{
+		{"text/plain", msg.Text},
+		{"text/html", msg.HTML},
+	}
+	for _, part := range parts {
+		buf.WriteString("--" + boundary + "\r\n")
+		writeHeader("Content-Type", part.contentType+`; charset="utf-8"`)
+		writeHeader("Content-Transfer-Encoding", "quoted-printable")
+		buf.WriteString("\r\n")
+		if err := writeQuotedPrintable(&buf, part.body); err != nil {
+			return nil, err
+		}
+		buf.WriteString("\r\n")
+	}
+	buf.WriteString("--" + boundary + "--\r\n")
+	return buf.Bytes(), nil
+}
+
+func writeQuotedPrintable(buf *bytes.Buffer, body string) error {
+	w := quotedprintable.NewWriter(buf)
+	if _, err := w.Write([]byte(body)); err != nil {
+		return err
+	}
+	return w.Close()
+}
+
+func messageID(from string) string {
+	domain := "localhost"
+	if at := strings.LastIndex(from, "@"); at >= 0 {
+		domain = strings.Trim(from[at+1:], "> ")
+	}
+	return "<" + randomHex(16) + "@" + domain + ">"
+}
+
+func randomHex(n int) string {
+	b := make([]byte, n)
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
+}
@@ -0,0 +1,110 @@
+package mail
+
+import (
+	"crypto/tls"
+	"fmt"
+	"net"
+	"net/mail"
+	"net/smtp"
+	"strconv"
+	"time"
+)
+
+// SMTPMailer delivers mail through an SMTP server. Port 465 uses implicit TLS;
+// other ports upgrade with STARTTLS when the server offers it.
+type SMTPMailer struct {
+	host     string
+	port     int
+	username string
+	password string
+	from     string
+	timeout  time.Duration
+}
+
+func NewSMTPMailer(host string, port int, username, password, from string) *SMTPMailer {
+	if port == 0 {
+		port = 587
+	}
+	return &SMTPMailer{
+		host:     host,
+		port:     port,
+		username: username,
+		password: password,
+		from:     from,
+		timeout:  15 * time.Second,
+	}
+}
+
+func (m *SMTPMailer) Send(msg Message) error {
+	data, err := buildMessage(m.from, msg)
+	if err != nil {
+		return err
+	}
+
+	client, err := m.dial()
+	if err != nil {
+		return fmt.Errorf("mail: connect: %w", err)
+	}
+	defer client.Close()
+
+	if m.port != 465 {
+		if ok, _ := client.Extension("STARTTLS"); ok {
+			if err := client.StartTLS(&tls.Config{ServerName: m.host}); err != nil {
+				return fmt.Errorf("mail: starttls: %w", err)
+			}
+		}
+	}
+
+	if m.username != "" {
+		if err := client.Auth(smtp.PlainAuth("", m.username, m.password, m.host)); err != nil {
+			return fmt.Errorf("mail: auth: %w", err)
+		}
+	}
+
+	if err := client.Mail(envelopeAddress(m.from)); err != nil {
+		return fmt.Errorf("mail: from: %w", err)
+	}
+	for _, to := range msg.To {
+		if err := client.Rcpt(envelopeAddress(to)); err != nil {
+			return fmt.Errorf("mail: rcpt %s: %w", to, err)
+		}
+	}
+
+	w, err := client.Data()
+	if err != nil {
+		return fmt.Errorf("mail: data: %w", err)
+	}
+	if _, err := w.Write(data); err != nil {
+		return fmt.Errorf("mail: write: %w", err)
+	}
+	if err := w.Close(); err != nil {
+		return fmt.Errorf("mail: data: %w", err)
+	}
+	return client.Quit()
+}
+
+func (m *SMTPMailer) dial() (*smtp.Client, error) {
+	addr := net.JoinHostPort(m.host, strconv.Itoa(m.port))
+	dialer := &net.Dialer{Timeout: m.timeout}
+
+	var conn net.Conn
+	var err error
+	if m.port == 465 {
+		conn, err = tls.DialWithDialer(dialer, "tcp", addr, &tls.Config{ServerName: m.host})
+	} else {
+		conn, err = dialer.Dial("tcp", addr)
+	}
+	if err != nil {
+		return nil, err
+	}
+	_ = conn.SetDeadline(time.Now().Add(m.timeout))
+	return smtp.NewClient(conn, m.host)
+}
+
+// envelopeAddress extracts the bare address from "Name <addr>" forms
+func envelopeAddress(addr string) string {
+	if parsed, err := mail.ParseAddress(addr); err == nil {
+		return parsed.Address
+	}
+	return addr
+}
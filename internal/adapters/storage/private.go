@@ -0,0 +1,98 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"io"
+	"strings"
+)
+
+// Private keeps files that must not be publicly reachable. Its directory or
+// bucket is never served as is: URLs point to the application, which only
+// serves a file for a valid signature.
+type Private struct {
+	Storage
+	baseURL string
+}
+
+// NewPrivate wraps backend; baseURL is where the application serves private
+// files, e.g. http://localhost:8080/files. URL returns unsigned URLs.
+func NewPrivate(backend Storage, baseURL string) *Private {
+	return &Private{Storage: backend, baseURL: strings.TrimRight(baseURL, "/")}
+}
+
+func (p *Private) Name() string {
+	return DriverPrivate
+}
+
+func (p *Private) URL(key string) string {
+	return p.baseURL + "/" + escapePath(strings.TrimPrefix(key, "/"))
+}
+
+// readCloser reads from r and closes c
+type readCloser struct {
+	io.Reader
+	c io.Closer
+}
+
+func (r readCloser) Close() error {
+	return r.c.Close()
+}
+
+// ReadSeeker reads an object of a known size, opening a ranged read wherever
+// it is seeked to. It lets http.ServeContent answer Range requests without
+// downloading whole objects from remote backends.
+type ReadSeeker struct {
+	ctx     context.Context
+	backend Storage
+	key     string
+	size    int64
+	offset  int64
+	body    io.ReadCloser
+}
+
+func NewReadSeeker(ctx context.Context, backend Storage, key string, size int64) *ReadSeeker {
+	return &ReadSeeker{ctx: ctx, backend: backend, key: key, size: size}
+}
+
+func (r *ReadSeeker) Read(p []byte) (int, error) {
+	if r.offset >= r.size {
+		return 0, io.EOF
+	}
+	if r.body == nil {
+		body, err := r.backend.GetRange(r.ctx, r.key, r.offset, -1)
+		if err != nil {
+			return 0, err
+		}
+		r.body = body
+	}
+	n, err := r.body.Read(p)
+	r.offset += int64(n)
+	return n, err
+}
+
+func (r *ReadSeeker) Seek(offset int64, whence int) (int64, error) {
+	switch whence {
+	case io.SeekCurrent:
+		offset += r.offset
+	case io.SeekEnd:
+		offset += r.size
+	}
+	if offset < 0 {
+		return 0, errors.New("storage: negative position")
+	}
+	if offset != r.offset {
+		r.Close()
+		r.offset = offset
+	}
+	return offset, nil
+}
+
+func (r *ReadSeeker) Close() error {
+	if r.body == nil {
+		return nil
+	}
+	err := r.body.Close()
+	r.body = nil
+	return err
+}
@@ -0,0 +1,337 @@
+package storage
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// S3Config addresses a bucket on AWS S3 or a compatible server (MinIO,
+// R2, Spaces, ...)
+type S3Config struct {
+	// Endpoint is the server URL, e.g. https://s3.eu-central-1.amazonaws.com
+	// or http://localhost:9000
+	Endpoint  string
+	Region    string
+	Bucket    string
+	AccessKey string
+	SecretKey string
+	// PathStyle addresses the bucket as endpoint/bucket instead of
+	// bucket.endpoint; most self-hosted servers need it
+	PathStyle bool
+	// PublicURL is where objects are publicly reachable (a CDN or the bucket
+	// website); defaults to the bucket URL
+	PublicURL string
+}
+
+// unsignedPayload skips hashing request bodies; the TLS connection (or the
+// trusted network of a local server) protects them instead
+const unsignedPayload = "UNSIGNED-PAYLOAD"
+
+// S3 stores files in a bucket using the S3 REST API with Signature V4
+type S3 struct {
+	cfg      S3Config
+	endpoint *url.URL
+	client   *http.Client
+}
+
+func NewS3(cfg S3Config) (*S3, error) {
+	if cfg.Endpoint == "" || cfg.Bucket == "" {
+		return nil, fmt.Errorf("s3 storage needs an endpoint and a bucket")
+	}
+	endpoint, err := url.Parse(strings.TrimRight(cfg.Endpoint, "/"))
+	if err != nil {
+		return nil, err
+	}
+	if cfg.Region == "" {
+		cfg.Region = "us-east-1"
+	}
+	s := &S3{cfg: cfg, endpoint: endpoint, client: &http.Client{Timeout: 5 * time.Minute}}
+	if s.cfg.PublicURL == "" {
+		s.cfg.PublicURL = s.bucketURL()
+	}
+	s.cfg.PublicURL = strings.TrimRight(s.cfg.PublicURL, "/")
+	return s, nil
+}
+
+func (s *S3) Name() string {
+	return DriverS3
+}
+
+func (s *S3) Put(ctx context.Context, key string, r io.Reader, size int64, opts PutOptions) error {
+	req, err := s.request(ctx, http.MethodPut, key, r)
+	if err != nil {
+		return err
+	}
+	req.ContentLength = size
+	if size == 0 {
+		// Otherwise the body would be sent chunked, which S3 refuses
+		req.Body = http.NoBody
+	}
+	if opts.ContentType != "" {
+		req.Header.Set("Content-Type", opts.ContentType)
+	}
+	if opts.Disposition != "" {
+		req.Header.Set("Content-Disposition", opts.Disposition)
+	}
+	resp, err := s.do(req)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	return nil
+}
+
+func (s *S3) Get(ctx context.Context, key string) (io.ReadCloser, *Object, error) {
+	req, err := s.request(ctx, http.MethodGet, key, nil)
+	if err != nil {
+		return nil, nil, err
+	}
+	resp, err := s.do(req)
+	if err != nil {
+		return nil, nil, err
+	}
+	return resp.Body, s.object(key, resp), nil
+}
+
+func (s *S3) GetRange(ctx context.Context, key string, offset, length int64) (io.ReadCloser, error) {
+	req, err := s.request(ctx, http.MethodGet, key, nil)
+	if err != nil {
+		return nil, err
+	}
+	if length < 0 {
+		req.Header.Set("Range", fmt.Sprintf("bytes=%d-", offset))
+	} else {
+		req.Header.Set("Range", fmt.Sprintf("bytes=%d-%d", offset, offset+length-1))
+	}
+	resp, err := s.do(req)
+	if err != nil {
+		return nil, err
+	}
+	return resp.Body, nil
+}
+
+func (s *S3) Delete(ctx context.Context, key string) error {
+	req, err := s.request(ctx, http.MethodDelete, key, nil)
+	if err != nil {
+		return err
+	}
+	resp, err := s.do(req)
+	if err == ErrNotFound {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	return nil
+}
+
+func (s *S3) Stat(ctx context.Context, key string) (*Object, error) {
+	req, err := s.request(ctx, http.MethodHead, key, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := s.do(req)
+	if err != nil {
+		return nil, err
+	}
+	resp.Body.Close()
+	return s.object(key, resp), nil
+}
+
+// listResult is the part of a ListObjectsV2 response List reads
+type listResult struct {
+	Contents []struct {
+		Key          string
+		LastModified time.Time
+		ETag         string
+		Size         int64
+	}
+	IsTruncated           bool
+	NextContinuationToken string
+}
+
+func (s *S3) List(ctx context.Context, prefix string, fn func(Object) error) error {
+	token := ""
+	for {
+		query := url.Values{"list-type": {"2"}}
+		if prefix != "" {
+			query.Set("prefix", prefix)
+		}
+		if token != "" {
+			query.Set("continuation-token", token)
+		}
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, s.bucketURL()+"/?"+query.Encode(), nil)
+		if err != nil {
+			return err
+		}
+		resp, err := s.do(req)
+		if err != nil {
+			return err
+		}
+		var page listResult
+		err = xml.NewDecoder(resp.Body).Decode(&page)
+		resp.Body.Close()
+		if err != nil {
+			return err
+		}
+
+		for _, c := range page.Contents {
+			if err := fn(Object{Key: c.Key, Size: c.Size, ModTime: c.LastModified, ETag: strings.Trim(c.ETag, `"`)}); err != nil {
+				return err
+			}
+		}
+		if !page.IsTruncated || page.NextContinuationToken == "" {
+			return nil
+		}
+		token = page.NextContinuationToken
+	}
+}
+
+func (s *S3) URL(key string) string {
+	return s.cfg.PublicURL + "/" + escapePath(strings.TrimPrefix(key, "/"))
+}
+
+func (s *S3) Location(key string) string {
+	return "s3://" + s.cfg.Bucket + "/" + strings.TrimPrefix(key, "/")
+}
+
+// bucketURL is the base URL of the bucket in the configured addressing style
+func (s *S3) bucketURL() string {
+	if s.cfg.PathStyle {
+		return s.endpoint.String() + "/" + s.cfg.Bucket
+	}
+	u := *s.endpoint
+	u.Host = s.cfg.Bucket + "." + u.Host
+	return u.String()
+}
+
+func (s *S3) request(ctx context.Context, method, key string, body io.Reader) (*http.Request, error) {
+	key, err := CleanKey(key)
+	if err != nil {
+		return nil, err
+	}
+	u := s.bucketURL() + "/" + escapePath(key)
+	return http.NewRequestWithContext(ctx, method, u, body)
+}
+
+// do signs and sends a request; 404 becomes ErrNotFound and other failures
+// an error carrying the response body
+func (s *S3) do(req *http.Request) (*http.Response, error) {
+	s.sign(req, time.Now().UTC())
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode == http.StatusNotFound {
+		resp.Body.Close()
+		return nil, ErrNotFound
+	}
+	if resp.StatusCode >= 300 {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		resp.Body.Close()
+		return nil, fmt.Errorf("s3 %s %s: %s: %s", req.Method, req.URL.Path, resp.Status, strings.TrimSpace(string(msg)))
+	}
+	return resp, nil
+}
+
+func (s *S3) object(key string, resp *http.Response) *Object {
+	obj := &Object{
+		Key:         key,
+		ContentType: resp.Header.Get("Content-Type"),
+		ETag:        strings.Trim(resp.Header.Get("ETag"), `"`),
+	}
+	obj.Size, _ = strconv.ParseInt(resp.Header.Get("Content-Length"), 10, 64)
+	obj.ModTime, _ = http.ParseTime(resp.Header.Get("Last-Modified"))
+	return obj
+}
+
+// sign adds an AWS Signature Version 4 authorization header. The body is
+// left unsigned unless X-Amz-Content-Sha256 already holds its hash.
+func (s *S3) sign(req *http.Request, now time.Time) {
+	amzDate := now.Format("20060102T150405Z")
+	day := now.Format("20060102")
+	req.Header.Set("X-Amz-Date", amzDate)
+	payload := req.Header.Get("X-Amz-Content-Sha256")
+	if payload == "" {
+		payload = unsignedPayload
+		req.Header.Set("X-Amz-Content-Sha256", payload)
+	}
+
+	signed := []string{"host", "x-amz-content-sha256", "x-amz-date"}
+	if req.Header.Get("Content-Type") != "" {
+		signed = append(signed, "content-type")
+	}
+	if req.Header.Get("Content-Disposition") != "" {
+		signed = append(signed, "content-disposition")
+	}
+	if req.Header.Get("Range") != "" {
+		signed = append(signed, "range")
+	}
+	sort.Strings(signed)
+	var headers strings.Builder
+	for _, h := range signed {
+		v := req.Header.Get(h)
+		if h == "host" {
+			v = req.URL.Host
+		}
+		headers.WriteString(h + ":" + strings.TrimSpace(v) + "\n")
+	}
+
+	canonical := strings.Join([]string{
+		req.Method,
+		req.URL.EscapedPath(),
+		strings.ReplaceAll(req.URL.Query().Encode(), "+", "%20"),
+		headers.String(),
+		strings.Join(signed, ";"),
+		payload,
+	}, "\n")
+	scope := day + "/" + s.cfg.Region + "/s3/aws4_request"
+	toSign := "AWS4-HMAC-SHA256\n" + amzDate + "\n" + scope + "\n" + hexSHA256(canonical)
+
+	key := hmacSHA256([]byte("AWS4"+s.cfg.SecretKey), day)
+	key = hmacSHA256(key, s.cfg.Region)
+	key = hmacSHA256(key, "s3")
+	key = hmacSHA256(key, "aws4_request")
+	signature := hex.EncodeToString(hmacSHA256(key, toSign))
+
+	req.Header.Set("Authorization", fmt.Sprintf("AWS4-HMAC-SHA256 Credential=%s/%s, SignedHeaders=%s, Signature=%s",
+		s.cfg.AccessKey, scope, strings.Join(signed, ";"), signature))
+}
+
+// escapePath percent-encodes a key the way Signature V4 expects: everything
+// but unreserved characters and the slashes between segments
+func escapePath(key string) string {
+	var b strings.Builder
+	for i := 0; i < len(key); i++ {
+		c := key[i]
+		if 'A' <= c && c <= 'Z' || 'a' <= c && c <= 'z' || '0' <= c && c <= '9' || strings.IndexByte("-._~/", c) >= 0 {
+			b.WriteByte(c)
+		} else {
+			fmt.Fprintf(&b, "%%%02X", c)
+		}
+	}
+	return b.String()
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(data))
+	return mac.Sum(nil)
+}
+
+func hexSHA256(data string) string {
+	sum := sha256.Sum256([]byte(data))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,180 @@
+// Package storage stores media files on the local disk or in an
+// S3-compatible bucket behind a common interface.
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"path"
+	"strings"
+	"time"
+)
+
+// Backend names, as stored on media rows and used in configuration
+const (
+	DriverLocal   = "local"
+	DriverS3      = "s3"
+	DriverPrivate = "private"
+)
+
+var (
+	ErrNotFound      = errors.New("object not found")
+	ErrInvalidKey    = errors.New("invalid object key")
+	ErrUnknownDriver = errors.New("unknown storage driver")
+)
+
+// Object describes a stored file
+type Object struct {
+	Key         string
+	Size        int64
+	ContentType string
+	ModTime     time.Time
+	ETag        string
+}
+
+// PutOptions describes how a stored object is served. Disposition is sent
+// as the Content-Disposition of objects served straight from the backend,
+// e.g. "attachment" to force a download; the local backend is served by the
+// application, which sets its own headers.
+type PutOptions struct {
+	ContentType string
+	Disposition string
+}
+
+// Storage is a flat namespace of files addressed by slash-separated keys
+// such as "projects/abc/photo.jpg"
+type Storage interface {
+	// Name is the driver name recorded on media rows
+	Name() string
+	Put(ctx context.Context, key string, r io.Reader, size int64, opts PutOptions) error
+	// Get opens an object; the caller closes the reader
+	Get(ctx context.Context, key string) (io.ReadCloser, *Object, error)
+	// GetRange opens length bytes of an object from offset, or the rest of
+	// it when length is negative
+	GetRange(ctx context.Context, key string, offset, length int64) (io.ReadCloser, error)
+	// Delete removes an object; deleting a missing object is not an error
+	Delete(ctx context.Context, key string) error
+	Stat(ctx context.Context, key string) (*Object, error)
+	// URL is the public address of an object
+	URL(key string) string
+	// Location is where an object lives, for logs and the media file path
+	Location(key string) string
+	// List calls fn for every object whose key starts with prefix, in no
+	// particular order; an error from fn stops the walk and is returned
+	List(ctx context.Context, prefix string, fn func(Object) error) error
+}
+
+// CleanKey normalizes a key and rejects keys leaving the storage root
+func CleanKey(key string) (string, error) {
+	key = strings.ReplaceAll(key, "\\", "/")
+	cleaned := path.Clean("/" + key)[1:]
+	if cleaned == "" || cleaned != strings.TrimPrefix(key, "/") {
+		return "", fmt.Errorf("%w: %q", ErrInvalidKey, key)
+	}
+	return cleaned, nil
+}
+
+// Config selects and sets up the backends
+type Config struct {
+	// Driver is the backend new files are stored in
+	Driver string
+	// LocalDir is served at LocalURL
+	LocalDir string
+	LocalURL string
+	S3       S3Config
+	// PrivateDir keeps private files, or PrivateBucket when set (on the S3
+	// server); PrivateURL is where the application serves them
+	PrivateDir    string
+	PrivateBucket string
+	PrivateURL    string
+}
+
+// New sets up the local backend, and the S3 backend when a bucket is
+// configured, so files can be migrated between them whichever is the default.
+// The private backend is always set up, on the disk or in its own bucket.
+func New(cfg Config) (*Registry, error) {
+	local, err := NewLocal(cfg.LocalDir, cfg.LocalURL)
+	if err != nil {
+		return nil, err
+	}
+	backends := []Storage{local}
+	if cfg.S3.Bucket != "" {
+		s3, err := NewS3(cfg.S3)
+		if err != nil {
+			return nil, err
+		}
+		backends = append(backends, s3)
+	}
+
+	var private Storage
+	if cfg.PrivateBucket != "" {
+		s3cfg := cfg.S3
+		s3cfg.Bucket, s3cfg.PublicURL = cfg.PrivateBucket, ""
+		if private, err = NewS3(s3cfg); err != nil {
+			return nil, err
+		}
+	} else if private, err = NewLocal(cfg.PrivateDir, ""); err != nil {
+		return nil, err
+	}
+	backends = append(backends, NewPrivate(private, cfg.PrivateURL))
+	if cfg.Driver == "" {
+		cfg.Driver = DriverLocal
+	}
+	return NewRegistry(cfg.Driver, backends...)
+}
+
+// Registry holds the configured backends; new files go to the default one
+type Registry struct {
+	def      Storage
+	backends map[string]Storage
+}
+
+// NewRegistry registers backends and picks the one named def as default
+func NewRegistry(def string, backends ...Storage) (*Registry, error) {
+	r := &Registry{backends: make(map[string]Storage, len(backends))}
+	for _, b := range backends {
+		r.backends[b.Name()] = b
+	}
+	d, ok := r.backends[def]
+	if !ok {
+		return nil, fmt.Errorf("%w: %q is not configured", ErrUnknownDriver, def)
+	}
+	r.def = d
+	return r, nil
+}
+
+// Default is the backend new uploads are stored in
+func (r *Registry) Default() Storage {
+	return r.def
+}
+
+// All returns every configured backend, the default first
+func (r *Registry) All() []Storage {
+	all := []Storage{r.def}
+	for _, b := range r.backends {
+		if b != r.def {
+			all = append(all, b)
+		}
+	}
+	return all
+}
+
+// Private is the backend of files only served through signed URLs
+func (r *Registry) Private() Storage {
+	return r.backends[DriverPrivate]
+}
+
+// Get returns a backend by name; rows from before backends existed have an
+// empty name and live on the local disk
+func (r *Registry) Get(name string) (Storage, error) {
+	if name == "" {
+		name = DriverLocal
+	}
+	b, ok := r.backends[name]
+	if !ok {
+		return nil, fmt.Errorf("%w: %q is not configured", ErrUnknownDriver, name)
+	}
+	return b, nil
+}
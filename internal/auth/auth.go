@@ -7,32 +7,99 @@ import (
 	"time"
 
 	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
 	"golang.org/x/crypto/bcrypt"
 )
 
 // JWTService interface defines JWT operations
 type JWTService interface {
-	GenerateToken(userID int, email, role string) (string, error)
+	GenerateToken(userID int, email, role string, permissions []string) (string, error)
 	ValidateToken(tokenString string) (*Claims, error)
+	AccessTokenTTL() time.Duration
+	GenerateScopedToken(userID int, purpose, fingerprint string, ttl time.Duration) (string, error)
+	ValidateScopedToken(tokenString, purpose string) (*Claims, error)
+}
+
+// Purposes for scoped tokens. Scoped tokens are never accepted as access tokens.
+const (
+	PurposeTwoFactorChallenge = "2fa_challenge"
+	PurposePasswordReset      = "password_reset"
+	PurposeEmailVerification  = "email_verification"
+)
+
+// APITokenPrefix marks personal access tokens so JWTAuth can tell them apart
+// from JWTs without a database lookup.
+const APITokenPrefix = "wpb_"
+
+// APITokenAuthenticator resolves a personal access token into claims.
+type APITokenAuthenticator interface {
+	AuthenticateAPIToken(raw, ip string) (*Claims, error)
+}
+
+// RevocationChecker reports whether a token has been revoked server-side:
+// its ID (jti) by logout, or all access tokens of userID issued at or before
+// a cutoff by logging out everywhere. userID is 0 for scoped tokens.
+type RevocationChecker interface {
+	IsTokenRevoked(jti string, userID int, issuedAt time.Time) bool
 }
 
 type Claims struct {
-	UserID   int    `json:"user_id"`
-	Username string `json:"username"`
-	Role     string `json:"role"`
+	UserID      int      `json:"user_id"`
+	Username    string   `json:"username"`
+	Role        string   `json:"role"`
+	Permissions []string `json:"permissions,omitempty"`
+	// Purpose is empty for access tokens and set for single-purpose tokens
+	Purpose string `json:"purpose,omitempty"`
+	// Fingerprint binds a scoped token to server-side state (e.g. a password hash)
+	Fingerprint string `json:"fp,omitempty"`
 	jwt.RegisteredClaims
 }
 
 type AuthService struct {
-	jwtSecret []byte
+	jwtSecret   []byte
+	accessTTL   time.Duration
+	revocations RevocationChecker
+	apiTokens   APITokenAuthenticator
 }
 
-func NewAuthService(jwtSecret string) *AuthService {
+func NewAuthService(jwtSecret string, accessTTL time.Duration) *AuthService {
+	if accessTTL <= 0 {
+		accessTTL = 15 * time.Minute
+	}
 	return &AuthService{
 		jwtSecret: []byte(jwtSecret),
+		accessTTL: accessTTL,
 	}
 }
 
+// SetRevocationChecker wires the store consulted by ValidateToken for revoked jtis.
+func (a *AuthService) SetRevocationChecker(checker RevocationChecker) {
+	a.revocations = checker
+}
+
+// SetAPITokenAuthenticator enables personal access tokens in ValidateAPIToken.
+func (a *AuthService) SetAPITokenAuthenticator(authenticator APITokenAuthenticator) {
+	a.apiTokens = authenticator
+}
+
+// IsAPIToken reports whether the bearer credential is a personal access token.
+func IsAPIToken(token string) bool {
+	return strings.HasPrefix(token, APITokenPrefix)
+}
+
+// ValidateAPIToken authenticates a personal access token.
+func (a *AuthService) ValidateAPIToken(token, ip string) (*Claims, error) {
+	if a.apiTokens == nil {
+		return nil, errors.New("API tokens are not enabled")
+	}
+	return a.apiTokens.AuthenticateAPIToken(token, ip)
+}
+
+// AccessTokenTTL returns the lifetime of access tokens issued by GenerateToken.
+func (a *AuthService) AccessTokenTTL() time.Duration {
+	return a.accessTTL
+}
+
 func (a *AuthService) HashPassword(password string) (string, error) {
 	hashedBytes, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
 	if err != nil {
@@ -46,19 +113,22 @@ func (a *AuthService) CheckPassword(password, hashedPassword string) bool {
 	return err == nil
 }
 
-func (a *AuthService) GenerateToken(userID int, username, role string) (string, error) {
+// GenerateToken issues an access token carrying the permissions resolved for
+// the user's roles at issue time.
+func (a *AuthService) GenerateToken(userID int, username, role string, permissions []string) (string, error) {
 	now := time.Now()
 
 	claims := &Claims{
-		UserID:   userID,
-		Username: username,
-		Role:     role,
+		UserID:      userID,
+		Username:    username,
+		Role:        role,
+		Permissions: permissions,
 		RegisteredClaims: jwt.RegisteredClaims{
-			ExpiresAt: jwt.NewNumericDate(now.Add(24 * time.Hour)),
+			ExpiresAt: jwt.NewNumericDate(now.Add(a.accessTTL)),
 			IssuedAt:  jwt.NewNumericDate(now),
 			NotBefore: jwt.NewNumericDate(now),
 			Subject:   username,
-			ID:        fmt.Sprintf("%d-%d", userID, now.Unix()),
+			ID:        uuid.New().String(),
 		},
 	}
 
@@ -76,7 +146,51 @@ func (a *AuthService) GenerateToken(userID int, username, role string) (string,
 	return tokenString, nil
 }
 
+// ValidateToken validates an access token. Scoped tokens are rejected.
 func (a *AuthService) ValidateToken(tokenString string) (*Claims, error) {
+	claims, err := a.parseToken(tokenString)
+	if err != nil {
+		return nil, err
+	}
+	if claims.Purpose != "" {
+		return nil, errors.New("invalid token type")
+	}
+	return claims, nil
+}
+
+// GenerateScopedToken issues a short-lived token usable only for the given
+// purpose, such as completing a two-factor login.
+func (a *AuthService) GenerateScopedToken(userID int, purpose, fingerprint string, ttl time.Duration) (string, error) {
+	now := time.Now()
+	claims := &Claims{
+		UserID:      userID,
+		Purpose:     purpose,
+		Fingerprint: fingerprint,
+		RegisteredClaims: jwt.RegisteredClaims{
+			ExpiresAt: jwt.NewNumericDate(now.Add(ttl)),
+			IssuedAt:  jwt.NewNumericDate(now),
+			NotBefore: jwt.NewNumericDate(now),
+			ID:        uuid.New().String(),
+		},
+	}
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
+	token.Header["typ"] = "JWT"
+	return token.SignedString(a.jwtSecret)
+}
+
+// ValidateScopedToken validates a token issued by GenerateScopedToken for purpose.
+func (a *AuthService) ValidateScopedToken(tokenString, purpose string) (*Claims, error) {
+	claims, err := a.parseToken(tokenString)
+	if err != nil {
+		return nil, err
+	}
+	if purpose == "" || claims.Purpose != purpose {
+		return nil, errors.New("invalid token type")
+	}
+	return claims, nil
+}
+
+func (a *AuthService) parseToken(tokenString string) (*Claims, error) {
 	// Better validation with detailed error messages
 	if tokenString == "" {
 		return nil, errors.New("empty token")
@@ -111,7 +225,19 @@ func (a *AuthService) ValidateToken(tokenString string) (*Claims, error) {
 
 	// Check if the token is valid and extract claims
 	if claims, ok := token.Claims.(*Claims); ok && token.Valid {
-		// Additional validation could be done here if needed
+		if a.revocations != nil {
+			userID := claims.UserID
+			if claims.Purpose != "" {
+				userID = 0
+			}
+			var issuedAt time.Time
+			if claims.IssuedAt != nil {
+				issuedAt = claims.IssuedAt.Time
+			}
+			if a.revocations.IsTokenRevoked(claims.ID, userID, issuedAt) {
+				return nil, errors.New("token revoked")
+			}
+		}
 		return claims, nil
 	}
 
@@ -0,0 +1,105 @@
+package auth
+
+import "strings"
+
+// Permission names used by RequirePermission and stored in role_permissions.
+// A permission is "<resource>:<action>"; "<resource>:*" grants every action on
+// a resource and "*" grants everything.
+const (
+	PermissionAll = "*"
+
+	PermArticleWrite     = "article:write"
+	PermArticlePublish   = "article:publish"
+	PermArticleDelete    = "article:delete"
+	PermProjectWrite     = "project:write"
+	PermProjectPublish   = "project:publish"
+	PermProjectDelete    = "project:delete"
+	PermExperienceWrite  = "experience:write"
+	PermExperienceDelete = "experience:delete"
+	PermPageWrite        = "page:write"
+	PermPagePublish      = "page:publish"
+	PermPageDelete       = "page:delete"
+	PermCategoryWrite    = "category:write"
+	PermTagWrite         = "tag:write"
+	PermCommentModerate  = "comment:moderate"
+	PermMediaUpload      = "media:upload"
+	PermMediaDelete      = "media:delete"
+	PermSettingsWrite    = "settings:write"
+	PermUsersManage      = "users:manage"
+	PermRolesManage      = "roles:manage"
+	PermAuditRead        = "audit:read"
+	PermAnalyticsRead    = "analytics:read"
+)
+
+// AllPermissions lists every concrete permission known to the application.
+var AllPermissions = []string{
+	PermArticleWrite,
+	PermArticlePublish,
+	PermArticleDelete,
+	PermProjectWrite,
+	PermProjectPublish,
+	PermProjectDelete,
+	PermExperienceWrite,
+	PermExperienceDelete,
+	PermPageWrite,
+	PermPagePublish,
+	PermPageDelete,
+	PermCategoryWrite,
+	PermTagWrite,
+	PermCommentModerate,
+	PermMediaUpload,
+	PermMediaDelete,
+	PermSettingsWrite,
+	PermUsersManage,
+	PermRolesManage,
+	PermAuditRead,
+	PermAnalyticsRead,
+}
+
+// HasPermission reports whether the granted set satisfies required, honouring
+// the "*" and "<resource>:*" wildcards.
+func HasPermission(granted []string, required string) bool {
+	resource := required
+	if i := strings.Index(required, ":"); i >= 0 {
+		resource = required[:i]
+	}
+	for _, p := range granted {
+		if p == PermissionAll || p == required || p == resource+":*" {
+			return true
+		}
+	}
+	return false
+}
+
+// CanGrant reports whether a holder of granted holds every permission in
+// permissions, so handing them out (e.g. through a role) escalates nothing.
+func CanGrant(granted, permissions []string) bool {
+	for _, p := range permissions {
+		if !HasPermission(granted, p) {
+			return false
+		}
+	}
+	return true
+}
+
+// IsValidPermission reports whether name is a known permission or a supported wildcard.
+func IsValidPermission(name string) bool {
+	if name == PermissionAll {
+		return true
+	}
+	if strings.HasSuffix(name, ":*") {
+		resource := strings.TrimSuffix(name, ":*")
+		for _, p := range AllPermissions {
+			if strings.HasPrefix(p, resource+":") {
+				return true
+			}
+		}
+		return false
+	}
+	for _, p := range AllPermissions {
+		if p == name {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,69 @@
+package auth
+
+import "testing"
+
+func TestHasPermission(t *testing.T) {
+	tests := []struct {
+		name     string
+		granted  []string
+		required string
+		want     bool
+	}{
+		{"exact", []string{PermArticleWrite}, PermArticleWrite, true},
+		{"other action", []string{PermArticleWrite}, PermArticlePublish, false},
+		{"other resource", []string{PermArticleWrite}, PermProjectWrite, false},
+		{"everything", []string{PermissionAll}, PermUsersManage, true},
+		{"resource wildcard", []string{"article:*"}, PermArticleDelete, true},
+		{"wildcard of another resource", []string{"article:*"}, PermPagePublish, false},
+		{"resource prefix is not a resource", []string{"art:*"}, PermArticleWrite, false},
+		{"none", nil, PermArticleWrite, false},
+		{"required wildcard needs a wildcard", []string{PermArticleWrite, PermArticlePublish, PermArticleDelete}, "article:*", false},
+		{"required wildcard", []string{"article:*"}, "article:*", true},
+		{"required everything", []string{"article:*", "users:*"}, PermissionAll, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := HasPermission(tt.granted, tt.required); got != tt.want {
+				t.Fatalf("HasPermission(%v, %q) = %v, want %v", tt.granted, tt.required, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCanGrant(t *testing.T) {
+	editor := []string{"article:*", PermMediaUpload, PermRolesManage}
+	tests := []struct {
+		name        string
+		granted     []string
+		permissions []string
+		want        bool
+	}{
+		{"nothing", editor, nil, true},
+		{"held permissions", editor, []string{PermArticlePublish, PermMediaUpload}, true},
+		{"held wildcard", editor, []string{"article:*"}, true},
+		{"one missing", editor, []string{PermArticleWrite, PermUsersManage}, false},
+		{"everything", editor, []string{PermissionAll}, false},
+		{"wildcard of a partly held resource", editor, []string{"media:*"}, false},
+		{"admin", []string{PermissionAll}, []string{PermissionAll, PermUsersManage}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := CanGrant(tt.granted, tt.permissions); got != tt.want {
+				t.Fatalf("CanGrant(%v, %v) = %v, want %v", tt.granted, tt.permissions, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestIsValidPermission(t *testing.T) {
+	for _, p := range append([]string{PermissionAll, "article:*", "users:*"}, AllPermissions...) {
+		if !IsValidPermission(p) {
+			t.Errorf("IsValidPermission(%q) = false", p)
+		}
+	}
+	for _, p := range []string{"", "article", "article:read", "unknown:*", ":*", "art:*"} {
+		if IsValidPermission(p) {
+			t.Errorf("IsValidPermission(%q) = true", p)
+		}
+	}
+}
@@ -0,0 +1,98 @@
+package auth
+
+import (
+	"encoding/base32"
+	"net/url"
+	"testing"
+	"time"
+)
+
+// rfc6238Secret is the SHA-1 key of the RFC 6238 test vectors
+var rfc6238Secret = base32.StdEncoding.WithPadding(base32.NoPadding).EncodeToString([]byte("12345678901234567890"))
+
+func TestTOTPCodeMatchesRFC6238(t *testing.T) {
+	// RFC 6238 appendix B lists 8-digit codes; 6-digit codes are their
+	// last six digits
+	vectors := []struct {
+		unix int64
+		code string
+	}{
+		{59, "287082"},
+		{1111111109, "081804"},
+		{1111111111, "050471"},
+		{1234567890, "005924"},
+		{2000000000, "279037"},
+		{20000000000, "353130"},
+	}
+	for _, v := range vectors {
+		code, err := TOTPCode(rfc6238Secret, TOTPStep(time.Unix(v.unix, 0)))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if code != v.code {
+			t.Errorf("code at %d = %s, want %s", v.unix, code, v.code)
+		}
+	}
+}
+
+func TestValidateTOTP(t *testing.T) {
+	at := time.Unix(1111111109, 0)
+	step := TOTPStep(at)
+	code := func(step int64) string {
+		c, _ := TOTPCode(rfc6238Secret, step)
+		return c
+	}
+
+	for delta := int64(-TOTPSkew); delta <= TOTPSkew; delta++ {
+		got, ok := ValidateTOTP(rfc6238Secret, code(step+delta), at)
+		if !ok || got != step+delta {
+			t.Errorf("code of step %+d = %d, %v; want step %d", delta, got, ok, step+delta)
+		}
+	}
+	for _, delta := range []int64{-TOTPSkew - 1, TOTPSkew + 1} {
+		if _, ok := ValidateTOTP(rfc6238Secret, code(step+delta), at); ok {
+			t.Errorf("code of step %+d accepted", delta)
+		}
+	}
+	if got, ok := ValidateTOTP(rfc6238Secret, " 081 804 ", at); !ok || got != step {
+		t.Errorf("code with spaces = %d, %v", got, ok)
+	}
+	for _, bad := range []string{"", "08180", "0818040", "abcdef"} {
+		if _, ok := ValidateTOTP(rfc6238Secret, bad, at); ok {
+			t.Errorf("code %q accepted", bad)
+		}
+	}
+	if _, ok := ValidateTOTP("not base32!", "081804", at); ok {
+		t.Error("code accepted with an invalid secret")
+	}
+}
+
+func TestGenerateTOTPSecret(t *testing.T) {
+	secret, err := GenerateTOTPSecret()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(secret) != 32 {
+		t.Fatalf("secret %q is not 160 bits of base32", secret)
+	}
+	if other, _ := GenerateTOTPSecret(); other == secret {
+		t.Fatal("secrets repeat")
+	}
+	if _, err := TOTPCode(secret, 1); err != nil {
+		t.Fatalf("generated secret is not usable: %v", err)
+	}
+}
+
+func TestTOTPURI(t *testing.T) {
+	u, err := url.Parse(TOTPURI("Web Porto CMS", "alice@example.com", rfc6238Secret))
+	if err != nil {
+		t.Fatal(err)
+	}
+	q := u.Query()
+	if u.Scheme != "otpauth" || u.Host != "totp" || u.Path != "/Web Porto CMS:alice@example.com" {
+		t.Errorf("URI %s", u)
+	}
+	if q.Get("secret") != rfc6238Secret || q.Get("issuer") != "Web Porto CMS" || q.Get("digits") != "6" || q.Get("period") != "30" || q.Get("algorithm") != "SHA1" {
+		t.Errorf("URI parameters %v", q)
+	}
+}
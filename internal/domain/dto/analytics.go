@@ -0,0 +1,28 @@
+package dto
+
+import "time"
+
+// PageViewResponse is a raw page view
+type PageViewResponse struct {
+	ID        int       `json:"id"`
+	Page      string    `json:"page"`
+	VisitorID string    `json:"visitorId"`
+	UserAgent string    `json:"userAgent"`
+	Referrer  string    `json:"referrer"`
+	IP        string    `json:"ip"`
+	Country   string    `json:"country"`
+	City      string    `json:"city"`
+	Timestamp time.Time `json:"timestamp"`
+}
+
+// ContentViewResponse is a raw view of an article, project or page
+type ContentViewResponse struct {
+	ID          uint      `json:"id"`
+	ContentID   string    `json:"contentId"`
+	ContentType string    `json:"contentType"`
+	VisitorID   string    `json:"visitorId"`
+	UserAgent   string    `json:"userAgent"`
+	Referrer    string    `json:"referrer"`
+	IP          string    `json:"ip"`
+	Timestamp   time.Time `json:"timestamp"`
+}
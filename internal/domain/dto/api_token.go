@@ -0,0 +1,32 @@
+package dto
+
+import "time"
+
+// CreateAPITokenRequest represents data needed to issue a personal access token.
+// Scopes are permission names; empty means all permissions of the owner.
+type CreateAPITokenRequest struct {
+	Name      string     `json:"name" binding:"required,max=100"`
+	Scopes    []string   `json:"scopes"`
+	ExpiresAt *time.Time `json:"expiresAt"`
+}
+
+// APITokenResponse represents a personal access token without its secret
+type APITokenResponse struct {
+	ID         int        `json:"id"`
+	UserID     int        `json:"userId"`
+	Name       string     `json:"name"`
+	Prefix     string     `json:"prefix"`
+	Scopes     []string   `json:"scopes"`
+	ExpiresAt  *time.Time `json:"expiresAt,omitempty"`
+	LastUsedAt *time.Time `json:"lastUsedAt,omitempty"`
+	LastUsedIP string     `json:"lastUsedIp,omitempty"`
+	RevokedAt  *time.Time `json:"revokedAt,omitempty"`
+	Active     bool       `json:"active"`
+	CreatedAt  time.Time  `json:"createdAt"`
+}
+
+// CreateAPITokenResponse includes the raw token, which is only returned once
+type CreateAPITokenResponse struct {
+	APITokenResponse
+	Token string `json:"token"`
+}
@@ -11,7 +11,7 @@ type CreateArticleRequest struct {
 	Excerpt          string                 `json:"excerpt"`
 	Content          string                 `json:"content" validate:"required"`
 	FeaturedImageURL string                 `json:"featuredImageUrl"`
-	Status           string                 `json:"status" validate:"required,oneof=draft published private"`
+	Status           string                 `json:"status" validate:"required,oneof=draft published scheduled private"`
 	AuthorID         int                    `json:"authorId" validate:"required"`
 	Categories       []int                  `json:"categories"`
 	CategoryIds      []int                  `json:"categoryIds"`
@@ -23,6 +23,13 @@ type CreateArticleRequest struct {
 	Videos           []ArticleVideoData     `json:"videos"`
 	PublishAt        *time.Time             `json:"publishAt"`
 	Metadata         map[string]interface{} `json:"metadata"`
+	SEO              *SEO                   `json:"seo"`
+
+	// UnpublishAt schedules taking the article offline again
+	UnpublishAt *time.Time `json:"unpublishAt"`
+
+	// Set by the server: who is creating, recorded on scheduled jobs
+	EditorID int `json:"-"`
 }
 
 type UpdateArticleRequest struct {
@@ -31,7 +38,7 @@ type UpdateArticleRequest struct {
 	Excerpt          *string                `json:"excerpt"`
 	Content          *string                `json:"content"`
 	FeaturedImageURL *string                `json:"featuredImageUrl"`
-	Status           *string                `json:"status" validate:"omitempty,oneof=draft published private"`
+	Status           *string                `json:"status" validate:"omitempty,oneof=draft published scheduled private"`
 	Categories       []int                  `json:"categories"`
 	CategoryIds      []int                  `json:"categoryIds"`
 	CategoryIdStrs   []string               `json:"categoryIdStrs"`
@@ -42,6 +49,14 @@ type UpdateArticleRequest struct {
 	Videos           []ArticleVideoData     `json:"videos"`
 	PublishAt        *time.Time             `json:"publishAt"`
 	Metadata         map[string]interface{} `json:"metadata"`
+	SEO              *SEO                   `json:"seo"` // nil leaves the stored block unchanged
+
+	// UnpublishAt schedules taking the article offline again
+	UnpublishAt *time.Time `json:"unpublishAt"`
+
+	// Set by the server: who is editing, recorded on the revision snapshot
+	EditorID        int    `json:"-"`
+	RevisionSummary string `json:"-"`
 }
 
 type ArticleImageData struct {
@@ -74,6 +89,7 @@ type ArticleResponse struct {
 	Images           []ArticleImageResponse `json:"images"`
 	Videos           []ArticleVideoResponse `json:"videos"`
 	Metadata         map[string]interface{} `json:"metadata"`
+	SEO              SEO                    `json:"seo"`
 	CreatedAt        time.Time              `json:"createdAt"`
 	UpdatedAt        time.Time              `json:"updatedAt"`
 }
@@ -0,0 +1,21 @@
+package dto
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// AuditLogResponse represents an audit log entry. Changes maps each changed
+// field to {"from": ..., "to": ...}.
+type AuditLogResponse struct {
+	ID          int             `json:"id"`
+	UserID      *int            `json:"userId,omitempty"`
+	Action      string          `json:"action"`
+	EntityType  string          `json:"entityType"`
+	EntityID    string          `json:"entityId"`
+	Description string          `json:"description"`
+	Changes     json.RawMessage `json:"changes"`
+	IP          string          `json:"ip"`
+	UserAgent   string          `json:"userAgent"`
+	CreatedAt   time.Time       `json:"createdAt"`
+}
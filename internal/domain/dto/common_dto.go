@@ -20,14 +20,17 @@ type TagResponse struct {
 	Slug string `json:"slug"`
 }
 
-// PaginationResponse represents pagination metadata
+// PaginationResponse represents pagination metadata. Cursor paginated lists
+// fill NextCursor/PrevCursor instead of the page counts, which stay zero.
 type PaginationResponse struct {
-	TotalCount  int64 `json:"totalCount"`
-	CurrentPage int   `json:"currentPage"`
-	PageSize    int   `json:"pageSize"`
-	TotalPages  int   `json:"totalPages"`
-	HasNext     bool  `json:"hasNext"`
-	HasPrevious bool  `json:"hasPrevious"`
+	TotalCount  int64  `json:"totalCount"`
+	CurrentPage int    `json:"currentPage"`
+	PageSize    int    `json:"pageSize"`
+	TotalPages  int    `json:"totalPages"`
+	HasNext     bool   `json:"hasNext"`
+	HasPrevious bool   `json:"hasPrevious"`
+	NextCursor  string `json:"nextCursor,omitempty"`
+	PrevCursor  string `json:"prevCursor,omitempty"`
 }
 
 // PaginatedResponse is a generic paginated response structure
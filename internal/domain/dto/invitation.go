@@ -0,0 +1,29 @@
+package dto
+
+import "time"
+
+// CreateInvitationRequest represents data needed to invite a user
+type CreateInvitationRequest struct {
+	Email string `json:"email" binding:"required,email"`
+	Role  string `json:"role"`
+}
+
+// InvitationResponse represents an invitation without its token
+type InvitationResponse struct {
+	ID         int        `json:"id"`
+	Email      string     `json:"email"`
+	Role       string     `json:"role"`
+	Status     string     `json:"status"`
+	InvitedBy  *int       `json:"invitedBy,omitempty"`
+	ExpiresAt  time.Time  `json:"expiresAt"`
+	AcceptedAt *time.Time `json:"acceptedAt,omitempty"`
+	AcceptedBy *int       `json:"acceptedBy,omitempty"`
+	RevokedAt  *time.Time `json:"revokedAt,omitempty"`
+	CreatedAt  time.Time  `json:"createdAt"`
+}
+
+// CreateInvitationResponse includes the raw token, which is only returned once
+type CreateInvitationResponse struct {
+	InvitationResponse
+	Token string `json:"token"`
+}
@@ -0,0 +1,68 @@
+package dto
+
+// UpdateMediaRequest edits the library metadata of a media item. Nil fields
+// are left unchanged; a Tags list replaces all tags (empty clears them) and
+// FolderID 0 moves the media to the root. Changing Private moves the files
+// between the public and the private storage.
+type UpdateMediaRequest struct {
+	Title    *string  `json:"title"`
+	AltText  *string  `json:"altText"`
+	Caption  *string  `json:"caption"`
+	Credit   *string  `json:"credit"`
+	FolderID *uint    `json:"folderId"`
+	Tags     []string `json:"tags"`
+	Private  *bool    `json:"private"`
+}
+
+// MediaFolderRequest creates, renames or moves a media folder; a nil or zero
+// ParentID puts it at the root
+type MediaFolderRequest struct {
+	Name     string `json:"name" binding:"required"`
+	ParentID *uint  `json:"parentId"`
+}
+
+// MediaUsage is one place a media file is referenced from
+type MediaUsage struct {
+	EntityType string `json:"entityType"`
+	EntityID   string `json:"entityId"`
+	Title      string `json:"title"`
+	// Field is where the URL appears, e.g. featuredImage, content, gallery
+	Field string `json:"field"`
+}
+
+// MediaQuota reports the upload usage of a user. Bytes counts the uploaded
+// originals, which the quota applies to; VariantBytes the copies generated
+// from them. QuotaBytes 0 is unlimited, and Custom marks a per-user quota.
+type MediaQuota struct {
+	UserID       uint   `json:"userId"`
+	Username     string `json:"username"`
+	Files        int64  `json:"files"`
+	Bytes        int64  `json:"bytes"`
+	VariantBytes int64  `json:"variantBytes"`
+	QuotaBytes   int64  `json:"quotaBytes"`
+	Remaining    *int64 `json:"remaining,omitempty"`
+	Custom       bool   `json:"custom"`
+}
+
+// MediaQuotaRequest sets the upload quota of a user in MB; null returns the
+// user to the default quota and 0 makes it unlimited
+type MediaQuotaRequest struct {
+	QuotaMB *int `json:"quotaMb"`
+}
+
+// CreateUploadRequest starts a resumable upload of a file of Size bytes.
+// Checksum is the optional hex SHA-256 of the whole file, verified when the
+// upload completes. The folder and library fields are those of a regular
+// upload.
+type CreateUploadRequest struct {
+	FileName string `json:"fileName" binding:"required"`
+	Size     int64  `json:"size" binding:"required,min=1"`
+	Checksum string `json:"checksum"`
+	FolderID *uint  `json:"folderId"`
+	Folder   string `json:"folder"`
+	Title    string `json:"title"`
+	AltText  string `json:"altText"`
+	Caption  string `json:"caption"`
+	Credit   string `json:"credit"`
+	Private  bool   `json:"private"`
+}
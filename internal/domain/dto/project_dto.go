@@ -11,7 +11,7 @@ type CreateProjectRequest struct {
 	Description     string                 `json:"description"`
 	Content         string                 `json:"content" validate:"required"`
 	ThumbnailURL    string                 `json:"thumbnailUrl"`
-	Status          string                 `json:"status" validate:"required,oneof=draft published private"`
+	Status          string                 `json:"status" validate:"required,oneof=draft published scheduled private"`
 	CategoryID      *int                   `json:"categoryId"`
 	CategoryIds     []int                  `json:"categoryIds"`
 	CategoryIdStrs  []string               `json:"categoryIdStrs"`
@@ -29,6 +29,12 @@ type CreateProjectRequest struct {
 	TagIdStrs       []string               `json:"tagIdStrs"`
 	TagNames        []string               `json:"tagNames"` // General tags names
 	Metadata        map[string]interface{} `json:"metadata"`
+	SEO             *SEO                   `json:"seo"`
+	PublishAt       *time.Time             `json:"publishAt"`
+	UnpublishAt     *time.Time             `json:"unpublishAt"` // Schedules taking the project offline again
+
+	// Set by the server: who is creating, recorded on scheduled jobs
+	EditorID int `json:"-"`
 }
 
 type UpdateProjectRequest struct {
@@ -37,7 +43,7 @@ type UpdateProjectRequest struct {
 	Description     *string                `json:"description"`
 	Content         *string                `json:"content"`
 	ThumbnailURL    *string                `json:"thumbnailUrl"`
-	Status          *string                `json:"status" validate:"omitempty,oneof=draft published private"`
+	Status          *string                `json:"status" validate:"omitempty,oneof=draft published scheduled private"`
 	CategoryID      *int                   `json:"categoryId"`
 	CategoryIds     []int                  `json:"categoryIds"`
 	CategoryIdStrs  []string               `json:"categoryIdStrs"`
@@ -53,6 +59,13 @@ type UpdateProjectRequest struct {
 	Images          []ProjectImageData     `json:"images"`
 	Videos          []ProjectVideoData     `json:"videos"`
 	Metadata        map[string]interface{} `json:"metadata"`
+	SEO             *SEO                   `json:"seo"` // nil leaves the stored block unchanged
+	PublishAt       *time.Time             `json:"publishAt"`
+	UnpublishAt     *time.Time             `json:"unpublishAt"` // Schedules taking the project offline again
+
+	// Set by the server: who is editing, recorded on the revision snapshot
+	EditorID        int    `json:"-"`
+	RevisionSummary string `json:"-"`
 }
 
 type ProjectImageData struct {
@@ -85,6 +98,8 @@ type ProjectResponse struct {
 	Technologies []TagResponse          `json:"technologies"`
 	Tags         []TagResponse          `json:"tags"`
 	Metadata     map[string]interface{} `json:"metadata"`
+	SEO          SEO                    `json:"seo"`
+	PublishedAt  *time.Time             `json:"publishedAt,omitempty"`
 	CreatedAt    time.Time              `json:"createdAt"`
 	UpdatedAt    time.Time              `json:"updatedAt"`
 }
@@ -125,5 +140,6 @@ type ProjectListResponse struct {
 	Metadata       map[string]interface{} `json:"metadata"`
 	Images         []ProjectImageResponse `json:"images"`
 	Videos         []ProjectVideoResponse `json:"videos"`
+	PublishedAt    *time.Time             `json:"publishedAt,omitempty"`
 	CreatedAt      time.Time              `json:"createdAt"`
 }
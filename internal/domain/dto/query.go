@@ -0,0 +1,67 @@
+package dto
+
+import (
+	"errors"
+	"time"
+)
+
+// ErrInvalidListQuery is returned when a list query names a field that cannot
+// be sorted or filtered on
+var ErrInvalidListQuery = errors.New("invalid list query")
+
+// ListQuery holds the sort, filter and field selection of a list request:
+//
+//	sort=-publishedAt,title
+//	filter[category]=go,rust&filter[tag]=gin
+//	filter[publishedAt][from]=2024-01-01&filter[publishedAt][to]=2024-12-31
+//	fields=id,title,slug
+//
+// Names are API field names; each repository maps them to columns through its
+// own whitelist and rejects the rest.
+type ListQuery struct {
+	Sort []SortField
+	// Filters maps a filter name to its accepted values (comma separated in the query)
+	Filters map[string][]string
+	// Ranges maps a date field to its bounds
+	Ranges map[string]DateRange
+	// Fields is the sparse fieldset of the response items; empty means all
+	Fields []string
+}
+
+// SortField is one sort key; Desc is set by a leading "-"
+type SortField struct {
+	Field string
+	Desc  bool
+}
+
+// DateRange bounds a date field; either side may be open
+type DateRange struct {
+	From *time.Time
+	To   *time.Time
+}
+
+// Filter returns the values of a filter, or nil when it is not set
+func (q ListQuery) Filter(name string) []string {
+	return q.Filters[name]
+}
+
+// Cursor is a keyset position: the creation time and id of a row. Backward
+// cursors page towards newer rows.
+type Cursor struct {
+	CreatedAt time.Time
+	ID        int64
+	Backward  bool
+}
+
+// CursorQuery requests a keyset page of Limit rows, newest first. A nil
+// Cursor starts at the newest row.
+type CursorQuery struct {
+	Cursor *Cursor
+	Limit  int
+}
+
+// CursorResult holds the positions of the neighbouring pages; nil at either end
+type CursorResult struct {
+	Next *Cursor
+	Prev *Cursor
+}
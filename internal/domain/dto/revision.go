@@ -0,0 +1,26 @@
+package dto
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// RevisionResponse represents a stored revision of an article, project or page
+type RevisionResponse struct {
+	ID        int             `json:"id"`
+	EntityID  string          `json:"entityId"`
+	Version   int             `json:"version"`
+	Title     string          `json:"title"`
+	UserID    *int            `json:"userId,omitempty"`
+	Summary   string          `json:"summary,omitempty"`
+	Snapshot  json.RawMessage `json:"snapshot,omitempty"`
+	CreatedAt time.Time       `json:"createdAt"`
+}
+
+// RevisionDiffResponse holds a unified diff between two versions. To is
+// "current" when comparing against the live item.
+type RevisionDiffResponse struct {
+	From string `json:"from"`
+	To   string `json:"to"`
+	Diff string `json:"diff"`
+}
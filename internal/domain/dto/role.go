@@ -0,0 +1,34 @@
+package dto
+
+import "time"
+
+// CreateRoleRequest represents data needed to create a role
+type CreateRoleRequest struct {
+	Name        string   `json:"name" binding:"required"`
+	Description string   `json:"description"`
+	Permissions []string `json:"permissions"`
+}
+
+// UpdateRoleRequest represents data needed to update a role.
+// Omitted fields are left unchanged.
+type UpdateRoleRequest struct {
+	Name        *string  `json:"name"`
+	Description *string  `json:"description"`
+	Permissions []string `json:"permissions"`
+}
+
+// AssignRoleRequest assigns a role to a user
+type AssignRoleRequest struct {
+	UserID int `json:"userId" binding:"required"`
+}
+
+// RoleResponse represents a role with its permissions
+type RoleResponse struct {
+	ID          int       `json:"id"`
+	Name        string    `json:"name"`
+	Description string    `json:"description"`
+	IsSystem    bool      `json:"isSystem"`
+	Permissions []string  `json:"permissions"`
+	CreatedAt   time.Time `json:"createdAt"`
+	UpdatedAt   time.Time `json:"updatedAt"`
+}
@@ -0,0 +1,18 @@
+package dto
+
+import "time"
+
+// ScheduledJobResponse represents a scheduled publish or unpublish
+type ScheduledJobResponse struct {
+	ID         int        `json:"id"`
+	EntityType string     `json:"entityType"`
+	EntityID   string     `json:"entityId"`
+	Action     string     `json:"action"`
+	RunAt      time.Time  `json:"runAt"`
+	Status     string     `json:"status"`
+	Attempts   int        `json:"attempts"`
+	LastError  string     `json:"lastError,omitempty"`
+	ExecutedAt *time.Time `json:"executedAt,omitempty"`
+	CreatedBy  *int       `json:"createdBy,omitempty"`
+	CreatedAt  time.Time  `json:"createdAt"`
+}
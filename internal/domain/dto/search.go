@@ -0,0 +1,15 @@
+package dto
+
+import "time"
+
+// SearchResultResponse is a single full-text search hit. Snippet is
+// HTML-escaped text with matches wrapped in <mark></mark>.
+type SearchResultResponse struct {
+	Type    string     `json:"type"`
+	ID      string     `json:"id"`
+	Title   string     `json:"title"`
+	Slug    string     `json:"slug,omitempty"`
+	Snippet string     `json:"snippet"`
+	Rank    float64    `json:"rank"`
+	Date    *time.Time `json:"date,omitempty"`
+}
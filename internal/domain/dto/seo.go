@@ -0,0 +1,92 @@
+package dto
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+	"unicode/utf8"
+	"web-porto-backend/common/validation"
+)
+
+// Limits of the SEO block; longer values are cut off by search engines
+const (
+	MaxMetaTitleLength       = 70
+	MaxMetaDescriptionLength = 200
+)
+
+// Twitter card types accepted in SEO.TwitterCard
+const (
+	TwitterCardSummary      = "summary"
+	TwitterCardSummaryLarge = "summary_large_image"
+)
+
+// SEO is the SEO block of article, project and page requests and responses.
+// Every field is optional.
+type SEO struct {
+	MetaTitle       string `json:"metaTitle,omitempty"`
+	MetaDescription string `json:"metaDescription,omitempty"`
+	// CanonicalURL must be an absolute http(s) URL
+	CanonicalURL string `json:"canonicalUrl,omitempty"`
+	// OGImage is an absolute http(s) URL or a path such as /uploads/a.jpg
+	OGImage     string `json:"ogImage,omitempty"`
+	TwitterCard string `json:"twitterCard,omitempty"`
+	NoIndex     bool   `json:"noindex,omitempty"`
+}
+
+// Validate checks lengths, URLs and the card type. A nil block is valid.
+func (s *SEO) Validate() error {
+	if s == nil {
+		return nil
+	}
+	var errs validation.ValidationErrors
+	if utf8.RuneCountInString(s.MetaTitle) > MaxMetaTitleLength {
+		errs = append(errs, validation.ValidationError{Field: "seo.metaTitle", Message: fmt.Sprintf("must be at most %d characters", MaxMetaTitleLength)})
+	}
+	if utf8.RuneCountInString(s.MetaDescription) > MaxMetaDescriptionLength {
+		errs = append(errs, validation.ValidationError{Field: "seo.metaDescription", Message: fmt.Sprintf("must be at most %d characters", MaxMetaDescriptionLength)})
+	}
+	if s.CanonicalURL != "" && !isAbsoluteURL(s.CanonicalURL) {
+		errs = append(errs, validation.ValidationError{Field: "seo.canonicalUrl", Message: "must be an absolute http or https URL"})
+	}
+	if s.OGImage != "" && !isAbsoluteURL(s.OGImage) && !(strings.HasPrefix(s.OGImage, "/") && !strings.HasPrefix(s.OGImage, "//")) {
+		errs = append(errs, validation.ValidationError{Field: "seo.ogImage", Message: "must be an absolute http or https URL or a path starting with /"})
+	}
+	switch s.TwitterCard {
+	case "", TwitterCardSummary, TwitterCardSummaryLarge:
+	default:
+		errs = append(errs, validation.ValidationError{Field: "seo.twitterCard", Message: "must be summary or summary_large_image"})
+	}
+	if len(errs) > 0 {
+		return errs
+	}
+	return nil
+}
+
+func isAbsoluteURL(v string) bool {
+	u, err := url.Parse(v)
+	return err == nil && (u.Scheme == "http" || u.Scheme == "https") && u.Host != ""
+}
+
+// SEOHeadResponse holds the computed head metadata of a piece of content,
+// with fallbacks applied. Tags lists every element in document order; HTML
+// is the same markup ready to be placed in <head>.
+type SEOHeadResponse struct {
+	Title        string    `json:"title"`
+	Description  string    `json:"description"`
+	CanonicalURL string    `json:"canonicalUrl"`
+	Robots       string    `json:"robots"`
+	Image        string    `json:"image,omitempty"`
+	TwitterCard  string    `json:"twitterCard"`
+	Tags         []HeadTag `json:"tags"`
+	HTML         string    `json:"html"`
+}
+
+// HeadTag is one <title>, <meta> or <link> element
+type HeadTag struct {
+	Tag      string `json:"tag"`
+	Name     string `json:"name,omitempty"`
+	Property string `json:"property,omitempty"`
+	Rel      string `json:"rel,omitempty"`
+	Href     string `json:"href,omitempty"`
+	Content  string `json:"content,omitempty"`
+}
@@ -0,0 +1,26 @@
+package models
+
+import "time"
+
+// APIToken is a long-lived personal access token for scripts and CI. Only the
+// SHA-256 hash is stored; Prefix keeps the first characters so users can tell
+// tokens apart. Scopes limit the token to a subset of the owner's permissions.
+type APIToken struct {
+	ID         int         `gorm:"primaryKey"`
+	UserID     int         `gorm:"not null;index"`
+	Name       string      `gorm:"not null"`
+	TokenHash  string      `gorm:"uniqueIndex;not null"`
+	Prefix     string      `gorm:"not null"`
+	Scopes     StringArray `gorm:"type:jsonb;not null;default:'[]'"`
+	ExpiresAt  *time.Time
+	LastUsedAt *time.Time
+	LastUsedIP string
+	RevokedAt  *time.Time
+	CreatedAt  time.Time
+	UpdatedAt  time.Time
+}
+
+// IsActive reports whether the token can still authenticate
+func (t *APIToken) IsActive(now time.Time) bool {
+	return t.RevokedAt == nil && (t.ExpiresAt == nil || t.ExpiresAt.After(now))
+}
@@ -22,6 +22,7 @@ type Article struct {
 	ReadTime         int            `gorm:"default:0"`
 	ViewCount        int            `gorm:"default:0"`
 	Metadata         string         `gorm:"type:jsonb"`
+	SEO              SEO            `gorm:"column:seo;type:jsonb;default:'{}'"`
 	Categories       []Category     `gorm:"many2many:article_categories;"`
 	Tags             []Tag          `gorm:"many2many:article_tags;"`
 	Images           []ArticleImage `gorm:"foreignKey:ArticleID;constraint:OnDelete:CASCADE;"`
@@ -0,0 +1,39 @@
+package models
+
+import "time"
+
+const (
+	InvitationStatusPending  = "pending"
+	InvitationStatusAccepted = "accepted"
+	InvitationStatusRevoked  = "revoked"
+	InvitationStatusExpired  = "expired"
+)
+
+// Invitation is a single-use registration token tied to an email and role.
+// Only the SHA-256 hash of the token is stored.
+type Invitation struct {
+	ID         int        `gorm:"primaryKey" json:"id"`
+	Email      string     `gorm:"not null;index" json:"email"`
+	Role       string     `gorm:"not null" json:"role"`
+	TokenHash  string     `gorm:"uniqueIndex;not null" json:"-"`
+	InvitedBy  *int       `json:"invitedBy,omitempty"`
+	ExpiresAt  time.Time  `gorm:"not null" json:"expiresAt"`
+	AcceptedAt *time.Time `json:"acceptedAt,omitempty"`
+	AcceptedBy *int       `json:"acceptedBy,omitempty"`
+	RevokedAt  *time.Time `json:"revokedAt,omitempty"`
+	CreatedAt  time.Time  `json:"createdAt"`
+}
+
+// Status derives the invitation state from its timestamps.
+func (i *Invitation) Status() string {
+	switch {
+	case i.AcceptedAt != nil:
+		return InvitationStatusAccepted
+	case i.RevokedAt != nil:
+		return InvitationStatusRevoked
+	case time.Now().After(i.ExpiresAt):
+		return InvitationStatusExpired
+	default:
+		return InvitationStatusPending
+	}
+}
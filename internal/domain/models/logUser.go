@@ -2,10 +2,17 @@ package models
 
 import "time"
 
+// Log is an audit entry. Changes holds a JSON object of changed fields,
+// each as {"from": ..., "to": ...}.
 type Log struct {
-	ID          int `gorm:"primaryKey"`
-	UserID      *int
-	Action      string `gorm:"not null"`
+	ID          int    `gorm:"primaryKey"`
+	UserID      *int   `gorm:"index"`
+	Action      string `gorm:"not null;index"`
+	EntityType  string `gorm:"index"`
+	EntityID    string
 	Description string
-	CreatedAt   time.Time
+	Changes     string `gorm:"type:jsonb;default:'{}'"`
+	IP          string
+	UserAgent   string
+	CreatedAt   time.Time `gorm:"index"`
 }
@@ -0,0 +1,31 @@
+package models
+
+import "time"
+
+const (
+	LoginThrottleScopeAccount = "account"
+	LoginThrottleScopeIP      = "ip"
+)
+
+// LoginAttempt is an append-only record of every login attempt.
+type LoginAttempt struct {
+	ID        int       `gorm:"primaryKey" json:"id"`
+	UserID    *int      `gorm:"index" json:"userId,omitempty"`
+	Email     string    `gorm:"index" json:"email"`
+	IP        string    `gorm:"index" json:"ip"`
+	UserAgent string    `json:"userAgent"`
+	Success   bool      `gorm:"not null" json:"success"`
+	Reason    string    `json:"reason,omitempty"`
+	CreatedAt time.Time `gorm:"index" json:"createdAt"`
+}
+
+// LoginThrottle tracks consecutive failures for an account or an IP address
+// and the time until which further attempts are rejected.
+type LoginThrottle struct {
+	Scope         string     `gorm:"primaryKey;size:20" json:"scope"`
+	Identifier    string     `gorm:"primaryKey;size:255" json:"identifier"`
+	Failures      int        `gorm:"not null;default:0" json:"failures"`
+	LockedUntil   *time.Time `json:"lockedUntil,omitempty"`
+	LastFailureAt time.Time  `json:"lastFailureAt"`
+	UpdatedAt     time.Time  `json:"updatedAt"`
+}
@@ -1,6 +1,20 @@
 package models
 
-import "time"
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"time"
+)
+
+// Media processing statuses. Files that are not processed (PDFs, videos,
+// SVGs) keep an empty status.
+const (
+	MediaStatusPending    = "pending"
+	MediaStatusProcessing = "processing"
+	MediaStatusReady      = "ready"
+	MediaStatusFailed     = "failed"
+)
 
 // Media represents an uploaded file stored on the server
 type Media struct {
@@ -14,4 +28,94 @@ type Media struct {
 	MimeType     string    `gorm:"not null" json:"mimeType"`
 	UploadedBy   *uint     `json:"uploadedBy,omitempty"`
 	UploadedAt   time.Time `gorm:"autoCreateTime" json:"uploadedAt"`
+
+	// Storage is the backend holding the file and StorageKey its key there;
+	// FilePath is the location in that backend
+	Storage    string `gorm:"not null;default:'local'" json:"storage"`
+	StorageKey string `gorm:"not null;default:''" json:"storageKey"`
+	// Private media live in the private backend and are only served through
+	// signed, expiring URLs
+	Private bool `gorm:"not null;default:false;index" json:"private"`
+
+	// Library metadata
+	Title    string       `gorm:"not null;default:''" json:"title"`
+	AltText  string       `gorm:"not null;default:''" json:"altText"`
+	Caption  string       `gorm:"not null;default:''" json:"caption"`
+	Credit   string       `gorm:"not null;default:''" json:"credit"`
+	FolderID *uint        `gorm:"index" json:"folderId"`
+	Folder   *MediaFolder `gorm:"foreignKey:FolderID;constraint:OnDelete:SET NULL" json:"folder,omitempty"`
+	Tags     []Tag        `gorm:"many2many:media_tags;constraint:OnDelete:CASCADE;" json:"tags"`
+
+	// Width and Height are the displayed dimensions of images, 0 otherwise
+	Width    int           `gorm:"not null;default:0" json:"width"`
+	Height   int           `gorm:"not null;default:0" json:"height"`
+	Variants MediaVariants `gorm:"type:jsonb;not null;default:'[]'" json:"variants"`
+
+	ProcessingStatus    string     `gorm:"not null;default:''" json:"processingStatus"`
+	ProcessingError     string     `gorm:"not null;default:''" json:"processingError,omitempty"`
+	ProcessingAttempts  int        `gorm:"not null;default:0" json:"-"`
+	ProcessingStartedAt *time.Time `json:"-"`
+	ProcessedAt         *time.Time `json:"processedAt,omitempty"`
+}
+
+// Keys returns the storage keys of the original and all generated variants
+func (m *Media) Keys() []string {
+	keys := []string{m.StorageKey}
+	for _, v := range m.Variants {
+		if v.Key != "" {
+			keys = append(keys, v.Key)
+		}
+	}
+	return keys
+}
+
+// MediaFolder groups media in the library. Path is the slash-joined chain of
+// folder names, used as the storage key prefix of uploads into the folder;
+// files keep their key when media moves between folders.
+type MediaFolder struct {
+	ID        uint      `gorm:"primaryKey;autoIncrement" json:"id"`
+	Name      string    `gorm:"not null" json:"name"`
+	ParentID  *uint     `gorm:"index" json:"parentId"`
+	Path      string    `gorm:"uniqueIndex;not null" json:"path"`
+	CreatedAt time.Time `json:"createdAt"`
+	UpdatedAt time.Time `json:"updatedAt"`
+}
+
+// MediaVariant is a resized or re-encoded copy of an image
+type MediaVariant struct {
+	Width    int    `json:"width"`
+	Height   int    `json:"height"`
+	MimeType string `json:"mimeType"`
+	URL      string `json:"url"`
+	Key      string `json:"key"`
+	Size     int64  `json:"size"`
+}
+
+// MediaVariants is stored in the variants jsonb column
+type MediaVariants []MediaVariant
+
+// Value implements the driver.Valuer interface
+func (v MediaVariants) Value() (driver.Value, error) {
+	if v == nil {
+		return "[]", nil
+	}
+	b, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	return string(b), nil
+}
+
+// Scan implements the sql.Scanner interface
+func (v *MediaVariants) Scan(value interface{}) error {
+	*v = nil
+	switch data := value.(type) {
+	case nil:
+		return nil
+	case []byte:
+		return json.Unmarshal(data, v)
+	case string:
+		return json.Unmarshal([]byte(data), v)
+	}
+	return errors.New("unsupported type for MediaVariants")
 }
@@ -3,11 +3,13 @@ package models
 import "time"
 
 type Page struct {
-	ID        int    `gorm:"primaryKey"`
-	Title     string `gorm:"not null"`
-	Slug      string `gorm:"unique;not null"`
-	Content   string `gorm:"not null"`
-	Status    string `gorm:"not null"`
-	CreatedAt time.Time
-	UpdatedAt time.Time
+	ID          int    `gorm:"primaryKey"`
+	Title       string `gorm:"not null"`
+	Slug        string `gorm:"unique;not null"`
+	Content     string `gorm:"not null"`
+	Status      string `gorm:"not null"`
+	SEO         SEO    `gorm:"column:seo;type:jsonb;default:'{}'"`
+	PublishedAt *time.Time
+	CreatedAt   time.Time
+	UpdatedAt   time.Time
 }
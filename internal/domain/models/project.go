@@ -23,10 +23,12 @@ type Project struct {
 	Technologies []Tag          `gorm:"many2many:project_technologies;"`
 	Tags         []Tag          `gorm:"many2many:project_tags;"`
 	Metadata     string         `gorm:"type:jsonb;default:'{}'"`
+	SEO          SEO            `gorm:"column:seo;type:jsonb;default:'{}'"`
 	GitHubURL    string         `gorm:"column:github_url"`
 	LiveDemoURL  string         `gorm:"column:live_demo_url"`
 	Images       []ProjectImage `gorm:"foreignKey:ProjectID;constraint:OnDelete:CASCADE;"`
 	Videos       []ProjectVideo `gorm:"foreignKey:ProjectID;constraint:OnDelete:CASCADE;"`
+	PublishedAt  *time.Time
 	CreatedAt    time.Time
 	UpdatedAt    time.Time
 }
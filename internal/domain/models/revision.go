@@ -0,0 +1,23 @@
+package models
+
+import "time"
+
+// Revision tables, one per content type
+const (
+	ArticleRevisionTable = "article_revisions"
+	ProjectRevisionTable = "project_revisions"
+	PageRevisionTable    = "page_revisions"
+)
+
+// Revision is a snapshot of a content item taken before it was changed.
+// Snapshot holds the JSON-encoded state; its shape depends on the content type.
+type Revision struct {
+	ID        int    `gorm:"primaryKey"`
+	EntityID  string `gorm:"not null;index"`
+	Version   int    `gorm:"not null"`
+	Title     string
+	Snapshot  string `gorm:"type:jsonb;not null"`
+	UserID    *int
+	Summary   string
+	CreatedAt time.Time
+}
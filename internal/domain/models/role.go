@@ -1,9 +1,31 @@
 package models
 
+import "time"
+
+// Role groups a set of named permissions. A user's effective permissions are
+// the union of the role named in User.Role and any roles assigned via UserRole.
 type Role struct {
-	ID          int    `gorm:"primaryKey"`
-	Name        string `gorm:"unique;not null"`
-	Description string
+	ID          int              `gorm:"primaryKey" json:"id"`
+	Name        string           `gorm:"unique;not null" json:"name"`
+	Description string           `json:"description"`
+	IsSystem    bool             `gorm:"not null;default:false" json:"isSystem"`
+	Permissions []RolePermission `gorm:"foreignKey:RoleID;constraint:OnDelete:CASCADE;" json:"-"`
+	CreatedAt   time.Time        `json:"createdAt"`
+	UpdatedAt   time.Time        `json:"updatedAt"`
+}
+
+// PermissionNames returns the role's permissions as plain strings.
+func (r *Role) PermissionNames() []string {
+	names := make([]string, 0, len(r.Permissions))
+	for _, p := range r.Permissions {
+		names = append(names, p.Permission)
+	}
+	return names
+}
+
+type RolePermission struct {
+	RoleID     int    `gorm:"primaryKey"`
+	Permission string `gorm:"primaryKey;size:100"`
 }
 
 type UserRole struct {
@@ -0,0 +1,32 @@
+package models
+
+import "time"
+
+// Scheduled job statuses
+const (
+	JobStatusPending   = "pending"
+	JobStatusRunning   = "running"
+	JobStatusDone      = "done"
+	JobStatusFailed    = "failed"
+	JobStatusCancelled = "cancelled"
+)
+
+// ScheduledJob is a publish or unpublish of a content item due at RunAt.
+// Jobs live in the database so they survive restarts; workers claim them
+// with SELECT ... FOR UPDATE SKIP LOCKED so each job runs once.
+type ScheduledJob struct {
+	ID         int       `gorm:"primaryKey"`
+	EntityType string    `gorm:"not null;index:idx_scheduled_jobs_entity"`
+	EntityID   string    `gorm:"not null;index:idx_scheduled_jobs_entity"`
+	Action     string    `gorm:"not null"`
+	RunAt      time.Time `gorm:"not null;index"`
+	Status     string    `gorm:"not null;default:'pending';index"`
+	Attempts   int       `gorm:"not null;default:0"`
+	LastError  string
+	LockedBy   string
+	LockedAt   *time.Time
+	ExecutedAt *time.Time
+	CreatedBy  *int
+	CreatedAt  time.Time
+	UpdatedAt  time.Time
+}
@@ -0,0 +1,42 @@
+package models
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+)
+
+// SEO is the search and social metadata of an article, project or page,
+// stored in the seo jsonb column. Empty fields fall back to the content
+// itself when head tags are built.
+type SEO struct {
+	MetaTitle       string `json:"metaTitle,omitempty"`
+	MetaDescription string `json:"metaDescription,omitempty"`
+	CanonicalURL    string `json:"canonicalUrl,omitempty"`
+	OGImage         string `json:"ogImage,omitempty"`
+	TwitterCard     string `json:"twitterCard,omitempty"`
+	NoIndex         bool   `json:"noindex,omitempty"`
+}
+
+// Value implements the driver.Valuer interface
+func (s SEO) Value() (driver.Value, error) {
+	b, err := json.Marshal(s)
+	if err != nil {
+		return nil, err
+	}
+	return string(b), nil
+}
+
+// Scan implements the sql.Scanner interface
+func (s *SEO) Scan(value interface{}) error {
+	*s = SEO{}
+	switch v := value.(type) {
+	case nil:
+		return nil
+	case []byte:
+		return json.Unmarshal(v, s)
+	case string:
+		return json.Unmarshal([]byte(v), s)
+	}
+	return errors.New("unsupported type for SEO")
+}
@@ -0,0 +1,20 @@
+package models
+
+import "time"
+
+// Content statuses shared by articles, projects, pages and posts
+const (
+	StatusDraft     = "draft"
+	StatusPublished = "published"
+	StatusScheduled = "scheduled"
+	StatusPrivate   = "private"
+
+	// StatusAll disables status filtering on CMS listings
+	StatusAll = "all"
+)
+
+// IsPubliclyVisible reports whether content in the given state may be shown
+// to visitors: it must be published and its publish date must have passed
+func IsPubliclyVisible(status string, publishedAt *time.Time) bool {
+	return status == StatusPublished && publishedAt != nil && !publishedAt.After(time.Now())
+}
@@ -0,0 +1,28 @@
+package models
+
+import "time"
+
+// RefreshToken is a persisted refresh credential. Only the SHA-256 hash of the
+// raw token is stored. Tokens issued from the same login share a FamilyID so
+// that reuse of a rotated token can revoke the whole chain.
+type RefreshToken struct {
+	ID         int       `gorm:"primaryKey"`
+	UserID     int       `gorm:"not null;index"`
+	FamilyID   string    `gorm:"type:uuid;not null;index"`
+	TokenHash  string    `gorm:"uniqueIndex;not null"`
+	ExpiresAt  time.Time `gorm:"not null"`
+	RevokedAt  *time.Time
+	ReplacedBy *int
+	UserAgent  string
+	IP         string
+	CreatedAt  time.Time
+}
+
+// RevokedToken records an access token ID (jti) that must be rejected before
+// its natural expiry.
+type RevokedToken struct {
+	JTI       string    `gorm:"primaryKey;column:jti"`
+	UserID    int       `gorm:"index"`
+	ExpiresAt time.Time `gorm:"not null;index"`
+	CreatedAt time.Time
+}
@@ -0,0 +1,24 @@
+package models
+
+import "time"
+
+// TwoFactor holds a user's TOTP enrollment. Enabled stays false until the
+// first code has been verified.
+type TwoFactor struct {
+	UserID       int    `gorm:"primaryKey"`
+	Secret       string `gorm:"not null"`
+	Enabled      bool   `gorm:"not null;default:false"`
+	LastUsedStep int64  `gorm:"not null;default:0"`
+	EnabledAt    *time.Time
+	CreatedAt    time.Time
+	UpdatedAt    time.Time
+}
+
+// RecoveryCode is a single-use 2FA backup code; only its SHA-256 hash is stored.
+type RecoveryCode struct {
+	ID        int    `gorm:"primaryKey"`
+	UserID    int    `gorm:"not null;index"`
+	CodeHash  string `gorm:"not null;uniqueIndex"`
+	UsedAt    *time.Time
+	CreatedAt time.Time
+}
@@ -0,0 +1,52 @@
+package models
+
+import "time"
+
+// Upload session statuses
+const (
+	UploadStatusUploading  = "uploading"
+	UploadStatusCompleting = "completing"
+	UploadStatusCompleted  = "completed"
+)
+
+// UploadSession is a resumable upload in progress. Chunks are staged outside
+// the storage backends until the upload completes; only then is the file
+// stored and its Media row created. Sessions expire ExpiresAt, which each
+// received chunk pushes back.
+type UploadSession struct {
+	ID        string `gorm:"primaryKey;type:varchar(36)" json:"id"`
+	UserID    uint   `gorm:"not null;index" json:"userId"`
+	FileName  string `gorm:"not null" json:"fileName"`
+	MimeType  string `gorm:"not null" json:"mimeType"`
+	Size      int64  `gorm:"not null" json:"size"`
+	ChunkSize int64  `gorm:"not null" json:"chunkSize"`
+	// Checksum is the optional hex SHA-256 of the whole file
+	Checksum string `gorm:"not null;default:''" json:"checksum,omitempty"`
+
+	// Library fields the media gets
+	FolderID *uint  `json:"folderId"`
+	Title    string `gorm:"not null;default:''" json:"title"`
+	AltText  string `gorm:"not null;default:''" json:"altText"`
+	Caption  string `gorm:"not null;default:''" json:"caption"`
+	Credit   string `gorm:"not null;default:''" json:"credit"`
+	Private  bool   `gorm:"not null;default:false" json:"private"`
+
+	Status    string    `gorm:"not null;default:'uploading'" json:"status"`
+	MediaID   *uint     `json:"mediaId,omitempty"`
+	ExpiresAt time.Time `gorm:"not null;index" json:"expiresAt"`
+	CreatedAt time.Time `json:"createdAt"`
+	UpdatedAt time.Time `json:"updatedAt"`
+}
+
+// Chunks is the number of chunks the file is sent in
+func (u *UploadSession) Chunks() int {
+	if u.Size == 0 {
+		return 1
+	}
+	return int((u.Size + u.ChunkSize - 1) / u.ChunkSize)
+}
+
+// ChunkLength is the size chunk index must have; only the last is shorter
+func (u *UploadSession) ChunkLength(index int) int64 {
+	return min(u.ChunkSize, u.Size-int64(index)*u.ChunkSize)
+}
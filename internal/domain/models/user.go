@@ -2,12 +2,25 @@ package models
 
 import "time"
 
+const (
+	UserStatusActive   = "active"
+	UserStatusDisabled = "disabled"
+)
+
 type User struct {
 	ID           int    `gorm:"primaryKey"`
 	Username     string `gorm:"unique;not null"`
 	Email        string `gorm:"unique;not null"`
 	PasswordHash string `gorm:"not null"`
 	Role         string `gorm:"not null"`
-	CreatedAt    time.Time
-	UpdatedAt    time.Time
+	Status       string `gorm:"not null;default:'active'"`
+	// EmailVerifiedAt is nil until the user confirms their email address
+	EmailVerifiedAt *time.Time
+	// UploadQuotaMB overrides the default media upload quota; nil uses the
+	// default and 0 is unlimited
+	UploadQuotaMB *int
+	// TokensRevokedAt rejects access tokens issued at or before it
+	TokensRevokedAt *time.Time
+	CreatedAt       time.Time
+	UpdatedAt       time.Time
 }
@@ -6,6 +6,7 @@ import (
 
 	applog "web-porto-backend/common/logger"
 	"web-porto-backend/common/response"
+	"web-porto-backend/internal/domain/dto"
 	svc "web-porto-backend/internal/services/analytics"
 
 	"github.com/gin-gonic/gin"
@@ -188,3 +189,46 @@ func (h *Handler) GetContentViewAnalytics(c *gin.Context) {
 	log.Info("get content view analytics ok", applog.Fields{"contentId": contentID, "type": contentTypeStr, "points": len(dataPoints)})
 	h.httpAdapter.SendSuccessResponse(c, http.StatusOK, dataPoints, "Content view analytics retrieved successfully")
 }
+
+// ListContentViews handles GET /api/v1/analytics/content-views?cursor=&limit=&contentId=&contentType=
+// Raw content views, newest first, cursor paginated
+func (h *Handler) ListContentViews(c *gin.Context) {
+	log := applog.GetLogger().WithFields(applog.Fields{"handler": "analytics.ListContentViews"})
+	q, ok := h.httpAdapter.CursorQuery(c)
+	if !ok {
+		return
+	}
+
+	contentType := svc.ContentType(c.Query("contentType"))
+	switch contentType {
+	case "", svc.ContentTypeArticle, svc.ContentTypeProject, svc.ContentTypePage:
+	default:
+		c.JSON(http.StatusBadRequest, response.NewErrorResponse("Invalid contentType", "contentType must be article, project or page"))
+		return
+	}
+
+	views, result, err := h.service.ListContentViews(c.Query("contentId"), contentType, q)
+	if err != nil {
+		log.Error("list content views failed", applog.Fields{"error": err.Error()})
+		c.JSON(http.StatusInternalServerError, response.NewErrorResponse("Failed to list content views", err.Error()))
+		return
+	}
+
+	data := make([]dto.ContentViewResponse, 0, len(views))
+	for _, v := range views {
+		data = append(data, dto.ContentViewResponse{
+			ID:          v.ID,
+			ContentID:   v.ContentID,
+			ContentType: v.Type,
+			VisitorID:   v.VisitorID,
+			UserAgent:   v.UserAgent,
+			Referrer:    v.Referrer,
+			IP:          v.IP,
+			Timestamp:   v.Timestamp,
+		})
+	}
+	h.httpAdapter.SendSuccessResponse(c, http.StatusOK, dto.PaginatedResponse{
+		Data:       data,
+		Pagination: h.httpAdapter.CursorPagination(q, result),
+	}, "Content views fetched")
+}
@@ -6,7 +6,9 @@ import (
 	"time"
 	applog "web-porto-backend/common/logger"
 	"web-porto-backend/common/response"
+	"web-porto-backend/common/utils"
 	httpAdapter "web-porto-backend/internal/adapters/http"
+	"web-porto-backend/internal/domain/dto"
 	"web-porto-backend/internal/domain/models"
 	svc "web-porto-backend/internal/services/analytics"
 
@@ -140,3 +142,57 @@ func (h *Handler) GetTopPages(c *gin.Context) {
 	}
 	h.httpAdapter.SendSuccessResponse(c, http.StatusOK, pages, "Top pages fetched")
 }
+
+// GET /api/v1/analytics/page-views?cursor=&limit=&page=&visitorId=&country=&from=&to=
+// Raw page views, newest first, cursor paginated
+func (h *Handler) ListViews(c *gin.Context) {
+	log := applog.GetLogger().WithFields(applog.Fields{"handler": "analytics.ListViews"})
+	q, ok := h.httpAdapter.CursorQuery(c)
+	if !ok {
+		return
+	}
+
+	filter := svc.ViewFilter{
+		Page:      c.Query("page"),
+		VisitorID: c.Query("visitorId"),
+		Country:   c.Query("country"),
+	}
+	for param, target := range map[string]**time.Time{"from": &filter.From, "to": &filter.To} {
+		v := c.Query(param)
+		if v == "" {
+			continue
+		}
+		t, err := utils.ParseDate(v, param == "to")
+		if err != nil {
+			c.JSON(http.StatusBadRequest, response.NewErrorResponse("Invalid "+param+" date", err.Error()))
+			return
+		}
+		*target = &t
+	}
+
+	views, result, err := h.service.ListViews(filter, q)
+	if err != nil {
+		log.Error("list views failed", applog.Fields{"error": err.Error()})
+		c.JSON(http.StatusInternalServerError, response.NewErrorResponse("Failed to list page views", err.Error()))
+		return
+	}
+
+	data := make([]dto.PageViewResponse, 0, len(views))
+	for _, v := range views {
+		data = append(data, dto.PageViewResponse{
+			ID:        v.ID,
+			Page:      v.Page,
+			VisitorID: v.VisitorID,
+			UserAgent: v.UserAgent,
+			Referrer:  v.Referrer,
+			IP:        v.IP,
+			Country:   v.Country,
+			City:      v.City,
+			Timestamp: v.Timestamp,
+		})
+	}
+	h.httpAdapter.SendSuccessResponse(c, http.StatusOK, dto.PaginatedResponse{
+		Data:       data,
+		Pagination: h.httpAdapter.CursorPagination(q, result),
+	}, "Page views fetched")
+}
@@ -0,0 +1,141 @@
+package apitoken
+
+import (
+	"errors"
+	"net/http"
+	"time"
+	"web-porto-backend/common/response"
+	httpAdapter "web-porto-backend/internal/adapters/http"
+	"web-porto-backend/internal/domain/dto"
+	"web-porto-backend/internal/domain/models"
+	"web-porto-backend/internal/services/apitoken"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Handler handles HTTP requests for personal access tokens
+type Handler struct {
+	service     apitoken.Service
+	httpAdapter *httpAdapter.HTTPAdapter
+}
+
+// NewHandler creates a new API token handler
+func NewHandler(service apitoken.Service, httpAdapter *httpAdapter.HTTPAdapter) *Handler {
+	return &Handler{
+		service:     service,
+		httpAdapter: httpAdapter,
+	}
+}
+
+const msgInvalidTokenID = "Invalid token ID"
+
+// GetMine lists the current user's tokens
+func (h *Handler) GetMine(c *gin.Context) {
+	tokens, err := h.service.ListForUser(c.GetInt("user_id"))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, response.NewErrorResponse("Failed to retrieve API tokens", err.Error()))
+		return
+	}
+	h.httpAdapter.SendSuccessResponse(c, http.StatusOK, toAPITokenResponses(tokens), "API tokens retrieved successfully")
+}
+
+// Create issues a token for the current user. The token is only returned in this response.
+func (h *Handler) Create(c *gin.Context) {
+	// A token must not be able to mint further tokens
+	if c.GetString("auth_method") == "api_token" {
+		c.JSON(http.StatusForbidden, response.NewErrorResponse("API tokens cannot be created with an API token"))
+		return
+	}
+
+	var req dto.CreateAPITokenRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, response.NewErrorResponse("Invalid API token data", err.Error()))
+		return
+	}
+
+	raw, token, err := h.service.Create(c.GetInt("user_id"), req.Name, req.Scopes, req.ExpiresAt)
+	if err != nil {
+		switch {
+		case errors.Is(err, apitoken.ErrNameRequired), errors.Is(err, apitoken.ErrInvalidScope), errors.Is(err, apitoken.ErrExpiryInPast):
+			c.JSON(http.StatusBadRequest, response.NewErrorResponse("Failed to create API token", err.Error()))
+		case errors.Is(err, apitoken.ErrScopeNotGranted):
+			c.JSON(http.StatusForbidden, response.NewErrorResponse("Failed to create API token", err.Error()))
+		default:
+			c.JSON(http.StatusInternalServerError, response.NewErrorResponse("Failed to create API token", err.Error()))
+		}
+		return
+	}
+
+	result := dto.CreateAPITokenResponse{
+		APITokenResponse: toAPITokenResponse(token),
+		Token:            raw,
+	}
+	h.httpAdapter.SendSuccessResponse(c, http.StatusCreated, result, "API token created successfully")
+}
+
+// RevokeMine revokes one of the current user's tokens
+func (h *Handler) RevokeMine(c *gin.Context) {
+	h.revoke(c, c.GetInt("user_id"))
+}
+
+// GetAll lists tokens of all users (admin)
+func (h *Handler) GetAll(c *gin.Context) {
+	tokens, err := h.service.ListAll()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, response.NewErrorResponse("Failed to retrieve API tokens", err.Error()))
+		return
+	}
+	h.httpAdapter.SendSuccessResponse(c, http.StatusOK, toAPITokenResponses(tokens), "API tokens retrieved successfully")
+}
+
+// Revoke revokes any user's token (admin)
+func (h *Handler) Revoke(c *gin.Context) {
+	h.revoke(c, 0)
+}
+
+func (h *Handler) revoke(c *gin.Context, ownerID int) {
+	id, err := h.httpAdapter.ParseIntIDParam(c, "id")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, response.NewErrorResponse(msgInvalidTokenID, err.Error()))
+		return
+	}
+
+	if err := h.service.Revoke(id, ownerID); err != nil {
+		if errors.Is(err, apitoken.ErrTokenNotFound) {
+			c.JSON(http.StatusNotFound, response.NewErrorResponse("API token not found", err.Error()))
+			return
+		}
+		c.JSON(http.StatusInternalServerError, response.NewErrorResponse("Failed to revoke API token", err.Error()))
+		return
+	}
+
+	h.httpAdapter.SendSuccessResponse(c, http.StatusOK, nil, "API token revoked successfully")
+}
+
+func toAPITokenResponses(tokens []models.APIToken) []dto.APITokenResponse {
+	result := make([]dto.APITokenResponse, 0, len(tokens))
+	for i := range tokens {
+		result = append(result, toAPITokenResponse(&tokens[i]))
+	}
+	return result
+}
+
+func toAPITokenResponse(token *models.APIToken) dto.APITokenResponse {
+	scopes := []string(token.Scopes)
+	if scopes == nil {
+		scopes = []string{}
+	}
+	return dto.APITokenResponse{
+		ID:         token.ID,
+		UserID:     token.UserID,
+		Name:       token.Name,
+		Prefix:     token.Prefix,
+		Scopes:     scopes,
+		ExpiresAt:  token.ExpiresAt,
+		LastUsedAt: token.LastUsedAt,
+		LastUsedIP: token.LastUsedIP,
+		RevokedAt:  token.RevokedAt,
+		Active:     token.IsActive(time.Now()),
+		CreatedAt:  token.CreatedAt,
+	}
+}
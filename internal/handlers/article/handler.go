@@ -4,23 +4,28 @@ import (
 	"net/http"
 	"web-porto-backend/common/response"
 	httpAdapter "web-porto-backend/internal/adapters/http"
+	"web-porto-backend/internal/auth"
 	"web-porto-backend/internal/domain/dto"
+	"web-porto-backend/internal/domain/models"
 	"web-porto-backend/internal/services/article"
+	"web-porto-backend/internal/services/audit"
 
 	"github.com/gin-gonic/gin"
 )
 
 // Handler handles HTTP requests for article endpoints
 type Handler struct {
-	service     *article.Service
-	httpAdapter *httpAdapter.HTTPAdapter
+	service      *article.Service
+	auditService audit.Service
+	httpAdapter  *httpAdapter.HTTPAdapter
 }
 
 // NewHandler creates a new article handler
-func NewHandler(service *article.Service, httpAdapter *httpAdapter.HTTPAdapter) *Handler {
+func NewHandler(service *article.Service, auditService audit.Service, httpAdapter *httpAdapter.HTTPAdapter) *Handler {
 	return &Handler{
-		service:     service,
-		httpAdapter: httpAdapter,
+		service:      service,
+		auditService: auditService,
+		httpAdapter:  httpAdapter,
 	}
 }
 
@@ -31,6 +36,12 @@ func (h *Handler) Create(c *gin.Context) {
 		c.JSON(http.StatusBadRequest, response.NewErrorResponse("Invalid request data", err.Error()))
 		return
 	}
+	if !h.httpAdapter.AuthorizePublish(c, req.Status, auth.PermArticlePublish) {
+		return
+	}
+	if !h.httpAdapter.ValidSEO(c, req.SEO) {
+		return
+	}
 
 	// Get user ID from context (set by auth middleware)
 	// userID, exists := c.Get("userID")
@@ -41,22 +52,35 @@ func (h *Handler) Create(c *gin.Context) {
 	// req.AuthorID = userID.(int)
 
 	// AuthorID will be set by service using default admin user if not provided
+	req.EditorID = c.GetInt("user_id")
 
 	article, err := h.service.CreateArticle(req)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, response.NewErrorResponse("Failed to create article", err.Error()))
 		return
 	}
+	h.auditService.Record(h.httpAdapter.AuditActor(c), audit.ActionCreate, audit.EntityArticle, article.ID, nil, article)
 
 	h.httpAdapter.SendSuccessResponse(c, http.StatusCreated, article, "Article created successfully")
 }
 
-// GetAll gets all articles
+// GetAll gets published articles; CMS users may pass ?status= to see others
 func (h *Handler) GetAll(c *gin.Context) {
+	status, ok := h.httpAdapter.StatusFilter(c, auth.PermArticleWrite)
+	if !ok {
+		return
+	}
+	query, ok := h.httpAdapter.ListQuery(c)
+	if !ok {
+		return
+	}
 	pagination := h.httpAdapter.GetPaginationFromQuery(c)
 
-	articles, err := h.service.ListArticles(pagination.Page, pagination.Limit)
+	articles, err := h.service.ListArticles(pagination.Page, pagination.Limit, status, query)
 	if err != nil {
+		if h.httpAdapter.ListQueryError(c, err) {
+			return
+		}
 		c.JSON(http.StatusInternalServerError, response.NewErrorResponse("Failed to get articles", err.Error()))
 		return
 	}
@@ -72,6 +96,11 @@ func (h *Handler) GetAll(c *gin.Context) {
 		}
 	}
 
+	if articles.Data, err = httpAdapter.SelectFields(articles.Data, query.Fields); err != nil {
+		h.httpAdapter.ListQueryError(c, err)
+		return
+	}
+
 	h.httpAdapter.SendSuccessResponse(c, http.StatusOK, articles, "Articles retrieved successfully")
 }
 
@@ -79,13 +108,12 @@ func (h *Handler) GetAll(c *gin.Context) {
 func (h *Handler) GetPublished(c *gin.Context) {
 	pagination := h.httpAdapter.GetPaginationFromQuery(c)
 
-	articles, err := h.service.ListArticles(pagination.Page, pagination.Limit)
+	articles, err := h.service.ListArticles(pagination.Page, pagination.Limit, models.StatusPublished, dto.ListQuery{})
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, response.NewErrorResponse("Failed to get published articles", err.Error()))
 		return
 	}
 
-	// Filter only published articles (this could be optimized by adding a specific service method)
 	h.httpAdapter.SendSuccessResponse(c, http.StatusOK, articles, "Published articles retrieved successfully")
 }
 
@@ -102,6 +130,10 @@ func (h *Handler) GetByID(c *gin.Context) {
 		c.JSON(http.StatusNotFound, response.NewErrorResponse("Article not found", err.Error()))
 		return
 	}
+	if !h.httpAdapter.CanView(c, auth.PermArticleWrite, article.Status, article.PublishedAt) {
+		c.JSON(http.StatusNotFound, response.NewErrorResponse("Article not found"))
+		return
+	}
 
 	h.httpAdapter.SendSuccessResponse(c, http.StatusOK, article, "Article retrieved successfully")
 }
@@ -119,6 +151,10 @@ func (h *Handler) GetBySlug(c *gin.Context) {
 		c.JSON(http.StatusNotFound, response.NewErrorResponse("Article not found", err.Error()))
 		return
 	}
+	if !h.httpAdapter.CanView(c, auth.PermArticleWrite, article.Status, article.PublishedAt) {
+		c.JSON(http.StatusNotFound, response.NewErrorResponse("Article not found"))
+		return
+	}
 
 	h.httpAdapter.SendSuccessResponse(c, http.StatusOK, article, "Article retrieved successfully")
 }
@@ -130,10 +166,14 @@ func (h *Handler) GetByCategory(c *gin.Context) {
 		c.JSON(http.StatusBadRequest, response.NewErrorResponse("Category slug is required"))
 		return
 	}
+	status, ok := h.httpAdapter.StatusFilter(c, auth.PermArticleWrite)
+	if !ok {
+		return
+	}
 
 	pagination := h.httpAdapter.GetPaginationFromQuery(c)
 
-	articles, err := h.service.GetArticlesByCategorySlug(slug, pagination.Page, pagination.Limit)
+	articles, err := h.service.GetArticlesByCategorySlug(slug, status, pagination.Page, pagination.Limit)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, response.NewErrorResponse("Failed to get articles by category", err.Error()))
 		return
@@ -149,17 +189,19 @@ func (h *Handler) GetByTag(c *gin.Context) {
 		c.JSON(http.StatusBadRequest, response.NewErrorResponse("Tag name is required"))
 		return
 	}
+	status, ok := h.httpAdapter.StatusFilter(c, auth.PermArticleWrite)
+	if !ok {
+		return
+	}
 
 	pagination := h.httpAdapter.GetPaginationFromQuery(c)
 
-	// This method would need to be implemented in the service
-	articles, err := h.service.ListArticles(pagination.Page, pagination.Limit)
+	articles, err := h.service.GetArticlesByTag(tagName, status, pagination.Page, pagination.Limit)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, response.NewErrorResponse("Failed to get articles by tag", err.Error()))
 		return
 	}
 
-	// Filter by tag (could be optimized with a specific service method)
 	h.httpAdapter.SendSuccessResponse(c, http.StatusOK, articles, "Articles retrieved successfully")
 }
 
@@ -176,6 +218,12 @@ func (h *Handler) Update(c *gin.Context) {
 		c.JSON(http.StatusBadRequest, response.NewErrorResponse("Invalid request data", err.Error()))
 		return
 	}
+	if req.Status != nil && !h.httpAdapter.AuthorizePublish(c, *req.Status, auth.PermArticlePublish) {
+		return
+	}
+	if !h.httpAdapter.ValidSEO(c, req.SEO) {
+		return
+	}
 
 	// Log the request data for debugging
 	// fmt.Printf("Update article request: %+v\n", req)
@@ -184,11 +232,13 @@ func (h *Handler) Update(c *gin.Context) {
 	if len(id) > 0 && id[:5] == "temp-" {
 		// Create new article instead with the same data
 		createReq := dto.CreateArticleRequest{
-			Categories: req.Categories,
-			Tags:       req.Tags,
-			PublishAt:  req.PublishAt,
-			AuthorID:   0, // Will be set by service using default admin
-			Metadata:   req.Metadata,
+			Categories:  req.Categories,
+			Tags:        req.Tags,
+			PublishAt:   req.PublishAt,
+			UnpublishAt: req.UnpublishAt,
+			EditorID:    c.GetInt("user_id"),
+			AuthorID:    0, // Will be set by service using default admin
+			Metadata:    req.Metadata,
 		}
 		if req.Title != nil {
 			createReq.Title = *req.Title
@@ -214,12 +264,15 @@ func (h *Handler) Update(c *gin.Context) {
 			c.JSON(http.StatusInternalServerError, response.NewErrorResponse("Failed to create article from temp ID", err.Error()))
 			return
 		}
+		h.auditService.Record(h.httpAdapter.AuditActor(c), audit.ActionCreate, audit.EntityArticle, article.ID, nil, article)
 
 		h.httpAdapter.SendSuccessResponse(c, http.StatusOK, article, "Article created successfully")
 		return
 	}
 
 	// If not a temp ID, proceed with normal update
+	req.EditorID = c.GetInt("user_id")
+	before, _ := h.service.GetArticleByID(id)
 	article, err := h.service.UpdateArticle(id, req)
 	if err != nil {
 		if err.Error() == "record not found" {
@@ -229,6 +282,7 @@ func (h *Handler) Update(c *gin.Context) {
 		c.JSON(http.StatusInternalServerError, response.NewErrorResponse("Failed to update article", err.Error()))
 		return
 	}
+	h.auditService.Record(h.httpAdapter.AuditActor(c), audit.ActionUpdate, audit.EntityArticle, id, before, article)
 
 	h.httpAdapter.SendSuccessResponse(c, http.StatusOK, article, "Article updated successfully")
 }
@@ -246,6 +300,7 @@ func (h *Handler) Delete(c *gin.Context) {
 		return
 	}
 
+	before, _ := h.service.GetArticleByID(id)
 	err := h.service.DeleteArticle(id)
 	if err != nil {
 		if err.Error() == "record not found" {
@@ -255,6 +310,7 @@ func (h *Handler) Delete(c *gin.Context) {
 		c.JSON(http.StatusInternalServerError, response.NewErrorResponse("Failed to delete article", err.Error()))
 		return
 	}
+	h.auditService.Record(h.httpAdapter.AuditActor(c), audit.ActionDelete, audit.EntityArticle, id, before, nil)
 
 	h.httpAdapter.SendSuccessResponse(c, http.StatusOK, nil, "Article deleted successfully")
 }
@@ -273,11 +329,13 @@ func (h *Handler) AddImage(c *gin.Context) {
 		return
 	}
 
+	before, _ := h.service.GetArticleByID(id)
 	image, err := h.service.AddArticleImage(id, imageData)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, response.NewErrorResponse("Failed to add image", err.Error()))
+		h.sendChildError(c, "Failed to add image", err)
 		return
 	}
+	h.recordUpdate(c, id, before)
 
 	h.httpAdapter.SendSuccessResponse(c, http.StatusCreated, image, "Image added successfully")
 }
@@ -296,11 +354,13 @@ func (h *Handler) AddVideo(c *gin.Context) {
 		return
 	}
 
+	before, _ := h.service.GetArticleByID(id)
 	video, err := h.service.AddArticleVideo(id, videoData)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, response.NewErrorResponse("Failed to add video", err.Error()))
+		h.sendChildError(c, "Failed to add video", err)
 		return
 	}
+	h.recordUpdate(c, id, before)
 
 	h.httpAdapter.SendSuccessResponse(c, http.StatusCreated, video, "Video added successfully")
 }
@@ -314,12 +374,12 @@ func (h *Handler) DeleteImage(c *gin.Context) {
 		return
 	}
 
-	// This method would need to be implemented in the service
-	err := h.service.DeleteArticle(id) // Just as a placeholder
-	if err != nil {
-		c.JSON(http.StatusInternalServerError, response.NewErrorResponse("Failed to delete image", err.Error()))
+	before, _ := h.service.GetArticleByID(id)
+	if err := h.service.DeleteArticleImage(id, imageId); err != nil {
+		h.sendChildError(c, "Failed to delete image", err)
 		return
 	}
+	h.recordUpdate(c, id, before)
 
 	h.httpAdapter.SendSuccessResponse(c, http.StatusOK, nil, "Image deleted successfully")
 }
@@ -333,12 +393,27 @@ func (h *Handler) DeleteVideo(c *gin.Context) {
 		return
 	}
 
-	// This method would need to be implemented in the service
-	err := h.service.DeleteArticle(id) // Just as a placeholder
-	if err != nil {
-		c.JSON(http.StatusInternalServerError, response.NewErrorResponse("Failed to delete video", err.Error()))
+	before, _ := h.service.GetArticleByID(id)
+	if err := h.service.DeleteArticleVideo(id, videoId); err != nil {
+		h.sendChildError(c, "Failed to delete video", err)
 		return
 	}
+	h.recordUpdate(c, id, before)
 
 	h.httpAdapter.SendSuccessResponse(c, http.StatusOK, nil, "Video deleted successfully")
 }
+
+// recordUpdate audits a change to the images or videos of an article as an
+// update of the article
+func (h *Handler) recordUpdate(c *gin.Context, id string, before *dto.ArticleResponse) {
+	after, _ := h.service.GetArticleByID(id)
+	h.auditService.Record(h.httpAdapter.AuditActor(c), audit.ActionUpdate, audit.EntityArticle, id, before, after)
+}
+
+func (h *Handler) sendChildError(c *gin.Context, message string, err error) {
+	if err.Error() == "record not found" {
+		c.JSON(http.StatusNotFound, response.NewErrorResponse(message, err.Error()))
+		return
+	}
+	c.JSON(http.StatusInternalServerError, response.NewErrorResponse(message, err.Error()))
+}
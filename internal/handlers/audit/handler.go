@@ -0,0 +1,115 @@
+package audit
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+	"web-porto-backend/common/response"
+	"web-porto-backend/common/utils"
+	httpAdapter "web-porto-backend/internal/adapters/http"
+	"web-porto-backend/internal/domain/dto"
+	"web-porto-backend/internal/domain/models"
+	"web-porto-backend/internal/services/audit"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Handler exposes the audit log to admins
+type Handler struct {
+	service     audit.Service
+	httpAdapter *httpAdapter.HTTPAdapter
+}
+
+// NewHandler creates a new audit handler
+func NewHandler(service audit.Service, httpAdapter *httpAdapter.HTTPAdapter) *Handler {
+	return &Handler{
+		service:     service,
+		httpAdapter: httpAdapter,
+	}
+}
+
+// GetAll lists audit entries filtered by ?userId=, ?action=, ?entityType=,
+// ?entityId=, ?from= and ?to= (RFC 3339 or YYYY-MM-DD)
+func (h *Handler) GetAll(c *gin.Context) {
+	pagination := h.httpAdapter.GetPaginationFromQuery(c)
+
+	filter := audit.LogFilter{
+		Action:     c.Query("action"),
+		EntityType: c.Query("entityType"),
+		EntityID:   c.Query("entityId"),
+	}
+	if v := c.Query("userId"); v != "" {
+		id, err := strconv.Atoi(v)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, response.NewErrorResponse("Invalid userId", err.Error()))
+			return
+		}
+		filter.UserID = id
+	}
+	for param, target := range map[string]**time.Time{"from": &filter.From, "to": &filter.To} {
+		v := c.Query(param)
+		if v == "" {
+			continue
+		}
+		t, err := utils.ParseDate(v, param == "to")
+		if err != nil {
+			c.JSON(http.StatusBadRequest, response.NewErrorResponse("Invalid "+param+" date", err.Error()))
+			return
+		}
+		*target = &t
+	}
+
+	entries, total, err := h.service.List(filter, pagination.Page, pagination.Limit)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, response.NewErrorResponse("Failed to get audit log", err.Error()))
+		return
+	}
+
+	result := make([]dto.AuditLogResponse, 0, len(entries))
+	for i := range entries {
+		result = append(result, toAuditLogResponse(&entries[i]))
+	}
+	h.httpAdapter.SendPaginatedResponse(c, result, pagination.Page, pagination.Limit, total, "Audit log retrieved successfully")
+}
+
+// GetByID returns a single audit entry
+func (h *Handler) GetByID(c *gin.Context) {
+	id, err := h.httpAdapter.ParseIntIDParam(c, "id")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, response.NewErrorResponse("Invalid audit log ID", err.Error()))
+		return
+	}
+
+	entry, err := h.service.GetByID(id)
+	if err != nil {
+		if errors.Is(err, audit.ErrLogNotFound) {
+			c.JSON(http.StatusNotFound, response.NewErrorResponse("Audit log entry not found", err.Error()))
+			return
+		}
+		c.JSON(http.StatusInternalServerError, response.NewErrorResponse("Failed to get audit log entry", err.Error()))
+		return
+	}
+
+	h.httpAdapter.SendSuccessResponse(c, http.StatusOK, toAuditLogResponse(entry), "Audit log entry retrieved successfully")
+}
+
+func toAuditLogResponse(entry *models.Log) dto.AuditLogResponse {
+	changes := json.RawMessage(entry.Changes)
+	if !json.Valid(changes) {
+		changes = json.RawMessage("{}")
+	}
+	return dto.AuditLogResponse{
+		ID:          entry.ID,
+		UserID:      entry.UserID,
+		Action:      entry.Action,
+		EntityType:  entry.EntityType,
+		EntityID:    entry.EntityID,
+		Description: entry.Description,
+		Changes:     changes,
+		IP:          entry.IP,
+		UserAgent:   entry.UserAgent,
+		CreatedAt:   entry.CreatedAt,
+	}
+}
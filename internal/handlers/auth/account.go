@@ -0,0 +1,112 @@
+package auth
+
+import (
+	"errors"
+	"net/http"
+	applog "web-porto-backend/common/logger"
+	"web-porto-backend/common/response"
+	"web-porto-backend/internal/services/account"
+
+	"github.com/gin-gonic/gin"
+)
+
+type EmailRequest struct {
+	Email string `json:"email" binding:"required,email"`
+}
+
+type ResetPasswordRequest struct {
+	Token    string `json:"token" binding:"required"`
+	Password string `json:"password" binding:"required"`
+}
+
+type VerifyEmailRequest struct {
+	Token string `json:"token" binding:"required"`
+}
+
+const msgResetMailSent = "If an account exists for this email, a password reset link has been sent"
+
+// ForgotPassword sends a password reset link. The response is the same whether
+// or not the email belongs to an account.
+func (h *Handler) ForgotPassword(c *gin.Context) {
+	var req EmailRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, response.NewErrorResponse("Invalid request data", err.Error()))
+		return
+	}
+
+	if err := h.accountService.RequestPasswordReset(req.Email); err != nil {
+		// Delivery problems are logged but not surfaced to avoid leaking account existence
+		applog.GetLogger().WithFields(applog.Fields{"handler": "auth.ForgotPassword"}).Error("failed sending password reset", applog.Fields{"error": err.Error()})
+	}
+
+	h.httpAdapter.SendSuccessResponse(c, http.StatusOK, nil, msgResetMailSent)
+}
+
+// ResetPassword sets a new password using the token from the reset email.
+// All existing sessions of the account are revoked.
+func (h *Handler) ResetPassword(c *gin.Context) {
+	var req ResetPasswordRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, response.NewErrorResponse("Invalid request data", err.Error()))
+		return
+	}
+
+	if err := h.accountService.ResetPassword(req.Token, req.Password); err != nil {
+		h.sendAccountError(c, "Failed to reset password", err)
+		return
+	}
+
+	h.httpAdapter.SendSuccessResponse(c, http.StatusOK, nil, "Password reset successfully")
+}
+
+// VerifyEmail confirms the email address using the token from the verification email
+func (h *Handler) VerifyEmail(c *gin.Context) {
+	var req VerifyEmailRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, response.NewErrorResponse("Invalid request data", err.Error()))
+		return
+	}
+
+	if err := h.accountService.VerifyEmail(req.Token); err != nil {
+		h.sendAccountError(c, "Failed to verify email", err)
+		return
+	}
+
+	h.httpAdapter.SendSuccessResponse(c, http.StatusOK, nil, "Email verified successfully")
+}
+
+// ResendVerification sends a new verification link to an unverified address
+func (h *Handler) ResendVerification(c *gin.Context) {
+	var req EmailRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, response.NewErrorResponse("Invalid request data", err.Error()))
+		return
+	}
+
+	if err := h.accountService.RequestVerificationEmail(req.Email); err != nil {
+		applog.GetLogger().WithFields(applog.Fields{"handler": "auth.ResendVerification"}).Error("failed sending verification email", applog.Fields{"error": err.Error()})
+	}
+
+	h.httpAdapter.SendSuccessResponse(c, http.StatusOK, nil, "If the address is registered and unverified, a verification link has been sent")
+}
+
+// sendVerificationEmail mails the verification link off the request path
+func (h *Handler) sendVerificationEmail(userID int) {
+	go func() {
+		if err := h.accountService.SendVerificationEmail(userID); err != nil && !errors.Is(err, account.ErrAlreadyVerified) {
+			applog.GetLogger().WithFields(applog.Fields{"handler": "auth.Register"}).Error("failed sending verification email", applog.Fields{"user_id": userID, "error": err.Error()})
+		}
+	}()
+}
+
+func (h *Handler) sendAccountError(c *gin.Context, message string, err error) {
+	var weak *account.WeakPasswordError
+	switch {
+	case errors.As(err, &weak):
+		c.JSON(http.StatusBadRequest, response.NewErrorResponse("Password does not meet requirements", err.Error()))
+	case errors.Is(err, account.ErrInvalidToken):
+		c.JSON(http.StatusBadRequest, response.NewErrorResponse(message, err.Error()))
+	default:
+		c.JSON(http.StatusInternalServerError, response.NewErrorResponse(message, err.Error()))
+	}
+}
@@ -2,29 +2,50 @@
 
 import (
 	"bytes"
+	"errors"
 	"io"
 	"net/http"
+	"strconv"
+	"time"
 	applog "web-porto-backend/common/logger"
 	"web-porto-backend/common/response"
 	httpAdapter "web-porto-backend/internal/adapters/http"
 	"web-porto-backend/internal/auth"
 	"web-porto-backend/internal/domain/models"
+	"web-porto-backend/internal/services/account"
+	"web-porto-backend/internal/services/invitation"
+	"web-porto-backend/internal/services/loginattempt"
+	"web-porto-backend/internal/services/role"
+	"web-porto-backend/internal/services/token"
+	"web-porto-backend/internal/services/twofactor"
 	"web-porto-backend/internal/services/user"
 
 	"github.com/gin-gonic/gin"
 )
 
 type Handler struct {
-	userService user.Service
-	jwtService  auth.JWTService
-	httpAdapter *httpAdapter.HTTPAdapter
+	accountService    account.Service
+	userService       user.Service
+	tokenService      token.Service
+	roleService       role.Service
+	invitationService invitation.Service
+	attemptService    loginattempt.Service
+	twoFactorService  twofactor.Service
+	jwtService        auth.JWTService
+	httpAdapter       *httpAdapter.HTTPAdapter
 }
 
-func NewHandler(userService user.Service, jwtService auth.JWTService, httpAdapter *httpAdapter.HTTPAdapter) *Handler {
+func NewHandler(accountService account.Service, userService user.Service, tokenService token.Service, roleService role.Service, invitationService invitation.Service, attemptService loginattempt.Service, twoFactorService twofactor.Service, jwtService auth.JWTService, httpAdapter *httpAdapter.HTTPAdapter) *Handler {
 	return &Handler{
-		userService: userService,
-		jwtService:  jwtService,
-		httpAdapter: httpAdapter,
+		accountService:    accountService,
+		userService:       userService,
+		tokenService:      tokenService,
+		roleService:       roleService,
+		invitationService: invitationService,
+		attemptService:    attemptService,
+		twoFactorService:  twoFactorService,
+		jwtService:        jwtService,
+		httpAdapter:       httpAdapter,
 	}
 }
 
@@ -34,18 +55,48 @@ type LoginRequest struct {
 }
 
 type RegisterRequest struct {
-	Username string `json:"username" binding:"required"`
-	Email    string `json:"email" binding:"required,email"`
-	Password string `json:"password" binding:"required,min=6"`
+	Username    string `json:"username" binding:"required"`
+	Email       string `json:"email" binding:"required,email"`
+	Password    string `json:"password" binding:"required,min=6"`
+	InviteToken string `json:"inviteToken"`
+}
+
+type RefreshRequest struct {
+	RefreshToken string `json:"refreshToken" binding:"required"`
+}
+
+type LogoutRequest struct {
+	RefreshToken string `json:"refreshToken"`
 }
 
 type LoginResponse struct {
-	Token string      `json:"token"`
-	User  models.User `json:"user"`
+	Token        string      `json:"token"`
+	RefreshToken string      `json:"refreshToken"`
+	TokenType    string      `json:"tokenType"`
+	ExpiresIn    int         `json:"expiresIn"`
+	User         models.User `json:"user"`
+}
+
+// PendingVerificationResponse is returned by Register instead of tokens while
+// the new account's email address is unverified
+type PendingVerificationResponse struct {
+	VerificationRequired bool        `json:"verificationRequired"`
+	User                 models.User `json:"user"`
+}
+
+// TokenResponse is returned by the refresh endpoint
+type TokenResponse struct {
+	Token        string `json:"token"`
+	RefreshToken string `json:"refreshToken"`
+	TokenType    string `json:"tokenType"`
+	ExpiresIn    int    `json:"expiresIn"`
 }
 
 const (
 	msgFailedGenerateToken = "Failed to generate token"
+	msgAccountDisabled     = "Account is disabled"
+	msgEmailNotVerified    = "Email address is not verified"
+	tokenTypeBearer        = "Bearer"
 )
 
 func (h *Handler) Login(c *gin.Context) {
@@ -72,26 +123,92 @@ func (h *Handler) Login(c *gin.Context) {
 	// Debug
 	log.Info("login attempt", applog.Fields{"email": req.Email})
 
+	ip, userAgent := c.ClientIP(), c.Request.UserAgent()
+
+	// Reject early while the account or IP is locked out
+	if err := h.attemptService.Check(req.Email, ip); err != nil {
+		var locked *loginattempt.LockedError
+		if errors.As(err, &locked) {
+			log.Warn("login locked out", applog.Fields{"email": req.Email, "ip": ip})
+			c.Header("Retry-After", strconv.Itoa(int(locked.RetryAfter.Seconds())+1))
+			c.JSON(http.StatusTooManyRequests, response.NewErrorResponse("Too many failed login attempts, try again later"))
+			return
+		}
+	}
+
 	user, err := h.userService.GetByEmail(req.Email)
 	if err != nil {
 		log.Info("user not found or fetch failed", applog.Fields{"email": req.Email, "error": err.Error()})
+		h.attemptService.RecordFailure(req.Email, ip, userAgent, nil, "unknown_user")
 		c.JSON(http.StatusUnauthorized, response.NewErrorResponse("Invalid credentials"))
 		return
 	}
 
 	if !h.userService.CheckPassword(user.PasswordHash, req.Password) {
 		log.Info("invalid password", applog.Fields{"user_id": user.ID, "email": user.Email})
+		h.attemptService.RecordFailure(req.Email, ip, userAgent, &user.ID, "invalid_password")
 		c.JSON(http.StatusUnauthorized, response.NewErrorResponse("Invalid credentials"))
 		return
 	}
 
-	token, err := h.jwtService.GenerateToken(user.ID, user.Email, user.Role)
+	if user.Status == models.UserStatusDisabled {
+		log.Info("login rejected for disabled account", applog.Fields{"user_id": user.ID})
+		h.attemptService.RecordFailure(req.Email, ip, userAgent, &user.ID, "account_disabled")
+		c.JSON(http.StatusForbidden, response.NewErrorResponse(msgAccountDisabled))
+		return
+	}
+
+	if user.EmailVerifiedAt == nil && h.accountService.VerificationRequired() {
+		log.Info("login rejected for unverified email", applog.Fields{"user_id": user.ID})
+		c.JSON(http.StatusForbidden, response.NewErrorResponse(msgEmailNotVerified))
+		return
+	}
+
+	// Accounts with 2FA get a short-lived challenge instead of tokens; the
+	// attempt is only recorded as a success once the second factor passes
+	twoFactorEnabled, err := h.twoFactorService.IsEnabled(user.ID)
+	if err != nil {
+		log.Error("failed checking two-factor status", applog.Fields{"user_id": user.ID, "error": err.Error()})
+		c.JSON(http.StatusInternalServerError, response.NewErrorResponse("Failed to check two-factor status", err.Error()))
+		return
+	}
+	if twoFactorEnabled {
+		challenge, err := h.jwtService.GenerateScopedToken(user.ID, auth.PurposeTwoFactorChallenge, "", twoFactorChallengeTTL)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, response.NewErrorResponse(msgFailedGenerateToken, err.Error()))
+			return
+		}
+		log.Info("two-factor challenge issued", applog.Fields{"user_id": user.ID})
+		h.httpAdapter.SendSuccessResponse(c, http.StatusOK, TwoFactorChallengeResponse{
+			TwoFactorRequired: true,
+			ChallengeToken:    challenge,
+			ExpiresIn:         int(twoFactorChallengeTTL.Seconds()),
+		}, "Two-factor authentication required")
+		return
+	}
+
+	h.completeLogin(c, user, req.Email)
+}
+
+// completeLogin issues the access and refresh tokens for an authenticated user
+// and records the successful attempt.
+func (h *Handler) completeLogin(c *gin.Context, user *models.User, email string) {
+	log := applog.GetLogger().WithFields(applog.Fields{"handler": "auth.Login"})
+
+	token, err := h.generateAccessToken(user)
 	if err != nil {
 		log.Error("failed generating token", applog.Fields{"user_id": user.ID, "error": err.Error()})
 		c.JSON(http.StatusInternalServerError, response.NewErrorResponse(msgFailedGenerateToken, err.Error()))
 		return
 	}
 
+	refreshToken, _, err := h.tokenService.IssueRefreshToken(user.ID, c.Request.UserAgent(), c.ClientIP())
+	if err != nil {
+		log.Error("failed issuing refresh token", applog.Fields{"user_id": user.ID, "error": err.Error()})
+		c.JSON(http.StatusInternalServerError, response.NewErrorResponse(msgFailedGenerateToken, err.Error()))
+		return
+	}
+
 	// Print token to logs for debugging
 	log.Info("token generated", applog.Fields{
 		"user_id":      user.ID,
@@ -103,10 +220,14 @@ func (h *Handler) Login(c *gin.Context) {
 	user.PasswordHash = ""
 
 	loginResponse := LoginResponse{
-		Token: token,
-		User:  *user,
+		Token:        token,
+		RefreshToken: refreshToken,
+		TokenType:    tokenTypeBearer,
+		ExpiresIn:    int(h.jwtService.AccessTokenTTL().Seconds()),
+		User:         *user,
 	}
 
+	h.attemptService.RecordSuccess(email, c.ClientIP(), c.Request.UserAgent(), user.ID)
 	log.Info("login success", applog.Fields{"user_id": user.ID, "email": user.Email, "role": user.Role})
 
 	// Set CORS headers explicitly for debugging
@@ -123,6 +244,11 @@ func (h *Handler) Register(c *gin.Context) {
 		return
 	}
 
+	if err := h.accountService.ValidatePassword(req.Password); err != nil {
+		c.JSON(http.StatusBadRequest, response.NewErrorResponse("Password does not meet requirements", err.Error()))
+		return
+	}
+
 	// Check if user already exists
 	existingUser, _ := h.userService.GetByEmail(req.Email)
 	if existingUser != nil {
@@ -130,66 +256,191 @@ func (h *Handler) Register(c *gin.Context) {
 		return
 	}
 
-	user := &models.User{
-		Username:     req.Username,
-		Email:        req.Email,
-		PasswordHash: req.Password, // Service should hash this
-		Role:         "user",       // Default role
+	// Registration mode and invitation are enforced by the invitation service;
+	// the invite decides the role, open registration falls back to "user"
+	var user *models.User
+	_, err := h.invitationService.Redeem(req.InviteToken, req.Email, func(role string) (int, error) {
+		user = &models.User{
+			Username:     req.Username,
+			Email:        req.Email,
+			PasswordHash: req.Password, // Service should hash this
+			Role:         role,
+		}
+		if err := h.userService.Create(user); err != nil {
+			return 0, err
+		}
+		return user.ID, nil
+	})
+	if err != nil {
+		switch {
+		case errors.Is(err, invitation.ErrRegistrationClosed), errors.Is(err, invitation.ErrInviteRequired):
+			c.JSON(http.StatusForbidden, response.NewErrorResponse("Registration not allowed", err.Error()))
+		case errors.Is(err, invitation.ErrInvalidInvite), errors.Is(err, invitation.ErrInviteEmailMismatch):
+			c.JSON(http.StatusBadRequest, response.NewErrorResponse("Invalid invitation", err.Error()))
+		default:
+			c.JSON(http.StatusInternalServerError, response.NewErrorResponse("Failed to create user", err.Error()))
+		}
+		return
 	}
 
-	if err := h.userService.Create(user); err != nil {
-		c.JSON(http.StatusInternalServerError, response.NewErrorResponse("Failed to create user", err.Error()))
+	h.sendVerificationEmail(user.ID)
+
+	// Don't return password hash
+	user.PasswordHash = ""
+
+	// Like Login, unverified accounts get no tokens until the address is confirmed
+	if user.EmailVerifiedAt == nil && h.accountService.VerificationRequired() {
+		h.httpAdapter.SendSuccessResponse(c, http.StatusCreated, PendingVerificationResponse{VerificationRequired: true, User: *user}, "Registration successful; verify your email address to log in")
 		return
 	}
 
 	// Generate token for the new user
-	token, err := h.jwtService.GenerateToken(user.ID, user.Email, user.Role)
+	token, err := h.generateAccessToken(user)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, response.NewErrorResponse(msgFailedGenerateToken, err.Error()))
 		return
 	}
 
-	// Don't return password hash
-	user.PasswordHash = ""
+	refreshToken, _, err := h.tokenService.IssueRefreshToken(user.ID, c.Request.UserAgent(), c.ClientIP())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, response.NewErrorResponse(msgFailedGenerateToken, err.Error()))
+		return
+	}
 
 	registerResponse := LoginResponse{
-		Token: token,
-		User:  *user,
+		Token:        token,
+		RefreshToken: refreshToken,
+		TokenType:    tokenTypeBearer,
+		ExpiresIn:    int(h.jwtService.AccessTokenTTL().Seconds()),
+		User:         *user,
 	}
 
 	h.httpAdapter.SendSuccessResponse(c, http.StatusCreated, registerResponse, "Registration successful")
 }
 
+// RefreshToken exchanges a refresh token for a new access token and a rotated
+// refresh token. Reusing an already rotated refresh token revokes its whole family.
 func (h *Handler) RefreshToken(c *gin.Context) {
-	// Get user from context (set by auth middleware)
-	userInterface, exists := c.Get("user")
-	if !exists {
-		c.JSON(http.StatusUnauthorized, response.NewErrorResponse("User not found in context"))
+	log := applog.GetLogger().WithFields(applog.Fields{"handler": "auth.RefreshToken"})
+
+	var req RefreshRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, response.NewErrorResponse("Invalid request data", err.Error()))
 		return
 	}
 
-	user, ok := userInterface.(*models.User)
-	if !ok {
-		c.JSON(http.StatusUnauthorized, response.NewErrorResponse("Invalid user data"))
+	refreshToken, stored, err := h.tokenService.RotateRefreshToken(req.RefreshToken, c.Request.UserAgent(), c.ClientIP())
+	if err != nil {
+		switch {
+		case errors.Is(err, token.ErrRefreshTokenReused):
+			log.Warn("refresh token reuse detected", applog.Fields{"ip": c.ClientIP()})
+			c.JSON(http.StatusUnauthorized, response.NewErrorResponse("Refresh token has been revoked"))
+		case errors.Is(err, token.ErrInvalidRefreshToken), errors.Is(err, token.ErrRefreshTokenExpired):
+			c.JSON(http.StatusUnauthorized, response.NewErrorResponse("Invalid or expired refresh token"))
+		default:
+			log.Error("failed rotating refresh token", applog.Fields{"error": err.Error()})
+			c.JSON(http.StatusInternalServerError, response.NewErrorResponse("Failed to refresh token", err.Error()))
+		}
 		return
 	}
 
-	token, err := h.jwtService.GenerateToken(user.ID, user.Email, user.Role)
+	user, err := h.userService.GetByID(uint(stored.UserID))
+	if err != nil {
+		_ = h.tokenService.RevokeRefreshToken(refreshToken)
+		c.JSON(http.StatusUnauthorized, response.NewErrorResponse("User not found"))
+		return
+	}
+	if user.Status == models.UserStatusDisabled {
+		_ = h.tokenService.RevokeAllForUser(user.ID)
+		c.JSON(http.StatusForbidden, response.NewErrorResponse(msgAccountDisabled))
+		return
+	}
+	if user.EmailVerifiedAt == nil && h.accountService.VerificationRequired() {
+		_ = h.tokenService.RevokeRefreshToken(refreshToken)
+		c.JSON(http.StatusForbidden, response.NewErrorResponse(msgEmailNotVerified))
+		return
+	}
+
+	accessToken, err := h.generateAccessToken(user)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, response.NewErrorResponse(msgFailedGenerateToken, err.Error()))
 		return
 	}
 
-	tokenResponse := map[string]string{"token": token}
+	tokenResponse := TokenResponse{
+		Token:        accessToken,
+		RefreshToken: refreshToken,
+		TokenType:    tokenTypeBearer,
+		ExpiresIn:    int(h.jwtService.AccessTokenTTL().Seconds()),
+	}
 	h.httpAdapter.SendSuccessResponse(c, http.StatusOK, tokenResponse, "Token refreshed successfully")
 }
 
+// Logout revokes the current access token and, when provided, the refresh
+// token family of this session.
 func (h *Handler) Logout(c *gin.Context) {
-	// In JWT, logout is typically handled client-side by removing the token
-	// For enhanced security, you could implement token blacklisting here
+	var req LogoutRequest
+	// Body is optional; an empty body only revokes the access token
+	_ = c.ShouldBindJSON(&req)
+
+	if req.RefreshToken != "" {
+		if err := h.tokenService.RevokeRefreshToken(req.RefreshToken); err != nil && !errors.Is(err, token.ErrInvalidRefreshToken) {
+			c.JSON(http.StatusInternalServerError, response.NewErrorResponse("Failed to revoke refresh token", err.Error()))
+			return
+		}
+	}
+
+	if err := h.revokeCurrentAccessToken(c); err != nil {
+		c.JSON(http.StatusInternalServerError, response.NewErrorResponse("Failed to revoke token", err.Error()))
+		return
+	}
+
 	h.httpAdapter.SendSuccessResponse(c, http.StatusOK, nil, "Logout successful")
 }
 
+// LogoutAll revokes every refresh token of the current user along with the
+// access token used for this request.
+func (h *Handler) LogoutAll(c *gin.Context) {
+	userID := c.GetInt("user_id")
+	if userID == 0 {
+		c.JSON(http.StatusUnauthorized, response.NewErrorResponse("User not found in context"))
+		return
+	}
+
+	if err := h.tokenService.RevokeAllForUser(userID); err != nil {
+		c.JSON(http.StatusInternalServerError, response.NewErrorResponse("Failed to revoke sessions", err.Error()))
+		return
+	}
+
+	if err := h.revokeCurrentAccessToken(c); err != nil {
+		c.JSON(http.StatusInternalServerError, response.NewErrorResponse("Failed to revoke token", err.Error()))
+		return
+	}
+
+	h.httpAdapter.SendSuccessResponse(c, http.StatusOK, nil, "Logged out from all sessions")
+}
+
+// generateAccessToken issues an access token embedding the user's current permissions
+func (h *Handler) generateAccessToken(user *models.User) (string, error) {
+	permissions, err := h.roleService.PermissionsForUser(user)
+	if err != nil {
+		return "", err
+	}
+	return h.jwtService.GenerateToken(user.ID, user.Email, user.Role, permissions)
+}
+
+func (h *Handler) revokeCurrentAccessToken(c *gin.Context) error {
+	jti := c.GetString("jti")
+	if jti == "" {
+		return nil
+	}
+	expiresAt := c.GetTime("token_expires_at")
+	if expiresAt.IsZero() {
+		expiresAt = time.Now().Add(h.jwtService.AccessTokenTTL())
+	}
+	return h.tokenService.RevokeAccessToken(jti, c.GetInt("user_id"), expiresAt)
+}
+
 func (h *Handler) GetProfile(c *gin.Context) {
 	// Get user from context (set by auth middleware)
 	userInterface, exists := c.Get("user")
@@ -0,0 +1,192 @@
+package auth
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+	applog "web-porto-backend/common/logger"
+	"web-porto-backend/common/response"
+	"web-porto-backend/internal/auth"
+	"web-porto-backend/internal/services/loginattempt"
+	"web-porto-backend/internal/services/twofactor"
+
+	"github.com/gin-gonic/gin"
+)
+
+const twoFactorChallengeTTL = 5 * time.Minute
+
+// TwoFactorChallengeResponse is returned by Login instead of tokens when the
+// account has two-factor authentication enabled
+type TwoFactorChallengeResponse struct {
+	TwoFactorRequired bool   `json:"twoFactorRequired"`
+	ChallengeToken    string `json:"challengeToken"`
+	ExpiresIn         int    `json:"expiresIn"`
+}
+
+type TwoFactorVerifyRequest struct {
+	ChallengeToken string `json:"challengeToken" binding:"required"`
+	// Code is either a 6-digit TOTP code or a recovery code
+	Code string `json:"code" binding:"required"`
+}
+
+type TwoFactorCodeRequest struct {
+	Code string `json:"code" binding:"required"`
+}
+
+type TwoFactorSetupResponse struct {
+	Secret     string `json:"secret"`
+	OtpauthURI string `json:"otpauthUri"`
+}
+
+type TwoFactorStatusResponse struct {
+	Enabled           bool  `json:"enabled"`
+	RecoveryCodesLeft int64 `json:"recoveryCodesLeft"`
+}
+
+type RecoveryCodesResponse struct {
+	RecoveryCodes []string `json:"recoveryCodes"`
+}
+
+// VerifyTwoFactor completes a two-step login by exchanging a challenge token
+// and a TOTP or recovery code for access and refresh tokens.
+func (h *Handler) VerifyTwoFactor(c *gin.Context) {
+	log := applog.GetLogger().WithFields(applog.Fields{"handler": "auth.VerifyTwoFactor"})
+
+	var req TwoFactorVerifyRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, response.NewErrorResponse("Invalid request data", err.Error()))
+		return
+	}
+
+	claims, err := h.jwtService.ValidateScopedToken(req.ChallengeToken, auth.PurposeTwoFactorChallenge)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, response.NewErrorResponse("Invalid or expired challenge token"))
+		return
+	}
+
+	user, err := h.userService.GetByID(uint(claims.UserID))
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, response.NewErrorResponse("Invalid or expired challenge token"))
+		return
+	}
+
+	ip, userAgent := c.ClientIP(), c.Request.UserAgent()
+
+	// Second-factor guesses count towards the same lockout as passwords
+	if err := h.attemptService.Check(user.Email, ip); err != nil {
+		var locked *loginattempt.LockedError
+		if errors.As(err, &locked) {
+			c.Header("Retry-After", strconv.Itoa(int(locked.RetryAfter.Seconds())+1))
+			c.JSON(http.StatusTooManyRequests, response.NewErrorResponse("Too many failed login attempts, try again later"))
+			return
+		}
+	}
+
+	if err := h.twoFactorService.Verify(user.ID, req.Code); err != nil {
+		if errors.Is(err, twofactor.ErrInvalidCode) {
+			log.Info("invalid two-factor code", applog.Fields{"user_id": user.ID})
+			h.attemptService.RecordFailure(user.Email, ip, userAgent, &user.ID, "invalid_2fa_code")
+			c.JSON(http.StatusUnauthorized, response.NewErrorResponse("Invalid two-factor code"))
+			return
+		}
+		h.sendTwoFactorError(c, "Failed to verify two-factor code", err)
+		return
+	}
+
+	// The challenge is single use
+	if claims.ExpiresAt != nil {
+		if err := h.tokenService.RevokeAccessToken(claims.ID, user.ID, claims.ExpiresAt.Time); err != nil {
+			log.Error("failed revoking challenge token", applog.Fields{"user_id": user.ID, "error": err.Error()})
+		}
+	}
+
+	h.completeLogin(c, user, user.Email)
+}
+
+// GetTwoFactorStatus reports whether 2FA is enabled for the current user
+func (h *Handler) GetTwoFactorStatus(c *gin.Context) {
+	enabled, left, err := h.twoFactorService.Status(c.GetInt("user_id"))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, response.NewErrorResponse("Failed to get two-factor status", err.Error()))
+		return
+	}
+	h.httpAdapter.SendSuccessResponse(c, http.StatusOK, TwoFactorStatusResponse{Enabled: enabled, RecoveryCodesLeft: left}, "Two-factor status retrieved successfully")
+}
+
+// SetupTwoFactor starts enrollment and returns the secret and otpauth URI for
+// the authenticator app. 2FA is not active until EnableTwoFactor succeeds.
+func (h *Handler) SetupTwoFactor(c *gin.Context) {
+	userID := c.GetInt("user_id")
+	user, err := h.userService.GetByID(uint(userID))
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, response.NewErrorResponse("User not found"))
+		return
+	}
+
+	secret, uri, err := h.twoFactorService.BeginSetup(user.ID, user.Email)
+	if err != nil {
+		h.sendTwoFactorError(c, "Failed to set up two-factor authentication", err)
+		return
+	}
+	h.httpAdapter.SendSuccessResponse(c, http.StatusOK, TwoFactorSetupResponse{Secret: secret, OtpauthURI: uri}, "Scan the URI with an authenticator app and confirm with a code")
+}
+
+// EnableTwoFactor confirms enrollment with a first code and returns the
+// recovery codes. They are only shown once.
+func (h *Handler) EnableTwoFactor(c *gin.Context) {
+	var req TwoFactorCodeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, response.NewErrorResponse("Invalid request data", err.Error()))
+		return
+	}
+
+	codes, err := h.twoFactorService.Enable(c.GetInt("user_id"), req.Code)
+	if err != nil {
+		h.sendTwoFactorError(c, "Failed to enable two-factor authentication", err)
+		return
+	}
+	h.httpAdapter.SendSuccessResponse(c, http.StatusOK, RecoveryCodesResponse{RecoveryCodes: codes}, "Two-factor authentication enabled")
+}
+
+// DisableTwoFactor turns 2FA off after checking a current TOTP or recovery code
+func (h *Handler) DisableTwoFactor(c *gin.Context) {
+	var req TwoFactorCodeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, response.NewErrorResponse("Invalid request data", err.Error()))
+		return
+	}
+
+	if err := h.twoFactorService.Disable(c.GetInt("user_id"), req.Code); err != nil {
+		h.sendTwoFactorError(c, "Failed to disable two-factor authentication", err)
+		return
+	}
+	h.httpAdapter.SendSuccessResponse(c, http.StatusOK, nil, "Two-factor authentication disabled")
+}
+
+// RegenerateRecoveryCodes replaces all recovery codes of the current user
+func (h *Handler) RegenerateRecoveryCodes(c *gin.Context) {
+	var req TwoFactorCodeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, response.NewErrorResponse("Invalid request data", err.Error()))
+		return
+	}
+
+	codes, err := h.twoFactorService.RegenerateRecoveryCodes(c.GetInt("user_id"), req.Code)
+	if err != nil {
+		h.sendTwoFactorError(c, "Failed to regenerate recovery codes", err)
+		return
+	}
+	h.httpAdapter.SendSuccessResponse(c, http.StatusOK, RecoveryCodesResponse{RecoveryCodes: codes}, "Recovery codes regenerated")
+}
+
+func (h *Handler) sendTwoFactorError(c *gin.Context, message string, err error) {
+	switch {
+	case errors.Is(err, twofactor.ErrInvalidCode):
+		c.JSON(http.StatusUnauthorized, response.NewErrorResponse(message, err.Error()))
+	case errors.Is(err, twofactor.ErrNotEnrolled), errors.Is(err, twofactor.ErrNotEnabled), errors.Is(err, twofactor.ErrAlreadyEnabled):
+		c.JSON(http.StatusConflict, response.NewErrorResponse(message, err.Error()))
+	default:
+		c.JSON(http.StatusInternalServerError, response.NewErrorResponse(message, err.Error()))
+	}
+}
@@ -3,22 +3,26 @@
 import (
 	"log"
 	"net/http"
+	"strconv"
 	httpAdapter "web-porto-backend/internal/adapters/http"
 	"web-porto-backend/internal/domain/models"
+	"web-porto-backend/internal/services/audit"
 	"web-porto-backend/internal/services/category"
 
 	"github.com/gin-gonic/gin"
 )
 
 type Handler struct {
-	service     category.Service
-	httpAdapter *httpAdapter.HTTPAdapter
+	service      category.Service
+	auditService audit.Service
+	httpAdapter  *httpAdapter.HTTPAdapter
 }
 
-func NewHandler(service category.Service) *Handler {
+func NewHandler(service category.Service, auditService audit.Service) *Handler {
 	return &Handler{
-		service:     service,
-		httpAdapter: httpAdapter.NewHTTPAdapter(),
+		service:      service,
+		auditService: auditService,
+		httpAdapter:  httpAdapter.NewHTTPAdapter(),
 	}
 }
 
@@ -78,6 +82,7 @@ func (h *Handler) Create(c *gin.Context) {
 	}
 
 	log.Printf("Category created successfully: %+v", category)
+	h.auditService.Record(h.httpAdapter.AuditActor(c), audit.ActionCreate, audit.EntityCategory, strconv.Itoa(category.ID), nil, category)
 	h.httpAdapter.SendSuccessResponse(c, http.StatusCreated, category, "Category created successfully")
 }
 
@@ -105,6 +110,7 @@ func (h *Handler) Update(c *gin.Context) {
 	log.Printf("Updating category %d: %+v", id, category)
 
 	category.ID = id
+	before, _ := h.service.GetByID(id)
 	if err := h.service.Update(&category); err != nil {
 		log.Printf("Error updating category: %v", err)
 		h.httpAdapter.SendErrorResponse(c, http.StatusInternalServerError, err.Error())
@@ -112,6 +118,9 @@ func (h *Handler) Update(c *gin.Context) {
 	}
 
 	log.Printf("Category updated successfully: %+v", category)
+	if after, err := h.service.GetByID(id); err == nil {
+		h.auditService.Record(h.httpAdapter.AuditActor(c), audit.ActionUpdate, audit.EntityCategory, strconv.Itoa(id), before, after)
+	}
 	h.httpAdapter.SendSuccessResponse(c, http.StatusOK, category, "Category updated successfully")
 }
 
@@ -122,10 +131,12 @@ func (h *Handler) Delete(c *gin.Context) {
 		return
 	}
 
+	before, _ := h.service.GetByID(id)
 	if err := h.service.Delete(id); err != nil {
 		h.httpAdapter.SendErrorResponse(c, http.StatusInternalServerError, err.Error())
 		return
 	}
+	h.auditService.Record(h.httpAdapter.AuditActor(c), audit.ActionDelete, audit.EntityCategory, strconv.Itoa(id), before, nil)
 
 	h.httpAdapter.SendSuccessResponse(c, http.StatusOK, nil, "Category deleted successfully")
 }
@@ -2,37 +2,53 @@ package experience
 
 import (
 	"net/http"
+	"strconv"
 
 	httpAdapter "web-porto-backend/internal/adapters/http"
 	"web-porto-backend/internal/domain/dto"
+	"web-porto-backend/internal/services/audit"
 	experienceService "web-porto-backend/internal/services/experience"
 
 	"github.com/gin-gonic/gin"
 )
 
 type Handler struct {
-	service     *experienceService.Service
-	httpAdapter *httpAdapter.HTTPAdapter
+	service      *experienceService.Service
+	auditService audit.Service
+	httpAdapter  *httpAdapter.HTTPAdapter
 }
 
-func NewHandler(service *experienceService.Service, httpAdapter *httpAdapter.HTTPAdapter) *Handler {
+func NewHandler(service *experienceService.Service, auditService audit.Service, httpAdapter *httpAdapter.HTTPAdapter) *Handler {
 	return &Handler{
-		service:     service,
-		httpAdapter: httpAdapter,
+		service:      service,
+		auditService: auditService,
+		httpAdapter:  httpAdapter,
 	}
 }
 
 // GetAll retrieves all experiences
 func (h *Handler) GetAll(c *gin.Context) {
+	query, ok := h.httpAdapter.ListQuery(c)
+	if !ok {
+		return
+	}
 	// Get pagination parameters
 	pagination := h.httpAdapter.GetPaginationFromQuery(c)
 
-	result, err := h.service.ListExperiences(pagination.Page, pagination.Limit)
+	result, err := h.service.ListExperiences(pagination.Page, pagination.Limit, query)
 	if err != nil {
+		if h.httpAdapter.ListQueryError(c, err) {
+			return
+		}
 		h.httpAdapter.SendErrorResponse(c, http.StatusInternalServerError, "Failed to retrieve experiences")
 		return
 	}
 
+	if result.Data, err = httpAdapter.SelectFields(result.Data, query.Fields); err != nil {
+		h.httpAdapter.ListQueryError(c, err)
+		return
+	}
+
 	h.httpAdapter.SendSuccessResponse(c, http.StatusOK, result, "Experiences retrieved successfully")
 }
 
@@ -66,6 +82,7 @@ func (h *Handler) Create(c *gin.Context) {
 		h.httpAdapter.SendErrorResponse(c, http.StatusBadRequest, err.Error())
 		return
 	}
+	h.auditService.Record(h.httpAdapter.AuditActor(c), audit.ActionCreate, audit.EntityExperience, strconv.Itoa(experience.ID), nil, experience)
 
 	h.httpAdapter.SendSuccessResponse(c, http.StatusCreated, experience, "Experience created successfully")
 }
@@ -84,6 +101,7 @@ func (h *Handler) Update(c *gin.Context) {
 		return
 	}
 
+	before, _ := h.service.GetExperienceByID(id)
 	experience, err := h.service.UpdateExperience(id, req)
 	if err != nil {
 		if err.Error() == "record not found" {
@@ -93,6 +111,7 @@ func (h *Handler) Update(c *gin.Context) {
 		h.httpAdapter.SendErrorResponse(c, http.StatusBadRequest, err.Error())
 		return
 	}
+	h.auditService.Record(h.httpAdapter.AuditActor(c), audit.ActionUpdate, audit.EntityExperience, strconv.Itoa(id), before, experience)
 
 	h.httpAdapter.SendSuccessResponse(c, http.StatusOK, experience, "Experience updated successfully")
 }
@@ -110,6 +129,7 @@ func (h *Handler) Delete(c *gin.Context) {
 		return
 	}
 
+	before, _ := h.service.GetExperienceByID(id)
 	err = h.service.DeleteExperience(id)
 	if err != nil {
 		if err.Error() == "record not found" {
@@ -119,6 +139,7 @@ func (h *Handler) Delete(c *gin.Context) {
 		h.httpAdapter.SendErrorResponse(c, http.StatusInternalServerError, "Failed to delete experience")
 		return
 	}
+	h.auditService.Record(h.httpAdapter.AuditActor(c), audit.ActionDelete, audit.EntityExperience, strconv.Itoa(id), before, nil)
 
 	h.httpAdapter.SendSuccessResponse(c, http.StatusOK, nil, "Experience deleted successfully")
 }
@@ -0,0 +1,72 @@
+package feed
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+	applog "web-porto-backend/common/logger"
+	"web-porto-backend/common/response"
+	httpAdapter "web-porto-backend/internal/adapters/http"
+	feedSrvc "web-porto-backend/internal/services/feed"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Handler serves article feeds. Feeds live outside /api/v1 so the response
+// encoding middleware leaves them alone.
+type Handler struct {
+	service feedSrvc.Service
+	baseURL string
+}
+
+func NewHandler(service feedSrvc.Service, baseURL string) *Handler {
+	return &Handler{
+		service: service,
+		baseURL: strings.TrimRight(baseURL, "/"),
+	}
+}
+
+// Articles returns a handler for the article feed in the given format.
+// :slug selects a category or tag when the route has one (see by).
+// ?full=true includes the article body, ?limit= sets the number of entries.
+func (h *Handler) Articles(format, by string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		log := applog.GetLogger().WithFields(applog.Fields{"handler": "feed.Articles", "format": format})
+
+		opts := feedSrvc.Options{}
+		opts.FullContent, _ = strconv.ParseBool(c.Query("full"))
+		opts.Limit, _ = strconv.Atoi(c.Query("limit"))
+		switch by {
+		case "category":
+			opts.Category = c.Param("slug")
+		case "tag":
+			opts.Tag = c.Param("slug")
+		}
+
+		feed, err := h.service.Articles(opts)
+		if err != nil {
+			if errors.Is(err, feedSrvc.ErrCategoryNotFound) || errors.Is(err, feedSrvc.ErrTagNotFound) {
+				c.JSON(http.StatusNotFound, response.NewErrorResponse("Feed not found", err.Error()))
+				return
+			}
+			log.Error("building feed failed", applog.Fields{"error": err.Error()})
+			c.JSON(http.StatusInternalServerError, response.NewErrorResponse("Failed to build feed", err.Error()))
+			return
+		}
+
+		self := h.baseURL + c.Request.URL.Path
+		if c.Request.URL.RawQuery != "" {
+			self += "?" + c.Request.URL.RawQuery
+		}
+		body, err := render(format, feed, self)
+		if err != nil {
+			log.Error("rendering feed failed", applog.Fields{"error": err.Error()})
+			c.JSON(http.StatusInternalServerError, response.NewErrorResponse("Failed to render feed", err.Error()))
+			return
+		}
+
+		httpAdapter.SendCacheable(c, contentTypes[format], body, feed.Updated, 5*time.Minute)
+	}
+}
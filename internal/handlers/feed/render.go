@@ -0,0 +1,305 @@
+package feed
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"mime"
+	"path"
+	"strings"
+	"time"
+	feedSrvc "web-porto-backend/internal/services/feed"
+)
+
+// Supported output formats and their media types
+const (
+	FormatRSS  = "rss"
+	FormatAtom = "atom"
+	FormatJSON = "json"
+)
+
+var contentTypes = map[string]string{
+	FormatRSS:  "application/rss+xml; charset=utf-8",
+	FormatAtom: "application/atom+xml; charset=utf-8",
+	FormatJSON: "application/feed+json; charset=utf-8",
+}
+
+// render encodes a feed; self is the absolute URL of the feed itself
+func render(format string, feed *feedSrvc.Feed, self string) ([]byte, error) {
+	switch format {
+	case FormatRSS:
+		return marshalXML(toRSS(feed, self))
+	case FormatAtom:
+		return marshalXML(toAtom(feed, self))
+	default:
+		return json.MarshalIndent(toJSONFeed(feed, self), "", "  ")
+	}
+}
+
+func marshalXML(v interface{}) ([]byte, error) {
+	body, err := xml.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append([]byte(xml.Header), body...), nil
+}
+
+// imageType guesses the media type of an image URL for enclosures
+func imageType(u string) string {
+	if i := strings.IndexAny(u, "?#"); i >= 0 {
+		u = u[:i]
+	}
+	if t := mime.TypeByExtension(strings.ToLower(path.Ext(u))); t != "" {
+		return t
+	}
+	return "image/jpeg"
+}
+
+// RSS 2.0 with the content, dc and atom extensions
+
+type rssFeed struct {
+	XMLName   xml.Name   `xml:"rss"`
+	Version   string     `xml:"version,attr"`
+	ContentNS string     `xml:"xmlns:content,attr"`
+	DCNS      string     `xml:"xmlns:dc,attr"`
+	AtomNS    string     `xml:"xmlns:atom,attr"`
+	Channel   rssChannel `xml:"channel"`
+}
+
+type rssChannel struct {
+	Title         string    `xml:"title"`
+	Link          string    `xml:"link"`
+	Description   string    `xml:"description"`
+	AtomLink      rssLink   `xml:"atom:link"`
+	LastBuildDate string    `xml:"lastBuildDate,omitempty"`
+	Items         []rssItem `xml:"item"`
+}
+
+type rssLink struct {
+	Href string `xml:"href,attr"`
+	Rel  string `xml:"rel,attr"`
+	Type string `xml:"type,attr"`
+}
+
+type rssItem struct {
+	Title       string        `xml:"title"`
+	Link        string        `xml:"link"`
+	GUID        rssGUID       `xml:"guid"`
+	PubDate     string        `xml:"pubDate"`
+	Creator     string        `xml:"dc:creator,omitempty"`
+	Categories  []string      `xml:"category"`
+	Description string        `xml:"description,omitempty"`
+	Content     *rssCDATA     `xml:"content:encoded,omitempty"`
+	Enclosure   *rssEnclosure `xml:"enclosure,omitempty"`
+}
+
+type rssGUID struct {
+	IsPermaLink string `xml:"isPermaLink,attr"`
+	Value       string `xml:",chardata"`
+}
+
+type rssCDATA struct {
+	Value string `xml:",cdata"`
+}
+
+type rssEnclosure struct {
+	URL    string `xml:"url,attr"`
+	Length string `xml:"length,attr"`
+	Type   string `xml:"type,attr"`
+}
+
+func toRSS(feed *feedSrvc.Feed, self string) rssFeed {
+	channel := rssChannel{
+		Title:       feed.Title,
+		Link:        feed.Link,
+		Description: feed.Description,
+		AtomLink:    rssLink{Href: self, Rel: "self", Type: "application/rss+xml"},
+		Items:       make([]rssItem, 0, len(feed.Items)),
+	}
+	if !feed.Updated.IsZero() {
+		channel.LastBuildDate = feed.Updated.UTC().Format(time.RFC1123Z)
+	}
+	for _, item := range feed.Items {
+		entry := rssItem{
+			Title:       item.Title,
+			Link:        item.Link,
+			GUID:        rssGUID{IsPermaLink: "false", Value: "urn:uuid:" + item.ID},
+			PubDate:     item.Published.UTC().Format(time.RFC1123Z),
+			Creator:     item.Author,
+			Categories:  item.Categories,
+			Description: item.Summary,
+		}
+		if item.Content != "" {
+			entry.Content = &rssCDATA{Value: item.Content}
+		}
+		if item.Image != "" {
+			// The size is not known without fetching the file; readers accept 0
+			entry.Enclosure = &rssEnclosure{URL: item.Image, Length: "0", Type: imageType(item.Image)}
+		}
+		channel.Items = append(channel.Items, entry)
+	}
+	return rssFeed{
+		Version:   "2.0",
+		ContentNS: "http://purl.org/rss/1.0/modules/content/",
+		DCNS:      "http://purl.org/dc/elements/1.1/",
+		AtomNS:    "http://www.w3.org/2005/Atom",
+		Channel:   channel,
+	}
+}
+
+// Atom (RFC 4287)
+
+type atomFeed struct {
+	XMLName xml.Name    `xml:"feed"`
+	NS      string      `xml:"xmlns,attr"`
+	ID      string      `xml:"id"`
+	Title   string      `xml:"title"`
+	Updated string      `xml:"updated"`
+	Links   []atomLink  `xml:"link"`
+	Entries []atomEntry `xml:"entry"`
+}
+
+type atomLink struct {
+	Href string `xml:"href,attr"`
+	Rel  string `xml:"rel,attr,omitempty"`
+	Type string `xml:"type,attr,omitempty"`
+}
+
+type atomEntry struct {
+	ID         string         `xml:"id"`
+	Title      string         `xml:"title"`
+	Published  string         `xml:"published"`
+	Updated    string         `xml:"updated"`
+	Links      []atomLink     `xml:"link"`
+	Author     *atomPerson    `xml:"author,omitempty"`
+	Categories []atomCategory `xml:"category"`
+	Summary    *atomText      `xml:"summary,omitempty"`
+	Content    *atomText      `xml:"content,omitempty"`
+}
+
+type atomPerson struct {
+	Name string `xml:"name"`
+}
+
+type atomCategory struct {
+	Term string `xml:"term,attr"`
+}
+
+type atomText struct {
+	Type  string `xml:"type,attr"`
+	Value string `xml:",chardata"`
+}
+
+func toAtom(feed *feedSrvc.Feed, self string) atomFeed {
+	updated := feed.Updated
+	if updated.IsZero() {
+		updated = time.Unix(0, 0)
+	}
+	out := atomFeed{
+		NS:      "http://www.w3.org/2005/Atom",
+		ID:      self,
+		Title:   feed.Title,
+		Updated: updated.UTC().Format(time.RFC3339),
+		Links: []atomLink{
+			{Href: self, Rel: "self", Type: "application/atom+xml"},
+			{Href: feed.Link, Rel: "alternate", Type: "text/html"},
+		},
+		Entries: make([]atomEntry, 0, len(feed.Items)),
+	}
+	for _, item := range feed.Items {
+		entry := atomEntry{
+			ID:        "urn:uuid:" + item.ID,
+			Title:     item.Title,
+			Published: item.Published.UTC().Format(time.RFC3339),
+			Updated:   item.Updated.UTC().Format(time.RFC3339),
+			Links:     []atomLink{{Href: item.Link, Rel: "alternate", Type: "text/html"}},
+		}
+		if item.Author != "" {
+			entry.Author = &atomPerson{Name: item.Author}
+		}
+		for _, category := range item.Categories {
+			entry.Categories = append(entry.Categories, atomCategory{Term: category})
+		}
+		if item.Summary != "" {
+			entry.Summary = &atomText{Type: "text", Value: item.Summary}
+		}
+		if item.Content != "" {
+			entry.Content = &atomText{Type: "html", Value: item.Content}
+		}
+		if item.Image != "" {
+			entry.Links = append(entry.Links, atomLink{Href: item.Image, Rel: "enclosure", Type: imageType(item.Image)})
+		}
+		out.Entries = append(out.Entries, entry)
+	}
+	return out
+}
+
+// JSON Feed 1.1
+
+type jsonFeed struct {
+	Version     string         `json:"version"`
+	Title       string         `json:"title"`
+	HomePageURL string         `json:"home_page_url"`
+	FeedURL     string         `json:"feed_url"`
+	Description string         `json:"description,omitempty"`
+	Items       []jsonFeedItem `json:"items"`
+}
+
+type jsonFeedItem struct {
+	ID            string               `json:"id"`
+	URL           string               `json:"url"`
+	Title         string               `json:"title"`
+	ContentHTML   string               `json:"content_html,omitempty"`
+	ContentText   string               `json:"content_text,omitempty"`
+	Summary       string               `json:"summary,omitempty"`
+	Image         string               `json:"image,omitempty"`
+	DatePublished string               `json:"date_published"`
+	DateModified  string               `json:"date_modified"`
+	Authors       []jsonFeedAuthor     `json:"authors,omitempty"`
+	Tags          []string             `json:"tags,omitempty"`
+	Attachments   []jsonFeedAttachment `json:"attachments,omitempty"`
+}
+
+type jsonFeedAuthor struct {
+	Name string `json:"name"`
+}
+
+type jsonFeedAttachment struct {
+	URL      string `json:"url"`
+	MimeType string `json:"mime_type"`
+}
+
+func toJSONFeed(feed *feedSrvc.Feed, self string) jsonFeed {
+	out := jsonFeed{
+		Version:     "https://jsonfeed.org/version/1.1",
+		Title:       feed.Title,
+		HomePageURL: feed.Link,
+		FeedURL:     self,
+		Description: feed.Description,
+		Items:       make([]jsonFeedItem, 0, len(feed.Items)),
+	}
+	for _, item := range feed.Items {
+		entry := jsonFeedItem{
+			ID:            item.ID,
+			URL:           item.Link,
+			Title:         item.Title,
+			ContentHTML:   item.Content,
+			Summary:       item.Summary,
+			Image:         item.Image,
+			DatePublished: item.Published.UTC().Format(time.RFC3339),
+			DateModified:  item.Updated.UTC().Format(time.RFC3339),
+			Tags:          item.Categories,
+		}
+		// Every item needs content; excerpt feeds carry the summary as text
+		if entry.ContentHTML == "" {
+			entry.ContentText = item.Summary
+		}
+		if item.Author != "" {
+			entry.Authors = []jsonFeedAuthor{{Name: item.Author}}
+		}
+		if item.Image != "" {
+			entry.Attachments = []jsonFeedAttachment{{URL: item.Image, MimeType: imageType(item.Image)}}
+		}
+		out.Items = append(out.Items, entry)
+	}
+	return out
+}
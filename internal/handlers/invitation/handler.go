@@ -0,0 +1,109 @@
+package invitation
+
+import (
+	"errors"
+	"net/http"
+	"web-porto-backend/common/response"
+	httpAdapter "web-porto-backend/internal/adapters/http"
+	"web-porto-backend/internal/domain/dto"
+	"web-porto-backend/internal/domain/models"
+	"web-porto-backend/internal/services/invitation"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Handler handles HTTP requests for registration invitations
+type Handler struct {
+	service     invitation.Service
+	httpAdapter *httpAdapter.HTTPAdapter
+}
+
+// NewHandler creates a new invitation handler
+func NewHandler(service invitation.Service, httpAdapter *httpAdapter.HTTPAdapter) *Handler {
+	return &Handler{
+		service:     service,
+		httpAdapter: httpAdapter,
+	}
+}
+
+// GetAll lists invitations, optionally filtered by ?status=pending|accepted|revoked|expired
+func (h *Handler) GetAll(c *gin.Context) {
+	invitations, err := h.service.GetAll(c.Query("status"))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, response.NewErrorResponse("Failed to retrieve invitations", err.Error()))
+		return
+	}
+
+	result := make([]dto.InvitationResponse, 0, len(invitations))
+	for i := range invitations {
+		result = append(result, toInvitationResponse(&invitations[i]))
+	}
+	h.httpAdapter.SendSuccessResponse(c, http.StatusOK, result, "Invitations retrieved successfully")
+}
+
+// Create issues a new invitation. The token is only returned in this response.
+func (h *Handler) Create(c *gin.Context) {
+	var req dto.CreateInvitationRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, response.NewErrorResponse("Invalid invitation data", err.Error()))
+		return
+	}
+
+	token, inv, err := h.service.Create(req.Email, req.Role, c.GetInt("user_id"), c.GetStringSlice("permissions"))
+	if err != nil {
+		if errors.Is(err, invitation.ErrInvalidRole) {
+			c.JSON(http.StatusBadRequest, response.NewErrorResponse("Failed to create invitation", err.Error()))
+			return
+		}
+		if errors.Is(err, invitation.ErrRoleNotGrantable) {
+			c.JSON(http.StatusForbidden, response.NewErrorResponse("Failed to create invitation", err.Error()))
+			return
+		}
+		c.JSON(http.StatusInternalServerError, response.NewErrorResponse("Failed to create invitation", err.Error()))
+		return
+	}
+
+	result := dto.CreateInvitationResponse{
+		InvitationResponse: toInvitationResponse(inv),
+		Token:              token,
+	}
+	h.httpAdapter.SendSuccessResponse(c, http.StatusCreated, result, "Invitation created successfully")
+}
+
+// Revoke revokes a pending invitation
+func (h *Handler) Revoke(c *gin.Context) {
+	id, err := h.httpAdapter.ParseIntIDParam(c, "id")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, response.NewErrorResponse("Invalid invitation ID", err.Error()))
+		return
+	}
+
+	if err := h.service.Revoke(id); err != nil {
+		switch {
+		case errors.Is(err, invitation.ErrInviteNotFound):
+			c.JSON(http.StatusNotFound, response.NewErrorResponse("Invitation not found", err.Error()))
+		case errors.Is(err, invitation.ErrInviteNotPending):
+			c.JSON(http.StatusConflict, response.NewErrorResponse("Failed to revoke invitation", err.Error()))
+		default:
+			c.JSON(http.StatusInternalServerError, response.NewErrorResponse("Failed to revoke invitation", err.Error()))
+		}
+		return
+	}
+
+	h.httpAdapter.SendSuccessResponse(c, http.StatusOK, nil, "Invitation revoked successfully")
+}
+
+func toInvitationResponse(inv *models.Invitation) dto.InvitationResponse {
+	return dto.InvitationResponse{
+		ID:         inv.ID,
+		Email:      inv.Email,
+		Role:       inv.Role,
+		Status:     inv.Status(),
+		InvitedBy:  inv.InvitedBy,
+		ExpiresAt:  inv.ExpiresAt,
+		AcceptedAt: inv.AcceptedAt,
+		AcceptedBy: inv.AcceptedBy,
+		RevokedAt:  inv.RevokedAt,
+		CreatedAt:  inv.CreatedAt,
+	}
+}
@@ -0,0 +1,101 @@
+package loginattempt
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"web-porto-backend/common/response"
+	httpAdapter "web-porto-backend/internal/adapters/http"
+	"web-porto-backend/internal/domain/models"
+	"web-porto-backend/internal/services/loginattempt"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Handler exposes login attempt history and lockout management to admins
+type Handler struct {
+	service     loginattempt.Service
+	httpAdapter *httpAdapter.HTTPAdapter
+}
+
+// NewHandler creates a new login attempt handler
+func NewHandler(service loginattempt.Service, httpAdapter *httpAdapter.HTTPAdapter) *Handler {
+	return &Handler{
+		service:     service,
+		httpAdapter: httpAdapter,
+	}
+}
+
+// ClearLockoutRequest identifies the lockout to clear
+type ClearLockoutRequest struct {
+	Scope      string `json:"scope" binding:"required,oneof=account ip"`
+	Identifier string `json:"identifier" binding:"required"`
+}
+
+// GetAttempts lists login attempts filtered by ?email=, ?ip=, ?userId= and ?success=
+func (h *Handler) GetAttempts(c *gin.Context) {
+	pagination := h.httpAdapter.GetPaginationFromQuery(c)
+
+	filter := loginattempt.AttemptFilter{
+		Email: c.Query("email"),
+		IP:    c.Query("ip"),
+	}
+	if v := c.Query("userId"); v != "" {
+		id, err := strconv.Atoi(v)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, response.NewErrorResponse("Invalid userId", err.Error()))
+			return
+		}
+		filter.UserID = id
+	}
+	if v := c.Query("success"); v != "" {
+		success, err := strconv.ParseBool(v)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, response.NewErrorResponse("Invalid success filter", err.Error()))
+			return
+		}
+		filter.Success = &success
+	}
+
+	attempts, total, err := h.service.ListAttempts(filter, pagination.Page, pagination.Limit)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, response.NewErrorResponse("Failed to get login attempts", err.Error()))
+		return
+	}
+
+	h.httpAdapter.SendPaginatedResponse(c, attempts, pagination.Page, pagination.Limit, total, "Login attempts retrieved successfully")
+}
+
+// GetLockouts lists accounts and IPs that are currently locked
+func (h *Handler) GetLockouts(c *gin.Context) {
+	lockouts, err := h.service.ListLockouts()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, response.NewErrorResponse("Failed to get lockouts", err.Error()))
+		return
+	}
+	if lockouts == nil {
+		lockouts = []models.LoginThrottle{}
+	}
+
+	h.httpAdapter.SendSuccessResponse(c, http.StatusOK, lockouts, "Lockouts retrieved successfully")
+}
+
+// ClearLockout resets the failure counter for an account or IP
+func (h *Handler) ClearLockout(c *gin.Context) {
+	var req ClearLockoutRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, response.NewErrorResponse("Invalid request data", err.Error()))
+		return
+	}
+
+	if err := h.service.ClearLockout(req.Scope, req.Identifier); err != nil {
+		if errors.Is(err, loginattempt.ErrLockoutNotFound) {
+			c.JSON(http.StatusNotFound, response.NewErrorResponse("Lockout not found", err.Error()))
+			return
+		}
+		c.JSON(http.StatusInternalServerError, response.NewErrorResponse("Failed to clear lockout", err.Error()))
+		return
+	}
+
+	h.httpAdapter.SendSuccessResponse(c, http.StatusOK, nil, "Lockout cleared successfully")
+}
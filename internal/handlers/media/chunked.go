@@ -0,0 +1,101 @@
+package media
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"web-porto-backend/internal/domain/dto"
+	"web-porto-backend/internal/services/audit"
+	mediaSrvc "web-porto-backend/internal/services/media"
+
+	"github.com/gin-gonic/gin"
+)
+
+// CreateUpload starts a resumable upload. The response gives the upload ID,
+// the chunk size and the number of chunks to send.
+func (h *Handler) CreateUpload(c *gin.Context) {
+	var req dto.CreateUploadRequest
+	if err := h.httpAdapter.BindJSON(c, &req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+		return
+	}
+	status, err := h.mediaService.CreateUpload(uint(c.GetInt("user_id")), req)
+	if err != nil {
+		h.resumableError(c, err)
+		return
+	}
+	c.JSON(http.StatusCreated, gin.H{"data": status})
+}
+
+// GetUpload lists the chunks an upload received, so an interrupted client
+// can send only the missing ones
+func (h *Handler) GetUpload(c *gin.Context) {
+	status, err := h.mediaService.GetUpload(uint(c.GetInt("user_id")), c.Param("id"))
+	if err != nil {
+		h.resumableError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": status})
+}
+
+// PutChunk receives one chunk as the raw request body. X-Chunk-Checksum
+// carries its hex SHA-256; chunks may arrive in any order and be sent again.
+func (h *Handler) PutChunk(c *gin.Context) {
+	index, err := strconv.Atoi(c.Param("index"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid chunk index"})
+		return
+	}
+	checksum := c.GetHeader("X-Chunk-Checksum")
+	if checksum == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "X-Chunk-Checksum header is required"})
+		return
+	}
+	status, err := h.mediaService.PutChunk(uint(c.GetInt("user_id")), c.Param("id"), index, checksum, c.Request.Body)
+	if err != nil {
+		h.resumableError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": status})
+}
+
+// CompleteUpload assembles an upload whose chunks all arrived and creates
+// its media; completing it again returns the same media
+func (h *Handler) CompleteUpload(c *gin.Context) {
+	media, err := h.mediaService.CompleteUpload(c.Request.Context(), uint(c.GetInt("user_id")), c.Param("id"))
+	if err != nil {
+		h.resumableError(c, err)
+		return
+	}
+	h.auditService.Record(h.httpAdapter.AuditActor(c), audit.ActionCreate, audit.EntityMedia, strconv.Itoa(int(media.ID)), nil, media)
+
+	c.JSON(http.StatusOK, gin.H{"data": h.uploaded(media), "message": "File uploaded successfully"})
+}
+
+// AbortUpload cancels an upload and deletes its chunks
+func (h *Handler) AbortUpload(c *gin.Context) {
+	if err := h.mediaService.AbortUpload(uint(c.GetInt("user_id")), c.Param("id")); err != nil {
+		h.resumableError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Upload cancelled"})
+}
+
+// resumableError maps errors of resumable uploads to responses
+func (h *Handler) resumableError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, mediaSrvc.ErrUploadNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "Upload not found"})
+	case errors.Is(err, mediaSrvc.ErrUploadCompleted), errors.Is(err, mediaSrvc.ErrUploadInProgress), errors.Is(err, mediaSrvc.ErrUploadIncomplete):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	case errors.Is(err, mediaSrvc.ErrChunkOutOfRange), errors.Is(err, mediaSrvc.ErrChunkSize),
+		errors.Is(err, mediaSrvc.ErrInvalidChecksum), errors.Is(err, mediaSrvc.ErrChecksumMismatch):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	case errors.Is(err, mediaSrvc.ErrQuotaExceeded):
+		c.JSON(http.StatusForbidden, gin.H{"error": "Upload quota exceeded"})
+	case errors.Is(err, mediaSrvc.ErrFolderNotFound), errors.Is(err, mediaSrvc.ErrInvalidFolder):
+		h.mediaError(c, err, "Failed to resolve folder")
+	default:
+		uploadError(c, err)
+	}
+}
@@ -1,49 +1,54 @@
 package media
 
 import (
+	"bytes"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
-	"os"
-	"path/filepath"
+	"sort"
+	"strconv"
 	"strings"
-	"time"
+	"web-porto-backend/internal/adapters/filetype"
+	httpAdapter "web-porto-backend/internal/adapters/http"
+	"web-porto-backend/internal/auth"
+	"web-porto-backend/internal/domain/dto"
 	"web-porto-backend/internal/domain/models"
+	"web-porto-backend/internal/repositories/scopes"
+	"web-porto-backend/internal/services/audit"
+	mediaSrvc "web-porto-backend/internal/services/media"
 
 	"github.com/gin-gonic/gin"
-	"github.com/google/uuid"
 	"gorm.io/gorm"
 )
 
 // Handler handles media upload and management
 type Handler struct {
-	db        *gorm.DB
-	uploadDir string
-	baseURL   string
+	db           *gorm.DB
+	mediaService mediaSrvc.Service
+	auditService audit.Service
+	httpAdapter  *httpAdapter.HTTPAdapter
 }
 
 // NewHandler creates a new media handler
-func NewHandler(db *gorm.DB, uploadDir string, baseURL string) *Handler {
-	// Ensure upload directory exists
-	if err := os.MkdirAll(uploadDir, 0755); err != nil {
-		panic(fmt.Sprintf("Failed to create upload directory: %v", err))
-	}
+func NewHandler(db *gorm.DB, mediaService mediaSrvc.Service, auditService audit.Service, httpAdapter *httpAdapter.HTTPAdapter) *Handler {
 	return &Handler{
-		db:        db,
-		uploadDir: uploadDir,
-		baseURL:   baseURL,
+		db:           db,
+		mediaService: mediaService,
+		auditService: auditService,
+		httpAdapter:  httpAdapter,
 	}
 }
 
-// Upload handles file upload, saves to disk, and records to DB
+// Upload handles file upload, saves it to the storage backend, and records to DB
 func (h *Handler) Upload(c *gin.Context) {
+	ctx := c.Request.Context()
+
 	// First handle old file deletion if requested
 	oldFileURL := c.PostForm("oldFileUrl")
 	if oldFileURL != "" {
-		var oldMedia models.Media
-		if err := h.db.Where("file_url = ?", oldFileURL).First(&oldMedia).Error; err == nil {
-			os.Remove(oldMedia.FilePath)
-			h.db.Delete(&oldMedia)
+		if oldMedia, err := h.mediaService.DeleteByURL(ctx, oldFileURL); err == nil {
+			h.auditService.Record(h.httpAdapter.AuditActor(c), audit.ActionDelete, audit.EntityMedia, strconv.Itoa(int(oldMedia.ID)), oldMedia, nil)
 		}
 	}
 
@@ -55,128 +60,232 @@ func (h *Handler) Upload(c *gin.Context) {
 	}
 	defer file.Close()
 
-	// Validate file type
-	mimeType := header.Header.Get("Content-Type")
-	if mimeType == "" {
-		// Detect from content
-		buf := make([]byte, 512)
-		n, _ := file.Read(buf)
-		mimeType = http.DetectContentType(buf[:n])
-		// Reset reader
-		file.Seek(0, io.SeekStart)
+	// Reject oversized files before reading them; the limit of the actual
+	// type is applied once the content tells what it is
+	maxSize := h.mediaService.MaxUploadSize()
+	if header.Size > maxSize {
+		c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("File too large. Maximum allowed size is %dMB.", maxSize>>20)})
+		return
 	}
-
-	allowed := isAllowedMIME(mimeType)
-	if !allowed {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "File type not allowed. Allowed: images, PDF, video"})
+	data, err := io.ReadAll(io.LimitReader(file, maxSize+1))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to read file"})
 		return
 	}
-
-	// Validate file size
-	// Images max 50MB, Videos max 50MB
-	const maxImageSize int64 = 50 * 1024 * 1024 // 50 MB
-	const maxVideoSize int64 = 50 * 1024 * 1024 // 50 MB
-	var maxSize int64 = maxImageSize
-
-	if strings.HasPrefix(mimeType, "video/") || strings.HasPrefix(header.Header.Get("Content-Type"), "video/") {
-		maxSize = maxVideoSize
+	if int64(len(data)) > maxSize {
+		c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("File too large. Maximum allowed size is %dMB.", maxSize>>20)})
+		return
 	}
 
-	if header.Size > maxSize {
-		maxSizeMB := maxSize / (1024 * 1024)
-		c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("File too large. Maximum allowed size is %dMB.", maxSizeMB)})
+	// The type comes from the content and must agree with the extension;
+	// SVGs are sanitized or refused per the configured policy
+	upload, err := h.mediaService.Validate(header.Filename, data)
+	if err != nil {
+		uploadError(c, err)
 		return
 	}
+	data = upload.Data
+	mimeType := upload.MimeType
 
-	// Generate unique filename
-	ext := filepath.Ext(header.Filename)
-	uniqueName := uuid.New().String() + ext
-
-	// Handle specific folder paths (e.g., profiles, projects/projectABC)
-	folderPath := c.PostForm("folder")
-	finalUploadDir := h.uploadDir
-	urlPrefix := "uploads"
-
-	if folderPath != "" {
-		cleanFolder := filepath.Clean(folderPath)
-		// Ensure it doesn't try to escape into absolute roots or parents
-		if !strings.HasPrefix(cleanFolder, "..") && cleanFolder != "." {
-			finalUploadDir = filepath.Join(h.uploadDir, cleanFolder)
-			urlPrefix = fmt.Sprintf("uploads/%s", strings.ReplaceAll(cleanFolder, "\\", "/"))
-		}
+	// Files go below the path of their library folder, given by folderId or
+	// as a path in folder (e.g., profiles, projects/projectABC)
+	folder, err := h.uploadFolder(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid folder: " + err.Error()})
+		return
 	}
 
-	// Ensure structural directory exists
-	if err := os.MkdirAll(finalUploadDir, 0755); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create directory structure"})
-		return
+	// Images lose their EXIF/GPS metadata before they are stored;
+	// variants are generated in the background
+	var width, height int
+	processable := false
+	if upload.Category == "image" && mimeType != filetype.SVG {
+		data, width, height, processable = h.mediaService.Prepare(data)
 	}
 
-	// Create destination
-	destPath := filepath.Join(finalUploadDir, uniqueName)
-	out, err := os.Create(destPath)
-	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create file on server"})
+	userID := uint(c.GetInt("user_id"))
+	if quota, err := h.mediaService.CheckQuota(userID, int64(len(data))); err != nil {
+		if errors.Is(err, mediaSrvc.ErrQuotaExceeded) {
+			c.JSON(http.StatusForbidden, gin.H{"error": "Upload quota exceeded", "quota": quota})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to check upload quota"})
 		return
 	}
-	defer out.Close()
 
-	// Copy content
-	size, err := io.Copy(out, file)
+	// Stored files get a random name with the extension of their type;
+	// private ones are kept apart and only served through signed URLs
+	private, _ := strconv.ParseBool(c.PostForm("private"))
+	media, err := h.mediaService.Store(ctx, mediaSrvc.NewMedia{
+		OriginalName: header.Filename,
+		MimeType:     mimeType,
+		Category:     upload.Category,
+		Ext:          upload.Ext,
+		Body:         bytes.NewReader(data),
+		Size:         int64(len(data)),
+		Width:        width,
+		Height:       height,
+		Processable:  processable,
+		UserID:       userID,
+		Folder:       folder,
+		Title:        strings.TrimSpace(c.PostForm("title")),
+		AltText:      strings.TrimSpace(c.PostForm("altText")),
+		Caption:      strings.TrimSpace(c.PostForm("caption")),
+		Credit:       strings.TrimSpace(c.PostForm("credit")),
+		Private:      private,
+	})
 	if err != nil {
-		os.Remove(destPath)
+		if errors.Is(err, mediaSrvc.ErrInvalidFolder) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid folder"})
+			return
+		}
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to save file"})
 		return
 	}
+	h.auditService.Record(h.httpAdapter.AuditActor(c), audit.ActionCreate, audit.EntityMedia, strconv.Itoa(int(media.ID)), nil, media)
 
-	// Build accessible URL
-	fileURL := fmt.Sprintf("%s/%s/%s", strings.TrimRight(h.baseURL, "/"), urlPrefix, uniqueName)
+	c.JSON(http.StatusOK, gin.H{"data": h.uploaded(media), "message": "File uploaded successfully"})
+}
 
-	// Save to database
-	media := models.Media{
-		FileName:     uniqueName,
-		OriginalName: header.Filename,
-		FilePath:     destPath,
-		FileURL:      fileURL,
-		FileType:     getFileCategory(mimeType),
-		FileSize:     size,
-		MimeType:     mimeType,
-		UploadedAt:   time.Now(),
+// uploaded describes newly stored media. Variants are still being
+// generated; the planned ones are listed so clients can build srcset
+// attributes right away.
+func (h *Handler) uploaded(media *models.Media) gin.H {
+	variants := h.planned(media)
+	media, _ = h.mediaService.Sign(media, 0)
+	return gin.H{
+		"id":               media.ID,
+		"fileName":         media.FileName,
+		"originalName":     media.OriginalName,
+		"fileUrl":          media.FileURL,
+		"storage":          media.Storage,
+		"private":          media.Private,
+		"folderId":         media.FolderID,
+		"title":            media.Title,
+		"altText":          media.AltText,
+		"caption":          media.Caption,
+		"credit":           media.Credit,
+		"fileType":         media.FileType,
+		"fileSize":         media.FileSize,
+		"mimeType":         media.MimeType,
+		"uploadedAt":       media.UploadedAt,
+		"width":            media.Width,
+		"height":           media.Height,
+		"processingStatus": media.ProcessingStatus,
+		"variants":         variants,
+		"srcset":           srcset(media, variants),
 	}
+}
+
+// planned returns the variants processing creates for media, signed like its
+// own URLs when it is private
+func (h *Handler) planned(media *models.Media) []models.MediaVariant {
+	view := *media
+	view.Variants = h.mediaService.Planned(media)
+	signed, _ := h.mediaService.Sign(&view, 0)
+	return signed.Variants
+}
 
-	if err := h.db.Create(&media).Error; err != nil {
-		// Clean up file if DB insert fails
-		os.Remove(destPath)
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to save media record"})
+// Get returns one media record with its variants and srcset strings. While
+// processing is pending the planned variants are listed. Private media are
+// only found by users who may upload, and come with signed URLs.
+func (h *Handler) Get(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid media ID"})
+		return
+	}
+	media, err := h.mediaService.GetByID(uint(id))
+	if err != nil {
+		if errors.Is(err, mediaSrvc.ErrMediaNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Media not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch media"})
+		return
+	}
+	if media.Private && !h.httpAdapter.HasPermission(c, auth.PermMediaUpload) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Media not found"})
 		return
 	}
 
+	var variants []models.MediaVariant
+	if media.ProcessingStatus == models.MediaStatusPending || media.ProcessingStatus == models.MediaStatusProcessing {
+		variants = h.planned(media)
+	}
+	media, _ = h.mediaService.Sign(media, 0)
+	if variants == nil {
+		variants = media.Variants
+	}
 	c.JSON(http.StatusOK, gin.H{
 		"data": gin.H{
-			"id":           media.ID,
-			"fileName":     media.FileName,
-			"originalName": media.OriginalName,
-			"fileUrl":      media.FileURL,
-			"fileType":     media.FileType,
-			"fileSize":     media.FileSize,
-			"mimeType":     media.MimeType,
-			"uploadedAt":   media.UploadedAt,
+			"media":  media,
+			"srcset": srcset(media, variants),
 		},
-		"message": "File uploaded successfully",
 	})
 }
 
-// GetAll returns all media records
+// GetAll returns a page of the media library. It takes ?page=&limit=, a
+// ?search= term, the list query parameters (filter[type], filter[folder],
+// filter[tag], filter[uploadedAt][from], sort=...), or ?cursor= for keyset
+// pages newest first. Private media are only listed to users who may upload.
 func (h *Handler) GetAll(c *gin.Context) {
+	if h.httpAdapter.UsesCursor(c) {
+		h.getPage(c)
+		return
+	}
+
+	query, ok := h.httpAdapter.ListQuery(c)
+	if !ok {
+		return
+	}
+	pagination := h.httpAdapter.GetPaginationFromQuery(c)
+
+	withPrivate := h.httpAdapter.HasPermission(c, auth.PermMediaUpload)
+	result, err := h.mediaService.List(pagination.Page, pagination.Limit, query, c.Query("search"), withPrivate)
+	if err != nil {
+		if h.httpAdapter.ListQueryError(c, err) {
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch media"})
+		return
+	}
+	if result.Data, err = httpAdapter.SelectFields(result.Data, query.Fields); err != nil {
+		h.httpAdapter.ListQueryError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, result)
+}
+
+// getPage serves GetAll in cursor mode
+func (h *Handler) getPage(c *gin.Context) {
+	q, ok := h.httpAdapter.CursorQuery(c)
+	if !ok {
+		return
+	}
+
+	db := h.db
+	if !h.httpAdapter.HasPermission(c, auth.PermMediaUpload) {
+		db = db.Where("private = ?", false)
+	}
 	var mediaList []models.Media
-	if err := h.db.Order("uploaded_at desc").Find(&mediaList).Error; err != nil {
+	if err := db.Preload("Folder").Preload("Tags").Scopes(scopes.Keyset("uploaded_at", "id", q)).Find(&mediaList).Error; err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch media"})
 		return
 	}
-	c.JSON(http.StatusOK, gin.H{"data": mediaList})
+	mediaList, result := scopes.KeysetPage(mediaList, q, func(m models.Media) dto.Cursor {
+		return dto.Cursor{CreatedAt: m.UploadedAt, ID: int64(m.ID)}
+	})
+	for i := range mediaList {
+		signed, _ := h.mediaService.Sign(&mediaList[i], 0)
+		mediaList[i] = *signed
+	}
+	c.JSON(http.StatusOK, gin.H{"data": mediaList, "pagination": h.httpAdapter.CursorPagination(q, result)})
 }
 
-// Delete removes a media file from disk and database
+// Delete removes a media file from its storage backend and the database.
+// Files still referenced by content are kept unless ?force=true; the
+// response lists where they are used.
 func (h *Handler) Delete(c *gin.Context) {
 	id := c.Param("id")
 	var media models.Media
@@ -185,48 +294,63 @@ func (h *Handler) Delete(c *gin.Context) {
 		return
 	}
 
-	// Remove file from disk
-	os.Remove(media.FilePath)
+	if force, _ := strconv.ParseBool(c.Query("force")); !force {
+		usages, err := h.mediaService.Usage(&media)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to check media usage"})
+			return
+		}
+		if len(usages) > 0 {
+			c.JSON(http.StatusConflict, gin.H{"error": "Media is in use; delete with ?force=true to remove it anyway", "usages": usages})
+			return
+		}
+	}
 
-	// Remove from database
-	if err := h.db.Delete(&media).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete media record"})
+	// Remove the file and its variants, then the record
+	if err := h.mediaService.Remove(c.Request.Context(), &media); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete media"})
 		return
 	}
+	h.auditService.Record(h.httpAdapter.AuditActor(c), audit.ActionDelete, audit.EntityMedia, strconv.Itoa(int(media.ID)), media, nil)
 
 	c.JSON(http.StatusOK, gin.H{"message": "Media deleted successfully"})
 }
 
-func isAllowedMIME(mime string) bool {
-	allowed := []string{
-		"image/jpeg",
-		"image/png",
-		"image/gif",
-		"image/webp",
-		"image/svg+xml",
-		"application/pdf",
-		"video/mp4",
-		"video/webm",
-		"video/ogg",
-	}
-	mime = strings.ToLower(strings.TrimSpace(mime))
-	for _, a := range allowed {
-		if strings.HasPrefix(mime, a) {
-			return true
-		}
+// srcset builds a srcset string per media type from the variants of an
+// image; the original is included in its own type
+func srcset(media *models.Media, variants []models.MediaVariant) map[string]string {
+	if media.Width == 0 {
+		return map[string]string{}
 	}
-	return false
-}
+	all := append([]models.MediaVariant{{Width: media.Width, MimeType: media.MimeType, URL: media.FileURL}}, variants...)
+	sort.SliceStable(all, func(i, j int) bool { return all[i].Width < all[j].Width })
 
-func getFileCategory(mime string) string {
-	if strings.HasPrefix(mime, "image/") {
-		return "image"
+	entries := map[string][]string{}
+	for _, v := range all {
+		entries[v.MimeType] = append(entries[v.MimeType], fmt.Sprintf("%s %dw", v.URL, v.Width))
 	}
-	if strings.HasPrefix(mime, "video/") {
-		return "video"
+	out := make(map[string]string, len(entries))
+	for mimeType, list := range entries {
+		out[mimeType] = strings.Join(list, ", ")
 	}
-	if strings.HasPrefix(mime, "application/pdf") {
-		return "document"
+	return out
+}
+
+// uploadError responds to an upload Validate refused
+func uploadError(c *gin.Context, err error) {
+	var tooLarge *mediaSrvc.SizeLimitError
+	switch {
+	case errors.As(err, &tooLarge):
+		c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("File too large. Maximum allowed size for this type is %dMB.", tooLarge.Limit>>20)})
+	case errors.Is(err, mediaSrvc.ErrUnsupportedType):
+		c.JSON(http.StatusBadRequest, gin.H{"error": "File type not allowed. Allowed: JPEG, PNG, GIF, WebP and SVG images, PDF, MP4, WebM and Ogg video"})
+	case errors.Is(err, mediaSrvc.ErrTypeMismatch):
+		c.JSON(http.StatusBadRequest, gin.H{"error": "File extension does not match its content"})
+	case errors.Is(err, mediaSrvc.ErrSVGNotAllowed):
+		c.JSON(http.StatusBadRequest, gin.H{"error": "SVG uploads are not allowed"})
+	case errors.Is(err, filetype.ErrInvalidSVG):
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid SVG file"})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to process file"})
 	}
-	return "file"
 }
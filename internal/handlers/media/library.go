@@ -0,0 +1,179 @@
+package media
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"web-porto-backend/internal/domain/dto"
+	"web-porto-backend/internal/domain/models"
+	"web-porto-backend/internal/services/audit"
+	mediaSrvc "web-porto-backend/internal/services/media"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Update edits the title, alt text, caption, credit, folder and tags of a
+// media item. Changing private moves its files to or from the private storage.
+func (h *Handler) Update(c *gin.Context) {
+	id, ok := h.mediaID(c)
+	if !ok {
+		return
+	}
+	var req dto.UpdateMediaRequest
+	if err := h.httpAdapter.BindJSON(c, &req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+		return
+	}
+
+	before, err := h.mediaService.GetByID(id)
+	if err != nil {
+		h.mediaError(c, err, "Failed to fetch media")
+		return
+	}
+	media, err := h.mediaService.Update(c.Request.Context(), id, req)
+	if err != nil {
+		h.mediaError(c, err, "Failed to update media")
+		return
+	}
+	h.auditService.Record(h.httpAdapter.AuditActor(c), audit.ActionUpdate, audit.EntityMedia, strconv.Itoa(int(id)), before, media)
+
+	signed, _ := h.mediaService.Sign(media, 0)
+	c.JSON(http.StatusOK, gin.H{"data": signed, "message": "Media updated successfully"})
+}
+
+// Usage lists the articles, projects, experiences, pages and settings
+// referencing a media file or one of its variants
+func (h *Handler) Usage(c *gin.Context) {
+	id, ok := h.mediaID(c)
+	if !ok {
+		return
+	}
+	media, err := h.mediaService.GetByID(id)
+	if err != nil {
+		h.mediaError(c, err, "Failed to fetch media")
+		return
+	}
+	usages, err := h.mediaService.Usage(media)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to check media usage"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": gin.H{"inUse": len(usages) > 0, "usages": usages}})
+}
+
+// GetFolders returns all folders ordered by path
+func (h *Handler) GetFolders(c *gin.Context) {
+	folders, err := h.mediaService.ListFolders()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch folders"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": folders})
+}
+
+// CreateFolder creates a folder, at the root or below parentId
+func (h *Handler) CreateFolder(c *gin.Context) {
+	var req dto.MediaFolderRequest
+	if err := h.httpAdapter.BindJSON(c, &req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+		return
+	}
+	folder, err := h.mediaService.CreateFolder(req)
+	if err != nil {
+		h.mediaError(c, err, "Failed to create folder")
+		return
+	}
+	h.auditService.Record(h.httpAdapter.AuditActor(c), audit.ActionCreate, audit.EntityMediaFolder, strconv.Itoa(int(folder.ID)), nil, folder)
+
+	c.JSON(http.StatusCreated, gin.H{"data": folder, "message": "Folder created successfully"})
+}
+
+// UpdateFolder renames a folder or moves it below another one
+func (h *Handler) UpdateFolder(c *gin.Context) {
+	id, ok := h.mediaID(c)
+	if !ok {
+		return
+	}
+	var req dto.MediaFolderRequest
+	if err := h.httpAdapter.BindJSON(c, &req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+		return
+	}
+
+	before, err := h.mediaService.GetFolder(id)
+	if err != nil {
+		h.mediaError(c, err, "Failed to fetch folder")
+		return
+	}
+	folder, err := h.mediaService.UpdateFolder(id, req)
+	if err != nil {
+		h.mediaError(c, err, "Failed to update folder")
+		return
+	}
+	h.auditService.Record(h.httpAdapter.AuditActor(c), audit.ActionUpdate, audit.EntityMediaFolder, strconv.Itoa(int(id)), before, folder)
+
+	c.JSON(http.StatusOK, gin.H{"data": folder, "message": "Folder updated successfully"})
+}
+
+// DeleteFolder removes an empty folder
+func (h *Handler) DeleteFolder(c *gin.Context) {
+	id, ok := h.mediaID(c)
+	if !ok {
+		return
+	}
+	before, err := h.mediaService.GetFolder(id)
+	if err != nil {
+		h.mediaError(c, err, "Failed to fetch folder")
+		return
+	}
+	if err := h.mediaService.DeleteFolder(id); err != nil {
+		h.mediaError(c, err, "Failed to delete folder")
+		return
+	}
+	h.auditService.Record(h.httpAdapter.AuditActor(c), audit.ActionDelete, audit.EntityMediaFolder, strconv.Itoa(int(id)), before, nil)
+
+	c.JSON(http.StatusOK, gin.H{"message": "Folder deleted successfully"})
+}
+
+// uploadFolder resolves the folderId or folder form field of an upload; nil
+// means the root
+func (h *Handler) uploadFolder(c *gin.Context) (*models.MediaFolder, error) {
+	if folderID := c.PostForm("folderId"); folderID != "" {
+		id, err := strconv.ParseUint(folderID, 10, 64)
+		if err != nil {
+			return nil, mediaSrvc.ErrFolderNotFound
+		}
+		return h.mediaService.GetFolder(uint(id))
+	}
+	if folderPath := c.PostForm("folder"); folderPath != "" {
+		return h.mediaService.EnsureFolder(folderPath)
+	}
+	return nil, nil
+}
+
+func (h *Handler) mediaID(c *gin.Context) (uint, bool) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid ID"})
+		return 0, false
+	}
+	return uint(id), true
+}
+
+// mediaError maps service errors to responses
+func (h *Handler) mediaError(c *gin.Context, err error, msg string) {
+	switch {
+	case errors.Is(err, mediaSrvc.ErrMediaNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "Media not found"})
+	case errors.Is(err, mediaSrvc.ErrFolderNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "Folder not found"})
+	case errors.Is(err, mediaSrvc.ErrFolderExists), errors.Is(err, mediaSrvc.ErrFolderNotEmpty), errors.Is(err, mediaSrvc.ErrMediaProcessing):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	case errors.Is(err, mediaSrvc.ErrUserNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+	case errors.Is(err, mediaSrvc.ErrInvalidFolder), errors.Is(err, mediaSrvc.ErrInvalidQuota), errors.Is(err, mediaSrvc.ErrMediaNotStored):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": msg})
+	}
+}
@@ -0,0 +1,91 @@
+package media
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"path"
+	"strings"
+	"time"
+	"web-porto-backend/internal/adapters/storage"
+	mediaSrvc "web-porto-backend/internal/services/media"
+
+	"github.com/gin-gonic/gin"
+)
+
+// SignedURL returns URLs of a private media item and its variants that are
+// valid for ?ttl= (the configured lifetime by default, at most 24h). Public
+// media get their plain URLs.
+func (h *Handler) SignedURL(c *gin.Context) {
+	id, ok := h.mediaID(c)
+	if !ok {
+		return
+	}
+	var ttl time.Duration
+	if s := c.Query("ttl"); s != "" {
+		d, err := time.ParseDuration(s)
+		if err != nil || d <= 0 || d > mediaSrvc.MaxSignedURLTTL {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid ttl; it must be positive and at most 24h"})
+			return
+		}
+		ttl = d
+	}
+
+	media, err := h.mediaService.GetByID(id)
+	if err != nil {
+		h.mediaError(c, err, "Failed to fetch media")
+		return
+	}
+	signed, expires := h.mediaService.Sign(media, ttl)
+	data := gin.H{"url": signed.FileURL, "variants": signed.Variants, "private": media.Private}
+	if media.Private {
+		data["expiresAt"] = expires
+	}
+	c.JSON(http.StatusOK, gin.H{"data": data})
+}
+
+// ServeFile serves a private file for a URL signed by SignedURL. Range and
+// conditional requests are answered by http.ServeContent; responses may only
+// be cached by the browser, and not beyond the expiry of the URL.
+func (h *Handler) ServeFile(c *gin.Context) {
+	key, err := storage.CleanKey(strings.TrimPrefix(c.Param("key"), "/"))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "File not found"})
+		return
+	}
+	expires, err := h.mediaService.VerifyURL(key, c.Query("expires"), c.Query("signature"))
+	if err != nil {
+		if errors.Is(err, mediaSrvc.ErrURLExpired) {
+			c.JSON(http.StatusForbidden, gin.H{"error": "Link expired"})
+			return
+		}
+		c.JSON(http.StatusForbidden, gin.H{"error": "Invalid signature"})
+		return
+	}
+
+	ctx := c.Request.Context()
+	backend := h.mediaService.PrivateStorage()
+	obj, err := backend.Stat(ctx, key)
+	if err != nil {
+		if errors.Is(err, storage.ErrNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "File not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to read file"})
+		return
+	}
+
+	if obj.ContentType != "" {
+		c.Header("Content-Type", obj.ContentType)
+	}
+	etag := obj.ETag
+	if etag == "" {
+		etag = fmt.Sprintf("%x-%x", obj.ModTime.UnixNano(), obj.Size)
+	}
+	c.Header("ETag", `"`+etag+`"`)
+	c.Header("Cache-Control", fmt.Sprintf("private, max-age=%d", int(time.Until(expires).Seconds())))
+
+	body := storage.NewReadSeeker(ctx, backend, key, obj.Size)
+	defer body.Close()
+	http.ServeContent(c.Writer, c.Request, path.Base(key), obj.ModTime, body)
+}
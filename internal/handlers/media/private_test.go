@@ -0,0 +1,179 @@
+package media
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+	"web-porto-backend/internal/adapters/storage"
+	"web-porto-backend/internal/domain/models"
+	mediaSrvc "web-porto-backend/internal/services/media"
+
+	"github.com/gin-gonic/gin"
+)
+
+const fileBody = "0123456789abcdefghij"
+
+// newFileServer serves private files the way main.go routes them, with one
+// file stored under reports/q1.pdf
+func newFileServer(t *testing.T) (*gin.Engine, mediaSrvc.Service) {
+	t.Helper()
+	dir := t.TempDir()
+	store, err := storage.New(storage.Config{
+		LocalDir:   filepath.Join(dir, "public"),
+		LocalURL:   "/uploads",
+		PrivateDir: filepath.Join(dir, "private"),
+		PrivateURL: "/files",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = store.Private().Put(context.Background(), "reports/q1.pdf", strings.NewReader(fileBody), int64(len(fileBody)),
+		storage.PutOptions{ContentType: "application/pdf"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	mediaService := mediaSrvc.NewService(nil, store, nil, mediaSrvc.Config{SigningKey: "media key", SignedURLTTL: time.Minute})
+	h := NewHandler(nil, mediaService, nil, nil)
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.GET("/files/*key", h.ServeFile)
+	r.HEAD("/files/*key", h.ServeFile)
+	return r, mediaService
+}
+
+func signedPath(t *testing.T, s mediaSrvc.Service, key string) string {
+	t.Helper()
+	signed, _ := s.Sign(&models.Media{Private: true, StorageKey: key, FileURL: "/files/" + key}, 0)
+	return signed.FileURL
+}
+
+func serve(r *gin.Engine, method, target string, header http.Header) *httptest.ResponseRecorder {
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest(method, target, nil)
+	for k, v := range header {
+		req.Header[k] = v
+	}
+	r.ServeHTTP(w, req)
+	return w
+}
+
+func TestServeFile(t *testing.T) {
+	r, s := newFileServer(t)
+	target := signedPath(t, s, "reports/q1.pdf")
+
+	w := serve(r, http.MethodGet, target, nil)
+	if w.Code != http.StatusOK || w.Body.String() != fileBody {
+		t.Fatalf("GET = %d %q", w.Code, w.Body.String())
+	}
+	if ct := w.Header().Get("Content-Type"); ct != "application/pdf" {
+		t.Errorf("Content-Type = %q", ct)
+	}
+	if cc := w.Header().Get("Cache-Control"); !strings.HasPrefix(cc, "private, max-age=") {
+		t.Errorf("Cache-Control = %q", cc)
+	}
+	etag := w.Header().Get("ETag")
+	if etag == "" {
+		t.Fatal("no ETag")
+	}
+
+	w = serve(r, http.MethodGet, target, http.Header{"Range": {"bytes=5-9"}})
+	if w.Code != http.StatusPartialContent || w.Body.String() != "56789" {
+		t.Fatalf("ranged GET = %d %q", w.Code, w.Body.String())
+	}
+	if cr := w.Header().Get("Content-Range"); cr != "bytes 5-9/20" {
+		t.Errorf("Content-Range = %q", cr)
+	}
+
+	w = serve(r, http.MethodGet, target, http.Header{"Range": {"bytes=-3"}})
+	if w.Code != http.StatusPartialContent || w.Body.String() != "hij" {
+		t.Fatalf("suffix range GET = %d %q", w.Code, w.Body.String())
+	}
+
+	w = serve(r, http.MethodGet, target, http.Header{"Range": {"bytes=40-50"}})
+	if w.Code != http.StatusRequestedRangeNotSatisfiable {
+		t.Fatalf("unsatisfiable range GET = %d", w.Code)
+	}
+
+	w = serve(r, http.MethodHead, target, nil)
+	if w.Code != http.StatusOK || w.Body.Len() != 0 {
+		t.Fatalf("HEAD = %d with %d bytes", w.Code, w.Body.Len())
+	}
+	if cl := w.Header().Get("Content-Length"); cl != "20" {
+		t.Errorf("HEAD Content-Length = %q", cl)
+	}
+	if w.Header().Get("ETag") != etag {
+		t.Errorf("HEAD ETag = %q, want %q", w.Header().Get("ETag"), etag)
+	}
+
+	w = serve(r, http.MethodGet, target, http.Header{"If-None-Match": {etag}})
+	if w.Code != http.StatusNotModified {
+		t.Fatalf("conditional GET = %d", w.Code)
+	}
+}
+
+func TestServeFileRejectsBadLinks(t *testing.T) {
+	r, s := newFileServer(t)
+	signed, err := url.Parse(signedPath(t, s, "reports/q1.pdf"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	q := signed.Query()
+	with := func(path string, set func(url.Values)) string {
+		v := url.Values{"expires": {q.Get("expires")}, "signature": {q.Get("signature")}}
+		if set != nil {
+			set(v)
+		}
+		return path + "?" + v.Encode()
+	}
+	tampered := "A" + q.Get("signature")[1:]
+	if tampered == q.Get("signature") {
+		tampered = "B" + q.Get("signature")[1:]
+	}
+	other := mediaSrvc.NewService(nil, nil, nil, mediaSrvc.Config{SigningKey: "another key", SignedURLTTL: time.Minute})
+
+	tests := []struct {
+		name   string
+		target string
+		code   int
+		body   string
+	}{
+		{"no signature", "/files/reports/q1.pdf", http.StatusForbidden, "Invalid signature"},
+		{"tampered signature", with("/files/reports/q1.pdf", func(v url.Values) { v.Set("signature", tampered) }), http.StatusForbidden, "Invalid signature"},
+		{"extended expiry", with("/files/reports/q1.pdf", func(v url.Values) { v.Set("expires", q.Get("expires")+"0") }), http.StatusForbidden, "Invalid signature"},
+		{"signature of another file", with("/files/reports/q2.pdf", nil), http.StatusForbidden, "Invalid signature"},
+		{"signed with another key", signedPath(t, other, "reports/q1.pdf"), http.StatusForbidden, "Invalid signature"},
+		{"expired", expiredPath(t, s, "reports/q1.pdf"), http.StatusForbidden, "Link expired"},
+		{"path traversal", with("/files/reports/../reports/q1.pdf", nil), http.StatusNotFound, "File not found"},
+		{"missing file", signedPath(t, s, "reports/gone.pdf"), http.StatusNotFound, "File not found"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for _, method := range []string{http.MethodGet, http.MethodHead} {
+				w := serve(r, method, tt.target, nil)
+				if w.Code != tt.code {
+					t.Fatalf("%s = %d, want %d", method, w.Code, tt.code)
+				}
+				if method == http.MethodGet && !strings.Contains(w.Body.String(), tt.body) {
+					t.Fatalf("body = %s, want %q", w.Body.String(), tt.body)
+				}
+				if strings.Contains(w.Body.String(), fileBody) {
+					t.Fatal("file served")
+				}
+			}
+		})
+	}
+}
+
+// expiredPath is a URL of key signed by s that has already expired: expiry
+// is truncated to the second, so a nanosecond lifetime ends before now
+func expiredPath(t *testing.T, s mediaSrvc.Service, key string) string {
+	t.Helper()
+	signed, _ := s.Sign(&models.Media{Private: true, StorageKey: key, FileURL: "/files/" + key}, time.Nanosecond)
+	return signed.FileURL
+}
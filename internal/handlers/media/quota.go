@@ -0,0 +1,49 @@
+package media
+
+import (
+	"net/http"
+	"web-porto-backend/internal/domain/dto"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Quota reports the upload usage and quota of the authenticated user
+func (h *Handler) Quota(c *gin.Context) {
+	quota, err := h.mediaService.Quota(uint(c.GetInt("user_id")))
+	if err != nil {
+		h.mediaError(c, err, "Failed to fetch upload usage")
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": quota})
+}
+
+// ListQuotas reports the upload usage and quota of every user, biggest
+// uploaders first
+func (h *Handler) ListQuotas(c *gin.Context) {
+	quotas, err := h.mediaService.ListQuotas()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch upload usage"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": quotas})
+}
+
+// SetQuota overrides the upload quota of a user with {"quotaMb": n}; null
+// restores the default and 0 makes it unlimited
+func (h *Handler) SetQuota(c *gin.Context) {
+	id, ok := h.mediaID(c)
+	if !ok {
+		return
+	}
+	var req dto.MediaQuotaRequest
+	if err := h.httpAdapter.BindJSON(c, &req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+		return
+	}
+	quota, err := h.mediaService.SetQuota(id, req.QuotaMB)
+	if err != nil {
+		h.mediaError(c, err, "Failed to update upload quota")
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": quota, "message": "Upload quota updated successfully"})
+}
@@ -0,0 +1,32 @@
+package media
+
+import (
+	"net/http"
+	"time"
+	mediaSrvc "web-porto-backend/internal/services/media"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Reconcile reports orphaned files, media with missing files and media no
+// content uses. GET only reports; POST also deletes the orphaned files and
+// unreferenced media older than the grace period (?grace=72h overrides the
+// configured one).
+func (h *Handler) Reconcile(c *gin.Context) {
+	opts := mediaSrvc.ReconcileOptions{Cleanup: c.Request.Method == http.MethodPost}
+	if grace := c.Query("grace"); grace != "" {
+		d, err := time.ParseDuration(grace)
+		if err != nil || d <= 0 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid grace period"})
+			return
+		}
+		opts.Grace = d
+	}
+
+	report, err := h.mediaService.Reconcile(c.Request.Context(), opts)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to reconcile media: " + err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": report})
+}
@@ -2,36 +2,50 @@
 
 import (
 	"net/http"
+	"strconv"
+	"time"
 	"web-porto-backend/common/response"
 	httpAdapter "web-porto-backend/internal/adapters/http"
+	"web-porto-backend/internal/auth"
+	"web-porto-backend/internal/domain/dto"
 	"web-porto-backend/internal/domain/models"
+	"web-porto-backend/internal/services/audit"
 	"web-porto-backend/internal/services/page"
 
 	"github.com/gin-gonic/gin"
 )
 
 type Handler struct {
-	service     page.Service
-	httpAdapter *httpAdapter.HTTPAdapter
+	service      page.Service
+	auditService audit.Service
+	httpAdapter  *httpAdapter.HTTPAdapter
 }
 
-func NewHandler(service page.Service, httpAdapter *httpAdapter.HTTPAdapter) *Handler {
+func NewHandler(service page.Service, auditService audit.Service, httpAdapter *httpAdapter.HTTPAdapter) *Handler {
 	return &Handler{
-		service:     service,
-		httpAdapter: httpAdapter,
+		service:      service,
+		auditService: auditService,
+		httpAdapter:  httpAdapter,
 	}
 }
 
 type CreatePageRequest struct {
-	Title   string `json:"title" binding:"required"`
-	Content string `json:"content" binding:"required"`
-	Status  string `json:"status" binding:"required,oneof=draft published"`
+	Title       string     `json:"title" binding:"required"`
+	Content     string     `json:"content" binding:"required"`
+	Status      string     `json:"status" binding:"required,oneof=draft published scheduled"`
+	SEO         *dto.SEO   `json:"seo"`
+	PublishAt   *time.Time `json:"publishAt"`
+	UnpublishAt *time.Time `json:"unpublishAt"`
 }
 
 type UpdatePageRequest struct {
 	Title   string `json:"title"`
 	Content string `json:"content"`
-	Status  string `json:"status" binding:"oneof=draft published"`
+	Status  string `json:"status" binding:"oneof=draft published scheduled"`
+	// SEO replaces the stored block; nil keeps it
+	SEO         *dto.SEO   `json:"seo"`
+	PublishAt   *time.Time `json:"publishAt"`
+	UnpublishAt *time.Time `json:"unpublishAt"`
 }
 
 func (h *Handler) Create(c *gin.Context) {
@@ -40,25 +54,46 @@ func (h *Handler) Create(c *gin.Context) {
 		c.JSON(http.StatusBadRequest, response.NewErrorResponse("Invalid request data", err.Error()))
 		return
 	}
+	if !h.httpAdapter.AuthorizePublish(c, req.Status, auth.PermPagePublish) {
+		return
+	}
+	if !h.httpAdapter.ValidSEO(c, req.SEO) {
+		return
+	}
 
 	page := &models.Page{
-		Title:   req.Title,
-		Content: req.Content,
-		Status:  req.Status,
+		Title:       req.Title,
+		Content:     req.Content,
+		Status:      req.Status,
+		PublishedAt: req.PublishAt,
+	}
+	if req.SEO != nil {
+		page.SEO = models.SEO(*req.SEO)
 	}
 
 	if err := h.service.Create(page); err != nil {
 		c.JSON(http.StatusInternalServerError, response.NewErrorResponse("Failed to create page", err.Error()))
 		return
 	}
+	if req.UnpublishAt != nil {
+		if err := h.service.ScheduleUnpublish(uint(page.ID), *req.UnpublishAt, c.GetInt("user_id")); err != nil {
+			c.JSON(http.StatusInternalServerError, response.NewErrorResponse("Failed to schedule unpublish", err.Error()))
+			return
+		}
+	}
+	h.auditService.Record(h.httpAdapter.AuditActor(c), audit.ActionCreate, audit.EntityPage, strconv.Itoa(page.ID), nil, page)
 
 	h.httpAdapter.SendSuccessResponse(c, http.StatusCreated, page, "Page created successfully")
 }
 
 func (h *Handler) GetAll(c *gin.Context) {
+	status, ok := h.httpAdapter.StatusFilter(c, auth.PermPageWrite)
+	if !ok {
+		return
+	}
 	pagination := h.httpAdapter.GetPaginationFromQuery(c)
 
-	pages, paginationInfo, err := h.service.GetAll(pagination.Page, pagination.Limit)
+	pages, paginationInfo, err := h.service.GetAll(pagination.Page, pagination.Limit, status)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, response.NewErrorResponse("Failed to get pages", err.Error()))
 		return
@@ -80,6 +115,10 @@ func (h *Handler) GetByID(c *gin.Context) {
 		c.JSON(http.StatusNotFound, response.NewErrorResponse("Page not found", err.Error()))
 		return
 	}
+	if !h.httpAdapter.CanView(c, auth.PermPageWrite, page.Status, page.PublishedAt) {
+		c.JSON(http.StatusNotFound, response.NewErrorResponse("Page not found"))
+		return
+	}
 
 	h.httpAdapter.SendSuccessResponse(c, http.StatusOK, page, "Page retrieved successfully")
 }
@@ -96,17 +135,39 @@ func (h *Handler) Update(c *gin.Context) {
 		c.JSON(http.StatusBadRequest, response.NewErrorResponse("Invalid request data", err.Error()))
 		return
 	}
+	if !h.httpAdapter.AuthorizePublish(c, req.Status, auth.PermPagePublish) {
+		return
+	}
+	if !h.httpAdapter.ValidSEO(c, req.SEO) {
+		return
+	}
 
+	before, _ := h.service.GetByID(id)
 	page := &models.Page{
-		Title:   req.Title,
-		Content: req.Content,
-		Status:  req.Status,
+		Title:       req.Title,
+		Content:     req.Content,
+		Status:      req.Status,
+		PublishedAt: req.PublishAt,
+	}
+	if req.SEO != nil {
+		page.SEO = models.SEO(*req.SEO)
+	} else if before != nil {
+		page.SEO = before.SEO
 	}
 
-	if err := h.service.Update(id, page); err != nil {
+	if err := h.service.Update(id, page, c.GetInt("user_id")); err != nil {
 		c.JSON(http.StatusInternalServerError, response.NewErrorResponse("Failed to update page", err.Error()))
 		return
 	}
+	if req.UnpublishAt != nil {
+		if err := h.service.ScheduleUnpublish(id, *req.UnpublishAt, c.GetInt("user_id")); err != nil {
+			c.JSON(http.StatusInternalServerError, response.NewErrorResponse("Failed to schedule unpublish", err.Error()))
+			return
+		}
+	}
+	if after, err := h.service.GetByID(id); err == nil {
+		h.auditService.Record(h.httpAdapter.AuditActor(c), audit.ActionUpdate, audit.EntityPage, strconv.Itoa(int(id)), before, after)
+	}
 
 	h.httpAdapter.SendSuccessResponse(c, http.StatusOK, nil, "Page updated successfully")
 }
@@ -118,10 +179,12 @@ func (h *Handler) Delete(c *gin.Context) {
 		return
 	}
 
+	before, _ := h.service.GetByID(id)
 	if err := h.service.Delete(id); err != nil {
 		c.JSON(http.StatusInternalServerError, response.NewErrorResponse("Failed to delete page", err.Error()))
 		return
 	}
+	h.auditService.Record(h.httpAdapter.AuditActor(c), audit.ActionDelete, audit.EntityPage, strconv.Itoa(int(id)), before, nil)
 
 	h.httpAdapter.SendSuccessResponse(c, http.StatusOK, nil, "Page deleted successfully")
 }
@@ -138,6 +201,10 @@ func (h *Handler) GetBySlug(c *gin.Context) {
 		c.JSON(http.StatusNotFound, response.NewErrorResponse("Page not found", err.Error()))
 		return
 	}
+	if !h.httpAdapter.CanView(c, auth.PermPageWrite, page.Status, page.PublishedAt) {
+		c.JSON(http.StatusNotFound, response.NewErrorResponse("Page not found"))
+		return
+	}
 
 	h.httpAdapter.SendSuccessResponse(c, http.StatusOK, page, "Page retrieved successfully")
 }
@@ -35,14 +35,20 @@ func (a *PostServiceAdapter) Create(post *models.Post) error {
 	return err
 }
 
-// GetAll gets all posts using ArticleService
-func (a *PostServiceAdapter) GetAll(page, limit int) ([]*models.Post, *postService.PaginationInfo, error) {
+// GetAll gets posts with the given status using ArticleService
+func (a *PostServiceAdapter) GetAll(page, limit int, status string) ([]*models.Post, *postService.PaginationInfo, error) {
 	// Use ArticleService to get articles
-	response, err := a.articleService.ListArticles(page, limit)
+	response, err := a.articleService.ListArticles(page, limit, status, dto.ListQuery{})
 	if err != nil {
 		return nil, nil, err
 	}
 
+	posts, paginationInfo := convertArticleListToPosts(response)
+	return posts, paginationInfo, nil
+}
+
+// convertArticleListToPosts converts a paginated article list to posts
+func convertArticleListToPosts(response *dto.PaginatedResponse) ([]*models.Post, *postService.PaginationInfo) {
 	// Convert articles to posts
 	posts := make([]*models.Post, 0)
 	articleListResponses, ok := response.Data.([]dto.ArticleListResponse)
@@ -57,7 +63,7 @@ func (a *PostServiceAdapter) GetAll(page, limit int) ([]*models.Post, *postServi
 				Limit:      response.Pagination.PageSize,
 				TotalPages: response.Pagination.TotalPages,
 			}
-			return posts, paginationInfo, nil
+			return posts, paginationInfo
 		}
 
 		// Convert each interface{} to ArticleListResponse
@@ -83,7 +89,7 @@ func (a *PostServiceAdapter) GetAll(page, limit int) ([]*models.Post, *postServi
 		TotalPages: response.Pagination.TotalPages,
 	}
 
-	return posts, paginationInfo, nil
+	return posts, paginationInfo
 }
 
 // GetByID gets a post by ID using ArticleService
@@ -102,6 +108,7 @@ func (a *PostServiceAdapter) GetByID(id string) (*models.Post, error) {
 		Status:           article.Status,
 		AuthorID:         article.Author.ID,
 		FeaturedImageURL: article.FeaturedImageURL,
+		PublishedAt:      article.PublishedAt,
 		ViewCount:        article.ViewCount,
 		CreatedAt:        article.CreatedAt,
 		UpdatedAt:        article.UpdatedAt,
@@ -144,24 +151,27 @@ func (a *PostServiceAdapter) GetBySlug(slug string) (*models.Post, error) {
 		Status:           article.Status,
 		AuthorID:         article.Author.ID,
 		FeaturedImageURL: article.FeaturedImageURL,
+		PublishedAt:      article.PublishedAt,
 		ViewCount:        article.ViewCount,
 		CreatedAt:        article.CreatedAt,
 		UpdatedAt:        article.UpdatedAt,
 	}, nil
 }
 
-// GetByAuthorID gets posts by author ID using ArticleService (simplified implementation)
-func (a *PostServiceAdapter) GetByAuthorID(authorID, page, limit int) ([]*models.Post, *postService.PaginationInfo, error) {
-	// For simplicity, just return all articles
-	// In a real implementation, you'd filter by author ID
-	return a.GetAll(page, limit)
+// GetByAuthorID gets an author's posts with the given status using ArticleService
+func (a *PostServiceAdapter) GetByAuthorID(authorID, page, limit int, status string) ([]*models.Post, *postService.PaginationInfo, error) {
+	response, err := a.articleService.ListArticlesByAuthor(authorID, status, page, limit)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	posts, paginationInfo := convertArticleListToPosts(response)
+	return posts, paginationInfo, nil
 }
 
-// GetPublished gets published posts using ArticleService (simplified implementation)
+// GetPublished gets the posts visitors may see using ArticleService
 func (a *PostServiceAdapter) GetPublished(page, limit int) ([]*models.Post, *postService.PaginationInfo, error) {
-	// For simplicity, just return all articles
-	// In a real implementation, you'd filter by status
-	return a.GetAll(page, limit)
+	return a.GetAll(page, limit, models.StatusPublished)
 }
 
 // Helper function to convert ArticleListResponse to Post
@@ -174,6 +184,7 @@ func convertArticleResponseToPost(article dto.ArticleListResponse) *models.Post
 		Status:           article.Status,
 		AuthorID:         0, // Author ID not available in list response
 		FeaturedImageURL: article.FeaturedImageURL,
+		PublishedAt:      article.PublishedAt,
 		ViewCount:        article.ViewCount,
 		CreatedAt:        article.CreatedAt,
 	}
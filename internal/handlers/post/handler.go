@@ -5,7 +5,9 @@ import (
 	"web-porto-backend/common/response"
 	"web-porto-backend/common/utils"
 	httpAdapter "web-porto-backend/internal/adapters/http"
+	"web-porto-backend/internal/auth"
 	"web-porto-backend/internal/domain/models"
+	"web-porto-backend/internal/services/audit"
 	"web-porto-backend/internal/services/post"
 
 	"github.com/gin-gonic/gin"
@@ -28,14 +30,16 @@ const (
 )
 
 type Handler struct {
-	service     post.Service
-	httpAdapter *httpAdapter.HTTPAdapter
+	service      post.Service
+	auditService audit.Service
+	httpAdapter  *httpAdapter.HTTPAdapter
 }
 
-func NewHandler(service post.Service, httpAdapter *httpAdapter.HTTPAdapter) *Handler {
+func NewHandler(service post.Service, auditService audit.Service, httpAdapter *httpAdapter.HTTPAdapter) *Handler {
 	return &Handler{
-		service:     service,
-		httpAdapter: httpAdapter,
+		service:      service,
+		auditService: auditService,
+		httpAdapter:  httpAdapter,
 	}
 }
 
@@ -59,6 +63,9 @@ func (h *Handler) Create(c *gin.Context) {
 		c.JSON(http.StatusBadRequest, response.NewErrorResponse("Invalid request data", err.Error()))
 		return
 	}
+	if !h.httpAdapter.AuthorizePublish(c, req.Status, auth.PermArticlePublish) {
+		return
+	}
 
 	post := &models.Post{
 		Title:    req.Title,
@@ -71,14 +78,20 @@ func (h *Handler) Create(c *gin.Context) {
 		c.JSON(http.StatusInternalServerError, response.NewErrorResponse("Failed to create post", err.Error()))
 		return
 	}
+	// Posts are stored as articles
+	h.auditService.Record(h.httpAdapter.AuditActor(c), audit.ActionCreate, audit.EntityArticle, post.ID, nil, post)
 
 	h.httpAdapter.SendSuccessResponse(c, http.StatusCreated, post, msgPostCreated)
 }
 
 func (h *Handler) GetAll(c *gin.Context) {
+	status, ok := h.httpAdapter.StatusFilter(c, auth.PermArticleWrite)
+	if !ok {
+		return
+	}
 	pagination := h.httpAdapter.GetPaginationFromQuery(c)
 
-	posts, paginationInfo, err := h.service.GetAll(pagination.Page, pagination.Limit)
+	posts, paginationInfo, err := h.service.GetAll(pagination.Page, pagination.Limit, status)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, response.NewErrorResponse("Failed to get posts", err.Error()))
 		return
@@ -101,6 +114,10 @@ func (h *Handler) GetByID(c *gin.Context) {
 		c.JSON(http.StatusNotFound, response.NewErrorResponse(msgPostNotFound, err.Error()))
 		return
 	}
+	if !h.httpAdapter.CanView(c, auth.PermArticleWrite, post.Status, post.PublishedAt) {
+		c.JSON(http.StatusNotFound, response.NewErrorResponse(msgPostNotFound))
+		return
+	}
 
 	h.httpAdapter.SendSuccessResponse(c, http.StatusOK, post, msgPostRetrieved)
 }
@@ -118,6 +135,9 @@ func (h *Handler) Update(c *gin.Context) {
 		c.JSON(http.StatusBadRequest, response.NewErrorResponse(msgInvalidRequestData, err.Error()))
 		return
 	}
+	if !h.httpAdapter.AuthorizePublish(c, req.Status, auth.PermArticlePublish) {
+		return
+	}
 
 	post := &models.Post{
 		Title:    req.Title,
@@ -126,10 +146,14 @@ func (h *Handler) Update(c *gin.Context) {
 		AuthorID: req.AuthorID,
 	}
 
+	before, _ := h.service.GetByID(id)
 	if err := h.service.Update(id, post); err != nil {
 		c.JSON(http.StatusInternalServerError, response.NewErrorResponse("Failed to update post", err.Error()))
 		return
 	}
+	if after, err := h.service.GetByID(id); err == nil {
+		h.auditService.Record(h.httpAdapter.AuditActor(c), audit.ActionUpdate, audit.EntityArticle, id, before, after)
+	}
 
 	h.httpAdapter.SendSuccessResponse(c, http.StatusOK, nil, msgPostUpdated)
 }
@@ -142,10 +166,12 @@ func (h *Handler) Delete(c *gin.Context) {
 		return
 	}
 
+	before, _ := h.service.GetByID(id)
 	if err := h.service.Delete(id); err != nil {
 		c.JSON(http.StatusInternalServerError, response.NewErrorResponse("Failed to delete post", err.Error()))
 		return
 	}
+	h.auditService.Record(h.httpAdapter.AuditActor(c), audit.ActionDelete, audit.EntityArticle, id, before, nil)
 
 	h.httpAdapter.SendSuccessResponse(c, http.StatusOK, nil, msgPostDeleted)
 }
@@ -162,6 +188,10 @@ func (h *Handler) GetBySlug(c *gin.Context) {
 		c.JSON(http.StatusNotFound, response.NewErrorResponse(msgPostNotFound, err.Error()))
 		return
 	}
+	if !h.httpAdapter.CanView(c, auth.PermArticleWrite, post.Status, post.PublishedAt) {
+		c.JSON(http.StatusNotFound, response.NewErrorResponse(msgPostNotFound))
+		return
+	}
 
 	h.httpAdapter.SendSuccessResponse(c, http.StatusOK, post, msgPostRetrieved)
 }
@@ -173,10 +203,14 @@ func (h *Handler) GetByAuthor(c *gin.Context) {
 		c.JSON(http.StatusBadRequest, response.NewErrorResponse("Invalid author ID", err.Error()))
 		return
 	}
+	status, ok := h.httpAdapter.StatusFilter(c, auth.PermArticleWrite)
+	if !ok {
+		return
+	}
 
 	pagination := h.httpAdapter.GetPaginationFromQuery(c)
 
-	posts, paginationInfo, err := h.service.GetByAuthorID(authorID, pagination.Page, pagination.Limit)
+	posts, paginationInfo, err := h.service.GetByAuthorID(authorID, pagination.Page, pagination.Limit, status)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, response.NewErrorResponse("Failed to get posts", err.Error()))
 		return
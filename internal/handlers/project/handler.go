@@ -4,7 +4,10 @@ import (
 	"net/http"
 	"web-porto-backend/common/response"
 	httpAdapter "web-porto-backend/internal/adapters/http"
+	"web-porto-backend/internal/auth"
 	"web-porto-backend/internal/domain/dto"
+	"web-porto-backend/internal/domain/models"
+	"web-porto-backend/internal/services/audit"
 	"web-porto-backend/internal/services/project"
 
 	"github.com/gin-gonic/gin"
@@ -20,15 +23,17 @@ const (
 
 // Handler handles HTTP requests for project endpoints
 type Handler struct {
-	service     *project.Service
-	httpAdapter *httpAdapter.HTTPAdapter
+	service      *project.Service
+	auditService audit.Service
+	httpAdapter  *httpAdapter.HTTPAdapter
 }
 
 // NewHandler creates a new project handler
-func NewHandler(service *project.Service, httpAdapter *httpAdapter.HTTPAdapter) *Handler {
+func NewHandler(service *project.Service, auditService audit.Service, httpAdapter *httpAdapter.HTTPAdapter) *Handler {
 	return &Handler{
-		service:     service,
-		httpAdapter: httpAdapter,
+		service:      service,
+		auditService: auditService,
+		httpAdapter:  httpAdapter,
 	}
 }
 
@@ -39,27 +44,46 @@ func (h *Handler) Create(c *gin.Context) {
 		c.JSON(http.StatusBadRequest, response.NewErrorResponse(msgInvalidRequestData, err.Error()))
 		return
 	}
+	if !h.httpAdapter.AuthorizePublish(c, req.Status, auth.PermProjectPublish) {
+		return
+	}
+	if !h.httpAdapter.ValidSEO(c, req.SEO) {
+		return
+	}
 
 	// Set default authorID to be handled by service
 	// This simplifies authentication - we just check if the token is valid
 	// but we don't need to extract user ID from it
 	// req.AuthorID will be set by service using default admin user if 0
+	req.EditorID = c.GetInt("user_id")
 
 	project, err := h.service.CreateProject(req)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, response.NewErrorResponse("Failed to create project", err.Error()))
 		return
 	}
+	h.auditService.Record(h.httpAdapter.AuditActor(c), audit.ActionCreate, audit.EntityProject, project.ID, nil, project)
 
 	h.httpAdapter.SendSuccessResponse(c, http.StatusCreated, project, "Project created successfully")
 }
 
-// GetAll gets all projects
+// GetAll gets published projects; CMS users may pass ?status= to see others
 func (h *Handler) GetAll(c *gin.Context) {
+	status, ok := h.httpAdapter.StatusFilter(c, auth.PermProjectWrite)
+	if !ok {
+		return
+	}
+	query, ok := h.httpAdapter.ListQuery(c)
+	if !ok {
+		return
+	}
 	pagination := h.httpAdapter.GetPaginationFromQuery(c)
 
-	projects, err := h.service.ListProjects(pagination.Page, pagination.Limit)
+	projects, err := h.service.ListProjects(pagination.Page, pagination.Limit, status, query)
 	if err != nil {
+		if h.httpAdapter.ListQueryError(c, err) {
+			return
+		}
 		c.JSON(http.StatusInternalServerError, response.NewErrorResponse("Failed to get projects", err.Error()))
 		return
 	}
@@ -75,6 +99,11 @@ func (h *Handler) GetAll(c *gin.Context) {
 		}
 	}
 
+	if projects.Data, err = httpAdapter.SelectFields(projects.Data, query.Fields); err != nil {
+		h.httpAdapter.ListQueryError(c, err)
+		return
+	}
+
 	h.httpAdapter.SendSuccessResponse(c, http.StatusOK, projects, msgProjectsRetrieved)
 }
 
@@ -82,8 +111,7 @@ func (h *Handler) GetAll(c *gin.Context) {
 func (h *Handler) GetPublished(c *gin.Context) {
 	pagination := h.httpAdapter.GetPaginationFromQuery(c)
 
-	// For simplicity, just call list projects (could be optimized with a specific method)
-	projects, err := h.service.ListProjects(pagination.Page, pagination.Limit)
+	projects, err := h.service.ListProjects(pagination.Page, pagination.Limit, models.StatusPublished, dto.ListQuery{})
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, response.NewErrorResponse("Failed to get published projects", err.Error()))
 		return
@@ -105,6 +133,10 @@ func (h *Handler) GetByID(c *gin.Context) {
 		c.JSON(http.StatusNotFound, response.NewErrorResponse(msgProjectNotFound, err.Error()))
 		return
 	}
+	if !h.httpAdapter.CanView(c, auth.PermProjectWrite, project.Status, project.PublishedAt) {
+		c.JSON(http.StatusNotFound, response.NewErrorResponse(msgProjectNotFound))
+		return
+	}
 
 	h.httpAdapter.SendSuccessResponse(c, http.StatusOK, project, "Project retrieved successfully")
 }
@@ -122,6 +154,10 @@ func (h *Handler) GetBySlug(c *gin.Context) {
 		c.JSON(http.StatusNotFound, response.NewErrorResponse(msgProjectNotFound, err.Error()))
 		return
 	}
+	if !h.httpAdapter.CanView(c, auth.PermProjectWrite, project.Status, project.PublishedAt) {
+		c.JSON(http.StatusNotFound, response.NewErrorResponse(msgProjectNotFound))
+		return
+	}
 
 	h.httpAdapter.SendSuccessResponse(c, http.StatusOK, project, "Project retrieved successfully")
 }
@@ -133,10 +169,14 @@ func (h *Handler) GetByCategory(c *gin.Context) {
 		c.JSON(http.StatusBadRequest, response.NewErrorResponse("Category slug is required"))
 		return
 	}
+	status, ok := h.httpAdapter.StatusFilter(c, auth.PermProjectWrite)
+	if !ok {
+		return
+	}
 
 	pagination := h.httpAdapter.GetPaginationFromQuery(c)
 
-	projects, err := h.service.GetProjectsByCategorySlug(slug, pagination.Page, pagination.Limit)
+	projects, err := h.service.GetProjectsByCategorySlug(slug, status, pagination.Page, pagination.Limit)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, response.NewErrorResponse("Failed to get projects by category", err.Error()))
 		return
@@ -152,12 +192,15 @@ func (h *Handler) GetByTechnology(c *gin.Context) {
 		c.JSON(http.StatusBadRequest, response.NewErrorResponse("Technology name is required"))
 		return
 	}
+	status, ok := h.httpAdapter.StatusFilter(c, auth.PermProjectWrite)
+	if !ok {
+		return
+	}
 
 	pagination := h.httpAdapter.GetPaginationFromQuery(c)
 
-	// This would need to be implemented in the service
-	// For now, just return all projects
-	projects, err := h.service.ListProjects(pagination.Page, pagination.Limit)
+	query := dto.ListQuery{Filters: map[string][]string{"technology": {techName}}}
+	projects, err := h.service.ListProjects(pagination.Page, pagination.Limit, status, query)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, response.NewErrorResponse("Failed to get projects by technology", err.Error()))
 		return
@@ -179,6 +222,12 @@ func (h *Handler) Update(c *gin.Context) {
 		c.JSON(http.StatusBadRequest, response.NewErrorResponse(msgInvalidRequestData, err.Error()))
 		return
 	}
+	if req.Status != nil && !h.httpAdapter.AuthorizePublish(c, *req.Status, auth.PermProjectPublish) {
+		return
+	}
+	if !h.httpAdapter.ValidSEO(c, req.SEO) {
+		return
+	}
 
 	// Handle potential temporary ID from frontend
 	if len(id) > 0 && id[:5] == "temp-" {
@@ -193,6 +242,9 @@ func (h *Handler) Update(c *gin.Context) {
 			TagNames:        req.TagNames,
 			AuthorID:        0, // Will be set by service using default admin
 			Metadata:        req.Metadata,
+			PublishAt:       req.PublishAt,
+			UnpublishAt:     req.UnpublishAt,
+			EditorID:        c.GetInt("user_id"),
 		}
 
 		if req.Title != nil {
@@ -225,12 +277,15 @@ func (h *Handler) Update(c *gin.Context) {
 			c.JSON(http.StatusInternalServerError, response.NewErrorResponse("Failed to create project from temp ID", err.Error()))
 			return
 		}
+		h.auditService.Record(h.httpAdapter.AuditActor(c), audit.ActionCreate, audit.EntityProject, project.ID, nil, project)
 
 		h.httpAdapter.SendSuccessResponse(c, http.StatusOK, project, "Project created successfully")
 		return
 	}
 
 	// If not a temp ID, proceed with normal update
+	req.EditorID = c.GetInt("user_id")
+	before, _ := h.service.GetProjectByID(id)
 	project, err := h.service.UpdateProject(id, req)
 	if err != nil {
 		if err.Error() == "record not found" {
@@ -240,6 +295,7 @@ func (h *Handler) Update(c *gin.Context) {
 		c.JSON(http.StatusInternalServerError, response.NewErrorResponse("Failed to update project", err.Error()))
 		return
 	}
+	h.auditService.Record(h.httpAdapter.AuditActor(c), audit.ActionUpdate, audit.EntityProject, id, before, project)
 
 	h.httpAdapter.SendSuccessResponse(c, http.StatusOK, project, "Project updated successfully")
 }
@@ -257,8 +313,16 @@ func (h *Handler) Patch(c *gin.Context) {
 		c.JSON(http.StatusBadRequest, response.NewErrorResponse(msgInvalidRequestData, err.Error()))
 		return
 	}
+	if req.Status != nil && !h.httpAdapter.AuthorizePublish(c, *req.Status, auth.PermProjectPublish) {
+		return
+	}
+	if !h.httpAdapter.ValidSEO(c, req.SEO) {
+		return
+	}
 
 	// Service.UpdateProject currently implements partial update logic correctly via nil-pointer checks
+	req.EditorID = c.GetInt("user_id")
+	before, _ := h.service.GetProjectByID(id)
 	project, err := h.service.UpdateProject(id, req)
 	if err != nil {
 		if err.Error() == "record not found" {
@@ -268,6 +332,7 @@ func (h *Handler) Patch(c *gin.Context) {
 		c.JSON(http.StatusInternalServerError, response.NewErrorResponse("Failed to partially update project", err.Error()))
 		return
 	}
+	h.auditService.Record(h.httpAdapter.AuditActor(c), audit.ActionUpdate, audit.EntityProject, id, before, project)
 
 	h.httpAdapter.SendSuccessResponse(c, http.StatusOK, project, "Project partially updated successfully")
 }
@@ -280,6 +345,7 @@ func (h *Handler) Delete(c *gin.Context) {
 		return
 	}
 
+	before, _ := h.service.GetProjectByID(id)
 	err := h.service.DeleteProject(id)
 	if err != nil {
 		if err.Error() == "record not found" {
@@ -289,6 +355,7 @@ func (h *Handler) Delete(c *gin.Context) {
 		c.JSON(http.StatusInternalServerError, response.NewErrorResponse("Failed to delete project", err.Error()))
 		return
 	}
+	h.auditService.Record(h.httpAdapter.AuditActor(c), audit.ActionDelete, audit.EntityProject, id, before, nil)
 
 	h.httpAdapter.SendSuccessResponse(c, http.StatusOK, nil, "Project deleted successfully")
 }
@@ -307,11 +374,13 @@ func (h *Handler) AddImage(c *gin.Context) {
 		return
 	}
 
+	before, _ := h.service.GetProjectByID(id)
 	image, err := h.service.AddProjectImage(id, imageData)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, response.NewErrorResponse("Failed to add image", err.Error()))
+		h.sendChildError(c, "Failed to add image", err)
 		return
 	}
+	h.recordUpdate(c, id, before)
 
 	h.httpAdapter.SendSuccessResponse(c, http.StatusCreated, image, "Image added successfully")
 }
@@ -330,11 +399,13 @@ func (h *Handler) AddVideo(c *gin.Context) {
 		return
 	}
 
+	before, _ := h.service.GetProjectByID(id)
 	video, err := h.service.AddProjectVideo(id, videoData)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, response.NewErrorResponse("Failed to add video", err.Error()))
+		h.sendChildError(c, "Failed to add video", err)
 		return
 	}
+	h.recordUpdate(c, id, before)
 
 	h.httpAdapter.SendSuccessResponse(c, http.StatusCreated, video, "Video added successfully")
 }
@@ -348,8 +419,12 @@ func (h *Handler) DeleteImage(c *gin.Context) {
 		return
 	}
 
-	// This method would need to be implemented in the service
-	// For now, we just show a success message
+	before, _ := h.service.GetProjectByID(id)
+	if err := h.service.DeleteProjectImage(id, imageId); err != nil {
+		h.sendChildError(c, "Failed to delete image", err)
+		return
+	}
+	h.recordUpdate(c, id, before)
 	h.httpAdapter.SendSuccessResponse(c, http.StatusOK, nil, "Image deleted successfully")
 }
 
@@ -362,8 +437,12 @@ func (h *Handler) DeleteVideo(c *gin.Context) {
 		return
 	}
 
-	// This method would need to be implemented in the service
-	// For now, we just show a success message
+	before, _ := h.service.GetProjectByID(id)
+	if err := h.service.DeleteProjectVideo(id, videoId); err != nil {
+		h.sendChildError(c, "Failed to delete video", err)
+		return
+	}
+	h.recordUpdate(c, id, before)
 	h.httpAdapter.SendSuccessResponse(c, http.StatusOK, nil, "Video deleted successfully")
 }
 
@@ -383,8 +462,12 @@ func (h *Handler) AddTechnology(c *gin.Context) {
 		return
 	}
 
-	// This method would need to be implemented in the service
-	// For now, we just show a success message
+	before, _ := h.service.GetProjectByID(id)
+	if err := h.service.AddProjectTechnology(id, techData.Name); err != nil {
+		h.sendChildError(c, "Failed to add technology", err)
+		return
+	}
+	h.recordUpdate(c, id, before)
 	h.httpAdapter.SendSuccessResponse(c, http.StatusCreated, techData, "Technology added successfully")
 }
 
@@ -397,7 +480,27 @@ func (h *Handler) RemoveTechnology(c *gin.Context) {
 		return
 	}
 
-	// This method would need to be implemented in the service
-	// For now, we just show a success message
+	before, _ := h.service.GetProjectByID(id)
+	if err := h.service.RemoveProjectTechnology(id, techId); err != nil {
+		h.sendChildError(c, "Failed to remove technology", err)
+		return
+	}
+	h.recordUpdate(c, id, before)
+
 	h.httpAdapter.SendSuccessResponse(c, http.StatusOK, nil, "Technology removed successfully")
 }
+
+// recordUpdate audits a change to the images, videos or technologies of a
+// project as an update of the project
+func (h *Handler) recordUpdate(c *gin.Context, id string, before *dto.ProjectResponse) {
+	after, _ := h.service.GetProjectByID(id)
+	h.auditService.Record(h.httpAdapter.AuditActor(c), audit.ActionUpdate, audit.EntityProject, id, before, after)
+}
+
+func (h *Handler) sendChildError(c *gin.Context, message string, err error) {
+	if err.Error() == "record not found" {
+		c.JSON(http.StatusNotFound, response.NewErrorResponse(message, err.Error()))
+		return
+	}
+	c.JSON(http.StatusInternalServerError, response.NewErrorResponse(message, err.Error()))
+}
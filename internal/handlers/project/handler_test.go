@@ -0,0 +1,37 @@
+package project
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	httpAdapter "web-porto-backend/internal/adapters/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestUpdatesRejectInvalidSEO(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	// No service: an invalid request must be rejected before reaching it
+	h := NewHandler(nil, nil, httpAdapter.NewHTTPAdapter())
+	r := gin.New()
+	r.PUT("/projects/:id", h.Update)
+	r.PATCH("/projects/:id", h.Patch)
+
+	for _, method := range []string{http.MethodPut, http.MethodPatch} {
+		for _, body := range []string{
+			`{"seo":{"canonicalUrl":"javascript:alert(1)"}}`,
+			`{"seo":{"ogImage":"//evil.example/a.png"}}`,
+			`{"seo":{"twitterCard":"player"}}`,
+		} {
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest(method, "/projects/p1", strings.NewReader(body))
+			req.Header.Set("Content-Type", "application/json")
+			r.ServeHTTP(w, req)
+
+			if w.Code != http.StatusBadRequest || !strings.Contains(w.Body.String(), "Invalid SEO metadata") {
+				t.Errorf("%s %s = %d: %s", method, body, w.Code, w.Body.String())
+			}
+		}
+	}
+}
@@ -4,65 +4,103 @@ import (
 	httpAdapter "web-porto-backend/internal/adapters/http"
 	"web-porto-backend/internal/auth"
 	analyticsHandler "web-porto-backend/internal/handlers/analytics"
+	apiTokenHandler "web-porto-backend/internal/handlers/apitoken"
 	articleHandler "web-porto-backend/internal/handlers/article"
+	auditHandler "web-porto-backend/internal/handlers/audit"
 	authHandler "web-porto-backend/internal/handlers/auth"
 	categoryHandler "web-porto-backend/internal/handlers/category"
 	commentHandler "web-porto-backend/internal/handlers/comment"
 	experienceHandler "web-porto-backend/internal/handlers/experience"
+	feedHandler "web-porto-backend/internal/handlers/feed"
+	invitationHandler "web-porto-backend/internal/handlers/invitation"
+	loginAttemptHandler "web-porto-backend/internal/handlers/loginattempt"
 	mediaHandler "web-porto-backend/internal/handlers/media"
 	pageHandler "web-porto-backend/internal/handlers/page"
 	postHandler "web-porto-backend/internal/handlers/post"
 	projectHandler "web-porto-backend/internal/handlers/project"
+	revisionHandler "web-porto-backend/internal/handlers/revision"
+	roleHandler "web-porto-backend/internal/handlers/role"
+	scheduleHandler "web-porto-backend/internal/handlers/schedule"
+	searchHandler "web-porto-backend/internal/handlers/search"
+	seoHandler "web-porto-backend/internal/handlers/seo"
 	settingHandler "web-porto-backend/internal/handlers/setting"
+	sitemapHandler "web-porto-backend/internal/handlers/sitemap"
 	tagHandler "web-porto-backend/internal/handlers/tag"
 	userHandler "web-porto-backend/internal/handlers/user"
 	"web-porto-backend/internal/services"
+	"web-porto-backend/internal/services/audit"
 
 	"gorm.io/gorm"
 )
 
 type HandlerRegistry struct {
-	AnalyticsHandler  *analyticsHandler.Handler
-	ArticleHandler    *articleHandler.Handler
-	CategoryHandler   *categoryHandler.Handler
-	CommentHandler    *commentHandler.Handler
-	AuthHandler       *authHandler.Handler
-	ExperienceHandler *experienceHandler.Handler
-	MediaHandler      *mediaHandler.Handler
-	PostHandler       *postHandler.Handler
-	PageHandler       *pageHandler.Handler
-	ProjectHandler    *projectHandler.Handler
-	SettingHandler    *settingHandler.Handler
-	TagHandler        *tagHandler.Handler
-	UserHandler       *userHandler.Handler
+	APITokenHandler        *apiTokenHandler.Handler
+	AnalyticsHandler       *analyticsHandler.Handler
+	ArticleHandler         *articleHandler.Handler
+	ArticleRevisionHandler *revisionHandler.Handler
+	CategoryHandler        *categoryHandler.Handler
+	CommentHandler         *commentHandler.Handler
+	AuditHandler           *auditHandler.Handler
+	AuthHandler            *authHandler.Handler
+	ExperienceHandler      *experienceHandler.Handler
+	FeedHandler            *feedHandler.Handler
+	InvitationHandler      *invitationHandler.Handler
+	LoginAttemptHandler    *loginAttemptHandler.Handler
+	MediaHandler           *mediaHandler.Handler
+	PostHandler            *postHandler.Handler
+	PageHandler            *pageHandler.Handler
+	PageRevisionHandler    *revisionHandler.Handler
+	ProjectHandler         *projectHandler.Handler
+	ProjectRevisionHandler *revisionHandler.Handler
+	RoleHandler            *roleHandler.Handler
+	ScheduleHandler        *scheduleHandler.Handler
+	SearchHandler          *searchHandler.Handler
+	SEOHandler             *seoHandler.Handler
+	SettingHandler         *settingHandler.Handler
+	SitemapHandler         *sitemapHandler.Handler
+	TagHandler             *tagHandler.Handler
+	UserHandler            *userHandler.Handler
 }
 
 func NewHandlerRegistry(svc *services.ServiceRegistry, authService *auth.AuthService, httpAdapter *httpAdapter.HTTPAdapter) *HandlerRegistry {
-	return NewHandlerRegistryWithDB(svc, authService, httpAdapter, nil, "", "")
+	return NewHandlerRegistryWithDB(svc, authService, httpAdapter, nil, "")
 }
 
-func NewHandlerRegistryWithDB(svc *services.ServiceRegistry, authService *auth.AuthService, httpAdapter *httpAdapter.HTTPAdapter, db *gorm.DB, uploadDir string, baseURL string) *HandlerRegistry {
+func NewHandlerRegistryWithDB(svc *services.ServiceRegistry, authService *auth.AuthService, httpAdapter *httpAdapter.HTTPAdapter, db *gorm.DB, baseURL string) *HandlerRegistry {
 	// Create adapter from article service to post service for backward compatibility
 	postServiceAdapter := postHandler.NewPostServiceAdapter(svc.ArticleService)
 
 	var mHandler *mediaHandler.Handler
 	if db != nil {
-		mHandler = mediaHandler.NewHandler(db, uploadDir, baseURL)
+		mHandler = mediaHandler.NewHandler(db, svc.MediaService, svc.AuditService, httpAdapter)
 	}
 
 	return &HandlerRegistry{
-		AnalyticsHandler:  analyticsHandler.NewHandler(svc.AnalyticsService, httpAdapter),
-		ArticleHandler:    articleHandler.NewHandler(svc.ArticleService, httpAdapter),
-		CategoryHandler:   categoryHandler.NewHandler(svc.CategoryService), // Using existing constructor
-		CommentHandler:    commentHandler.NewHandler(svc.CommentService),   // Using existing constructor
-		AuthHandler:       authHandler.NewHandler(svc.UserService, authService, httpAdapter),
-		ExperienceHandler: experienceHandler.NewHandler(svc.ExperienceService, httpAdapter),
-		MediaHandler:      mHandler,
-		PostHandler:       postHandler.NewHandler(postServiceAdapter, httpAdapter),
-		PageHandler:       pageHandler.NewHandler(svc.PageService, httpAdapter),
-		ProjectHandler:    projectHandler.NewHandler(svc.ProjectService, httpAdapter),
-		SettingHandler:    settingHandler.NewHandler(svc.SettingService),
-		TagHandler:        tagHandler.NewHandler(svc.TagService, httpAdapter),
-		UserHandler:       userHandler.NewHandler(svc.UserService, httpAdapter),
+		APITokenHandler:        apiTokenHandler.NewHandler(svc.APITokenService, httpAdapter),
+		AnalyticsHandler:       analyticsHandler.NewHandler(svc.AnalyticsService, httpAdapter),
+		ArticleHandler:         articleHandler.NewHandler(svc.ArticleService, svc.AuditService, httpAdapter),
+		ArticleRevisionHandler: revisionHandler.NewHandler(svc.ArticleRevisionService, svc.ArticleService, audit.EntityArticle, svc.AuditService, httpAdapter),
+		CategoryHandler:        categoryHandler.NewHandler(svc.CategoryService, svc.AuditService), // Using existing constructor
+		CommentHandler:         commentHandler.NewHandler(svc.CommentService),                     // Using existing constructor
+		AuditHandler:           auditHandler.NewHandler(svc.AuditService, httpAdapter),
+		AuthHandler:            authHandler.NewHandler(svc.AccountService, svc.UserService, svc.TokenService, svc.RoleService, svc.InvitationService, svc.LoginAttemptService, svc.TwoFactorService, authService, httpAdapter),
+		ExperienceHandler:      experienceHandler.NewHandler(svc.ExperienceService, svc.AuditService, httpAdapter),
+		FeedHandler:            feedHandler.NewHandler(svc.FeedService, baseURL),
+		InvitationHandler:      invitationHandler.NewHandler(svc.InvitationService, httpAdapter),
+		LoginAttemptHandler:    loginAttemptHandler.NewHandler(svc.LoginAttemptService, httpAdapter),
+		MediaHandler:           mHandler,
+		PostHandler:            postHandler.NewHandler(postServiceAdapter, svc.AuditService, httpAdapter),
+		PageHandler:            pageHandler.NewHandler(svc.PageService, svc.AuditService, httpAdapter),
+		PageRevisionHandler:    revisionHandler.NewHandler(svc.PageRevisionService, svc.PageService, audit.EntityPage, svc.AuditService, httpAdapter),
+		ProjectHandler:         projectHandler.NewHandler(svc.ProjectService, svc.AuditService, httpAdapter),
+		ProjectRevisionHandler: revisionHandler.NewHandler(svc.ProjectRevisionService, svc.ProjectService, audit.EntityProject, svc.AuditService, httpAdapter),
+		RoleHandler:            roleHandler.NewHandler(svc.RoleService, httpAdapter),
+		ScheduleHandler:        scheduleHandler.NewHandler(svc.ScheduleService, httpAdapter),
+		SearchHandler:          searchHandler.NewHandler(svc.SearchService, httpAdapter),
+		SEOHandler:             seoHandler.NewHandler(svc.SEOService, httpAdapter),
+		SettingHandler:         settingHandler.NewHandler(svc.SettingService, svc.AuditService, httpAdapter),
+		SitemapHandler:         sitemapHandler.NewHandler(svc.SitemapService, baseURL),
+		TagHandler:             tagHandler.NewHandler(svc.TagService, svc.AuditService, httpAdapter),
+		UserHandler:            userHandler.NewHandler(svc.UserService, svc.RoleService, svc.TokenService, svc.TwoFactorService, svc.AccountService, httpAdapter),
 	}
 }
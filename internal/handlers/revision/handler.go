@@ -0,0 +1,182 @@
+package revision
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"web-porto-backend/common/response"
+	httpAdapter "web-porto-backend/internal/adapters/http"
+	"web-porto-backend/internal/domain/dto"
+	"web-porto-backend/internal/domain/models"
+	"web-porto-backend/internal/services/audit"
+	"web-porto-backend/internal/services/revision"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// Handler serves the revision history of one content type. The same handler
+// is instantiated for articles, projects and pages; routes are expected to
+// carry the item in :id and the revision in :revisionId.
+type Handler struct {
+	service      revision.Service
+	content      revision.Restorer
+	entityType   string
+	auditService audit.Service
+	httpAdapter  *httpAdapter.HTTPAdapter
+}
+
+// NewHandler creates a revision handler. entityType is the audit entity the
+// revisions belong to, e.g. audit.EntityArticle.
+func NewHandler(service revision.Service, content revision.Restorer, entityType string, auditService audit.Service, httpAdapter *httpAdapter.HTTPAdapter) *Handler {
+	return &Handler{
+		service:      service,
+		content:      content,
+		entityType:   entityType,
+		auditService: auditService,
+		httpAdapter:  httpAdapter,
+	}
+}
+
+// GetAll lists the revisions of an item, newest first
+func (h *Handler) GetAll(c *gin.Context) {
+	id := c.Param("id")
+	pagination := h.httpAdapter.GetPaginationFromQuery(c)
+
+	revisions, total, err := h.service.List(id, pagination.Page, pagination.Limit)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, response.NewErrorResponse("Failed to get revisions", err.Error()))
+		return
+	}
+
+	result := make([]dto.RevisionResponse, 0, len(revisions))
+	for i := range revisions {
+		result = append(result, toRevisionResponse(&revisions[i], false))
+	}
+	h.httpAdapter.SendPaginatedResponse(c, result, pagination.Page, pagination.Limit, total, "Revisions retrieved successfully")
+}
+
+// GetByID returns a single revision including its stored snapshot
+func (h *Handler) GetByID(c *gin.Context) {
+	rev, ok := h.loadRevision(c, "revisionId")
+	if !ok {
+		return
+	}
+
+	h.httpAdapter.SendSuccessResponse(c, http.StatusOK, toRevisionResponse(rev, true), "Revision retrieved successfully")
+}
+
+// Diff returns a unified diff from a revision to ?to=<revisionId>, or to the
+// current version of the item when ?to is omitted or "current"
+func (h *Handler) Diff(c *gin.Context) {
+	from, ok := h.loadRevision(c, "revisionId")
+	if !ok {
+		return
+	}
+	fromLabel := versionLabel(from)
+
+	var to interface{}
+	toLabel := "current"
+	if v := c.Query("to"); v != "" && v != "current" {
+		toID, err := strconv.Atoi(v)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, response.NewErrorResponse("Invalid revision ID", err.Error()))
+			return
+		}
+		rev, err := h.service.Get(c.Param("id"), toID)
+		if err != nil {
+			h.sendRevisionError(c, err)
+			return
+		}
+		to = json.RawMessage(rev.Snapshot)
+		toLabel = versionLabel(rev)
+	} else {
+		state, err := h.content.RevisionState(c.Param("id"))
+		if err != nil {
+			h.sendRevisionError(c, err)
+			return
+		}
+		to = state
+	}
+
+	diff, err := h.service.Diff(fromLabel, json.RawMessage(from.Snapshot), toLabel, to)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, response.NewErrorResponse("Failed to diff revisions", err.Error()))
+		return
+	}
+
+	h.httpAdapter.SendSuccessResponse(c, http.StatusOK, dto.RevisionDiffResponse{
+		From: fromLabel,
+		To:   toLabel,
+		Diff: diff,
+	}, "Revision diff generated successfully")
+}
+
+// Restore applies a revision as a new update. The current state is kept as
+// a revision of its own, so a restore can itself be undone.
+func (h *Handler) Restore(c *gin.Context) {
+	rev, ok := h.loadRevision(c, "revisionId")
+	if !ok {
+		return
+	}
+	id := c.Param("id")
+
+	before, _ := h.content.RevisionState(id)
+	item, err := h.content.RestoreRevision(id, rev, c.GetInt("user_id"))
+	if err != nil {
+		h.sendRevisionError(c, err)
+		return
+	}
+	after, _ := h.content.RevisionState(id)
+	h.auditService.Record(h.httpAdapter.AuditActor(c), audit.ActionUpdate, h.entityType, id, before, after)
+
+	h.httpAdapter.SendSuccessResponse(c, http.StatusOK, item, fmt.Sprintf("Restored %s", versionLabel(rev)))
+}
+
+func (h *Handler) loadRevision(c *gin.Context, param string) (*models.Revision, bool) {
+	revisionID, err := h.httpAdapter.ParseIntIDParam(c, param)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, response.NewErrorResponse("Invalid revision ID", err.Error()))
+		return nil, false
+	}
+
+	rev, err := h.service.Get(c.Param("id"), revisionID)
+	if err != nil {
+		h.sendRevisionError(c, err)
+		return nil, false
+	}
+	return rev, true
+}
+
+func (h *Handler) sendRevisionError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, revision.ErrRevisionNotFound):
+		c.JSON(http.StatusNotFound, response.NewErrorResponse("Revision not found", err.Error()))
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		c.JSON(http.StatusNotFound, response.NewErrorResponse("Item not found", err.Error()))
+	default:
+		c.JSON(http.StatusInternalServerError, response.NewErrorResponse("Failed to process revision", err.Error()))
+	}
+}
+
+func versionLabel(rev *models.Revision) string {
+	return "version " + strconv.Itoa(rev.Version)
+}
+
+func toRevisionResponse(rev *models.Revision, withSnapshot bool) dto.RevisionResponse {
+	resp := dto.RevisionResponse{
+		ID:        rev.ID,
+		EntityID:  rev.EntityID,
+		Version:   rev.Version,
+		Title:     rev.Title,
+		UserID:    rev.UserID,
+		Summary:   rev.Summary,
+		CreatedAt: rev.CreatedAt,
+	}
+	if withSnapshot {
+		resp.Snapshot = json.RawMessage(rev.Snapshot)
+	}
+	return resp
+}
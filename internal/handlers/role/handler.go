@@ -0,0 +1,235 @@
+package role
+
+import (
+	"errors"
+	"net/http"
+	"web-porto-backend/common/response"
+	httpAdapter "web-porto-backend/internal/adapters/http"
+	"web-porto-backend/internal/auth"
+	"web-porto-backend/internal/domain/dto"
+	"web-porto-backend/internal/domain/models"
+	"web-porto-backend/internal/services/role"
+
+	"github.com/gin-gonic/gin"
+)
+
+const msgNotGrantable = "You cannot grant permissions you do not hold"
+
+// Handler handles HTTP requests for role and permission management
+type Handler struct {
+	service     role.Service
+	httpAdapter *httpAdapter.HTTPAdapter
+}
+
+// NewHandler creates a new role handler
+func NewHandler(service role.Service, httpAdapter *httpAdapter.HTTPAdapter) *Handler {
+	return &Handler{
+		service:     service,
+		httpAdapter: httpAdapter,
+	}
+}
+
+// GetPermissions lists every permission that can be granted to a role
+func (h *Handler) GetPermissions(c *gin.Context) {
+	h.httpAdapter.SendSuccessResponse(c, http.StatusOK, auth.AllPermissions, "Permissions retrieved successfully")
+}
+
+// GetAll lists roles with their permissions
+func (h *Handler) GetAll(c *gin.Context) {
+	roles, err := h.service.GetAll()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, response.NewErrorResponse("Failed to retrieve roles", err.Error()))
+		return
+	}
+
+	result := make([]dto.RoleResponse, 0, len(roles))
+	for i := range roles {
+		result = append(result, toRoleResponse(&roles[i]))
+	}
+	h.httpAdapter.SendSuccessResponse(c, http.StatusOK, result, "Roles retrieved successfully")
+}
+
+// GetByID gets a role by ID
+func (h *Handler) GetByID(c *gin.Context) {
+	id, err := h.httpAdapter.ParseIntIDParam(c, "id")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, response.NewErrorResponse("Invalid role ID", err.Error()))
+		return
+	}
+
+	r, err := h.service.GetByID(id)
+	if err != nil {
+		h.sendServiceError(c, "Failed to retrieve role", err)
+		return
+	}
+	h.httpAdapter.SendSuccessResponse(c, http.StatusOK, toRoleResponse(r), "Role retrieved successfully")
+}
+
+// Create creates a new role
+func (h *Handler) Create(c *gin.Context) {
+	var req dto.CreateRoleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, response.NewErrorResponse("Invalid role data", err.Error()))
+		return
+	}
+	if !h.checkGrantable(c, req.Permissions) {
+		return
+	}
+
+	r, err := h.service.Create(req.Name, req.Description, req.Permissions)
+	if err != nil {
+		h.sendServiceError(c, "Failed to create role", err)
+		return
+	}
+	h.httpAdapter.SendSuccessResponse(c, http.StatusCreated, toRoleResponse(r), "Role created successfully")
+}
+
+// Update updates a role and optionally replaces its permissions. The caller
+// must hold the role's permissions, and any new ones, as editing the role
+// changes what everyone assigned to it may do.
+func (h *Handler) Update(c *gin.Context) {
+	id, err := h.httpAdapter.ParseIntIDParam(c, "id")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, response.NewErrorResponse("Invalid role ID", err.Error()))
+		return
+	}
+
+	var req dto.UpdateRoleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, response.NewErrorResponse("Invalid role data", err.Error()))
+		return
+	}
+	current, err := h.service.GetByID(id)
+	if err != nil {
+		h.sendServiceError(c, "Failed to update role", err)
+		return
+	}
+	if !h.checkGrantable(c, append(current.PermissionNames(), req.Permissions...)) {
+		return
+	}
+
+	r, err := h.service.Update(id, req.Name, req.Description, req.Permissions)
+	if err != nil {
+		h.sendServiceError(c, "Failed to update role", err)
+		return
+	}
+	h.httpAdapter.SendSuccessResponse(c, http.StatusOK, toRoleResponse(r), "Role updated successfully")
+}
+
+// Delete deletes a non-system role
+func (h *Handler) Delete(c *gin.Context) {
+	id, err := h.httpAdapter.ParseIntIDParam(c, "id")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, response.NewErrorResponse("Invalid role ID", err.Error()))
+		return
+	}
+
+	if err := h.service.Delete(id); err != nil {
+		h.sendServiceError(c, "Failed to delete role", err)
+		return
+	}
+	h.httpAdapter.SendSuccessResponse(c, http.StatusOK, nil, "Role deleted successfully")
+}
+
+// GetUsers lists IDs of users assigned to a role
+func (h *Handler) GetUsers(c *gin.Context) {
+	id, err := h.httpAdapter.ParseIntIDParam(c, "id")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, response.NewErrorResponse("Invalid role ID", err.Error()))
+		return
+	}
+
+	userIDs, err := h.service.GetUsers(id)
+	if err != nil {
+		h.sendServiceError(c, "Failed to retrieve role assignments", err)
+		return
+	}
+	h.httpAdapter.SendSuccessResponse(c, http.StatusOK, gin.H{"userIds": userIDs}, "Role assignments retrieved successfully")
+}
+
+// AssignUser assigns a role to a user; the caller must hold every permission
+// of the role
+func (h *Handler) AssignUser(c *gin.Context) {
+	id, err := h.httpAdapter.ParseIntIDParam(c, "id")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, response.NewErrorResponse("Invalid role ID", err.Error()))
+		return
+	}
+
+	var req dto.AssignRoleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, response.NewErrorResponse("Invalid assignment data", err.Error()))
+		return
+	}
+	r, err := h.service.GetByID(id)
+	if err != nil {
+		h.sendServiceError(c, "Failed to assign role", err)
+		return
+	}
+	if !h.checkGrantable(c, r.PermissionNames()) {
+		return
+	}
+
+	if err := h.service.AssignToUser(id, req.UserID); err != nil {
+		h.sendServiceError(c, "Failed to assign role", err)
+		return
+	}
+	h.httpAdapter.SendSuccessResponse(c, http.StatusOK, nil, "Role assigned successfully")
+}
+
+// RemoveUser removes a role assignment from a user
+func (h *Handler) RemoveUser(c *gin.Context) {
+	id, err := h.httpAdapter.ParseIntIDParam(c, "id")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, response.NewErrorResponse("Invalid role ID", err.Error()))
+		return
+	}
+	userID, err := h.httpAdapter.ParseIntIDParam(c, "userId")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, response.NewErrorResponse("Invalid user ID", err.Error()))
+		return
+	}
+
+	if err := h.service.RemoveFromUser(id, userID); err != nil {
+		h.sendServiceError(c, "Failed to remove role", err)
+		return
+	}
+	h.httpAdapter.SendSuccessResponse(c, http.StatusOK, nil, "Role removed successfully")
+}
+
+// checkGrantable answers 403 unless the caller holds every permission in
+// permissions, so roles cannot be used to escalate privileges
+func (h *Handler) checkGrantable(c *gin.Context, permissions []string) bool {
+	if !auth.CanGrant(c.GetStringSlice("permissions"), permissions) {
+		c.JSON(http.StatusForbidden, response.NewErrorResponse(msgNotGrantable))
+		return false
+	}
+	return true
+}
+
+func (h *Handler) sendServiceError(c *gin.Context, message string, err error) {
+	switch {
+	case errors.Is(err, role.ErrRoleNotFound), errors.Is(err, role.ErrUserNotFound):
+		c.JSON(http.StatusNotFound, response.NewErrorResponse(message, err.Error()))
+	case errors.Is(err, role.ErrRoleExists):
+		c.JSON(http.StatusConflict, response.NewErrorResponse(message, err.Error()))
+	case errors.Is(err, role.ErrSystemRole):
+		c.JSON(http.StatusForbidden, response.NewErrorResponse(message, err.Error()))
+	case errors.Is(err, role.ErrInvalidPermission), errors.Is(err, role.ErrRoleNameRequired):
+		c.JSON(http.StatusBadRequest, response.NewErrorResponse(message, err.Error()))
+	default:
+		c.JSON(http.StatusInternalServerError, response.NewErrorResponse(message, err.Error()))
+	}
+}
+
+func toRoleResponse(r *models.Role) dto.RoleResponse {
+	return dto.RoleResponse{
+		ID:          r.ID,
+		Name:        r.Name,
+		Description: r.Description,
+		IsSystem:    r.IsSystem,
+		Permissions: r.PermissionNames(),
+		CreatedAt:   r.CreatedAt,
+		UpdatedAt:   r.UpdatedAt,
+	}
+}
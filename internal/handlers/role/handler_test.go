@@ -0,0 +1,132 @@
+package role
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	httpAdapter "web-porto-backend/internal/adapters/http"
+	"web-porto-backend/internal/auth"
+	"web-porto-backend/internal/domain/models"
+	roleRepo "web-porto-backend/internal/repositories/role"
+	userRepo "web-porto-backend/internal/repositories/user"
+	"web-porto-backend/internal/services/role"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// fakeRoleRepo holds the system admin and user roles and an editor role
+type fakeRoleRepo struct {
+	roleRepo.Repository
+	roles   map[int]*models.Role
+	changed bool
+}
+
+func newFakeRoleRepo() *fakeRoleRepo {
+	role := func(id int, name string, system bool, perms ...string) *models.Role {
+		r := &models.Role{ID: id, Name: name, IsSystem: system}
+		for _, p := range perms {
+			r.Permissions = append(r.Permissions, models.RolePermission{RoleID: id, Permission: p})
+		}
+		return r
+	}
+	return &fakeRoleRepo{roles: map[int]*models.Role{
+		1: role(1, "admin", true, auth.PermissionAll),
+		2: role(2, "user", true),
+		3: role(3, "editor", false, auth.PermArticleWrite),
+	}}
+}
+
+func (r *fakeRoleRepo) FindByID(id int) (*models.Role, error) {
+	if role, ok := r.roles[id]; ok {
+		copied := *role
+		return &copied, nil
+	}
+	return nil, gorm.ErrRecordNotFound
+}
+
+func (r *fakeRoleRepo) FindByName(name string) (*models.Role, error) {
+	for _, role := range r.roles {
+		if role.Name == name {
+			return role, nil
+		}
+	}
+	return nil, gorm.ErrRecordNotFound
+}
+
+func (r *fakeRoleRepo) Create(*models.Role) error {
+	r.changed = true
+	return nil
+}
+
+func (r *fakeRoleRepo) Update(*models.Role, []string) error {
+	r.changed = true
+	return nil
+}
+
+func (r *fakeRoleRepo) AssignToUser(int, int) error {
+	r.changed = true
+	return nil
+}
+
+type fakeUserRepo struct {
+	userRepo.Repository
+}
+
+func (fakeUserRepo) FindByID(id int) (*models.User, error) {
+	return &models.User{ID: id}, nil
+}
+
+func TestRolesCannotEscalatePrivileges(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	manager := []string{auth.PermRolesManage, auth.PermArticleWrite}
+	admin := []string{auth.PermissionAll}
+	tests := []struct {
+		name        string
+		method      string
+		path        string
+		body        string
+		permissions []string
+		want        int
+	}{
+		{"create role with wildcard", http.MethodPost, "/roles", `{"name":"root","permissions":["*"]}`, manager, http.StatusForbidden},
+		{"create role with unheld permission", http.MethodPost, "/roles", `{"name":"mod","permissions":["users:manage"]}`, manager, http.StatusForbidden},
+		{"create role with held permissions", http.MethodPost, "/roles", `{"name":"writer","permissions":["article:write"]}`, manager, http.StatusCreated},
+		{"add unheld permission", http.MethodPut, "/roles/3", `{"permissions":["article:write","roles:manage","users:manage"]}`, manager, http.StatusForbidden},
+		{"edit role beyond own permissions", http.MethodPut, "/roles/1", `{"description":"mine now"}`, manager, http.StatusForbidden},
+		{"edit held role", http.MethodPut, "/roles/3", `{"description":"Writes articles"}`, manager, http.StatusOK},
+		{"change system role permissions", http.MethodPut, "/roles/2", `{"permissions":["article:write"]}`, admin, http.StatusForbidden},
+		{"clear admin permissions", http.MethodPut, "/roles/1", `{"permissions":[]}`, admin, http.StatusForbidden},
+		{"resend system role permissions", http.MethodPut, "/roles/1", `{"description":"Everything","permissions":["*"]}`, admin, http.StatusOK},
+		{"assign admin role", http.MethodPost, "/roles/1/users", `{"userId":5}`, manager, http.StatusForbidden},
+		{"assign held role", http.MethodPost, "/roles/3/users", `{"userId":5}`, manager, http.StatusOK},
+		{"assign unknown role", http.MethodPost, "/roles/9/users", `{"userId":5}`, manager, http.StatusNotFound},
+		{"admin assigns admin role", http.MethodPost, "/roles/1/users", `{"userId":5}`, admin, http.StatusOK},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := newFakeRoleRepo()
+			h := NewHandler(role.NewService(repo, fakeUserRepo{}), httpAdapter.NewHTTPAdapter())
+			r := gin.New()
+			r.Use(func(c *gin.Context) {
+				c.Set("permissions", tt.permissions)
+			})
+			r.POST("/roles", h.Create)
+			r.PUT("/roles/:id", h.Update)
+			r.POST("/roles/:id/users", h.AssignUser)
+
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest(tt.method, tt.path, strings.NewReader(tt.body))
+			req.Header.Set("Content-Type", "application/json")
+			r.ServeHTTP(w, req)
+
+			if w.Code != tt.want {
+				t.Fatalf("status = %d, want %d: %s", w.Code, tt.want, w.Body.String())
+			}
+			if repo.changed != (tt.want < 300) {
+				t.Fatalf("roles changed = %v", repo.changed)
+			}
+		})
+	}
+}
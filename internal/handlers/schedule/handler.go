@@ -0,0 +1,135 @@
+package schedule
+
+import (
+	"errors"
+	"net/http"
+	"sort"
+	"web-porto-backend/common/response"
+	httpAdapter "web-porto-backend/internal/adapters/http"
+	"web-porto-backend/internal/auth"
+	"web-porto-backend/internal/domain/dto"
+	"web-porto-backend/internal/domain/models"
+	"web-porto-backend/internal/services/audit"
+	"web-porto-backend/internal/services/schedule"
+
+	"github.com/gin-gonic/gin"
+)
+
+// publishPermissions maps schedulable content to the permission needed to
+// see and cancel its jobs
+var publishPermissions = map[string]string{
+	audit.EntityArticle: auth.PermArticlePublish,
+	audit.EntityProject: auth.PermProjectPublish,
+	audit.EntityPage:    auth.PermPagePublish,
+}
+
+// Handler exposes scheduled publish/unpublish jobs
+type Handler struct {
+	service     schedule.Service
+	httpAdapter *httpAdapter.HTTPAdapter
+}
+
+// NewHandler creates a new schedule handler
+func NewHandler(service schedule.Service, httpAdapter *httpAdapter.HTTPAdapter) *Handler {
+	return &Handler{
+		service:     service,
+		httpAdapter: httpAdapter,
+	}
+}
+
+// GetAll lists scheduled jobs, soonest first. Filters: ?entityType=,
+// ?entityId=, ?action= and ?status= (default "pending"; "all" for any).
+// Only jobs of content the caller may publish are listed.
+func (h *Handler) GetAll(c *gin.Context) {
+	pagination := h.httpAdapter.GetPaginationFromQuery(c)
+
+	filter := schedule.JobFilter{
+		EntityType: c.Query("entityType"),
+		EntityID:   c.Query("entityId"),
+		Action:     c.Query("action"),
+		Status:     c.DefaultQuery("status", models.JobStatusPending),
+	}
+	if filter.Status == "all" {
+		filter.Status = ""
+	}
+	filter.EntityTypes = []string{}
+	for entityType, permission := range publishPermissions {
+		if h.httpAdapter.HasPermission(c, permission) {
+			filter.EntityTypes = append(filter.EntityTypes, entityType)
+		}
+	}
+	if len(filter.EntityTypes) == 0 {
+		c.JSON(http.StatusForbidden, response.NewErrorResponse("Insufficient permissions", "missing publish permission"))
+		return
+	}
+	sort.Strings(filter.EntityTypes)
+	if permission, ok := publishPermissions[filter.EntityType]; ok && !h.httpAdapter.HasPermission(c, permission) {
+		c.JSON(http.StatusForbidden, response.NewErrorResponse("Insufficient permissions", "missing permission "+permission))
+		return
+	}
+
+	jobs, total, err := h.service.List(filter, pagination.Page, pagination.Limit)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, response.NewErrorResponse("Failed to get scheduled jobs", err.Error()))
+		return
+	}
+
+	result := make([]dto.ScheduledJobResponse, 0, len(jobs))
+	for i := range jobs {
+		result = append(result, toScheduledJobResponse(&jobs[i]))
+	}
+	h.httpAdapter.SendPaginatedResponse(c, result, pagination.Page, pagination.Limit, total, "Scheduled jobs retrieved successfully")
+}
+
+// Cancel cancels a pending job. Requires the publish permission of the
+// job's content type.
+func (h *Handler) Cancel(c *gin.Context) {
+	id, err := h.httpAdapter.ParseIntIDParam(c, "id")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, response.NewErrorResponse("Invalid job ID", err.Error()))
+		return
+	}
+
+	job, err := h.service.GetByID(id)
+	if err != nil {
+		h.sendJobError(c, err)
+		return
+	}
+	if permission, ok := publishPermissions[job.EntityType]; ok && !h.httpAdapter.HasPermission(c, permission) {
+		c.JSON(http.StatusForbidden, response.NewErrorResponse("Insufficient permissions", "missing permission "+permission))
+		return
+	}
+
+	if err := h.service.CancelByID(id); err != nil {
+		h.sendJobError(c, err)
+		return
+	}
+	h.httpAdapter.SendSuccessResponse(c, http.StatusOK, nil, "Scheduled job cancelled successfully")
+}
+
+func (h *Handler) sendJobError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, schedule.ErrJobNotFound):
+		c.JSON(http.StatusNotFound, response.NewErrorResponse("Scheduled job not found", err.Error()))
+	case errors.Is(err, schedule.ErrJobNotPending):
+		c.JSON(http.StatusConflict, response.NewErrorResponse("Scheduled job is no longer pending", err.Error()))
+	default:
+		c.JSON(http.StatusInternalServerError, response.NewErrorResponse("Failed to cancel scheduled job", err.Error()))
+	}
+}
+
+func toScheduledJobResponse(job *models.ScheduledJob) dto.ScheduledJobResponse {
+	return dto.ScheduledJobResponse{
+		ID:         job.ID,
+		EntityType: job.EntityType,
+		EntityID:   job.EntityID,
+		Action:     job.Action,
+		RunAt:      job.RunAt,
+		Status:     job.Status,
+		Attempts:   job.Attempts,
+		LastError:  job.LastError,
+		ExecutedAt: job.ExecutedAt,
+		CreatedBy:  job.CreatedBy,
+		CreatedAt:  job.CreatedAt,
+	}
+}
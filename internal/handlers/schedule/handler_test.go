@@ -0,0 +1,70 @@
+package schedule
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	httpAdapter "web-porto-backend/internal/adapters/http"
+	"web-porto-backend/internal/auth"
+	"web-porto-backend/internal/domain/models"
+	"web-porto-backend/internal/services/schedule"
+
+	"github.com/gin-gonic/gin"
+)
+
+// fakeService records the filter of the last listing
+type fakeService struct {
+	schedule.Service
+	filter *schedule.JobFilter
+}
+
+func (s *fakeService) List(filter schedule.JobFilter, _, _ int) ([]models.ScheduledJob, int64, error) {
+	s.filter = &filter
+	return nil, 0, nil
+}
+
+func TestGetAllListsOnlyPublishableContent(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	tests := []struct {
+		name        string
+		query       string
+		permissions []string
+		want        int
+		types       string
+	}{
+		{"no publish permission", "", []string{auth.PermArticleWrite}, http.StatusForbidden, ""},
+		{"article publisher", "", []string{auth.PermArticlePublish}, http.StatusOK, "article"},
+		{"article and page publisher", "", []string{auth.PermPagePublish, auth.PermArticlePublish}, http.StatusOK, "article,page"},
+		{"admin", "", []string{auth.PermissionAll}, http.StatusOK, "article,page,project"},
+		{"filter on a publishable type", "?entityType=article", []string{auth.PermArticlePublish}, http.StatusOK, "article"},
+		{"filter on another type", "?entityType=project", []string{auth.PermArticlePublish}, http.StatusForbidden, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			service := &fakeService{}
+			h := NewHandler(service, httpAdapter.NewHTTPAdapter())
+			r := gin.New()
+			r.Use(func(c *gin.Context) {
+				c.Set("permissions", tt.permissions)
+			})
+			r.GET("/schedule", h.GetAll)
+
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/schedule"+tt.query, nil))
+
+			if w.Code != tt.want {
+				t.Fatalf("status = %d, want %d: %s", w.Code, tt.want, w.Body.String())
+			}
+			if tt.want != http.StatusOK {
+				if service.filter != nil {
+					t.Fatal("jobs were listed")
+				}
+				return
+			}
+			if got := strings.Join(service.filter.EntityTypes, ","); got != tt.types {
+				t.Fatalf("listed content types %q, want %q", got, tt.types)
+			}
+		})
+	}
+}
@@ -0,0 +1,72 @@
+package search
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+	"web-porto-backend/common/response"
+	"web-porto-backend/common/utils"
+	httpAdapter "web-porto-backend/internal/adapters/http"
+	"web-porto-backend/internal/services/search"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Handler serves full-text search over published content
+type Handler struct {
+	service     search.Service
+	httpAdapter *httpAdapter.HTTPAdapter
+}
+
+// NewHandler creates a new search handler
+func NewHandler(service search.Service, httpAdapter *httpAdapter.HTTPAdapter) *Handler {
+	return &Handler{
+		service:     service,
+		httpAdapter: httpAdapter,
+	}
+}
+
+// Search runs ?q= against articles, projects, experiences and pages.
+// Filters: ?type= (comma separated), ?category= (slug), ?tag= (slug or
+// name), ?from= and ?to= (RFC 3339 or YYYY-MM-DD)
+func (h *Handler) Search(c *gin.Context) {
+	pagination := h.httpAdapter.GetPaginationFromQuery(c)
+
+	filter := search.Filter{
+		Query:    c.Query("q"),
+		Category: c.Query("category"),
+		Tag:      c.Query("tag"),
+	}
+	if v := c.Query("type"); v != "" {
+		for _, typ := range strings.Split(v, ",") {
+			if typ = strings.TrimSpace(typ); typ != "" {
+				filter.Types = append(filter.Types, typ)
+			}
+		}
+	}
+	for param, target := range map[string]**time.Time{"from": &filter.From, "to": &filter.To} {
+		v := c.Query(param)
+		if v == "" {
+			continue
+		}
+		t, err := utils.ParseDate(v, param == "to")
+		if err != nil {
+			c.JSON(http.StatusBadRequest, response.NewErrorResponse("Invalid "+param+" date", err.Error()))
+			return
+		}
+		*target = &t
+	}
+
+	results, total, err := h.service.Search(filter, pagination.Page, pagination.Limit)
+	if err != nil {
+		if errors.Is(err, search.ErrEmptyQuery) || errors.Is(err, search.ErrQueryTooLong) || errors.Is(err, search.ErrInvalidType) {
+			c.JSON(http.StatusBadRequest, response.NewErrorResponse("Invalid search", err.Error()))
+			return
+		}
+		c.JSON(http.StatusInternalServerError, response.NewErrorResponse("Failed to search", err.Error()))
+		return
+	}
+
+	h.httpAdapter.SendPaginatedResponse(c, results, pagination.Page, pagination.Limit, total, "Search results retrieved successfully")
+}
@@ -0,0 +1,44 @@
+package seo
+
+import (
+	"errors"
+	"net/http"
+	"web-porto-backend/common/response"
+	httpAdapter "web-porto-backend/internal/adapters/http"
+	seoSrvc "web-porto-backend/internal/services/seo"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Handler serves computed head metadata for SSR frontends and link previews
+type Handler struct {
+	service     seoSrvc.Service
+	httpAdapter *httpAdapter.HTTPAdapter
+}
+
+func NewHandler(service seoSrvc.Service, httpAdapter *httpAdapter.HTTPAdapter) *Handler {
+	return &Handler{
+		service:     service,
+		httpAdapter: httpAdapter,
+	}
+}
+
+// Head returns the head tags of /seo/:type/:slug, where type is article,
+// project or page. Only published content is served.
+func (h *Handler) Head(c *gin.Context) {
+	head, err := h.service.Head(c.Param("type"), c.Param("slug"))
+	if err != nil {
+		switch {
+		case errors.Is(err, seoSrvc.ErrUnknownType):
+			c.JSON(http.StatusBadRequest, response.NewErrorResponse("Invalid content type", "type must be article, project or page"))
+		case errors.Is(err, seoSrvc.ErrNotFound):
+			c.JSON(http.StatusNotFound, response.NewErrorResponse("Content not found", err.Error()))
+		default:
+			c.JSON(http.StatusInternalServerError, response.NewErrorResponse("Failed to build SEO metadata", err.Error()))
+		}
+		return
+	}
+
+	c.Header("Cache-Control", "public, max-age=300")
+	h.httpAdapter.SendSuccessResponse(c, http.StatusOK, head, "SEO metadata retrieved successfully")
+}
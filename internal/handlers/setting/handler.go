@@ -2,18 +2,27 @@ package setting
 
 import (
 	"net/http"
+	"sort"
 	"strings"
+	httpAdapter "web-porto-backend/internal/adapters/http"
+	"web-porto-backend/internal/services/audit"
 	"web-porto-backend/internal/services/setting"
 
 	"github.com/gin-gonic/gin"
 )
 
 type Handler struct {
-	service setting.Service
+	service      setting.Service
+	auditService audit.Service
+	httpAdapter  *httpAdapter.HTTPAdapter
 }
 
-func NewHandler(service setting.Service) *Handler {
-	return &Handler{service}
+func NewHandler(service setting.Service, auditService audit.Service, httpAdapter *httpAdapter.HTTPAdapter) *Handler {
+	return &Handler{
+		service:      service,
+		auditService: auditService,
+		httpAdapter:  httpAdapter,
+	}
 }
 
 func (h *Handler) GetAll(c *gin.Context) {
@@ -45,10 +54,18 @@ func (h *Handler) Update(c *gin.Context) {
 		return
 	}
 
+	keys := make([]string, 0, len(input))
+	for key := range input {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	before, _ := h.service.GetSettings(keys)
+
 	if err := h.service.SaveSettings(input); err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to save settings"})
 		return
 	}
+	h.auditService.Record(h.httpAdapter.AuditActor(c), audit.ActionUpdate, audit.EntitySetting, strings.Join(keys, ","), before, input)
 
 	c.JSON(http.StatusOK, gin.H{"message": "Settings saved successfully", "data": input})
 }
@@ -0,0 +1,172 @@
+package sitemap
+
+import (
+	"encoding/xml"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+	applog "web-porto-backend/common/logger"
+	"web-porto-backend/common/response"
+	httpAdapter "web-porto-backend/internal/adapters/http"
+	sitemapSrvc "web-porto-backend/internal/services/sitemap"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	xmlContentType = "application/xml; charset=utf-8"
+	maxAge         = time.Hour
+)
+
+// Handler serves /sitemap.xml and /robots.txt at the root, outside /api/v1
+type Handler struct {
+	service sitemapSrvc.Service
+	baseURL string
+}
+
+func NewHandler(service sitemapSrvc.Service, baseURL string) *Handler {
+	return &Handler{
+		service: service,
+		baseURL: strings.TrimRight(baseURL, "/"),
+	}
+}
+
+// Sitemap serves the urlset, or a sitemap index once the site has more URLs
+// than one file may hold
+func (h *Handler) Sitemap(c *gin.Context) {
+	log := applog.GetLogger().WithFields(applog.Fields{"handler": "sitemap.Sitemap"})
+
+	files, err := h.service.Files()
+	if err != nil {
+		log.Error("counting sitemap entries failed", applog.Fields{"error": err.Error()})
+		c.JSON(http.StatusInternalServerError, response.NewErrorResponse("Failed to build sitemap", err.Error()))
+		return
+	}
+	if files == 1 {
+		h.sendURLSet(c, 1)
+		return
+	}
+
+	updated, err := h.service.LastModified()
+	if err != nil {
+		log.Error("reading sitemap lastmod failed", applog.Fields{"error": err.Error()})
+		c.JSON(http.StatusInternalServerError, response.NewErrorResponse("Failed to build sitemap", err.Error()))
+		return
+	}
+	index := sitemapIndex{NS: sitemapNS, Sitemaps: make([]indexEntry, 0, files)}
+	for n := 1; n <= files; n++ {
+		index.Sitemaps = append(index.Sitemaps, indexEntry{
+			Loc:     h.baseURL + "/sitemaps/" + strconv.Itoa(n) + ".xml",
+			LastMod: formatLastMod(updated),
+		})
+	}
+	h.send(c, index, updated)
+}
+
+// File serves one file of a split sitemap: /sitemaps/:file with file "<n>.xml"
+func (h *Handler) File(c *gin.Context) {
+	n, err := strconv.Atoi(strings.TrimSuffix(c.Param("file"), ".xml"))
+	if err != nil || !strings.HasSuffix(c.Param("file"), ".xml") || n < 1 {
+		c.JSON(http.StatusNotFound, response.NewErrorResponse("Sitemap not found", "unknown sitemap file"))
+		return
+	}
+	files, err := h.service.Files()
+	if err != nil {
+		applog.GetLogger().WithFields(applog.Fields{"handler": "sitemap.File"}).Error("counting sitemap entries failed", applog.Fields{"error": err.Error()})
+		c.JSON(http.StatusInternalServerError, response.NewErrorResponse("Failed to build sitemap", err.Error()))
+		return
+	}
+	if n > files {
+		c.JSON(http.StatusNotFound, response.NewErrorResponse("Sitemap not found", "unknown sitemap file"))
+		return
+	}
+	h.sendURLSet(c, n)
+}
+
+// Robots serves robots.txt from the robots_txt setting
+func (h *Handler) Robots(c *gin.Context) {
+	body, err := h.service.Robots()
+	if err != nil {
+		applog.GetLogger().WithFields(applog.Fields{"handler": "sitemap.Robots"}).Error("reading robots.txt failed", applog.Fields{"error": err.Error()})
+		c.JSON(http.StatusInternalServerError, response.NewErrorResponse("Failed to build robots.txt", err.Error()))
+		return
+	}
+	httpAdapter.SendCacheable(c, "text/plain; charset=utf-8", []byte(body), time.Time{}, maxAge)
+}
+
+func (h *Handler) sendURLSet(c *gin.Context, n int) {
+	urls, err := h.service.URLs(n)
+	if err != nil {
+		applog.GetLogger().WithFields(applog.Fields{"handler": "sitemap.URLSet", "file": n}).Error("listing sitemap entries failed", applog.Fields{"error": err.Error()})
+		c.JSON(http.StatusInternalServerError, response.NewErrorResponse("Failed to build sitemap", err.Error()))
+		return
+	}
+
+	set := urlSet{NS: sitemapNS, ImageNS: imageNS, URLs: make([]urlEntry, 0, len(urls))}
+	var updated time.Time
+	for _, u := range urls {
+		if u.LastMod.After(updated) {
+			updated = u.LastMod
+		}
+		entry := urlEntry{Loc: u.Loc, LastMod: formatLastMod(u.LastMod)}
+		for _, img := range u.Images {
+			entry.Images = append(entry.Images, imageEntry(img))
+		}
+		set.URLs = append(set.URLs, entry)
+	}
+	h.send(c, set, updated)
+}
+
+func (h *Handler) send(c *gin.Context, v interface{}, updated time.Time) {
+	body, err := xml.MarshalIndent(v, "", "  ")
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, response.NewErrorResponse("Failed to render sitemap", err.Error()))
+		return
+	}
+	httpAdapter.SendCacheable(c, xmlContentType, append([]byte(xml.Header), body...), updated, maxAge)
+}
+
+func formatLastMod(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.UTC().Format(time.RFC3339)
+}
+
+// sitemaps.org 0.9 with the Google image extension
+
+const (
+	sitemapNS = "http://www.sitemaps.org/schemas/sitemap/0.9"
+	imageNS   = "http://www.google.com/schemas/sitemap-image/1.1"
+)
+
+type urlSet struct {
+	XMLName xml.Name   `xml:"urlset"`
+	NS      string     `xml:"xmlns,attr"`
+	ImageNS string     `xml:"xmlns:image,attr"`
+	URLs    []urlEntry `xml:"url"`
+}
+
+type urlEntry struct {
+	Loc     string       `xml:"loc"`
+	LastMod string       `xml:"lastmod,omitempty"`
+	Images  []imageEntry `xml:"image:image"`
+}
+
+type imageEntry struct {
+	Loc     string `xml:"image:loc"`
+	Title   string `xml:"image:title,omitempty"`
+	Caption string `xml:"image:caption,omitempty"`
+}
+
+type sitemapIndex struct {
+	XMLName  xml.Name     `xml:"sitemapindex"`
+	NS       string       `xml:"xmlns,attr"`
+	Sitemaps []indexEntry `xml:"sitemap"`
+}
+
+type indexEntry struct {
+	Loc     string `xml:"loc"`
+	LastMod string `xml:"lastmod,omitempty"`
+}
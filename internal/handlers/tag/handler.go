@@ -6,6 +6,7 @@ import (
 	"web-porto-backend/common/response"
 	httpAdapter "web-porto-backend/internal/adapters/http"
 	"web-porto-backend/internal/domain/dto"
+	"web-porto-backend/internal/services/audit"
 
 	"github.com/gin-gonic/gin"
 )
@@ -20,7 +21,8 @@ type Handler struct {
 		Update(id int, tag *dto.UpdateTagRequest) (*dto.TagResponse, error)
 		Delete(id int) error
 	}
-	httpAdapter *httpAdapter.HTTPAdapter
+	auditService audit.Service
+	httpAdapter  *httpAdapter.HTTPAdapter
 }
 
 // NewHandler creates a new tag handler
@@ -31,10 +33,11 @@ func NewHandler(tagService interface {
 	Create(tag *dto.CreateTagRequest) (*dto.TagResponse, error)
 	Update(id int, tag *dto.UpdateTagRequest) (*dto.TagResponse, error)
 	Delete(id int) error
-}, httpAdapter *httpAdapter.HTTPAdapter) *Handler {
+}, auditService audit.Service, httpAdapter *httpAdapter.HTTPAdapter) *Handler {
 	return &Handler{
-		tagService:  tagService,
-		httpAdapter: httpAdapter,
+		tagService:   tagService,
+		auditService: auditService,
+		httpAdapter:  httpAdapter,
 	}
 }
 
@@ -63,6 +66,7 @@ func (h *Handler) Create(c *gin.Context) {
 		c.JSON(http.StatusInternalServerError, response.NewErrorResponse("Failed to create tag", err.Error()))
 		return
 	}
+	h.auditService.Record(h.httpAdapter.AuditActor(c), audit.ActionCreate, audit.EntityTag, strconv.Itoa(tag.ID), nil, tag)
 
 	h.httpAdapter.SendSuccessResponse(c, http.StatusCreated, tag, "Tag created successfully")
 }
@@ -89,11 +93,13 @@ func (h *Handler) Update(c *gin.Context) {
 		return
 	}
 
+	before, _ := h.tagService.GetByID(tagID)
 	tag, err := h.tagService.Update(tagID, &tagRequest)
 	if err != nil {
 		c.JSON(http.StatusNotFound, response.NewErrorResponse("Failed to update tag", err.Error()))
 		return
 	}
+	h.auditService.Record(h.httpAdapter.AuditActor(c), audit.ActionUpdate, audit.EntityTag, id, before, tag)
 
 	h.httpAdapter.SendSuccessResponse(c, http.StatusOK, tag, "Tag updated successfully")
 }
@@ -113,10 +119,12 @@ func (h *Handler) Delete(c *gin.Context) {
 		return
 	}
 
+	before, _ := h.tagService.GetByID(tagID)
 	if err := h.tagService.Delete(tagID); err != nil {
 		c.JSON(http.StatusNotFound, response.NewErrorResponse("Failed to delete tag", err.Error()))
 		return
 	}
+	h.auditService.Record(h.httpAdapter.AuditActor(c), audit.ActionDelete, audit.EntityTag, id, before, nil)
 
 	h.httpAdapter.SendSuccessResponse(c, http.StatusOK, nil, "Tag deleted successfully")
 }
@@ -1,156 +1,404 @@
 ﻿package user
 
 import (
-"net/http"
-"web-porto-backend/common/response"
-httpAdapter "web-porto-backend/internal/adapters/http"
-"web-porto-backend/internal/domain/models"
-"web-porto-backend/internal/services/user"
+	"errors"
+	"net/http"
+	"web-porto-backend/common/response"
+	"web-porto-backend/internal/auth"
+	httpAdapter "web-porto-backend/internal/adapters/http"
+	"web-porto-backend/internal/domain/models"
+	"web-porto-backend/internal/services/account"
+	"web-porto-backend/internal/services/role"
+	"web-porto-backend/internal/services/token"
+	"web-porto-backend/internal/services/twofactor"
+	"web-porto-backend/internal/services/user"
 
-"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
 )
 
 type Handler struct {
-service     user.Service
-httpAdapter *httpAdapter.HTTPAdapter
+	service          user.Service
+	roleService      role.Service
+	tokenService     token.Service
+	twoFactorService twofactor.Service
+	accountService   account.Service
+	httpAdapter      *httpAdapter.HTTPAdapter
 }
 
-func NewHandler(service user.Service, httpAdapter *httpAdapter.HTTPAdapter) *Handler {
-return &Handler{
-service:     service,
-httpAdapter: httpAdapter,
-}
+func NewHandler(service user.Service, roleService role.Service, tokenService token.Service, twoFactorService twofactor.Service, accountService account.Service, httpAdapter *httpAdapter.HTTPAdapter) *Handler {
+	return &Handler{
+		service:          service,
+		roleService:      roleService,
+		tokenService:     tokenService,
+		twoFactorService: twoFactorService,
+		accountService:   accountService,
+		httpAdapter:      httpAdapter,
+	}
 }
 
 type CreateUserRequest struct {
-Username string `json:"username" binding:"required"`
-Email    string `json:"email" binding:"required,email"`
-Password string `json:"password" binding:"required,min=6"`
-Role     string `json:"role" binding:"oneof=admin user"`
+	Username string `json:"username" binding:"required"`
+	Email    string `json:"email" binding:"required,email"`
+	Password string `json:"password" binding:"required,min=6"`
+	Role     string `json:"role"`
 }
 
 type UpdateUserRequest struct {
-Username string `json:"username"`
-Email    string `json:"email" binding:"omitempty,email"`
-Role     string `json:"role" binding:"omitempty,oneof=admin user"`
+	Username string `json:"username"`
+	Email    string `json:"email" binding:"omitempty,email"`
+	Role     string `json:"role"`
 }
 
-func (h *Handler) CreateUser(c *gin.Context) {
-var req CreateUserRequest
-if err := c.ShouldBindJSON(&req); err != nil {
-c.JSON(http.StatusBadRequest, response.NewErrorResponse("Invalid request data", err.Error()))
-return
+type ChangeRoleRequest struct {
+	Role string `json:"role" binding:"required"`
 }
 
-user := &models.User{
-Username:     req.Username,
-Email:        req.Email,
-PasswordHash: req.Password, // Service will hash this
-Role:         req.Role,
+type ResetPasswordRequest struct {
+	Password string `json:"password" binding:"required,min=6"`
 }
 
-if err := h.service.Create(user); err != nil {
-c.JSON(http.StatusInternalServerError, response.NewErrorResponse("Failed to create user", err.Error()))
-return
-}
+const (
+	msgInvalidUserID    = "Invalid user ID"
+	msgUserNotFound     = "User not found"
+	msgInvalidRole      = "Invalid role"
+	msgInvalidReqData   = "Invalid request data"
+	msgCannotTargetSelf = "You cannot perform this action on your own account"
+	msgRoleNotGrantable = "You cannot grant a role with permissions you do not hold"
+	msgUserNotManaged   = "You cannot manage a user with permissions you do not hold"
+	msgWeakPassword     = "Password does not meet requirements"
+)
+
+func (h *Handler) CreateUser(c *gin.Context) {
+	var req CreateUserRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, response.NewErrorResponse(msgInvalidReqData, err.Error()))
+		return
+	}
+
+	if req.Role == "" {
+		req.Role = "user"
+	}
+	if !h.checkGrantable(c, req.Role) || !h.checkPassword(c, req.Password) {
+		return
+	}
+
+	user := &models.User{
+		Username:     req.Username,
+		Email:        req.Email,
+		PasswordHash: req.Password, // Service will hash this
+		Role:         req.Role,
+	}
 
-// Don't return password in response
-user.PasswordHash = ""
-h.httpAdapter.SendSuccessResponse(c, http.StatusCreated, user, "User created successfully")
+	if err := h.service.Create(user); err != nil {
+		c.JSON(http.StatusInternalServerError, response.NewErrorResponse("Failed to create user", err.Error()))
+		return
+	}
+
+	// Don't return password in response
+	user.PasswordHash = ""
+	h.httpAdapter.SendSuccessResponse(c, http.StatusCreated, user, "User created successfully")
 }
 
 func (h *Handler) GetUser(c *gin.Context) {
-id, err := h.httpAdapter.ParseIDParam(c, "id")
-if err != nil {
-c.JSON(http.StatusBadRequest, response.NewErrorResponse("Invalid user ID", err.Error()))
-return
-}
+	id, err := h.httpAdapter.ParseIDParam(c, "id")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, response.NewErrorResponse(msgInvalidUserID, err.Error()))
+		return
+	}
 
-user, err := h.service.GetByID(id)
-if err != nil {
-c.JSON(http.StatusNotFound, response.NewErrorResponse("User not found", err.Error()))
-return
-}
+	user, err := h.service.GetByID(id)
+	if err != nil {
+		c.JSON(http.StatusNotFound, response.NewErrorResponse(msgUserNotFound, err.Error()))
+		return
+	}
 
-// Don't return password
-user.PasswordHash = ""
-h.httpAdapter.SendSuccessResponse(c, http.StatusOK, user, "User retrieved successfully")
+	// Don't return password
+	user.PasswordHash = ""
+	h.httpAdapter.SendSuccessResponse(c, http.StatusOK, user, "User retrieved successfully")
 }
 
+// GetUsers lists users with pagination and optional ?search=, ?role= and ?status= filters
 func (h *Handler) GetUsers(c *gin.Context) {
-pagination := h.httpAdapter.GetPaginationFromQuery(c)
+	pagination := h.httpAdapter.GetPaginationFromQuery(c)
+	filter := user.ListFilter{
+		Search: c.Query("search"),
+		Role:   c.Query("role"),
+		Status: c.Query("status"),
+	}
+
+	users, paginationInfo, err := h.service.GetAll(pagination.Page, pagination.Limit, filter)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, response.NewErrorResponse("Failed to get users", err.Error()))
+		return
+	}
+
+	// Remove passwords from response
+	for _, user := range users {
+		user.PasswordHash = ""
+	}
 
-users, paginationInfo, err := h.service.GetAll(pagination.Page, pagination.Limit)
-if err != nil {
-c.JSON(http.StatusInternalServerError, response.NewErrorResponse("Failed to get users", err.Error()))
-return
+	responseData := response.NewPaginatedResponse(users, pagination.Page, pagination.Limit, paginationInfo.Total, "Users retrieved successfully")
+	c.JSON(http.StatusOK, responseData)
 }
 
-// Remove passwords from response
-for _, user := range users {
-user.PasswordHash = ""
+func (h *Handler) UpdateUser(c *gin.Context) {
+	id, err := h.httpAdapter.ParseIDParam(c, "id")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, response.NewErrorResponse(msgInvalidUserID, err.Error()))
+		return
+	}
+
+	var req UpdateUserRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, response.NewErrorResponse(msgInvalidReqData, err.Error()))
+		return
+	}
+
+	if !h.checkManageable(c, id) {
+		return
+	}
+	if req.Role != "" && !h.checkGrantable(c, req.Role) {
+		return
+	}
+
+	user := &models.User{
+		Username: req.Username,
+		Email:    req.Email,
+		Role:     req.Role,
+	}
+
+	if err := h.service.Update(id, user); err != nil {
+		h.sendUpdateError(c, "Failed to update user", err)
+		return
+	}
+
+	h.httpAdapter.SendSuccessResponse(c, http.StatusOK, nil, "User updated successfully")
 }
 
-responseData := response.NewPaginatedResponse(users, pagination.Page, pagination.Limit, paginationInfo.Total, "Users retrieved successfully")
-c.JSON(http.StatusOK, responseData)
+func (h *Handler) DeleteUser(c *gin.Context) {
+	id, err := h.httpAdapter.ParseIDParam(c, "id")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, response.NewErrorResponse(msgInvalidUserID, err.Error()))
+		return
+	}
+	if h.isSelf(c, id) {
+		c.JSON(http.StatusBadRequest, response.NewErrorResponse(msgCannotTargetSelf))
+		return
+	}
+	if !h.checkManageable(c, id) {
+		return
+	}
+
+	if err := h.service.Delete(id); err != nil {
+		c.JSON(http.StatusInternalServerError, response.NewErrorResponse("Failed to delete user", err.Error()))
+		return
+	}
+
+	h.httpAdapter.SendSuccessResponse(c, http.StatusOK, nil, "User deleted successfully")
 }
 
-func (h *Handler) UpdateUser(c *gin.Context) {
-id, err := h.httpAdapter.ParseIDParam(c, "id")
-if err != nil {
-c.JSON(http.StatusBadRequest, response.NewErrorResponse("Invalid user ID", err.Error()))
-return
+// ChangeRole sets the user's primary role
+func (h *Handler) ChangeRole(c *gin.Context) {
+	id, err := h.httpAdapter.ParseIDParam(c, "id")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, response.NewErrorResponse(msgInvalidUserID, err.Error()))
+		return
+	}
+
+	var req ChangeRoleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, response.NewErrorResponse(msgInvalidReqData, err.Error()))
+		return
+	}
+	if !h.checkManageable(c, id) || !h.checkGrantable(c, req.Role) {
+		return
+	}
+
+	if err := h.service.ChangeRole(id, req.Role); err != nil {
+		h.sendUpdateError(c, "Failed to change role", err)
+		return
+	}
+
+	h.httpAdapter.SendSuccessResponse(c, http.StatusOK, nil, "User role updated successfully")
 }
 
-var req UpdateUserRequest
-if err := c.ShouldBindJSON(&req); err != nil {
-c.JSON(http.StatusBadRequest, response.NewErrorResponse("Invalid request data", err.Error()))
-return
+// ResetPassword sets a new password chosen by an admin and signs the user out everywhere
+func (h *Handler) ResetPassword(c *gin.Context) {
+	id, err := h.httpAdapter.ParseIDParam(c, "id")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, response.NewErrorResponse(msgInvalidUserID, err.Error()))
+		return
+	}
+
+	var req ResetPasswordRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, response.NewErrorResponse(msgInvalidReqData, err.Error()))
+		return
+	}
+	if !h.checkManageable(c, id) || !h.checkPassword(c, req.Password) {
+		return
+	}
+
+	if err := h.service.ResetPassword(id, req.Password); err != nil {
+		h.sendUpdateError(c, "Failed to reset password", err)
+		return
+	}
+	if err := h.tokenService.RevokeAllForUser(int(id)); err != nil {
+		c.JSON(http.StatusInternalServerError, response.NewErrorResponse("Password reset but failed to revoke sessions", err.Error()))
+		return
+	}
+
+	h.httpAdapter.SendSuccessResponse(c, http.StatusOK, nil, "Password reset successfully")
 }
 
-user := &models.User{
-Username: req.Username,
-Email:    req.Email,
-Role:     req.Role,
+// Disable blocks the account from logging in and revokes its sessions
+func (h *Handler) Disable(c *gin.Context) {
+	id, err := h.httpAdapter.ParseIDParam(c, "id")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, response.NewErrorResponse(msgInvalidUserID, err.Error()))
+		return
+	}
+	if h.isSelf(c, id) {
+		c.JSON(http.StatusBadRequest, response.NewErrorResponse(msgCannotTargetSelf))
+		return
+	}
+	if !h.checkManageable(c, id) {
+		return
+	}
+
+	if err := h.service.SetStatus(id, models.UserStatusDisabled); err != nil {
+		h.sendUpdateError(c, "Failed to disable user", err)
+		return
+	}
+	if err := h.tokenService.RevokeAllForUser(int(id)); err != nil {
+		c.JSON(http.StatusInternalServerError, response.NewErrorResponse("User disabled but failed to revoke sessions", err.Error()))
+		return
+	}
+
+	h.httpAdapter.SendSuccessResponse(c, http.StatusOK, nil, "User disabled successfully")
 }
 
-if err := h.service.Update(id, user); err != nil {
-c.JSON(http.StatusInternalServerError, response.NewErrorResponse("Failed to update user", err.Error()))
-return
+// Enable re-activates a disabled account
+func (h *Handler) Enable(c *gin.Context) {
+	id, err := h.httpAdapter.ParseIDParam(c, "id")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, response.NewErrorResponse(msgInvalidUserID, err.Error()))
+		return
+	}
+	if !h.checkManageable(c, id) {
+		return
+	}
+
+	if err := h.service.SetStatus(id, models.UserStatusActive); err != nil {
+		h.sendUpdateError(c, "Failed to enable user", err)
+		return
+	}
+
+	h.httpAdapter.SendSuccessResponse(c, http.StatusOK, nil, "User enabled successfully")
 }
 
-h.httpAdapter.SendSuccessResponse(c, http.StatusOK, nil, "User updated successfully")
+// ResetTwoFactor removes two-factor authentication from a user who lost their
+// authenticator, so they can log in with a password and enroll again
+func (h *Handler) ResetTwoFactor(c *gin.Context) {
+	id, err := h.httpAdapter.ParseIDParam(c, "id")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, response.NewErrorResponse(msgInvalidUserID, err.Error()))
+		return
+	}
+	if !h.checkManageable(c, id) {
+		return
+	}
+
+	if err := h.twoFactorService.Reset(int(id)); err != nil {
+		if errors.Is(err, twofactor.ErrNotEnrolled) {
+			c.JSON(http.StatusNotFound, response.NewErrorResponse("Two-factor authentication is not set up for this user", err.Error()))
+			return
+		}
+		c.JSON(http.StatusInternalServerError, response.NewErrorResponse("Failed to reset two-factor authentication", err.Error()))
+		return
+	}
+
+	h.httpAdapter.SendSuccessResponse(c, http.StatusOK, nil, "Two-factor authentication reset successfully")
 }
 
-func (h *Handler) DeleteUser(c *gin.Context) {
-id, err := h.httpAdapter.ParseIDParam(c, "id")
-if err != nil {
-c.JSON(http.StatusBadRequest, response.NewErrorResponse("Invalid user ID", err.Error()))
-return
+func (h *Handler) GetUserByEmail(c *gin.Context) {
+	email := c.Query("email")
+	if email == "" {
+		c.JSON(http.StatusBadRequest, response.NewErrorResponse("Email parameter is required"))
+		return
+	}
+
+	user, err := h.service.GetByEmail(email)
+	if err != nil {
+		c.JSON(http.StatusNotFound, response.NewErrorResponse(msgUserNotFound, err.Error()))
+		return
+	}
+
+	// Don't return password
+	user.PasswordHash = ""
+	h.httpAdapter.SendSuccessResponse(c, http.StatusOK, user, "User retrieved successfully")
 }
 
-if err := h.service.Delete(id); err != nil {
-c.JSON(http.StatusInternalServerError, response.NewErrorResponse("Failed to delete user", err.Error()))
-return
+// checkGrantable responds with an error unless roleName exists and the caller
+// holds all of its permissions, so nobody can make a user, including
+// themselves, more privileged than they are
+func (h *Handler) checkGrantable(c *gin.Context, roleName string) bool {
+	permissions, err := h.roleService.RolePermissions(roleName)
+	if err != nil {
+		if errors.Is(err, role.ErrRoleNotFound) {
+			c.JSON(http.StatusBadRequest, response.NewErrorResponse(msgInvalidRole, "role "+roleName+" does not exist"))
+			return false
+		}
+		c.JSON(http.StatusInternalServerError, response.NewErrorResponse("Failed to check role", err.Error()))
+		return false
+	}
+	if !auth.CanGrant(c.GetStringSlice("permissions"), permissions) {
+		c.JSON(http.StatusForbidden, response.NewErrorResponse(msgRoleNotGrantable))
+		return false
+	}
+	return true
 }
 
-h.httpAdapter.SendSuccessResponse(c, http.StatusOK, nil, "User deleted successfully")
+// checkManageable responds with an error unless the user exists and the
+// caller holds every permission the user has. Resetting the password, email
+// or two-factor authentication of a more privileged user would otherwise
+// hand over their account.
+func (h *Handler) checkManageable(c *gin.Context, id uint) bool {
+	target, err := h.service.GetByID(id)
+	if err != nil {
+		h.sendUpdateError(c, "Failed to load user", err)
+		return false
+	}
+	permissions, err := h.roleService.PermissionsForUser(target)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, response.NewErrorResponse("Failed to check user permissions", err.Error()))
+		return false
+	}
+	if !auth.CanGrant(c.GetStringSlice("permissions"), permissions) {
+		c.JSON(http.StatusForbidden, response.NewErrorResponse(msgUserNotManaged))
+		return false
+	}
+	return true
 }
 
-func (h *Handler) GetUserByEmail(c *gin.Context) {
-email := c.Query("email")
-if email == "" {
-c.JSON(http.StatusBadRequest, response.NewErrorResponse("Email parameter is required"))
-return
+// checkPassword applies the password policy of the self-service flows
+func (h *Handler) checkPassword(c *gin.Context, password string) bool {
+	if err := h.accountService.ValidatePassword(password); err != nil {
+		c.JSON(http.StatusBadRequest, response.NewErrorResponse(msgWeakPassword, err.Error()))
+		return false
+	}
+	return true
 }
 
-user, err := h.service.GetByEmail(email)
-if err != nil {
-c.JSON(http.StatusNotFound, response.NewErrorResponse("User not found", err.Error()))
-return
+func (h *Handler) isSelf(c *gin.Context, id uint) bool {
+	return c.GetInt("user_id") == int(id)
 }
 
-// Don't return password
-user.PasswordHash = ""
-h.httpAdapter.SendSuccessResponse(c, http.StatusOK, user, "User retrieved successfully")
+func (h *Handler) sendUpdateError(c *gin.Context, message string, err error) {
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		c.JSON(http.StatusNotFound, response.NewErrorResponse(msgUserNotFound, err.Error()))
+		return
+	}
+	c.JSON(http.StatusInternalServerError, response.NewErrorResponse(message, err.Error()))
 }
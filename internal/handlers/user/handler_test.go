@@ -0,0 +1,235 @@
+package user
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	httpAdapter "web-porto-backend/internal/adapters/http"
+	"web-porto-backend/internal/auth"
+	"web-porto-backend/internal/domain/models"
+	"web-porto-backend/internal/services/account"
+	"web-porto-backend/internal/services/role"
+	"web-porto-backend/internal/services/token"
+	"web-porto-backend/internal/services/twofactor"
+	"web-porto-backend/internal/services/user"
+
+	"github.com/gin-gonic/gin"
+)
+
+type fakeRoles struct {
+	role.Service
+}
+
+func (fakeRoles) RolePermissions(name string) ([]string, error) {
+	switch name {
+	case "admin":
+		return []string{auth.PermissionAll}, nil
+	case "user":
+		return nil, nil
+	}
+	return nil, role.ErrRoleNotFound
+}
+
+func (r fakeRoles) PermissionsForUser(u *models.User) ([]string, error) {
+	return r.RolePermissions(u.Role)
+}
+
+type fakeUsers struct {
+	user.Service
+	changed bool
+}
+
+// GetByID finds the admin with ID 1 and plain users otherwise
+func (s *fakeUsers) GetByID(id uint) (*models.User, error) {
+	if id == 1 {
+		return &models.User{ID: 1, Role: "admin"}, nil
+	}
+	return &models.User{ID: int(id), Role: "user"}, nil
+}
+
+func (s *fakeUsers) Create(*models.User) error {
+	s.changed = true
+	return nil
+}
+
+func (s *fakeUsers) Update(uint, *models.User) error {
+	s.changed = true
+	return nil
+}
+
+func (s *fakeUsers) ChangeRole(uint, string) error {
+	s.changed = true
+	return nil
+}
+
+func (s *fakeUsers) Delete(uint) error {
+	s.changed = true
+	return nil
+}
+
+func (s *fakeUsers) ResetPassword(uint, string) error {
+	s.changed = true
+	return nil
+}
+
+func (s *fakeUsers) SetStatus(uint, string) error {
+	s.changed = true
+	return nil
+}
+
+type fakeTokens struct {
+	token.Service
+}
+
+func (fakeTokens) RevokeAllForUser(int) error {
+	return nil
+}
+
+type fakeTwoFactor struct {
+	twofactor.Service
+	users *fakeUsers
+}
+
+func (f fakeTwoFactor) Reset(int) error {
+	f.users.changed = true
+	return nil
+}
+
+// fakeAccounts requires passwords of 10 characters
+type fakeAccounts struct {
+	account.Service
+}
+
+func (fakeAccounts) ValidatePassword(password string) error {
+	if len(password) < 10 {
+		return &account.WeakPasswordError{Err: errors.New("password must be at least 10 characters")}
+	}
+	return nil
+}
+
+func newTestRouter(users *fakeUsers, permissions []string) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	h := NewHandler(users, fakeRoles{}, fakeTokens{}, fakeTwoFactor{users: users}, fakeAccounts{}, httpAdapter.NewHTTPAdapter())
+	r := gin.New()
+	r.Use(func(c *gin.Context) {
+		c.Set("user_id", 2)
+		c.Set("permissions", permissions)
+	})
+	r.POST("/users", h.CreateUser)
+	r.PUT("/users/:id", h.UpdateUser)
+	r.DELETE("/users/:id", h.DeleteUser)
+	r.PUT("/users/:id/role", h.ChangeRole)
+	r.POST("/users/:id/reset-password", h.ResetPassword)
+	r.POST("/users/:id/disable", h.Disable)
+	r.POST("/users/:id/enable", h.Enable)
+	r.DELETE("/users/:id/2fa", h.ResetTwoFactor)
+	return r
+}
+
+func TestRoleGrantRequiresHoldingItsPermissions(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	manager := []string{auth.PermUsersManage}
+	tests := []struct {
+		name        string
+		method      string
+		path        string
+		body        string
+		permissions []string
+		want        int
+	}{
+		{"create admin", http.MethodPost, "/users", `{"username":"x","email":"x@example.com","password":"long secret","role":"admin"}`, manager, http.StatusForbidden},
+		{"promote via update", http.MethodPut, "/users/3", `{"role":"admin"}`, manager, http.StatusForbidden},
+		{"promote via change role", http.MethodPut, "/users/3/role", `{"role":"admin"}`, manager, http.StatusForbidden},
+		{"unknown role", http.MethodPut, "/users/3/role", `{"role":"owner"}`, manager, http.StatusBadRequest},
+		{"create user", http.MethodPost, "/users", `{"username":"x","email":"x@example.com","password":"long secret"}`, manager, http.StatusCreated},
+		{"admin promotes", http.MethodPut, "/users/3/role", `{"role":"admin"}`, []string{auth.PermissionAll}, http.StatusOK},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			users := &fakeUsers{}
+			r := newTestRouter(users, tt.permissions)
+
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest(tt.method, tt.path, strings.NewReader(tt.body))
+			req.Header.Set("Content-Type", "application/json")
+			r.ServeHTTP(w, req)
+
+			if w.Code != tt.want {
+				t.Fatalf("status = %d, want %d: %s", w.Code, tt.want, w.Body.String())
+			}
+			if users.changed != (tt.want < 300) {
+				t.Fatalf("user changed = %v", users.changed)
+			}
+		})
+	}
+}
+
+func TestManagingUsersRequiresHoldingTheirPermissions(t *testing.T) {
+	manager := []string{auth.PermUsersManage}
+	admin := []string{auth.PermissionAll}
+	tests := []struct {
+		name        string
+		method      string
+		path        string
+		body        string
+		permissions []string
+		want        int
+	}{
+		{"change admin email", http.MethodPut, "/users/1", `{"email":"mine@example.com"}`, manager, http.StatusForbidden},
+		{"delete admin", http.MethodDelete, "/users/1", ``, manager, http.StatusForbidden},
+		{"demote admin", http.MethodPut, "/users/1/role", `{"role":"user"}`, manager, http.StatusForbidden},
+		{"reset admin password", http.MethodPost, "/users/1/reset-password", `{"password":"long secret"}`, manager, http.StatusForbidden},
+		{"disable admin", http.MethodPost, "/users/1/disable", ``, manager, http.StatusForbidden},
+		{"enable admin", http.MethodPost, "/users/1/enable", ``, manager, http.StatusForbidden},
+		{"reset admin two-factor", http.MethodDelete, "/users/1/2fa", ``, manager, http.StatusForbidden},
+		{"change user email", http.MethodPut, "/users/3", `{"email":"new@example.com"}`, manager, http.StatusOK},
+		{"delete user", http.MethodDelete, "/users/3", ``, manager, http.StatusOK},
+		{"reset user password", http.MethodPost, "/users/3/reset-password", `{"password":"long secret"}`, manager, http.StatusOK},
+		{"disable user", http.MethodPost, "/users/3/disable", ``, manager, http.StatusOK},
+		{"reset user two-factor", http.MethodDelete, "/users/3/2fa", ``, manager, http.StatusOK},
+		{"admin resets admin password", http.MethodPost, "/users/1/reset-password", `{"password":"long secret"}`, admin, http.StatusOK},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			users := &fakeUsers{}
+			r := newTestRouter(users, tt.permissions)
+
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest(tt.method, tt.path, strings.NewReader(tt.body))
+			req.Header.Set("Content-Type", "application/json")
+			r.ServeHTTP(w, req)
+
+			if w.Code != tt.want {
+				t.Fatalf("status = %d, want %d: %s", w.Code, tt.want, w.Body.String())
+			}
+			if users.changed != (tt.want < 300) {
+				t.Fatalf("user changed = %v", users.changed)
+			}
+		})
+	}
+}
+
+func TestAdminSetPasswordsFollowThePolicy(t *testing.T) {
+	for _, tt := range []struct {
+		name, method, path, body string
+	}{
+		{"create user", http.MethodPost, "/users", `{"username":"x","email":"x@example.com","password":"secret1"}`},
+		{"reset password", http.MethodPost, "/users/3/reset-password", `{"password":"secret1"}`},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			users := &fakeUsers{}
+			r := newTestRouter(users, []string{auth.PermissionAll})
+
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest(tt.method, tt.path, strings.NewReader(tt.body))
+			req.Header.Set("Content-Type", "application/json")
+			r.ServeHTTP(w, req)
+
+			if w.Code != http.StatusBadRequest || users.changed {
+				t.Fatalf("status = %d, changed = %v: %s", w.Code, users.changed, w.Body.String())
+			}
+		})
+	}
+}
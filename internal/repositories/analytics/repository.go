@@ -5,7 +5,9 @@ import (
 	"sync"
 	"time"
 	applog "web-porto-backend/common/logger"
+	"web-porto-backend/internal/domain/dto"
 	"web-porto-backend/internal/domain/models"
+	"web-porto-backend/internal/repositories/scopes"
 
 	"gorm.io/gorm"
 )
@@ -28,8 +30,19 @@ type PageCount struct {
 	Count int64  `json:"count"`
 }
 
+// ViewFilter narrows ListViews results; zero values are ignored
+type ViewFilter struct {
+	Page      string
+	VisitorID string
+	Country   string
+	From      *time.Time
+	To        *time.Time
+}
+
 type Repository interface {
 	TrackView(v *models.PageView) error
+	// ListViews pages through raw page views, newest first
+	ListViews(filter ViewFilter, q dto.CursorQuery) ([]models.PageView, dto.CursorResult, error)
 	GetStats(page string) (*ViewStats, error)
 	GetStatsWithFilter(page string, start, end *time.Time, country string) (*ViewStats, error)
 	GetTimeSeries(page string, start, end time.Time, interval string) ([]TimeSeriesPoint, error)
@@ -84,6 +97,34 @@ func (r *repository) TrackView(v *models.PageView) error {
 	return nil
 }
 
+func (r *repository) ListViews(filter ViewFilter, q dto.CursorQuery) ([]models.PageView, dto.CursorResult, error) {
+	query := r.db.Model(&models.PageView{})
+	if filter.Page != "" {
+		query = query.Where("page = ?", filter.Page)
+	}
+	if filter.VisitorID != "" {
+		query = query.Where("visitor_id = ?", filter.VisitorID)
+	}
+	if filter.Country != "" {
+		query = query.Where("country = ?", filter.Country)
+	}
+	if filter.From != nil {
+		query = query.Where("timestamp >= ?", *filter.From)
+	}
+	if filter.To != nil {
+		query = query.Where("timestamp <= ?", *filter.To)
+	}
+
+	var views []models.PageView
+	if err := query.Scopes(scopes.Keyset("timestamp", "id", q)).Find(&views).Error; err != nil {
+		return nil, dto.CursorResult{}, err
+	}
+	views, result := scopes.KeysetPage(views, q, func(v models.PageView) dto.Cursor {
+		return dto.Cursor{CreatedAt: v.Timestamp, ID: int64(v.ID)}
+	})
+	return views, result, nil
+}
+
 // In-memory cache for analytics stats with 5 minute TTL
 type statsCache struct {
 	stats     *ViewStats
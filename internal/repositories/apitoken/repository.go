@@ -0,0 +1,84 @@
+package apitoken
+
+import (
+	"time"
+	"web-porto-backend/internal/domain/models"
+
+	"gorm.io/gorm"
+)
+
+type Repository interface {
+	Create(token *models.APIToken) error
+	FindByID(id int) (*models.APIToken, error)
+	FindByHash(hash string) (*models.APIToken, error)
+	FindByUser(userID int) ([]models.APIToken, error)
+	FindAll() ([]models.APIToken, error)
+	TouchLastUsed(id int, at time.Time, ip string) error
+	Revoke(id int) error
+	RevokeAllForUser(userID int) error
+}
+
+type repository struct {
+	db *gorm.DB
+}
+
+func NewRepository(db *gorm.DB) Repository {
+	return &repository{db: db}
+}
+
+func (r *repository) Create(token *models.APIToken) error {
+	return r.db.Create(token).Error
+}
+
+func (r *repository) FindByID(id int) (*models.APIToken, error) {
+	var token models.APIToken
+	if err := r.db.First(&token, id).Error; err != nil {
+		return nil, err
+	}
+	return &token, nil
+}
+
+func (r *repository) FindByHash(hash string) (*models.APIToken, error) {
+	var token models.APIToken
+	if err := r.db.Where("token_hash = ?", hash).First(&token).Error; err != nil {
+		return nil, err
+	}
+	return &token, nil
+}
+
+func (r *repository) FindByUser(userID int) ([]models.APIToken, error) {
+	var tokens []models.APIToken
+	err := r.db.Where("user_id = ?", userID).Order("created_at DESC").Find(&tokens).Error
+	return tokens, err
+}
+
+func (r *repository) FindAll() ([]models.APIToken, error) {
+	var tokens []models.APIToken
+	err := r.db.Order("created_at DESC").Find(&tokens).Error
+	return tokens, err
+}
+
+// TouchLastUsed records usage without bumping updated_at
+func (r *repository) TouchLastUsed(id int, at time.Time, ip string) error {
+	return r.db.Model(&models.APIToken{}).Where("id = ?", id).
+		UpdateColumns(map[string]interface{}{"last_used_at": at, "last_used_ip": ip}).Error
+}
+
+func (r *repository) Revoke(id int) error {
+	res := r.db.Model(&models.APIToken{}).
+		Where("id = ? AND revoked_at IS NULL", id).
+		Update("revoked_at", time.Now())
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+func (r *repository) RevokeAllForUser(userID int) error {
+	return r.db.Model(&models.APIToken{}).
+		Where("user_id = ? AND revoked_at IS NULL", userID).
+		Update("revoked_at", time.Now()).Error
+}
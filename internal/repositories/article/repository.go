@@ -1,7 +1,9 @@
 package article
 
 import (
+	"web-porto-backend/internal/domain/dto"
 	"web-porto-backend/internal/domain/models"
+	"web-porto-backend/internal/repositories/scopes"
 
 	"gorm.io/gorm"
 )
@@ -9,14 +11,15 @@ import (
 type Repository interface {
 	Create(article *models.Article) error
 	GetByID(id string) (*models.Article, error)
-	GetAll(limit, offset int) ([]*models.Article, int64, error)
+	// GetAll lists articles with the given status (see scopes.Status),
+	// filtered and sorted by q within listColumns
+	GetAll(status string, q dto.ListQuery, limit, offset int) ([]*models.Article, int64, error)
 	Update(article *models.Article) error
 	Delete(id string) error
 	GetBySlug(slug string) (*models.Article, error)
-	GetByAuthorID(authorID int, limit, offset int) ([]*models.Article, int64, error)
-	GetPublished(limit, offset int) ([]*models.Article, int64, error)
-	GetByCategory(categoryID int, limit, offset int) ([]*models.Article, int64, error)
-	GetByTag(tagID int, limit, offset int) ([]*models.Article, int64, error)
+	GetByAuthorID(authorID int, status string, limit, offset int) ([]*models.Article, int64, error)
+	GetByCategory(categoryID int, status string, limit, offset int) ([]*models.Article, int64, error)
+	GetByTag(tagID int, status string, limit, offset int) ([]*models.Article, int64, error)
 	UpdateArticleCategories(articleID string, categoryIDs []int) error
 	UpdateArticleTags(articleID string, tagIDs []int) error
 	UpdateArticleImages(articleID string, images []models.ArticleImage) error
@@ -54,20 +57,57 @@ func (r *repository) GetByID(id string) (*models.Article, error) {
 	return &article, nil
 }
 
-func (r *repository) GetAll(limit, offset int) ([]*models.Article, int64, error) {
+// listColumns whitelists the list query fields of GetAll. The status filter
+// arrives as GetAll's status argument.
+var listColumns = scopes.ListColumns{
+	Sort: map[string]string{
+		"publishedAt": "articles.published_at",
+		"createdAt":   "articles.created_at",
+		"updatedAt":   "articles.updated_at",
+		"title":       "articles.title",
+		"viewCount":   "articles.view_count",
+		"readTime":    "articles.read_time",
+	},
+	Ranges: map[string]string{
+		"publishedAt": "articles.published_at",
+		"createdAt":   "articles.created_at",
+		"updatedAt":   "articles.updated_at",
+	},
+	Filters:      []string{"status", "category", "tag", "author"},
+	DefaultOrder: "articles.created_at DESC",
+	Key:          "articles.id",
+}
+
+func (r *repository) GetAll(status string, q dto.ListQuery, limit, offset int) ([]*models.Article, int64, error) {
 	var articles []*models.Article
 	var total int64
 
+	ranges, order, err := listColumns.Apply(q)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	query := r.db.Model(&models.Article{}).Scopes(scopes.Status("articles", status), ranges)
+	if categories := q.Filter("category"); len(categories) > 0 {
+		query = query.Where("EXISTS (SELECT 1 FROM article_categories ac JOIN categories c ON c.id = ac.category_id WHERE ac.article_id = articles.id AND c.slug IN ?)", categories)
+	}
+	if tags := q.Filter("tag"); len(tags) > 0 {
+		query = query.Where("EXISTS (SELECT 1 FROM article_tags tg JOIN tags t ON t.id = tg.tag_id WHERE tg.article_id = articles.id AND (t.slug IN ? OR lower(t.name) IN ?))", tags, scopes.Lower(tags))
+	}
+	if authors := q.Filter("author"); len(authors) > 0 {
+		query = query.Where("EXISTS (SELECT 1 FROM users u WHERE u.id = articles.author_id AND u.username IN ?)", authors)
+	}
+
 	// Count total records
-	r.db.Model(&models.Article{}).Count(&total)
+	query.Count(&total)
 
 	// Get paginated results with preloaded associations
-	err := r.db.Preload("Author").
+	err = query.Preload("Author").
 		Preload("Categories").
 		Preload("Tags").
 		Preload("Images").
 		Preload("Videos").
-		Order("created_at DESC").
+		Order(order).
 		Limit(limit).Offset(offset).Find(&articles).Error
 
 	return articles, total, err
@@ -112,29 +152,13 @@ func (r *repository) GetBySlug(slug string) (*models.Article, error) {
 	return &article, nil
 }
 
-func (r *repository) GetByAuthorID(authorID int, limit, offset int) ([]*models.Article, int64, error) {
+func (r *repository) GetByAuthorID(authorID int, status string, limit, offset int) ([]*models.Article, int64, error) {
 	var articles []*models.Article
 	var total int64
 
-	query := r.db.Model(&models.Article{}).Where("author_id = ?", authorID)
-	query.Count(&total)
-
-	err := query.Preload("Author").
-		Preload("Categories").
-		Preload("Tags").
-		Preload("Images").
-		Preload("Videos").
-		Order("created_at DESC").
-		Limit(limit).Offset(offset).Find(&articles).Error
-
-	return articles, total, err
-}
-
-func (r *repository) GetPublished(limit, offset int) ([]*models.Article, int64, error) {
-	var articles []*models.Article
-	var total int64
-
-	query := r.db.Model(&models.Article{}).Where("status = ?", "published")
+	query := r.db.Model(&models.Article{}).
+		Scopes(scopes.Status("articles", status)).
+		Where("author_id = ?", authorID)
 	query.Count(&total)
 
 	err := query.Preload("Author").
@@ -148,11 +172,12 @@ func (r *repository) GetPublished(limit, offset int) ([]*models.Article, int64,
 	return articles, total, err
 }
 
-func (r *repository) GetByCategory(categoryID int, limit, offset int) ([]*models.Article, int64, error) {
+func (r *repository) GetByCategory(categoryID int, status string, limit, offset int) ([]*models.Article, int64, error) {
 	var articles []*models.Article
 	var total int64
 
 	query := r.db.Model(&models.Article{}).
+		Scopes(scopes.Status("articles", status)).
 		Joins("JOIN article_categories ac ON ac.article_id = articles.id").
 		Where("ac.category_id = ?", categoryID)
 
@@ -169,11 +194,12 @@ func (r *repository) GetByCategory(categoryID int, limit, offset int) ([]*models
 	return articles, total, err
 }
 
-func (r *repository) GetByTag(tagID int, limit, offset int) ([]*models.Article, int64, error) {
+func (r *repository) GetByTag(tagID int, status string, limit, offset int) ([]*models.Article, int64, error) {
 	var articles []*models.Article
 	var total int64
 
 	query := r.db.Model(&models.Article{}).
+		Scopes(scopes.Status("articles", status)).
 		Joins("JOIN article_tags at ON at.article_id = articles.id").
 		Where("at.tag_id = ?", tagID)
 
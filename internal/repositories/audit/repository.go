@@ -0,0 +1,75 @@
+package audit
+
+import (
+	"time"
+	"web-porto-backend/internal/domain/models"
+
+	"gorm.io/gorm"
+)
+
+// LogFilter narrows FindAll results; zero values are ignored
+type LogFilter struct {
+	UserID     int
+	Action     string
+	EntityType string
+	EntityID   string
+	From       *time.Time
+	To         *time.Time
+}
+
+type Repository interface {
+	Create(entry *models.Log) error
+	FindByID(id int) (*models.Log, error)
+	FindAll(filter LogFilter, offset, limit int) ([]models.Log, int64, error)
+}
+
+type repository struct {
+	db *gorm.DB
+}
+
+func NewRepository(db *gorm.DB) Repository {
+	return &repository{db: db}
+}
+
+func (r *repository) Create(entry *models.Log) error {
+	return r.db.Create(entry).Error
+}
+
+func (r *repository) FindByID(id int) (*models.Log, error) {
+	var entry models.Log
+	if err := r.db.First(&entry, id).Error; err != nil {
+		return nil, err
+	}
+	return &entry, nil
+}
+
+func (r *repository) FindAll(filter LogFilter, offset, limit int) ([]models.Log, int64, error) {
+	query := r.db.Model(&models.Log{})
+	if filter.UserID > 0 {
+		query = query.Where("user_id = ?", filter.UserID)
+	}
+	if filter.Action != "" {
+		query = query.Where("action = ?", filter.Action)
+	}
+	if filter.EntityType != "" {
+		query = query.Where("entity_type = ?", filter.EntityType)
+	}
+	if filter.EntityID != "" {
+		query = query.Where("entity_id = ?", filter.EntityID)
+	}
+	if filter.From != nil {
+		query = query.Where("created_at >= ?", *filter.From)
+	}
+	if filter.To != nil {
+		query = query.Where("created_at <= ?", *filter.To)
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var entries []models.Log
+	err := query.Order("created_at DESC, id DESC").Offset(offset).Limit(limit).Find(&entries).Error
+	return entries, total, err
+}
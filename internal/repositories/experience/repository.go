@@ -2,7 +2,10 @@ package experience
 
 import (
 	"fmt"
+	"strconv"
+	"web-porto-backend/internal/domain/dto"
 	"web-porto-backend/internal/domain/models"
+	"web-porto-backend/internal/repositories/scopes"
 
 	"gorm.io/gorm"
 )
@@ -10,7 +13,8 @@ import (
 type Repository interface {
 	Create(experience *models.Experience) error
 	GetByID(id int) (*models.Experience, error)
-	GetAll(limit, offset int) ([]*models.Experience, int64, error)
+	// GetAll lists experiences filtered and sorted by q within listColumns
+	GetAll(q dto.ListQuery, limit, offset int) ([]*models.Experience, int64, error)
 	Update(experience *models.Experience) error
 	Delete(id int) error
 	GetCurrent() ([]*models.Experience, error)
@@ -39,15 +43,55 @@ func (r *repository) GetByID(id int) (*models.Experience, error) {
 	return &experience, nil
 }
 
-func (r *repository) GetAll(limit, offset int) ([]*models.Experience, int64, error) {
+// listColumns whitelists the list query fields of GetAll
+var listColumns = scopes.ListColumns{
+	Sort: map[string]string{
+		"startDate": "experiences.start_date",
+		"endDate":   "experiences.end_date",
+		"createdAt": "experiences.created_at",
+		"updatedAt": "experiences.updated_at",
+		"title":     "experiences.title",
+		"company":   "experiences.company",
+	},
+	Ranges: map[string]string{
+		"startDate": "experiences.start_date",
+		"endDate":   "experiences.end_date",
+		"createdAt": "experiences.created_at",
+	},
+	Filters:      []string{"tag", "current", "company"},
+	DefaultOrder: "experiences.start_date DESC",
+	Key:          "experiences.id",
+}
+
+func (r *repository) GetAll(q dto.ListQuery, limit, offset int) ([]*models.Experience, int64, error) {
 	var experiences []*models.Experience
 	var total int64
 
+	ranges, order, err := listColumns.Apply(q)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	query := r.db.Model(&models.Experience{}).Scopes(ranges)
+	if tags := q.Filter("tag"); len(tags) > 0 {
+		query = query.Where("EXISTS (SELECT 1 FROM experience_technologies et JOIN tags t ON t.id = et.tag_id WHERE et.experience_id = experiences.id AND (t.slug IN ? OR lower(t.name) IN ?))", tags, scopes.Lower(tags))
+	}
+	if companies := q.Filter("company"); len(companies) > 0 {
+		query = query.Where("lower(experiences.company) IN ?", scopes.Lower(companies))
+	}
+	if current := q.Filter("current"); len(current) > 0 {
+		value, err := strconv.ParseBool(current[0])
+		if err != nil || len(current) > 1 {
+			return nil, 0, fmt.Errorf("%w: filter[current] must be true or false", dto.ErrInvalidListQuery)
+		}
+		query = query.Where("experiences.current = ?", value)
+	}
+
 	// Count total records
-	r.db.Model(&models.Experience{}).Count(&total)
+	query.Count(&total)
 
-	// Get paginated results ordered by start_date desc with preloaded technologies
-	err := r.db.Preload("Technologies").Preload("Images").Order("start_date DESC").
+	// Get paginated results with preloaded technologies
+	err = query.Preload("Technologies").Preload("Images").Order(order).
 		Limit(limit).Offset(offset).Find(&experiences).Error
 
 	return experiences, total, err
@@ -0,0 +1,111 @@
+package invitation
+
+import (
+	"errors"
+	"time"
+	"web-porto-backend/internal/domain/models"
+
+	"gorm.io/gorm"
+)
+
+// ErrNotRedeemable is returned by Claim when the invitation was already
+// accepted, revoked or has expired.
+var ErrNotRedeemable = errors.New("invitation not redeemable")
+
+type Repository interface {
+	Create(invitation *models.Invitation) error
+	FindByID(id int) (*models.Invitation, error)
+	FindByHash(hash string) (*models.Invitation, error)
+	FindAll(status string) ([]models.Invitation, error)
+	Claim(id int) error
+	Release(id int) error
+	SetAcceptedBy(id, userID int) error
+	Revoke(id int) error
+}
+
+type repository struct {
+	db *gorm.DB
+}
+
+func NewRepository(db *gorm.DB) Repository {
+	return &repository{db: db}
+}
+
+func (r *repository) Create(invitation *models.Invitation) error {
+	return r.db.Create(invitation).Error
+}
+
+func (r *repository) FindByID(id int) (*models.Invitation, error) {
+	var invitation models.Invitation
+	if err := r.db.First(&invitation, id).Error; err != nil {
+		return nil, err
+	}
+	return &invitation, nil
+}
+
+func (r *repository) FindByHash(hash string) (*models.Invitation, error) {
+	var invitation models.Invitation
+	if err := r.db.Where("token_hash = ?", hash).First(&invitation).Error; err != nil {
+		return nil, err
+	}
+	return &invitation, nil
+}
+
+// FindAll lists invitations, newest first, optionally filtered by derived status.
+func (r *repository) FindAll(status string) ([]models.Invitation, error) {
+	query := r.db.Model(&models.Invitation{})
+	now := time.Now()
+	switch status {
+	case models.InvitationStatusPending:
+		query = query.Where("accepted_at IS NULL AND revoked_at IS NULL AND expires_at > ?", now)
+	case models.InvitationStatusAccepted:
+		query = query.Where("accepted_at IS NOT NULL")
+	case models.InvitationStatusRevoked:
+		query = query.Where("accepted_at IS NULL AND revoked_at IS NOT NULL")
+	case models.InvitationStatusExpired:
+		query = query.Where("accepted_at IS NULL AND revoked_at IS NULL AND expires_at <= ?", now)
+	}
+
+	var invitations []models.Invitation
+	err := query.Order("created_at DESC").Find(&invitations).Error
+	return invitations, err
+}
+
+// Claim atomically marks a pending invitation as accepted so concurrent
+// registrations cannot redeem it twice.
+func (r *repository) Claim(id int) error {
+	res := r.db.Model(&models.Invitation{}).
+		Where("id = ? AND accepted_at IS NULL AND revoked_at IS NULL AND expires_at > ?", id, time.Now()).
+		Update("accepted_at", time.Now())
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return ErrNotRedeemable
+	}
+	return nil
+}
+
+// Release undoes a Claim whose registration failed.
+func (r *repository) Release(id int) error {
+	return r.db.Model(&models.Invitation{}).
+		Where("id = ? AND accepted_by IS NULL", id).
+		Update("accepted_at", nil).Error
+}
+
+func (r *repository) SetAcceptedBy(id, userID int) error {
+	return r.db.Model(&models.Invitation{}).Where("id = ?", id).Update("accepted_by", userID).Error
+}
+
+func (r *repository) Revoke(id int) error {
+	res := r.db.Model(&models.Invitation{}).
+		Where("id = ? AND accepted_at IS NULL AND revoked_at IS NULL", id).
+		Update("revoked_at", time.Now())
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return ErrNotRedeemable
+	}
+	return nil
+}
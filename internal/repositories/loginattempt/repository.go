@@ -0,0 +1,131 @@
+package loginattempt
+
+import (
+	"errors"
+	"time"
+	"web-porto-backend/internal/domain/models"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// AttemptFilter narrows FindAttempts results; zero values are ignored
+type AttemptFilter struct {
+	Email   string
+	IP      string
+	UserID  int
+	Success *bool
+}
+
+type Repository interface {
+	CreateAttempt(attempt *models.LoginAttempt) error
+	FindAttempts(filter AttemptFilter, offset, limit int) ([]models.LoginAttempt, int64, error)
+	FindThrottle(scope, identifier string) (*models.LoginThrottle, error)
+	RegisterFailure(scope, identifier string, window time.Duration, lockFor func(failures int) time.Duration) (*models.LoginThrottle, error)
+	DeleteThrottle(scope, identifier string) error
+	FindActiveThrottles(now time.Time) ([]models.LoginThrottle, error)
+}
+
+type repository struct {
+	db *gorm.DB
+}
+
+func NewRepository(db *gorm.DB) Repository {
+	return &repository{db: db}
+}
+
+func (r *repository) CreateAttempt(attempt *models.LoginAttempt) error {
+	return r.db.Create(attempt).Error
+}
+
+func (r *repository) FindAttempts(filter AttemptFilter, offset, limit int) ([]models.LoginAttempt, int64, error) {
+	query := r.db.Model(&models.LoginAttempt{})
+	if filter.Email != "" {
+		query = query.Where("email = ?", filter.Email)
+	}
+	if filter.IP != "" {
+		query = query.Where("ip = ?", filter.IP)
+	}
+	if filter.UserID > 0 {
+		query = query.Where("user_id = ?", filter.UserID)
+	}
+	if filter.Success != nil {
+		query = query.Where("success = ?", *filter.Success)
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var attempts []models.LoginAttempt
+	err := query.Order("created_at DESC, id DESC").Offset(offset).Limit(limit).Find(&attempts).Error
+	return attempts, total, err
+}
+
+func (r *repository) FindThrottle(scope, identifier string) (*models.LoginThrottle, error) {
+	var throttle models.LoginThrottle
+	if err := r.db.Where("scope = ? AND identifier = ?", scope, identifier).First(&throttle).Error; err != nil {
+		return nil, err
+	}
+	return &throttle, nil
+}
+
+// RegisterFailure increments the failure counter under a row lock so parallel
+// attempts cannot lose updates. Counters older than window start over.
+// lockFor returns how long to lock for the new failure count (0 for no lock).
+func (r *repository) RegisterFailure(scope, identifier string, window time.Duration, lockFor func(failures int) time.Duration) (*models.LoginThrottle, error) {
+	var throttle models.LoginThrottle
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Clauses(clause.OnConflict{DoNothing: true}).
+			Create(&models.LoginThrottle{Scope: scope, Identifier: identifier}).Error; err != nil {
+			return err
+		}
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("scope = ? AND identifier = ?", scope, identifier).
+			First(&throttle).Error; err != nil {
+			return err
+		}
+
+		now := time.Now()
+		if !throttle.LastFailureAt.IsZero() && now.Sub(throttle.LastFailureAt) > window &&
+			(throttle.LockedUntil == nil || throttle.LockedUntil.Before(now)) {
+			throttle.Failures = 0
+		}
+		throttle.Failures++
+		throttle.LastFailureAt = now
+		if d := lockFor(throttle.Failures); d > 0 {
+			until := now.Add(d)
+			throttle.LockedUntil = &until
+		}
+
+		return tx.Save(&throttle).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &throttle, nil
+}
+
+func (r *repository) DeleteThrottle(scope, identifier string) error {
+	res := r.db.Where("scope = ? AND identifier = ?", scope, identifier).Delete(&models.LoginThrottle{})
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+// FindActiveThrottles lists entries that are currently locked.
+func (r *repository) FindActiveThrottles(now time.Time) ([]models.LoginThrottle, error) {
+	var throttles []models.LoginThrottle
+	err := r.db.Where("locked_until > ?", now).Order("locked_until DESC").Find(&throttles).Error
+	return throttles, err
+}
+
+// IsNotFound reports whether err means the throttle entry does not exist
+func IsNotFound(err error) bool {
+	return errors.Is(err, gorm.ErrRecordNotFound)
+}
@@ -0,0 +1,534 @@
+package media
+
+import (
+	"fmt"
+	"strings"
+	"time"
+	"unicode/utf8"
+	"web-porto-backend/internal/domain/dto"
+	"web-porto-backend/internal/domain/models"
+	"web-porto-backend/internal/repositories/scopes"
+
+	"gorm.io/gorm"
+)
+
+type Repository interface {
+	Create(media *models.Media) error
+	FindByID(id uint) (*models.Media, error)
+	// ClaimPending marks up to limit pending media as processing and returns
+	// them. Rows locked by another worker are skipped.
+	ClaimPending(now time.Time, limit int) ([]models.Media, error)
+	// ReleaseStale puts media stuck in processing since before the cutoff
+	// back to pending
+	ReleaseStale(before time.Time) (int64, error)
+	// SaveProcessing stores the result of processing a media item
+	SaveProcessing(media *models.Media) error
+	FindByURL(url string) (*models.Media, error)
+	Delete(id uint) error
+	// ListForMigration returns up to limit public media with an ID above
+	// afterID that are not stored in the given backend, in ID order
+	ListForMigration(notStorage string, afterID uint, limit int) ([]models.Media, error)
+	// ListAfter returns up to limit media with an ID above afterID, in ID order
+	ListAfter(afterID uint, limit int) ([]models.Media, error)
+	// SaveStorage stores the new location of a moved media item and rewrites
+	// references to its old URLs (keys of urls) in content, in one transaction
+	SaveStorage(media *models.Media, urls map[string]string) error
+
+	// List returns a page of the library matching q and a search term in the
+	// names, title, alt text, caption and credit, with the total count.
+	// Private media are left out unless withPrivate is set.
+	List(q dto.ListQuery, search string, withPrivate bool, limit, offset int) ([]models.Media, int64, error)
+	// UpdateMetadata saves the library fields of media; tags replace the
+	// current ones unless nil
+	UpdateMetadata(media *models.Media, tags []models.Tag) error
+	// FindUsages lists the content referencing any of urls
+	FindUsages(urls []string) ([]dto.MediaUsage, error)
+
+	ListFolders() ([]models.MediaFolder, error)
+	FindFolder(id uint) (*models.MediaFolder, error)
+	FindFolderByPath(path string) (*models.MediaFolder, error)
+	CreateFolder(folder *models.MediaFolder) error
+	// UpdateFolder saves a renamed or moved folder and rewrites the paths of
+	// its subfolders from oldPath
+	UpdateFolder(folder *models.MediaFolder, oldPath string) error
+	DeleteFolder(id uint) error
+	// CountFolderContents counts the media and subfolders directly in a folder
+	CountFolderContents(id uint) (int64, error)
+
+	// UploadUsage returns the upload totals and quota override of one user,
+	// or of every user (userID 0) biggest uploaders first
+	UploadUsage(userID uint) ([]UploadUsage, error)
+	// SetUploadQuota stores the quota override of a user; nil clears it
+	SetUploadQuota(userID uint, quotaMB *int) error
+
+	CreateUploadSession(session *models.UploadSession) error
+	FindUploadSession(id string) (*models.UploadSession, error)
+	// TouchUploadSession pushes back the expiry of an upload still in progress
+	TouchUploadSession(id string, expiresAt time.Time) error
+	// ClaimUploadSession moves an upload from uploading to completing until
+	// deadline, reporting false when another request got there first
+	ClaimUploadSession(id string, deadline time.Time) (bool, error)
+	// SaveUploadSession stores the status and media of an upload
+	SaveUploadSession(session *models.UploadSession) error
+	DeleteUploadSession(id string) error
+	// ListExpiredUploadSessions returns up to limit sessions expired before
+	// now; completing sessions expire at their claim deadline, when the
+	// completion must have failed
+	ListExpiredUploadSessions(now time.Time, limit int) ([]models.UploadSession, error)
+}
+
+// UploadUsage totals the media a user uploaded. Bytes counts the originals,
+// VariantBytes the copies generated from them.
+type UploadUsage struct {
+	UserID        uint
+	Username      string
+	UploadQuotaMB *int
+	Files         int64
+	Bytes         int64
+	VariantBytes  int64
+}
+
+// urlColumns hold a single media URL each
+var urlColumns = []struct{ table, column string }{
+	{"articles", "featured_image_url"},
+	{"article_images", "url"},
+	{"article_videos", "url"},
+	{"projects", "thumbnail_url"},
+	{"project_images", "url"},
+	{"project_videos", "url"},
+	{"experiences", "logo_url"},
+	{"experience_images", "url"},
+}
+
+// contentTables embed media URLs in their content and SEO ogImage
+var contentTables = []string{"articles", "projects", "pages"}
+
+type repository struct {
+	db *gorm.DB
+}
+
+func NewRepository(db *gorm.DB) Repository {
+	return &repository{db: db}
+}
+
+func (r *repository) Create(media *models.Media) error {
+	return r.db.Create(media).Error
+}
+
+func (r *repository) FindByID(id uint) (*models.Media, error) {
+	var media models.Media
+	if err := r.db.Preload("Folder").Preload("Tags").First(&media, id).Error; err != nil {
+		return nil, err
+	}
+	return &media, nil
+}
+
+func (r *repository) ClaimPending(now time.Time, limit int) ([]models.Media, error) {
+	var media []models.Media
+	err := r.db.Raw(`
+		UPDATE media
+		SET processing_status = ?, processing_started_at = ?, processing_attempts = processing_attempts + 1
+		WHERE id IN (
+			SELECT id FROM media
+			WHERE processing_status = ?
+			ORDER BY id
+			LIMIT ?
+			FOR UPDATE SKIP LOCKED
+		)
+		RETURNING *`,
+		models.MediaStatusProcessing, now,
+		models.MediaStatusPending, limit,
+	).Scan(&media).Error
+	return media, err
+}
+
+func (r *repository) ReleaseStale(before time.Time) (int64, error) {
+	result := r.db.Model(&models.Media{}).
+		Where("processing_status = ? AND processing_started_at < ?", models.MediaStatusProcessing, before).
+		Updates(map[string]interface{}{
+			"processing_status":     models.MediaStatusPending,
+			"processing_started_at": nil,
+		})
+	return result.RowsAffected, result.Error
+}
+
+func (r *repository) SaveProcessing(media *models.Media) error {
+	return r.db.Model(&models.Media{}).Where("id = ?", media.ID).Updates(map[string]interface{}{
+		"file_size":             media.FileSize,
+		"width":                 media.Width,
+		"height":                media.Height,
+		"variants":              media.Variants,
+		"processing_status":     media.ProcessingStatus,
+		"processing_error":      media.ProcessingError,
+		"processing_started_at": nil,
+		"processed_at":          media.ProcessedAt,
+	}).Error
+}
+
+func (r *repository) FindByURL(url string) (*models.Media, error) {
+	var media models.Media
+	if err := r.db.Where("file_url = ?", url).First(&media).Error; err != nil {
+		return nil, err
+	}
+	return &media, nil
+}
+
+func (r *repository) Delete(id uint) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		media := models.Media{ID: id}
+		if err := tx.Model(&media).Association("Tags").Clear(); err != nil {
+			return err
+		}
+		return tx.Delete(&media).Error
+	})
+}
+
+func (r *repository) ListForMigration(notStorage string, afterID uint, limit int) ([]models.Media, error) {
+	var media []models.Media
+	err := r.db.Where("storage <> ? AND private = ? AND id > ?", notStorage, false, afterID).
+		Order("id").
+		Limit(limit).
+		Find(&media).Error
+	return media, err
+}
+
+func (r *repository) ListAfter(afterID uint, limit int) ([]models.Media, error) {
+	var media []models.Media
+	err := r.db.Where("id > ?", afterID).Order("id").Limit(limit).Find(&media).Error
+	return media, err
+}
+
+func (r *repository) SaveStorage(media *models.Media, urls map[string]string) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&models.Media{}).Where("id = ?", media.ID).Updates(map[string]interface{}{
+			"storage":     media.Storage,
+			"storage_key": media.StorageKey,
+			"file_path":   media.FilePath,
+			"file_url":    media.FileURL,
+			"variants":    media.Variants,
+			"private":     media.Private,
+		}).Error; err != nil {
+			return err
+		}
+
+		for from, to := range urls {
+			if from == to {
+				continue
+			}
+			for _, c := range urlColumns {
+				if err := tx.Table(c.table).Where(c.column+" = ?", from).Update(c.column, to).Error; err != nil {
+					return err
+				}
+			}
+			for _, table := range contentTables {
+				if err := tx.Exec("UPDATE "+table+" SET content = replace(content, ?, ?) WHERE strpos(content, ?) > 0", from, to, from).Error; err != nil {
+					return err
+				}
+				if err := tx.Exec("UPDATE "+table+" SET seo = jsonb_set(seo, '{ogImage}', to_jsonb(?::text)) WHERE seo->>'ogImage' = ?", to, from).Error; err != nil {
+					return err
+				}
+			}
+		}
+		return nil
+	})
+}
+
+// listColumns whitelists the list query fields of List
+var listColumns = scopes.ListColumns{
+	Sort: map[string]string{
+		"uploadedAt":   "media.uploaded_at",
+		"originalName": "media.original_name",
+		"title":        "media.title",
+		"fileSize":     "media.file_size",
+	},
+	Ranges: map[string]string{
+		"uploadedAt": "media.uploaded_at",
+	},
+	Filters:      []string{"type", "mimeType", "folder", "tag", "storage"},
+	DefaultOrder: "media.uploaded_at DESC",
+	Key:          "media.id",
+}
+
+func (r *repository) List(q dto.ListQuery, search string, withPrivate bool, limit, offset int) ([]models.Media, int64, error) {
+	var media []models.Media
+	var total int64
+
+	ranges, order, err := listColumns.Apply(q)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	query := r.db.Model(&models.Media{}).Scopes(ranges)
+	if !withPrivate {
+		query = query.Where("media.private = ?", false)
+	}
+	if types := q.Filter("type"); len(types) > 0 {
+		query = query.Where("media.file_type IN ?", scopes.Lower(types))
+	}
+	if mimeTypes := q.Filter("mimeType"); len(mimeTypes) > 0 {
+		query = query.Where("lower(media.mime_type) IN ?", scopes.Lower(mimeTypes))
+	}
+	if folders := q.Filter("folder"); len(folders) > 0 {
+		query = query.Where("media.folder_id IN (SELECT f.id FROM media_folders f WHERE f.path IN ? OR f.id::text IN ?)", folders, folders)
+	}
+	if tags := q.Filter("tag"); len(tags) > 0 {
+		query = query.Where("EXISTS (SELECT 1 FROM media_tags mt JOIN tags t ON t.id = mt.tag_id WHERE mt.media_id = media.id AND (t.slug IN ? OR lower(t.name) IN ?))", tags, scopes.Lower(tags))
+	}
+	if storages := q.Filter("storage"); len(storages) > 0 {
+		query = query.Where("media.storage IN ?", storages)
+	}
+	if search = strings.TrimSpace(search); search != "" {
+		pattern := "%" + likeEscape(strings.ToLower(search)) + "%"
+		query = query.Where("(lower(media.original_name) LIKE ? OR lower(media.title) LIKE ? OR lower(media.alt_text) LIKE ? OR lower(media.caption) LIKE ? OR lower(media.credit) LIKE ?)",
+			pattern, pattern, pattern, pattern, pattern)
+	}
+
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+	err = query.Preload("Folder").Preload("Tags").Order(order).
+		Limit(limit).Offset(offset).Find(&media).Error
+	return media, total, err
+}
+
+func (r *repository) UpdateMetadata(media *models.Media, tags []models.Tag) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&models.Media{}).Where("id = ?", media.ID).Updates(map[string]interface{}{
+			"title":     media.Title,
+			"alt_text":  media.AltText,
+			"caption":   media.Caption,
+			"credit":    media.Credit,
+			"folder_id": media.FolderID,
+		}).Error; err != nil {
+			return err
+		}
+		if tags == nil {
+			return nil
+		}
+		return tx.Model(media).Association("Tags").Replace(tags)
+	})
+}
+
+// usageSource is a place media URLs are referenced from. Its query returns
+// the entity id and title. Sources with a column search it for any of the
+// URLs, the query ending in WHERE; the others match the URLs exactly.
+type usageSource struct {
+	entity, field, query string
+	column               string
+}
+
+// usageSources are searched for every media item. Revisions count as usage
+// so restoring one does not bring back a dead link.
+var usageSources = []usageSource{
+	{"article", "featuredImage", "SELECT id::text AS id, title FROM articles WHERE featured_image_url IN ?", ""},
+	{"article", "ogImage", "SELECT id::text AS id, title FROM articles WHERE seo->>'ogImage' IN ?", ""},
+	{"article", "content", "SELECT id::text AS id, title FROM articles WHERE", "content"},
+	{"article", "gallery", "SELECT DISTINCT a.id::text AS id, a.title FROM article_images i JOIN articles a ON a.id = i.article_id WHERE i.url IN ?", ""},
+	{"article", "videos", "SELECT DISTINCT a.id::text AS id, a.title FROM article_videos v JOIN articles a ON a.id = v.article_id WHERE v.url IN ?", ""},
+	{"project", "thumbnail", "SELECT id::text AS id, title FROM projects WHERE thumbnail_url IN ?", ""},
+	{"project", "ogImage", "SELECT id::text AS id, title FROM projects WHERE seo->>'ogImage' IN ?", ""},
+	{"project", "content", "SELECT id::text AS id, title FROM projects WHERE", "content"},
+	{"project", "gallery", "SELECT DISTINCT p.id::text AS id, p.title FROM project_images i JOIN projects p ON p.id = i.project_id WHERE i.url IN ?", ""},
+	{"project", "videos", "SELECT DISTINCT p.id::text AS id, p.title FROM project_videos v JOIN projects p ON p.id = v.project_id WHERE v.url IN ?", ""},
+	{"experience", "logo", "SELECT id::text AS id, title FROM experiences WHERE logo_url IN ?", ""},
+	{"experience", "description", "SELECT id::text AS id, title FROM experiences WHERE", "description"},
+	{"experience", "gallery", "SELECT DISTINCT e.id::text AS id, e.title FROM experience_images i JOIN experiences e ON e.id = i.experience_id WHERE i.url IN ?", ""},
+	{"page", "ogImage", "SELECT id::text AS id, title FROM pages WHERE seo->>'ogImage' IN ?", ""},
+	{"page", "content", "SELECT id::text AS id, title FROM pages WHERE", "content"},
+	{"setting", "value", "SELECT key AS id, key AS title FROM settings WHERE", "value"},
+	{"post", "featuredImage", "SELECT id::text AS id, title FROM posts WHERE featured_image_url IN ?", ""},
+	{"post", "content", "SELECT id::text AS id, title FROM posts WHERE", "content"},
+	{"article", "revision", "SELECT DISTINCT ON (entity_id) entity_id AS id, title FROM article_revisions WHERE", "snapshot::text"},
+	{"project", "revision", "SELECT DISTINCT ON (entity_id) entity_id AS id, title FROM project_revisions WHERE", "snapshot::text"},
+	{"page", "revision", "SELECT DISTINCT ON (entity_id) entity_id AS id, title FROM page_revisions WHERE", "snapshot::text"},
+}
+
+// optionalUsageSources are searched where their table exists; no migration
+// creates these tables, but the models do where they were auto-migrated
+var optionalUsageSources = map[string][]usageSource{
+	"post_images": {{"post", "gallery", "SELECT DISTINCT p.id::text AS id, p.title FROM post_images i JOIN posts p ON p.id = i.post_id WHERE i.url IN ?", ""}},
+	"post_videos": {{"post", "videos", "SELECT DISTINCT p.id::text AS id, p.title FROM post_videos v JOIN posts p ON p.id = v.post_id WHERE v.url IN ?", ""}},
+	"menus":       {{"menu", "url", "SELECT id::text AS id, name AS title FROM menus WHERE url IN ?", ""}},
+}
+
+func (r *repository) FindUsages(urls []string) ([]dto.MediaUsage, error) {
+	usages := []dto.MediaUsage{}
+	if len(urls) == 0 {
+		return usages, nil
+	}
+	patterns := make([]interface{}, len(urls))
+	for i, u := range urls {
+		patterns[i] = "%" + likeEscape(u) + "%"
+	}
+
+	tables := make([]string, 0, len(optionalUsageSources))
+	for table := range optionalUsageSources {
+		tables = append(tables, table)
+	}
+	var existing []string
+	if err := r.db.Raw("SELECT table_name FROM information_schema.tables WHERE table_schema = current_schema() AND table_name IN ?", tables).
+		Scan(&existing).Error; err != nil {
+		return nil, err
+	}
+	sources := append([]usageSource{}, usageSources...)
+	for _, table := range existing {
+		sources = append(sources, optionalUsageSources[table]...)
+	}
+
+	for _, src := range sources {
+		query, args := src.query, []interface{}{urls}
+		if src.column != "" {
+			like := make([]string, len(urls))
+			for i := range like {
+				like[i] = src.column + " LIKE ?"
+			}
+			query, args = query+" ("+strings.Join(like, " OR ")+")", patterns
+		}
+		var rows []struct {
+			ID    string
+			Title string
+		}
+		if err := r.db.Raw(query, args...).Scan(&rows).Error; err != nil {
+			return nil, fmt.Errorf("%s %s: %w", src.entity, src.field, err)
+		}
+		for _, row := range rows {
+			usages = append(usages, dto.MediaUsage{EntityType: src.entity, EntityID: row.ID, Title: row.Title, Field: src.field})
+		}
+	}
+	return usages, nil
+}
+
+func (r *repository) ListFolders() ([]models.MediaFolder, error) {
+	var folders []models.MediaFolder
+	err := r.db.Order("path").Find(&folders).Error
+	return folders, err
+}
+
+func (r *repository) FindFolder(id uint) (*models.MediaFolder, error) {
+	var folder models.MediaFolder
+	if err := r.db.First(&folder, id).Error; err != nil {
+		return nil, err
+	}
+	return &folder, nil
+}
+
+func (r *repository) FindFolderByPath(path string) (*models.MediaFolder, error) {
+	var folder models.MediaFolder
+	if err := r.db.Where("path = ?", path).First(&folder).Error; err != nil {
+		return nil, err
+	}
+	return &folder, nil
+}
+
+func (r *repository) CreateFolder(folder *models.MediaFolder) error {
+	return r.db.Create(folder).Error
+}
+
+func (r *repository) UpdateFolder(folder *models.MediaFolder, oldPath string) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(folder).Updates(map[string]interface{}{
+			"name":      folder.Name,
+			"parent_id": folder.ParentID,
+			"path":      folder.Path,
+		}).Error; err != nil {
+			return err
+		}
+		if folder.Path == oldPath {
+			return nil
+		}
+		return tx.Exec("UPDATE media_folders SET path = ? || substr(path, ?), updated_at = ? WHERE path LIKE ?",
+			folder.Path, utf8.RuneCountInString(oldPath)+1, time.Now(), likeEscape(oldPath)+"/%").Error
+	})
+}
+
+func (r *repository) DeleteFolder(id uint) error {
+	return r.db.Delete(&models.MediaFolder{}, id).Error
+}
+
+func (r *repository) CountFolderContents(id uint) (int64, error) {
+	var count int64
+	err := r.db.Raw("SELECT (SELECT COUNT(*) FROM media WHERE folder_id = ?) + (SELECT COUNT(*) FROM media_folders WHERE parent_id = ?)", id, id).
+		Scan(&count).Error
+	return count, err
+}
+
+// likeEscape escapes the LIKE wildcards in s
+func likeEscape(s string) string {
+	return strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`).Replace(s)
+}
+
+// uploadUsageQuery sums the media of each user; variant sizes come from the
+// variants jsonb
+const uploadUsageQuery = `
+SELECT u.id AS user_id, u.username, u.upload_quota_mb,
+	COUNT(m.id) AS files,
+	COALESCE(SUM(m.file_size), 0) AS bytes,
+	COALESCE(SUM(vs.size), 0) AS variant_bytes
+FROM users u
+LEFT JOIN media m ON m.uploaded_by = u.id
+LEFT JOIN LATERAL (
+	SELECT SUM((v->>'size')::bigint) AS size FROM jsonb_array_elements(m.variants) v
+) vs ON true
+WHERE @user_id = 0 OR u.id = @user_id
+GROUP BY u.id, u.username, u.upload_quota_mb
+ORDER BY bytes DESC, u.id`
+
+func (r *repository) UploadUsage(userID uint) ([]UploadUsage, error) {
+	var rows []UploadUsage
+	err := r.db.Raw(uploadUsageQuery, map[string]interface{}{"user_id": userID}).Scan(&rows).Error
+	return rows, err
+}
+
+func (r *repository) SetUploadQuota(userID uint, quotaMB *int) error {
+	result := r.db.Model(&models.User{}).Where("id = ?", userID).Update("upload_quota_mb", quotaMB)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+func (r *repository) CreateUploadSession(session *models.UploadSession) error {
+	return r.db.Create(session).Error
+}
+
+func (r *repository) FindUploadSession(id string) (*models.UploadSession, error) {
+	var session models.UploadSession
+	if err := r.db.Where("id = ?", id).First(&session).Error; err != nil {
+		return nil, err
+	}
+	return &session, nil
+}
+
+func (r *repository) TouchUploadSession(id string, expiresAt time.Time) error {
+	return r.db.Model(&models.UploadSession{}).
+		Where("id = ? AND status = ?", id, models.UploadStatusUploading).
+		Update("expires_at", expiresAt).Error
+}
+
+func (r *repository) ClaimUploadSession(id string, deadline time.Time) (bool, error) {
+	result := r.db.Model(&models.UploadSession{}).
+		Where("id = ? AND status = ?", id, models.UploadStatusUploading).
+		Updates(map[string]interface{}{"status": models.UploadStatusCompleting, "expires_at": deadline})
+	return result.RowsAffected == 1, result.Error
+}
+
+func (r *repository) SaveUploadSession(session *models.UploadSession) error {
+	return r.db.Model(&models.UploadSession{}).Where("id = ?", session.ID).Updates(map[string]interface{}{
+		"status":     session.Status,
+		"media_id":   session.MediaID,
+		"expires_at": session.ExpiresAt,
+	}).Error
+}
+
+func (r *repository) DeleteUploadSession(id string) error {
+	return r.db.Where("id = ?", id).Delete(&models.UploadSession{}).Error
+}
+
+func (r *repository) ListExpiredUploadSessions(now time.Time, limit int) ([]models.UploadSession, error) {
+	var sessions []models.UploadSession
+	err := r.db.Where("expires_at < ?", now).Order("expires_at").Limit(limit).Find(&sessions).Error
+	return sessions, err
+}
@@ -0,0 +1,114 @@
+package media
+
+import (
+	"database/sql/driver"
+	"fmt"
+	"regexp"
+	"strings"
+	"testing"
+	"web-porto-backend/internal/testutil/fakesql"
+)
+
+var likeParam = regexp.MustCompile(`([a-z_.]+) LIKE \$(\d+)`)
+
+func TestFindUsagesBindsEachURLPattern(t *testing.T) {
+	db, fake := fakesql.Open(t, func(query string, _ []driver.NamedValue) ([]string, [][]driver.Value) {
+		if strings.Contains(query, "FROM pages WHERE (content LIKE") {
+			return []string{"id", "title"}, [][]driver.Value{{"7", "About"}}
+		}
+		return nil, nil
+	})
+	urls := []string{"/uploads/a_1.jpg", "/uploads/a_1-thumb.jpg", "/uploads/a_1-medium.jpg"}
+
+	usages, err := NewRepository(db).FindUsages(urls)
+	if err != nil {
+		t.Fatalf("FindUsages: %v", err)
+	}
+	if len(usages) != 1 || usages[0].EntityType != "page" || usages[0].EntityID != "7" || usages[0].Field != "content" {
+		t.Fatalf("usages = %+v", usages)
+	}
+
+	searches := 0
+	for _, st := range fake.Statements() {
+		if strings.Contains(st.Query, "ARRAY") {
+			t.Errorf("query binds an array expression: %s", st.Query)
+		}
+		matches := likeParam.FindAllStringSubmatch(st.Query, -1)
+		if len(matches) == 0 {
+			continue
+		}
+		searches++
+		if len(matches) != len(urls) || len(st.Args) != len(urls) {
+			t.Errorf("query has %d LIKE conditions and %d args for %d URLs: %s", len(matches), len(st.Args), len(urls), st.Query)
+			continue
+		}
+		for i, m := range matches {
+			want := "%" + strings.ReplaceAll(urls[i], "_", `\_`) + "%"
+			if m[2] != fmt.Sprint(i+1) || st.Args[i].Value != want {
+				t.Errorf("condition %s LIKE $%s bound to %v, want $%d = %q", m[1], m[2], st.Args[i].Value, i+1, want)
+			}
+		}
+	}
+	if searches == 0 {
+		t.Fatal("no content searches were run")
+	}
+}
+
+func TestDeleteClearsTagsFirst(t *testing.T) {
+	db, fake := fakesql.Open(t, nil)
+
+	if err := NewRepository(db).Delete(4); err != nil {
+		t.Fatalf("Delete: %v", err)
+	}
+
+	var got []string
+	for _, st := range fake.Statements() {
+		got = append(got, strings.Fields(st.Query)[0]+" "+tableOf(st.Query))
+	}
+	want := "BEGIN , DELETE media_tags, DELETE media, COMMIT "
+	if strings.Join(got, ", ") != want {
+		t.Fatalf("statements = %q, want %q", strings.Join(got, ", "), want)
+	}
+}
+
+// tableOf is the table a DELETE statement removes rows from
+func tableOf(query string) string {
+	_, rest, ok := strings.Cut(query, "FROM ")
+	if !ok {
+		return ""
+	}
+	return strings.Trim(strings.Fields(rest)[0], `"`)
+}
+
+func TestFindUsagesSearchesOptionalTablesThatExist(t *testing.T) {
+	db, fake := fakesql.Open(t, func(query string, _ []driver.NamedValue) ([]string, [][]driver.Value) {
+		switch {
+		case strings.Contains(query, "information_schema.tables"):
+			return []string{"table_name"}, [][]driver.Value{{"menus"}}
+		case strings.Contains(query, "FROM menus"):
+			return []string{"id", "title"}, [][]driver.Value{{"3", "Brochure"}}
+		case strings.Contains(query, "FROM page_revisions"):
+			return []string{"id", "title"}, [][]driver.Value{{"9", "About (old)"}}
+		}
+		return nil, nil
+	})
+
+	usages, err := NewRepository(db).FindUsages([]string{"/uploads/brochure.pdf"})
+	if err != nil {
+		t.Fatalf("FindUsages: %v", err)
+	}
+	got := map[string]bool{}
+	for _, u := range usages {
+		got[u.EntityType+" "+u.Field+" "+u.EntityID] = true
+	}
+	if len(usages) != 2 || !got["menu url 3"] || !got["page revision 9"] {
+		t.Fatalf("usages = %+v", usages)
+	}
+	for _, st := range fake.Statements() {
+		if strings.Contains(st.Query, "post_images") || strings.Contains(st.Query, "post_videos") {
+			if !strings.Contains(st.Query, "information_schema") {
+				t.Fatalf("searched a missing table: %s", st.Query)
+			}
+		}
+	}
+}
@@ -2,6 +2,7 @@ package page
 
 import (
 	"web-porto-backend/internal/domain/models"
+	"web-porto-backend/internal/repositories/scopes"
 
 	"gorm.io/gorm"
 )
@@ -9,11 +10,11 @@ import (
 type Repository interface {
 	Create(page *models.Page) error
 	GetByID(id uint) (*models.Page, error)
-	GetAll(limit, offset int) ([]*models.Page, int64, error)
+	// GetAll lists pages with the given status (see scopes.Status)
+	GetAll(status string, limit, offset int) ([]*models.Page, int64, error)
 	Update(page *models.Page) error
 	Delete(id uint) error
 	GetBySlug(slug string) (*models.Page, error)
-	GetPublished(limit, offset int) ([]*models.Page, int64, error)
 }
 
 type repository struct {
@@ -37,15 +38,17 @@ func (r *repository) GetByID(id uint) (*models.Page, error) {
 	return &page, nil
 }
 
-func (r *repository) GetAll(limit, offset int) ([]*models.Page, int64, error) {
+func (r *repository) GetAll(status string, limit, offset int) ([]*models.Page, int64, error) {
 	var pages []*models.Page
 	var total int64
 
+	query := r.db.Model(&models.Page{}).Scopes(scopes.Status("pages", status))
+
 	// Count total records
-	r.db.Model(&models.Page{}).Count(&total)
+	query.Count(&total)
 
 	// Get paginated results
-	err := r.db.Limit(limit).Offset(offset).Find(&pages).Error
+	err := query.Limit(limit).Offset(offset).Find(&pages).Error
 
 	return pages, total, err
 }
@@ -66,15 +69,3 @@ func (r *repository) GetBySlug(slug string) (*models.Page, error) {
 	}
 	return &page, nil
 }
-
-func (r *repository) GetPublished(limit, offset int) ([]*models.Page, int64, error) {
-	var pages []*models.Page
-	var total int64
-
-	query := r.db.Model(&models.Page{}).Where("status = ?", "published")
-	query.Count(&total)
-
-	err := query.Limit(limit).Offset(offset).Find(&pages).Error
-
-	return pages, total, err
-}
@@ -2,6 +2,7 @@ package post
 
 import (
 	"web-porto-backend/internal/domain/models"
+	"web-porto-backend/internal/repositories/scopes"
 
 	"gorm.io/gorm"
 )
@@ -9,12 +10,12 @@ import (
 type Repository interface {
 	Create(post *models.Post) error
 	GetByID(id string) (*models.Post, error)
-	GetAll(limit, offset int) ([]*models.Post, int64, error)
+	// GetAll lists posts with the given status (see scopes.Status)
+	GetAll(status string, limit, offset int) ([]*models.Post, int64, error)
 	Update(post *models.Post) error
 	Delete(id string) error
 	GetBySlug(slug string) (*models.Post, error)
-	GetByAuthorID(authorID int, limit, offset int) ([]*models.Post, int64, error)
-	GetPublished(limit, offset int) ([]*models.Post, int64, error)
+	GetByAuthorID(authorID int, status string, limit, offset int) ([]*models.Post, int64, error)
 }
 
 type repository struct {
@@ -38,15 +39,17 @@ func (r *repository) GetByID(id string) (*models.Post, error) {
 	return &post, nil
 }
 
-func (r *repository) GetAll(limit, offset int) ([]*models.Post, int64, error) {
+func (r *repository) GetAll(status string, limit, offset int) ([]*models.Post, int64, error) {
 	var posts []*models.Post
 	var total int64
 
+	query := r.db.Model(&models.Post{}).Scopes(scopes.Status("posts", status))
+
 	// Count total records
-	r.db.Model(&models.Post{}).Count(&total)
+	query.Count(&total)
 
 	// Get paginated results with preloaded associations
-	err := r.db.Preload("Author").
+	err := query.Preload("Author").
 		Limit(limit).Offset(offset).Find(&posts).Error
 
 	return posts, total, err
@@ -70,24 +73,13 @@ func (r *repository) GetBySlug(slug string) (*models.Post, error) {
 	return &post, nil
 }
 
-func (r *repository) GetByAuthorID(authorID int, limit, offset int) ([]*models.Post, int64, error) {
-	var posts []*models.Post
-	var total int64
-
-	query := r.db.Model(&models.Post{}).Where("author_id = ?", authorID)
-	query.Count(&total)
-
-	err := query.Preload("Author").
-		Limit(limit).Offset(offset).Find(&posts).Error
-
-	return posts, total, err
-}
-
-func (r *repository) GetPublished(limit, offset int) ([]*models.Post, int64, error) {
+func (r *repository) GetByAuthorID(authorID int, status string, limit, offset int) ([]*models.Post, int64, error) {
 	var posts []*models.Post
 	var total int64
 
-	query := r.db.Model(&models.Post{}).Where("status = ?", "published")
+	query := r.db.Model(&models.Post{}).
+		Scopes(scopes.Status("posts", status)).
+		Where("author_id = ?", authorID)
 	query.Count(&total)
 
 	err := query.Preload("Author").
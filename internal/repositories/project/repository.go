@@ -1,7 +1,9 @@
 package project
 
 import (
+	"web-porto-backend/internal/domain/dto"
 	"web-porto-backend/internal/domain/models"
+	"web-porto-backend/internal/repositories/scopes"
 
 	"gorm.io/gorm"
 )
@@ -9,11 +11,13 @@ import (
 type Repository interface {
 	Create(project *models.Project) error
 	GetByID(id string) (*models.Project, error)
-	GetAll(limit, offset int) ([]*models.Project, int64, error)
+	// GetAll lists projects with the given status (see scopes.Status),
+	// filtered and sorted by q within listColumns
+	GetAll(status string, q dto.ListQuery, limit, offset int) ([]*models.Project, int64, error)
 	Update(project *models.Project) error
 	Delete(id string) error
 	GetBySlug(slug string) (*models.Project, error)
-	GetByCategorySlug(slug string, limit, offset int) ([]*models.Project, int64, error)
+	GetByCategorySlug(slug, status string, limit, offset int) ([]*models.Project, int64, error)
 	UpdateProjectTechnologies(projectID string, technologyIDs []int) error
 	UpdateProjectTags(projectID string, tagIDs []int) error
 	UpdateProjectCategories(projectID string, categoryIDs []int) error
@@ -54,21 +58,61 @@ func (r *repository) GetByID(id string) (*models.Project, error) {
 	return &project, nil
 }
 
-func (r *repository) GetAll(limit, offset int) ([]*models.Project, int64, error) {
+// listColumns whitelists the list query fields of GetAll. The status filter
+// arrives as GetAll's status argument.
+var listColumns = scopes.ListColumns{
+	Sort: map[string]string{
+		"publishedAt": "projects.published_at",
+		"createdAt":   "projects.created_at",
+		"updatedAt":   "projects.updated_at",
+		"title":       "projects.title",
+	},
+	Ranges: map[string]string{
+		"publishedAt": "projects.published_at",
+		"createdAt":   "projects.created_at",
+		"updatedAt":   "projects.updated_at",
+	},
+	Filters:      []string{"status", "category", "tag", "technology"},
+	DefaultOrder: "projects.created_at DESC",
+	Key:          "projects.id",
+}
+
+func (r *repository) GetAll(status string, q dto.ListQuery, limit, offset int) ([]*models.Project, int64, error) {
 	var projects []*models.Project
 	var total int64
 
+	ranges, order, err := listColumns.Apply(q)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	query := r.db.Model(&models.Project{}).Scopes(scopes.Status("projects", status), ranges)
+	if categories := q.Filter("category"); len(categories) > 0 {
+		query = query.Where(`(EXISTS (SELECT 1 FROM categories c WHERE c.id = projects.category_id AND c.slug IN ?)
+			OR EXISTS (SELECT 1 FROM project_categories pc JOIN categories c ON c.id = pc.category_id WHERE pc.project_id = projects.id AND c.slug IN ?))`, categories, categories)
+	}
+	if tags := q.Filter("tag"); len(tags) > 0 {
+		// Technologies are tags too
+		query = query.Where(`EXISTS (SELECT 1 FROM tags t WHERE (t.slug IN ? OR lower(t.name) IN ?) AND (
+			t.id IN (SELECT tag_id FROM project_tags WHERE project_id = projects.id)
+			OR t.id IN (SELECT tag_id FROM project_technologies WHERE project_id = projects.id)))`, tags, scopes.Lower(tags))
+	}
+	if technologies := q.Filter("technology"); len(technologies) > 0 {
+		query = query.Where("EXISTS (SELECT 1 FROM project_technologies pt JOIN tags t ON t.id = pt.tag_id WHERE pt.project_id = projects.id AND (t.slug IN ? OR lower(t.name) IN ?))", technologies, scopes.Lower(technologies))
+	}
+
 	// Count total records
-	r.db.Model(&models.Project{}).Count(&total)
+	query.Count(&total)
 
 	// Get paginated results with preloaded associations
-	err := r.db.Preload("Author").
+	err = query.Preload("Author").
 		Preload("Category").
 		Preload("Categories").
 		Preload("Technologies").
 		Preload("Tags").
 		Preload("Images").
 		Preload("Videos").
+		Order(order).
 		Limit(limit).Offset(offset).Find(&projects).Error
 
 	return projects, total, err
@@ -115,11 +159,12 @@ func (r *repository) GetBySlug(slug string) (*models.Project, error) {
 	return &project, nil
 }
 
-func (r *repository) GetByCategorySlug(slug string, limit, offset int) ([]*models.Project, int64, error) {
+func (r *repository) GetByCategorySlug(slug, status string, limit, offset int) ([]*models.Project, int64, error) {
 	var projects []*models.Project
 	var total int64
 
 	query := r.db.Model(&models.Project{}).
+		Scopes(scopes.Status("projects", status)).
 		Joins("JOIN project_categories pc ON pc.project_id = projects.id").
 		Joins("JOIN categories c ON c.id = pc.category_id").
 		Where("c.slug = ?", slug)
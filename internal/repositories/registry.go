@@ -1,46 +1,87 @@
 ﻿package repositories
 
 import (
+	"web-porto-backend/internal/domain/models"
 	analyticsRepo "web-porto-backend/internal/repositories/analytics"
+	apiTokenRepo "web-porto-backend/internal/repositories/apitoken"
 	articleRepo "web-porto-backend/internal/repositories/article"
+	auditRepo "web-porto-backend/internal/repositories/audit"
 	categoryRepo "web-porto-backend/internal/repositories/category"
 	commentRepo "web-porto-backend/internal/repositories/comment"
 	experienceRepo "web-porto-backend/internal/repositories/experience"
+	invitationRepo "web-porto-backend/internal/repositories/invitation"
+	loginAttemptRepo "web-porto-backend/internal/repositories/loginattempt"
+	mediaRepo "web-porto-backend/internal/repositories/media"
 	pageRepo "web-porto-backend/internal/repositories/page"
 	projectRepo "web-porto-backend/internal/repositories/project"
+	revisionRepo "web-porto-backend/internal/repositories/revision"
+	roleRepo "web-porto-backend/internal/repositories/role"
+	scheduleRepo "web-porto-backend/internal/repositories/schedule"
+	searchRepo "web-porto-backend/internal/repositories/search"
 	settingRepo "web-porto-backend/internal/repositories/setting"
+	sitemapRepo "web-porto-backend/internal/repositories/sitemap"
 	tagRepo "web-porto-backend/internal/repositories/tag"
+	tokenRepo "web-porto-backend/internal/repositories/token"
+	twoFactorRepo "web-porto-backend/internal/repositories/twofactor"
 	userRepo "web-porto-backend/internal/repositories/user"
 
 	"gorm.io/gorm"
 )
 
 type RepositoryRegistry struct {
-	AnalyticsRepository  analyticsRepo.Repository
-	ArticleRepository    articleRepo.Repository
-	CategoryRepository   categoryRepo.Repository
-	CommentRepository    commentRepo.Repository
-	ExperienceRepository experienceRepo.Repository
-	UserRepository       userRepo.Repository
-	PageRepository       pageRepo.Repository
-	ProjectRepository    projectRepo.Repository
-	SettingRepository    settingRepo.Repository
-	TagRepository        tagRepo.Repository
-	DB                   *gorm.DB
+	APITokenRepository        apiTokenRepo.Repository
+	AnalyticsRepository       analyticsRepo.Repository
+	AuditRepository           auditRepo.Repository
+	ArticleRepository         articleRepo.Repository
+	ArticleRevisionRepository revisionRepo.Repository
+	CategoryRepository        categoryRepo.Repository
+	CommentRepository         commentRepo.Repository
+	ExperienceRepository      experienceRepo.Repository
+	InvitationRepository      invitationRepo.Repository
+	LoginAttemptRepository    loginAttemptRepo.Repository
+	MediaRepository           mediaRepo.Repository
+	UserRepository            userRepo.Repository
+	PageRepository            pageRepo.Repository
+	PageRevisionRepository    revisionRepo.Repository
+	ProjectRepository         projectRepo.Repository
+	ProjectRevisionRepository revisionRepo.Repository
+	RoleRepository            roleRepo.Repository
+	ScheduleRepository        scheduleRepo.Repository
+	SearchRepository          searchRepo.Repository
+	SettingRepository         settingRepo.Repository
+	SitemapRepository         sitemapRepo.Repository
+	TagRepository             tagRepo.Repository
+	TokenRepository           tokenRepo.Repository
+	TwoFactorRepository       twoFactorRepo.Repository
+	DB                        *gorm.DB
 }
 
 func NewRepositoryRegistry(db *gorm.DB) *RepositoryRegistry {
 	return &RepositoryRegistry{
-		AnalyticsRepository:  analyticsRepo.NewRepository(db),
-		ArticleRepository:    articleRepo.NewRepository(db),
-		CategoryRepository:   categoryRepo.NewRepository(db),
-		CommentRepository:    commentRepo.NewRepository(db),
-		ExperienceRepository: experienceRepo.NewRepository(db),
-		UserRepository:       userRepo.NewRepository(db),
-		PageRepository:       pageRepo.NewRepository(db),
-		ProjectRepository:    projectRepo.NewRepository(db),
-		SettingRepository:    settingRepo.NewRepository(db),
-		TagRepository:        tagRepo.NewRepository(db),
-		DB:                   db,
+		APITokenRepository:        apiTokenRepo.NewRepository(db),
+		AnalyticsRepository:       analyticsRepo.NewRepository(db),
+		AuditRepository:           auditRepo.NewRepository(db),
+		ArticleRepository:         articleRepo.NewRepository(db),
+		ArticleRevisionRepository: revisionRepo.NewRepository(db, models.ArticleRevisionTable),
+		CategoryRepository:        categoryRepo.NewRepository(db),
+		CommentRepository:         commentRepo.NewRepository(db),
+		ExperienceRepository:      experienceRepo.NewRepository(db),
+		InvitationRepository:      invitationRepo.NewRepository(db),
+		LoginAttemptRepository:    loginAttemptRepo.NewRepository(db),
+		MediaRepository:           mediaRepo.NewRepository(db),
+		UserRepository:            userRepo.NewRepository(db),
+		PageRepository:            pageRepo.NewRepository(db),
+		PageRevisionRepository:    revisionRepo.NewRepository(db, models.PageRevisionTable),
+		ProjectRepository:         projectRepo.NewRepository(db),
+		ProjectRevisionRepository: revisionRepo.NewRepository(db, models.ProjectRevisionTable),
+		RoleRepository:            roleRepo.NewRepository(db),
+		ScheduleRepository:        scheduleRepo.NewRepository(db),
+		SearchRepository:          searchRepo.NewRepository(db),
+		SettingRepository:         settingRepo.NewRepository(db),
+		SitemapRepository:         sitemapRepo.NewRepository(db),
+		TagRepository:             tagRepo.NewRepository(db),
+		TokenRepository:           tokenRepo.NewRepository(db),
+		TwoFactorRepository:       twoFactorRepo.NewRepository(db),
+		DB:                        db,
 	}
 }
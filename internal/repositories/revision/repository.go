@@ -0,0 +1,63 @@
+package revision
+
+import (
+	"web-porto-backend/internal/domain/models"
+
+	"gorm.io/gorm"
+)
+
+type Repository interface {
+	Create(revision *models.Revision) error
+	FindByID(entityID string, id int) (*models.Revision, error)
+	FindByEntity(entityID string, offset, limit int) ([]models.Revision, int64, error)
+	LatestVersion(entityID string) (int, error)
+}
+
+// repository stores revisions of one content type in its own table
+type repository struct {
+	db    *gorm.DB
+	table string
+}
+
+// NewRepository returns a repository backed by the given revision table,
+// e.g. models.ArticleRevisionTable
+func NewRepository(db *gorm.DB, table string) Repository {
+	return &repository{db: db, table: table}
+}
+
+func (r *repository) Create(revision *models.Revision) error {
+	return r.db.Table(r.table).Create(revision).Error
+}
+
+func (r *repository) FindByID(entityID string, id int) (*models.Revision, error) {
+	var revision models.Revision
+	err := r.db.Table(r.table).
+		Where("id = ? AND entity_id = ?", id, entityID).
+		First(&revision).Error
+	if err != nil {
+		return nil, err
+	}
+	return &revision, nil
+}
+
+func (r *repository) FindByEntity(entityID string, offset, limit int) ([]models.Revision, int64, error) {
+	query := r.db.Table(r.table).Where("entity_id = ?", entityID)
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var revisions []models.Revision
+	err := query.Order("version DESC").Offset(offset).Limit(limit).Find(&revisions).Error
+	return revisions, total, err
+}
+
+func (r *repository) LatestVersion(entityID string) (int, error) {
+	var version int
+	err := r.db.Table(r.table).
+		Where("entity_id = ?", entityID).
+		Select("COALESCE(MAX(version), 0)").
+		Scan(&version).Error
+	return version, err
+}
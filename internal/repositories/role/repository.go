@@ -0,0 +1,131 @@
+package role
+
+import (
+	"web-porto-backend/internal/domain/models"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type Repository interface {
+	FindAll() ([]models.Role, error)
+	FindByID(id int) (*models.Role, error)
+	FindByName(name string) (*models.Role, error)
+	Create(role *models.Role) error
+	Update(role *models.Role, permissions []string) error
+	Delete(id int) error
+	AssignToUser(userID, roleID int) error
+	RemoveFromUser(userID, roleID int) error
+	FindUserIDsByRole(roleID int) ([]int, error)
+	FindRolesByUser(userID int) ([]models.Role, error)
+	PermissionsForUser(userID int, primaryRole string) ([]string, error)
+}
+
+type repository struct {
+	db *gorm.DB
+}
+
+func NewRepository(db *gorm.DB) Repository {
+	return &repository{db: db}
+}
+
+func (r *repository) FindAll() ([]models.Role, error) {
+	var roles []models.Role
+	err := r.db.Preload("Permissions").Order("name ASC").Find(&roles).Error
+	return roles, err
+}
+
+func (r *repository) FindByID(id int) (*models.Role, error) {
+	var role models.Role
+	if err := r.db.Preload("Permissions").First(&role, id).Error; err != nil {
+		return nil, err
+	}
+	return &role, nil
+}
+
+func (r *repository) FindByName(name string) (*models.Role, error) {
+	var role models.Role
+	if err := r.db.Preload("Permissions").Where("name = ?", name).First(&role).Error; err != nil {
+		return nil, err
+	}
+	return &role, nil
+}
+
+func (r *repository) Create(role *models.Role) error {
+	return r.db.Create(role).Error
+}
+
+// Update saves role fields and replaces its permission set.
+func (r *repository) Update(role *models.Role, permissions []string) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(role).Select("name", "description", "updated_at").Updates(role).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("role_id = ?", role.ID).Delete(&models.RolePermission{}).Error; err != nil {
+			return err
+		}
+		if len(permissions) == 0 {
+			role.Permissions = nil
+			return nil
+		}
+		rows := make([]models.RolePermission, 0, len(permissions))
+		for _, p := range permissions {
+			rows = append(rows, models.RolePermission{RoleID: role.ID, Permission: p})
+		}
+		if err := tx.Create(&rows).Error; err != nil {
+			return err
+		}
+		role.Permissions = rows
+		return nil
+	})
+}
+
+func (r *repository) Delete(id int) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("role_id = ?", id).Delete(&models.UserRole{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("role_id = ?", id).Delete(&models.RolePermission{}).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&models.Role{}, id).Error
+	})
+}
+
+func (r *repository) AssignToUser(userID, roleID int) error {
+	return r.db.Clauses(clause.OnConflict{DoNothing: true}).
+		Create(&models.UserRole{UserID: userID, RoleID: roleID}).Error
+}
+
+func (r *repository) RemoveFromUser(userID, roleID int) error {
+	return r.db.Where("user_id = ? AND role_id = ?", userID, roleID).Delete(&models.UserRole{}).Error
+}
+
+func (r *repository) FindUserIDsByRole(roleID int) ([]int, error) {
+	var ids []int
+	err := r.db.Model(&models.UserRole{}).Where("role_id = ?", roleID).Pluck("user_id", &ids).Error
+	return ids, err
+}
+
+func (r *repository) FindRolesByUser(userID int) ([]models.Role, error) {
+	var roles []models.Role
+	err := r.db.Preload("Permissions").
+		Where("id IN (?)", r.db.Model(&models.UserRole{}).Select("role_id").Where("user_id = ?", userID)).
+		Order("name ASC").
+		Find(&roles).Error
+	return roles, err
+}
+
+// PermissionsForUser resolves the distinct permissions granted by the user's
+// primary role (users.role) and every role assigned through user_roles.
+func (r *repository) PermissionsForUser(userID int, primaryRole string) ([]string, error) {
+	var permissions []string
+	err := r.db.Model(&models.RolePermission{}).
+		Distinct("role_permissions.permission").
+		Joins("JOIN roles ON roles.id = role_permissions.role_id").
+		Where("roles.name = ? OR roles.id IN (?)", primaryRole,
+			r.db.Model(&models.UserRole{}).Select("role_id").Where("user_id = ?", userID)).
+		Order("role_permissions.permission").
+		Pluck("role_permissions.permission", &permissions).Error
+	return permissions, err
+}
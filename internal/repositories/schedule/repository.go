@@ -0,0 +1,153 @@
+package schedule
+
+import (
+	"time"
+	"web-porto-backend/internal/domain/models"
+
+	"gorm.io/gorm"
+)
+
+// JobFilter narrows FindAll results; zero values are ignored
+type JobFilter struct {
+	EntityType string
+	EntityID   string
+	Action     string
+	Status     string
+	// EntityTypes, when not nil, limits results to these content types
+	EntityTypes []string
+}
+
+type Repository interface {
+	Create(job *models.ScheduledJob) error
+	FindByID(id int) (*models.ScheduledJob, error)
+	FindAll(filter JobFilter, offset, limit int) ([]models.ScheduledJob, int64, error)
+	// CancelPending cancels pending jobs of an item; an empty action matches all
+	CancelPending(entityType, entityID, action string) error
+	// CancelJob cancels a pending job, reporting false when it is no longer
+	// pending
+	CancelJob(id int) (bool, error)
+	// ClaimDue marks up to limit due jobs as running for workerID and returns
+	// them. Rows locked by another worker are skipped.
+	ClaimDue(workerID string, now time.Time, limit int) ([]models.ScheduledJob, error)
+	// ReleaseStale puts jobs locked before the cutoff back to pending
+	ReleaseStale(before time.Time) (int64, error)
+	// Finish stores the outcome of a job run by workerID, reporting false when
+	// the job was released and is no longer that worker's to finish
+	Finish(workerID string, job *models.ScheduledJob) (bool, error)
+}
+
+type repository struct {
+	db *gorm.DB
+}
+
+func NewRepository(db *gorm.DB) Repository {
+	return &repository{db: db}
+}
+
+func (r *repository) Create(job *models.ScheduledJob) error {
+	return r.db.Create(job).Error
+}
+
+func (r *repository) FindByID(id int) (*models.ScheduledJob, error) {
+	var job models.ScheduledJob
+	if err := r.db.First(&job, id).Error; err != nil {
+		return nil, err
+	}
+	return &job, nil
+}
+
+func (r *repository) FindAll(filter JobFilter, offset, limit int) ([]models.ScheduledJob, int64, error) {
+	query := r.db.Model(&models.ScheduledJob{})
+	if filter.EntityType != "" {
+		query = query.Where("entity_type = ?", filter.EntityType)
+	}
+	if filter.EntityID != "" {
+		query = query.Where("entity_id = ?", filter.EntityID)
+	}
+	if filter.Action != "" {
+		query = query.Where("action = ?", filter.Action)
+	}
+	if filter.Status != "" {
+		query = query.Where("status = ?", filter.Status)
+	}
+	if filter.EntityTypes != nil {
+		query = query.Where("entity_type IN ?", filter.EntityTypes)
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var jobs []models.ScheduledJob
+	err := query.Order("run_at ASC, id ASC").Offset(offset).Limit(limit).Find(&jobs).Error
+	return jobs, total, err
+}
+
+func (r *repository) CancelPending(entityType, entityID, action string) error {
+	query := r.db.Model(&models.ScheduledJob{}).
+		Where("entity_type = ? AND entity_id = ? AND status = ?", entityType, entityID, models.JobStatusPending)
+	if action != "" {
+		query = query.Where("action = ?", action)
+	}
+	return query.Updates(map[string]interface{}{
+		"status":     models.JobStatusCancelled,
+		"updated_at": time.Now(),
+	}).Error
+}
+
+func (r *repository) CancelJob(id int) (bool, error) {
+	result := r.db.Model(&models.ScheduledJob{}).
+		Where("id = ? AND status = ?", id, models.JobStatusPending).
+		Updates(map[string]interface{}{
+			"status":     models.JobStatusCancelled,
+			"updated_at": time.Now(),
+		})
+	return result.RowsAffected == 1, result.Error
+}
+
+func (r *repository) ClaimDue(workerID string, now time.Time, limit int) ([]models.ScheduledJob, error) {
+	var jobs []models.ScheduledJob
+	err := r.db.Raw(`
+		UPDATE scheduled_jobs
+		SET status = ?, locked_by = ?, locked_at = ?, attempts = attempts + 1, updated_at = ?
+		WHERE id IN (
+			SELECT id FROM scheduled_jobs
+			WHERE status = ? AND run_at <= ?
+			ORDER BY run_at, id
+			LIMIT ?
+			FOR UPDATE SKIP LOCKED
+		)
+		RETURNING *`,
+		models.JobStatusRunning, workerID, now, now,
+		models.JobStatusPending, now, limit,
+	).Scan(&jobs).Error
+	return jobs, err
+}
+
+func (r *repository) ReleaseStale(before time.Time) (int64, error) {
+	result := r.db.Model(&models.ScheduledJob{}).
+		Where("status = ? AND locked_at < ?", models.JobStatusRunning, before).
+		Updates(map[string]interface{}{
+			"status":     models.JobStatusPending,
+			"locked_by":  "",
+			"locked_at":  nil,
+			"updated_at": time.Now(),
+		})
+	return result.RowsAffected, result.Error
+}
+
+func (r *repository) Finish(workerID string, job *models.ScheduledJob) (bool, error) {
+	result := r.db.Model(&models.ScheduledJob{}).
+		Where("id = ? AND status = ? AND locked_by = ?", job.ID, models.JobStatusRunning, workerID).
+		Updates(map[string]interface{}{
+			"status":      job.Status,
+			"last_error":  job.LastError,
+			"executed_at": job.ExecutedAt,
+			"run_at":      job.RunAt,
+			"locked_by":   "",
+			"locked_at":   nil,
+			"updated_at":  time.Now(),
+		})
+	return result.RowsAffected == 1, result.Error
+}
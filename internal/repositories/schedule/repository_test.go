@@ -0,0 +1,77 @@
+package schedule
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+	"web-porto-backend/internal/domain/models"
+	"web-porto-backend/internal/testutil/fakesql"
+)
+
+// updateOf returns the only UPDATE statement sent to fake
+func updateOf(t *testing.T, fake *fakesql.DB) fakesql.Statement {
+	t.Helper()
+	var updates []fakesql.Statement
+	for _, st := range fake.Statements() {
+		if strings.HasPrefix(strings.TrimSpace(st.Query), "UPDATE") {
+			updates = append(updates, st)
+		}
+	}
+	if len(updates) != 1 {
+		t.Fatalf("%d UPDATE statements were sent: %+v", len(updates), fake.Statements())
+	}
+	return updates[0]
+}
+
+func argsOf(st fakesql.Statement) []string {
+	args := make([]string, len(st.Args))
+	for i, a := range st.Args {
+		args[i] = fmt.Sprint(a.Value)
+	}
+	return args
+}
+
+func TestFinishOnlyUpdatesJobsHeldByTheWorker(t *testing.T) {
+	db, fake := fakesql.Open(t, nil)
+	job := &models.ScheduledJob{ID: 12, Status: models.JobStatusDone}
+
+	finished, err := NewRepository(db).Finish("worker-a", job)
+	if err != nil || !finished {
+		t.Fatalf("Finish = %v, %v", finished, err)
+	}
+	st := updateOf(t, fake)
+	if !strings.Contains(st.Query, "WHERE id = $") || !strings.Contains(st.Query, "AND status = $") || !strings.Contains(st.Query, "AND locked_by = $") {
+		t.Fatalf("Finish does not check the job's lock: %s", st.Query)
+	}
+	args := strings.Join(argsOf(st), ",")
+	if !strings.HasSuffix(args, ",12,running,worker-a") {
+		t.Fatalf("Finish args = %s, want the job ID, running and the worker ID last", args)
+	}
+}
+
+func TestCancelJobOnlyCancelsPendingJobs(t *testing.T) {
+	db, fake := fakesql.Open(t, nil)
+
+	if _, err := NewRepository(db).CancelJob(5); err != nil {
+		t.Fatalf("CancelJob: %v", err)
+	}
+	st := updateOf(t, fake)
+	args := argsOf(st)
+	if !strings.Contains(st.Query, "status = $") || args[0] != models.JobStatusCancelled ||
+		!strings.HasSuffix(strings.Join(args, ","), ",5,"+models.JobStatusPending) {
+		t.Fatalf("CancelJob sent %s with %v", st.Query, args)
+	}
+}
+
+func TestClaimDueSkipsLockedRows(t *testing.T) {
+	db, fake := fakesql.Open(t, nil)
+
+	if _, err := NewRepository(db).ClaimDue("worker-a", time.Now(), 5); err != nil {
+		t.Fatalf("ClaimDue: %v", err)
+	}
+	statements := fake.Statements()
+	if len(statements) != 1 || !strings.Contains(statements[0].Query, "FOR UPDATE SKIP LOCKED") {
+		t.Fatalf("ClaimDue sent %+v", statements)
+	}
+}
@@ -0,0 +1,74 @@
+package scopes
+
+import (
+	"web-porto-backend/internal/domain/dto"
+
+	"gorm.io/gorm"
+)
+
+// Keyset pages a query newest first by (timeColumn, idColumn), starting after
+// q.Cursor. It fetches one row more than q.Limit so KeysetPage can tell
+// whether another page follows; no COUNT is needed.
+func Keyset(timeColumn, idColumn string, q dto.CursorQuery) func(*gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		switch {
+		case q.Cursor == nil:
+			db = db.Order(timeColumn + " DESC, " + idColumn + " DESC")
+		case q.Cursor.Backward:
+			db = db.Where("("+timeColumn+", "+idColumn+") > (?, ?)", q.Cursor.CreatedAt, q.Cursor.ID).
+				Order(timeColumn + " ASC, " + idColumn + " ASC")
+		default:
+			db = db.Where("("+timeColumn+", "+idColumn+") < (?, ?)", q.Cursor.CreatedAt, q.Cursor.ID).
+				Order(timeColumn + " DESC, " + idColumn + " DESC")
+		}
+		return db.Limit(q.Limit + 1)
+	}
+}
+
+// KeysetPage trims the rows fetched through Keyset to the requested page,
+// newest first, and returns the cursors of the neighbouring pages. key reads
+// a row's position.
+func KeysetPage[T any](rows []T, q dto.CursorQuery, key func(T) dto.Cursor) ([]T, dto.CursorResult) {
+	var result dto.CursorResult
+	backward := q.Cursor != nil && q.Cursor.Backward
+	more := len(rows) > q.Limit
+	if more {
+		rows = rows[:q.Limit]
+	}
+	if backward {
+		for i, j := 0, len(rows)-1; i < j; i, j = i+1, j-1 {
+			rows[i], rows[j] = rows[j], rows[i]
+		}
+	}
+
+	if len(rows) == 0 {
+		// Nothing past the cursor; offer the way back
+		if q.Cursor != nil {
+			back := *q.Cursor
+			back.Backward = !backward
+			if backward {
+				result.Next = &back
+			} else {
+				result.Prev = &back
+			}
+		}
+		return rows, result
+	}
+
+	first, last := key(rows[0]), key(rows[len(rows)-1])
+	first.Backward, last.Backward = true, false
+	if backward {
+		result.Next = &last
+		if more {
+			result.Prev = &first
+		}
+	} else {
+		if more {
+			result.Next = &last
+		}
+		if q.Cursor != nil {
+			result.Prev = &first
+		}
+	}
+	return rows, result
+}
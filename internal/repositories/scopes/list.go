@@ -0,0 +1,98 @@
+package scopes
+
+import (
+	"fmt"
+	"strings"
+	"web-porto-backend/internal/domain/dto"
+
+	"gorm.io/gorm"
+)
+
+// ListColumns whitelists what a list query may touch in one table. Only the
+// SQL written here reaches the query text; request values are always bound.
+type ListColumns struct {
+	// Sort maps sortable API fields to qualified columns
+	Sort map[string]string
+	// Ranges maps API fields accepting filter[field][from|to] to qualified columns
+	Ranges map[string]string
+	// Filters lists the filter[...] names the repository applies itself
+	Filters []string
+	// DefaultOrder is used without ?sort=
+	DefaultOrder string
+	// Key is appended to every order so pages are stable
+	Key string
+}
+
+// Apply checks every name in q against the whitelist and returns a scope for
+// the date ranges and the ORDER BY clause. The scope belongs before Count, the
+// order after it. Unknown names fail with dto.ErrInvalidListQuery.
+func (lc ListColumns) Apply(q dto.ListQuery) (func(*gorm.DB) *gorm.DB, string, error) {
+	for name := range q.Filters {
+		if !lc.allowsFilter(name) {
+			return nil, "", fmt.Errorf("%w: cannot filter by %q", dto.ErrInvalidListQuery, name)
+		}
+	}
+
+	type bound struct {
+		column string
+		rng    dto.DateRange
+	}
+	bounds := make([]bound, 0, len(q.Ranges))
+	for name, rng := range q.Ranges {
+		column, ok := lc.Ranges[name]
+		if !ok {
+			return nil, "", fmt.Errorf("%w: cannot filter %q by date", dto.ErrInvalidListQuery, name)
+		}
+		bounds = append(bounds, bound{column, rng})
+	}
+
+	order := make([]string, 0, len(q.Sort)+1)
+	for _, field := range q.Sort {
+		column, ok := lc.Sort[field.Field]
+		if !ok {
+			return nil, "", fmt.Errorf("%w: cannot sort by %q", dto.ErrInvalidListQuery, field.Field)
+		}
+		if field.Desc {
+			order = append(order, column+" DESC NULLS LAST")
+		} else {
+			order = append(order, column+" ASC NULLS LAST")
+		}
+	}
+	if len(order) == 0 && lc.DefaultOrder != "" {
+		order = append(order, lc.DefaultOrder)
+	}
+	if lc.Key != "" {
+		order = append(order, lc.Key)
+	}
+
+	scope := func(db *gorm.DB) *gorm.DB {
+		for _, b := range bounds {
+			if b.rng.From != nil {
+				db = db.Where(b.column+" >= ?", *b.rng.From)
+			}
+			if b.rng.To != nil {
+				db = db.Where(b.column+" <= ?", *b.rng.To)
+			}
+		}
+		return db
+	}
+	return scope, strings.Join(order, ", "), nil
+}
+
+// Lower lowercases filter values for case-insensitive name matching
+func Lower(values []string) []string {
+	out := make([]string, len(values))
+	for i, v := range values {
+		out[i] = strings.ToLower(v)
+	}
+	return out
+}
+
+func (lc ListColumns) allowsFilter(name string) bool {
+	for _, allowed := range lc.Filters {
+		if allowed == name {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,24 @@
+package scopes
+
+import (
+	"time"
+	"web-porto-backend/internal/domain/models"
+
+	"gorm.io/gorm"
+)
+
+// Status limits a content query on table to one status.
+// models.StatusPublished only matches rows whose publish date has passed, so
+// it yields exactly what visitors may see; models.StatusAll disables the filter.
+func Status(table, status string) func(*gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		switch status {
+		case models.StatusAll:
+			return db
+		case models.StatusPublished:
+			return db.Where(table+".status = ? AND "+table+".published_at IS NOT NULL AND "+table+".published_at <= ?", models.StatusPublished, time.Now())
+		default:
+			return db.Where(table+".status = ?", status)
+		}
+	}
+}
@@ -0,0 +1,225 @@
+package search
+
+import (
+	"html"
+	"strings"
+	"time"
+	"web-porto-backend/internal/domain/models"
+
+	"gorm.io/gorm"
+)
+
+// Searchable content types
+const (
+	TypeArticle    = "article"
+	TypeProject    = "project"
+	TypeExperience = "experience"
+	TypePage       = "page"
+)
+
+// Types lists every searchable content type
+var Types = []string{TypeArticle, TypeProject, TypeExperience, TypePage}
+
+// textSearchConfig must match the configuration used by the triggers in
+// database_schema/033_add_search_vectors.sql
+const textSearchConfig = "english"
+
+// ts_headline wraps matches in these private use characters rather than
+// <mark>, so the snippet can be HTML-escaped before the marks are added.
+// They are removed from the text first.
+const (
+	startSel = "\uE000"
+	stopSel  = "\uE001"
+)
+
+// headlineOptions controls ts_headline snippets
+const headlineOptions = `StartSel="` + startSel + `", StopSel="` + stopSel + `", MaxWords=35, MinWords=15, MaxFragments=2`
+
+// marks turns the selection characters of an escaped snippet into <mark>
+var marks = strings.NewReplacer(startSel, "<mark>", stopSel, "</mark>")
+
+// Filter narrows a search. Only content visitors may see is searched.
+type Filter struct {
+	// Query uses web search syntax: "quoted phrases", or, -excluded
+	Query string
+	// Types limits results to some content types; empty searches all
+	Types []string
+	// Category is a category slug; excludes experiences and pages
+	Category string
+	// Tag is a tag slug or name; excludes pages
+	Tag string
+	// From/To bound the publish date (start date for experiences)
+	From *time.Time
+	To   *time.Time
+}
+
+// Hit is a ranked search result
+type Hit struct {
+	Type    string
+	ID      string
+	Title   string
+	Slug    string
+	Snippet string
+	Rank    float64
+	Date    *time.Time `gorm:"column:hit_date"`
+}
+
+type Repository interface {
+	Search(filter Filter, limit, offset int) ([]Hit, int64, error)
+}
+
+type repository struct {
+	db *gorm.DB
+}
+
+func NewRepository(db *gorm.DB) Repository {
+	return &repository{db: db}
+}
+
+func (r *repository) Search(filter Filter, limit, offset int) ([]Hit, int64, error) {
+	union, args := buildUnion(filter)
+	if union == "" {
+		return []Hit{}, 0, nil
+	}
+	with := "WITH q AS (SELECT websearch_to_tsquery('" + textSearchConfig + "', ?) AS query), hits AS (" + union + ") "
+	args = append([]interface{}{filter.Query}, args...)
+
+	var total int64
+	if err := r.db.Raw(with+"SELECT count(*) FROM hits", args...).Scan(&total).Error; err != nil {
+		return nil, 0, err
+	}
+	if total == 0 {
+		return []Hit{}, 0, nil
+	}
+
+	// Snippets are only computed for the requested page
+	query := with + `SELECT page.type, page.id, page.title, page.slug, page.rank, page.hit_date,
+		ts_headline('` + textSearchConfig + `', translate(CASE page.type
+			WHEN 'article' THEN (SELECT search_strip_html(excerpt) || ' ' || search_strip_html(content) FROM articles WHERE id = page.id::uuid)
+			WHEN 'project' THEN (SELECT search_strip_html(description) || ' ' || search_strip_html(content) FROM projects WHERE id = page.id::uuid)
+			WHEN 'experience' THEN (SELECT search_strip_html(company) || ' ' || search_strip_html(description) FROM experiences WHERE id = page.id::int)
+			WHEN 'page' THEN (SELECT search_strip_html(content) FROM pages WHERE id = page.id::int)
+		END, ?, ''), q.query, ?) AS snippet
+	FROM (SELECT * FROM hits ORDER BY rank DESC, hit_date DESC NULLS LAST, id LIMIT ? OFFSET ?) page, q
+	ORDER BY page.rank DESC, page.hit_date DESC NULLS LAST, page.id`
+
+	var hits []Hit
+	if err := r.db.Raw(query, append(args, startSel+stopSel, headlineOptions, limit, offset)...).Scan(&hits).Error; err != nil {
+		return nil, 0, err
+	}
+	for i := range hits {
+		hits[i].Snippet = highlight(hits[i].Snippet)
+	}
+	return hits, total, nil
+}
+
+// highlight escapes a ts_headline snippet and marks its matches
+func highlight(snippet string) string {
+	return marks.Replace(html.EscapeString(snippet))
+}
+
+// buildUnion combines one SELECT per requested content type into a UNION ALL
+// yielding type, id, title, slug, rank and date
+func buildUnion(filter Filter) (string, []interface{}) {
+	var parts []string
+	var args []interface{}
+	for _, typ := range filter.Types {
+		var sql string
+		var partArgs []interface{}
+		switch typ {
+		case TypeArticle:
+			sql, partArgs = articleBranch(filter)
+		case TypeProject:
+			sql, partArgs = projectBranch(filter)
+		case TypeExperience:
+			sql, partArgs = experienceBranch(filter)
+		case TypePage:
+			sql, partArgs = pageBranch(filter)
+		}
+		if sql != "" {
+			parts = append(parts, sql)
+			args = append(args, partArgs...)
+		}
+	}
+	return strings.Join(parts, " UNION ALL "), args
+}
+
+// published restricts a branch to content visitors may see
+func published(alias string, where []string, args []interface{}, filter Filter) ([]string, []interface{}) {
+	where = append(where, alias+".status = ?", alias+".published_at IS NOT NULL", alias+".published_at <= ?")
+	args = append(args, models.StatusPublished, time.Now())
+	return dateRange(alias+".published_at", where, args, filter)
+}
+
+func dateRange(column string, where []string, args []interface{}, filter Filter) ([]string, []interface{}) {
+	if filter.From != nil {
+		where = append(where, column+" >= ?")
+		args = append(args, *filter.From)
+	}
+	if filter.To != nil {
+		where = append(where, column+" <= ?")
+		args = append(args, *filter.To)
+	}
+	return where, args
+}
+
+func branch(selectSQL, from string, where []string) string {
+	return selectSQL + " FROM " + from + ", q WHERE " + strings.Join(where, " AND ")
+}
+
+func articleBranch(filter Filter) (string, []interface{}) {
+	where := []string{"a.search_vector @@ q.query"}
+	var args []interface{}
+	where, args = published("a", where, args, filter)
+	if filter.Category != "" {
+		where = append(where, "EXISTS (SELECT 1 FROM article_categories ac JOIN categories c ON c.id = ac.category_id WHERE ac.article_id = a.id AND c.slug = ?)")
+		args = append(args, filter.Category)
+	}
+	if filter.Tag != "" {
+		where = append(where, "EXISTS (SELECT 1 FROM article_tags tg JOIN tags t ON t.id = tg.tag_id WHERE tg.article_id = a.id AND (t.slug = ? OR lower(t.name) = lower(?)))")
+		args = append(args, filter.Tag, filter.Tag)
+	}
+	return branch("SELECT 'article' AS type, a.id::text AS id, a.title, a.slug, ts_rank_cd(a.search_vector, q.query) AS rank, a.published_at AS hit_date", "articles a", where), args
+}
+
+func projectBranch(filter Filter) (string, []interface{}) {
+	where := []string{"p.search_vector @@ q.query"}
+	var args []interface{}
+	where, args = published("p", where, args, filter)
+	if filter.Category != "" {
+		where = append(where, `(EXISTS (SELECT 1 FROM categories c WHERE c.id = p.category_id AND c.slug = ?)
+			OR EXISTS (SELECT 1 FROM project_categories pc JOIN categories c ON c.id = pc.category_id WHERE pc.project_id = p.id AND c.slug = ?))`)
+		args = append(args, filter.Category, filter.Category)
+	}
+	if filter.Tag != "" {
+		where = append(where, `EXISTS (SELECT 1 FROM tags t WHERE (t.slug = ? OR lower(t.name) = lower(?)) AND (
+			t.id IN (SELECT tag_id FROM project_tags WHERE project_id = p.id)
+			OR t.id IN (SELECT tag_id FROM project_technologies WHERE project_id = p.id)))`)
+		args = append(args, filter.Tag, filter.Tag)
+	}
+	return branch("SELECT 'project' AS type, p.id::text AS id, p.title, p.slug, ts_rank_cd(p.search_vector, q.query) AS rank, p.published_at AS hit_date", "projects p", where), args
+}
+
+func experienceBranch(filter Filter) (string, []interface{}) {
+	if filter.Category != "" {
+		return "", nil
+	}
+	where := []string{"e.search_vector @@ q.query"}
+	var args []interface{}
+	where, args = dateRange("e.start_date", where, args, filter)
+	if filter.Tag != "" {
+		where = append(where, "EXISTS (SELECT 1 FROM experience_technologies et JOIN tags t ON t.id = et.tag_id WHERE et.experience_id = e.id AND (t.slug = ? OR lower(t.name) = lower(?)))")
+		args = append(args, filter.Tag, filter.Tag)
+	}
+	return branch("SELECT 'experience' AS type, e.id::text AS id, e.title, ''::text AS slug, ts_rank_cd(e.search_vector, q.query) AS rank, e.start_date::timestamp AS hit_date", "experiences e", where), args
+}
+
+func pageBranch(filter Filter) (string, []interface{}) {
+	if filter.Category != "" || filter.Tag != "" {
+		return "", nil
+	}
+	where := []string{"pg.search_vector @@ q.query"}
+	var args []interface{}
+	where, args = published("pg", where, args, filter)
+	return branch("SELECT 'page' AS type, pg.id::text AS id, pg.title, pg.slug, ts_rank_cd(pg.search_vector, q.query) AS rank, pg.published_at AS hit_date", "pages pg", where), args
+}
@@ -0,0 +1,104 @@
+package search
+
+import (
+	"database/sql/driver"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+	"web-porto-backend/internal/testutil/fakesql"
+)
+
+func TestSearchEscapesSnippets(t *testing.T) {
+	stored := `<img src=x onerror=alert(1)> ` + startSel + `rust` + stopSel + ` & "more"`
+	db, fake := fakesql.Open(t, func(query string, _ []driver.NamedValue) ([]string, [][]driver.Value) {
+		if strings.Contains(query, "count(*)") {
+			return []string{"count"}, [][]driver.Value{{int64(1)}}
+		}
+		return []string{"type", "id", "title", "slug", "rank", "hit_date", "snippet"},
+			[][]driver.Value{{"article", "a1", "Rust", "rust", 0.5, time.Now(), stored}}
+	})
+
+	hits, total, err := NewRepository(db).Search(Filter{Query: "rust", Types: []string{TypeArticle}}, 10, 0)
+	if err != nil || total != 1 || len(hits) != 1 {
+		t.Fatalf("Search = %+v, %d, %v", hits, total, err)
+	}
+	want := `&lt;img src=x onerror=alert(1)&gt; <mark>rust</mark> &amp; &#34;more&#34;`
+	if hits[0].Snippet != want {
+		t.Fatalf("snippet = %q, want %q", hits[0].Snippet, want)
+	}
+
+	statements := fake.Statements()
+	last := statements[len(statements)-1]
+	if strings.Contains(last.Query, "<mark>") || strings.Contains(last.Query, startSel) {
+		t.Fatalf("selection markers are part of the query: %s", last.Query)
+	}
+	var options, sentinels bool
+	for _, arg := range last.Args {
+		options = options || arg.Value == headlineOptions
+		sentinels = sentinels || arg.Value == startSel+stopSel
+	}
+	if !options || !sentinels {
+		t.Fatalf("snippet query args %v lack the headline options or the markers to remove", last.Args)
+	}
+}
+
+func TestSearchWithoutTypesRunsNoQuery(t *testing.T) {
+	db, fake := fakesql.Open(t, nil)
+
+	hits, total, err := NewRepository(db).Search(Filter{Query: "rust"}, 10, 0)
+	if err != nil || total != 0 || len(hits) != 0 {
+		t.Fatalf("Search = %+v, %d, %v", hits, total, err)
+	}
+	if n := len(fake.Statements()); n != 0 {
+		t.Fatalf("%d statements were sent", n)
+	}
+}
+
+func TestBuildUnion(t *testing.T) {
+	from := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	to := time.Date(2024, 12, 31, 0, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name     string
+		filter   Filter
+		branches []string
+		args     int
+		contains []string
+	}{
+		{"all types", Filter{Types: Types}, []string{"article", "project", "experience", "page"}, 6,
+			[]string{"a.status = ?", "p.status = ?", "pg.status = ?", "e.search_vector @@ q.query"}},
+		{"unknown types are ignored", Filter{Types: []string{"user", TypePage}}, []string{"page"}, 2, nil},
+		{"category leaves out experiences and pages", Filter{Types: Types, Category: "go"}, []string{"article", "project"}, 7,
+			[]string{"c.slug = ?", "project_categories"}},
+		{"tag leaves out pages", Filter{Types: Types, Tag: "go"}, []string{"article", "project", "experience"}, 10,
+			[]string{"article_tags", "project_technologies", "experience_technologies"}},
+		{"date range", Filter{Types: []string{TypeArticle, TypeExperience}, From: &from, To: &to}, []string{"article", "experience"}, 6,
+			[]string{"a.published_at >= ?", "a.published_at <= ?", "e.start_date >= ?", "e.start_date <= ?"}},
+		{"no types", Filter{}, nil, 0, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sql, args := buildUnion(tt.filter)
+
+			var branches []string
+			for _, part := range strings.Split(sql, " UNION ALL ") {
+				if part == "" {
+					continue
+				}
+				typ, _, _ := strings.Cut(strings.TrimPrefix(part, "SELECT '"), "'")
+				branches = append(branches, typ)
+			}
+			if fmt.Sprint(branches) != fmt.Sprint(tt.branches) {
+				t.Fatalf("branches = %v, want %v", branches, tt.branches)
+			}
+			if len(args) != tt.args || strings.Count(sql, "?") != len(args) {
+				t.Fatalf("%d args for %d placeholders, want %d: %s", len(args), strings.Count(sql, "?"), tt.args, sql)
+			}
+			for _, s := range tt.contains {
+				if !strings.Contains(sql, s) {
+					t.Errorf("query lacks %q: %s", s, sql)
+				}
+			}
+		})
+	}
+}
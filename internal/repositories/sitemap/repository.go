@@ -0,0 +1,133 @@
+package sitemap
+
+import (
+	"time"
+	"web-porto-backend/internal/domain/models"
+
+	"gorm.io/gorm"
+)
+
+// Content types listed in the sitemap
+const (
+	TypeArticle = "article"
+	TypeProject = "project"
+	TypePage    = "page"
+)
+
+// Entry is a published article, project or page
+type Entry struct {
+	Type      string
+	ID        string
+	Slug      string
+	UpdatedAt time.Time
+	Images    []Image `gorm:"-"`
+}
+
+// Image is an image attached to an entry
+type Image struct {
+	OwnerID string
+	URL     string
+	Title   string
+	Caption string
+}
+
+type Repository interface {
+	// Count returns the number of published entries
+	Count() (int64, error)
+	// List returns published entries with their images in a stable order
+	List(limit, offset int) ([]Entry, error)
+	// LastModified returns the newest UpdatedAt of any published entry
+	LastModified() (time.Time, error)
+}
+
+type repository struct {
+	db *gorm.DB
+}
+
+func NewRepository(db *gorm.DB) Repository {
+	return &repository{db: db}
+}
+
+// entries unions what visitors may see, with the same rule as scopes.Status,
+// leaving out content whose SEO block asks not to be indexed
+const entries = `SELECT 'article' AS type, id::text AS id, slug, updated_at FROM articles
+	WHERE status = @status AND published_at IS NOT NULL AND published_at <= @now` + indexable + `
+UNION ALL
+SELECT 'project', id::text, slug, updated_at FROM projects
+	WHERE status = @status AND published_at IS NOT NULL AND published_at <= @now` + indexable + `
+UNION ALL
+SELECT 'page', id::text, slug, updated_at FROM pages
+	WHERE status = @status AND published_at IS NOT NULL AND published_at <= @now` + indexable
+
+// indexable excludes rows with seo.noindex set, see models.SEO
+const indexable = ` AND NOT coalesce((seo->>'noindex')::boolean, false)`
+
+func (r *repository) args() map[string]interface{} {
+	return map[string]interface{}{"status": models.StatusPublished, "now": time.Now()}
+}
+
+func (r *repository) Count() (int64, error) {
+	var total int64
+	err := r.db.Raw("SELECT count(*) FROM ("+entries+") e", r.args()).Scan(&total).Error
+	return total, err
+}
+
+func (r *repository) LastModified() (time.Time, error) {
+	var last *time.Time
+	if err := r.db.Raw("SELECT max(updated_at) FROM ("+entries+") e", r.args()).Scan(&last).Error; err != nil {
+		return time.Time{}, err
+	}
+	if last == nil {
+		return time.Time{}, nil
+	}
+	return *last, nil
+}
+
+func (r *repository) List(limit, offset int) ([]Entry, error) {
+	args := r.args()
+	args["limit"] = limit
+	args["offset"] = offset
+
+	var list []Entry
+	if err := r.db.Raw("SELECT * FROM ("+entries+") e ORDER BY type, id LIMIT @limit OFFSET @offset", args).Scan(&list).Error; err != nil {
+		return nil, err
+	}
+
+	ids := map[string][]string{}
+	for _, e := range list {
+		ids[e.Type] = append(ids[e.Type], e.ID)
+	}
+	images := map[string][]Image{}
+	if len(ids[TypeArticle]) > 0 {
+		var rows []Image
+		err := r.db.Model(&models.ArticleImage{}).
+			Select("article_id AS owner_id, url, alt_text AS title, caption").
+			Where("article_id IN ?", ids[TypeArticle]).
+			Order("article_id, sort_order").
+			Scan(&rows).Error
+		if err != nil {
+			return nil, err
+		}
+		for _, img := range rows {
+			images[TypeArticle+":"+img.OwnerID] = append(images[TypeArticle+":"+img.OwnerID], img)
+		}
+	}
+	if len(ids[TypeProject]) > 0 {
+		var rows []Image
+		err := r.db.Model(&models.ProjectImage{}).
+			Select("project_id AS owner_id, url, caption").
+			Where("project_id IN ?", ids[TypeProject]).
+			Order("project_id, sort_order").
+			Scan(&rows).Error
+		if err != nil {
+			return nil, err
+		}
+		for _, img := range rows {
+			images[TypeProject+":"+img.OwnerID] = append(images[TypeProject+":"+img.OwnerID], img)
+		}
+	}
+	for i := range list {
+		list[i].Images = images[list[i].Type+":"+list[i].ID]
+	}
+	return list, nil
+}
@@ -0,0 +1,29 @@
+package sitemap
+
+import (
+	"strings"
+	"testing"
+	"web-porto-backend/internal/testutil/fakesql"
+)
+
+func TestEntriesLeaveOutNoindexContent(t *testing.T) {
+	db, fake := fakesql.Open(t, nil)
+
+	if _, err := NewRepository(db).Count(); err != nil {
+		t.Fatalf("Count: %v", err)
+	}
+	statements := fake.Statements()
+	if len(statements) != 1 {
+		t.Fatalf("statements = %+v", statements)
+	}
+	for _, table := range []string{"articles", "projects", "pages"} {
+		_, rest, ok := strings.Cut(statements[0].Query, "FROM "+table)
+		if !ok {
+			t.Fatalf("%s are not listed: %s", table, statements[0].Query)
+		}
+		where, _, _ := strings.Cut(rest, "UNION ALL")
+		if !strings.Contains(where, "NOT coalesce((seo->>'noindex')::boolean, false)") {
+			t.Errorf("noindex %s are listed: %s", table, where)
+		}
+	}
+}
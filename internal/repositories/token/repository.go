@@ -0,0 +1,134 @@
+package token
+
+import (
+	"errors"
+	"time"
+	"web-porto-backend/internal/domain/models"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// ErrAlreadyRevoked is returned by Rotate when the token was revoked concurrently.
+var ErrAlreadyRevoked = errors.New("refresh token already revoked")
+
+type Repository interface {
+	Create(token *models.RefreshToken) error
+	FindByHash(hash string) (*models.RefreshToken, error)
+	Rotate(old *models.RefreshToken, next *models.RefreshToken) error
+	Revoke(id int) error
+	RevokeFamily(familyID string) error
+	RevokeAllForUser(userID int) error
+	RevokeAccessToken(token *models.RevokedToken) error
+	// RevokeAccessTokensBefore rejects the access tokens of a user issued at
+	// or before the given time
+	RevokeAccessTokensBefore(userID int, at time.Time) error
+	// IsAccessTokenRevoked reports whether jti was revoked, or the access
+	// tokens of userID (when not 0) issued at issuedAt were, which includes
+	// all tokens of a disabled user
+	IsAccessTokenRevoked(jti string, userID int, issuedAt time.Time) (bool, error)
+	DeleteExpired(before time.Time) (int64, error)
+}
+
+type repository struct {
+	db *gorm.DB
+}
+
+func NewRepository(db *gorm.DB) Repository {
+	return &repository{db: db}
+}
+
+func (r *repository) Create(token *models.RefreshToken) error {
+	return r.db.Create(token).Error
+}
+
+func (r *repository) FindByHash(hash string) (*models.RefreshToken, error) {
+	var token models.RefreshToken
+	if err := r.db.Where("token_hash = ?", hash).First(&token).Error; err != nil {
+		return nil, err
+	}
+	return &token, nil
+}
+
+// Rotate stores next and marks old as revoked and replaced in one transaction.
+// The conditional update guarantees a token can only be rotated once.
+func (r *repository) Rotate(old *models.RefreshToken, next *models.RefreshToken) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(next).Error; err != nil {
+			return err
+		}
+		res := tx.Model(&models.RefreshToken{}).
+			Where("id = ? AND revoked_at IS NULL", old.ID).
+			Updates(map[string]interface{}{"revoked_at": time.Now(), "replaced_by": next.ID})
+		if res.Error != nil {
+			return res.Error
+		}
+		if res.RowsAffected == 0 {
+			return ErrAlreadyRevoked
+		}
+		return nil
+	})
+}
+
+func (r *repository) Revoke(id int) error {
+	return r.db.Model(&models.RefreshToken{}).
+		Where("id = ? AND revoked_at IS NULL", id).
+		Update("revoked_at", time.Now()).Error
+}
+
+func (r *repository) RevokeFamily(familyID string) error {
+	return r.db.Model(&models.RefreshToken{}).
+		Where("family_id = ? AND revoked_at IS NULL", familyID).
+		Update("revoked_at", time.Now()).Error
+}
+
+func (r *repository) RevokeAllForUser(userID int) error {
+	return r.db.Model(&models.RefreshToken{}).
+		Where("user_id = ? AND revoked_at IS NULL", userID).
+		Update("revoked_at", time.Now()).Error
+}
+
+func (r *repository) RevokeAccessToken(token *models.RevokedToken) error {
+	return r.db.Clauses(clause.OnConflict{DoNothing: true}).Create(token).Error
+}
+
+func (r *repository) RevokeAccessTokensBefore(userID int, at time.Time) error {
+	return r.db.Model(&models.User{}).Where("id = ?", userID).Update("tokens_revoked_at", at).Error
+}
+
+func (r *repository) IsAccessTokenRevoked(jti string, userID int, issuedAt time.Time) (bool, error) {
+	// Issue times have second precision, so a cutoff within the second a
+	// token was issued revokes it
+	var revoked bool
+	err := r.db.Raw(`SELECT EXISTS (SELECT 1 FROM revoked_tokens WHERE jti = ?)
+		OR EXISTS (SELECT 1 FROM users WHERE id = ? AND (status = ? OR tokens_revoked_at >= ?))`,
+		jti, userID, models.UserStatusDisabled, issuedAt).
+		Scan(&revoked).Error
+	return revoked, err
+}
+
+// DeleteExpired removes refresh tokens and revoked jtis that expired before the given time.
+func (r *repository) DeleteExpired(before time.Time) (int64, error) {
+	var total int64
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		res := tx.Where("expires_at < ?", before).Delete(&models.RevokedToken{})
+		if res.Error != nil {
+			return res.Error
+		}
+		total += res.RowsAffected
+
+		// Detach chains first so the self-referencing FK does not block deletes
+		if err := tx.Model(&models.RefreshToken{}).
+			Where("replaced_by IN (?)", tx.Model(&models.RefreshToken{}).Select("id").Where("expires_at < ?", before)).
+			Update("replaced_by", nil).Error; err != nil {
+			return err
+		}
+		res = tx.Where("expires_at < ?", before).Delete(&models.RefreshToken{})
+		if res.Error != nil {
+			return res.Error
+		}
+		total += res.RowsAffected
+		return nil
+	})
+	return total, err
+}
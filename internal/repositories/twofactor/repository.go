@@ -0,0 +1,92 @@
+package twofactor
+
+import (
+	"time"
+	"web-porto-backend/internal/domain/models"
+
+	"gorm.io/gorm"
+)
+
+type Repository interface {
+	FindByUserID(userID int) (*models.TwoFactor, error)
+	Save(twoFactor *models.TwoFactor) error
+	AdvanceStep(userID int, step int64) (bool, error)
+	Delete(userID int) error
+	ReplaceRecoveryCodes(userID int, hashes []string) error
+	UseRecoveryCode(userID int, hash string) (bool, error)
+	CountUnusedRecoveryCodes(userID int) (int64, error)
+}
+
+type repository struct {
+	db *gorm.DB
+}
+
+func NewRepository(db *gorm.DB) Repository {
+	return &repository{db: db}
+}
+
+func (r *repository) FindByUserID(userID int) (*models.TwoFactor, error) {
+	var twoFactor models.TwoFactor
+	if err := r.db.Where("user_id = ?", userID).First(&twoFactor).Error; err != nil {
+		return nil, err
+	}
+	return &twoFactor, nil
+}
+
+func (r *repository) Save(twoFactor *models.TwoFactor) error {
+	return r.db.Save(twoFactor).Error
+}
+
+// AdvanceStep records step as the last accepted TOTP step. It returns false
+// when an equal or later step was already used, which rejects replayed codes.
+func (r *repository) AdvanceStep(userID int, step int64) (bool, error) {
+	res := r.db.Model(&models.TwoFactor{}).
+		Where("user_id = ? AND last_used_step < ?", userID, step).
+		Update("last_used_step", step)
+	if res.Error != nil {
+		return false, res.Error
+	}
+	return res.RowsAffected > 0, nil
+}
+
+func (r *repository) Delete(userID int) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("user_id = ?", userID).Delete(&models.RecoveryCode{}).Error; err != nil {
+			return err
+		}
+		return tx.Where("user_id = ?", userID).Delete(&models.TwoFactor{}).Error
+	})
+}
+
+func (r *repository) ReplaceRecoveryCodes(userID int, hashes []string) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("user_id = ?", userID).Delete(&models.RecoveryCode{}).Error; err != nil {
+			return err
+		}
+		codes := make([]models.RecoveryCode, 0, len(hashes))
+		for _, h := range hashes {
+			codes = append(codes, models.RecoveryCode{UserID: userID, CodeHash: h})
+		}
+		if len(codes) == 0 {
+			return nil
+		}
+		return tx.Create(&codes).Error
+	})
+}
+
+// UseRecoveryCode marks a matching unused code as used; false means no match.
+func (r *repository) UseRecoveryCode(userID int, hash string) (bool, error) {
+	res := r.db.Model(&models.RecoveryCode{}).
+		Where("user_id = ? AND code_hash = ? AND used_at IS NULL", userID, hash).
+		Update("used_at", time.Now())
+	if res.Error != nil {
+		return false, res.Error
+	}
+	return res.RowsAffected > 0, nil
+}
+
+func (r *repository) CountUnusedRecoveryCodes(userID int) (int64, error) {
+	var count int64
+	err := r.db.Model(&models.RecoveryCode{}).Where("user_id = ? AND used_at IS NULL", userID).Count(&count).Error
+	return count, err
+}
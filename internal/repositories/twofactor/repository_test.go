@@ -0,0 +1,32 @@
+package twofactor
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"web-porto-backend/internal/testutil/fakesql"
+)
+
+func TestAdvanceStepOnlyMovesForward(t *testing.T) {
+	db, fake := fakesql.Open(t, nil)
+
+	if _, err := NewRepository(db).AdvanceStep(3, 1000); err != nil {
+		t.Fatalf("AdvanceStep: %v", err)
+	}
+	var updates []fakesql.Statement
+	for _, st := range fake.Statements() {
+		if strings.HasPrefix(st.Query, "UPDATE") {
+			updates = append(updates, st)
+		}
+	}
+	if len(updates) != 1 {
+		t.Fatalf("statements = %+v", fake.Statements())
+	}
+	st := updates[0]
+	if !strings.Contains(st.Query, "WHERE user_id = $3 AND last_used_step < $4") {
+		t.Fatalf("AdvanceStep does not reject used steps: %s", st.Query)
+	}
+	if fmt.Sprint(st.Args[2].Value, st.Args[3].Value) != "3 1000" {
+		t.Fatalf("AdvanceStep args = %v", st.Args)
+	}
+}
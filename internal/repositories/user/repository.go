@@ -18,6 +18,7 @@ const (
 
 type Repository interface {
 	FindAll() ([]models.User, error)
+	FindPaginated(search, role, status string, offset, limit int) ([]models.User, int64, error)
 	FindByID(id int) (*models.User, error)
 	FindByEmail(email string) (*models.User, error)
 	Create(user *models.User) error
@@ -47,6 +48,38 @@ func (r *repository) FindAll() ([]models.User, error) {
 	return users, err
 }
 
+// FindPaginated returns one page of users matching the optional filters.
+// search matches username or email case-insensitively.
+func (r *repository) FindPaginated(search, role, status string, offset, limit int) ([]models.User, int64, error) {
+	log := applog.GetLogger().WithFields(applog.Fields{"repo": "user", "method": "FindPaginated"})
+
+	query := r.db.Model(&models.User{})
+	if search != "" {
+		pattern := "%" + search + "%"
+		query = query.Where("username ILIKE ? OR email ILIKE ?", pattern, pattern)
+	}
+	if role != "" {
+		query = query.Where("role = ?", role)
+	}
+	if status != "" {
+		query = query.Where("status = ?", status)
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		log.Error(msgDBError, applog.Fields{"error": err.Error()})
+		return nil, 0, err
+	}
+
+	var users []models.User
+	if err := query.Order("id ASC").Offset(offset).Limit(limit).Find(&users).Error; err != nil {
+		log.Error(msgDBError, applog.Fields{"error": err.Error()})
+		return nil, 0, err
+	}
+	log.Info(msgFetchedUsers, applog.Fields{"count": len(users), "total": total})
+	return users, total, nil
+}
+
 func (r *repository) FindByID(id int) (*models.User, error) {
 	log := applog.GetLogger().WithFields(applog.Fields{"repo": "user", "method": "FindByID", "id": id})
 	var user models.User
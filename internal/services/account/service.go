@@ -0,0 +1,218 @@
+package account
+
+import (
+	"errors"
+	"fmt"
+	"html"
+	"net/url"
+	"strings"
+	"time"
+	applog "web-porto-backend/common/logger"
+	"web-porto-backend/common/utils"
+	"web-porto-backend/common/validation"
+	"web-porto-backend/internal/adapters/mail"
+	"web-porto-backend/internal/auth"
+	"web-porto-backend/internal/domain/models"
+	tokenSrvc "web-porto-backend/internal/services/token"
+	userSrvc "web-porto-backend/internal/services/user"
+)
+
+var (
+	ErrInvalidToken    = errors.New("invalid or expired token")
+	ErrAlreadyVerified = errors.New("email address is already verified")
+)
+
+// WeakPasswordError wraps the password policy violation
+type WeakPasswordError struct {
+	Err error
+}
+
+func (e *WeakPasswordError) Error() string { return e.Err.Error() }
+func (e *WeakPasswordError) Unwrap() error { return e.Err }
+
+// Service handles password recovery and email verification. Tokens are
+// signed scoped JWTs bound to a fingerprint of the state they act on, so a
+// reset token stops working once the password changed and a verification
+// token once the email changed.
+type Service interface {
+	RequestPasswordReset(email string) error
+	ResetPassword(token, password string) error
+	SendVerificationEmail(userID int) error
+	RequestVerificationEmail(email string) error
+	VerifyEmail(token string) error
+	ValidatePassword(password string) error
+	VerificationRequired() bool
+}
+
+// Config holds link and lifetime settings
+type Config struct {
+	AppName              string
+	PasswordResetTTL     time.Duration
+	PasswordResetURL     string
+	EmailVerificationTTL time.Duration
+	EmailVerificationURL string
+	RequireVerification  bool
+}
+
+type service struct {
+	userService  userSrvc.Service
+	tokenService tokenSrvc.Service
+	signer       auth.JWTService
+	mailer       mail.Mailer
+	validator    *validation.Validator
+	cfg          Config
+}
+
+func NewService(userService userSrvc.Service, tokenService tokenSrvc.Service, signer auth.JWTService, mailer mail.Mailer, cfg Config) Service {
+	if cfg.PasswordResetTTL <= 0 {
+		cfg.PasswordResetTTL = time.Hour
+	}
+	if cfg.EmailVerificationTTL <= 0 {
+		cfg.EmailVerificationTTL = 48 * time.Hour
+	}
+	if cfg.AppName == "" {
+		cfg.AppName = "Web Porto CMS"
+	}
+	return &service{
+		userService:  userService,
+		tokenService: tokenService,
+		signer:       signer,
+		mailer:       mailer,
+		validator:    validation.NewValidator(),
+		cfg:          cfg,
+	}
+}
+
+// RequestPasswordReset mails a reset link when the email belongs to an active
+// account. Unknown emails are not reported to avoid account enumeration.
+func (s *service) RequestPasswordReset(email string) error {
+	log := applog.GetLogger().WithFields(applog.Fields{"service": "account", "method": "RequestPasswordReset"})
+
+	user, err := s.userService.GetByEmail(strings.TrimSpace(email))
+	if err != nil {
+		log.Info("password reset requested for unknown email")
+		return nil
+	}
+	if user.Status == models.UserStatusDisabled {
+		log.Info("password reset requested for disabled account", applog.Fields{"user_id": user.ID})
+		return nil
+	}
+
+	token, err := s.signer.GenerateScopedToken(user.ID, auth.PurposePasswordReset, fingerprint(user.PasswordHash), s.cfg.PasswordResetTTL)
+	if err != nil {
+		return err
+	}
+
+	link := withToken(s.cfg.PasswordResetURL, token)
+	return s.mailer.Send(mail.Message{
+		To:      []string{user.Email},
+		Subject: fmt.Sprintf("Reset your %s password", s.cfg.AppName),
+		Text: fmt.Sprintf("Hi %s,\n\nSomeone requested a password reset for your account. Open the link below to choose a new password:\n\n%s\n\nThe link expires in %s. If you did not request this, you can ignore this email.\n",
+			user.Username, link, s.cfg.PasswordResetTTL),
+		HTML: fmt.Sprintf("<p>Hi %s,</p><p>Someone requested a password reset for your account.</p><p><a href=\"%s\">Choose a new password</a></p><p>The link expires in %s. If you did not request this, you can ignore this email.</p>",
+			html.EscapeString(user.Username), html.EscapeString(link), s.cfg.PasswordResetTTL),
+	})
+}
+
+// ResetPassword sets a new password and revokes every session of the user
+func (s *service) ResetPassword(token, password string) error {
+	claims, err := s.signer.ValidateScopedToken(token, auth.PurposePasswordReset)
+	if err != nil {
+		return ErrInvalidToken
+	}
+	user, err := s.userService.GetByID(uint(claims.UserID))
+	if err != nil {
+		return ErrInvalidToken
+	}
+	// The fingerprint changes with the password, so each token works only once
+	if claims.Fingerprint != fingerprint(user.PasswordHash) || user.Status == models.UserStatusDisabled {
+		return ErrInvalidToken
+	}
+	if err := s.ValidatePassword(password); err != nil {
+		return err
+	}
+
+	if err := s.userService.ResetPassword(uint(user.ID), password); err != nil {
+		return err
+	}
+	if err := s.tokenService.RevokeAllForUser(user.ID); err != nil {
+		return err
+	}
+	// Receiving the reset mail proves ownership of the address
+	return s.userService.MarkEmailVerified(uint(user.ID))
+}
+
+func (s *service) SendVerificationEmail(userID int) error {
+	user, err := s.userService.GetByID(uint(userID))
+	if err != nil {
+		return err
+	}
+	if user.EmailVerifiedAt != nil {
+		return ErrAlreadyVerified
+	}
+
+	token, err := s.signer.GenerateScopedToken(user.ID, auth.PurposeEmailVerification, fingerprint(user.Email), s.cfg.EmailVerificationTTL)
+	if err != nil {
+		return err
+	}
+
+	link := withToken(s.cfg.EmailVerificationURL, token)
+	return s.mailer.Send(mail.Message{
+		To:      []string{user.Email},
+		Subject: fmt.Sprintf("Verify your email for %s", s.cfg.AppName),
+		Text: fmt.Sprintf("Hi %s,\n\nPlease confirm your email address by opening the link below:\n\n%s\n\nThe link expires in %s.\n",
+			user.Username, link, s.cfg.EmailVerificationTTL),
+		HTML: fmt.Sprintf("<p>Hi %s,</p><p>Please confirm your email address.</p><p><a href=\"%s\">Verify email</a></p><p>The link expires in %s.</p>",
+			html.EscapeString(user.Username), html.EscapeString(link), s.cfg.EmailVerificationTTL),
+	})
+}
+
+// RequestVerificationEmail resends the verification link by email address.
+// Like RequestPasswordReset it does not reveal whether the account exists.
+func (s *service) RequestVerificationEmail(email string) error {
+	user, err := s.userService.GetByEmail(strings.TrimSpace(email))
+	if err != nil || user.EmailVerifiedAt != nil || user.Status == models.UserStatusDisabled {
+		return nil
+	}
+	return s.SendVerificationEmail(user.ID)
+}
+
+func (s *service) VerifyEmail(token string) error {
+	claims, err := s.signer.ValidateScopedToken(token, auth.PurposeEmailVerification)
+	if err != nil {
+		return ErrInvalidToken
+	}
+	user, err := s.userService.GetByID(uint(claims.UserID))
+	if err != nil || claims.Fingerprint != fingerprint(user.Email) {
+		return ErrInvalidToken
+	}
+	return s.userService.MarkEmailVerified(uint(user.ID))
+}
+
+// ValidatePassword enforces the password policy of validation.Validator
+func (s *service) ValidatePassword(password string) error {
+	if err := s.validator.ValidatePassword(password); err != nil {
+		return &WeakPasswordError{Err: err}
+	}
+	return nil
+}
+
+func (s *service) VerificationRequired() bool {
+	return s.cfg.RequireVerification
+}
+
+// fingerprint binds a token to a value without exposing it in the token
+func fingerprint(value string) string {
+	return utils.HashToken(value)[:32]
+}
+
+func withToken(base, token string) string {
+	u, err := url.Parse(base)
+	if err != nil {
+		return base + "?token=" + url.QueryEscape(token)
+	}
+	q := u.Query()
+	q.Set("token", token)
+	u.RawQuery = q.Encode()
+	return u.String()
+}
@@ -2,7 +2,9 @@ package analytics
 
 import (
 	"time"
+	"web-porto-backend/internal/domain/dto"
 	"web-porto-backend/internal/domain/models"
+	"web-porto-backend/internal/repositories/scopes"
 
 	"gorm.io/gorm"
 )
@@ -83,6 +85,27 @@ func (s *ContentViewService) GetContentViewCount(contentID string, contentType C
 	return int(count), err
 }
 
+// ListContentViews pages through raw content views, newest first. An empty
+// contentID or contentType matches every item.
+func (s *ContentViewService) ListContentViews(contentID string, contentType ContentType, q dto.CursorQuery) ([]ContentView, dto.CursorResult, error) {
+	query := s.db.Model(&ContentView{})
+	if contentID != "" {
+		query = query.Where("content_id = ?", contentID)
+	}
+	if contentType != "" {
+		query = query.Where("type = ?", string(contentType))
+	}
+
+	var views []ContentView
+	if err := query.Scopes(scopes.Keyset("timestamp", "id", q)).Find(&views).Error; err != nil {
+		return nil, dto.CursorResult{}, err
+	}
+	views, result := scopes.KeysetPage(views, q, func(v ContentView) dto.Cursor {
+		return dto.Cursor{CreatedAt: v.Timestamp, ID: int64(v.ID)}
+	})
+	return views, result, nil
+}
+
 // GetContentViewAnalytics returns analytics data for a specific content
 func (s *ContentViewService) GetContentViewAnalytics(
 	contentID string,
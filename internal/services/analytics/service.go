@@ -3,6 +3,7 @@ package analytics
 import (
 	"time"
 	"web-porto-backend/internal/adapters/websocket"
+	"web-porto-backend/internal/domain/dto"
 	"web-porto-backend/internal/domain/models"
 	repo "web-porto-backend/internal/repositories/analytics"
 )
@@ -21,14 +22,19 @@ type Service interface {
 	GetStatsWithFilter(page string, start, end *string, country string) (*ViewStats, error)
 	GetTimeSeries(page string, start, end string, interval string) ([]repo.TimeSeriesPoint, error)
 	GetTopPages(limit int) ([]repo.PageCount, error)
+	ListViews(filter ViewFilter, q dto.CursorQuery) ([]models.PageView, dto.CursorResult, error)
 	SetWebsocketManager(wsManager *websocket.Manager)
 
 	// Content View methods
 	TrackContentView(contentID string, contentType ContentType, visitorID string, userAgent string, referrer string, ip string) error
 	GetContentViewCount(contentID string, contentType ContentType) (int, error)
 	GetContentViewAnalytics(contentID string, contentType ContentType, period string, limit int) ([]AnalyticsDataPoint, error)
+	ListContentViews(contentID string, contentType ContentType, q dto.CursorQuery) ([]ContentView, dto.CursorResult, error)
 }
 
+// ViewFilter narrows ListViews results
+type ViewFilter = repo.ViewFilter
+
 type service struct {
 	repo               repo.Repository
 	wsManager          *websocket.Manager
@@ -131,6 +137,10 @@ func (s *service) GetTopPages(limit int) ([]repo.PageCount, error) {
 	return s.repo.GetTopPages(limit)
 }
 
+func (s *service) ListViews(filter ViewFilter, q dto.CursorQuery) ([]models.PageView, dto.CursorResult, error) {
+	return s.repo.ListViews(filter, q)
+}
+
 // Content View method delegations
 func (s *service) TrackContentView(contentID string, contentType ContentType, visitorID string, userAgent string, referrer string, ip string) error {
 	if s.contentViewService == nil {
@@ -152,3 +162,10 @@ func (s *service) GetContentViewAnalytics(contentID string, contentType ContentT
 	}
 	return s.contentViewService.GetContentViewAnalytics(contentID, contentType, period, limit)
 }
+
+func (s *service) ListContentViews(contentID string, contentType ContentType, q dto.CursorQuery) ([]ContentView, dto.CursorResult, error) {
+	if s.contentViewService == nil {
+		return []ContentView{}, dto.CursorResult{}, nil
+	}
+	return s.contentViewService.ListContentViews(contentID, contentType, q)
+}
@@ -0,0 +1,194 @@
+package apitoken
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+	applog "web-porto-backend/common/logger"
+	"web-porto-backend/common/utils"
+	"web-porto-backend/internal/auth"
+	"web-porto-backend/internal/domain/models"
+	apiTokenRepo "web-porto-backend/internal/repositories/apitoken"
+	roleSrvc "web-porto-backend/internal/services/role"
+	userSrvc "web-porto-backend/internal/services/user"
+
+	"gorm.io/gorm"
+)
+
+var (
+	ErrInvalidToken    = errors.New("invalid or expired API token")
+	ErrTokenNotFound   = errors.New("API token not found")
+	ErrNameRequired    = errors.New("token name is required")
+	ErrInvalidScope    = errors.New("invalid scope")
+	ErrScopeNotGranted = errors.New("scope exceeds your permissions")
+	ErrExpiryInPast    = errors.New("expiry must be in the future")
+)
+
+// lastUsedResolution limits last-used writes to one per token per interval
+const lastUsedResolution = time.Minute
+
+type Service interface {
+	Create(userID int, name string, scopes []string, expiresAt *time.Time) (string, *models.APIToken, error)
+	ListForUser(userID int) ([]models.APIToken, error)
+	ListAll() ([]models.APIToken, error)
+	// Revoke revokes a token owned by ownerID; ownerID 0 revokes any token
+	Revoke(id, ownerID int) error
+	AuthenticateAPIToken(raw, ip string) (*auth.Claims, error)
+}
+
+type service struct {
+	repo        apiTokenRepo.Repository
+	userService userSrvc.Service
+	roleService roleSrvc.Service
+}
+
+func NewService(repo apiTokenRepo.Repository, userService userSrvc.Service, roleService roleSrvc.Service) Service {
+	return &service{repo: repo, userService: userService, roleService: roleService}
+}
+
+// Create issues a new token. The raw value is returned once and never stored.
+// Scopes default to everything the user may do, and can never exceed it.
+func (s *service) Create(userID int, name string, scopes []string, expiresAt *time.Time) (string, *models.APIToken, error) {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return "", nil, ErrNameRequired
+	}
+	if expiresAt != nil && !expiresAt.After(time.Now()) {
+		return "", nil, ErrExpiryInPast
+	}
+
+	user, err := s.userService.GetByID(uint(userID))
+	if err != nil {
+		return "", nil, err
+	}
+	granted, err := s.roleService.PermissionsForUser(user)
+	if err != nil {
+		return "", nil, err
+	}
+
+	if len(scopes) == 0 {
+		scopes = []string{auth.PermissionAll}
+	}
+	for _, scope := range scopes {
+		if !auth.IsValidPermission(scope) {
+			return "", nil, fmt.Errorf("%w: %s", ErrInvalidScope, scope)
+		}
+		if scope != auth.PermissionAll && !auth.HasPermission(granted, scope) {
+			return "", nil, fmt.Errorf("%w: %s", ErrScopeNotGranted, scope)
+		}
+	}
+
+	secret, _, err := utils.GenerateSecureToken()
+	if err != nil {
+		return "", nil, err
+	}
+	raw := auth.APITokenPrefix + secret
+
+	token := &models.APIToken{
+		UserID:    userID,
+		Name:      name,
+		TokenHash: utils.HashToken(raw),
+		Prefix:    raw[:len(auth.APITokenPrefix)+6],
+		Scopes:    models.StringArray(scopes),
+		ExpiresAt: expiresAt,
+	}
+	if err := s.repo.Create(token); err != nil {
+		return "", nil, err
+	}
+	return raw, token, nil
+}
+
+func (s *service) ListForUser(userID int) ([]models.APIToken, error) {
+	return s.repo.FindByUser(userID)
+}
+
+func (s *service) ListAll() ([]models.APIToken, error) {
+	return s.repo.FindAll()
+}
+
+func (s *service) Revoke(id, ownerID int) error {
+	token, err := s.repo.FindByID(id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrTokenNotFound
+		}
+		return err
+	}
+	if ownerID != 0 && token.UserID != ownerID {
+		return ErrTokenNotFound
+	}
+	if err := s.repo.Revoke(id); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			// Already revoked
+			return nil
+		}
+		return err
+	}
+	return nil
+}
+
+// AuthenticateAPIToken resolves a raw token into claims. Permissions are the
+// owner's current permissions narrowed by the token scopes, so demoting or
+// disabling the owner takes effect immediately.
+func (s *service) AuthenticateAPIToken(raw, ip string) (*auth.Claims, error) {
+	token, err := s.repo.FindByHash(utils.HashToken(raw))
+	if err != nil {
+		return nil, ErrInvalidToken
+	}
+	now := time.Now()
+	if !token.IsActive(now) {
+		return nil, ErrInvalidToken
+	}
+
+	user, err := s.userService.GetByID(uint(token.UserID))
+	if err != nil || user.Status == models.UserStatusDisabled {
+		return nil, ErrInvalidToken
+	}
+	granted, err := s.roleService.PermissionsForUser(user)
+	if err != nil {
+		return nil, err
+	}
+
+	if token.LastUsedAt == nil || now.Sub(*token.LastUsedAt) >= lastUsedResolution {
+		if err := s.repo.TouchLastUsed(token.ID, now, ip); err != nil {
+			applog.GetLogger().WithFields(applog.Fields{"service": "apitoken"}).Error("failed updating last used", applog.Fields{"token_id": token.ID, "error": err.Error()})
+		}
+	}
+
+	claims := &auth.Claims{
+		UserID:      user.ID,
+		Username:    user.Email,
+		Role:        user.Role,
+		Permissions: effectivePermissions(granted, token.Scopes),
+	}
+	claims.Subject = "api_token:" + strconv.Itoa(token.ID)
+	return claims, nil
+}
+
+// effectivePermissions intersects the owner's permissions with the token scopes
+func effectivePermissions(granted, scopes []string) []string {
+	seen := make(map[string]bool)
+	var result []string
+	add := func(p string) {
+		if !seen[p] {
+			seen[p] = true
+			result = append(result, p)
+		}
+	}
+	for _, scope := range scopes {
+		if auth.HasPermission(granted, scope) {
+			add(scope)
+		}
+	}
+	for _, p := range granted {
+		if auth.HasPermission(scopes, p) {
+			add(p)
+		}
+	}
+	if result == nil {
+		result = []string{}
+	}
+	return result
+}
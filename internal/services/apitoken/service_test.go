@@ -0,0 +1,182 @@
+package apitoken
+
+import (
+	"errors"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+	"web-porto-backend/internal/auth"
+	"web-porto-backend/internal/domain/models"
+	apiTokenRepo "web-porto-backend/internal/repositories/apitoken"
+	roleSrvc "web-porto-backend/internal/services/role"
+	userSrvc "web-porto-backend/internal/services/user"
+
+	"gorm.io/gorm"
+)
+
+type fakeTokens struct {
+	apiTokenRepo.Repository
+	tokens []*models.APIToken
+}
+
+func (r *fakeTokens) Create(token *models.APIToken) error {
+	token.ID = len(r.tokens) + 1
+	r.tokens = append(r.tokens, token)
+	return nil
+}
+
+func (r *fakeTokens) FindByHash(hash string) (*models.APIToken, error) {
+	for _, token := range r.tokens {
+		if token.TokenHash == hash {
+			copied := *token
+			return &copied, nil
+		}
+	}
+	return nil, gorm.ErrRecordNotFound
+}
+
+func (r *fakeTokens) TouchLastUsed(id int, at time.Time, ip string) error {
+	r.tokens[id-1].LastUsedAt, r.tokens[id-1].LastUsedIP = &at, ip
+	return nil
+}
+
+// fakeUsers holds user 1, whose permissions are in fakeRoles
+type fakeUsers struct {
+	userSrvc.Service
+	user models.User
+}
+
+func (u *fakeUsers) GetByID(uint) (*models.User, error) {
+	copied := u.user
+	return &copied, nil
+}
+
+type fakeRoles struct {
+	roleSrvc.Service
+	permissions []string
+}
+
+func (r *fakeRoles) PermissionsForUser(*models.User) ([]string, error) {
+	return r.permissions, nil
+}
+
+func newTestService(permissions ...string) (Service, *fakeTokens, *fakeUsers, *fakeRoles) {
+	tokens := &fakeTokens{}
+	users := &fakeUsers{user: models.User{ID: 1, Email: "alice@example.com", Role: "editor", Status: models.UserStatusActive}}
+	roles := &fakeRoles{permissions: permissions}
+	return NewService(tokens, users, roles), tokens, users, roles
+}
+
+func sorted(perms []string) string {
+	perms = append([]string(nil), perms...)
+	sort.Strings(perms)
+	return strings.Join(perms, ",")
+}
+
+func TestEffectivePermissions(t *testing.T) {
+	tests := []struct {
+		name    string
+		granted []string
+		scopes  []string
+		want    string
+	}{
+		{"scope within permissions", []string{auth.PermArticleWrite, auth.PermMediaUpload}, []string{auth.PermArticleWrite}, "article:write"},
+		{"scope beyond permissions", []string{auth.PermArticleWrite}, []string{auth.PermArticleWrite, auth.PermUsersManage}, "article:write"},
+		{"everything scope", []string{auth.PermArticleWrite, auth.PermMediaUpload}, []string{auth.PermissionAll}, "article:write,media:upload"},
+		{"admin with narrow scope", []string{auth.PermissionAll}, []string{auth.PermArticlePublish}, "article:publish"},
+		{"wildcard scope", []string{auth.PermArticleWrite, auth.PermArticlePublish, auth.PermPageWrite}, []string{"article:*"}, "article:publish,article:write"},
+		{"wildcard permission", []string{"article:*"}, []string{auth.PermArticleDelete, auth.PermPageWrite}, "article:delete"},
+		{"no overlap", []string{auth.PermArticleWrite}, []string{auth.PermPageWrite}, ""},
+		{"no permissions", nil, []string{auth.PermissionAll}, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := effectivePermissions(tt.granted, tt.scopes)
+			if got == nil || sorted(got) != tt.want {
+				t.Fatalf("effectivePermissions(%v, %v) = %v, want %s", tt.granted, tt.scopes, got, tt.want)
+			}
+			for _, p := range got {
+				if !auth.HasPermission(tt.granted, p) || !auth.HasPermission(tt.scopes, p) {
+					t.Fatalf("%s is not both granted and in scope", p)
+				}
+			}
+		})
+	}
+}
+
+func TestCreateChecksScopes(t *testing.T) {
+	s, _, _, _ := newTestService(auth.PermArticleWrite, auth.PermMediaUpload)
+
+	if _, _, err := s.Create(1, "ci", []string{auth.PermUsersManage}, nil); !errors.Is(err, ErrScopeNotGranted) {
+		t.Fatalf("scope beyond permissions = %v, want ErrScopeNotGranted", err)
+	}
+	if _, _, err := s.Create(1, "ci", []string{"article:*"}, nil); !errors.Is(err, ErrScopeNotGranted) {
+		t.Fatalf("wildcard over partly held permissions = %v, want ErrScopeNotGranted", err)
+	}
+	if _, _, err := s.Create(1, "ci", []string{"article:read"}, nil); !errors.Is(err, ErrInvalidScope) {
+		t.Fatalf("unknown scope = %v, want ErrInvalidScope", err)
+	}
+	past := time.Now().Add(-time.Hour)
+	if _, _, err := s.Create(1, "ci", nil, &past); !errors.Is(err, ErrExpiryInPast) {
+		t.Fatalf("past expiry = %v, want ErrExpiryInPast", err)
+	}
+	if _, _, err := s.Create(1, " ", nil, nil); !errors.Is(err, ErrNameRequired) {
+		t.Fatalf("blank name = %v, want ErrNameRequired", err)
+	}
+
+	raw, token, err := s.Create(1, "ci", nil, nil)
+	if err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+	if !auth.IsAPIToken(raw) || !strings.HasPrefix(raw, token.Prefix) || strings.Contains(token.TokenHash, raw) {
+		t.Fatalf("token %q stored as %+v", raw, token)
+	}
+	if sorted(token.Scopes) != auth.PermissionAll {
+		t.Fatalf("default scopes = %v", token.Scopes)
+	}
+}
+
+func TestAuthenticateAPIToken(t *testing.T) {
+	s, tokens, users, roles := newTestService(auth.PermArticleWrite, auth.PermArticlePublish, auth.PermMediaUpload)
+	raw, _, err := s.Create(1, "deploy", []string{auth.PermArticleWrite, auth.PermArticlePublish}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	claims, err := s.AuthenticateAPIToken(raw, "10.0.0.1")
+	if err != nil {
+		t.Fatalf("AuthenticateAPIToken: %v", err)
+	}
+	if claims.UserID != 1 || claims.Subject != "api_token:1" || sorted(claims.Permissions) != "article:publish,article:write" {
+		t.Fatalf("claims = %+v", claims)
+	}
+	if tokens.tokens[0].LastUsedIP != "10.0.0.1" {
+		t.Fatalf("last use not recorded: %+v", tokens.tokens[0])
+	}
+
+	// Demoting the owner narrows the token at once
+	roles.permissions = []string{auth.PermArticleWrite}
+	if claims, _ := s.AuthenticateAPIToken(raw, ""); sorted(claims.Permissions) != auth.PermArticleWrite {
+		t.Fatalf("permissions after demotion = %v", claims.Permissions)
+	}
+
+	users.user.Status = models.UserStatusDisabled
+	if _, err := s.AuthenticateAPIToken(raw, ""); !errors.Is(err, ErrInvalidToken) {
+		t.Fatalf("token of a disabled user = %v, want ErrInvalidToken", err)
+	}
+	users.user.Status = models.UserStatusActive
+
+	expired := time.Now().Add(-time.Second)
+	tokens.tokens[0].ExpiresAt = &expired
+	if _, err := s.AuthenticateAPIToken(raw, ""); !errors.Is(err, ErrInvalidToken) {
+		t.Fatalf("expired token = %v, want ErrInvalidToken", err)
+	}
+	tokens.tokens[0].ExpiresAt, tokens.tokens[0].RevokedAt = nil, &expired
+	if _, err := s.AuthenticateAPIToken(raw, ""); !errors.Is(err, ErrInvalidToken) {
+		t.Fatalf("revoked token = %v, want ErrInvalidToken", err)
+	}
+	if _, err := s.AuthenticateAPIToken(auth.APITokenPrefix+"unknown", ""); !errors.Is(err, ErrInvalidToken) {
+		t.Fatalf("unknown token = %v, want ErrInvalidToken", err)
+	}
+}
@@ -0,0 +1,142 @@
+package article
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+	"web-porto-backend/internal/domain/dto"
+	"web-porto-backend/internal/domain/models"
+)
+
+// articleState is the revisioned part of an article. Status and slug are kept
+// for reference in diffs but are not changed by a restore.
+type articleState struct {
+	Title            string                 `json:"title"`
+	Slug             string                 `json:"slug"`
+	Status           string                 `json:"status"`
+	PublishedAt      *time.Time             `json:"publishedAt"`
+	Excerpt          string                 `json:"excerpt"`
+	FeaturedImageURL string                 `json:"featuredImageUrl"`
+	Categories       []dto.CategoryResponse `json:"categories"`
+	Tags             []dto.TagResponse      `json:"tags"`
+	Images           []dto.ArticleImageData `json:"images"`
+	Videos           []dto.ArticleVideoData `json:"videos"`
+	Metadata         map[string]interface{} `json:"metadata"`
+	SEO              *dto.SEO               `json:"seo,omitempty"` // nil in revisions older than the SEO block
+	Content          string                 `json:"content"`
+}
+
+func newArticleState(article *models.Article) articleState {
+	state := articleState{
+		Title:            article.Title,
+		Slug:             article.Slug,
+		Status:           article.Status,
+		PublishedAt:      article.PublishedAt,
+		Excerpt:          article.Excerpt,
+		FeaturedImageURL: article.FeaturedImageURL,
+		Categories:       []dto.CategoryResponse{},
+		Tags:             []dto.TagResponse{},
+		Images:           []dto.ArticleImageData{},
+		Videos:           []dto.ArticleVideoData{},
+		Metadata:         map[string]interface{}{},
+		SEO:              &dto.SEO{},
+		Content:          article.Content,
+	}
+	for _, category := range article.Categories {
+		state.Categories = append(state.Categories, dto.CategoryResponse{ID: category.ID, Name: category.Name, Slug: category.Slug})
+	}
+	for _, tag := range article.Tags {
+		state.Tags = append(state.Tags, dto.TagResponse{ID: tag.ID, Name: tag.Name, Slug: tag.Slug})
+	}
+	for _, img := range article.Images {
+		state.Images = append(state.Images, dto.ArticleImageData{URL: img.URL, Caption: img.Caption, AltText: img.AltText, SortOrder: img.SortOrder})
+	}
+	for _, vid := range article.Videos {
+		state.Videos = append(state.Videos, dto.ArticleVideoData{URL: vid.URL, Caption: vid.Caption, SortOrder: vid.SortOrder})
+	}
+	if article.Metadata != "" {
+		_ = json.Unmarshal([]byte(article.Metadata), &state.Metadata)
+	}
+	*state.SEO = dto.SEO(article.SEO)
+	return state
+}
+
+// saveRevision snapshots an article before it is modified
+func (s *Service) saveRevision(article *models.Article, editorID int, summary string) error {
+	if s.revisionService == nil {
+		return nil
+	}
+	if _, err := s.revisionService.Snapshot(article.ID, article.Title, newArticleState(article), editorID, summary); err != nil {
+		return fmt.Errorf("failed to save revision: %w", err)
+	}
+	return nil
+}
+
+// RevisionState returns the current revisioned state of an article
+func (s *Service) RevisionState(id string) (interface{}, error) {
+	article, err := s.articleRepo.GetByID(id)
+	if err != nil {
+		return nil, err
+	}
+	return newArticleState(article), nil
+}
+
+// RestoreRevision applies a stored revision to the article as a regular update
+func (s *Service) RestoreRevision(id string, revision *models.Revision, editorID int) (interface{}, error) {
+	var state articleState
+	if err := s.revisionService.Decode(revision, &state); err != nil {
+		return nil, err
+	}
+
+	current, err := s.articleRepo.GetByID(id)
+	if err != nil {
+		return nil, err
+	}
+
+	// Keep the live slug so restoring an old title does not break URLs
+	req := dto.UpdateArticleRequest{
+		Title:           &state.Title,
+		Slug:            &current.Slug,
+		Excerpt:         &state.Excerpt,
+		Content:         &state.Content,
+		Images:          state.Images,
+		Videos:          state.Videos,
+		Metadata:        state.Metadata,
+		SEO:             state.SEO,
+		EditorID:        editorID,
+		RevisionSummary: fmt.Sprintf("Before restoring version %d", revision.Version),
+	}
+	// An empty featured image would make UpdateArticle delete the current
+	// file, which the restore must never do
+	if state.FeaturedImageURL != "" {
+		req.FeaturedImageURL = &state.FeaturedImageURL
+	}
+	if req.Images == nil {
+		req.Images = []dto.ArticleImageData{}
+	}
+	if req.Videos == nil {
+		req.Videos = []dto.ArticleVideoData{}
+	}
+	if _, err := s.UpdateArticle(id, req); err != nil {
+		return nil, err
+	}
+
+	// UpdateArticle leaves taxonomies alone when none are given; a restore
+	// replaces them, including clearing them
+	categoryIDs := make([]int, 0, len(state.Categories))
+	for _, category := range state.Categories {
+		categoryIDs = append(categoryIDs, category.ID)
+	}
+	if err := s.articleRepo.UpdateArticleCategories(id, categoryIDs); err != nil {
+		return nil, err
+	}
+	tagIDs := make([]int, 0, len(state.Tags))
+	for _, tag := range state.Tags {
+		tagIDs = append(tagIDs, tag.ID)
+	}
+	if err := s.articleRepo.UpdateArticleTags(id, tagIDs); err != nil {
+		return nil, err
+	}
+
+	return s.GetArticleByID(id)
+}
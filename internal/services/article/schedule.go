@@ -0,0 +1,41 @@
+package article
+
+import (
+	"time"
+	"web-porto-backend/internal/domain/dto"
+	"web-porto-backend/internal/domain/models"
+	"web-porto-backend/internal/services/audit"
+	"web-porto-backend/internal/services/schedule"
+)
+
+// applyPublishDate moves an article between "published" and "scheduled"
+// depending on whether its publish date lies in the future
+func applyPublishDate(article *models.Article) {
+	article.Status, article.PublishedAt = schedule.ResolveStatus(article.Status, article.PublishedAt)
+}
+
+// syncSchedule keeps the pending jobs of an article in line with its status
+func (s *Service) syncSchedule(article *models.Article, unpublishAt *time.Time, editorID int) {
+	schedule.Sync(s.scheduleService, audit.EntityArticle, article.ID, article.Status, article.PublishedAt, unpublishAt, editorID)
+}
+
+// PublishScheduled publishes an article when its scheduled job is due
+func (s *Service) PublishScheduled(id string, at time.Time) error {
+	status := "published"
+	_, err := s.UpdateArticle(id, dto.UpdateArticleRequest{
+		Status:          &status,
+		PublishAt:       &at,
+		RevisionSummary: "Before scheduled publish",
+	})
+	return err
+}
+
+// UnpublishScheduled moves an article back to draft when its expiry is due
+func (s *Service) UnpublishScheduled(id string) error {
+	status := "draft"
+	_, err := s.UpdateArticle(id, dto.UpdateArticleRequest{
+		Status:          &status,
+		RevisionSummary: "Before scheduled unpublish",
+	})
+	return err
+}
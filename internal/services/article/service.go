@@ -1,9 +1,9 @@
 package article
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
-	"os"
 	"strconv"
 	"strings"
 	"time"
@@ -12,6 +12,9 @@ import (
 	articleRepo "web-porto-backend/internal/repositories/article"
 	categoryRepo "web-porto-backend/internal/repositories/category"
 	tagRepo "web-porto-backend/internal/repositories/tag"
+	mediaService "web-porto-backend/internal/services/media"
+	revisionService "web-porto-backend/internal/services/revision"
+	scheduleService "web-porto-backend/internal/services/schedule"
 	userService "web-porto-backend/internal/services/user"
 
 	"github.com/google/uuid"
@@ -43,11 +46,14 @@ func getInt(data map[string]interface{}, key string) int {
 
 // Service handles business logic for articles
 type Service struct {
-	articleRepo  articleRepo.Repository
-	categoryRepo categoryRepo.Repository
-	tagRepo      tagRepo.Repository
-	userService  userService.Service
-	db           *gorm.DB
+	articleRepo     articleRepo.Repository
+	categoryRepo    categoryRepo.Repository
+	tagRepo         tagRepo.Repository
+	userService     userService.Service
+	revisionService revisionService.Service
+	scheduleService scheduleService.Service
+	mediaService    mediaService.Service
+	db              *gorm.DB
 }
 
 // NewService creates a new article service
@@ -56,14 +62,20 @@ func NewService(
 	categoryRepo categoryRepo.Repository,
 	tagRepo tagRepo.Repository,
 	userService userService.Service,
+	revisionService revisionService.Service,
+	scheduleService scheduleService.Service,
+	mediaService mediaService.Service,
 	db *gorm.DB,
 ) *Service {
 	return &Service{
-		articleRepo:  articleRepo,
-		categoryRepo: categoryRepo,
-		tagRepo:      tagRepo,
-		userService:  userService,
-		db:           db,
+		articleRepo:     articleRepo,
+		categoryRepo:    categoryRepo,
+		tagRepo:         tagRepo,
+		userService:     userService,
+		revisionService: revisionService,
+		scheduleService: scheduleService,
+		mediaService:    mediaService,
+		db:              db,
 	}
 }
 
@@ -230,6 +242,9 @@ func (s *Service) CreateArticle(req dto.CreateArticleRequest) (*dto.ArticleRespo
 		AuthorID:         authorID,
 		ReadTime:         readTime,
 	}
+	if req.SEO != nil {
+		article.SEO = models.SEO(*req.SEO)
+	}
 
 	if req.PublishAt != nil && !req.PublishAt.IsZero() {
 		article.PublishedAt = req.PublishAt
@@ -237,6 +252,7 @@ func (s *Service) CreateArticle(req dto.CreateArticleRequest) (*dto.ArticleRespo
 		now := time.Now()
 		article.PublishedAt = &now
 	}
+	applyPublishDate(article)
 
 	// Handle metadata
 	metadata := map[string]interface{}{}
@@ -305,6 +321,8 @@ func (s *Service) CreateArticle(req dto.CreateArticleRequest) (*dto.ArticleRespo
 		s.articleRepo.UpdateArticleVideos(article.ID, videos)
 	}
 
+	s.syncSchedule(article, req.UnpublishAt, req.EditorID)
+
 	// Final fetch to get media
 	article, _ = s.articleRepo.GetByID(article.ID)
 
@@ -329,18 +347,20 @@ func (s *Service) GetArticleBySlug(slug string) (*dto.ArticleResponse, error) {
 		return nil, err
 	}
 
-	// Update view count
-	article.ViewCount++
-	if err := s.articleRepo.Update(article); err != nil {
-		// Log error but don't fail the request
-		fmt.Printf("Error updating view count: %v\n", err)
+	// Update view count; CMS previews of unpublished articles don't count
+	if models.IsPubliclyVisible(article.Status, article.PublishedAt) {
+		article.ViewCount++
+		if err := s.articleRepo.Update(article); err != nil {
+			// Log error but don't fail the request
+			fmt.Printf("Error updating view count: %v\n", err)
+		}
 	}
 
 	return s.mapArticleToResponse(article), nil
 }
 
-// GetArticlesByCategorySlug retrieves articles by category slug
-func (s *Service) GetArticlesByCategorySlug(slug string, page, size int) (*dto.PaginatedResponse, error) {
+// GetArticlesByCategorySlug retrieves articles with the given status by category slug
+func (s *Service) GetArticlesByCategorySlug(slug, status string, page, size int) (*dto.PaginatedResponse, error) {
 	// Get category by slug first
 	category, err := s.categoryRepo.FindBySlug(slug)
 	if err != nil {
@@ -349,41 +369,56 @@ func (s *Service) GetArticlesByCategorySlug(slug string, page, size int) (*dto.P
 
 	// Get articles by category ID
 	offset := (page - 1) * size
-	articles, total, err := s.articleRepo.GetByCategory(category.ID, size, offset)
+	articles, total, err := s.articleRepo.GetByCategory(category.ID, status, size, offset)
 	if err != nil {
 		return nil, err
 	}
 
-	// Map articles to response objects
-	articlesResponse := make([]interface{}, 0, len(articles))
-	for _, article := range articles {
-		articlesResponse = append(articlesResponse, s.mapArticleToListResponse(article))
+	return s.paginate(articles, total, page, size), nil
+}
+
+// GetArticlesByTag retrieves articles with the given status by tag name
+func (s *Service) GetArticlesByTag(name, status string, page, size int) (*dto.PaginatedResponse, error) {
+	tag, err := s.tagRepo.GetByName(name)
+	if err != nil {
+		return nil, fmt.Errorf("tag not found: %w", err)
 	}
 
-	// Create pagination info
-	pagination := dto.PaginationResponse{
-		TotalCount:  total,
-		CurrentPage: page,
-		PageSize:    size,
-		TotalPages:  int((total + int64(size) - 1) / int64(size)),
-		HasNext:     int64(page*size) < total,
-		HasPrevious: page > 1,
+	offset := (page - 1) * size
+	articles, total, err := s.articleRepo.GetByTag(tag.ID, status, size, offset)
+	if err != nil {
+		return nil, err
 	}
 
-	return &dto.PaginatedResponse{
-		Data:       articlesResponse,
-		Pagination: pagination,
-	}, nil
+	return s.paginate(articles, total, page, size), nil
+}
+
+// ListArticlesByAuthor retrieves a paginated list of an author's articles with the given status
+func (s *Service) ListArticlesByAuthor(authorID int, status string, page, size int) (*dto.PaginatedResponse, error) {
+	offset := (page - 1) * size
+	articles, total, err := s.articleRepo.GetByAuthorID(authorID, status, size, offset)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.paginate(articles, total, page, size), nil
 }
 
-// ListArticles retrieves a paginated list of articles
-func (s *Service) ListArticles(page, size int) (*dto.PaginatedResponse, error) {
+// ListArticles retrieves a paginated list of articles with the given status,
+// filtered and sorted by q. models.StatusPublished lists only what visitors
+// may see.
+func (s *Service) ListArticles(page, size int, status string, q dto.ListQuery) (*dto.PaginatedResponse, error) {
 	offset := (page - 1) * size
-	articles, total, err := s.articleRepo.GetAll(size, offset)
+	articles, total, err := s.articleRepo.GetAll(status, q, size, offset)
 	if err != nil {
 		return nil, err
 	}
 
+	return s.paginate(articles, total, page, size), nil
+}
+
+// paginate maps a page of articles to a paginated list response
+func (s *Service) paginate(articles []*models.Article, total int64, page, size int) *dto.PaginatedResponse {
 	// Map articles to response objects
 	articlesResponse := make([]interface{}, 0, len(articles))
 	for _, article := range articles {
@@ -403,7 +438,7 @@ func (s *Service) ListArticles(page, size int) (*dto.PaginatedResponse, error) {
 	return &dto.PaginatedResponse{
 		Data:       articlesResponse,
 		Pagination: pagination,
-	}, nil
+	}
 }
 
 // UpdateArticle updates an existing article
@@ -419,7 +454,10 @@ func (s *Service) UpdateArticle(id string, req dto.UpdateArticleRequest) (*dto.A
 			TagIds:         req.TagIds,
 			TagIdStrs:      req.TagIdStrs,
 			PublishAt:      req.PublishAt,
+			UnpublishAt:    req.UnpublishAt,
 			Metadata:       req.Metadata,
+			SEO:            req.SEO,
+			EditorID:       req.EditorID,
 		}
 		if req.Title != nil {
 			createReq.Title = *req.Title
@@ -448,6 +486,11 @@ func (s *Service) UpdateArticle(id string, req dto.UpdateArticleRequest) (*dto.A
 		return nil, err
 	}
 
+	// Keep the previous version before anything is overwritten
+	if err := s.saveRevision(article, req.EditorID, req.RevisionSummary); err != nil {
+		return nil, err
+	}
+
 	if req.Title != nil {
 		article.Title = *req.Title
 	}
@@ -459,11 +502,7 @@ func (s *Service) UpdateArticle(id string, req dto.UpdateArticleRequest) (*dto.A
 	}
 	if req.FeaturedImageURL != nil {
 		if *req.FeaturedImageURL == "" && article.FeaturedImageURL != "" {
-			var media models.Media
-			if err := s.db.Where("file_url = ?", article.FeaturedImageURL).First(&media).Error; err == nil {
-				os.Remove(media.FilePath)
-				s.db.Delete(&media)
-			}
+			s.mediaService.DeleteByURL(context.Background(), article.FeaturedImageURL)
 		}
 		article.FeaturedImageURL = *req.FeaturedImageURL
 	}
@@ -490,6 +529,7 @@ func (s *Service) UpdateArticle(id string, req dto.UpdateArticleRequest) (*dto.A
 	if req.PublishAt != nil {
 		article.PublishedAt = req.PublishAt
 	}
+	applyPublishDate(article)
 
 	// Recalculate read time if content changed
 	if req.Content != nil && *req.Content != "" {
@@ -521,7 +561,9 @@ func (s *Service) UpdateArticle(id string, req dto.UpdateArticleRequest) (*dto.A
 			return nil, fmt.Errorf("failed to marshal metadata: %v", err)
 		}
 		article.Metadata = string(metadataJSON)
-		article.Metadata = "{}"
+	}
+	if req.SEO != nil {
+		article.SEO = models.SEO(*req.SEO)
 	}
 
 	// Update images if provided
@@ -561,6 +603,7 @@ func (s *Service) UpdateArticle(id string, req dto.UpdateArticleRequest) (*dto.A
 	if err := s.articleRepo.Update(article); err != nil {
 		return nil, err
 	}
+	s.syncSchedule(article, req.UnpublishAt, req.EditorID)
 
 	// Reload dari DB agar mendapatkan Categories dan Tags yang terbaru
 	updatedArticle, err := s.articleRepo.GetByID(id)
@@ -586,6 +629,7 @@ func (s *Service) AddArticleImage(articleID string, imageData dto.ArticleImageDa
 
 	images := article.Images
 	newImage := models.ArticleImage{
+		ID:        uuid.New().String(),
 		ArticleID: articleID,
 		URL:       imageData.URL,
 		Caption:   imageData.Caption,
@@ -616,6 +660,7 @@ func (s *Service) AddArticleVideo(articleID string, videoData dto.ArticleVideoDa
 
 	videos := article.Videos
 	newVideo := models.ArticleVideo{
+		ID:        uuid.New().String(),
 		ArticleID: articleID,
 		URL:       videoData.URL,
 		Caption:   videoData.Caption,
@@ -635,6 +680,46 @@ func (s *Service) AddArticleVideo(articleID string, videoData dto.ArticleVideoDa
 	}, nil
 }
 
+// DeleteArticleImage removes an image from an article; an image the article
+// does not have is not found
+func (s *Service) DeleteArticleImage(articleID, imageID string) error {
+	article, err := s.articleRepo.GetByID(articleID)
+	if err != nil {
+		return err
+	}
+
+	images := make([]models.ArticleImage, 0, len(article.Images))
+	for _, image := range article.Images {
+		if image.ID != imageID {
+			images = append(images, image)
+		}
+	}
+	if len(images) == len(article.Images) {
+		return gorm.ErrRecordNotFound
+	}
+	return s.articleRepo.UpdateArticleImages(articleID, images)
+}
+
+// DeleteArticleVideo removes a video from an article; a video the article
+// does not have is not found
+func (s *Service) DeleteArticleVideo(articleID, videoID string) error {
+	article, err := s.articleRepo.GetByID(articleID)
+	if err != nil {
+		return err
+	}
+
+	videos := make([]models.ArticleVideo, 0, len(article.Videos))
+	for _, video := range article.Videos {
+		if video.ID != videoID {
+			videos = append(videos, video)
+		}
+	}
+	if len(videos) == len(article.Videos) {
+		return gorm.ErrRecordNotFound
+	}
+	return s.articleRepo.UpdateArticleVideos(articleID, videos)
+}
+
 // Helper function to map Article to ArticleResponse
 func (s *Service) mapArticleToResponse(article *models.Article) *dto.ArticleResponse {
 	// Create base response
@@ -659,6 +744,7 @@ func (s *Service) mapArticleToResponse(article *models.Article) *dto.ArticleResp
 		Images:   []dto.ArticleImageResponse{},
 		Videos:   []dto.ArticleVideoResponse{},
 		Metadata: make(map[string]interface{}),
+		SEO:      dto.SEO(article.SEO),
 	}
 
 	// Add images from database (primary source)
@@ -0,0 +1,222 @@
+package audit
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+	applog "web-porto-backend/common/logger"
+	"web-porto-backend/internal/domain/models"
+	auditRepo "web-porto-backend/internal/repositories/audit"
+
+	"gorm.io/gorm"
+)
+
+// Actions recorded in the audit log
+const (
+	ActionCreate    = "create"
+	ActionUpdate    = "update"
+	ActionDelete    = "delete"
+	ActionPublish   = "publish"
+	ActionUnpublish = "unpublish"
+)
+
+// Entity types recorded in the audit log
+const (
+	EntityArticle     = "article"
+	EntityProject     = "project"
+	EntityExperience  = "experience"
+	EntityPage        = "page"
+	EntityCategory    = "category"
+	EntityTag         = "tag"
+	EntitySetting     = "setting"
+	EntityMedia       = "media"
+	EntityMediaFolder = "media_folder"
+)
+
+var ErrLogNotFound = errors.New("audit log entry not found")
+
+// LogFilter narrows List results
+type LogFilter = auditRepo.LogFilter
+
+// Actor identifies who performed a change and from where
+type Actor struct {
+	UserID    int
+	IP        string
+	UserAgent string
+}
+
+// FieldChange is the before/after value of a single field
+type FieldChange struct {
+	From interface{} `json:"from"`
+	To   interface{} `json:"to"`
+}
+
+// ignoredFields never show up in diffs; they change on every write
+var ignoredFields = map[string]bool{
+	"updatedAt":  true,
+	"updated_at": true,
+	"UpdatedAt":  true,
+}
+
+type Service interface {
+	// Record writes an audit entry. before is nil for creates and after is nil
+	// for deletes. Failures are logged, never returned, so auditing cannot
+	// break the request that triggered it.
+	Record(actor Actor, action, entityType, entityID string, before, after interface{})
+	List(filter LogFilter, page, limit int) ([]models.Log, int64, error)
+	GetByID(id int) (*models.Log, error)
+}
+
+type service struct {
+	repo auditRepo.Repository
+}
+
+func NewService(repo auditRepo.Repository) Service {
+	return &service{repo: repo}
+}
+
+func (s *service) Record(actor Actor, action, entityType, entityID string, before, after interface{}) {
+	log := applog.GetLogger().WithFields(applog.Fields{"service": "audit", "entity_type": entityType, "entity_id": entityID})
+
+	changes, err := Diff(before, after)
+	if err != nil {
+		log.Error("failed computing audit diff", applog.Fields{"error": err.Error()})
+		changes = map[string]FieldChange{}
+	}
+	if action == ActionUpdate {
+		// Updates that do nothing are not worth an entry
+		if len(changes) == 0 {
+			return
+		}
+		action = statusAction(changes)
+	}
+
+	encoded, err := json.Marshal(changes)
+	if err != nil {
+		log.Error("failed encoding audit diff", applog.Fields{"error": err.Error()})
+		encoded = []byte("{}")
+	}
+
+	entry := &models.Log{
+		Action:      action,
+		EntityType:  entityType,
+		EntityID:    entityID,
+		Description: describe(action, entityType, entityID, changes),
+		Changes:     string(encoded),
+		IP:          actor.IP,
+		UserAgent:   actor.UserAgent,
+	}
+	if actor.UserID > 0 {
+		userID := actor.UserID
+		entry.UserID = &userID
+	}
+
+	if err := s.repo.Create(entry); err != nil {
+		log.Error("failed writing audit log", applog.Fields{"action": action, "error": err.Error()})
+	}
+}
+
+func (s *service) List(filter LogFilter, page, limit int) ([]models.Log, int64, error) {
+	if page < 1 {
+		page = 1
+	}
+	if limit < 1 {
+		limit = 20
+	}
+	return s.repo.FindAll(filter, (page-1)*limit, limit)
+}
+
+func (s *service) GetByID(id int) (*models.Log, error) {
+	entry, err := s.repo.FindByID(id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrLogNotFound
+		}
+		return nil, err
+	}
+	return entry, nil
+}
+
+// Diff compares the JSON representations of before and after and returns the
+// top-level fields that differ. A nil side is treated as an empty object.
+func Diff(before, after interface{}) (map[string]FieldChange, error) {
+	from, err := toMap(before)
+	if err != nil {
+		return nil, err
+	}
+	to, err := toMap(after)
+	if err != nil {
+		return nil, err
+	}
+
+	changes := make(map[string]FieldChange)
+	for key, oldValue := range from {
+		if ignoredFields[key] {
+			continue
+		}
+		newValue, ok := to[key]
+		if !ok || !reflect.DeepEqual(oldValue, newValue) {
+			changes[key] = FieldChange{From: oldValue, To: newValue}
+		}
+	}
+	for key, newValue := range to {
+		if ignoredFields[key] {
+			continue
+		}
+		if _, ok := from[key]; !ok {
+			changes[key] = FieldChange{From: nil, To: newValue}
+		}
+	}
+	return changes, nil
+}
+
+func toMap(v interface{}) (map[string]interface{}, error) {
+	result := map[string]interface{}{}
+	if v == nil {
+		return result, nil
+	}
+	if rv := reflect.ValueOf(v); rv.Kind() == reflect.Ptr && rv.IsNil() {
+		return result, nil
+	}
+	raw, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(raw, &result); err != nil {
+		return nil, fmt.Errorf("audit: value is not an object: %w", err)
+	}
+	return result, nil
+}
+
+// statusAction upgrades an update that changes the status into publish/unpublish
+func statusAction(changes map[string]FieldChange) string {
+	for _, key := range []string{"status", "Status"} {
+		change, ok := changes[key]
+		if !ok {
+			continue
+		}
+		switch {
+		case change.To == "published":
+			return ActionPublish
+		case change.From == "published":
+			return ActionUnpublish
+		}
+	}
+	return ActionUpdate
+}
+
+func describe(action, entityType, entityID string, changes map[string]FieldChange) string {
+	desc := fmt.Sprintf("%s %s %s", action, entityType, entityID)
+	if action == ActionCreate || action == ActionDelete || len(changes) == 0 {
+		return desc
+	}
+	fields := make([]string, 0, len(changes))
+	for key := range changes {
+		fields = append(fields, key)
+	}
+	sort.Strings(fields)
+	return desc + " (" + strings.Join(fields, ", ") + ")"
+}
@@ -1,14 +1,15 @@
 package experience
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
-	"os"
 	"time"
 	"web-porto-backend/common/utils"
 	"web-porto-backend/internal/domain/dto"
 	"web-porto-backend/internal/domain/models"
 	experienceRepo "web-porto-backend/internal/repositories/experience"
+	mediaService "web-porto-backend/internal/services/media"
 	tagService "web-porto-backend/internal/services/tag"
 
 	"gorm.io/gorm"
@@ -72,17 +73,20 @@ func parseFlexibleDate(dateStr string) (time.Time, error) {
 type Service struct {
 	experienceRepo experienceRepo.Repository
 	tagService     tagService.Service
+	mediaService   mediaService.Service
 	db             *gorm.DB
 }
 
 func NewService(
 	experienceRepo experienceRepo.Repository,
 	tagService tagService.Service,
+	mediaService mediaService.Service,
 	db *gorm.DB,
 ) *Service {
 	return &Service{
 		experienceRepo: experienceRepo,
 		tagService:     tagService,
+		mediaService:   mediaService,
 		db:             db,
 	}
 }
@@ -288,10 +292,11 @@ func (s *Service) GetExperienceByID(id int) (*dto.ExperienceResponse, error) {
 	return s.mapToResponse(experience), nil
 }
 
-// ListExperiences retrieves a paginated list of experiences
-func (s *Service) ListExperiences(page, size int) (*dto.PaginatedResponse, error) {
+// ListExperiences retrieves a paginated list of experiences filtered and
+// sorted by q
+func (s *Service) ListExperiences(page, size int, q dto.ListQuery) (*dto.PaginatedResponse, error) {
 	offset := (page - 1) * size
-	experiences, total, err := s.experienceRepo.GetAll(size, offset)
+	experiences, total, err := s.experienceRepo.GetAll(q, size, offset)
 	if err != nil {
 		return nil, err
 	}
@@ -386,11 +391,7 @@ func (s *Service) UpdateExperience(id int, req dto.UpdateExperienceRequest) (*dt
 	}
 	if req.LogoURL != nil {
 		if *req.LogoURL == "" && experience.LogoURL != "" {
-			var media models.Media
-			if err := s.db.Where("file_url = ?", experience.LogoURL).First(&media).Error; err == nil {
-				os.Remove(media.FilePath)
-				s.db.Delete(&media)
-			}
+			s.mediaService.DeleteByURL(context.Background(), experience.LogoURL)
 		}
 		experience.LogoURL = *req.LogoURL
 	}
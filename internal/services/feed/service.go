@@ -0,0 +1,202 @@
+package feed
+
+import (
+	"errors"
+	"strings"
+	"time"
+	"web-porto-backend/common/utils"
+	"web-porto-backend/internal/domain/dto"
+	"web-porto-backend/internal/domain/models"
+	articleRepo "web-porto-backend/internal/repositories/article"
+	categoryRepo "web-porto-backend/internal/repositories/category"
+	tagRepo "web-porto-backend/internal/repositories/tag"
+	settingSrvc "web-porto-backend/internal/services/setting"
+)
+
+var (
+	ErrCategoryNotFound = errors.New("category not found")
+	ErrTagNotFound      = errors.New("tag not found")
+)
+
+const (
+	// DefaultLimit is the number of entries in a feed without ?limit=
+	DefaultLimit = 20
+	// MaxLimit caps ?limit=
+	MaxLimit = 100
+)
+
+// Options selects the articles of a feed
+type Options struct {
+	// Category and Tag are slugs (tags also match by name); empty means all
+	Category string
+	Tag      string
+	// FullContent puts the article body in each entry instead of the excerpt
+	FullContent bool
+	Limit       int
+}
+
+// Feed is a format-neutral feed; handlers render it as RSS, Atom or JSON Feed
+type Feed struct {
+	Title       string
+	Description string
+	// Link is the public site page the feed belongs to
+	Link string
+	// Updated is the latest change to any entry; zero for an empty feed
+	Updated time.Time
+	Items   []Item
+}
+
+// Item is a feed entry. Content is HTML and only set for full-content feeds.
+type Item struct {
+	ID         string
+	Title      string
+	Link       string
+	Summary    string
+	Content    string
+	Author     string
+	Categories []string
+	Published  time.Time
+	Updated    time.Time
+	// Image is the absolute URL of the featured image, if any
+	Image string
+}
+
+// Config holds feed defaults
+type Config struct {
+	// BaseURL makes relative media URLs absolute and stands in for the site
+	// URL until the site_url setting is set
+	BaseURL string
+	// Title is used until the site_title setting is set
+	Title string
+}
+
+type Service interface {
+	// Articles builds a feed of the newest published articles
+	Articles(opts Options) (*Feed, error)
+}
+
+type service struct {
+	articleRepo  articleRepo.Repository
+	categoryRepo categoryRepo.Repository
+	tagRepo      tagRepo.Repository
+	settings     settingSrvc.Service
+	cfg          Config
+}
+
+func NewService(articleRepo articleRepo.Repository, categoryRepo categoryRepo.Repository, tagRepo tagRepo.Repository, settings settingSrvc.Service, cfg Config) Service {
+	cfg.BaseURL = strings.TrimRight(cfg.BaseURL, "/")
+	return &service{
+		articleRepo:  articleRepo,
+		categoryRepo: categoryRepo,
+		tagRepo:      tagRepo,
+		settings:     settings,
+		cfg:          cfg,
+	}
+}
+
+func (s *service) Articles(opts Options) (*Feed, error) {
+	site, err := s.site()
+	if err != nil {
+		return nil, err
+	}
+
+	feed := &Feed{
+		Title:       site.Title,
+		Description: site.Description,
+		Link:        site.URL + site.ArticlePath,
+	}
+	query := dto.ListQuery{
+		Sort:    []dto.SortField{{Field: "publishedAt", Desc: true}},
+		Filters: map[string][]string{},
+	}
+
+	if opts.Category != "" {
+		category, err := s.categoryRepo.FindBySlug(opts.Category)
+		if err != nil {
+			return nil, ErrCategoryNotFound
+		}
+		feed.Title += ": " + category.Name
+		query.Filters["category"] = []string{category.Slug}
+	}
+	if opts.Tag != "" {
+		tag, err := s.tagRepo.GetBySlug(opts.Tag)
+		if err != nil {
+			if tag, err = s.tagRepo.GetByName(opts.Tag); err != nil {
+				return nil, ErrTagNotFound
+			}
+		}
+		feed.Title += ": " + tag.Name
+		query.Filters["tag"] = []string{tag.Slug}
+	}
+	if feed.Description == "" {
+		feed.Description = feed.Title
+	}
+
+	limit := opts.Limit
+	if limit < 1 {
+		limit = DefaultLimit
+	}
+	if limit > MaxLimit {
+		limit = MaxLimit
+	}
+	articles, _, err := s.articleRepo.GetAll(models.StatusPublished, query, limit, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	feed.Items = make([]Item, 0, len(articles))
+	for _, article := range articles {
+		item := s.toItem(site, article, opts.FullContent)
+		if item.Updated.After(feed.Updated) {
+			feed.Updated = item.Updated
+		}
+		feed.Items = append(feed.Items, item)
+	}
+	return feed, nil
+}
+
+// site returns the public site settings with the configured fallbacks applied
+func (s *service) site() (settingSrvc.Site, error) {
+	site, err := s.settings.GetSite()
+	if err != nil {
+		return site, err
+	}
+	if site.URL == "" {
+		site.URL = s.cfg.BaseURL
+	}
+	if site.Title == "" {
+		site.Title = s.cfg.Title
+	}
+	return site, nil
+}
+
+func (s *service) toItem(site settingSrvc.Site, article *models.Article, full bool) Item {
+	item := Item{
+		ID:      article.ID,
+		Title:   article.Title,
+		Link:    site.ArticleURL(article.Slug),
+		Summary: article.Excerpt,
+		Author:  article.Author.Username,
+		Updated: article.UpdatedAt,
+		Image:   utils.AbsoluteURL(s.cfg.BaseURL, article.FeaturedImageURL),
+	}
+	if article.PublishedAt != nil {
+		item.Published = *article.PublishedAt
+	} else {
+		item.Published = article.CreatedAt
+	}
+	// A publish date set after the last edit is when the entry changed
+	if item.Published.After(item.Updated) {
+		item.Updated = item.Published
+	}
+	if full {
+		item.Content = article.Content
+	}
+	for _, category := range article.Categories {
+		item.Categories = append(item.Categories, category.Name)
+	}
+	for _, tag := range article.Tags {
+		item.Categories = append(item.Categories, tag.Name)
+	}
+	return item
+}
@@ -0,0 +1,180 @@
+package invitation
+
+import (
+	"errors"
+	"strings"
+	"time"
+	"web-porto-backend/common/utils"
+	"web-porto-backend/internal/auth"
+	"web-porto-backend/internal/domain/models"
+	inviteRepo "web-porto-backend/internal/repositories/invitation"
+
+	"gorm.io/gorm"
+)
+
+// Registration modes accepted in config.AuthConfig.RegistrationMode
+const (
+	ModeOpen   = "open"
+	ModeInvite = "invite"
+	ModeClosed = "closed"
+)
+
+var (
+	ErrRegistrationClosed  = errors.New("registration is closed")
+	ErrInviteRequired      = errors.New("an invitation is required to register")
+	ErrInvalidInvite       = errors.New("invalid or expired invitation")
+	ErrInviteEmailMismatch = errors.New("invitation was issued for a different email")
+	ErrInviteNotFound      = errors.New("invitation not found")
+	ErrInviteNotPending    = errors.New("invitation is no longer pending")
+	ErrInvalidRole         = errors.New("invalid role")
+	ErrRoleNotGrantable    = errors.New("cannot grant a role with permissions you do not hold")
+)
+
+// RoleChecker reports whether a role name exists and resolves its permissions.
+type RoleChecker interface {
+	RoleExists(name string) bool
+	RolePermissions(name string) ([]string, error)
+}
+
+type Service interface {
+	RegistrationMode() string
+	// Create invites email with a role; granted are the permissions of the
+	// inviter, who may not hand out a role holding more than that.
+	Create(email, role string, invitedBy int, granted []string) (string, *models.Invitation, error)
+	GetAll(status string) ([]models.Invitation, error)
+	Revoke(id int) error
+	Redeem(rawToken, email string, register func(role string) (int, error)) (*models.Invitation, error)
+}
+
+type service struct {
+	repo  inviteRepo.Repository
+	roles RoleChecker
+	mode  string
+	ttl   time.Duration
+}
+
+func NewService(repo inviteRepo.Repository, roles RoleChecker, mode string, ttl time.Duration) Service {
+	switch mode {
+	case ModeOpen, ModeInvite, ModeClosed:
+	default:
+		// Unknown values fall back to the safe default
+		mode = ModeInvite
+	}
+	if ttl <= 0 {
+		ttl = 7 * 24 * time.Hour
+	}
+	return &service{repo: repo, roles: roles, mode: mode, ttl: ttl}
+}
+
+func (s *service) RegistrationMode() string {
+	return s.mode
+}
+
+// Create issues a new invitation and returns the raw token, which is not stored.
+func (s *service) Create(email, role string, invitedBy int, granted []string) (string, *models.Invitation, error) {
+	email = strings.ToLower(strings.TrimSpace(email))
+	if !utils.IsValidEmail(email) {
+		return "", nil, errors.New("invalid email")
+	}
+	if role == "" {
+		role = "user"
+	}
+	if !s.roles.RoleExists(role) {
+		return "", nil, ErrInvalidRole
+	}
+	permissions, err := s.roles.RolePermissions(role)
+	if err != nil {
+		return "", nil, err
+	}
+	if !auth.CanGrant(granted, permissions) {
+		return "", nil, ErrRoleNotGrantable
+	}
+
+	raw, hash, err := utils.GenerateSecureToken()
+	if err != nil {
+		return "", nil, err
+	}
+
+	invitation := &models.Invitation{
+		Email:     email,
+		Role:      role,
+		TokenHash: hash,
+		ExpiresAt: time.Now().Add(s.ttl),
+	}
+	if invitedBy > 0 {
+		invitation.InvitedBy = &invitedBy
+	}
+	if err := s.repo.Create(invitation); err != nil {
+		return "", nil, err
+	}
+	return raw, invitation, nil
+}
+
+func (s *service) GetAll(status string) ([]models.Invitation, error) {
+	return s.repo.FindAll(status)
+}
+
+func (s *service) Revoke(id int) error {
+	if _, err := s.repo.FindByID(id); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrInviteNotFound
+		}
+		return err
+	}
+	if err := s.repo.Revoke(id); err != nil {
+		if errors.Is(err, inviteRepo.ErrNotRedeemable) {
+			return ErrInviteNotPending
+		}
+		return err
+	}
+	return nil
+}
+
+// Redeem enforces the registration mode and, when an invitation is supplied,
+// claims it before calling register with the role to assign. The claim is
+// released again if register fails so the invitation can be retried.
+// A nil invitation is returned for open registration without a token.
+func (s *service) Redeem(rawToken, email string, register func(role string) (int, error)) (*models.Invitation, error) {
+	if s.mode == ModeClosed {
+		return nil, ErrRegistrationClosed
+	}
+	if rawToken == "" {
+		if s.mode == ModeInvite {
+			return nil, ErrInviteRequired
+		}
+		_, err := register("user")
+		return nil, err
+	}
+
+	invitation, err := s.repo.FindByHash(utils.HashToken(rawToken))
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrInvalidInvite
+		}
+		return nil, err
+	}
+	if !strings.EqualFold(invitation.Email, strings.TrimSpace(email)) {
+		return nil, ErrInviteEmailMismatch
+	}
+
+	if err := s.repo.Claim(invitation.ID); err != nil {
+		if errors.Is(err, inviteRepo.ErrNotRedeemable) {
+			return nil, ErrInvalidInvite
+		}
+		return nil, err
+	}
+
+	userID, err := register(invitation.Role)
+	if err != nil {
+		_ = s.repo.Release(invitation.ID)
+		return nil, err
+	}
+	if err := s.repo.SetAcceptedBy(invitation.ID, userID); err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	invitation.AcceptedAt = &now
+	invitation.AcceptedBy = &userID
+	return invitation, nil
+}
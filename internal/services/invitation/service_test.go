@@ -0,0 +1,68 @@
+package invitation
+
+import (
+	"errors"
+	"testing"
+	"time"
+	"web-porto-backend/internal/auth"
+	"web-porto-backend/internal/domain/models"
+	inviteRepo "web-porto-backend/internal/repositories/invitation"
+)
+
+type fakeRepo struct {
+	inviteRepo.Repository
+	created []*models.Invitation
+}
+
+func (r *fakeRepo) Create(invitation *models.Invitation) error {
+	r.created = append(r.created, invitation)
+	return nil
+}
+
+type fakeRoles map[string][]string
+
+func (r fakeRoles) RoleExists(name string) bool {
+	_, ok := r[name]
+	return ok
+}
+
+func (r fakeRoles) RolePermissions(name string) ([]string, error) {
+	return r[name], nil
+}
+
+var roles = fakeRoles{
+	"admin":  {auth.PermissionAll},
+	"editor": {"article:*", auth.PermMediaUpload},
+	"user":   {},
+}
+
+func TestCreateRejectsRolesBeyondInviter(t *testing.T) {
+	tests := []struct {
+		name    string
+		role    string
+		granted []string
+		wantErr error
+	}{
+		{"user manager invites admin", "admin", []string{auth.PermUsersManage}, ErrRoleNotGrantable},
+		{"user manager with roles:manage invites admin", "admin", []string{auth.PermUsersManage, auth.PermRolesManage}, ErrRoleNotGrantable},
+		{"partial article rights invite editor", "editor", []string{auth.PermUsersManage, auth.PermArticleWrite, auth.PermMediaUpload}, ErrRoleNotGrantable},
+		{"user manager invites user", "user", []string{auth.PermUsersManage}, nil},
+		{"default role", "", []string{auth.PermUsersManage}, nil},
+		{"holder of article:* invites editor", "editor", []string{auth.PermUsersManage, "article:*", auth.PermMediaUpload}, nil},
+		{"admin invites admin", "admin", []string{auth.PermissionAll}, nil},
+		{"unknown role", "owner", []string{auth.PermissionAll}, ErrInvalidRole},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := &fakeRepo{}
+			s := NewService(repo, roles, ModeInvite, time.Hour)
+			_, _, err := s.Create("new@example.com", tt.role, 1, tt.granted)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Create() error = %v, want %v", err, tt.wantErr)
+			}
+			if tt.wantErr != nil && len(repo.created) != 0 {
+				t.Fatal("invitation stored despite the error")
+			}
+		})
+	}
+}
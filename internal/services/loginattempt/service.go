@@ -0,0 +1,203 @@
+package loginattempt
+
+import (
+	"errors"
+	"strings"
+	"time"
+	applog "web-porto-backend/common/logger"
+	"web-porto-backend/internal/domain/models"
+	attemptRepo "web-porto-backend/internal/repositories/loginattempt"
+)
+
+// ErrLocked is returned by Check while an account or IP is locked out
+var ErrLocked = errors.New("too many failed login attempts")
+
+// ErrLockoutNotFound is returned by ClearLockout when nothing was locked
+var ErrLockoutNotFound = errors.New("lockout not found")
+
+// Failures before the lockout threshold still add a short exponential delay
+// starting at this count.
+const backoffStartFailures = 3
+
+// Config holds the throttling thresholds, usually taken from config.AuthConfig
+type Config struct {
+	MaxFailures   int
+	IPMaxFailures int
+	LockoutBase   time.Duration
+	LockoutMax    time.Duration
+	FailureWindow time.Duration
+}
+
+// AttemptFilter narrows ListAttempts results
+type AttemptFilter = attemptRepo.AttemptFilter
+
+// LockedError carries how long the caller must wait
+type LockedError struct {
+	RetryAfter time.Duration
+}
+
+func (e *LockedError) Error() string { return ErrLocked.Error() }
+
+func (e *LockedError) Unwrap() error { return ErrLocked }
+
+type Service interface {
+	Check(email, ip string) error
+	RecordFailure(email, ip, userAgent string, userID *int, reason string)
+	RecordSuccess(email, ip, userAgent string, userID int)
+	ListAttempts(filter AttemptFilter, page, limit int) ([]models.LoginAttempt, int64, error)
+	ListLockouts() ([]models.LoginThrottle, error)
+	ClearLockout(scope, identifier string) error
+}
+
+type service struct {
+	repo attemptRepo.Repository
+	cfg  Config
+}
+
+func NewService(repo attemptRepo.Repository, cfg Config) Service {
+	if cfg.MaxFailures <= 0 {
+		cfg.MaxFailures = 5
+	}
+	if cfg.IPMaxFailures <= 0 {
+		cfg.IPMaxFailures = 20
+	}
+	if cfg.LockoutBase <= 0 {
+		cfg.LockoutBase = time.Minute
+	}
+	if cfg.LockoutMax <= 0 {
+		cfg.LockoutMax = time.Hour
+	}
+	if cfg.FailureWindow <= 0 {
+		cfg.FailureWindow = 15 * time.Minute
+	}
+	return &service{repo: repo, cfg: cfg}
+}
+
+// Check returns a *LockedError when either the account or the IP is locked.
+// Lookup errors fail open so a database hiccup does not block every login.
+func (s *service) Check(email, ip string) error {
+	now := time.Now()
+	var wait time.Duration
+	for _, key := range s.keys(email, ip) {
+		throttle, err := s.repo.FindThrottle(key.scope, key.identifier)
+		if err != nil {
+			continue
+		}
+		if throttle.LockedUntil != nil && throttle.LockedUntil.After(now) {
+			if d := throttle.LockedUntil.Sub(now); d > wait {
+				wait = d
+			}
+		}
+	}
+	if wait > 0 {
+		return &LockedError{RetryAfter: wait}
+	}
+	return nil
+}
+
+func (s *service) RecordFailure(email, ip, userAgent string, userID *int, reason string) {
+	log := applog.GetLogger().WithFields(applog.Fields{"service": "loginattempt"})
+	email = normalizeEmail(email)
+
+	s.record(&models.LoginAttempt{UserID: userID, Email: email, IP: ip, UserAgent: userAgent, Success: false, Reason: reason})
+
+	for _, key := range s.keys(email, ip) {
+		threshold := s.cfg.MaxFailures
+		if key.scope == models.LoginThrottleScopeIP {
+			threshold = s.cfg.IPMaxFailures
+		}
+		throttle, err := s.repo.RegisterFailure(key.scope, key.identifier, s.cfg.FailureWindow, func(failures int) time.Duration {
+			return s.lockDuration(failures, threshold)
+		})
+		if err != nil {
+			log.Error("failed registering login failure", applog.Fields{"scope": key.scope, "error": err.Error()})
+			continue
+		}
+		if throttle.Failures >= threshold {
+			log.Warn("login locked out", applog.Fields{"scope": key.scope, "identifier": key.identifier, "failures": throttle.Failures})
+		}
+	}
+}
+
+// RecordSuccess logs the attempt and clears the account counter. The IP
+// counter is kept so one valid login cannot reset a spraying IP.
+func (s *service) RecordSuccess(email, ip, userAgent string, userID int) {
+	email = normalizeEmail(email)
+	s.record(&models.LoginAttempt{UserID: &userID, Email: email, IP: ip, UserAgent: userAgent, Success: true})
+	_ = s.repo.DeleteThrottle(models.LoginThrottleScopeAccount, email)
+}
+
+func (s *service) ListAttempts(filter AttemptFilter, page, limit int) ([]models.LoginAttempt, int64, error) {
+	if page < 1 {
+		page = 1
+	}
+	if limit < 1 {
+		limit = 20
+	}
+	filter.Email = normalizeEmail(filter.Email)
+	return s.repo.FindAttempts(filter, (page-1)*limit, limit)
+}
+
+func (s *service) ListLockouts() ([]models.LoginThrottle, error) {
+	return s.repo.FindActiveThrottles(time.Now())
+}
+
+func (s *service) ClearLockout(scope, identifier string) error {
+	if scope == models.LoginThrottleScopeAccount {
+		identifier = normalizeEmail(identifier)
+	}
+	if err := s.repo.DeleteThrottle(scope, identifier); err != nil {
+		if attemptRepo.IsNotFound(err) {
+			return ErrLockoutNotFound
+		}
+		return err
+	}
+	return nil
+}
+
+// lockDuration implements the backoff: from backoffStartFailures a delay of
+// 1s, 2s, 4s... (capped at LockoutBase) applies; from the threshold on the
+// lockout starts at LockoutBase and doubles with each further failure,
+// capped at LockoutMax.
+func (s *service) lockDuration(failures, threshold int) time.Duration {
+	base, limit, exp := s.cfg.LockoutBase, s.cfg.LockoutMax, failures-threshold
+	if failures < threshold {
+		if failures < backoffStartFailures {
+			return 0
+		}
+		base, limit, exp = time.Second, s.cfg.LockoutBase, failures-backoffStartFailures
+	}
+	if exp > 30 {
+		return limit
+	}
+	if d := base << uint(exp); d > 0 && d < limit {
+		return d
+	}
+	return limit
+}
+
+func (s *service) record(attempt *models.LoginAttempt) {
+	if err := s.repo.CreateAttempt(attempt); err != nil {
+		applog.GetLogger().WithFields(applog.Fields{"service": "loginattempt"}).Error("failed recording login attempt", applog.Fields{"error": err.Error()})
+	}
+}
+
+type throttleKey struct {
+	scope      string
+	identifier string
+}
+
+func (s *service) keys(email, ip string) []throttleKey {
+	keys := make([]throttleKey, 0, 2)
+	if email = normalizeEmail(email); email != "" {
+		keys = append(keys, throttleKey{models.LoginThrottleScopeAccount, email})
+	}
+	if ip != "" {
+		keys = append(keys, throttleKey{models.LoginThrottleScopeIP, ip})
+	}
+	return keys
+}
+
+func normalizeEmail(email string) string {
+	return strings.ToLower(strings.TrimSpace(email))
+}
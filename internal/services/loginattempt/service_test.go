@@ -0,0 +1,217 @@
+package loginattempt
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+	"web-porto-backend/internal/domain/models"
+	attemptRepo "web-porto-backend/internal/repositories/loginattempt"
+
+	"gorm.io/gorm"
+)
+
+// fakeThrottles keeps counters in memory, counting like RegisterFailure
+type fakeThrottles struct {
+	attemptRepo.Repository
+	throttles map[string]*models.LoginThrottle
+	attempts  []models.LoginAttempt
+	lookupErr error
+}
+
+func newFakeThrottles() *fakeThrottles {
+	return &fakeThrottles{throttles: map[string]*models.LoginThrottle{}}
+}
+
+func (r *fakeThrottles) CreateAttempt(attempt *models.LoginAttempt) error {
+	r.attempts = append(r.attempts, *attempt)
+	return nil
+}
+
+func (r *fakeThrottles) FindThrottle(scope, identifier string) (*models.LoginThrottle, error) {
+	if r.lookupErr != nil {
+		return nil, r.lookupErr
+	}
+	throttle, ok := r.throttles[scope+":"+identifier]
+	if !ok {
+		return nil, gorm.ErrRecordNotFound
+	}
+	copied := *throttle
+	return &copied, nil
+}
+
+func (r *fakeThrottles) RegisterFailure(scope, identifier string, window time.Duration, lockFor func(int) time.Duration) (*models.LoginThrottle, error) {
+	throttle, ok := r.throttles[scope+":"+identifier]
+	if !ok {
+		throttle = &models.LoginThrottle{Scope: scope, Identifier: identifier}
+		r.throttles[scope+":"+identifier] = throttle
+	}
+	now := time.Now()
+	if !throttle.LastFailureAt.IsZero() && now.Sub(throttle.LastFailureAt) > window &&
+		(throttle.LockedUntil == nil || throttle.LockedUntil.Before(now)) {
+		throttle.Failures = 0
+	}
+	throttle.Failures++
+	throttle.LastFailureAt = now
+	if d := lockFor(throttle.Failures); d > 0 {
+		until := now.Add(d)
+		throttle.LockedUntil = &until
+	}
+	copied := *throttle
+	return &copied, nil
+}
+
+func (r *fakeThrottles) DeleteThrottle(scope, identifier string) error {
+	if _, ok := r.throttles[scope+":"+identifier]; !ok {
+		return gorm.ErrRecordNotFound
+	}
+	delete(r.throttles, scope+":"+identifier)
+	return nil
+}
+
+// unlock ends the lock of a counter without resetting it
+func (r *fakeThrottles) unlock(scope, identifier string) {
+	r.throttles[scope+":"+identifier].LockedUntil = nil
+}
+
+func TestLockDuration(t *testing.T) {
+	s := NewService(nil, Config{LockoutBase: time.Minute, LockoutMax: time.Hour}).(*service)
+	want := map[int]time.Duration{
+		1:   0,
+		2:   0,
+		3:   time.Second,
+		4:   2 * time.Second,
+		5:   time.Minute,
+		6:   2 * time.Minute,
+		7:   4 * time.Minute,
+		10:  32 * time.Minute,
+		11:  time.Hour,
+		100: time.Hour,
+	}
+	for failures, d := range want {
+		if got := s.lockDuration(failures, 5); got != d {
+			t.Errorf("lockDuration(%d) = %v, want %v", failures, got, d)
+		}
+	}
+
+	// The backoff before the threshold never exceeds the first lockout
+	s.cfg.LockoutBase = 3 * time.Second
+	for failures := 3; failures < 10; failures++ {
+		if got := s.lockDuration(failures, 10); got > 3*time.Second {
+			t.Errorf("lockDuration(%d) with a 3s lockout = %v", failures, got)
+		}
+	}
+}
+
+func TestRecordFailureLocksTheAccount(t *testing.T) {
+	repo := newFakeThrottles()
+	s := NewService(repo, Config{MaxFailures: 5, LockoutBase: time.Minute, LockoutMax: time.Hour})
+
+	for i := 1; i <= 5; i++ {
+		if i < 3 {
+			if err := s.Check("alice@example.com", "10.0.0.1"); err != nil {
+				t.Fatalf("attempt %d refused: %v", i, err)
+			}
+		}
+		s.RecordFailure(" Alice@Example.com", "10.0.0.1", "agent", nil, "invalid_password")
+		repo.unlock(models.LoginThrottleScopeIP, "10.0.0.1")
+	}
+
+	var locked *LockedError
+	err := s.Check("ALICE@example.com", "10.0.0.2")
+	if !errors.As(err, &locked) || !errors.Is(err, ErrLocked) {
+		t.Fatalf("Check after 5 failures = %v, want a LockedError", err)
+	}
+	if locked.RetryAfter <= 59*time.Second || locked.RetryAfter > time.Minute {
+		t.Fatalf("RetryAfter = %v, want a minute", locked.RetryAfter)
+	}
+	if err := s.Check("bob@example.com", "10.0.0.2"); err != nil {
+		t.Fatalf("another account is locked: %v", err)
+	}
+	if len(repo.attempts) != 5 || repo.attempts[0].Email != "alice@example.com" {
+		t.Fatalf("attempts = %+v", repo.attempts)
+	}
+
+	// Failures while locked extend the lockout
+	s.RecordFailure("alice@example.com", "10.0.0.2", "", nil, "locked")
+	if err := s.Check("alice@example.com", ""); !errors.As(err, &locked) || locked.RetryAfter <= time.Minute {
+		t.Fatalf("Check after a 6th failure = %v", err)
+	}
+}
+
+func TestRecordFailureBacksOffBeforeTheLockout(t *testing.T) {
+	repo := newFakeThrottles()
+	s := NewService(repo, Config{MaxFailures: 5})
+
+	s.RecordFailure("alice@example.com", "", "", nil, "invalid_password")
+	s.RecordFailure("alice@example.com", "", "", nil, "invalid_password")
+	if err := s.Check("alice@example.com", ""); err != nil {
+		t.Fatalf("Check after 2 failures = %v", err)
+	}
+	s.RecordFailure("alice@example.com", "", "", nil, "invalid_password")
+	var locked *LockedError
+	if err := s.Check("alice@example.com", ""); !errors.As(err, &locked) || locked.RetryAfter > time.Second {
+		t.Fatalf("Check after 3 failures = %v, want a delay of up to a second", err)
+	}
+}
+
+func TestRecordFailureLocksSprayingIPs(t *testing.T) {
+	repo := newFakeThrottles()
+	s := NewService(repo, Config{MaxFailures: 5, IPMaxFailures: 20})
+
+	for i := 0; i < 20; i++ {
+		s.RecordFailure(fmt.Sprintf("user%d@example.com", i), "10.0.0.9", "", nil, "invalid_password")
+	}
+	if err := s.Check("new@example.com", "10.0.0.9"); !errors.Is(err, ErrLocked) {
+		t.Fatalf("Check from the IP = %v, want ErrLocked", err)
+	}
+	if err := s.Check("new@example.com", "10.0.0.10"); err != nil {
+		t.Fatalf("Check from another IP = %v", err)
+	}
+}
+
+func TestRecordSuccessClearsOnlyTheAccount(t *testing.T) {
+	repo := newFakeThrottles()
+	s := NewService(repo, Config{})
+	for i := 0; i < 3; i++ {
+		s.RecordFailure("alice@example.com", "10.0.0.1", "", nil, "invalid_password")
+	}
+
+	s.RecordSuccess("Alice@example.com", "10.0.0.1", "", 1)
+	if _, err := repo.FindThrottle(models.LoginThrottleScopeAccount, "alice@example.com"); err == nil {
+		t.Fatal("account counter kept after a successful login")
+	}
+	if throttle, err := repo.FindThrottle(models.LoginThrottleScopeIP, "10.0.0.1"); err != nil || throttle.Failures != 3 {
+		t.Fatalf("IP counter = %+v, %v; want it kept", throttle, err)
+	}
+}
+
+func TestCheckFailsOpen(t *testing.T) {
+	repo := newFakeThrottles()
+	s := NewService(repo, Config{})
+	for i := 0; i < 10; i++ {
+		s.RecordFailure("alice@example.com", "", "", nil, "invalid_password")
+	}
+	repo.lookupErr = errors.New("connection refused")
+	if err := s.Check("alice@example.com", ""); err != nil {
+		t.Fatalf("Check with the database down = %v", err)
+	}
+}
+
+func TestClearLockout(t *testing.T) {
+	repo := newFakeThrottles()
+	s := NewService(repo, Config{})
+	for i := 0; i < 5; i++ {
+		s.RecordFailure("alice@example.com", "", "", nil, "invalid_password")
+	}
+
+	if err := s.ClearLockout(models.LoginThrottleScopeAccount, " Alice@Example.com"); err != nil {
+		t.Fatalf("ClearLockout: %v", err)
+	}
+	if err := s.Check("alice@example.com", ""); err != nil {
+		t.Fatalf("Check after clearing = %v", err)
+	}
+	if err := s.ClearLockout(models.LoginThrottleScopeAccount, "alice@example.com"); !errors.Is(err, ErrLockoutNotFound) {
+		t.Fatalf("clearing again = %v, want ErrLockoutNotFound", err)
+	}
+}
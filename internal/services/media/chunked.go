@@ -0,0 +1,440 @@
+package media
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	applog "web-porto-backend/common/logger"
+	"web-porto-backend/internal/adapters/filetype"
+	"web-porto-backend/internal/domain/dto"
+	"web-porto-backend/internal/domain/models"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+var (
+	ErrUploadNotFound   = errors.New("upload not found")
+	ErrUploadCompleted  = errors.New("upload already completed")
+	ErrUploadInProgress = errors.New("upload is being completed")
+	ErrUploadIncomplete = errors.New("upload is missing chunks")
+	ErrChunkOutOfRange  = errors.New("chunk index out of range")
+	ErrChunkSize        = errors.New("chunk has the wrong size")
+	ErrChecksumMismatch = errors.New("checksum mismatch")
+	ErrInvalidChecksum  = errors.New("checksum must be a hex sha-256")
+)
+
+// UploadStatus is a resumable upload with the chunks received so far;
+// clients resume by sending the missing ones
+type UploadStatus struct {
+	*models.UploadSession
+	TotalChunks   int   `json:"totalChunks"`
+	Received      []int `json:"received"`
+	ReceivedBytes int64 `json:"receivedBytes"`
+}
+
+// expireBatch is how many expired uploads are removed at a time
+const expireBatch = 100
+
+// chunkSuffix names staged chunk files, e.g. 3.part
+const chunkSuffix = ".part"
+
+func (s *service) CreateUpload(userID uint, req dto.CreateUploadRequest) (*UploadStatus, error) {
+	mimeType := filetype.ForExtension(filepath.Ext(req.FileName))
+	if mimeType == "" {
+		return nil, ErrUnsupportedType
+	}
+	if mimeType == filetype.SVG && s.cfg.SVGPolicy == SVGReject {
+		return nil, ErrSVGNotAllowed
+	}
+	// Videos are streamed into storage; everything else is checked in memory
+	// like a regular upload
+	limit := s.maxSize(mimeType)
+	if filetype.Category(mimeType) == "video" {
+		limit = s.cfg.MaxResumableSize
+	}
+	if req.Size > limit {
+		return nil, &SizeLimitError{MimeType: mimeType, Limit: limit}
+	}
+	checksum := strings.ToLower(strings.TrimSpace(req.Checksum))
+	if checksum != "" && !validChecksum(checksum) {
+		return nil, ErrInvalidChecksum
+	}
+	if _, err := s.CheckQuota(userID, req.Size); err != nil {
+		return nil, err
+	}
+
+	session := &models.UploadSession{
+		ID:        uuid.New().String(),
+		UserID:    userID,
+		FileName:  filepath.Base(req.FileName),
+		MimeType:  mimeType,
+		Size:      req.Size,
+		ChunkSize: s.cfg.ChunkSize,
+		Checksum:  checksum,
+		Title:     strings.TrimSpace(req.Title),
+		AltText:   strings.TrimSpace(req.AltText),
+		Caption:   strings.TrimSpace(req.Caption),
+		Credit:    strings.TrimSpace(req.Credit),
+		Private:   req.Private,
+		Status:    models.UploadStatusUploading,
+		ExpiresAt: time.Now().Add(s.cfg.UploadTTL),
+	}
+	folder, err := s.resolveFolder(req.FolderID, req.Folder)
+	if err != nil {
+		return nil, err
+	}
+	if folder != nil {
+		session.FolderID = &folder.ID
+	}
+	if err := s.repo.CreateUploadSession(session); err != nil {
+		return nil, err
+	}
+	return &UploadStatus{UploadSession: session, TotalChunks: session.Chunks(), Received: []int{}}, nil
+}
+
+func (s *service) GetUpload(userID uint, id string) (*UploadStatus, error) {
+	session, err := s.findUpload(userID, id)
+	if err != nil {
+		return nil, err
+	}
+	return s.uploadStatus(session)
+}
+
+func (s *service) PutChunk(userID uint, id string, index int, checksum string, r io.Reader) (*UploadStatus, error) {
+	session, err := s.findUpload(userID, id)
+	if err != nil {
+		return nil, err
+	}
+	switch session.Status {
+	case models.UploadStatusCompleted:
+		return nil, ErrUploadCompleted
+	case models.UploadStatusCompleting:
+		return nil, ErrUploadInProgress
+	}
+	if index < 0 || index >= session.Chunks() {
+		return nil, ErrChunkOutOfRange
+	}
+	checksum = strings.ToLower(strings.TrimSpace(checksum))
+	if !validChecksum(checksum) {
+		return nil, ErrInvalidChecksum
+	}
+
+	dir := s.uploadDir(session.ID)
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return nil, err
+	}
+	// Stage next to the chunk and rename, so a dropped connection never
+	// leaves a partial chunk that looks received
+	tmp, err := os.CreateTemp(dir, ".chunk-*")
+	if err != nil {
+		return nil, err
+	}
+	defer os.Remove(tmp.Name())
+
+	want := session.ChunkLength(index)
+	hash := sha256.New()
+	n, err := io.Copy(io.MultiWriter(tmp, hash), io.LimitReader(r, want+1))
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return nil, err
+	}
+	if n != want {
+		return nil, ErrChunkSize
+	}
+	if hex.EncodeToString(hash.Sum(nil)) != checksum {
+		return nil, ErrChecksumMismatch
+	}
+	if err := os.Rename(tmp.Name(), s.chunkPath(session.ID, index)); err != nil {
+		return nil, err
+	}
+
+	session.ExpiresAt = time.Now().Add(s.cfg.UploadTTL)
+	if err := s.repo.TouchUploadSession(session.ID, session.ExpiresAt); err != nil {
+		return nil, err
+	}
+	return s.uploadStatus(session)
+}
+
+func (s *service) CompleteUpload(ctx context.Context, userID uint, id string) (*models.Media, error) {
+	session, err := s.findUpload(userID, id)
+	if err != nil {
+		return nil, err
+	}
+	if session.Status == models.UploadStatusCompleted && session.MediaID != nil {
+		return s.GetByID(*session.MediaID)
+	}
+	// The claim pushes back the expiry, so ExpireUploads leaves the chunks
+	// alone while they are assembled
+	deadline := time.Now().Add(s.cfg.UploadTTL)
+	claimed, err := s.repo.ClaimUploadSession(session.ID, deadline)
+	if err != nil {
+		return nil, err
+	}
+	if !claimed {
+		return nil, ErrUploadInProgress
+	}
+	session.ExpiresAt = deadline
+
+	media, err := s.assemble(ctx, session)
+	if err != nil {
+		// Leave the upload to be fixed up and completed again, or to expire
+		session.Status = models.UploadStatusUploading
+		if saveErr := s.repo.SaveUploadSession(session); saveErr != nil {
+			applog.GetLogger().WithFields(applog.Fields{"service": "media"}).Error("failed releasing upload", applog.Fields{"upload_id": session.ID, "error": saveErr.Error()})
+		}
+		return nil, err
+	}
+
+	// The session stays until it expires so a retried completion finds the
+	// media; the chunks are no longer needed
+	session.Status = models.UploadStatusCompleted
+	session.MediaID = &media.ID
+	if err := s.repo.SaveUploadSession(session); err != nil {
+		applog.GetLogger().WithFields(applog.Fields{"service": "media"}).Error("failed saving completed upload", applog.Fields{"upload_id": session.ID, "media_id": media.ID, "error": err.Error()})
+	}
+	os.RemoveAll(s.uploadDir(session.ID))
+	return media, nil
+}
+
+// assemble checks the staged chunks of a complete upload against the
+// declared type and checksum and stores them as one file
+func (s *service) assemble(ctx context.Context, session *models.UploadSession) (*models.Media, error) {
+	status, err := s.uploadStatus(session)
+	if err != nil {
+		return nil, err
+	}
+	if missing := status.TotalChunks - len(status.Received); missing > 0 {
+		return nil, fmt.Errorf("%w: %d of %d", ErrUploadIncomplete, missing, status.TotalChunks)
+	}
+
+	// First pass: hash the whole file and read its head
+	hash := sha256.New()
+	var head bytes.Buffer
+	if err := s.readChunks(session, io.MultiWriter(hash, &limitedWriter{w: &head, n: 64 << 10})); err != nil {
+		return nil, err
+	}
+	if session.Checksum != "" && hex.EncodeToString(hash.Sum(nil)) != session.Checksum {
+		return nil, ErrChecksumMismatch
+	}
+	if _, err := s.CheckQuota(session.UserID, session.Size); err != nil {
+		return nil, err
+	}
+
+	var folder *models.MediaFolder
+	if session.FolderID != nil {
+		if folder, err = s.GetFolder(*session.FolderID); err != nil && !errors.Is(err, ErrFolderNotFound) {
+			return nil, err
+		}
+	}
+	in := NewMedia{
+		OriginalName: session.FileName,
+		UserID:       session.UserID,
+		Folder:       folder,
+		Title:        session.Title,
+		AltText:      session.AltText,
+		Caption:      session.Caption,
+		Credit:       session.Credit,
+		Private:      session.Private,
+	}
+
+	if filetype.Category(session.MimeType) != "video" {
+		// Small enough for memory: validate, sanitize and prepare like a
+		// regular upload
+		var data bytes.Buffer
+		if err := s.readChunks(session, &data); err != nil {
+			return nil, err
+		}
+		upload, err := s.Validate(session.FileName, data.Bytes())
+		if err != nil {
+			return nil, err
+		}
+		in.MimeType, in.Category, in.Ext = upload.MimeType, upload.Category, upload.Ext
+		body := upload.Data
+		if upload.Category == "image" && upload.MimeType != filetype.SVG {
+			body, in.Width, in.Height, in.Processable = s.Prepare(body)
+		}
+		in.Body, in.Size = bytes.NewReader(body), int64(len(body))
+		return s.Store(ctx, in)
+	}
+
+	// Videos are streamed from the chunks; their type is checked on the head
+	if filetype.Detect(head.Bytes()) != session.MimeType {
+		return nil, ErrTypeMismatch
+	}
+	pr, pw := io.Pipe()
+	go func() {
+		pw.CloseWithError(s.readChunks(session, pw))
+	}()
+	defer pr.Close()
+	in.MimeType = session.MimeType
+	in.Category = filetype.Category(session.MimeType)
+	in.Ext = filetype.Extension(session.MimeType)
+	in.Body, in.Size = pr, session.Size
+	return s.Store(ctx, in)
+}
+
+func (s *service) AbortUpload(userID uint, id string) error {
+	session, err := s.findUpload(userID, id)
+	if err != nil {
+		return err
+	}
+	if session.Status == models.UploadStatusCompleting {
+		return ErrUploadInProgress
+	}
+	if err := s.repo.DeleteUploadSession(session.ID); err != nil {
+		return err
+	}
+	return os.RemoveAll(s.uploadDir(session.ID))
+}
+
+func (s *service) ExpireUploads() (int, error) {
+	removed := 0
+	for {
+		sessions, err := s.repo.ListExpiredUploadSessions(time.Now(), expireBatch)
+		if err != nil {
+			return removed, err
+		}
+		for _, session := range sessions {
+			if err := os.RemoveAll(s.uploadDir(session.ID)); err != nil {
+				return removed, err
+			}
+			if err := s.repo.DeleteUploadSession(session.ID); err != nil {
+				return removed, err
+			}
+			removed++
+		}
+		if len(sessions) < expireBatch {
+			break
+		}
+	}
+
+	// Chunks of sessions that are gone (user deleted, crash mid-abort) are
+	// removed once they are as old as an expired session would be
+	entries, err := os.ReadDir(s.cfg.ChunkDir)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return removed, nil
+		}
+		return removed, err
+	}
+	cutoff := time.Now().Add(-s.cfg.UploadTTL)
+	for _, entry := range entries {
+		info, err := entry.Info()
+		if err != nil || !entry.IsDir() || info.ModTime().After(cutoff) {
+			continue
+		}
+		if _, err := s.repo.FindUploadSession(entry.Name()); errors.Is(err, gorm.ErrRecordNotFound) {
+			os.RemoveAll(filepath.Join(s.cfg.ChunkDir, entry.Name()))
+		}
+	}
+	return removed, nil
+}
+
+// findUpload loads an unexpired upload of a user
+func (s *service) findUpload(userID uint, id string) (*models.UploadSession, error) {
+	if _, err := uuid.Parse(id); err != nil {
+		return nil, ErrUploadNotFound
+	}
+	session, err := s.repo.FindUploadSession(id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrUploadNotFound
+		}
+		return nil, err
+	}
+	if session.UserID != userID || session.ExpiresAt.Before(time.Now()) {
+		return nil, ErrUploadNotFound
+	}
+	return session, nil
+}
+
+// uploadStatus lists the chunks staged for an upload
+func (s *service) uploadStatus(session *models.UploadSession) (*UploadStatus, error) {
+	status := &UploadStatus{UploadSession: session, TotalChunks: session.Chunks(), Received: []int{}}
+	if session.Status == models.UploadStatusCompleted {
+		status.Received = make([]int, status.TotalChunks)
+		for i := range status.Received {
+			status.Received[i] = i
+		}
+		status.ReceivedBytes = session.Size
+		return status, nil
+	}
+
+	entries, err := os.ReadDir(s.uploadDir(session.ID))
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return nil, err
+	}
+	for _, entry := range entries {
+		index, err := strconv.Atoi(strings.TrimSuffix(entry.Name(), chunkSuffix))
+		if err != nil || !strings.HasSuffix(entry.Name(), chunkSuffix) || index < 0 || index >= status.TotalChunks {
+			continue
+		}
+		status.Received = append(status.Received, index)
+		status.ReceivedBytes += session.ChunkLength(index)
+	}
+	sort.Ints(status.Received)
+	return status, nil
+}
+
+// readChunks writes the staged chunks of an upload to w in order
+func (s *service) readChunks(session *models.UploadSession, w io.Writer) error {
+	for i := 0; i < session.Chunks(); i++ {
+		f, err := os.Open(s.chunkPath(session.ID, i))
+		if err != nil {
+			return err
+		}
+		_, err = io.Copy(w, f)
+		f.Close()
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *service) uploadDir(id string) string {
+	return filepath.Join(s.cfg.ChunkDir, id)
+}
+
+func (s *service) chunkPath(id string, index int) string {
+	return filepath.Join(s.uploadDir(id), strconv.Itoa(index)+chunkSuffix)
+}
+
+// validChecksum reports whether s is a lowercase hex SHA-256
+func validChecksum(s string) bool {
+	if len(s) != sha256.Size*2 {
+		return false
+	}
+	_, err := hex.DecodeString(s)
+	return err == nil
+}
+
+// limitedWriter keeps the first n bytes written to it and discards the rest
+type limitedWriter struct {
+	w io.Writer
+	n int64
+}
+
+func (l *limitedWriter) Write(p []byte) (int, error) {
+	if l.n > 0 {
+		keep := p[:min(int64(len(p)), l.n)]
+		if _, err := l.w.Write(keep); err != nil {
+			return 0, err
+		}
+		l.n -= int64(len(keep))
+	}
+	return len(p), nil
+}
@@ -0,0 +1,327 @@
+package media
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+	"web-porto-backend/internal/adapters/storage"
+	"web-porto-backend/internal/domain/dto"
+	"web-porto-backend/internal/domain/models"
+	mediaRepo "web-porto-backend/internal/repositories/media"
+
+	"gorm.io/gorm"
+)
+
+// fakeUploads keeps upload sessions and media in memory
+type fakeUploads struct {
+	mediaRepo.Repository
+	mu       sync.Mutex
+	sessions map[string]models.UploadSession
+	media    map[uint]models.Media
+}
+
+func newFakeUploads() *fakeUploads {
+	return &fakeUploads{sessions: map[string]models.UploadSession{}, media: map[uint]models.Media{}}
+}
+
+func (r *fakeUploads) CreateUploadSession(session *models.UploadSession) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.sessions[session.ID] = *session
+	return nil
+}
+
+func (r *fakeUploads) FindUploadSession(id string) (*models.UploadSession, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	session, ok := r.sessions[id]
+	if !ok {
+		return nil, gorm.ErrRecordNotFound
+	}
+	return &session, nil
+}
+
+func (r *fakeUploads) TouchUploadSession(id string, expiresAt time.Time) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if session, ok := r.sessions[id]; ok && session.Status == models.UploadStatusUploading {
+		session.ExpiresAt = expiresAt
+		r.sessions[id] = session
+	}
+	return nil
+}
+
+func (r *fakeUploads) ClaimUploadSession(id string, deadline time.Time) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	session, ok := r.sessions[id]
+	if !ok || session.Status != models.UploadStatusUploading {
+		return false, nil
+	}
+	session.Status, session.ExpiresAt = models.UploadStatusCompleting, deadline
+	r.sessions[id] = session
+	return true, nil
+}
+
+func (r *fakeUploads) SaveUploadSession(session *models.UploadSession) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	stored := r.sessions[session.ID]
+	stored.Status, stored.MediaID, stored.ExpiresAt = session.Status, session.MediaID, session.ExpiresAt
+	r.sessions[session.ID] = stored
+	return nil
+}
+
+func (r *fakeUploads) DeleteUploadSession(id string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	delete(r.sessions, id)
+	return nil
+}
+
+func (r *fakeUploads) ListExpiredUploadSessions(now time.Time, limit int) ([]models.UploadSession, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var expired []models.UploadSession
+	for _, session := range r.sessions {
+		if session.ExpiresAt.Before(now) && len(expired) < limit {
+			expired = append(expired, session)
+		}
+	}
+	return expired, nil
+}
+
+func (r *fakeUploads) UploadUsage(userID uint) ([]mediaRepo.UploadUsage, error) {
+	return []mediaRepo.UploadUsage{{UserID: userID}}, nil
+}
+
+func (r *fakeUploads) Create(media *models.Media) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	media.ID = uint(len(r.media) + 1)
+	r.media[media.ID] = *media
+	return nil
+}
+
+func (r *fakeUploads) FindByID(id uint) (*models.Media, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	media, ok := r.media[id]
+	if !ok {
+		return nil, gorm.ErrRecordNotFound
+	}
+	return &media, nil
+}
+
+// mp4 is a file Detect takes for a video, sent in five chunks of 8 bytes
+// and a last one of 2
+var mp4 = append([]byte("\x00\x00\x00\x18ftypmp42"), bytes.Repeat([]byte("video"), 6)...)
+
+const userID = 7
+
+func newUploadService(t *testing.T) (Service, *fakeUploads, string) {
+	t.Helper()
+	dir := t.TempDir()
+	local, err := storage.NewLocal(filepath.Join(dir, "files"), "/uploads")
+	if err != nil {
+		t.Fatal(err)
+	}
+	store, err := storage.NewRegistry(storage.DriverLocal, local)
+	if err != nil {
+		t.Fatal(err)
+	}
+	repo := newFakeUploads()
+	chunkDir := filepath.Join(dir, "chunks")
+	return NewService(repo, store, nil, Config{ChunkDir: chunkDir, ChunkSize: 8, UploadTTL: time.Hour}), repo, chunkDir
+}
+
+func hexSum(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+func putChunk(t *testing.T, s Service, id string, index int) *UploadStatus {
+	t.Helper()
+	chunk := mp4[index*8 : min((index+1)*8, len(mp4))]
+	status, err := s.PutChunk(userID, id, index, hexSum(chunk), bytes.NewReader(chunk))
+	if err != nil {
+		t.Fatalf("PutChunk(%d): %v", index, err)
+	}
+	return status
+}
+
+func createUpload(t *testing.T, s Service, checksum string) *UploadStatus {
+	t.Helper()
+	status, err := s.CreateUpload(userID, dto.CreateUploadRequest{FileName: "clip.mp4", Size: int64(len(mp4)), Checksum: checksum})
+	if err != nil {
+		t.Fatalf("CreateUpload: %v", err)
+	}
+	return status
+}
+
+func TestUploadResumesWithMissingChunks(t *testing.T) {
+	s, _, _ := newUploadService(t)
+	upload := createUpload(t, s, hexSum(mp4))
+	if upload.TotalChunks != 6 {
+		t.Fatalf("TotalChunks = %d, want 6", upload.TotalChunks)
+	}
+
+	// Chunks arrive out of order and one of them twice; the connection drops
+	// before 1, 3 and 4 are sent
+	putChunk(t, s, upload.ID, 5)
+	putChunk(t, s, upload.ID, 0)
+	putChunk(t, s, upload.ID, 2)
+	putChunk(t, s, upload.ID, 2)
+
+	status, err := s.GetUpload(userID, upload.ID)
+	if err != nil {
+		t.Fatalf("GetUpload: %v", err)
+	}
+	if got := status.Received; len(got) != 3 || got[0] != 0 || got[1] != 2 || got[2] != 5 {
+		t.Fatalf("Received = %v, want [0 2 5]", got)
+	}
+	if status.ReceivedBytes != 8+8+2 {
+		t.Fatalf("ReceivedBytes = %d", status.ReceivedBytes)
+	}
+	if _, err := s.CompleteUpload(context.Background(), userID, upload.ID); !errors.Is(err, ErrUploadIncomplete) {
+		t.Fatalf("CompleteUpload with missing chunks error = %v, want ErrUploadIncomplete", err)
+	}
+
+	putChunk(t, s, upload.ID, 1)
+	putChunk(t, s, upload.ID, 3)
+	putChunk(t, s, upload.ID, 4)
+	media, err := s.CompleteUpload(context.Background(), userID, upload.ID)
+	if err != nil {
+		t.Fatalf("CompleteUpload: %v", err)
+	}
+	body, _, err := s.Storage().Get(context.Background(), media.StorageKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer body.Close()
+	var stored bytes.Buffer
+	stored.ReadFrom(body)
+	if !bytes.Equal(stored.Bytes(), mp4) || media.MimeType != "video/mp4" {
+		t.Fatalf("stored %s %q", media.MimeType, stored.Bytes())
+	}
+
+	if _, err := s.GetUpload(8, upload.ID); !errors.Is(err, ErrUploadNotFound) {
+		t.Fatalf("GetUpload by another user error = %v, want ErrUploadNotFound", err)
+	}
+}
+
+func TestUploadRejectsBadChecksums(t *testing.T) {
+	s, _, chunkDir := newUploadService(t)
+	upload := createUpload(t, s, "")
+
+	_, err := s.PutChunk(userID, upload.ID, 0, hexSum([]byte("something else")), bytes.NewReader(mp4[:8]))
+	if !errors.Is(err, ErrChecksumMismatch) {
+		t.Fatalf("PutChunk error = %v, want ErrChecksumMismatch", err)
+	}
+	if _, err := s.PutChunk(userID, upload.ID, 0, "abc", bytes.NewReader(mp4[:8])); !errors.Is(err, ErrInvalidChecksum) {
+		t.Fatalf("PutChunk error = %v, want ErrInvalidChecksum", err)
+	}
+	if _, err := s.PutChunk(userID, upload.ID, 0, hexSum(mp4[:7]), bytes.NewReader(mp4[:7])); !errors.Is(err, ErrChunkSize) {
+		t.Fatalf("PutChunk error = %v, want ErrChunkSize", err)
+	}
+	status, err := s.GetUpload(userID, upload.ID)
+	if err != nil || len(status.Received) != 0 {
+		t.Fatalf("rejected chunks were kept: %v, %v", status.Received, err)
+	}
+	entries, _ := os.ReadDir(filepath.Join(chunkDir, upload.ID))
+	if len(entries) != 0 {
+		t.Fatalf("staged files left behind: %v", entries)
+	}
+
+	// Whole-file checksum
+	s, _, _ = newUploadService(t)
+	upload = createUpload(t, s, hexSum([]byte("not the file")))
+	for i := 0; i < upload.TotalChunks; i++ {
+		putChunk(t, s, upload.ID, i)
+	}
+	if _, err := s.CompleteUpload(context.Background(), userID, upload.ID); !errors.Is(err, ErrChecksumMismatch) {
+		t.Fatalf("CompleteUpload error = %v, want ErrChecksumMismatch", err)
+	}
+	// The upload is released to be fixed up, not stuck completing
+	status, err = s.GetUpload(userID, upload.ID)
+	if err != nil || status.Status != models.UploadStatusUploading || len(status.Received) != upload.TotalChunks {
+		t.Fatalf("upload after failed completion = %+v, %v", status, err)
+	}
+}
+
+func TestCompleteUploadRetryReturnsSameMedia(t *testing.T) {
+	s, repo, chunkDir := newUploadService(t)
+	upload := createUpload(t, s, hexSum(mp4))
+	for i := 0; i < upload.TotalChunks; i++ {
+		putChunk(t, s, upload.ID, i)
+	}
+
+	first, err := s.CompleteUpload(context.Background(), userID, upload.ID)
+	if err != nil {
+		t.Fatalf("CompleteUpload: %v", err)
+	}
+	second, err := s.CompleteUpload(context.Background(), userID, upload.ID)
+	if err != nil {
+		t.Fatalf("retried CompleteUpload: %v", err)
+	}
+	if second.ID != first.ID || len(repo.media) != 1 {
+		t.Fatalf("retry made media %d, first was %d; %d media stored", second.ID, first.ID, len(repo.media))
+	}
+	if _, err := os.Stat(filepath.Join(chunkDir, upload.ID)); !os.IsNotExist(err) {
+		t.Fatalf("chunks kept after completion: %v", err)
+	}
+	if _, err := s.PutChunk(userID, upload.ID, 0, hexSum(mp4[:8]), bytes.NewReader(mp4[:8])); !errors.Is(err, ErrUploadCompleted) {
+		t.Fatalf("PutChunk after completion error = %v, want ErrUploadCompleted", err)
+	}
+}
+
+func TestCompletingUploadIsNotExpired(t *testing.T) {
+	s, repo, chunkDir := newUploadService(t)
+	upload := createUpload(t, s, "")
+	for i := 0; i < upload.TotalChunks; i++ {
+		putChunk(t, s, upload.ID, i)
+	}
+
+	// The upload was about to expire when its completion was claimed
+	session := repo.sessions[upload.ID]
+	session.ExpiresAt = time.Now().Add(time.Second)
+	repo.sessions[upload.ID] = session
+	claimed, err := repo.ClaimUploadSession(upload.ID, time.Now().Add(time.Hour))
+	if err != nil || !claimed {
+		t.Fatal("claim failed")
+	}
+	if _, err := s.CompleteUpload(context.Background(), userID, upload.ID); !errors.Is(err, ErrUploadInProgress) {
+		t.Fatalf("concurrent CompleteUpload error = %v, want ErrUploadInProgress", err)
+	}
+
+	removed, err := s.ExpireUploads()
+	if err != nil || removed != 0 {
+		t.Fatalf("ExpireUploads = %d, %v", removed, err)
+	}
+	if _, err := os.Stat(filepath.Join(chunkDir, upload.ID)); err != nil {
+		t.Fatalf("chunks of a completing upload were removed: %v", err)
+	}
+}
+
+func TestCompleteUploadClaimExtendsExpiry(t *testing.T) {
+	s, repo, _ := newUploadService(t)
+	upload := createUpload(t, s, hexSum([]byte("not the file")))
+	for i := 0; i < upload.TotalChunks; i++ {
+		putChunk(t, s, upload.ID, i)
+	}
+	session := repo.sessions[upload.ID]
+	session.ExpiresAt = time.Now().Add(time.Second)
+	repo.sessions[upload.ID] = session
+
+	s.CompleteUpload(context.Background(), userID, upload.ID)
+	if got := repo.sessions[upload.ID].ExpiresAt; time.Until(got) < 59*time.Minute {
+		t.Fatalf("expiry after completing = %v, want about an hour away", got)
+	}
+}
@@ -0,0 +1,275 @@
+package media
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"web-porto-backend/common/utils"
+	"web-porto-backend/internal/domain/dto"
+	"web-porto-backend/internal/domain/models"
+
+	"gorm.io/gorm"
+)
+
+var (
+	ErrFolderNotFound = errors.New("folder not found")
+	ErrFolderExists   = errors.New("a folder with this name already exists here")
+	ErrFolderNotEmpty = errors.New("folder is not empty")
+	ErrInvalidFolder  = errors.New("invalid folder")
+)
+
+func (s *service) List(page, size int, q dto.ListQuery, search string, withPrivate bool) (*dto.PaginatedResponse, error) {
+	offset := (page - 1) * size
+	media, total, err := s.repo.List(q, search, withPrivate, size, offset)
+	if err != nil {
+		return nil, err
+	}
+	for i := range media {
+		signed, _ := s.Sign(&media[i], 0)
+		media[i] = *signed
+	}
+
+	pagination := dto.PaginationResponse{
+		TotalCount:  total,
+		CurrentPage: page,
+		PageSize:    size,
+		TotalPages:  int((total + int64(size) - 1) / int64(size)),
+		HasNext:     int64(page*size) < total,
+		HasPrevious: page > 1,
+	}
+	return &dto.PaginatedResponse{
+		Data:       media,
+		Pagination: pagination,
+	}, nil
+}
+
+func (s *service) Update(ctx context.Context, id uint, req dto.UpdateMediaRequest) (*models.Media, error) {
+	media, err := s.GetByID(id)
+	if err != nil {
+		return nil, err
+	}
+	if req.Private != nil {
+		if err := s.setPrivate(ctx, media, *req.Private); err != nil {
+			return nil, err
+		}
+	}
+
+	if req.Title != nil {
+		media.Title = strings.TrimSpace(*req.Title)
+	}
+	if req.AltText != nil {
+		media.AltText = strings.TrimSpace(*req.AltText)
+	}
+	if req.Caption != nil {
+		media.Caption = strings.TrimSpace(*req.Caption)
+	}
+	if req.Credit != nil {
+		media.Credit = strings.TrimSpace(*req.Credit)
+	}
+	if req.FolderID != nil {
+		if *req.FolderID == 0 {
+			media.FolderID = nil
+		} else {
+			folder, err := s.GetFolder(*req.FolderID)
+			if err != nil {
+				return nil, err
+			}
+			media.FolderID = &folder.ID
+		}
+	}
+
+	var tags []models.Tag
+	if req.Tags != nil {
+		if tags, err = s.resolveTags(req.Tags); err != nil {
+			return nil, err
+		}
+	}
+	if err := s.repo.UpdateMetadata(media, tags); err != nil {
+		return nil, err
+	}
+	return s.GetByID(id)
+}
+
+func (s *service) Usage(media *models.Media) ([]dto.MediaUsage, error) {
+	urls := []string{media.FileURL}
+	for _, v := range media.Variants {
+		if v.URL != "" {
+			urls = append(urls, v.URL)
+		}
+	}
+	return s.repo.FindUsages(urls)
+}
+
+func (s *service) ListFolders() ([]models.MediaFolder, error) {
+	return s.repo.ListFolders()
+}
+
+func (s *service) GetFolder(id uint) (*models.MediaFolder, error) {
+	folder, err := s.repo.FindFolder(id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrFolderNotFound
+		}
+		return nil, err
+	}
+	return folder, nil
+}
+
+func (s *service) CreateFolder(req dto.MediaFolderRequest) (*models.MediaFolder, error) {
+	name, err := folderName(req.Name)
+	if err != nil {
+		return nil, err
+	}
+	folder := &models.MediaFolder{Name: name, Path: name}
+	if req.ParentID != nil && *req.ParentID != 0 {
+		parent, err := s.GetFolder(*req.ParentID)
+		if err != nil {
+			return nil, err
+		}
+		folder.ParentID = &parent.ID
+		folder.Path = parent.Path + "/" + name
+	}
+	if _, err := s.repo.FindFolderByPath(folder.Path); err == nil {
+		return nil, ErrFolderExists
+	}
+	if err := s.repo.CreateFolder(folder); err != nil {
+		return nil, err
+	}
+	return folder, nil
+}
+
+func (s *service) UpdateFolder(id uint, req dto.MediaFolderRequest) (*models.MediaFolder, error) {
+	folder, err := s.GetFolder(id)
+	if err != nil {
+		return nil, err
+	}
+	name, err := folderName(req.Name)
+	if err != nil {
+		return nil, err
+	}
+
+	oldPath := folder.Path
+	folder.Name = name
+	folder.ParentID = nil
+	folder.Path = name
+	if req.ParentID != nil && *req.ParentID != 0 {
+		parent, err := s.GetFolder(*req.ParentID)
+		if err != nil {
+			return nil, err
+		}
+		// A folder cannot move into itself or one of its subfolders
+		if parent.ID == folder.ID || strings.HasPrefix(parent.Path, oldPath+"/") {
+			return nil, fmt.Errorf("%w: cannot move a folder into itself", ErrInvalidFolder)
+		}
+		folder.ParentID = &parent.ID
+		folder.Path = parent.Path + "/" + name
+	}
+	if folder.Path != oldPath {
+		if _, err := s.repo.FindFolderByPath(folder.Path); err == nil {
+			return nil, ErrFolderExists
+		}
+	}
+	if err := s.repo.UpdateFolder(folder, oldPath); err != nil {
+		return nil, err
+	}
+	return folder, nil
+}
+
+func (s *service) DeleteFolder(id uint) error {
+	if _, err := s.GetFolder(id); err != nil {
+		return err
+	}
+	n, err := s.repo.CountFolderContents(id)
+	if err != nil {
+		return err
+	}
+	if n > 0 {
+		return ErrFolderNotEmpty
+	}
+	return s.repo.DeleteFolder(id)
+}
+
+// resolveFolder returns the folder an upload goes into: folderID when set,
+// otherwise the folder at path, created as needed
+func (s *service) resolveFolder(folderID *uint, path string) (*models.MediaFolder, error) {
+	if folderID != nil && *folderID != 0 {
+		return s.GetFolder(*folderID)
+	}
+	if strings.TrimSpace(path) != "" {
+		return s.EnsureFolder(path)
+	}
+	return nil, nil
+}
+
+func (s *service) EnsureFolder(path string) (*models.MediaFolder, error) {
+	var parent *models.MediaFolder
+	for _, part := range strings.Split(strings.Trim(strings.ReplaceAll(path, "\\", "/"), "/"), "/") {
+		if part == "" {
+			continue
+		}
+		name, err := folderName(part)
+		if err != nil {
+			return nil, err
+		}
+		current := name
+		if parent != nil {
+			current = parent.Path + "/" + name
+		}
+		folder, err := s.repo.FindFolderByPath(current)
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			folder = &models.MediaFolder{Name: name, Path: current}
+			if parent != nil {
+				folder.ParentID = &parent.ID
+			}
+			if err = s.repo.CreateFolder(folder); err != nil {
+				// Another upload may have created it in the meantime
+				folder, err = s.repo.FindFolderByPath(current)
+			}
+		}
+		if err != nil {
+			return nil, err
+		}
+		parent = folder
+	}
+	if parent == nil {
+		return nil, fmt.Errorf("%w: empty path", ErrInvalidFolder)
+	}
+	return parent, nil
+}
+
+// resolveTags finds tags by name, creating the missing ones
+func (s *service) resolveTags(names []string) ([]models.Tag, error) {
+	tags := make([]models.Tag, 0, len(names))
+	seen := map[int]bool{}
+	for _, name := range names {
+		name = strings.TrimSpace(name)
+		if name == "" {
+			continue
+		}
+		tag, err := s.tagService.GetBySlug(utils.StringToSlug(name))
+		if err != nil {
+			tag, err = s.tagService.Create(&dto.CreateTagRequest{Name: name, Slug: utils.StringToSlug(name)})
+		}
+		if err != nil {
+			// Created concurrently, or the slug differs from an existing name
+			if tag, err = s.tagService.GetByName(name); err != nil {
+				return nil, fmt.Errorf("failed to handle tag '%s': %v", name, err)
+			}
+		}
+		if !seen[tag.ID] {
+			seen[tag.ID] = true
+			tags = append(tags, models.Tag{ID: tag.ID, Name: tag.Name, Slug: tag.Slug})
+		}
+	}
+	return tags, nil
+}
+
+// folderName validates a folder name; names become storage key segments
+func folderName(name string) (string, error) {
+	name = strings.TrimSpace(name)
+	if name == "" || name == "." || name == ".." || strings.ContainsAny(name, "/\\") || len(name) > 255 {
+		return "", fmt.Errorf("%w: %q is not a valid folder name", ErrInvalidFolder, name)
+	}
+	return name, nil
+}
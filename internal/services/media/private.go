@@ -0,0 +1,109 @@
+package media
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"net/url"
+	"strconv"
+	"time"
+	"web-porto-backend/internal/adapters/storage"
+	"web-porto-backend/internal/domain/models"
+)
+
+var (
+	ErrURLExpired       = errors.New("signed url expired")
+	ErrInvalidSignature = errors.New("invalid url signature")
+	ErrMediaNotStored   = errors.New("media file is not in a storage backend")
+	ErrMediaProcessing  = errors.New("media is being processed")
+	ErrPrivateTarget    = errors.New("media are made private by updating them, not by migration")
+)
+
+// MaxSignedURLTTL caps the lifetime of signed URLs requested explicitly
+const MaxSignedURLTTL = 24 * time.Hour
+
+// DeriveSigningKey derives the key signing URLs from secret, so the secret
+// itself never signs anything but JWTs
+func DeriveSigningKey(secret string) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte("media-url"))
+	return string(mac.Sum(nil))
+}
+
+func (s *service) PrivateStorage() storage.Storage {
+	return s.store.Private()
+}
+
+// Signed URLs carry their expiry and an HMAC of the storage key and expiry,
+// so a signature cannot be moved to another file or extended
+func (s *service) Sign(media *models.Media, ttl time.Duration) (*models.Media, time.Time) {
+	if ttl <= 0 {
+		ttl = s.cfg.SignedURLTTL
+	}
+	expires := time.Now().Add(ttl).Truncate(time.Second)
+	if !media.Private {
+		return media, expires
+	}
+
+	signed := *media
+	signed.FileURL = s.signURL(media.FileURL, media.StorageKey, expires)
+	signed.Variants = make(models.MediaVariants, len(media.Variants))
+	for i, v := range media.Variants {
+		if v.Key != "" {
+			v.URL = s.signURL(v.URL, v.Key, expires)
+		}
+		signed.Variants[i] = v
+	}
+	return &signed, expires
+}
+
+func (s *service) VerifyURL(key, expires, signature string) (time.Time, error) {
+	unix, err := strconv.ParseInt(expires, 10, 64)
+	if err != nil {
+		return time.Time{}, ErrInvalidSignature
+	}
+	sig, err := base64.RawURLEncoding.DecodeString(signature)
+	if err != nil || !hmac.Equal(sig, s.signature(key, unix)) {
+		return time.Time{}, ErrInvalidSignature
+	}
+	at := time.Unix(unix, 0)
+	if time.Now().After(at) {
+		return at, ErrURLExpired
+	}
+	return at, nil
+}
+
+func (s *service) signURL(rawURL, key string, expires time.Time) string {
+	q := url.Values{}
+	q.Set("expires", strconv.FormatInt(expires.Unix(), 10))
+	q.Set("signature", base64.RawURLEncoding.EncodeToString(s.signature(key, expires.Unix())))
+	return rawURL + "?" + q.Encode()
+}
+
+func (s *service) signature(key string, expires int64) []byte {
+	mac := hmac.New(sha256.New, []byte(s.cfg.SigningKey))
+	mac.Write([]byte(key + "\n" + strconv.FormatInt(expires, 10)))
+	return mac.Sum(nil)
+}
+
+// setPrivate moves the files of media between the default and the private
+// backend. Content referencing the old URLs is pointed at the new ones.
+func (s *service) setPrivate(ctx context.Context, media *models.Media, private bool) error {
+	if media.Private == private {
+		return nil
+	}
+	if media.StorageKey == "" {
+		return ErrMediaNotStored
+	}
+	if media.ProcessingStatus == models.MediaStatusProcessing {
+		return ErrMediaProcessing
+	}
+	target := s.store.Default()
+	if private {
+		target = s.store.Private()
+	}
+	_, err := s.migrate(ctx, media, target, MigrateOptions{DeleteSource: true})
+	return err
+}
@@ -0,0 +1,111 @@
+package media
+
+import (
+	"errors"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+	"web-porto-backend/internal/domain/models"
+)
+
+func newSigner(key string, ttl time.Duration) Service {
+	return NewService(newFakeUploads(), nil, nil, Config{SigningKey: key, SignedURLTTL: ttl})
+}
+
+// signedQuery signs a private media item and returns the query of its URL
+func signedQuery(t *testing.T, s Service, key string) url.Values {
+	t.Helper()
+	media := &models.Media{Private: true, StorageKey: key, FileURL: "/files/" + key}
+	signed, _ := s.Sign(media, 0)
+	u, err := url.Parse(signed.FileURL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if u.Path != "/files/"+key {
+		t.Fatalf("signed URL %s", signed.FileURL)
+	}
+	return u.Query()
+}
+
+func TestVerifyURL(t *testing.T) {
+	s := newSigner("media key", time.Minute)
+	q := signedQuery(t, s, "2024/01/report.pdf")
+
+	at, err := s.VerifyURL("2024/01/report.pdf", q.Get("expires"), q.Get("signature"))
+	if err != nil {
+		t.Fatalf("VerifyURL: %v", err)
+	}
+	if d := time.Until(at); d <= 0 || d > time.Minute {
+		t.Fatalf("expires in %v, want within a minute", d)
+	}
+
+	later := q.Get("expires") + "0"
+	flipped := []byte(q.Get("signature"))
+	flipped[0] ^= 1
+	tests := []struct {
+		name, key, expires, signature string
+	}{
+		{"other key", "2024/01/salaries.pdf", q.Get("expires"), q.Get("signature")},
+		{"extended expiry", "2024/01/report.pdf", later, q.Get("signature")},
+		{"changed signature", "2024/01/report.pdf", q.Get("expires"), string(flipped)},
+		{"truncated signature", "2024/01/report.pdf", q.Get("expires"), q.Get("signature")[:10]},
+		{"no signature", "2024/01/report.pdf", q.Get("expires"), ""},
+		{"not base64", "2024/01/report.pdf", q.Get("expires"), "!!!"},
+		{"bad expiry", "2024/01/report.pdf", "soon", q.Get("signature")},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := s.VerifyURL(tt.key, tt.expires, tt.signature); !errors.Is(err, ErrInvalidSignature) {
+				t.Fatalf("VerifyURL error = %v, want ErrInvalidSignature", err)
+			}
+		})
+	}
+}
+
+func TestVerifyURLExpired(t *testing.T) {
+	s := newSigner("media key", time.Minute)
+	signed := s.(*service).signURL("/files/report.pdf", "report.pdf", time.Now().Add(-time.Minute))
+	u, err := url.Parse(signed)
+	if err != nil {
+		t.Fatal(err)
+	}
+	q := u.Query()
+
+	at, err := s.VerifyURL("report.pdf", q.Get("expires"), q.Get("signature"))
+	if !errors.Is(err, ErrURLExpired) {
+		t.Fatalf("VerifyURL error = %v, want ErrURLExpired", err)
+	}
+	if time.Since(at) < 59*time.Second {
+		t.Fatalf("expired at %v", at)
+	}
+}
+
+func TestVerifyURLRejectsOtherKeys(t *testing.T) {
+	secret := "jwt secret"
+	derived := DeriveSigningKey(secret)
+	if derived == secret || derived == DeriveSigningKey("another secret") {
+		t.Fatal("derived key is the secret, or does not depend on it")
+	}
+
+	s := newSigner(derived, time.Minute)
+	for name, other := range map[string]Service{
+		"other key":  newSigner("another key", time.Minute),
+		"JWT secret": newSigner(secret, time.Minute),
+	} {
+		t.Run(name, func(t *testing.T) {
+			q := signedQuery(t, other, "report.pdf")
+			if _, err := s.VerifyURL("report.pdf", q.Get("expires"), q.Get("signature")); !errors.Is(err, ErrInvalidSignature) {
+				t.Fatalf("VerifyURL error = %v, want ErrInvalidSignature", err)
+			}
+		})
+	}
+}
+
+func TestSignLeavesPublicMediaAlone(t *testing.T) {
+	media := &models.Media{StorageKey: "a.jpg", FileURL: "/uploads/a.jpg"}
+	signed, _ := newSigner("media key", time.Minute).Sign(media, 0)
+	if signed.FileURL != "/uploads/a.jpg" || strings.Contains(signed.FileURL, "signature") {
+		t.Fatalf("public media URL %s", signed.FileURL)
+	}
+}
@@ -0,0 +1,224 @@
+package media
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+	applog "web-porto-backend/common/logger"
+	"web-porto-backend/internal/adapters/storage"
+	"web-porto-backend/internal/domain/models"
+)
+
+// ReconcileOptions controls a reconciliation run
+type ReconcileOptions struct {
+	// Cleanup deletes orphaned files and unreferenced media older than the
+	// grace period; without it the run only reports
+	Cleanup bool
+	// Grace overrides Config.ReconcileGrace when positive
+	Grace time.Duration
+}
+
+// ReconcileReport lists what a reconciliation run found. Deleted marks the
+// entries cleanup removed.
+type ReconcileReport struct {
+	Cleanup      bool      `json:"cleanup"`
+	Grace        string    `json:"grace"`
+	Cutoff       time.Time `json:"cutoff"`
+	ScannedMedia int       `json:"scannedMedia"`
+	ScannedFiles int       `json:"scannedFiles"`
+	// Orphans are stored files no media record owns
+	Orphans []OrphanFile `json:"orphans"`
+	// Missing are media whose original or variant files are gone
+	Missing []MissingMedia `json:"missing"`
+	// Unreferenced are media no content uses
+	Unreferenced []UnreferencedMedia `json:"unreferenced"`
+	Errors       []string            `json:"errors,omitempty"`
+}
+
+type OrphanFile struct {
+	Storage string    `json:"storage"`
+	Key     string    `json:"key"`
+	Size    int64     `json:"size"`
+	ModTime time.Time `json:"modTime"`
+	Deleted bool      `json:"deleted"`
+}
+
+type MissingMedia struct {
+	MediaID uint     `json:"mediaId"`
+	FileURL string   `json:"fileUrl"`
+	Storage string   `json:"storage"`
+	Keys    []string `json:"keys"`
+	// Original is set when the original file itself is gone
+	Original bool `json:"original"`
+	InUse    bool `json:"inUse"`
+}
+
+type UnreferencedMedia struct {
+	MediaID      uint      `json:"mediaId"`
+	FileURL      string    `json:"fileUrl"`
+	OriginalName string    `json:"originalName"`
+	FileSize     int64     `json:"fileSize"`
+	UploadedAt   time.Time `json:"uploadedAt"`
+	Deleted      bool      `json:"deleted"`
+}
+
+// reconcileBatch is how many media rows are loaded at a time
+const reconcileBatch = 100
+
+func (s *service) Reconcile(ctx context.Context, opts ReconcileOptions) (*ReconcileReport, error) {
+	grace := s.cfg.ReconcileGrace
+	if opts.Grace > 0 {
+		grace = opts.Grace
+	}
+	report := &ReconcileReport{
+		Cleanup:      opts.Cleanup,
+		Grace:        grace.String(),
+		Cutoff:       time.Now().Add(-grace),
+		Orphans:      []OrphanFile{},
+		Missing:      []MissingMedia{},
+		Unreferenced: []UnreferencedMedia{},
+	}
+	fail := func(format string, args ...interface{}) {
+		report.Errors = append(report.Errors, fmt.Sprintf(format, args...))
+	}
+
+	// Media first: their keys tell the owned files from the orphans
+	known := map[string]map[string]bool{}
+	var afterID uint
+	for {
+		batch, err := s.repo.ListAfter(afterID, reconcileBatch)
+		if err != nil {
+			return report, err
+		}
+		if len(batch) == 0 {
+			break
+		}
+		for i := range batch {
+			if err := ctx.Err(); err != nil {
+				return report, err
+			}
+			media := &batch[i]
+			afterID = media.ID
+			report.ScannedMedia++
+
+			backend := media.Storage
+			if backend == "" {
+				backend = storage.DriverLocal
+			}
+			if known[backend] == nil {
+				known[backend] = map[string]bool{}
+			}
+			for _, key := range s.ownedKeys(media) {
+				known[backend][key] = true
+			}
+
+			usages, err := s.Usage(media)
+			if err != nil {
+				fail("media %d: usage: %v", media.ID, err)
+				continue
+			}
+			if missing, err := s.missingKeys(ctx, media); err != nil {
+				fail("media %d: %v", media.ID, err)
+			} else if len(missing) > 0 {
+				report.Missing = append(report.Missing, MissingMedia{
+					MediaID:  media.ID,
+					FileURL:  media.FileURL,
+					Storage:  backend,
+					Keys:     missing,
+					Original: missing[0] == media.StorageKey,
+					InUse:    len(usages) > 0,
+				})
+			}
+			if len(usages) > 0 {
+				continue
+			}
+
+			entry := UnreferencedMedia{
+				MediaID:      media.ID,
+				FileURL:      media.FileURL,
+				OriginalName: media.OriginalName,
+				FileSize:     media.FileSize,
+				UploadedAt:   media.UploadedAt,
+			}
+			if opts.Cleanup && media.UploadedAt.Before(report.Cutoff) {
+				if err := s.Remove(ctx, media); err != nil {
+					fail("media %d: remove: %v", media.ID, err)
+				} else {
+					entry.Deleted = true
+				}
+			}
+			report.Unreferenced = append(report.Unreferenced, entry)
+		}
+	}
+
+	for _, backend := range s.store.All() {
+		owned := known[backend.Name()]
+		err := backend.List(ctx, "", func(obj storage.Object) error {
+			report.ScannedFiles++
+			if owned[obj.Key] {
+				return nil
+			}
+			entry := OrphanFile{Storage: backend.Name(), Key: obj.Key, Size: obj.Size, ModTime: obj.ModTime}
+			if opts.Cleanup && obj.ModTime.Before(report.Cutoff) {
+				if err := backend.Delete(ctx, obj.Key); err != nil {
+					fail("%s %s: delete: %v", backend.Name(), obj.Key, err)
+				} else {
+					entry.Deleted = true
+				}
+			}
+			report.Orphans = append(report.Orphans, entry)
+			return nil
+		})
+		if err != nil {
+			if errors.Is(err, context.Canceled) {
+				return report, err
+			}
+			fail("%s: list: %v", backend.Name(), err)
+		}
+	}
+
+	applog.GetLogger().WithFields(applog.Fields{"service": "media"}).Info("media reconciled", applog.Fields{
+		"cleanup":      opts.Cleanup,
+		"orphans":      len(report.Orphans),
+		"missing":      len(report.Missing),
+		"unreferenced": len(report.Unreferenced),
+		"errors":       len(report.Errors),
+	})
+	return report, nil
+}
+
+// ownedKeys returns the keys media owns: its files, and while processing
+// is pending the files processing is about to write
+func (s *service) ownedKeys(media *models.Media) []string {
+	keys := media.Keys()
+	if media.ProcessingStatus == models.MediaStatusPending || media.ProcessingStatus == models.MediaStatusProcessing {
+		keys = append(keys, withSuffix(media.StorageKey, ".webp"))
+		for _, v := range s.Planned(media) {
+			keys = append(keys, v.Key, withSuffix(v.Key, ".webp"))
+		}
+	}
+	return keys
+}
+
+// missingKeys returns the keys of media whose files are gone, the original
+// first
+func (s *service) missingKeys(ctx context.Context, media *models.Media) ([]string, error) {
+	if media.StorageKey == "" {
+		// Rows from before storage keys whose URL was not below /uploads
+		return nil, nil
+	}
+	backend, err := s.store.Get(media.Storage)
+	if err != nil {
+		return nil, err
+	}
+	var missing []string
+	for _, key := range media.Keys() {
+		if _, err := backend.Stat(ctx, key); errors.Is(err, storage.ErrNotFound) {
+			missing = append(missing, key)
+		} else if err != nil {
+			return nil, err
+		}
+	}
+	return missing, nil
+}
@@ -0,0 +1,74 @@
+package media
+
+import (
+	"context"
+	"database/sql/driver"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+	"web-porto-backend/internal/adapters/storage"
+	mediaRepo "web-porto-backend/internal/repositories/media"
+	"web-porto-backend/internal/testutil/fakesql"
+)
+
+func TestReconcileCleanupKeepsMediaUsedByPosts(t *testing.T) {
+	dir := t.TempDir()
+	local, err := storage.NewLocal(dir, "/uploads")
+	if err != nil {
+		t.Fatal(err)
+	}
+	store, err := storage.NewRegistry(storage.DriverLocal, local)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, key := range []string{"cover.jpg", "unused.jpg"} {
+		if err := os.WriteFile(filepath.Join(dir, key), []byte("jpeg"), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	uploaded := time.Now().Add(-30 * 24 * time.Hour)
+	db, fake := fakesql.Open(t, func(query string, args []driver.NamedValue) ([]string, [][]driver.Value) {
+		switch {
+		case strings.Contains(query, `FROM "media" WHERE id >`) && fmt.Sprint(args[0].Value) == "0":
+			return []string{"id", "file_url", "storage", "storage_key", "uploaded_at"}, [][]driver.Value{
+				{int64(1), "/uploads/cover.jpg", storage.DriverLocal, "cover.jpg", uploaded},
+				{int64(2), "/uploads/unused.jpg", storage.DriverLocal, "unused.jpg", uploaded},
+			}
+		case strings.Contains(query, "FROM posts WHERE featured_image_url IN"):
+			for _, a := range args {
+				if a.Value == "/uploads/cover.jpg" {
+					return []string{"id", "title"}, [][]driver.Value{{"12", "Launch post"}}
+				}
+			}
+		}
+		return nil, nil
+	})
+	s := NewService(mediaRepo.NewRepository(db), store, nil, Config{})
+
+	report, err := s.Reconcile(context.Background(), ReconcileOptions{Cleanup: true})
+	if err != nil {
+		t.Fatalf("Reconcile: %v", err)
+	}
+	if len(report.Errors) > 0 {
+		t.Fatalf("errors: %v", report.Errors)
+	}
+	if len(report.Unreferenced) != 1 || report.Unreferenced[0].MediaID != 2 || !report.Unreferenced[0].Deleted {
+		t.Fatalf("unreferenced = %+v, want only media 2, deleted", report.Unreferenced)
+	}
+
+	if _, err := os.Stat(filepath.Join(dir, "cover.jpg")); err != nil {
+		t.Fatalf("file of the media used by a post: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "unused.jpg")); !os.IsNotExist(err) {
+		t.Fatalf("file of unreferenced media still there: %v", err)
+	}
+	for _, st := range fake.Statements() {
+		if strings.HasPrefix(st.Query, `DELETE FROM "media"`) && fmt.Sprint(st.Args[0].Value) != "2" {
+			t.Fatalf("deleted media %v", st.Args[0].Value)
+		}
+	}
+}
@@ -0,0 +1,691 @@
+package media
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"image"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+	applog "web-porto-backend/common/logger"
+	"web-porto-backend/internal/adapters/imaging"
+	"web-porto-backend/internal/adapters/storage"
+	"web-porto-backend/internal/domain/dto"
+	"web-porto-backend/internal/domain/models"
+	mediaRepo "web-porto-backend/internal/repositories/media"
+	tagService "web-porto-backend/internal/services/tag"
+
+	"gorm.io/gorm"
+)
+
+// maxAttempts is how often processing is tried before the media is marked failed
+const maxAttempts = 3
+
+var ErrMediaNotFound = errors.New("media not found")
+
+// Config tunes image processing and the worker loop
+type Config struct {
+	// Widths are the variant widths; only those narrower than the original are made
+	Widths      []int
+	JPEGQuality int
+	// WebP adds a lossless WebP of each variant, and of the original when
+	// it is no wider than the widest variant. A WebP is kept only when it is
+	// smaller than the JPEG/PNG it is made from.
+	WebP bool
+	// MaxPixels refuses to decode larger images (decompression bombs)
+	MaxPixels   int
+	Interval    time.Duration
+	BatchSize   int
+	LockTimeout time.Duration
+	// ReconcileGrace protects recent files and media from reconciliation
+	// cleanup, so uploads and processing in flight are not mistaken for orphans
+	ReconcileGrace time.Duration
+	// Upload limits in bytes per type; zero falls back to 50MB (2MB for SVG)
+	MaxImageSize    int64
+	MaxVideoSize    int64
+	MaxDocumentSize int64
+	MaxSVGSize      int64
+	// SVGPolicy is SVGSanitize, SVGDownload or SVGReject
+	SVGPolicy string
+	// UserQuota caps the bytes of originals a user may upload unless their
+	// account overrides it; 0 is unlimited
+	UserQuota int64
+	// ChunkDir stages the chunks of resumable uploads, sent ChunkSize bytes
+	// at a time; videos uploaded this way may be up to MaxResumableSize.
+	// Uploads idle for UploadTTL expire.
+	ChunkDir         string
+	ChunkSize        int64
+	MaxResumableSize int64
+	UploadTTL        time.Duration
+	// SigningKey signs the URLs of private media, which expire after
+	// SignedURLTTL unless a lifetime is asked for
+	SigningKey   string
+	SignedURLTTL time.Duration
+}
+
+type Service interface {
+	// Prepare strips metadata from an uploaded file before it is stored and
+	// reads the dimensions of images. processable reports whether variants
+	// will be generated for it.
+	Prepare(data []byte) (out []byte, width, height int, processable bool)
+	// Planned returns the resized variants processing creates for media. They
+	// are known up front, so upload responses can list them while the files
+	// are still being generated.
+	Planned(media *models.Media) []models.MediaVariant
+	// Enqueue wakes the worker after media was marked pending
+	Enqueue()
+	GetByID(id uint) (*models.Media, error)
+	// Process strips metadata from the original and generates its variants
+	Process(media *models.Media) error
+	// ProcessPending claims and processes pending media, returning how many
+	ProcessPending() (int, error)
+	// Start runs the worker until ctx is cancelled
+	Start(ctx context.Context)
+	// Storage is the backend new uploads are stored in
+	Storage() storage.Storage
+	// PutOptions returns how files of a media type are stored
+	PutOptions(mimeType string) storage.PutOptions
+	// MaxUploadSize is the largest upload any type allows
+	MaxUploadSize() int64
+	// Validate identifies an upload by its content, which must agree with
+	// the extension of name, and applies the size limit and SVG policy of
+	// its type. The returned data is what to store.
+	Validate(name string, data []byte) (*ValidatedUpload, error)
+	// Store saves an upload in the default backend, or the private one, and
+	// records it
+	Store(ctx context.Context, in NewMedia) (*models.Media, error)
+	// CheckQuota fails with ErrQuotaExceeded when storing size more bytes
+	// would take a user over their quota; the usage is returned either way
+	CheckQuota(userID uint, size int64) (*dto.MediaQuota, error)
+	// Quota reports the upload usage of a user
+	Quota(userID uint) (*dto.MediaQuota, error)
+	// ListQuotas reports the upload usage of every user
+	ListQuotas() ([]dto.MediaQuota, error)
+	// SetQuota overrides the quota of a user; nil restores the default
+	SetQuota(userID uint, quotaMB *int) (*dto.MediaQuota, error)
+
+	// CreateUpload starts a resumable upload of a user
+	CreateUpload(userID uint, req dto.CreateUploadRequest) (*UploadStatus, error)
+	// GetUpload reports the chunks an upload received so far, to resume it
+	GetUpload(userID uint, id string) (*UploadStatus, error)
+	// PutChunk stages one chunk of an upload; checksum is its hex SHA-256
+	PutChunk(userID uint, id string, index int, checksum string, r io.Reader) (*UploadStatus, error)
+	// CompleteUpload assembles an upload whose chunks all arrived and
+	// creates its media. Completing a completed upload returns the media.
+	CompleteUpload(ctx context.Context, userID uint, id string) (*models.Media, error)
+	// AbortUpload deletes an upload and its chunks
+	AbortUpload(userID uint, id string) error
+	// ExpireUploads deletes expired uploads and stray chunks, returning how
+	// many uploads were removed
+	ExpireUploads() (int, error)
+	// PrivateStorage is the backend of private media
+	PrivateStorage() storage.Storage
+	// Sign returns a copy of private media whose file and variant URLs are
+	// signed for ttl (the configured lifetime when 0), with their expiry.
+	// Public media are returned as they are.
+	Sign(media *models.Media, ttl time.Duration) (*models.Media, time.Time)
+	// VerifyURL checks the signature of a private file URL and returns its
+	// expiry; it fails with ErrInvalidSignature or ErrURLExpired
+	VerifyURL(key, expires, signature string) (time.Time, error)
+	// Remove deletes the files of media from its backend and then its record
+	Remove(ctx context.Context, media *models.Media) error
+	// DeleteByURL removes the media with the given file URL, returning it
+	DeleteByURL(ctx context.Context, url string) (*models.Media, error)
+	// Migrate copies media into another backend and points the records and
+	// content referencing them at the new URLs. Private media stay where they
+	// are.
+	Migrate(ctx context.Context, opts MigrateOptions) (*MigrateResult, error)
+	// Reconcile compares the storage backends with the media records and
+	// content, and with Cleanup removes what is safe to remove
+	Reconcile(ctx context.Context, opts ReconcileOptions) (*ReconcileReport, error)
+
+	// List returns a page of the media library; private media are only
+	// included, with signed URLs, when withPrivate is set
+	List(page, size int, q dto.ListQuery, search string, withPrivate bool) (*dto.PaginatedResponse, error)
+	// Update edits the library metadata of media, and moves its files when it
+	// becomes private or public
+	Update(ctx context.Context, id uint, req dto.UpdateMediaRequest) (*models.Media, error)
+	// Usage lists the content referencing the file or any variant of media
+	Usage(media *models.Media) ([]dto.MediaUsage, error)
+	ListFolders() ([]models.MediaFolder, error)
+	GetFolder(id uint) (*models.MediaFolder, error)
+	CreateFolder(req dto.MediaFolderRequest) (*models.MediaFolder, error)
+	// UpdateFolder renames or moves a folder; files keep their storage keys
+	UpdateFolder(id uint, req dto.MediaFolderRequest) (*models.MediaFolder, error)
+	// DeleteFolder removes an empty folder
+	DeleteFolder(id uint) error
+	// EnsureFolder returns the folder at a slash-separated path, creating it
+	// and its parents as needed
+	EnsureFolder(path string) (*models.MediaFolder, error)
+}
+
+// MigrateOptions controls a storage migration
+type MigrateOptions struct {
+	// To is the target backend name
+	To string
+	// DryRun checks that the source files exist without copying anything
+	DryRun bool
+	// DeleteSource removes the files from the old backend once moved
+	DeleteSource bool
+	BatchSize    int
+}
+
+// MigrateResult summarizes a storage migration
+type MigrateResult struct {
+	Moved   int      `json:"moved"`
+	Skipped int      `json:"skipped"`
+	Failed  int      `json:"failed"`
+	Bytes   int64    `json:"bytes"`
+	Errors  []string `json:"errors,omitempty"`
+}
+
+type service struct {
+	repo       mediaRepo.Repository
+	store      *storage.Registry
+	tagService tagService.Service
+	cfg        Config
+	wake       chan struct{}
+}
+
+func NewService(repo mediaRepo.Repository, store *storage.Registry, tagService tagService.Service, cfg Config) Service {
+	if cfg.JPEGQuality <= 0 || cfg.JPEGQuality > 100 {
+		cfg.JPEGQuality = 82
+	}
+	if cfg.Interval <= 0 {
+		cfg.Interval = 5 * time.Second
+	}
+	if cfg.BatchSize <= 0 {
+		cfg.BatchSize = 4
+	}
+	if cfg.LockTimeout <= 0 {
+		cfg.LockTimeout = 10 * time.Minute
+	}
+	if cfg.ReconcileGrace <= 0 {
+		cfg.ReconcileGrace = 7 * 24 * time.Hour
+	}
+	for _, limit := range []*int64{&cfg.MaxImageSize, &cfg.MaxVideoSize, &cfg.MaxDocumentSize} {
+		if *limit <= 0 {
+			*limit = 50 << 20
+		}
+	}
+	if cfg.MaxSVGSize <= 0 {
+		cfg.MaxSVGSize = 2 << 20
+	}
+	if cfg.SVGPolicy != SVGDownload && cfg.SVGPolicy != SVGReject {
+		cfg.SVGPolicy = SVGSanitize
+	}
+	if cfg.ChunkDir == "" {
+		cfg.ChunkDir = filepath.Join(os.TempDir(), "web-porto-uploads")
+	}
+	if cfg.ChunkSize <= 0 {
+		cfg.ChunkSize = 8 << 20
+	}
+	if cfg.MaxResumableSize <= 0 {
+		cfg.MaxResumableSize = 2 << 30
+	}
+	if cfg.UploadTTL <= 0 {
+		cfg.UploadTTL = 24 * time.Hour
+	}
+	if cfg.SignedURLTTL <= 0 {
+		cfg.SignedURLTTL = 15 * time.Minute
+	}
+	widths := make([]int, 0, len(cfg.Widths))
+	for _, w := range cfg.Widths {
+		if w > 0 {
+			widths = append(widths, w)
+		}
+	}
+	sort.Ints(widths)
+	cfg.Widths = widths
+	return &service{
+		repo:       repo,
+		store:      store,
+		tagService: tagService,
+		cfg:        cfg,
+		wake:       make(chan struct{}, 1),
+	}
+}
+
+func (s *service) Prepare(data []byte) ([]byte, int, int, bool) {
+	info, err := imaging.Probe(data)
+	if err != nil {
+		return data, 0, 0, false
+	}
+	if stripped, err := imaging.StripMetadata(data); err == nil {
+		data = stripped
+	}
+	return data, info.Width, info.Height, info.Format != imaging.FormatGIF
+}
+
+func (s *service) Planned(media *models.Media) []models.MediaVariant {
+	mimeType := sourceType(media.MimeType)
+	if media.Width == 0 || mimeType == "" {
+		return nil
+	}
+	ext := filepath.Ext(media.StorageKey)
+	variants := make([]models.MediaVariant, 0, len(s.cfg.Widths))
+	for _, width := range s.widths(media.Width) {
+		suffix := fmt.Sprintf("-%dw%s", width, ext)
+		variants = append(variants, models.MediaVariant{
+			Width:    width,
+			Height:   imaging.FitWidth(media.Width, media.Height, width),
+			MimeType: mimeType,
+			URL:      withSuffix(media.FileURL, suffix),
+			Key:      withSuffix(media.StorageKey, suffix),
+		})
+	}
+	return variants
+}
+
+func (s *service) Enqueue() {
+	select {
+	case s.wake <- struct{}{}:
+	default:
+	}
+}
+
+func (s *service) GetByID(id uint) (*models.Media, error) {
+	media, err := s.repo.FindByID(id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrMediaNotFound
+		}
+		return nil, err
+	}
+	return media, nil
+}
+
+func (s *service) Process(media *models.Media) error {
+	ctx := context.Background()
+	backend, err := s.store.Get(media.Storage)
+	if err != nil {
+		return err
+	}
+	data, err := readAll(ctx, backend, media.StorageKey)
+	if err != nil {
+		return err
+	}
+	stripped, err := imaging.StripMetadata(data)
+	if err != nil {
+		return err
+	}
+	if !bytes.Equal(stripped, data) {
+		if err := backend.Put(ctx, media.StorageKey, bytes.NewReader(stripped), int64(len(stripped)), s.PutOptions(media.MimeType)); err != nil {
+			return err
+		}
+		media.FileSize = int64(len(stripped))
+	}
+
+	info, err := imaging.Probe(stripped)
+	if err != nil {
+		return err
+	}
+	media.Width, media.Height = info.Width, info.Height
+	media.Variants = models.MediaVariants{}
+	if info.Format == imaging.FormatGIF {
+		return nil
+	}
+
+	img, _, err := imaging.Decode(stripped, s.cfg.MaxPixels)
+	if err != nil {
+		return err
+	}
+
+	var variants models.MediaVariants
+	fail := func(err error) error {
+		for _, v := range variants {
+			backend.Delete(ctx, v.Key)
+		}
+		return err
+	}
+	for _, planned := range s.Planned(media) {
+		resized := imaging.Resize(img, planned.Width, planned.Height)
+		var buf bytes.Buffer
+		if planned.MimeType == "image/png" {
+			err = imaging.EncodePNG(&buf, resized)
+		} else {
+			err = imaging.EncodeJPEG(&buf, resized, s.cfg.JPEGQuality)
+		}
+		if err == nil {
+			err = backend.Put(ctx, planned.Key, bytes.NewReader(buf.Bytes()), int64(buf.Len()), s.PutOptions(planned.MimeType))
+		}
+		if err != nil {
+			return fail(err)
+		}
+		planned.Size = int64(buf.Len())
+		variants = append(variants, planned)
+
+		if webp, ok, err := s.webp(ctx, backend, resized, planned, planned.Size); err != nil {
+			return fail(err)
+		} else if ok {
+			variants = append(variants, webp)
+		}
+	}
+
+	// Full size WebPs of large photos cost a lot and are never smaller
+	if n := len(s.cfg.Widths); n > 0 && media.Width <= s.cfg.Widths[n-1] {
+		original := models.MediaVariant{
+			Width:  media.Width,
+			Height: media.Height,
+			URL:    media.FileURL,
+			Key:    media.StorageKey,
+		}
+		if webp, ok, err := s.webp(ctx, backend, img, original, media.FileSize); err != nil {
+			return fail(err)
+		} else if ok {
+			variants = append(variants, webp)
+		}
+	}
+
+	media.Variants = variants
+	return nil
+}
+
+// webp stores a WebP next to base when it comes out smaller than limit bytes
+func (s *service) webp(ctx context.Context, backend storage.Storage, img *image.RGBA, base models.MediaVariant, limit int64) (models.MediaVariant, bool, error) {
+	if !s.cfg.WebP {
+		return models.MediaVariant{}, false, nil
+	}
+	var buf bytes.Buffer
+	if err := imaging.EncodeWebP(&buf, img); err != nil {
+		// Too large for WebP; the other variants still serve
+		return models.MediaVariant{}, false, nil
+	}
+	if int64(buf.Len()) >= limit {
+		return models.MediaVariant{}, false, nil
+	}
+	v := models.MediaVariant{
+		Width:    base.Width,
+		Height:   base.Height,
+		MimeType: "image/webp",
+		URL:      withSuffix(base.URL, ".webp"),
+		Key:      withSuffix(base.Key, ".webp"),
+		Size:     int64(buf.Len()),
+	}
+	if err := backend.Put(ctx, v.Key, bytes.NewReader(buf.Bytes()), v.Size, s.PutOptions(v.MimeType)); err != nil {
+		return v, false, err
+	}
+	return v, true, nil
+}
+
+func (s *service) ProcessPending() (int, error) {
+	log := applog.GetLogger().WithFields(applog.Fields{"service": "media"})
+
+	// Media of a worker that died mid-run becomes claimable again
+	if n, err := s.repo.ReleaseStale(time.Now().Add(-s.cfg.LockTimeout)); err != nil {
+		log.Error("failed releasing stale media", applog.Fields{"error": err.Error()})
+	} else if n > 0 {
+		log.Warn("released stale media processing", applog.Fields{"count": n})
+	}
+
+	claimed, err := s.repo.ClaimPending(time.Now(), s.cfg.BatchSize)
+	if err != nil {
+		return 0, err
+	}
+
+	for i := range claimed {
+		media := &claimed[i]
+		if err := s.Process(media); err != nil {
+			media.ProcessingError = err.Error()
+			permanent := errors.Is(err, imaging.ErrTooLarge) || errors.Is(err, imaging.ErrUnsupportedFormat)
+			if permanent || media.ProcessingAttempts >= maxAttempts {
+				media.ProcessingStatus = models.MediaStatusFailed
+			} else {
+				media.ProcessingStatus = models.MediaStatusPending
+			}
+			log.Error("media processing failed", applog.Fields{"media_id": media.ID, "attempts": media.ProcessingAttempts, "error": err.Error()})
+		} else {
+			now := time.Now()
+			media.ProcessingStatus = models.MediaStatusReady
+			media.ProcessingError = ""
+			media.ProcessedAt = &now
+		}
+		if err := s.repo.SaveProcessing(media); err != nil {
+			log.Error("failed saving media processing result", applog.Fields{"media_id": media.ID, "error": err.Error()})
+		}
+	}
+	return len(claimed), nil
+}
+
+func (s *service) Start(ctx context.Context) {
+	log := applog.GetLogger().WithFields(applog.Fields{"service": "media"})
+	ticker := time.NewTicker(s.cfg.Interval)
+	defer ticker.Stop()
+
+	for {
+		// Keep going while full batches come back
+		for {
+			n, err := s.ProcessPending()
+			if err != nil {
+				log.Error("failed processing media", applog.Fields{"error": err.Error()})
+			} else if n > 0 {
+				log.Info("processed media", applog.Fields{"count": n})
+			}
+			if err != nil || n < s.cfg.BatchSize {
+				break
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		case <-s.wake:
+		}
+	}
+}
+
+func (s *service) Storage() storage.Storage {
+	return s.store.Default()
+}
+
+func (s *service) Remove(ctx context.Context, media *models.Media) error {
+	backend, err := s.store.Get(media.Storage)
+	if err != nil {
+		return err
+	}
+	for _, key := range media.Keys() {
+		if key == "" {
+			continue
+		}
+		if err := backend.Delete(ctx, key); err != nil {
+			return err
+		}
+	}
+	return s.repo.Delete(media.ID)
+}
+
+func (s *service) DeleteByURL(ctx context.Context, url string) (*models.Media, error) {
+	media, err := s.repo.FindByURL(url)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrMediaNotFound
+		}
+		return nil, err
+	}
+	if err := s.Remove(ctx, media); err != nil {
+		applog.GetLogger().WithFields(applog.Fields{"service": "media"}).Error("failed removing media", applog.Fields{"media_id": media.ID, "error": err.Error()})
+		return nil, err
+	}
+	return media, nil
+}
+
+func (s *service) Migrate(ctx context.Context, opts MigrateOptions) (*MigrateResult, error) {
+	target, err := s.store.Get(opts.To)
+	if err != nil {
+		return nil, err
+	}
+	if target.Name() == storage.DriverPrivate {
+		return nil, ErrPrivateTarget
+	}
+	if opts.BatchSize <= 0 {
+		opts.BatchSize = 50
+	}
+	log := applog.GetLogger().WithFields(applog.Fields{"service": "media", "target": target.Name()})
+
+	result := &MigrateResult{}
+	var afterID uint
+	for {
+		batch, err := s.repo.ListForMigration(target.Name(), afterID, opts.BatchSize)
+		if err != nil {
+			return result, err
+		}
+		if len(batch) == 0 {
+			return result, nil
+		}
+		for i := range batch {
+			if err := ctx.Err(); err != nil {
+				return result, err
+			}
+			media := &batch[i]
+			afterID = media.ID
+			if media.StorageKey == "" {
+				result.Skipped++
+				continue
+			}
+			size, err := s.migrate(ctx, media, target, opts)
+			if err != nil {
+				result.Failed++
+				result.Errors = append(result.Errors, fmt.Sprintf("media %d: %v", media.ID, err))
+				log.Error("failed migrating media", applog.Fields{"media_id": media.ID, "error": err.Error()})
+				continue
+			}
+			result.Moved++
+			result.Bytes += size
+		}
+	}
+}
+
+// migrate moves the files of one media item into target and returns the bytes
+// copied. Variants whose file is gone are dropped rather than failing the move.
+func (s *service) migrate(ctx context.Context, media *models.Media, target storage.Storage, opts MigrateOptions) (int64, error) {
+	source, err := s.store.Get(media.Storage)
+	if err != nil {
+		return 0, err
+	}
+
+	if opts.DryRun {
+		obj, err := source.Stat(ctx, media.StorageKey)
+		if err != nil {
+			return 0, err
+		}
+		size := obj.Size
+		for _, v := range media.Variants {
+			if obj, err := source.Stat(ctx, v.Key); err == nil {
+				size += obj.Size
+			}
+		}
+		return size, nil
+	}
+
+	var copied []string
+	fail := func(err error) (int64, error) {
+		for _, key := range copied {
+			target.Delete(ctx, key)
+		}
+		return 0, err
+	}
+
+	size, err := copyObject(ctx, source, target, media.StorageKey, s.PutOptions(media.MimeType))
+	if err != nil {
+		return fail(err)
+	}
+	copied = append(copied, media.StorageKey)
+
+	urls := map[string]string{media.FileURL: target.URL(media.StorageKey)}
+	variants := models.MediaVariants{}
+	for _, v := range media.Variants {
+		n, err := copyObject(ctx, source, target, v.Key, s.PutOptions(v.MimeType))
+		if errors.Is(err, storage.ErrNotFound) {
+			continue
+		}
+		if err != nil {
+			return fail(err)
+		}
+		copied = append(copied, v.Key)
+		size += n
+		urls[v.URL] = target.URL(v.Key)
+		v.URL = target.URL(v.Key)
+		variants = append(variants, v)
+	}
+
+	moved := *media
+	moved.Storage = target.Name()
+	moved.Private = target.Name() == storage.DriverPrivate
+	moved.FileURL = target.URL(media.StorageKey)
+	moved.FilePath = target.Location(media.StorageKey)
+	moved.Variants = variants
+	if err := s.repo.SaveStorage(&moved, urls); err != nil {
+		return fail(err)
+	}
+
+	if opts.DeleteSource {
+		for _, key := range media.Keys() {
+			if err := source.Delete(ctx, key); err != nil {
+				applog.GetLogger().Warn("failed deleting migrated media file", applog.Fields{"media_id": media.ID, "key": key, "error": err.Error()})
+			}
+		}
+	}
+	*media = moved
+	return size, nil
+}
+
+// copyObject streams one object between backends and returns its size
+func copyObject(ctx context.Context, from, to storage.Storage, key string, opts storage.PutOptions) (int64, error) {
+	rc, obj, err := from.Get(ctx, key)
+	if err != nil {
+		return 0, err
+	}
+	defer rc.Close()
+	if opts.ContentType == "" {
+		opts.ContentType = obj.ContentType
+	}
+	if err := to.Put(ctx, key, rc, obj.Size, opts); err != nil {
+		return 0, err
+	}
+	return obj.Size, nil
+}
+
+// readAll reads a whole object into memory
+func readAll(ctx context.Context, backend storage.Storage, key string) ([]byte, error) {
+	rc, _, err := backend.Get(ctx, key)
+	if err != nil {
+		return nil, err
+	}
+	defer rc.Close()
+	return io.ReadAll(rc)
+}
+
+// widths returns the configured widths narrower than the original
+func (s *service) widths(original int) []int {
+	var out []int
+	for _, w := range s.cfg.Widths {
+		if w < original && (len(out) == 0 || out[len(out)-1] != w) {
+			out = append(out, w)
+		}
+	}
+	return out
+}
+
+// sourceType returns the media type of JPEG and PNG uploads, which get
+// resized variants, or "" for anything else
+func sourceType(mimeType string) string {
+	mimeType = strings.ToLower(strings.TrimSpace(mimeType))
+	switch {
+	case strings.HasPrefix(mimeType, "image/jpeg"), strings.HasPrefix(mimeType, "image/jpg"), strings.HasPrefix(mimeType, "image/pjpeg"):
+		return "image/jpeg"
+	case strings.HasPrefix(mimeType, "image/png"):
+		return "image/png"
+	}
+	return ""
+}
+
+// withSuffix replaces the extension of a path or URL with suffix
+func withSuffix(p, suffix string) string {
+	return strings.TrimSuffix(p, filepath.Ext(p)) + suffix
+}
@@ -0,0 +1,269 @@
+package media
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"path/filepath"
+	"time"
+	"web-porto-backend/internal/adapters/filetype"
+	"web-porto-backend/internal/adapters/storage"
+	"web-porto-backend/internal/domain/dto"
+	"web-porto-backend/internal/domain/models"
+	mediaRepo "web-porto-backend/internal/repositories/media"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// SVG policies: sanitized SVGs are served inline like other images, while
+// downloaded ones are kept as uploaded but only ever served as attachments
+const (
+	SVGSanitize = "sanitize"
+	SVGDownload = "download"
+	SVGReject   = "reject"
+)
+
+var (
+	ErrUnsupportedType = errors.New("file type not allowed")
+	ErrTypeMismatch    = errors.New("file extension does not match its content")
+	ErrSVGNotAllowed   = errors.New("svg uploads are not allowed")
+	ErrQuotaExceeded   = errors.New("upload quota exceeded")
+	ErrUserNotFound    = errors.New("user not found")
+	ErrInvalidQuota    = errors.New("quota must not be negative")
+)
+
+// SizeLimitError reports an upload above the limit of its type
+type SizeLimitError struct {
+	MimeType string
+	Limit    int64
+}
+
+func (e *SizeLimitError) Error() string {
+	return fmt.Sprintf("%s files are limited to %dMB", e.MimeType, e.Limit>>20)
+}
+
+// ValidatedUpload is an upload identified by its content. Ext is the
+// extension stored files of its type get, whatever the client named it.
+type ValidatedUpload struct {
+	MimeType string
+	Category string
+	Ext      string
+	Data     []byte
+}
+
+// NewMedia is a validated upload ready to be stored. Width, Height and
+// Processable come from Prepare for images. Private media go to the private
+// backend.
+type NewMedia struct {
+	OriginalName string
+	MimeType     string
+	Category     string
+	Ext          string
+	Body         io.Reader
+	Size         int64
+	Width        int
+	Height       int
+	Processable  bool
+	UserID       uint
+	Folder       *models.MediaFolder
+	Title        string
+	AltText      string
+	Caption      string
+	Credit       string
+	Private      bool
+}
+
+func (s *service) Store(ctx context.Context, in NewMedia) (*models.Media, error) {
+	// Stored files get a random name with the extension of their type, below
+	// the path of their library folder
+	name := uuid.New().String() + in.Ext
+	key := name
+	if in.Folder != nil {
+		key = in.Folder.Path + "/" + name
+	}
+	key, err := storage.CleanKey(key)
+	if err != nil {
+		return nil, ErrInvalidFolder
+	}
+
+	store := s.Storage()
+	if in.Private {
+		store = s.store.Private()
+	}
+	if err := store.Put(ctx, key, in.Body, in.Size, s.PutOptions(in.MimeType)); err != nil {
+		return nil, err
+	}
+	media := &models.Media{
+		FileName:     name,
+		OriginalName: in.OriginalName,
+		FilePath:     store.Location(key),
+		FileURL:      store.URL(key),
+		Storage:      store.Name(),
+		StorageKey:   key,
+		FileType:     in.Category,
+		FileSize:     in.Size,
+		MimeType:     in.MimeType,
+		UploadedAt:   time.Now(),
+		Width:        in.Width,
+		Height:       in.Height,
+		Title:        in.Title,
+		AltText:      in.AltText,
+		Caption:      in.Caption,
+		Credit:       in.Credit,
+		Private:      in.Private,
+	}
+	if in.UserID != 0 {
+		media.UploadedBy = &in.UserID
+	}
+	if in.Folder != nil {
+		media.FolderID = &in.Folder.ID
+	}
+	if in.Processable {
+		media.ProcessingStatus = models.MediaStatusPending
+	}
+	if err := s.repo.Create(media); err != nil {
+		// Do not leave the file behind without its record
+		store.Delete(ctx, key)
+		return nil, err
+	}
+	if in.Processable {
+		s.Enqueue()
+	}
+	return media, nil
+}
+
+func (s *service) PutOptions(mimeType string) storage.PutOptions {
+	opts := storage.PutOptions{ContentType: mimeType}
+	if mimeType == filetype.SVG && s.cfg.SVGPolicy == SVGDownload {
+		opts.Disposition = "attachment"
+	}
+	return opts
+}
+
+func (s *service) MaxUploadSize() int64 {
+	return max(s.cfg.MaxImageSize, s.cfg.MaxVideoSize, s.cfg.MaxDocumentSize, s.cfg.MaxSVGSize)
+}
+
+// maxSize returns the upload limit of a type
+func (s *service) maxSize(mimeType string) int64 {
+	if mimeType == filetype.SVG {
+		return s.cfg.MaxSVGSize
+	}
+	switch filetype.Category(mimeType) {
+	case "video":
+		return s.cfg.MaxVideoSize
+	case "document":
+		return s.cfg.MaxDocumentSize
+	}
+	return s.cfg.MaxImageSize
+}
+
+func (s *service) Validate(name string, data []byte) (*ValidatedUpload, error) {
+	// The client's Content-Type is never trusted; only the content counts
+	mimeType := filetype.Detect(data)
+	if mimeType == "" {
+		return nil, ErrUnsupportedType
+	}
+	if filetype.ForExtension(filepath.Ext(name)) != mimeType {
+		return nil, ErrTypeMismatch
+	}
+	if limit := s.maxSize(mimeType); int64(len(data)) > limit {
+		return nil, &SizeLimitError{MimeType: mimeType, Limit: limit}
+	}
+
+	if mimeType == filetype.SVG {
+		switch s.cfg.SVGPolicy {
+		case SVGReject:
+			return nil, ErrSVGNotAllowed
+		case SVGSanitize:
+			clean, err := filetype.SanitizeSVG(data)
+			if err != nil {
+				return nil, err
+			}
+			data = clean
+		}
+	}
+
+	return &ValidatedUpload{
+		MimeType: mimeType,
+		Category: filetype.Category(mimeType),
+		Ext:      filetype.Extension(mimeType),
+		Data:     data,
+	}, nil
+}
+
+// Quotas only count finished uploads, so concurrent uploads by one user can
+// overshoot by the size of the files in flight.
+func (s *service) CheckQuota(userID uint, size int64) (*dto.MediaQuota, error) {
+	quota, err := s.Quota(userID)
+	if err != nil {
+		return nil, err
+	}
+	if quota.QuotaBytes > 0 && quota.Bytes+size > quota.QuotaBytes {
+		return quota, ErrQuotaExceeded
+	}
+	return quota, nil
+}
+
+func (s *service) Quota(userID uint) (*dto.MediaQuota, error) {
+	if userID == 0 {
+		return nil, ErrUserNotFound
+	}
+	rows, err := s.repo.UploadUsage(userID)
+	if err != nil {
+		return nil, err
+	}
+	if len(rows) == 0 {
+		return nil, ErrUserNotFound
+	}
+	quota := s.quota(rows[0])
+	return &quota, nil
+}
+
+func (s *service) ListQuotas() ([]dto.MediaQuota, error) {
+	rows, err := s.repo.UploadUsage(0)
+	if err != nil {
+		return nil, err
+	}
+	quotas := make([]dto.MediaQuota, len(rows))
+	for i, row := range rows {
+		quotas[i] = s.quota(row)
+	}
+	return quotas, nil
+}
+
+func (s *service) SetQuota(userID uint, quotaMB *int) (*dto.MediaQuota, error) {
+	if quotaMB != nil && *quotaMB < 0 {
+		return nil, ErrInvalidQuota
+	}
+	if err := s.repo.SetUploadQuota(userID, quotaMB); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrUserNotFound
+		}
+		return nil, err
+	}
+	return s.Quota(userID)
+}
+
+// quota applies the default quota to the usage of a user
+func (s *service) quota(row mediaRepo.UploadUsage) dto.MediaQuota {
+	quota := dto.MediaQuota{
+		UserID:       row.UserID,
+		Username:     row.Username,
+		Files:        row.Files,
+		Bytes:        row.Bytes,
+		VariantBytes: row.VariantBytes,
+		QuotaBytes:   s.cfg.UserQuota,
+	}
+	if row.UploadQuotaMB != nil {
+		quota.QuotaBytes = int64(*row.UploadQuotaMB) << 20
+		quota.Custom = true
+	}
+	if quota.QuotaBytes > 0 {
+		remaining := max(quota.QuotaBytes-quota.Bytes, 0)
+		quota.Remaining = &remaining
+	}
+	return quota
+}
@@ -0,0 +1,56 @@
+package media
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"web-porto-backend/internal/adapters/filetype"
+)
+
+func TestValidateChecksContentAgainstName(t *testing.T) {
+	jpeg := []byte("\xff\xd8\xff\xe0\x00\x10JFIF\x00")
+	svg := []byte(`<svg xmlns="http://www.w3.org/2000/svg" onload="alert(1)"><rect/></svg>`)
+	tests := []struct {
+		name     string
+		fileName string
+		data     []byte
+		policy   string
+		want     error
+	}{
+		{"jpeg", "photo.jpg", jpeg, "", nil},
+		{"jpeg named png", "photo.png", jpeg, "", ErrTypeMismatch},
+		{"svg named jpg", "photo.jpg", svg, "", ErrTypeMismatch},
+		{"html named jpg", "photo.jpg", []byte("<html><script>alert(1)</script></html>"), "", ErrUnsupportedType},
+		{"svg rejected", "logo.svg", svg, SVGReject, ErrSVGNotAllowed},
+		{"malformed svg", "logo.svg", []byte(`<svg xmlns="http://www.w3.org/2000/svg"><g></svg>`), "", filetype.ErrInvalidSVG},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := NewService(nil, nil, nil, Config{SVGPolicy: tt.policy})
+			_, err := s.Validate(tt.fileName, tt.data)
+			if !errors.Is(err, tt.want) {
+				t.Fatalf("Validate error = %v, want %v", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestValidateSanitizesSVG(t *testing.T) {
+	svg := []byte(`<svg xmlns="http://www.w3.org/2000/svg" onload="alert(1)"><rect/></svg>`)
+
+	upload, err := NewService(nil, nil, nil, Config{}).Validate("logo.svg", svg)
+	if err != nil {
+		t.Fatalf("Validate: %v", err)
+	}
+	if upload.MimeType != filetype.SVG || strings.Contains(string(upload.Data), "onload") {
+		t.Fatalf("Validate = %s %s", upload.MimeType, upload.Data)
+	}
+
+	upload, err = NewService(nil, nil, nil, Config{SVGPolicy: SVGDownload}).Validate("logo.svg", svg)
+	if err != nil {
+		t.Fatalf("Validate: %v", err)
+	}
+	if string(upload.Data) != string(svg) {
+		t.Fatalf("download policy changed the file: %s", upload.Data)
+	}
+}
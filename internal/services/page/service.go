@@ -1,9 +1,15 @@
 ﻿package page
 
 import (
+	"fmt"
+	"strconv"
+	"time"
 	"web-porto-backend/common/utils"
 	"web-porto-backend/internal/domain/models"
 	"web-porto-backend/internal/repositories/page"
+	"web-porto-backend/internal/services/audit"
+	"web-porto-backend/internal/services/revision"
+	"web-porto-backend/internal/services/schedule"
 )
 
 // PaginationInfo represents pagination metadata
@@ -17,19 +23,44 @@ type PaginationInfo struct {
 type Service interface {
 	Create(pageData *models.Page) error
 	GetByID(id uint) (*models.Page, error)
-	GetAll(page, limit int) ([]*models.Page, *PaginationInfo, error)
-	Update(id uint, pageData *models.Page) error
+	// GetAll lists pages with the given status; models.StatusPublished lists
+	// only what visitors may see
+	GetAll(page, limit int, status string) ([]*models.Page, *PaginationInfo, error)
+	// Update replaces title, content, status and SEO block and saves the
+	// previous version as a revision attributed to editorID
+	Update(id uint, pageData *models.Page, editorID int) error
 	Delete(id uint) error
 	GetBySlug(slug string) (*models.Page, error)
 	GetPublished(page, limit int) ([]*models.Page, *PaginationInfo, error)
+	// ScheduleUnpublish takes a page offline at the given time
+	ScheduleUnpublish(id uint, at time.Time, editorID int) error
+	revision.Restorer
+	schedule.Target
 }
 
 type service struct {
-	repo page.Repository
+	repo      page.Repository
+	revisions revision.Service
+	schedules schedule.Service
 }
 
-func NewService(repo page.Repository) Service {
-	return &service{repo: repo}
+func NewService(repo page.Repository, revisions revision.Service, schedules schedule.Service) Service {
+	return &service{repo: repo, revisions: revisions, schedules: schedules}
+}
+
+// pageState is the revisioned part of a page. Status and slug are kept for
+// reference in diffs but are not changed by a restore.
+type pageState struct {
+	Title   string      `json:"title"`
+	Slug    string      `json:"slug"`
+	Status  string      `json:"status"`
+	SEO     *models.SEO `json:"seo,omitempty"` // nil in revisions older than the SEO block
+	Content string      `json:"content"`
+}
+
+func newPageState(p *models.Page) pageState {
+	seo := p.SEO
+	return pageState{Title: p.Title, Slug: p.Slug, Status: p.Status, SEO: &seo, Content: p.Content}
 }
 
 func (s *service) Create(pageData *models.Page) error {
@@ -37,19 +68,29 @@ func (s *service) Create(pageData *models.Page) error {
 	if pageData.Slug == "" {
 		pageData.Slug = utils.StringToSlug(pageData.Title)
 	}
-	return s.repo.Create(pageData)
+	if pageData.Status == "published" && pageData.PublishedAt == nil {
+		now := time.Now()
+		pageData.PublishedAt = &now
+	}
+	pageData.Status, pageData.PublishedAt = schedule.ResolveStatus(pageData.Status, pageData.PublishedAt)
+
+	if err := s.repo.Create(pageData); err != nil {
+		return err
+	}
+	s.syncSchedule(pageData, 0)
+	return nil
 }
 
 func (s *service) GetByID(id uint) (*models.Page, error) {
 	return s.repo.GetByID(id)
 }
 
-func (s *service) GetAll(page, limit int) ([]*models.Page, *PaginationInfo, error) {
+func (s *service) GetAll(page, limit int, status string) ([]*models.Page, *PaginationInfo, error) {
 	// Validate pagination parameters
 	page, limit = utils.ValidatePageAndLimit(page, limit)
 	offset := (page - 1) * limit
 
-	pages, total, err := s.repo.GetAll(limit, offset)
+	pages, total, err := s.repo.GetAll(status, limit, offset)
 	if err != nil {
 		return nil, nil, err
 	}
@@ -58,21 +99,82 @@ func (s *service) GetAll(page, limit int) ([]*models.Page, *PaginationInfo, erro
 	return pages, pagination, nil
 }
 
-func (s *service) Update(id uint, pageData *models.Page) error {
+func (s *service) Update(id uint, pageData *models.Page, editorID int) error {
+	return s.update(id, pageData, editorID, "")
+}
+
+func (s *service) update(id uint, pageData *models.Page, editorID int, summary string) error {
 	existingPage, err := s.repo.GetByID(id)
 	if err != nil {
 		return err
 	}
 
+	// Keep the previous version before anything is overwritten
+	if s.revisions != nil {
+		if _, err := s.revisions.Snapshot(strconv.Itoa(existingPage.ID), existingPage.Title, newPageState(existingPage), editorID, summary); err != nil {
+			return fmt.Errorf("failed to save revision: %w", err)
+		}
+	}
+
 	// Update fields according to actual model
 	existingPage.Title = pageData.Title
 	existingPage.Content = pageData.Content
+	existingPage.SEO = pageData.SEO
 	existingPage.Status = pageData.Status // Update slug if title changed
 	if existingPage.Title != pageData.Title {
 		existingPage.Slug = utils.StringToSlug(pageData.Title)
 	}
+	if pageData.PublishedAt != nil {
+		existingPage.PublishedAt = pageData.PublishedAt
+	} else if existingPage.Status == "published" && existingPage.PublishedAt == nil {
+		now := time.Now()
+		existingPage.PublishedAt = &now
+	}
+	existingPage.Status, existingPage.PublishedAt = schedule.ResolveStatus(existingPage.Status, existingPage.PublishedAt)
+
+	if err := s.repo.Update(existingPage); err != nil {
+		return err
+	}
+	s.syncSchedule(existingPage, editorID)
+	return nil
+}
+
+// syncSchedule keeps the pending publish job of a page in line with its status
+func (s *service) syncSchedule(p *models.Page, editorID int) {
+	schedule.Sync(s.schedules, audit.EntityPage, strconv.Itoa(p.ID), p.Status, p.PublishedAt, nil, editorID)
+}
+
+func (s *service) ScheduleUnpublish(id uint, at time.Time, editorID int) error {
+	_, err := s.schedules.Schedule(audit.EntityPage, strconv.Itoa(int(id)), schedule.ActionUnpublish, at, editorID)
+	return err
+}
+
+// PublishScheduled publishes a page when its scheduled job is due
+func (s *service) PublishScheduled(id string, at time.Time) error {
+	return s.setScheduledStatus(id, "published", &at, "Before scheduled publish")
+}
+
+// UnpublishScheduled moves a page back to draft when its expiry is due
+func (s *service) UnpublishScheduled(id string) error {
+	return s.setScheduledStatus(id, "draft", nil, "Before scheduled unpublish")
+}
 
-	return s.repo.Update(existingPage)
+func (s *service) setScheduledStatus(id, status string, publishedAt *time.Time, summary string) error {
+	pageID, err := utils.ParseID(id)
+	if err != nil {
+		return err
+	}
+	existingPage, err := s.repo.GetByID(pageID)
+	if err != nil {
+		return err
+	}
+	return s.update(pageID, &models.Page{
+		Title:       existingPage.Title,
+		Content:     existingPage.Content,
+		SEO:         existingPage.SEO,
+		Status:      status,
+		PublishedAt: publishedAt,
+	}, 0, summary)
 }
 
 func (s *service) Delete(id uint) error {
@@ -84,17 +186,51 @@ func (s *service) GetBySlug(slug string) (*models.Page, error) {
 }
 
 func (s *service) GetPublished(page, limit int) ([]*models.Page, *PaginationInfo, error) {
-	// Validate pagination parameters
-	page, limit = utils.ValidatePageAndLimit(page, limit)
-	offset := (page - 1) * limit
+	return s.GetAll(page, limit, models.StatusPublished)
+}
 
-	pages, total, err := s.repo.GetPublished(limit, offset)
+// RevisionState returns the current revisioned state of a page
+func (s *service) RevisionState(id string) (interface{}, error) {
+	pageID, err := utils.ParseID(id)
 	if err != nil {
-		return nil, nil, err
+		return nil, err
+	}
+	existingPage, err := s.repo.GetByID(pageID)
+	if err != nil {
+		return nil, err
 	}
+	return newPageState(existingPage), nil
+}
 
-	pagination := s.calculatePagination(int(total), page, limit)
-	return pages, pagination, nil
+// RestoreRevision applies a stored revision to the page as a regular update
+func (s *service) RestoreRevision(id string, rev *models.Revision, editorID int) (interface{}, error) {
+	pageID, err := utils.ParseID(id)
+	if err != nil {
+		return nil, err
+	}
+	var state pageState
+	if err := s.revisions.Decode(rev, &state); err != nil {
+		return nil, err
+	}
+	existingPage, err := s.repo.GetByID(pageID)
+	if err != nil {
+		return nil, err
+	}
+
+	restored := &models.Page{
+		Title:       state.Title,
+		Content:     state.Content,
+		SEO:         existingPage.SEO,
+		Status:      existingPage.Status,
+		PublishedAt: existingPage.PublishedAt,
+	}
+	if state.SEO != nil {
+		restored.SEO = *state.SEO
+	}
+	if err := s.update(pageID, restored, editorID, fmt.Sprintf("Before restoring version %d", rev.Version)); err != nil {
+		return nil, err
+	}
+	return s.repo.GetByID(pageID)
 }
 
 // calculatePagination calculates pagination metadata
@@ -17,11 +17,13 @@ type PaginationInfo struct {
 type Service interface {
 	Create(postData *models.Post) error
 	GetByID(id string) (*models.Post, error)
-	GetAll(page, limit int) ([]*models.Post, *PaginationInfo, error)
+	// GetAll lists posts with the given status; models.StatusPublished lists
+	// only what visitors may see
+	GetAll(page, limit int, status string) ([]*models.Post, *PaginationInfo, error)
 	Update(id string, postData *models.Post) error
 	Delete(id string) error
 	GetBySlug(slug string) (*models.Post, error)
-	GetByAuthorID(authorID int, page, limit int) ([]*models.Post, *PaginationInfo, error)
+	GetByAuthorID(authorID int, page, limit int, status string) ([]*models.Post, *PaginationInfo, error)
 	GetPublished(page, limit int) ([]*models.Post, *PaginationInfo, error)
 }
 
@@ -45,12 +47,12 @@ func (s *service) GetByID(id string) (*models.Post, error) {
 	return s.repo.GetByID(id)
 }
 
-func (s *service) GetAll(page, limit int) ([]*models.Post, *PaginationInfo, error) {
+func (s *service) GetAll(page, limit int, status string) ([]*models.Post, *PaginationInfo, error) {
 	// Validate pagination parameters
 	page, limit = utils.ValidatePageAndLimit(page, limit)
 	offset := (page - 1) * limit
 
-	posts, total, err := s.repo.GetAll(limit, offset)
+	posts, total, err := s.repo.GetAll(status, limit, offset)
 	if err != nil {
 		return nil, nil, err
 	}
@@ -88,12 +90,12 @@ func (s *service) GetBySlug(slug string) (*models.Post, error) {
 	return s.repo.GetBySlug(slug)
 }
 
-func (s *service) GetByAuthorID(authorID int, page, limit int) ([]*models.Post, *PaginationInfo, error) {
+func (s *service) GetByAuthorID(authorID int, page, limit int, status string) ([]*models.Post, *PaginationInfo, error) {
 	// Validate pagination parameters
 	page, limit = utils.ValidatePageAndLimit(page, limit)
 	offset := (page - 1) * limit
 
-	posts, total, err := s.repo.GetByAuthorID(authorID, limit, offset)
+	posts, total, err := s.repo.GetByAuthorID(authorID, status, limit, offset)
 	if err != nil {
 		return nil, nil, err
 	}
@@ -103,17 +105,7 @@ func (s *service) GetByAuthorID(authorID int, page, limit int) ([]*models.Post,
 }
 
 func (s *service) GetPublished(page, limit int) ([]*models.Post, *PaginationInfo, error) {
-	// Validate pagination parameters
-	page, limit = utils.ValidatePageAndLimit(page, limit)
-	offset := (page - 1) * limit
-
-	posts, total, err := s.repo.GetPublished(limit, offset)
-	if err != nil {
-		return nil, nil, err
-	}
-
-	pagination := s.calculatePagination(int(total), page, limit)
-	return posts, pagination, nil
+	return s.GetAll(page, limit, models.StatusPublished)
 }
 
 // calculatePagination calculates pagination metadata
@@ -0,0 +1,158 @@
+package project
+
+import (
+	"encoding/json"
+	"fmt"
+	"web-porto-backend/internal/domain/dto"
+	"web-porto-backend/internal/domain/models"
+)
+
+// projectState is the revisioned part of a project. Status and slug are kept
+// for reference in diffs but are not changed by a restore.
+type projectState struct {
+	Title        string                 `json:"title"`
+	Slug         string                 `json:"slug"`
+	Status       string                 `json:"status"`
+	Description  string                 `json:"description"`
+	ThumbnailURL string                 `json:"thumbnailUrl"`
+	GitHubURL    string                 `json:"githubUrl"`
+	LiveDemoURL  string                 `json:"liveDemoUrl"`
+	Categories   []dto.CategoryResponse `json:"categories"`
+	Technologies []dto.TagResponse      `json:"technologies"`
+	Tags         []dto.TagResponse      `json:"tags"`
+	Images       []dto.ProjectImageData `json:"images"`
+	Videos       []dto.ProjectVideoData `json:"videos"`
+	Metadata     map[string]interface{} `json:"metadata"`
+	SEO          *dto.SEO               `json:"seo,omitempty"` // nil in revisions older than the SEO block
+	Content      string                 `json:"content"`
+}
+
+func newProjectState(project *models.Project) projectState {
+	state := projectState{
+		Title:        project.Title,
+		Slug:         project.Slug,
+		Status:       project.Status,
+		Description:  project.Description,
+		ThumbnailURL: project.ThumbnailURL,
+		GitHubURL:    project.GitHubURL,
+		LiveDemoURL:  project.LiveDemoURL,
+		Categories:   []dto.CategoryResponse{},
+		Technologies: []dto.TagResponse{},
+		Tags:         []dto.TagResponse{},
+		Images:       []dto.ProjectImageData{},
+		Videos:       []dto.ProjectVideoData{},
+		Metadata:     map[string]interface{}{},
+		SEO:          &dto.SEO{},
+		Content:      project.Content,
+	}
+	for _, cat := range project.Categories {
+		state.Categories = append(state.Categories, dto.CategoryResponse{ID: cat.ID, Name: cat.Name, Slug: cat.Slug})
+	}
+	for _, tech := range project.Technologies {
+		state.Technologies = append(state.Technologies, dto.TagResponse{ID: tech.ID, Name: tech.Name, Slug: tech.Slug})
+	}
+	for _, tag := range project.Tags {
+		state.Tags = append(state.Tags, dto.TagResponse{ID: tag.ID, Name: tag.Name, Slug: tag.Slug})
+	}
+	for _, img := range project.Images {
+		state.Images = append(state.Images, dto.ProjectImageData{URL: img.URL, Caption: img.Caption, SortOrder: img.SortOrder})
+	}
+	for _, vid := range project.Videos {
+		state.Videos = append(state.Videos, dto.ProjectVideoData{URL: vid.URL, Caption: vid.Caption, SortOrder: vid.SortOrder})
+	}
+	if project.Metadata != "" {
+		_ = json.Unmarshal([]byte(project.Metadata), &state.Metadata)
+	}
+	*state.SEO = dto.SEO(project.SEO)
+	return state
+}
+
+// saveRevision snapshots a project before it is modified
+func (s *Service) saveRevision(project *models.Project, editorID int, summary string) error {
+	if s.revisionService == nil {
+		return nil
+	}
+	if _, err := s.revisionService.Snapshot(project.ID, project.Title, newProjectState(project), editorID, summary); err != nil {
+		return fmt.Errorf("failed to save revision: %w", err)
+	}
+	return nil
+}
+
+// RevisionState returns the current revisioned state of a project
+func (s *Service) RevisionState(id string) (interface{}, error) {
+	project, err := s.projectRepo.GetByID(id)
+	if err != nil {
+		return nil, err
+	}
+	return newProjectState(project), nil
+}
+
+// RestoreRevision applies a stored revision to the project as a regular update
+func (s *Service) RestoreRevision(id string, revision *models.Revision, editorID int) (interface{}, error) {
+	var state projectState
+	if err := s.revisionService.Decode(revision, &state); err != nil {
+		return nil, err
+	}
+
+	current, err := s.projectRepo.GetByID(id)
+	if err != nil {
+		return nil, err
+	}
+
+	ids := func(refs []dto.TagResponse) []int {
+		out := make([]int, 0, len(refs))
+		for _, ref := range refs {
+			out = append(out, ref.ID)
+		}
+		return out
+	}
+	categoryIDs := make([]int, 0, len(state.Categories))
+	for _, cat := range state.Categories {
+		categoryIDs = append(categoryIDs, cat.ID)
+	}
+
+	// Keep the live slug so restoring an old title does not break URLs.
+	// Non-nil taxonomy slices make UpdateProject replace (or clear) them.
+	req := dto.UpdateProjectRequest{
+		Title:           &state.Title,
+		Slug:            &current.Slug,
+		Description:     &state.Description,
+		Content:         &state.Content,
+		GitHubURL:       &state.GitHubURL,
+		LiveDemoURL:     &state.LiveDemoURL,
+		Categories:      categoryIDs,
+		Technologies:    ids(state.Technologies),
+		Tags:            ids(state.Tags),
+		Metadata:        state.Metadata,
+		SEO:             state.SEO,
+		EditorID:        editorID,
+		RevisionSummary: fmt.Sprintf("Before restoring version %d", revision.Version),
+	}
+	// An empty thumbnail would make UpdateProject delete the current file,
+	// which the restore must never do
+	if state.ThumbnailURL != "" {
+		req.ThumbnailURL = &state.ThumbnailURL
+	}
+	if _, err := s.UpdateProject(id, req); err != nil {
+		return nil, err
+	}
+
+	// UpdateProject only replaces media when some are given; a restore
+	// replaces them, including clearing them
+	images := make([]models.ProjectImage, 0, len(state.Images))
+	for _, img := range state.Images {
+		images = append(images, models.ProjectImage{ProjectID: id, URL: img.URL, Caption: img.Caption, SortOrder: img.SortOrder})
+	}
+	if err := s.projectRepo.UpdateProjectImages(id, images); err != nil {
+		return nil, err
+	}
+	videos := make([]models.ProjectVideo, 0, len(state.Videos))
+	for _, vid := range state.Videos {
+		videos = append(videos, models.ProjectVideo{ProjectID: id, URL: vid.URL, Caption: vid.Caption, SortOrder: vid.SortOrder})
+	}
+	if err := s.projectRepo.UpdateProjectVideos(id, videos); err != nil {
+		return nil, err
+	}
+
+	return s.GetProjectByID(id)
+}
@@ -0,0 +1,41 @@
+package project
+
+import (
+	"time"
+	"web-porto-backend/internal/domain/dto"
+	"web-porto-backend/internal/domain/models"
+	"web-porto-backend/internal/services/audit"
+	"web-porto-backend/internal/services/schedule"
+)
+
+// applyPublishDate moves a project between "published" and "scheduled"
+// depending on whether its publish date lies in the future
+func applyPublishDate(project *models.Project) {
+	project.Status, project.PublishedAt = schedule.ResolveStatus(project.Status, project.PublishedAt)
+}
+
+// syncSchedule keeps the pending jobs of a project in line with its status
+func (s *Service) syncSchedule(project *models.Project, unpublishAt *time.Time, editorID int) {
+	schedule.Sync(s.scheduleService, audit.EntityProject, project.ID, project.Status, project.PublishedAt, unpublishAt, editorID)
+}
+
+// PublishScheduled publishes a project when its scheduled job is due
+func (s *Service) PublishScheduled(id string, at time.Time) error {
+	status := "published"
+	_, err := s.UpdateProject(id, dto.UpdateProjectRequest{
+		Status:          &status,
+		PublishAt:       &at,
+		RevisionSummary: "Before scheduled publish",
+	})
+	return err
+}
+
+// UnpublishScheduled moves a project back to draft when its expiry is due
+func (s *Service) UnpublishScheduled(id string) error {
+	status := "draft"
+	_, err := s.UpdateProject(id, dto.UpdateProjectRequest{
+		Status:          &status,
+		RevisionSummary: "Before scheduled unpublish",
+	})
+	return err
+}
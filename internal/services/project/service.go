@@ -1,14 +1,18 @@
 package project
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
-	"os"
 	"strconv"
+	"time"
 	"web-porto-backend/internal/domain/dto"
 	"web-porto-backend/internal/domain/models"
 	categoryRepo "web-porto-backend/internal/repositories/category"
 	projectRepo "web-porto-backend/internal/repositories/project"
+	mediaService "web-porto-backend/internal/services/media"
+	revisionService "web-porto-backend/internal/services/revision"
+	scheduleService "web-porto-backend/internal/services/schedule"
 	tagService "web-porto-backend/internal/services/tag"
 	userService "web-porto-backend/internal/services/user"
 
@@ -19,11 +23,14 @@ import (
 
 // Service handles business logic for projects
 type Service struct {
-	projectRepo  projectRepo.Repository
-	categoryRepo categoryRepo.Repository
-	userService  userService.Service
-	tagService   tagService.Service
-	db           *gorm.DB
+	projectRepo     projectRepo.Repository
+	categoryRepo    categoryRepo.Repository
+	userService     userService.Service
+	tagService      tagService.Service
+	revisionService revisionService.Service
+	scheduleService scheduleService.Service
+	mediaService    mediaService.Service
+	db              *gorm.DB
 }
 
 // NewService creates a new project service
@@ -32,14 +39,20 @@ func NewService(
 	categoryRepo categoryRepo.Repository,
 	userService userService.Service,
 	tagService tagService.Service,
+	revisionService revisionService.Service,
+	scheduleService scheduleService.Service,
+	mediaService mediaService.Service,
 	db *gorm.DB,
 ) *Service {
 	return &Service{
-		projectRepo:  projectRepo,
-		categoryRepo: categoryRepo,
-		userService:  userService,
-		tagService:   tagService,
-		db:           db,
+		projectRepo:     projectRepo,
+		categoryRepo:    categoryRepo,
+		userService:     userService,
+		tagService:      tagService,
+		revisionService: revisionService,
+		scheduleService: scheduleService,
+		mediaService:    mediaService,
+		db:              db,
 	}
 }
 
@@ -215,10 +228,21 @@ func (s *Service) CreateProject(req dto.CreateProjectRequest) (*dto.ProjectRespo
 		LiveDemoURL:  req.LiveDemoURL,
 		Metadata:     string(metadataJSON),
 	}
+	if req.SEO != nil {
+		project.SEO = models.SEO(*req.SEO)
+	}
+	if req.PublishAt != nil && !req.PublishAt.IsZero() {
+		project.PublishedAt = req.PublishAt
+	} else if req.Status == "published" {
+		now := time.Now()
+		project.PublishedAt = &now
+	}
+	applyPublishDate(project)
 
 	if err := s.projectRepo.Create(project); err != nil {
 		return nil, err
 	}
+	s.syncSchedule(project, req.UnpublishAt, req.EditorID)
 
 	techIDs, _ := s.resolveTechnologies(req.Technologies, req.TechnologyNames)
 	if len(techIDs) > 0 {
@@ -297,8 +321,12 @@ func (s *Service) UpdateProject(id string, req dto.UpdateProjectRequest) (*dto.P
 			TagIdStrs:       req.TagIdStrs,
 			TagNames:        req.TagNames,
 			Metadata:        req.Metadata,
+			SEO:             req.SEO,
 			Images:          req.Images,
 			Videos:          req.Videos,
+			PublishAt:       req.PublishAt,
+			UnpublishAt:     req.UnpublishAt,
+			EditorID:        req.EditorID,
 		}
 		if req.Title != nil {
 			createReq.Title = *req.Title
@@ -332,6 +360,11 @@ func (s *Service) UpdateProject(id string, req dto.UpdateProjectRequest) (*dto.P
 		return nil, err
 	}
 
+	// Keep the previous version before anything is overwritten
+	if err := s.saveRevision(project, req.EditorID, req.RevisionSummary); err != nil {
+		return nil, err
+	}
+
 	if req.Title != nil {
 		project.Title = *req.Title
 	}
@@ -343,17 +376,21 @@ func (s *Service) UpdateProject(id string, req dto.UpdateProjectRequest) (*dto.P
 	}
 	if req.ThumbnailURL != nil {
 		if *req.ThumbnailURL == "" && project.ThumbnailURL != "" {
-			var media models.Media
-			if err := s.db.Where("file_url = ?", project.ThumbnailURL).First(&media).Error; err == nil {
-				os.Remove(media.FilePath)
-				s.db.Delete(&media)
-			}
+			s.mediaService.DeleteByURL(context.Background(), project.ThumbnailURL)
 		}
 		project.ThumbnailURL = *req.ThumbnailURL
 	}
 	if req.Status != nil {
 		project.Status = *req.Status
+		if *req.Status == "published" && project.PublishedAt == nil {
+			now := time.Now()
+			project.PublishedAt = &now
+		}
+	}
+	if req.PublishAt != nil {
+		project.PublishedAt = req.PublishAt
 	}
+	applyPublishDate(project)
 	if req.GitHubURL != nil {
 		project.GitHubURL = *req.GitHubURL
 	}
@@ -378,10 +415,14 @@ func (s *Service) UpdateProject(id string, req dto.UpdateProjectRequest) (*dto.P
 	metaMap["liveDemoUrl"] = project.LiveDemoURL
 	metadataJSON, _ := json.Marshal(metaMap)
 	project.Metadata = string(metadataJSON)
+	if req.SEO != nil {
+		project.SEO = models.SEO(*req.SEO)
+	}
 
 	if err := s.projectRepo.Update(project); err != nil {
 		return nil, err
 	}
+	s.syncSchedule(project, req.UnpublishAt, req.EditorID)
 
 	if req.Technologies != nil || req.TechnologyNames != nil {
 		techIDs, err := s.resolveTechnologies(req.Technologies, req.TechnologyNames)
@@ -448,9 +489,12 @@ func (s *Service) DeleteProject(id string) error {
 	return s.projectRepo.Delete(id)
 }
 
-func (s *Service) ListProjects(page, size int) (*dto.PaginatedResponse, error) {
+// ListProjects retrieves a paginated list of projects with the given status,
+// filtered and sorted by q. models.StatusPublished lists only what visitors
+// may see.
+func (s *Service) ListProjects(page, size int, status string, q dto.ListQuery) (*dto.PaginatedResponse, error) {
 	offset := (page - 1) * size
-	projects, total, err := s.projectRepo.GetAll(size, offset)
+	projects, total, err := s.projectRepo.GetAll(status, q, size, offset)
 	if err != nil {
 		return nil, err
 	}
@@ -473,9 +517,9 @@ func (s *Service) ListProjects(page, size int) (*dto.PaginatedResponse, error) {
 	}, nil
 }
 
-func (s *Service) GetProjectsByCategorySlug(slug string, page, size int) (*dto.PaginatedResponse, error) {
+func (s *Service) GetProjectsByCategorySlug(slug, status string, page, size int) (*dto.PaginatedResponse, error) {
 	offset := (page - 1) * size
-	projects, total, err := s.projectRepo.GetByCategorySlug(slug, size, offset)
+	projects, total, err := s.projectRepo.GetByCategorySlug(slug, status, size, offset)
 	if err != nil {
 		return nil, err
 	}
@@ -506,6 +550,7 @@ func (s *Service) mapToResponse(project *models.Project) *dto.ProjectResponse {
 		Status:       project.Status,
 		GitHubURL:    project.GitHubURL,
 		LiveDemoURL:  project.LiveDemoURL,
+		PublishedAt:  project.PublishedAt,
 		CreatedAt:    project.CreatedAt,
 		UpdatedAt:    project.UpdatedAt,
 		Author: dto.AuthorResponse{
@@ -517,6 +562,7 @@ func (s *Service) mapToResponse(project *models.Project) *dto.ProjectResponse {
 		Technologies: []dto.TagResponse{},
 		Categories:   []dto.CategoryResponse{},
 		Metadata:     make(map[string]interface{}),
+		SEO:          dto.SEO(project.SEO),
 	}
 	if response.Author.Username == "" {
 		response.Author.Username = "user"
@@ -584,6 +630,7 @@ func (s *Service) mapToListResponse(project *models.Project) dto.ProjectListResp
 		Categories:     []string{},
 		CategoryModels: []dto.CategoryResponse{},
 		Metadata:       make(map[string]interface{}),
+		PublishedAt:    project.PublishedAt,
 		CreatedAt:      project.CreatedAt,
 	}
 	if response.AuthorName == "" {
@@ -630,14 +677,128 @@ func (s *Service) mapToListResponse(project *models.Project) dto.ProjectListResp
 	return response
 }
 
+// AddProjectImage adds an image to a project
 func (s *Service) AddProjectImage(projectID string, imageData dto.ProjectImageData) (*dto.ProjectImageResponse, error) {
+	project, err := s.projectRepo.GetByID(projectID)
+	if err != nil {
+		return nil, err
+	}
+
+	image := models.ProjectImage{
+		ID:        uuid.New().String(),
+		ProjectID: project.ID,
+		URL:       imageData.URL,
+		Caption:   imageData.Caption,
+		SortOrder: imageData.SortOrder,
+	}
+	if err := s.projectRepo.UpdateProjectImages(project.ID, append(project.Images, image)); err != nil {
+		return nil, err
+	}
 	return &dto.ProjectImageResponse{
-		ID: uuid.New().String(), URL: imageData.URL, Caption: imageData.Caption,
+		ID: image.ID, URL: image.URL, Caption: image.Caption, SortOrder: image.SortOrder,
 	}, nil
 }
 
+// AddProjectVideo adds a video to a project
 func (s *Service) AddProjectVideo(projectID string, videoData dto.ProjectVideoData) (*dto.ProjectVideoResponse, error) {
+	project, err := s.projectRepo.GetByID(projectID)
+	if err != nil {
+		return nil, err
+	}
+
+	video := models.ProjectVideo{
+		ID:        uuid.New().String(),
+		ProjectID: project.ID,
+		URL:       videoData.URL,
+		Caption:   videoData.Caption,
+		SortOrder: videoData.SortOrder,
+	}
+	if err := s.projectRepo.UpdateProjectVideos(project.ID, append(project.Videos, video)); err != nil {
+		return nil, err
+	}
 	return &dto.ProjectVideoResponse{
-		ID: uuid.New().String(), URL: videoData.URL, Caption: videoData.Caption,
+		ID: video.ID, URL: video.URL, Caption: video.Caption, SortOrder: video.SortOrder,
 	}, nil
 }
+
+// DeleteProjectImage removes an image from a project; an image the project
+// does not have is not found
+func (s *Service) DeleteProjectImage(projectID, imageID string) error {
+	project, err := s.projectRepo.GetByID(projectID)
+	if err != nil {
+		return err
+	}
+
+	images := make([]models.ProjectImage, 0, len(project.Images))
+	for _, image := range project.Images {
+		if image.ID != imageID {
+			images = append(images, image)
+		}
+	}
+	if len(images) == len(project.Images) {
+		return gorm.ErrRecordNotFound
+	}
+	return s.projectRepo.UpdateProjectImages(project.ID, images)
+}
+
+// DeleteProjectVideo removes a video from a project; a video the project
+// does not have is not found
+func (s *Service) DeleteProjectVideo(projectID, videoID string) error {
+	project, err := s.projectRepo.GetByID(projectID)
+	if err != nil {
+		return err
+	}
+
+	videos := make([]models.ProjectVideo, 0, len(project.Videos))
+	for _, video := range project.Videos {
+		if video.ID != videoID {
+			videos = append(videos, video)
+		}
+	}
+	if len(videos) == len(project.Videos) {
+		return gorm.ErrRecordNotFound
+	}
+	return s.projectRepo.UpdateProjectVideos(project.ID, videos)
+}
+
+// AddProjectTechnology adds a technology, found or created by name, to a
+// project
+func (s *Service) AddProjectTechnology(projectID, name string) error {
+	project, err := s.projectRepo.GetByID(projectID)
+	if err != nil {
+		return err
+	}
+
+	ids, err := s.convertTechnologyNamesToIDs([]string{name})
+	if err != nil {
+		return err
+	}
+	for _, tech := range project.Technologies {
+		ids = append(ids, tech.ID)
+	}
+	return s.projectRepo.UpdateProjectTechnologies(project.ID, s.deduplicateIDs(ids))
+}
+
+// RemoveProjectTechnology removes a technology from a project; a technology
+// the project does not have is not found
+func (s *Service) RemoveProjectTechnology(projectID, techID string) error {
+	id, err := strconv.Atoi(techID)
+	if err != nil {
+		return gorm.ErrRecordNotFound
+	}
+	project, err := s.projectRepo.GetByID(projectID)
+	if err != nil {
+		return err
+	}
+
+	ids := make([]int, 0, len(project.Technologies))
+	for _, tech := range project.Technologies {
+		if tech.ID != id {
+			ids = append(ids, tech.ID)
+		}
+	}
+	if len(ids) == len(project.Technologies) {
+		return gorm.ErrRecordNotFound
+	}
+	return s.projectRepo.UpdateProjectTechnologies(project.ID, ids)
+}
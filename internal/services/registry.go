@@ -1,65 +1,224 @@
 package services
 
 import (
+	"web-porto-backend/config"
+	"web-porto-backend/internal/adapters/mail"
+	"web-porto-backend/internal/adapters/storage"
+	"web-porto-backend/internal/auth"
 	"web-porto-backend/internal/repositories"
+	accountSrvc "web-porto-backend/internal/services/account"
 	analyticsSrvc "web-porto-backend/internal/services/analytics"
+	apiTokenSrvc "web-porto-backend/internal/services/apitoken"
 	articleSrvc "web-porto-backend/internal/services/article"
+	auditSrvc "web-porto-backend/internal/services/audit"
 	categorySrvc "web-porto-backend/internal/services/category"
 	commentSrvc "web-porto-backend/internal/services/comment"
 	experienceSrvc "web-porto-backend/internal/services/experience"
+	feedSrvc "web-porto-backend/internal/services/feed"
+	invitationSrvc "web-porto-backend/internal/services/invitation"
+	loginAttemptSrvc "web-porto-backend/internal/services/loginattempt"
+	mediaSrvc "web-porto-backend/internal/services/media"
 	pageSrvc "web-porto-backend/internal/services/page"
 	projectSrvc "web-porto-backend/internal/services/project"
+	revisionSrvc "web-porto-backend/internal/services/revision"
+	roleSrvc "web-porto-backend/internal/services/role"
+	scheduleSrvc "web-porto-backend/internal/services/schedule"
+	searchSrvc "web-porto-backend/internal/services/search"
+	seoSrvc "web-porto-backend/internal/services/seo"
 	settingSrvc "web-porto-backend/internal/services/setting"
+	sitemapSrvc "web-porto-backend/internal/services/sitemap"
 	tagSrvc "web-porto-backend/internal/services/tag"
+	tokenSrvc "web-porto-backend/internal/services/token"
+	twoFactorSrvc "web-porto-backend/internal/services/twofactor"
 	userSrvc "web-porto-backend/internal/services/user"
 )
 
 type ServiceRegistry struct {
-	AnalyticsService  analyticsSrvc.Service
-	ArticleService    *articleSrvc.Service
-	CategoryService   categorySrvc.Service
-	CommentService    commentSrvc.Service
-	ExperienceService *experienceSrvc.Service
-	UserService       userSrvc.Service
-	PageService       pageSrvc.Service
-	ProjectService    *projectSrvc.Service
-	SettingService    settingSrvc.Service
-	TagService        tagSrvc.Service
+	AccountService         accountSrvc.Service
+	APITokenService        apiTokenSrvc.Service
+	AnalyticsService       analyticsSrvc.Service
+	AuditService           auditSrvc.Service
+	ArticleService         *articleSrvc.Service
+	ArticleRevisionService revisionSrvc.Service
+	CategoryService        categorySrvc.Service
+	CommentService         commentSrvc.Service
+	ExperienceService      *experienceSrvc.Service
+	FeedService            feedSrvc.Service
+	InvitationService      invitationSrvc.Service
+	LoginAttemptService    loginAttemptSrvc.Service
+	MediaService           mediaSrvc.Service
+	UserService            userSrvc.Service
+	PageService            pageSrvc.Service
+	PageRevisionService    revisionSrvc.Service
+	ProjectService         *projectSrvc.Service
+	ProjectRevisionService revisionSrvc.Service
+	RoleService            roleSrvc.Service
+	ScheduleService        scheduleSrvc.Service
+	SearchService          searchSrvc.Service
+	SEOService             seoSrvc.Service
+	SettingService         settingSrvc.Service
+	SitemapService         sitemapSrvc.Service
+	TagService             tagSrvc.Service
+	TokenService           tokenSrvc.Service
+	TwoFactorService       twoFactorSrvc.Service
 }
 
-func NewServiceRegistry(repo *repositories.RepositoryRegistry) *ServiceRegistry {
+func NewServiceRegistry(repo *repositories.RepositoryRegistry, cfg *config.Config, store *storage.Registry) *ServiceRegistry {
 	// Create user service first
 	userService := userSrvc.NewService(repo.UserRepository)
 
+	// Revision history, one store per content type
+	articleRevisionService := revisionSrvc.NewService(repo.ArticleRevisionRepository)
+	projectRevisionService := revisionSrvc.NewService(repo.ProjectRevisionRepository)
+	pageRevisionService := revisionSrvc.NewService(repo.PageRevisionRepository)
+
+	auditService := auditSrvc.NewService(repo.AuditRepository)
+
+	// Scheduled publishing; content services register themselves as targets below
+	scheduleService := scheduleSrvc.NewService(repo.ScheduleRepository, auditService, scheduleSrvc.Config{
+		Interval:    cfg.Scheduler.Interval,
+		BatchSize:   cfg.Scheduler.BatchSize,
+		LockTimeout: cfg.Scheduler.LockTimeout,
+	})
+
 	// Create tag service
 	tagService := tagSrvc.NewService(repo.TagRepository)
 
+	// Create role service (used for permission resolution and invitations)
+	roleService := roleSrvc.NewService(repo.RoleRepository, repo.UserRepository)
+
+	tokenService := tokenSrvc.NewService(repo.TokenRepository, cfg.JWT.RefreshTokenTTL)
+
+	// Reset and verification links are scoped tokens signed with the JWT secret;
+	// they are bound to a fingerprint instead of the revocation list
+	accountService := accountSrvc.NewService(
+		userService,
+		tokenService,
+		auth.NewAuthService(cfg.JWT.Secret, cfg.JWT.AccessTokenTTL),
+		mail.NewMailer(mail.Config{
+			Driver:   cfg.Mail.Driver,
+			Host:     cfg.Mail.Host,
+			Port:     cfg.Mail.Port,
+			Username: cfg.Mail.Username,
+			Password: cfg.Mail.Password,
+			From:     cfg.Mail.From,
+			FileDir:  cfg.Mail.FileDir,
+		}),
+		accountSrvc.Config{
+			AppName:              cfg.App.Name,
+			PasswordResetTTL:     cfg.Auth.PasswordResetTTL,
+			PasswordResetURL:     cfg.Auth.PasswordResetURL,
+			EmailVerificationTTL: cfg.Auth.EmailVerificationTTL,
+			EmailVerificationURL: cfg.Auth.EmailVerificationURL,
+			RequireVerification:  cfg.Auth.RequireEmailVerification,
+		},
+	)
+
+	// Media files live in the configured storage backend; content services
+	// remove replaced images through it. URLs of private media are signed
+	// with a key derived from the JWT secret unless one is configured.
+	mediaSigningKey := cfg.Media.SigningKey
+	if mediaSigningKey == "" {
+		mediaSigningKey = mediaSrvc.DeriveSigningKey(cfg.JWT.Secret)
+	}
+	mediaService := mediaSrvc.NewService(repo.MediaRepository, store, tagService, mediaSrvc.Config{
+		Widths:           cfg.Media.ImageWidths,
+		JPEGQuality:      cfg.Media.JPEGQuality,
+		WebP:             cfg.Media.WebP,
+		MaxPixels:        cfg.Media.MaxPixels,
+		Interval:         cfg.Media.Interval,
+		BatchSize:        cfg.Media.BatchSize,
+		LockTimeout:      cfg.Media.LockTimeout,
+		ReconcileGrace:   cfg.Media.ReconcileGrace,
+		MaxImageSize:     int64(cfg.Media.MaxImageMB) << 20,
+		MaxVideoSize:     int64(cfg.Media.MaxVideoMB) << 20,
+		MaxDocumentSize:  int64(cfg.Media.MaxDocumentMB) << 20,
+		MaxSVGSize:       int64(cfg.Media.MaxSVGMB) << 20,
+		SVGPolicy:        cfg.Media.SVGPolicy,
+		UserQuota:        int64(cfg.Media.UserQuotaMB) << 20,
+		ChunkDir:         cfg.Media.ChunkDir,
+		ChunkSize:        int64(cfg.Media.ChunkSizeMB) << 20,
+		MaxResumableSize: int64(cfg.Media.MaxResumableMB) << 20,
+		UploadTTL:        cfg.Media.UploadSessionTTL,
+		SigningKey:       mediaSigningKey,
+		SignedURLTTL:     cfg.Media.SignedURLTTL,
+	})
+
+	articleService := articleSrvc.NewService(
+		repo.ArticleRepository,
+		repo.CategoryRepository,
+		repo.TagRepository,
+		userService,
+		articleRevisionService,
+		scheduleService,
+		mediaService,
+		repo.DB,
+	)
+	projectService := projectSrvc.NewService(
+		repo.ProjectRepository,
+		repo.CategoryRepository,
+		userService,
+		tagService,
+		projectRevisionService,
+		scheduleService,
+		mediaService,
+		repo.DB,
+	)
+	pageService := pageSrvc.NewService(repo.PageRepository, pageRevisionService, scheduleService)
+	settingService := settingSrvc.NewService(repo.SettingRepository)
+	scheduleService.RegisterTarget(auditSrvc.EntityArticle, articleService)
+	scheduleService.RegisterTarget(auditSrvc.EntityProject, projectService)
+	scheduleService.RegisterTarget(auditSrvc.EntityPage, pageService)
+
 	return &ServiceRegistry{
-		AnalyticsService: analyticsSrvc.NewService(repo.AnalyticsRepository, analyticsSrvc.NewContentViewService(repo.DB)),
-		ArticleService: articleSrvc.NewService(
-			repo.ArticleRepository,
-			repo.CategoryRepository,
-			repo.TagRepository,
-			userService,
-			repo.DB,
-		),
-		CategoryService: categorySrvc.NewService(repo.CategoryRepository),
-		CommentService:  commentSrvc.NewService(repo.CommentRepository),
+		AccountService:         accountService,
+		APITokenService:        apiTokenSrvc.NewService(repo.APITokenRepository, userService, roleService),
+		AnalyticsService:       analyticsSrvc.NewService(repo.AnalyticsRepository, analyticsSrvc.NewContentViewService(repo.DB)),
+		AuditService:           auditService,
+		ArticleService:         articleService,
+		ArticleRevisionService: articleRevisionService,
+		CategoryService:        categorySrvc.NewService(repo.CategoryRepository),
+		CommentService:         commentSrvc.NewService(repo.CommentRepository),
 		ExperienceService: experienceSrvc.NewService(
 			repo.ExperienceRepository,
 			tagService,
+			mediaService,
 			repo.DB,
 		),
-		UserService: userService,
-		PageService: pageSrvc.NewService(repo.PageRepository),
-		ProjectService: projectSrvc.NewService(
-			repo.ProjectRepository,
+		FeedService: feedSrvc.NewService(
+			repo.ArticleRepository,
 			repo.CategoryRepository,
-			userService,
-			tagService,
-			repo.DB,
+			repo.TagRepository,
+			settingService,
+			feedSrvc.Config{BaseURL: cfg.Server.PublicURL(), Title: cfg.App.Name},
+		),
+		InvitationService: invitationSrvc.NewService(
+			repo.InvitationRepository,
+			roleService,
+			cfg.Auth.RegistrationMode,
+			cfg.Auth.InviteTTL,
 		),
-		SettingService: settingSrvc.NewService(repo.SettingRepository),
-		TagService:     tagService,
+		LoginAttemptService: loginAttemptSrvc.NewService(repo.LoginAttemptRepository, loginAttemptSrvc.Config{
+			MaxFailures:   cfg.Auth.LoginMaxFailures,
+			IPMaxFailures: cfg.Auth.LoginIPMaxFailures,
+			LockoutBase:   cfg.Auth.LoginLockoutBase,
+			LockoutMax:    cfg.Auth.LoginLockoutMax,
+			FailureWindow: cfg.Auth.LoginFailureWindow,
+		}),
+		MediaService:           mediaService,
+		UserService:            userService,
+		PageService:            pageService,
+		PageRevisionService:    pageRevisionService,
+		ProjectService:         projectService,
+		ProjectRevisionService: projectRevisionService,
+		RoleService:            roleService,
+		ScheduleService:        scheduleService,
+		SearchService:          searchSrvc.NewService(repo.SearchRepository),
+		SEOService:             seoSrvc.NewService(repo.ArticleRepository, repo.ProjectRepository, repo.PageRepository, settingService, seoSrvc.Config{BaseURL: cfg.Server.PublicURL(), Title: cfg.App.Name}),
+		SettingService:         settingService,
+		SitemapService:         sitemapSrvc.NewService(repo.SitemapRepository, settingService, sitemapSrvc.Config{BaseURL: cfg.Server.PublicURL()}),
+		TagService:             tagService,
+		TokenService:           tokenSrvc.NewService(repo.TokenRepository, cfg.JWT.RefreshTokenTTL),
+		TwoFactorService:       twoFactorSrvc.NewService(repo.TwoFactorRepository, cfg.App.Name),
 	}
 }
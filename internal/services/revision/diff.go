@@ -0,0 +1,169 @@
+package revision
+
+import (
+	"fmt"
+	"strings"
+)
+
+type opKind byte
+
+const (
+	opEqual  opKind = ' '
+	opDelete opKind = '-'
+	opInsert opKind = '+'
+)
+
+type lineOp struct {
+	kind opKind
+	text string
+}
+
+// diffLines computes a shortest edit script between a and b using Myers'
+// O(ND) algorithm
+func diffLines(a, b []string) []lineOp {
+	n, m := len(a), len(b)
+	max := n + m
+	if max == 0 {
+		return nil
+	}
+	offset := max + 1
+	v := make([]int, 2*max+2)
+	var trace [][]int
+
+search:
+	for d := 0; d <= max; d++ {
+		trace = append(trace, append([]int(nil), v...))
+		for k := -d; k <= d; k += 2 {
+			var x int
+			if k == -d || (k != d && v[offset+k-1] < v[offset+k+1]) {
+				x = v[offset+k+1]
+			} else {
+				x = v[offset+k-1] + 1
+			}
+			y := x - k
+			for x < n && y < m && a[x] == b[y] {
+				x++
+				y++
+			}
+			v[offset+k] = x
+			if x >= n && y >= m {
+				break search
+			}
+		}
+	}
+
+	// Walk the trace backwards to recover the edit script
+	ops := make([]lineOp, 0, max)
+	x, y := n, m
+	for d := len(trace) - 1; d >= 0; d-- {
+		v := trace[d]
+		k := x - y
+		var prevK int
+		if k == -d || (k != d && v[offset+k-1] < v[offset+k+1]) {
+			prevK = k + 1
+		} else {
+			prevK = k - 1
+		}
+		prevX := v[offset+prevK]
+		prevY := prevX - prevK
+		for x > prevX && y > prevY {
+			ops = append(ops, lineOp{opEqual, a[x-1]})
+			x--
+			y--
+		}
+		if d > 0 {
+			if x == prevX {
+				ops = append(ops, lineOp{opInsert, b[y-1]})
+			} else {
+				ops = append(ops, lineOp{opDelete, a[x-1]})
+			}
+			x, y = prevX, prevY
+		}
+	}
+
+	for i, j := 0, len(ops)-1; i < j; i, j = i+1, j-1 {
+		ops[i], ops[j] = ops[j], ops[i]
+	}
+	return ops
+}
+
+// unifiedDiff renders the difference between a and b in unified format with
+// the given number of context lines. It returns "" when they are equal.
+func unifiedDiff(fromLabel, toLabel string, a, b []string, context int) string {
+	ops := diffLines(a, b)
+
+	// Group changed lines into hunks, merging ones whose context overlaps
+	type span struct{ start, end int }
+	var hunks []span
+	for i, op := range ops {
+		if op.kind == opEqual {
+			continue
+		}
+		start, end := i-context, i+context+1
+		if start < 0 {
+			start = 0
+		}
+		if end > len(ops) {
+			end = len(ops)
+		}
+		if len(hunks) > 0 && start <= hunks[len(hunks)-1].end {
+			hunks[len(hunks)-1].end = end
+		} else {
+			hunks = append(hunks, span{start, end})
+		}
+	}
+	if len(hunks) == 0 {
+		return ""
+	}
+
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "--- %s\n+++ %s\n", fromLabel, toLabel)
+
+	aLine, bLine, pos := 0, 0, 0
+	for _, h := range hunks {
+		for ; pos < h.start; pos++ {
+			aLine, bLine = advance(ops[pos].kind, aLine, bLine)
+		}
+		aStart, bStart := aLine, bLine
+		aLen, bLen := 0, 0
+		var body strings.Builder
+		for ; pos < h.end; pos++ {
+			op := ops[pos]
+			body.WriteByte(byte(op.kind))
+			body.WriteString(op.text)
+			body.WriteByte('\n')
+			if op.kind != opInsert {
+				aLen++
+			}
+			if op.kind != opDelete {
+				bLen++
+			}
+			aLine, bLine = advance(op.kind, aLine, bLine)
+		}
+		fmt.Fprintf(&sb, "@@ -%s +%s @@\n", hunkRange(aStart, aLen), hunkRange(bStart, bLen))
+		sb.WriteString(body.String())
+	}
+	return sb.String()
+}
+
+func advance(kind opKind, aLine, bLine int) (int, int) {
+	switch kind {
+	case opEqual:
+		return aLine + 1, bLine + 1
+	case opDelete:
+		return aLine + 1, bLine
+	default:
+		return aLine, bLine + 1
+	}
+}
+
+// hunkRange formats a 0-based start and length the way diff(1) does
+func hunkRange(start, length int) string {
+	if length == 0 {
+		return fmt.Sprintf("%d,0", start)
+	}
+	if length == 1 {
+		return fmt.Sprintf("%d", start+1)
+	}
+	return fmt.Sprintf("%d,%d", start+1, length)
+}
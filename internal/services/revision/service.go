@@ -0,0 +1,188 @@
+package revision
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"web-porto-backend/internal/domain/models"
+	revisionRepo "web-porto-backend/internal/repositories/revision"
+
+	"gorm.io/gorm"
+)
+
+var ErrRevisionNotFound = errors.New("revision not found")
+
+// diffContext is the number of unchanged lines shown around each change
+const diffContext = 3
+
+// Service keeps the revision history of one content type. Content services
+// call Snapshot with the previous state before every update; the state is any
+// JSON-serialisable struct describing the editable fields.
+type Service interface {
+	Snapshot(entityID, title string, state interface{}, userID int, summary string) (*models.Revision, error)
+	List(entityID string, page, limit int) ([]models.Revision, int64, error)
+	Get(entityID string, id int) (*models.Revision, error)
+	// Decode unmarshals the state stored in a revision into target
+	Decode(revision *models.Revision, target interface{}) error
+	// Diff renders a unified text diff between two states. Each state may be a
+	// struct or a revision's raw snapshot (json.RawMessage).
+	Diff(fromLabel string, from interface{}, toLabel string, to interface{}) (string, error)
+}
+
+// Restorer is implemented by content services that keep revisions, so the
+// revision endpoints can be shared between content types
+type Restorer interface {
+	// RevisionState returns the current revisioned state of an item
+	RevisionState(id string) (interface{}, error)
+	// RestoreRevision applies a stored state as a new update, snapshotting the
+	// current one first, and returns the updated item
+	RestoreRevision(id string, revision *models.Revision, editorID int) (interface{}, error)
+}
+
+type service struct {
+	repo revisionRepo.Repository
+}
+
+func NewService(repo revisionRepo.Repository) Service {
+	return &service{repo: repo}
+}
+
+func (s *service) Snapshot(entityID, title string, state interface{}, userID int, summary string) (*models.Revision, error) {
+	data, err := json.Marshal(state)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode revision: %w", err)
+	}
+
+	revision := &models.Revision{
+		EntityID: entityID,
+		Title:    title,
+		Snapshot: string(data),
+		Summary:  summary,
+	}
+	if userID > 0 {
+		revision.UserID = &userID
+	}
+
+	// Versions are unique per entity; retry if a concurrent save took ours
+	for attempt := 0; attempt < 3; attempt++ {
+		var latest int
+		if latest, err = s.repo.LatestVersion(entityID); err != nil {
+			return nil, err
+		}
+		revision.ID = 0
+		revision.Version = latest + 1
+		if err = s.repo.Create(revision); err == nil {
+			return revision, nil
+		}
+	}
+	return nil, err
+}
+
+func (s *service) List(entityID string, page, limit int) ([]models.Revision, int64, error) {
+	if page < 1 {
+		page = 1
+	}
+	if limit < 1 {
+		limit = 20
+	}
+	return s.repo.FindByEntity(entityID, (page-1)*limit, limit)
+}
+
+func (s *service) Get(entityID string, id int) (*models.Revision, error) {
+	revision, err := s.repo.FindByID(entityID, id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrRevisionNotFound
+		}
+		return nil, err
+	}
+	return revision, nil
+}
+
+func (s *service) Decode(revision *models.Revision, target interface{}) error {
+	if err := json.Unmarshal([]byte(revision.Snapshot), target); err != nil {
+		return fmt.Errorf("failed to decode revision %d: %w", revision.ID, err)
+	}
+	return nil
+}
+
+func (s *service) Diff(fromLabel string, from interface{}, toLabel string, to interface{}) (string, error) {
+	a, err := renderLines(from)
+	if err != nil {
+		return "", err
+	}
+	b, err := renderLines(to)
+	if err != nil {
+		return "", err
+	}
+	return unifiedDiff(fromLabel, toLabel, a, b, diffContext), nil
+}
+
+// renderLines turns a state into diffable text: one "field: value" line per
+// scalar, multi-line strings expanded below their field name and one line per
+// list element. Field order follows the JSON encoding of the state.
+func renderLines(state interface{}) ([]string, error) {
+	data, err := json.Marshal(state)
+	if err != nil {
+		return nil, err
+	}
+
+	dec := json.NewDecoder(bytes.NewReader(data))
+	if tok, err := dec.Token(); err != nil || tok != json.Delim('{') {
+		return nil, errors.New("revision state must be a JSON object")
+	}
+
+	var lines []string
+	for dec.More() {
+		tok, err := dec.Token()
+		if err != nil {
+			return nil, err
+		}
+		key, _ := tok.(string)
+
+		var raw json.RawMessage
+		if err := dec.Decode(&raw); err != nil {
+			return nil, err
+		}
+		lines = append(lines, renderField(key, raw)...)
+	}
+	return lines, nil
+}
+
+func renderField(key string, raw json.RawMessage) []string {
+	var str string
+	if err := json.Unmarshal(raw, &str); err == nil {
+		if !strings.Contains(str, "\n") {
+			return []string{key + ": " + str}
+		}
+		lines := []string{key + ":"}
+		for _, line := range strings.Split(strings.TrimRight(str, "\n"), "\n") {
+			lines = append(lines, "  "+line)
+		}
+		return lines
+	}
+
+	var list []json.RawMessage
+	if err := json.Unmarshal(raw, &list); err == nil {
+		if len(list) == 0 {
+			return []string{key + ": []"}
+		}
+		lines := []string{key + ":"}
+		for _, item := range list {
+			lines = append(lines, "  - "+compact(item))
+		}
+		return lines
+	}
+
+	return []string{key + ": " + compact(raw)}
+}
+
+func compact(raw json.RawMessage) string {
+	var buf bytes.Buffer
+	if err := json.Compact(&buf, raw); err != nil {
+		return string(raw)
+	}
+	return buf.String()
+}
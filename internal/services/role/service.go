@@ -0,0 +1,219 @@
+package role
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"web-porto-backend/internal/auth"
+	"web-porto-backend/internal/domain/models"
+	roleRepo "web-porto-backend/internal/repositories/role"
+	userRepo "web-porto-backend/internal/repositories/user"
+
+	"gorm.io/gorm"
+)
+
+var (
+	ErrRoleNotFound      = errors.New("role not found")
+	ErrRoleExists        = errors.New("role already exists")
+	ErrRoleNameRequired  = errors.New("role name is required")
+	ErrSystemRole        = errors.New("system roles cannot be renamed, deleted or have their permissions changed")
+	ErrUserNotFound      = errors.New("user not found")
+	ErrInvalidPermission = errors.New("invalid permission")
+)
+
+type Service interface {
+	GetAll() ([]models.Role, error)
+	GetByID(id int) (*models.Role, error)
+	Create(name, description string, permissions []string) (*models.Role, error)
+	Update(id int, name, description *string, permissions []string) (*models.Role, error)
+	Delete(id int) error
+	AssignToUser(roleID, userID int) error
+	RemoveFromUser(roleID, userID int) error
+	GetUsers(roleID int) ([]int, error)
+	GetUserRoles(userID int) ([]models.Role, error)
+	RoleExists(name string) bool
+	// RolePermissions returns the permissions of the role with the given name
+	RolePermissions(name string) ([]string, error)
+	PermissionsForUser(user *models.User) ([]string, error)
+}
+
+type service struct {
+	repo     roleRepo.Repository
+	userRepo userRepo.Repository
+}
+
+func NewService(repo roleRepo.Repository, userRepo userRepo.Repository) Service {
+	return &service{repo: repo, userRepo: userRepo}
+}
+
+func (s *service) GetAll() ([]models.Role, error) {
+	return s.repo.FindAll()
+}
+
+func (s *service) GetByID(id int) (*models.Role, error) {
+	role, err := s.repo.FindByID(id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrRoleNotFound
+		}
+		return nil, err
+	}
+	return role, nil
+}
+
+func (s *service) Create(name, description string, permissions []string) (*models.Role, error) {
+	name = strings.TrimSpace(strings.ToLower(name))
+	if name == "" {
+		return nil, ErrRoleNameRequired
+	}
+	if s.RoleExists(name) {
+		return nil, ErrRoleExists
+	}
+	perms, err := normalizePermissions(permissions)
+	if err != nil {
+		return nil, err
+	}
+
+	role := &models.Role{Name: name, Description: description}
+	for _, p := range perms {
+		role.Permissions = append(role.Permissions, models.RolePermission{Permission: p})
+	}
+	if err := s.repo.Create(role); err != nil {
+		return nil, err
+	}
+	return role, nil
+}
+
+// Update changes name/description when provided. A nil permissions slice keeps
+// the current set; an empty slice clears it. The permissions of system roles
+// are fixed.
+func (s *service) Update(id int, name, description *string, permissions []string) (*models.Role, error) {
+	role, err := s.GetByID(id)
+	if err != nil {
+		return nil, err
+	}
+
+	if name != nil {
+		newName := strings.TrimSpace(strings.ToLower(*name))
+		if newName != role.Name {
+			if role.IsSystem {
+				return nil, ErrSystemRole
+			}
+			if newName == "" {
+				return nil, ErrRoleNameRequired
+			}
+			if s.RoleExists(newName) {
+				return nil, ErrRoleExists
+			}
+			role.Name = newName
+		}
+	}
+	if description != nil {
+		role.Description = *description
+	}
+
+	perms := role.PermissionNames()
+	if permissions != nil {
+		if perms, err = normalizePermissions(permissions); err != nil {
+			return nil, err
+		}
+		if role.IsSystem && !samePermissions(perms, role.PermissionNames()) {
+			return nil, ErrSystemRole
+		}
+	}
+
+	if err := s.repo.Update(role, perms); err != nil {
+		return nil, err
+	}
+	return role, nil
+}
+
+func (s *service) Delete(id int) error {
+	role, err := s.GetByID(id)
+	if err != nil {
+		return err
+	}
+	if role.IsSystem {
+		return ErrSystemRole
+	}
+	return s.repo.Delete(id)
+}
+
+func (s *service) AssignToUser(roleID, userID int) error {
+	if _, err := s.GetByID(roleID); err != nil {
+		return err
+	}
+	if _, err := s.userRepo.FindByID(userID); err != nil {
+		return ErrUserNotFound
+	}
+	return s.repo.AssignToUser(userID, roleID)
+}
+
+func (s *service) RemoveFromUser(roleID, userID int) error {
+	return s.repo.RemoveFromUser(userID, roleID)
+}
+
+func (s *service) GetUsers(roleID int) ([]int, error) {
+	if _, err := s.GetByID(roleID); err != nil {
+		return nil, err
+	}
+	return s.repo.FindUserIDsByRole(roleID)
+}
+
+func (s *service) GetUserRoles(userID int) ([]models.Role, error) {
+	return s.repo.FindRolesByUser(userID)
+}
+
+func (s *service) RoleExists(name string) bool {
+	_, err := s.repo.FindByName(name)
+	return err == nil
+}
+
+func (s *service) RolePermissions(name string) ([]string, error) {
+	role, err := s.repo.FindByName(name)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrRoleNotFound
+		}
+		return nil, err
+	}
+	return role.PermissionNames(), nil
+}
+
+// PermissionsForUser resolves the effective permissions embedded in access tokens.
+func (s *service) PermissionsForUser(user *models.User) ([]string, error) {
+	return s.repo.PermissionsForUser(user.ID, user.Role)
+}
+
+// samePermissions reports whether a and b hold the same permissions in any order
+func samePermissions(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	set := make(map[string]bool, len(a))
+	for _, p := range a {
+		set[p] = true
+	}
+	for _, p := range b {
+		if !set[p] {
+			return false
+		}
+	}
+	return true
+}
+
+func normalizePermissions(permissions []string) ([]string, error) {
+	seen := make(map[string]bool, len(permissions))
+	result := make([]string, 0, len(permissions))
+	for _, p := range permissions {
+		p = strings.TrimSpace(p)
+		if !auth.IsValidPermission(p) {
+			return nil, fmt.Errorf("%w: %q", ErrInvalidPermission, p)
+		}
+		if !seen[p] {
+			seen[p] = true
+			result = append(result, p)
+		}
+	}
+	return result, nil
+}
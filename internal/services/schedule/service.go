@@ -0,0 +1,296 @@
+package schedule
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"time"
+	applog "web-porto-backend/common/logger"
+	"web-porto-backend/internal/domain/models"
+	scheduleRepo "web-porto-backend/internal/repositories/schedule"
+	"web-porto-backend/internal/services/audit"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// Scheduled actions; they match the audit actions they produce
+const (
+	ActionPublish   = audit.ActionPublish
+	ActionUnpublish = audit.ActionUnpublish
+)
+
+// maxAttempts is how often a failing job is tried before it is marked failed
+const maxAttempts = 3
+
+var (
+	ErrJobNotFound    = errors.New("scheduled job not found")
+	ErrJobNotPending  = errors.New("only pending jobs can be cancelled")
+	ErrUnknownAction  = errors.New("unknown scheduled action")
+	ErrUnknownContent = errors.New("content type cannot be scheduled")
+)
+
+// JobFilter narrows List results
+type JobFilter = scheduleRepo.JobFilter
+
+// Target applies scheduled status changes to one content type
+type Target interface {
+	// PublishScheduled publishes the item with at as its publish date
+	PublishScheduled(id string, at time.Time) error
+	// UnpublishScheduled takes the item offline
+	UnpublishScheduled(id string) error
+}
+
+// Config tunes the worker loop
+type Config struct {
+	Interval    time.Duration
+	BatchSize   int
+	LockTimeout time.Duration
+}
+
+type Service interface {
+	// RegisterTarget makes entityType (an audit entity such as
+	// audit.EntityArticle) schedulable
+	RegisterTarget(entityType string, target Target)
+	// Schedule replaces any pending job for the same item and action
+	Schedule(entityType, entityID, action string, runAt time.Time, userID int) (*models.ScheduledJob, error)
+	// Cancel drops pending jobs of an item; an empty action cancels all
+	Cancel(entityType, entityID, action string) error
+	CancelByID(id int) error
+	GetByID(id int) (*models.ScheduledJob, error)
+	List(filter JobFilter, page, limit int) ([]models.ScheduledJob, int64, error)
+	// RunDue claims and executes due jobs, returning how many ran
+	RunDue() (int, error)
+	// Start runs the worker until ctx is cancelled
+	Start(ctx context.Context)
+}
+
+type service struct {
+	repo         scheduleRepo.Repository
+	auditService audit.Service
+	targets      map[string]Target
+	cfg          Config
+	workerID     string
+}
+
+func NewService(repo scheduleRepo.Repository, auditService audit.Service, cfg Config) Service {
+	if cfg.Interval <= 0 {
+		cfg.Interval = 30 * time.Second
+	}
+	if cfg.BatchSize <= 0 {
+		cfg.BatchSize = 20
+	}
+	if cfg.LockTimeout <= 0 {
+		cfg.LockTimeout = 5 * time.Minute
+	}
+	host, _ := os.Hostname()
+	return &service{
+		repo:         repo,
+		auditService: auditService,
+		targets:      make(map[string]Target),
+		cfg:          cfg,
+		workerID:     fmt.Sprintf("%s-%d-%s", host, os.Getpid(), uuid.New().String()[:8]),
+	}
+}
+
+func (s *service) RegisterTarget(entityType string, target Target) {
+	s.targets[entityType] = target
+}
+
+func (s *service) Schedule(entityType, entityID, action string, runAt time.Time, userID int) (*models.ScheduledJob, error) {
+	if action != ActionPublish && action != ActionUnpublish {
+		return nil, ErrUnknownAction
+	}
+	if _, ok := s.targets[entityType]; !ok {
+		return nil, ErrUnknownContent
+	}
+	if err := s.repo.CancelPending(entityType, entityID, action); err != nil {
+		return nil, err
+	}
+
+	job := &models.ScheduledJob{
+		EntityType: entityType,
+		EntityID:   entityID,
+		Action:     action,
+		RunAt:      runAt,
+		Status:     models.JobStatusPending,
+	}
+	if userID > 0 {
+		job.CreatedBy = &userID
+	}
+	if err := s.repo.Create(job); err != nil {
+		return nil, err
+	}
+	return job, nil
+}
+
+func (s *service) Cancel(entityType, entityID, action string) error {
+	return s.repo.CancelPending(entityType, entityID, action)
+}
+
+func (s *service) CancelByID(id int) error {
+	job, err := s.GetByID(id)
+	if err != nil {
+		return err
+	}
+	if job.Status != models.JobStatusPending {
+		return ErrJobNotPending
+	}
+	cancelled, err := s.repo.CancelJob(job.ID)
+	if err != nil {
+		return err
+	}
+	if !cancelled {
+		return ErrJobNotPending
+	}
+	return nil
+}
+
+func (s *service) GetByID(id int) (*models.ScheduledJob, error) {
+	job, err := s.repo.FindByID(id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrJobNotFound
+		}
+		return nil, err
+	}
+	return job, nil
+}
+
+func (s *service) List(filter JobFilter, page, limit int) ([]models.ScheduledJob, int64, error) {
+	if page < 1 {
+		page = 1
+	}
+	if limit < 1 {
+		limit = 20
+	}
+	return s.repo.FindAll(filter, (page-1)*limit, limit)
+}
+
+func (s *service) RunDue() (int, error) {
+	log := applog.GetLogger().WithFields(applog.Fields{"service": "schedule", "worker": s.workerID})
+
+	// Jobs of a worker that died mid-run become claimable again
+	if n, err := s.repo.ReleaseStale(time.Now().Add(-s.cfg.LockTimeout)); err != nil {
+		log.Error("failed releasing stale jobs", applog.Fields{"error": err.Error()})
+	} else if n > 0 {
+		log.Warn("released stale scheduled jobs", applog.Fields{"count": n})
+	}
+
+	jobs, err := s.repo.ClaimDue(s.workerID, time.Now(), s.cfg.BatchSize)
+	if err != nil {
+		return 0, err
+	}
+
+	for i := range jobs {
+		job := &jobs[i]
+		now := time.Now()
+		if err := s.execute(job); err != nil {
+			job.LastError = err.Error()
+			if job.Attempts >= maxAttempts {
+				job.Status = models.JobStatusFailed
+			} else {
+				// Retry later with a growing delay
+				job.Status = models.JobStatusPending
+				job.RunAt = now.Add(time.Duration(job.Attempts) * time.Minute)
+			}
+			log.Error("scheduled job failed", applog.Fields{"job_id": job.ID, "attempts": job.Attempts, "error": err.Error()})
+		} else {
+			job.Status = models.JobStatusDone
+			job.LastError = ""
+			job.ExecutedAt = &now
+			s.auditService.Record(audit.Actor{UserAgent: "scheduler"}, job.Action, job.EntityType, job.EntityID,
+				nil, map[string]interface{}{"scheduledJobId": job.ID, "runAt": job.RunAt})
+		}
+		finished, err := s.repo.Finish(s.workerID, job)
+		if err != nil {
+			log.Error("failed saving scheduled job result", applog.Fields{"job_id": job.ID, "error": err.Error()})
+		} else if !finished {
+			// The job outlived the lock timeout and was released; whoever
+			// claimed it since owns its status
+			log.Warn("scheduled job was released before it finished", applog.Fields{"job_id": job.ID})
+		}
+	}
+	return len(jobs), nil
+}
+
+func (s *service) execute(job *models.ScheduledJob) error {
+	target, ok := s.targets[job.EntityType]
+	if !ok {
+		return ErrUnknownContent
+	}
+	switch job.Action {
+	case ActionPublish:
+		return target.PublishScheduled(job.EntityID, job.RunAt)
+	case ActionUnpublish:
+		return target.UnpublishScheduled(job.EntityID)
+	default:
+		return ErrUnknownAction
+	}
+}
+
+// ResolveStatus applies a publish date to a content status: "published"
+// with a date in the future becomes "scheduled", and "scheduled" whose date
+// has passed (or is missing) becomes "published"
+func ResolveStatus(status string, publishedAt *time.Time) (string, *time.Time) {
+	future := publishedAt != nil && publishedAt.After(time.Now())
+	switch {
+	case status == "published" && future:
+		return "scheduled", publishedAt
+	case status == "scheduled" && !future:
+		if publishedAt == nil {
+			now := time.Now()
+			publishedAt = &now
+		}
+		return "published", publishedAt
+	}
+	return status, publishedAt
+}
+
+// Sync keeps the pending jobs of an item in line with its status: a
+// "scheduled" item gets a publish job at publishedAt, anything else has its
+// pending publish cancelled. unpublishAt, when given, (re)schedules taking
+// the item offline. Failures are logged; content saves never fail on them.
+func Sync(s Service, entityType, entityID, status string, publishedAt, unpublishAt *time.Time, userID int) {
+	if s == nil {
+		return
+	}
+	log := applog.GetLogger().WithFields(applog.Fields{"service": "schedule", "entity_type": entityType, "entity_id": entityID})
+
+	var err error
+	if status == "scheduled" && publishedAt != nil {
+		_, err = s.Schedule(entityType, entityID, ActionPublish, *publishedAt, userID)
+	} else {
+		err = s.Cancel(entityType, entityID, ActionPublish)
+	}
+	if err != nil {
+		log.Error("failed scheduling publish", applog.Fields{"error": err.Error()})
+	}
+
+	if unpublishAt != nil && !unpublishAt.IsZero() {
+		if _, err := s.Schedule(entityType, entityID, ActionUnpublish, *unpublishAt, userID); err != nil {
+			log.Error("failed scheduling unpublish", applog.Fields{"error": err.Error()})
+		}
+	}
+}
+
+func (s *service) Start(ctx context.Context) {
+	log := applog.GetLogger().WithFields(applog.Fields{"service": "schedule", "worker": s.workerID})
+	ticker := time.NewTicker(s.cfg.Interval)
+	defer ticker.Stop()
+
+	for {
+		if n, err := s.RunDue(); err != nil {
+			log.Error("failed running scheduled jobs", applog.Fields{"error": err.Error()})
+		} else if n > 0 {
+			log.Info("ran scheduled jobs", applog.Fields{"count": n})
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
@@ -0,0 +1,266 @@
+package schedule
+
+import (
+	"errors"
+	"sync"
+	"testing"
+	"time"
+	"web-porto-backend/internal/domain/models"
+	scheduleRepo "web-porto-backend/internal/repositories/schedule"
+	"web-porto-backend/internal/services/audit"
+
+	"gorm.io/gorm"
+)
+
+// fakeJobs keeps jobs in memory with the locking rules of the repository
+type fakeJobs struct {
+	scheduleRepo.Repository
+	mu   sync.Mutex
+	jobs map[int]*models.ScheduledJob
+}
+
+func newFakeJobs(jobs ...models.ScheduledJob) *fakeJobs {
+	r := &fakeJobs{jobs: map[int]*models.ScheduledJob{}}
+	for i := range jobs {
+		job := jobs[i]
+		if job.Status == "" {
+			job.Status = models.JobStatusPending
+		}
+		r.jobs[job.ID] = &job
+	}
+	return r
+}
+
+func (r *fakeJobs) job(id int) models.ScheduledJob {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return *r.jobs[id]
+}
+
+func (r *fakeJobs) FindByID(id int) (*models.ScheduledJob, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	job, ok := r.jobs[id]
+	if !ok {
+		return nil, gorm.ErrRecordNotFound
+	}
+	copied := *job
+	return &copied, nil
+}
+
+func (r *fakeJobs) CancelJob(id int) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	job, ok := r.jobs[id]
+	if !ok || job.Status != models.JobStatusPending {
+		return false, nil
+	}
+	job.Status = models.JobStatusCancelled
+	return true, nil
+}
+
+func (r *fakeJobs) ClaimDue(workerID string, now time.Time, limit int) ([]models.ScheduledJob, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var claimed []models.ScheduledJob
+	for id := 1; id <= len(r.jobs) && len(claimed) < limit; id++ {
+		job := r.jobs[id]
+		if job.Status != models.JobStatusPending || job.RunAt.After(now) {
+			continue
+		}
+		job.Status, job.LockedBy, job.LockedAt = models.JobStatusRunning, workerID, &now
+		job.Attempts++
+		claimed = append(claimed, *job)
+	}
+	return claimed, nil
+}
+
+func (r *fakeJobs) ReleaseStale(before time.Time) (int64, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var n int64
+	for _, job := range r.jobs {
+		if job.Status == models.JobStatusRunning && job.LockedAt.Before(before) {
+			job.Status, job.LockedBy, job.LockedAt = models.JobStatusPending, "", nil
+			n++
+		}
+	}
+	return n, nil
+}
+
+func (r *fakeJobs) Finish(workerID string, result *models.ScheduledJob) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	job := r.jobs[result.ID]
+	if job.Status != models.JobStatusRunning || job.LockedBy != workerID {
+		return false, nil
+	}
+	job.Status, job.LastError, job.ExecutedAt, job.RunAt = result.Status, result.LastError, result.ExecutedAt, result.RunAt
+	job.LockedBy, job.LockedAt = "", nil
+	return true, nil
+}
+
+type fakeAudit struct {
+	audit.Service
+}
+
+func (fakeAudit) Record(audit.Actor, string, string, string, interface{}, interface{}) {}
+
+// fakeTarget publishes through publish, which may be nil
+type fakeTarget struct {
+	publish   func(id string) error
+	published []string
+}
+
+func (t *fakeTarget) PublishScheduled(id string, _ time.Time) error {
+	t.published = append(t.published, id)
+	if t.publish != nil {
+		return t.publish(id)
+	}
+	return nil
+}
+
+func (t *fakeTarget) UnpublishScheduled(string) error {
+	return nil
+}
+
+func newTestService(repo *fakeJobs, target *fakeTarget) *service {
+	s := NewService(repo, fakeAudit{}, Config{BatchSize: 10, LockTimeout: time.Minute}).(*service)
+	s.RegisterTarget(audit.EntityArticle, target)
+	return s
+}
+
+func publishJob(id int, runAt time.Time) models.ScheduledJob {
+	return models.ScheduledJob{ID: id, EntityType: audit.EntityArticle, EntityID: "a", Action: ActionPublish, RunAt: runAt}
+}
+
+func TestRunDueRunsDueJobsOnce(t *testing.T) {
+	repo := newFakeJobs(publishJob(1, time.Now().Add(-time.Minute)), publishJob(2, time.Now().Add(time.Hour)))
+	target := &fakeTarget{}
+	s := newTestService(repo, target)
+
+	ran, err := s.RunDue()
+	if err != nil || ran != 1 {
+		t.Fatalf("RunDue = %d, %v; want 1 job", ran, err)
+	}
+	done := repo.job(1)
+	if done.Status != models.JobStatusDone || done.ExecutedAt == nil || done.LockedBy != "" || done.LockedAt != nil {
+		t.Fatalf("due job after running = %+v", done)
+	}
+	if repo.job(2).Status != models.JobStatusPending {
+		t.Fatalf("future job = %+v", repo.job(2))
+	}
+
+	if ran, _ := s.RunDue(); ran != 0 || len(target.published) != 1 {
+		t.Fatalf("second RunDue ran %d jobs; published %v", ran, target.published)
+	}
+}
+
+func TestRunDueRetriesFailingJobs(t *testing.T) {
+	repo := newFakeJobs(publishJob(1, time.Now().Add(-time.Minute)))
+	target := &fakeTarget{publish: func(string) error { return errors.New("database is down") }}
+	s := newTestService(repo, target)
+
+	for attempt := 1; attempt <= maxAttempts; attempt++ {
+		if _, err := s.RunDue(); err != nil {
+			t.Fatal(err)
+		}
+		job := repo.job(1)
+		if job.Attempts != attempt || job.LastError != "database is down" {
+			t.Fatalf("attempt %d: job = %+v", attempt, job)
+		}
+		if attempt < maxAttempts {
+			if job.Status != models.JobStatusPending || !job.RunAt.After(time.Now()) {
+				t.Fatalf("attempt %d: job not retried later: %+v", attempt, job)
+			}
+			// Make the retry due
+			repo.jobs[1].RunAt = time.Now().Add(-time.Second)
+		} else if job.Status != models.JobStatusFailed {
+			t.Fatalf("job after %d attempts = %+v, want failed", attempt, job)
+		}
+	}
+}
+
+func TestRunDueReleasesJobsOfDeadWorkers(t *testing.T) {
+	lockedAt := time.Now().Add(-time.Hour)
+	job := publishJob(1, time.Now().Add(-2*time.Hour))
+	job.Status, job.LockedBy, job.LockedAt, job.Attempts = models.JobStatusRunning, "dead-worker", &lockedAt, 1
+	repo := newFakeJobs(job)
+	s := newTestService(repo, &fakeTarget{})
+
+	if ran, err := s.RunDue(); err != nil || ran != 1 {
+		t.Fatalf("RunDue = %d, %v; want the released job to run", ran, err)
+	}
+	if got := repo.job(1); got.Status != models.JobStatusDone || got.Attempts != 2 {
+		t.Fatalf("job = %+v", got)
+	}
+}
+
+func TestReleasedJobIsNotFinishedByItsFormerWorker(t *testing.T) {
+	repo := newFakeJobs(publishJob(1, time.Now().Add(-time.Minute)))
+	target := &fakeTarget{}
+	s := newTestService(repo, target)
+	// The run outlives the lock timeout: the job is released and another
+	// worker claims it before the first one finishes
+	target.publish = func(string) error {
+		repo.ReleaseStale(time.Now().Add(time.Hour))
+		repo.ClaimDue("other-worker", time.Now(), 1)
+		return errors.New("timed out")
+	}
+
+	if _, err := s.RunDue(); err != nil {
+		t.Fatal(err)
+	}
+	job := repo.job(1)
+	if job.Status != models.JobStatusRunning || job.LockedBy != "other-worker" || job.LastError != "" {
+		t.Fatalf("job = %+v; the first worker overwrote the second worker's claim", job)
+	}
+}
+
+func TestCancelByID(t *testing.T) {
+	running := publishJob(2, time.Now())
+	running.Status = models.JobStatusRunning
+	repo := newFakeJobs(publishJob(1, time.Now().Add(time.Hour)), running)
+	s := newTestService(repo, &fakeTarget{})
+
+	if err := s.CancelByID(1); err != nil {
+		t.Fatalf("CancelByID(pending): %v", err)
+	}
+	if repo.job(1).Status != models.JobStatusCancelled {
+		t.Fatalf("job = %+v", repo.job(1))
+	}
+	if err := s.CancelByID(2); !errors.Is(err, ErrJobNotPending) {
+		t.Fatalf("CancelByID(running) = %v, want ErrJobNotPending", err)
+	}
+	if err := s.CancelByID(3); !errors.Is(err, ErrJobNotFound) {
+		t.Fatalf("CancelByID(missing) = %v, want ErrJobNotFound", err)
+	}
+}
+
+func TestResolveStatus(t *testing.T) {
+	future, past := time.Now().Add(time.Hour), time.Now().Add(-time.Hour)
+	tests := []struct {
+		name        string
+		status      string
+		publishedAt *time.Time
+		want        string
+	}{
+		{"published in the future", "published", &future, "scheduled"},
+		{"published in the past", "published", &past, "published"},
+		{"scheduled in the past", "scheduled", &past, "published"},
+		{"scheduled without date", "scheduled", nil, "published"},
+		{"scheduled in the future", "scheduled", &future, "scheduled"},
+		{"draft", "draft", &future, "draft"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, at := ResolveStatus(tt.status, tt.publishedAt)
+			if got != tt.want {
+				t.Fatalf("status = %q, want %q", got, tt.want)
+			}
+			if got == "published" && at == nil {
+				t.Fatal("published without a date")
+			}
+		})
+	}
+}
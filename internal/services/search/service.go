@@ -0,0 +1,87 @@
+package search
+
+import (
+	"errors"
+	"strings"
+	"unicode/utf8"
+	"web-porto-backend/internal/domain/dto"
+	searchRepo "web-porto-backend/internal/repositories/search"
+)
+
+// maxQueryLength bounds the search text to keep tsquery parsing cheap
+const maxQueryLength = 200
+
+var (
+	ErrEmptyQuery   = errors.New("search query is required")
+	ErrQueryTooLong = errors.New("search query is too long")
+	ErrInvalidType  = errors.New("type must be one of article, project, experience, page")
+)
+
+// Filter narrows a search
+type Filter = searchRepo.Filter
+
+type Service interface {
+	// Search returns published content matching filter.Query, best match first
+	Search(filter Filter, page, limit int) ([]dto.SearchResultResponse, int64, error)
+}
+
+type service struct {
+	repo searchRepo.Repository
+}
+
+func NewService(repo searchRepo.Repository) Service {
+	return &service{repo: repo}
+}
+
+func (s *service) Search(filter Filter, page, limit int) ([]dto.SearchResultResponse, int64, error) {
+	filter.Query = strings.TrimSpace(filter.Query)
+	if filter.Query == "" {
+		return nil, 0, ErrEmptyQuery
+	}
+	if utf8.RuneCountInString(filter.Query) > maxQueryLength {
+		return nil, 0, ErrQueryTooLong
+	}
+
+	if len(filter.Types) == 0 {
+		filter.Types = searchRepo.Types
+	}
+	for _, typ := range filter.Types {
+		if !isSearchable(typ) {
+			return nil, 0, ErrInvalidType
+		}
+	}
+
+	if page < 1 {
+		page = 1
+	}
+	if limit < 1 {
+		limit = 10
+	}
+	hits, total, err := s.repo.Search(filter, limit, (page-1)*limit)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	results := make([]dto.SearchResultResponse, 0, len(hits))
+	for _, hit := range hits {
+		results = append(results, dto.SearchResultResponse{
+			Type:    hit.Type,
+			ID:      hit.ID,
+			Title:   hit.Title,
+			Slug:    hit.Slug,
+			Snippet: hit.Snippet,
+			Rank:    hit.Rank,
+			Date:    hit.Date,
+		})
+	}
+	return results, total, nil
+}
+
+func isSearchable(typ string) bool {
+	for _, t := range searchRepo.Types {
+		if t == typ {
+			return true
+		}
+	}
+	return false
+}
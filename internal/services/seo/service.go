@@ -0,0 +1,254 @@
+package seo
+
+import (
+	"errors"
+	"html"
+	"strings"
+	"time"
+	"web-porto-backend/common/helper"
+	"web-porto-backend/common/utils"
+	"web-porto-backend/internal/domain/dto"
+	"web-porto-backend/internal/domain/models"
+	articleRepo "web-porto-backend/internal/repositories/article"
+	pageRepo "web-porto-backend/internal/repositories/page"
+	projectRepo "web-porto-backend/internal/repositories/project"
+	settingSrvc "web-porto-backend/internal/services/setting"
+)
+
+// Content types with head metadata
+const (
+	TypeArticle = "article"
+	TypeProject = "project"
+	TypePage    = "page"
+)
+
+var (
+	ErrUnknownType = errors.New("unknown content type")
+	// ErrNotFound also covers content visitors may not see yet
+	ErrNotFound = errors.New("content not found")
+)
+
+// Config holds head metadata defaults
+type Config struct {
+	// BaseURL makes relative image URLs absolute and stands in for the site
+	// URL until the site_url setting is set
+	BaseURL string
+	// Title is used until the site_title setting is set
+	Title string
+}
+
+type Service interface {
+	// Head computes the head tags of published content by type and slug
+	Head(contentType, slug string) (*dto.SEOHeadResponse, error)
+}
+
+type service struct {
+	articleRepo articleRepo.Repository
+	projectRepo projectRepo.Repository
+	pageRepo    pageRepo.Repository
+	settings    settingSrvc.Service
+	cfg         Config
+	helper      *helper.Helper
+}
+
+func NewService(articleRepo articleRepo.Repository, projectRepo projectRepo.Repository, pageRepo pageRepo.Repository, settings settingSrvc.Service, cfg Config) Service {
+	cfg.BaseURL = strings.TrimRight(cfg.BaseURL, "/")
+	return &service{
+		articleRepo: articleRepo,
+		projectRepo: projectRepo,
+		pageRepo:    pageRepo,
+		settings:    settings,
+		cfg:         cfg,
+		helper:      helper.NewHelper(),
+	}
+}
+
+// source is what head tags are computed from, whatever the content type
+type source struct {
+	seo         models.SEO
+	ogType      string
+	title       string
+	description string
+	url         string
+	image       string
+	published   *time.Time
+	modified    time.Time
+	tags        []string
+}
+
+func (s *service) Head(contentType, slug string) (*dto.SEOHeadResponse, error) {
+	site, err := s.settings.GetSite()
+	if err != nil {
+		return nil, err
+	}
+	if site.URL == "" {
+		site.URL = s.cfg.BaseURL
+	}
+	if site.Title == "" {
+		site.Title = s.cfg.Title
+	}
+
+	var src source
+	switch contentType {
+	case TypeArticle:
+		article, err := s.articleRepo.GetBySlug(slug)
+		if err != nil || !models.IsPubliclyVisible(article.Status, article.PublishedAt) {
+			return nil, ErrNotFound
+		}
+		src = source{
+			seo:         article.SEO,
+			ogType:      "article",
+			title:       article.Title,
+			description: article.Excerpt,
+			url:         site.ArticleURL(article.Slug),
+			image:       article.FeaturedImageURL,
+			published:   article.PublishedAt,
+			modified:    article.UpdatedAt,
+		}
+		if src.description == "" {
+			src.description = article.Content
+		}
+		if src.image == "" && len(article.Images) > 0 {
+			src.image = article.Images[0].URL
+		}
+		for _, tag := range article.Tags {
+			src.tags = append(src.tags, tag.Name)
+		}
+	case TypeProject:
+		project, err := s.projectRepo.GetBySlug(slug)
+		if err != nil || !models.IsPubliclyVisible(project.Status, project.PublishedAt) {
+			return nil, ErrNotFound
+		}
+		src = source{
+			seo:         project.SEO,
+			ogType:      "website",
+			title:       project.Title,
+			description: project.Description,
+			url:         site.ProjectURL(project.Slug),
+			image:       project.ThumbnailURL,
+		}
+		if src.description == "" {
+			src.description = project.Content
+		}
+		if src.image == "" && len(project.Images) > 0 {
+			src.image = project.Images[0].URL
+		}
+	case TypePage:
+		page, err := s.pageRepo.GetBySlug(slug)
+		if err != nil || !models.IsPubliclyVisible(page.Status, page.PublishedAt) {
+			return nil, ErrNotFound
+		}
+		src = source{
+			seo:         page.SEO,
+			ogType:      "website",
+			title:       page.Title,
+			description: page.Content,
+			url:         site.PageURL(page.Slug),
+		}
+	default:
+		return nil, ErrUnknownType
+	}
+	return s.build(site, src), nil
+}
+
+// build applies the fallbacks: SEO block first, then the content itself
+func (s *service) build(site settingSrvc.Site, src source) *dto.SEOHeadResponse {
+	head := &dto.SEOHeadResponse{
+		Title:        src.seo.MetaTitle,
+		Description:  src.seo.MetaDescription,
+		CanonicalURL: src.seo.CanonicalURL,
+		Image:        utils.AbsoluteURL(s.cfg.BaseURL, src.seo.OGImage),
+		TwitterCard:  src.seo.TwitterCard,
+		Robots:       "index, follow",
+	}
+	ogTitle := head.Title
+	if head.Title == "" {
+		ogTitle = src.title
+		head.Title = src.title
+		if site.Title != "" {
+			head.Title += " | " + site.Title
+		}
+	}
+	if head.Description == "" {
+		// Excerpts and bodies may hold HTML; descriptions are plain text
+		head.Description = s.helper.ExtractExcerpt(src.description, dto.MaxMetaDescriptionLength-3)
+	}
+	if head.CanonicalURL == "" {
+		head.CanonicalURL = src.url
+	}
+	if head.Image == "" {
+		head.Image = utils.AbsoluteURL(s.cfg.BaseURL, src.image)
+	}
+	if head.TwitterCard == "" {
+		head.TwitterCard = dto.TwitterCardSummary
+		if head.Image != "" {
+			head.TwitterCard = dto.TwitterCardSummaryLarge
+		}
+	}
+	if src.seo.NoIndex {
+		head.Robots = "noindex, nofollow"
+	}
+
+	meta := func(name, content string) {
+		if content != "" {
+			head.Tags = append(head.Tags, dto.HeadTag{Tag: "meta", Name: name, Content: content})
+		}
+	}
+	property := func(name, content string) {
+		if content != "" {
+			head.Tags = append(head.Tags, dto.HeadTag{Tag: "meta", Property: name, Content: content})
+		}
+	}
+
+	head.Tags = append(head.Tags, dto.HeadTag{Tag: "title", Content: head.Title})
+	meta("description", head.Description)
+	meta("robots", head.Robots)
+	head.Tags = append(head.Tags, dto.HeadTag{Tag: "link", Rel: "canonical", Href: head.CanonicalURL})
+
+	property("og:type", src.ogType)
+	property("og:title", ogTitle)
+	property("og:description", head.Description)
+	property("og:url", head.CanonicalURL)
+	property("og:site_name", site.Title)
+	property("og:image", head.Image)
+	if src.ogType == "article" {
+		if src.published != nil {
+			property("article:published_time", src.published.UTC().Format(time.RFC3339))
+		}
+		if !src.modified.IsZero() {
+			property("article:modified_time", src.modified.UTC().Format(time.RFC3339))
+		}
+		for _, tag := range src.tags {
+			property("article:tag", tag)
+		}
+	}
+
+	meta("twitter:card", head.TwitterCard)
+	meta("twitter:title", ogTitle)
+	meta("twitter:description", head.Description)
+	meta("twitter:image", head.Image)
+
+	head.HTML = renderHTML(head.Tags)
+	return head
+}
+
+// renderHTML writes tags as escaped markup, one element per line
+func renderHTML(tags []dto.HeadTag) string {
+	var b strings.Builder
+	for _, tag := range tags {
+		switch tag.Tag {
+		case "title":
+			b.WriteString("<title>" + html.EscapeString(tag.Content) + "</title>")
+		case "link":
+			b.WriteString(`<link rel="` + html.EscapeString(tag.Rel) + `" href="` + html.EscapeString(tag.Href) + `">`)
+		default:
+			attr, key := "name", tag.Name
+			if tag.Property != "" {
+				attr, key = "property", tag.Property
+			}
+			b.WriteString(`<meta ` + attr + `="` + html.EscapeString(key) + `" content="` + html.EscapeString(tag.Content) + `">`)
+		}
+		b.WriteString("\n")
+	}
+	return b.String()
+}
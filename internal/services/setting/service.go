@@ -1,15 +1,57 @@
 package setting
 
 import (
+	"strings"
 	"web-porto-backend/internal/domain/models"
 	"web-porto-backend/internal/repositories/setting"
 )
 
+// Settings describing the public site, which feeds and sitemaps link to.
+// Paths are where the frontend serves each content type, e.g. "/blog".
+const (
+	KeySiteURL         = "site_url"
+	KeySiteTitle       = "site_title"
+	KeySiteDescription = "site_description"
+	KeyArticlePath     = "site_article_path"
+	KeyProjectPath     = "site_project_path"
+	KeyPagePath        = "site_page_path"
+	// KeyRobotsTxt replaces the default rules of /robots.txt
+	KeyRobotsTxt = "robots_txt"
+)
+
+// Site describes the public site; see the Key* settings
+type Site struct {
+	URL         string
+	Title       string
+	Description string
+	ArticlePath string
+	ProjectPath string
+	PagePath    string
+}
+
+// ArticleURL is the public address of an article
+func (s Site) ArticleURL(slug string) string {
+	return s.URL + s.ArticlePath + "/" + slug
+}
+
+// ProjectURL is the public address of a project
+func (s Site) ProjectURL(slug string) string {
+	return s.URL + s.ProjectPath + "/" + slug
+}
+
+// PageURL is the public address of a page
+func (s Site) PageURL(slug string) string {
+	return s.URL + s.PagePath + "/" + slug
+}
+
 type Service interface {
 	GetSetting(key string) (string, error)
 	GetSettings(keys []string) (map[string]string, error)
 	GetAllSettings() (map[string]string, error)
 	SaveSettings(updates map[string]string) error
+	// GetSite reads the public site settings. Unset paths default to
+	// /articles, /projects and the site root; an unset URL is left empty.
+	GetSite() (Site, error)
 }
 
 type service struct {
@@ -61,3 +103,33 @@ func (s *service) SaveSettings(updates map[string]string) error {
 	}
 	return s.repo.SaveMany(settings)
 }
+
+func (s *service) GetSite() (Site, error) {
+	values, err := s.GetSettings([]string{KeySiteURL, KeySiteTitle, KeySiteDescription, KeyArticlePath, KeyProjectPath, KeyPagePath})
+	if err != nil {
+		return Site{}, err
+	}
+	site := Site{
+		URL:         strings.TrimRight(strings.TrimSpace(values[KeySiteURL]), "/"),
+		Title:       strings.TrimSpace(values[KeySiteTitle]),
+		Description: strings.TrimSpace(values[KeySiteDescription]),
+		ArticlePath: "/articles",
+		ProjectPath: "/projects",
+	}
+	for key, target := range map[string]*string{KeyArticlePath: &site.ArticlePath, KeyProjectPath: &site.ProjectPath, KeyPagePath: &site.PagePath} {
+		if v, ok := values[key]; ok {
+			*target = sitePath(v)
+		}
+	}
+	return site, nil
+}
+
+// sitePath normalises a path setting to "/path" without a trailing slash;
+// "" or "/" mean the site root
+func sitePath(v string) string {
+	v = strings.Trim(strings.TrimSpace(v), "/")
+	if v == "" {
+		return ""
+	}
+	return "/" + v
+}
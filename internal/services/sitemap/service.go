@@ -0,0 +1,136 @@
+package sitemap
+
+import (
+	"strings"
+	"time"
+	"web-porto-backend/common/utils"
+	sitemapRepo "web-porto-backend/internal/repositories/sitemap"
+	settingSrvc "web-porto-backend/internal/services/setting"
+)
+
+const (
+	// MaxURLs is the most URLs one sitemap file may list (sitemaps.org);
+	// larger sites are split into files listed by a sitemap index
+	MaxURLs = 50000
+	// maxImages is the most images one URL may list
+	maxImages = 1000
+)
+
+// DefaultRobots is served when the robots_txt setting is empty
+const DefaultRobots = "User-agent: *\nDisallow: /api/\nAllow: /\n"
+
+// URL is a sitemap entry
+type URL struct {
+	Loc     string
+	LastMod time.Time
+	Images  []Image
+}
+
+// Image is an image sitemap extension entry
+type Image struct {
+	Loc     string
+	Title   string
+	Caption string
+}
+
+// Config holds sitemap defaults
+type Config struct {
+	// BaseURL is where the API serves the sitemap; it makes relative image
+	// URLs absolute and stands in for the site URL until site_url is set
+	BaseURL string
+}
+
+type Service interface {
+	// Files returns the number of sitemap files; above one an index is needed
+	Files() (int, error)
+	// URLs returns the entries of sitemap file n, counting from 1
+	URLs(n int) ([]URL, error)
+	// LastModified returns the newest change to any listed content
+	LastModified() (time.Time, error)
+	// Robots returns the robots.txt body, ending with the sitemap location
+	Robots() (string, error)
+}
+
+type service struct {
+	repo     sitemapRepo.Repository
+	settings settingSrvc.Service
+	cfg      Config
+}
+
+func NewService(repo sitemapRepo.Repository, settings settingSrvc.Service, cfg Config) Service {
+	cfg.BaseURL = strings.TrimRight(cfg.BaseURL, "/")
+	return &service{
+		repo:     repo,
+		settings: settings,
+		cfg:      cfg,
+	}
+}
+
+func (s *service) Files() (int, error) {
+	total, err := s.repo.Count()
+	if err != nil {
+		return 0, err
+	}
+	if total == 0 {
+		return 1, nil
+	}
+	return int((total + MaxURLs - 1) / MaxURLs), nil
+}
+
+func (s *service) URLs(n int) ([]URL, error) {
+	site, err := s.settings.GetSite()
+	if err != nil {
+		return nil, err
+	}
+	if site.URL == "" {
+		site.URL = s.cfg.BaseURL
+	}
+
+	entries, err := s.repo.List(MaxURLs, (n-1)*MaxURLs)
+	if err != nil {
+		return nil, err
+	}
+	urls := make([]URL, 0, len(entries))
+	for _, entry := range entries {
+		u := URL{LastMod: entry.UpdatedAt}
+		switch entry.Type {
+		case sitemapRepo.TypeArticle:
+			u.Loc = site.ArticleURL(entry.Slug)
+		case sitemapRepo.TypeProject:
+			u.Loc = site.ProjectURL(entry.Slug)
+		default:
+			u.Loc = site.PageURL(entry.Slug)
+		}
+		for i, img := range entry.Images {
+			if i == maxImages {
+				break
+			}
+			u.Images = append(u.Images, Image{
+				Loc:     utils.AbsoluteURL(s.cfg.BaseURL, img.URL),
+				Title:   img.Title,
+				Caption: img.Caption,
+			})
+		}
+		urls = append(urls, u)
+	}
+	return urls, nil
+}
+
+func (s *service) LastModified() (time.Time, error) {
+	return s.repo.LastModified()
+}
+
+func (s *service) Robots() (string, error) {
+	values, err := s.settings.GetSettings([]string{settingSrvc.KeyRobotsTxt})
+	if err != nil {
+		return "", err
+	}
+	body := strings.TrimSpace(strings.ReplaceAll(values[settingSrvc.KeyRobotsTxt], "\r\n", "\n"))
+	if body == "" {
+		body = strings.TrimSpace(DefaultRobots)
+	}
+	if !strings.Contains(strings.ToLower(body), "sitemap:") {
+		body += "\n\nSitemap: " + s.cfg.BaseURL + "/sitemap.xml"
+	}
+	return body + "\n", nil
+}
@@ -0,0 +1,161 @@
+package token
+
+import (
+	"errors"
+	"time"
+	applog "web-porto-backend/common/logger"
+	"web-porto-backend/common/utils"
+	"web-porto-backend/internal/domain/models"
+	tokenRepo "web-porto-backend/internal/repositories/token"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+var (
+	ErrInvalidRefreshToken = errors.New("invalid refresh token")
+	ErrRefreshTokenExpired = errors.New("refresh token expired")
+	ErrRefreshTokenReused  = errors.New("refresh token reuse detected")
+)
+
+type Service interface {
+	IssueRefreshToken(userID int, userAgent, ip string) (string, *models.RefreshToken, error)
+	RotateRefreshToken(raw, userAgent, ip string) (string, *models.RefreshToken, error)
+	RevokeRefreshToken(raw string) error
+	// RevokeAllForUser ends every session of a user: refresh tokens are
+	// revoked and access tokens issued until now are rejected
+	RevokeAllForUser(userID int) error
+	RevokeAccessToken(jti string, userID int, expiresAt time.Time) error
+	IsTokenRevoked(jti string, userID int, issuedAt time.Time) bool
+	PurgeExpired() (int64, error)
+}
+
+type service struct {
+	repo       tokenRepo.Repository
+	refreshTTL time.Duration
+}
+
+func NewService(repo tokenRepo.Repository, refreshTTL time.Duration) Service {
+	if refreshTTL <= 0 {
+		refreshTTL = 30 * 24 * time.Hour
+	}
+	return &service{repo: repo, refreshTTL: refreshTTL}
+}
+
+// IssueRefreshToken starts a new token family for a fresh login.
+func (s *service) IssueRefreshToken(userID int, userAgent, ip string) (string, *models.RefreshToken, error) {
+	raw, hash, err := utils.GenerateSecureToken()
+	if err != nil {
+		return "", nil, err
+	}
+
+	token := &models.RefreshToken{
+		UserID:    userID,
+		FamilyID:  uuid.New().String(),
+		TokenHash: hash,
+		ExpiresAt: time.Now().Add(s.refreshTTL),
+		UserAgent: userAgent,
+		IP:        ip,
+	}
+	if err := s.repo.Create(token); err != nil {
+		return "", nil, err
+	}
+	return raw, token, nil
+}
+
+// RotateRefreshToken exchanges a valid refresh token for a new one in the same
+// family. Presenting a token that was already rotated or revoked is treated as
+// theft and revokes the whole family.
+func (s *service) RotateRefreshToken(raw, userAgent, ip string) (string, *models.RefreshToken, error) {
+	current, err := s.repo.FindByHash(utils.HashToken(raw))
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return "", nil, ErrInvalidRefreshToken
+		}
+		return "", nil, err
+	}
+
+	if current.RevokedAt != nil {
+		s.revokeFamily(current)
+		return "", nil, ErrRefreshTokenReused
+	}
+	if time.Now().After(current.ExpiresAt) {
+		return "", nil, ErrRefreshTokenExpired
+	}
+
+	nextRaw, nextHash, err := utils.GenerateSecureToken()
+	if err != nil {
+		return "", nil, err
+	}
+	next := &models.RefreshToken{
+		UserID:    current.UserID,
+		FamilyID:  current.FamilyID,
+		TokenHash: nextHash,
+		ExpiresAt: time.Now().Add(s.refreshTTL),
+		UserAgent: userAgent,
+		IP:        ip,
+	}
+
+	if err := s.repo.Rotate(current, next); err != nil {
+		if errors.Is(err, tokenRepo.ErrAlreadyRevoked) {
+			// Lost a race against another rotation of the same token
+			s.revokeFamily(current)
+			return "", nil, ErrRefreshTokenReused
+		}
+		return "", nil, err
+	}
+	return nextRaw, next, nil
+}
+
+func (s *service) RevokeRefreshToken(raw string) error {
+	token, err := s.repo.FindByHash(utils.HashToken(raw))
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrInvalidRefreshToken
+		}
+		return err
+	}
+	return s.repo.RevokeFamily(token.FamilyID)
+}
+
+func (s *service) RevokeAllForUser(userID int) error {
+	if err := s.repo.RevokeAllForUser(userID); err != nil {
+		return err
+	}
+	return s.repo.RevokeAccessTokensBefore(userID, time.Now())
+}
+
+func (s *service) RevokeAccessToken(jti string, userID int, expiresAt time.Time) error {
+	if jti == "" {
+		return nil
+	}
+	return s.repo.RevokeAccessToken(&models.RevokedToken{
+		JTI:       jti,
+		UserID:    userID,
+		ExpiresAt: expiresAt,
+	})
+}
+
+// IsTokenRevoked implements auth.RevocationChecker. It fails closed: if the
+// revocation store cannot be queried the token is treated as revoked.
+func (s *service) IsTokenRevoked(jti string, userID int, issuedAt time.Time) bool {
+	if jti == "" && userID == 0 {
+		return false
+	}
+	revoked, err := s.repo.IsAccessTokenRevoked(jti, userID, issuedAt)
+	if err != nil {
+		applog.GetLogger().WithFields(applog.Fields{"service": "token"}).Error("revocation lookup failed", applog.Fields{"error": err.Error()})
+		return true
+	}
+	return revoked
+}
+
+func (s *service) PurgeExpired() (int64, error) {
+	return s.repo.DeleteExpired(time.Now())
+}
+
+func (s *service) revokeFamily(token *models.RefreshToken) {
+	if err := s.repo.RevokeFamily(token.FamilyID); err != nil {
+		applog.GetLogger().WithFields(applog.Fields{"service": "token"}).Error("failed revoking token family", applog.Fields{"family_id": token.FamilyID, "error": err.Error()})
+	}
+}
@@ -0,0 +1,188 @@
+package token
+
+import (
+	"errors"
+	"testing"
+	"time"
+	"web-porto-backend/internal/domain/models"
+	tokenRepo "web-porto-backend/internal/repositories/token"
+
+	"gorm.io/gorm"
+)
+
+// fakeTokens stores refresh tokens in memory with the repository's rules
+type fakeTokens struct {
+	tokenRepo.Repository
+	tokens []*models.RefreshToken
+	// beforeRotate runs between the lookup and the rotation of a token
+	beforeRotate func()
+	lookupErr    error
+}
+
+func (r *fakeTokens) Create(token *models.RefreshToken) error {
+	token.ID = len(r.tokens) + 1
+	copied := *token
+	r.tokens = append(r.tokens, &copied)
+	return nil
+}
+
+func (r *fakeTokens) FindByHash(hash string) (*models.RefreshToken, error) {
+	for _, token := range r.tokens {
+		if token.TokenHash == hash {
+			copied := *token
+			return &copied, nil
+		}
+	}
+	return nil, gorm.ErrRecordNotFound
+}
+
+func (r *fakeTokens) Rotate(old, next *models.RefreshToken) error {
+	if r.beforeRotate != nil {
+		r.beforeRotate()
+	}
+	stored := r.tokens[old.ID-1]
+	if stored.RevokedAt != nil {
+		return tokenRepo.ErrAlreadyRevoked
+	}
+	if err := r.Create(next); err != nil {
+		return err
+	}
+	now := time.Now()
+	stored.RevokedAt, stored.ReplacedBy = &now, &next.ID
+	return nil
+}
+
+func (r *fakeTokens) RevokeFamily(familyID string) error {
+	now := time.Now()
+	for _, token := range r.tokens {
+		if token.FamilyID == familyID && token.RevokedAt == nil {
+			token.RevokedAt = &now
+		}
+	}
+	return nil
+}
+
+func (r *fakeTokens) IsAccessTokenRevoked(string, int, time.Time) (bool, error) {
+	return false, r.lookupErr
+}
+
+// live counts the tokens of a family that are not revoked
+func (r *fakeTokens) live(familyID string) int {
+	n := 0
+	for _, token := range r.tokens {
+		if token.FamilyID == familyID && token.RevokedAt == nil {
+			n++
+		}
+	}
+	return n
+}
+
+func TestRotateRefreshToken(t *testing.T) {
+	repo := &fakeTokens{}
+	s := NewService(repo, time.Hour)
+
+	raw, first, err := s.IssueRefreshToken(7, "agent", "127.0.0.1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	nextRaw, next, err := s.RotateRefreshToken(raw, "agent", "127.0.0.2")
+	if err != nil {
+		t.Fatalf("RotateRefreshToken: %v", err)
+	}
+	if nextRaw == raw || next.TokenHash == first.TokenHash {
+		t.Fatal("rotation returned the same token")
+	}
+	if next.FamilyID != first.FamilyID || next.UserID != 7 || next.IP != "127.0.0.2" {
+		t.Fatalf("rotated token = %+v", next)
+	}
+	if old := repo.tokens[0]; old.RevokedAt == nil || old.ReplacedBy == nil || *old.ReplacedBy != next.ID {
+		t.Fatalf("rotated token was not revoked and linked: %+v", old)
+	}
+	if _, _, err := s.RotateRefreshToken(nextRaw, "agent", "127.0.0.2"); err != nil {
+		t.Fatalf("rotating the new token: %v", err)
+	}
+}
+
+func TestRotateRefreshTokenDetectsReuse(t *testing.T) {
+	repo := &fakeTokens{}
+	s := NewService(repo, time.Hour)
+	raw, first, _ := s.IssueRefreshToken(7, "", "")
+	nextRaw, _, _ := s.RotateRefreshToken(raw, "", "")
+
+	// The old token is presented again, e.g. by whoever stole it
+	if _, _, err := s.RotateRefreshToken(raw, "", ""); !errors.Is(err, ErrRefreshTokenReused) {
+		t.Fatalf("reusing a rotated token = %v, want ErrRefreshTokenReused", err)
+	}
+	if n := repo.live(first.FamilyID); n != 0 {
+		t.Fatalf("%d tokens of the family are still valid", n)
+	}
+	if _, _, err := s.RotateRefreshToken(nextRaw, "", ""); !errors.Is(err, ErrRefreshTokenReused) {
+		t.Fatalf("rotating the latest token after reuse = %v, want ErrRefreshTokenReused", err)
+	}
+
+	// Other logins are left alone
+	otherRaw, _, _ := s.IssueRefreshToken(7, "", "")
+	if _, _, err := s.RotateRefreshToken(otherRaw, "", ""); err != nil {
+		t.Fatalf("rotating another family: %v", err)
+	}
+}
+
+func TestRotateRefreshTokenLosingARaceRevokesTheFamily(t *testing.T) {
+	repo := &fakeTokens{}
+	s := NewService(repo, time.Hour)
+	raw, first, _ := s.IssueRefreshToken(7, "", "")
+	// Another request rotates the same token after this one looked it up
+	repo.beforeRotate = func() {
+		now := time.Now()
+		repo.tokens[0].RevokedAt = &now
+		repo.beforeRotate = nil
+		repo.Create(&models.RefreshToken{UserID: 7, FamilyID: first.FamilyID, TokenHash: "winner"})
+	}
+
+	if _, _, err := s.RotateRefreshToken(raw, "", ""); !errors.Is(err, ErrRefreshTokenReused) {
+		t.Fatalf("RotateRefreshToken = %v, want ErrRefreshTokenReused", err)
+	}
+	if n := repo.live(first.FamilyID); n != 0 {
+		t.Fatalf("%d tokens of the family are still valid", n)
+	}
+}
+
+func TestRotateRefreshTokenRejections(t *testing.T) {
+	repo := &fakeTokens{}
+	s := NewService(repo, time.Hour)
+	raw, _, _ := s.IssueRefreshToken(7, "", "")
+	repo.tokens[0].ExpiresAt = time.Now().Add(-time.Second)
+
+	if _, _, err := s.RotateRefreshToken(raw, "", ""); !errors.Is(err, ErrRefreshTokenExpired) {
+		t.Fatalf("expired token = %v, want ErrRefreshTokenExpired", err)
+	}
+	if _, _, err := s.RotateRefreshToken("not a token", "", ""); !errors.Is(err, ErrInvalidRefreshToken) {
+		t.Fatalf("unknown token = %v, want ErrInvalidRefreshToken", err)
+	}
+}
+
+func TestRevokeRefreshTokenEndsTheFamily(t *testing.T) {
+	repo := &fakeTokens{}
+	s := NewService(repo, time.Hour)
+	raw, first, _ := s.IssueRefreshToken(7, "", "")
+	nextRaw, _, _ := s.RotateRefreshToken(raw, "", "")
+
+	if err := s.RevokeRefreshToken(nextRaw); err != nil {
+		t.Fatalf("RevokeRefreshToken: %v", err)
+	}
+	if n := repo.live(first.FamilyID); n != 0 {
+		t.Fatalf("%d tokens of the family are still valid", n)
+	}
+}
+
+func TestIsTokenRevokedFailsClosed(t *testing.T) {
+	repo := &fakeTokens{}
+	s := NewService(repo, time.Hour)
+	if s.IsTokenRevoked("jti", 7, time.Now()) {
+		t.Fatal("token revoked without a record")
+	}
+	repo.lookupErr = errors.New("connection refused")
+	if !s.IsTokenRevoked("jti", 7, time.Now()) {
+		t.Fatal("token accepted when the revocation store is down")
+	}
+}
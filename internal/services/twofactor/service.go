@@ -0,0 +1,227 @@
+package twofactor
+
+import (
+	"crypto/rand"
+	"errors"
+	"strings"
+	"time"
+	"web-porto-backend/common/utils"
+	"web-porto-backend/internal/auth"
+	"web-porto-backend/internal/domain/models"
+	twoFactorRepo "web-porto-backend/internal/repositories/twofactor"
+
+	"gorm.io/gorm"
+)
+
+const recoveryCodeCount = 10
+
+var (
+	ErrNotEnrolled    = errors.New("two-factor authentication is not set up")
+	ErrAlreadyEnabled = errors.New("two-factor authentication is already enabled")
+	ErrNotEnabled     = errors.New("two-factor authentication is not enabled")
+	ErrInvalidCode    = errors.New("invalid two-factor code")
+)
+
+type Service interface {
+	IsEnabled(userID int) (bool, error)
+	Status(userID int) (enabled bool, recoveryCodesLeft int64, err error)
+	BeginSetup(userID int, account string) (secret string, uri string, err error)
+	Enable(userID int, code string) ([]string, error)
+	Disable(userID int, code string) error
+	Verify(userID int, code string) error
+	RegenerateRecoveryCodes(userID int, code string) ([]string, error)
+	Reset(userID int) error
+}
+
+type service struct {
+	repo   twoFactorRepo.Repository
+	issuer string
+}
+
+func NewService(repo twoFactorRepo.Repository, issuer string) Service {
+	if issuer == "" {
+		issuer = "Web Porto CMS"
+	}
+	return &service{repo: repo, issuer: issuer}
+}
+
+func (s *service) IsEnabled(userID int) (bool, error) {
+	twoFactor, err := s.repo.FindByUserID(userID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return false, nil
+		}
+		return false, err
+	}
+	return twoFactor.Enabled, nil
+}
+
+func (s *service) Status(userID int) (bool, int64, error) {
+	enabled, err := s.IsEnabled(userID)
+	if err != nil || !enabled {
+		return false, 0, err
+	}
+	left, err := s.repo.CountUnusedRecoveryCodes(userID)
+	return true, left, err
+}
+
+// BeginSetup creates (or replaces) a pending enrollment and returns the
+// secret with its otpauth URI. 2FA stays off until Enable verifies a code.
+func (s *service) BeginSetup(userID int, account string) (string, string, error) {
+	existing, err := s.repo.FindByUserID(userID)
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return "", "", err
+	}
+	if existing != nil && existing.Enabled {
+		return "", "", ErrAlreadyEnabled
+	}
+
+	secret, err := auth.GenerateTOTPSecret()
+	if err != nil {
+		return "", "", err
+	}
+	twoFactor := &models.TwoFactor{UserID: userID, Secret: secret}
+	if existing != nil {
+		twoFactor.CreatedAt = existing.CreatedAt
+	}
+	if err := s.repo.Save(twoFactor); err != nil {
+		return "", "", err
+	}
+	return secret, auth.TOTPURI(s.issuer, account, secret), nil
+}
+
+// Enable confirms the pending enrollment with a first code and returns a
+// fresh set of recovery codes, which are only shown this once.
+func (s *service) Enable(userID int, code string) ([]string, error) {
+	twoFactor, err := s.find(userID)
+	if err != nil {
+		return nil, err
+	}
+	if twoFactor.Enabled {
+		return nil, ErrAlreadyEnabled
+	}
+	if err := s.verifyTOTP(twoFactor, code); err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	twoFactor.Enabled = true
+	twoFactor.EnabledAt = &now
+	if err := s.repo.Save(twoFactor); err != nil {
+		return nil, err
+	}
+	return s.issueRecoveryCodes(userID)
+}
+
+func (s *service) Disable(userID int, code string) error {
+	if err := s.Verify(userID, code); err != nil {
+		return err
+	}
+	return s.repo.Delete(userID)
+}
+
+// Verify accepts either a current TOTP code or an unused recovery code.
+func (s *service) Verify(userID int, code string) error {
+	twoFactor, err := s.find(userID)
+	if err != nil {
+		return err
+	}
+	if !twoFactor.Enabled {
+		return ErrNotEnabled
+	}
+	if err := s.verifyTOTP(twoFactor, code); err == nil {
+		return nil
+	}
+
+	used, err := s.repo.UseRecoveryCode(userID, utils.HashToken(normalizeRecoveryCode(code)))
+	if err != nil {
+		return err
+	}
+	if !used {
+		return ErrInvalidCode
+	}
+	return nil
+}
+
+func (s *service) RegenerateRecoveryCodes(userID int, code string) ([]string, error) {
+	if err := s.Verify(userID, code); err != nil {
+		return nil, err
+	}
+	return s.issueRecoveryCodes(userID)
+}
+
+// Reset removes 2FA for a user, e.g. when an admin helps a locked-out user.
+func (s *service) Reset(userID int) error {
+	if _, err := s.find(userID); err != nil {
+		return err
+	}
+	return s.repo.Delete(userID)
+}
+
+func (s *service) find(userID int) (*models.TwoFactor, error) {
+	twoFactor, err := s.repo.FindByUserID(userID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrNotEnrolled
+		}
+		return nil, err
+	}
+	return twoFactor, nil
+}
+
+func (s *service) verifyTOTP(twoFactor *models.TwoFactor, code string) error {
+	step, ok := auth.ValidateTOTP(twoFactor.Secret, code, time.Now())
+	if !ok {
+		return ErrInvalidCode
+	}
+	advanced, err := s.repo.AdvanceStep(twoFactor.UserID, step)
+	if err != nil {
+		return err
+	}
+	if !advanced {
+		// Code already used in this or a later time window
+		return ErrInvalidCode
+	}
+	twoFactor.LastUsedStep = step
+	return nil
+}
+
+func (s *service) issueRecoveryCodes(userID int) ([]string, error) {
+	codes := make([]string, 0, recoveryCodeCount)
+	hashes := make([]string, 0, recoveryCodeCount)
+	for i := 0; i < recoveryCodeCount; i++ {
+		code, err := newRecoveryCode()
+		if err != nil {
+			return nil, err
+		}
+		codes = append(codes, code)
+		hashes = append(hashes, utils.HashToken(normalizeRecoveryCode(code)))
+	}
+	if err := s.repo.ReplaceRecoveryCodes(userID, hashes); err != nil {
+		return nil, err
+	}
+	return codes, nil
+}
+
+// newRecoveryCode returns a code like "k3f9x-2mq7d" (50 bits of entropy)
+func newRecoveryCode() (string, error) {
+	const alphabet = "abcdefghjkmnpqrstuvwxyz23456789"
+	buf := make([]byte, 10)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	out := make([]byte, 0, 11)
+	for i, b := range buf {
+		if i == 5 {
+			out = append(out, '-')
+		}
+		out = append(out, alphabet[int(b)%len(alphabet)])
+	}
+	return string(out), nil
+}
+
+func normalizeRecoveryCode(code string) string {
+	code = strings.ToLower(strings.TrimSpace(code))
+	code = strings.ReplaceAll(code, "-", "")
+	return strings.ReplaceAll(code, " ", "")
+}
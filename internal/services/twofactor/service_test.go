@@ -0,0 +1,160 @@
+package twofactor
+
+import (
+	"errors"
+	"testing"
+	"time"
+	"web-porto-backend/internal/auth"
+	"web-porto-backend/internal/domain/models"
+	twoFactorRepo "web-porto-backend/internal/repositories/twofactor"
+
+	"gorm.io/gorm"
+)
+
+// fakeTwoFactor stores one user's 2FA in memory with the repository's rules
+type fakeTwoFactor struct {
+	twoFactorRepo.Repository
+	twoFactor *models.TwoFactor
+	codes     map[string]bool
+}
+
+func (r *fakeTwoFactor) FindByUserID(int) (*models.TwoFactor, error) {
+	if r.twoFactor == nil {
+		return nil, gorm.ErrRecordNotFound
+	}
+	copied := *r.twoFactor
+	return &copied, nil
+}
+
+func (r *fakeTwoFactor) Save(twoFactor *models.TwoFactor) error {
+	copied := *twoFactor
+	r.twoFactor = &copied
+	return nil
+}
+
+func (r *fakeTwoFactor) AdvanceStep(_ int, step int64) (bool, error) {
+	if r.twoFactor.LastUsedStep >= step {
+		return false, nil
+	}
+	r.twoFactor.LastUsedStep = step
+	return true, nil
+}
+
+func (r *fakeTwoFactor) Delete(int) error {
+	r.twoFactor, r.codes = nil, nil
+	return nil
+}
+
+func (r *fakeTwoFactor) ReplaceRecoveryCodes(_ int, hashes []string) error {
+	r.codes = map[string]bool{}
+	for _, h := range hashes {
+		r.codes[h] = false
+	}
+	return nil
+}
+
+func (r *fakeTwoFactor) UseRecoveryCode(_ int, hash string) (bool, error) {
+	used, ok := r.codes[hash]
+	if !ok || used {
+		return false, nil
+	}
+	r.codes[hash] = true
+	return true, nil
+}
+
+func (r *fakeTwoFactor) CountUnusedRecoveryCodes(int) (int64, error) {
+	var n int64
+	for _, used := range r.codes {
+		if !used {
+			n++
+		}
+	}
+	return n, nil
+}
+
+// codeAt is the TOTP code of secret delta steps from now
+func codeAt(t *testing.T, secret string, delta int64) string {
+	t.Helper()
+	code, err := auth.TOTPCode(secret, auth.TOTPStep(time.Now())+delta)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return code
+}
+
+// enabled sets up 2FA for user 1, confirmed with the code of the previous
+// step, and returns the secret and the recovery codes
+func enabled(t *testing.T) (Service, *fakeTwoFactor, string, []string) {
+	t.Helper()
+	repo := &fakeTwoFactor{}
+	s := NewService(repo, "")
+	secret, _, err := s.BeginSetup(1, "alice@example.com")
+	if err != nil {
+		t.Fatal(err)
+	}
+	codes, err := s.Enable(1, codeAt(t, secret, -1))
+	if err != nil {
+		t.Fatalf("Enable: %v", err)
+	}
+	return s, repo, secret, codes
+}
+
+func TestVerifyRejectsReplayedCodes(t *testing.T) {
+	s, repo, secret, _ := enabled(t)
+
+	step := auth.TOTPStep(time.Now())
+	code := codeAt(t, secret, 0)
+	if err := s.Verify(1, code); err != nil {
+		t.Fatalf("Verify: %v", err)
+	}
+	if repo.twoFactor.LastUsedStep < step {
+		t.Fatalf("last used step = %d", repo.twoFactor.LastUsedStep)
+	}
+	if err := s.Verify(1, code); !errors.Is(err, ErrInvalidCode) {
+		t.Fatalf("replayed code = %v, want ErrInvalidCode", err)
+	}
+	// The code used to enable is older than the last one used
+	if err := s.Verify(1, codeAt(t, secret, -1)); !errors.Is(err, ErrInvalidCode) {
+		t.Fatalf("earlier code = %v, want ErrInvalidCode", err)
+	}
+	if err := s.Verify(1, codeAt(t, secret, 1)); err != nil {
+		t.Fatalf("code of the next step: %v", err)
+	}
+}
+
+func TestEnableRequiresAValidCode(t *testing.T) {
+	repo := &fakeTwoFactor{}
+	s := NewService(repo, "")
+	secret, uri, err := s.BeginSetup(1, "alice@example.com")
+	if err != nil || uri == "" {
+		t.Fatalf("BeginSetup = %q, %v", uri, err)
+	}
+
+	if _, err := s.Enable(1, codeAt(t, secret, 5)); !errors.Is(err, ErrInvalidCode) {
+		t.Fatalf("Enable with a wrong code = %v", err)
+	}
+	if on, _ := s.IsEnabled(1); on {
+		t.Fatal("enabled with a wrong code")
+	}
+	codes, err := s.Enable(1, codeAt(t, secret, 0))
+	if err != nil || len(codes) != recoveryCodeCount {
+		t.Fatalf("Enable = %v, %v", codes, err)
+	}
+	if _, _, err := s.BeginSetup(1, "alice@example.com"); !errors.Is(err, ErrAlreadyEnabled) {
+		t.Fatalf("BeginSetup once enabled = %v", err)
+	}
+}
+
+func TestRecoveryCodesWorkOnce(t *testing.T) {
+	s, _, _, codes := enabled(t)
+
+	if err := s.Verify(1, " "+codes[0][:5]+" "+codes[0][6:]+" "); err != nil {
+		t.Fatalf("recovery code: %v", err)
+	}
+	if err := s.Verify(1, codes[0]); !errors.Is(err, ErrInvalidCode) {
+		t.Fatalf("used recovery code = %v, want ErrInvalidCode", err)
+	}
+	if on, left, _ := s.Status(1); !on || left != recoveryCodeCount-1 {
+		t.Fatalf("status after using a recovery code = %v with %d codes left", on, left)
+	}
+}
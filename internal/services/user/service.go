@@ -2,6 +2,7 @@
 
 import (
 	"fmt"
+	"time"
 	"web-porto-backend/internal/domain/models"
 	"web-porto-backend/internal/repositories/user"
 
@@ -9,7 +10,7 @@ import (
 )
 
 type Service interface {
-	GetAll(page, limit int) ([]*models.User, *PaginationInfo, error)
+	GetAll(page, limit int, filter ListFilter) ([]*models.User, *PaginationInfo, error)
 	GetByID(id uint) (*models.User, error)
 	GetByEmail(email string) (*models.User, error)
 	GetDefaultAdmin() (*models.User, error)
@@ -17,6 +18,17 @@ type Service interface {
 	Update(id uint, user *models.User) error
 	Delete(id uint) error
 	CheckPassword(hashedPassword, password string) bool
+	ChangeRole(id uint, role string) error
+	ResetPassword(id uint, password string) error
+	SetStatus(id uint, status string) error
+	MarkEmailVerified(id uint) error
+}
+
+// ListFilter narrows GetAll results; empty fields are ignored
+type ListFilter struct {
+	Search string
+	Role   string
+	Status string
 }
 
 // PaginationInfo represents pagination metadata
@@ -35,33 +47,25 @@ func NewService(repo user.Repository) Service {
 	return &service{repo: repo}
 }
 
-func (s *service) GetAll(page, limit int) ([]*models.User, *PaginationInfo, error) {
-	users, err := s.repo.FindAll()
+func (s *service) GetAll(page, limit int, filter ListFilter) ([]*models.User, *PaginationInfo, error) {
+	if page < 1 {
+		page = 1
+	}
+	if limit < 1 {
+		limit = 10
+	}
+
+	users, total, err := s.repo.FindPaginated(filter.Search, filter.Role, filter.Status, (page-1)*limit, limit)
 	if err != nil {
 		return nil, nil, err
 	}
 
-	// Convert []models.User to []*models.User
 	userPtrs := make([]*models.User, len(users))
 	for i := range users {
 		userPtrs[i] = &users[i]
 	}
 
-	// Calculate pagination (simplified)
-	total := len(users)
-	start := (page - 1) * limit
-	end := start + limit
-
-	if start >= total {
-		userPtrs = []*models.User{}
-	} else if end > total {
-		userPtrs = userPtrs[start:]
-	} else {
-		userPtrs = userPtrs[start:end]
-	}
-
-	pagination := s.calculatePagination(total, page, limit)
-	return userPtrs, pagination, nil
+	return userPtrs, s.calculatePagination(int(total), page, limit), nil
 }
 
 func (s *service) GetByID(id uint) (*models.User, error) {
@@ -112,10 +116,21 @@ func (s *service) Update(id uint, user *models.User) error {
 		return err
 	}
 
-	// Update fields
-	existingUser.Username = user.Username
-	existingUser.Email = user.Email
-	existingUser.Role = user.Role
+	// Update provided fields only
+	if user.Username != "" {
+		existingUser.Username = user.Username
+	}
+	if user.Email != "" && user.Email != existingUser.Email {
+		existingUser.Email = user.Email
+		// A changed address has to be verified again
+		existingUser.EmailVerifiedAt = nil
+	}
+	if user.Role != "" {
+		existingUser.Role = user.Role
+	}
+	if user.Status != "" {
+		existingUser.Status = user.Status
+	}
 
 	// Hash password if provided
 	if user.PasswordHash != "" {
@@ -138,6 +153,38 @@ func (s *service) CheckPassword(hashedPassword, password string) bool {
 	return err == nil
 }
 
+func (s *service) ChangeRole(id uint, role string) error {
+	return s.Update(id, &models.User{Role: role})
+}
+
+// ResetPassword sets a new password for the user; the plain password is hashed by Update
+func (s *service) ResetPassword(id uint, password string) error {
+	if password == "" {
+		return fmt.Errorf("password is required")
+	}
+	return s.Update(id, &models.User{PasswordHash: password})
+}
+
+func (s *service) SetStatus(id uint, status string) error {
+	if status != models.UserStatusActive && status != models.UserStatusDisabled {
+		return fmt.Errorf("invalid status %q", status)
+	}
+	return s.Update(id, &models.User{Status: status})
+}
+
+func (s *service) MarkEmailVerified(id uint) error {
+	existingUser, err := s.repo.FindByID(int(id))
+	if err != nil {
+		return err
+	}
+	if existingUser.EmailVerifiedAt != nil {
+		return nil
+	}
+	now := time.Now()
+	existingUser.EmailVerifiedAt = &now
+	return s.repo.Update(existingUser)
+}
+
 // calculatePagination calculates pagination metadata
 func (s *service) calculatePagination(total, page, limit int) *PaginationInfo {
 	totalPages := total / limit
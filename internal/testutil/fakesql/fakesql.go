@@ -0,0 +1,156 @@
+// Package fakesql is a database/sql driver for tests that records the
+// statements GORM sends through the postgres dialector and answers queries
+// with rows chosen by the test.
+package fakesql
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"fmt"
+	"io"
+	"sync"
+	"testing"
+
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// Statement is a query or command sent to the database. Transactions are
+// recorded as BEGIN, COMMIT and ROLLBACK statements.
+type Statement struct {
+	Query string
+	Args  []driver.NamedValue
+}
+
+// RowsFunc returns the columns and rows answering a query; no columns
+// answers with an empty result
+type RowsFunc func(query string, args []driver.NamedValue) (columns []string, rows [][]driver.Value)
+
+// DB is the state of one fake database
+type DB struct {
+	mu         sync.Mutex
+	statements []Statement
+	rows       RowsFunc
+}
+
+var (
+	mu  sync.Mutex
+	dbs = map[string]*DB{}
+)
+
+func init() {
+	sql.Register("fakesql", fakeDriver{})
+}
+
+// Open returns a GORM connection to a new fake database answering queries
+// with rows, which may be nil
+func Open(t *testing.T, rows RowsFunc) (*gorm.DB, *DB) {
+	t.Helper()
+	fake := &DB{rows: rows}
+	mu.Lock()
+	dbs[t.Name()] = fake
+	mu.Unlock()
+	t.Cleanup(func() {
+		mu.Lock()
+		delete(dbs, t.Name())
+		mu.Unlock()
+	})
+
+	db, err := gorm.Open(postgres.New(postgres.Config{DriverName: "fakesql", DSN: t.Name()}),
+		&gorm.Config{DisableAutomaticPing: true, Logger: logger.Discard})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return db, fake
+}
+
+// Statements returns what was sent so far, in order
+func (f *DB) Statements() []Statement {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return append([]Statement(nil), f.statements...)
+}
+
+func (f *DB) record(query string, args []driver.NamedValue) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.statements = append(f.statements, Statement{query, args})
+}
+
+type fakeDriver struct{}
+
+func (fakeDriver) Open(name string) (driver.Conn, error) {
+	mu.Lock()
+	defer mu.Unlock()
+	db, ok := dbs[name]
+	if !ok {
+		return nil, fmt.Errorf("fakesql: no database %q", name)
+	}
+	return &conn{db}, nil
+}
+
+type conn struct {
+	db *DB
+}
+
+func (c *conn) Prepare(string) (driver.Stmt, error) {
+	return nil, fmt.Errorf("fakesql: prepared statements are not supported")
+}
+
+func (c *conn) Close() error { return nil }
+
+func (c *conn) Begin() (driver.Tx, error) {
+	c.db.record("BEGIN", nil)
+	return tx{c.db}, nil
+}
+
+// CheckNamedValue accepts arguments of any type
+func (c *conn) CheckNamedValue(*driver.NamedValue) error { return nil }
+
+func (c *conn) ExecContext(_ context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
+	c.db.record(query, args)
+	return driver.RowsAffected(1), nil
+}
+
+func (c *conn) QueryContext(_ context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	c.db.record(query, args)
+	r := &rows{}
+	if c.db.rows != nil {
+		r.columns, r.values = c.db.rows(query, args)
+	}
+	return r, nil
+}
+
+type tx struct {
+	db *DB
+}
+
+func (t tx) Commit() error {
+	t.db.record("COMMIT", nil)
+	return nil
+}
+
+func (t tx) Rollback() error {
+	t.db.record("ROLLBACK", nil)
+	return nil
+}
+
+type rows struct {
+	columns []string
+	values  [][]driver.Value
+}
+
+func (r *rows) Columns() []string { return r.columns }
+
+func (r *rows) Close() error { return nil }
+
+func (r *rows) Next(dest []driver.Value) error {
+	if len(r.values) == 0 {
+		return io.EOF
+	}
+	copy(dest, r.values[0])
+	r.values = r.values[1:]
+	return nil
+}
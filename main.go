@@ -1,15 +1,16 @@
 ﻿package main
 
 import (
+	"context"
 	"fmt"
 	"log"
 	"os"
-	"path/filepath"
 	"strconv"
 	"time"
 	appLogger "web-porto-backend/common/logger"
 	"web-porto-backend/config"
 	httpAdapter "web-porto-backend/internal/adapters/http"
+	"web-porto-backend/internal/adapters/storage"
 	"web-porto-backend/internal/adapters/websocket"
 	"web-porto-backend/internal/auth"
 	"web-porto-backend/internal/domain/models"
@@ -18,6 +19,7 @@ import (
 	"web-porto-backend/internal/repositories"
 	"web-porto-backend/internal/services"
 	analyticsSrvc "web-porto-backend/internal/services/analytics"
+	mediaSrvc "web-porto-backend/internal/services/media"
 	"web-porto-backend/middleware"
 	"web-porto-backend/routes"
 
@@ -83,17 +85,89 @@ func main() {
 		log.Fatal("Failed seeding data:", err)
 	}
 
+	// Media storage; the local backend is always available so files can be
+	// migrated off it, S3 is added when a bucket is configured. Private media
+	// are kept apart and only served by the signed /files route.
+	mediaStorage, err := storage.New(storage.Config{
+		Driver:        cfg.Storage.Driver,
+		LocalDir:      getUploadDir(cfg),
+		LocalURL:      getBaseURL(cfg) + "/uploads",
+		PrivateDir:    cfg.Storage.PrivatePath(),
+		PrivateBucket: cfg.Storage.S3.PrivateBucket,
+		PrivateURL:    getBaseURL(cfg) + "/files",
+		S3: storage.S3Config{
+			Endpoint:  cfg.Storage.S3.Endpoint,
+			Region:    cfg.Storage.S3.Region,
+			Bucket:    cfg.Storage.S3.Bucket,
+			AccessKey: cfg.Storage.S3.AccessKey,
+			SecretKey: cfg.Storage.S3.SecretKey,
+			PathStyle: cfg.Storage.S3.PathStyle,
+			PublicURL: cfg.Storage.S3.PublicURL,
+		},
+	})
+	if err != nil {
+		log.Fatal("Failed to set up media storage:", err)
+	}
+
 	// Initialize layers
 	repositoryRegistry := repositories.NewRepositoryRegistry(db)
-	serviceRegistry := services.NewServiceRegistry(repositoryRegistry)
-	authService := auth.NewAuthService(cfg.JWT.Secret)
+	serviceRegistry := services.NewServiceRegistry(repositoryRegistry, cfg, mediaStorage)
+
+	// One-off commands run against the configured database and exit
+	if len(os.Args) > 1 {
+		switch os.Args[1] {
+		case "migrate-storage":
+			os.Exit(runStorageMigration(serviceRegistry.MediaService, os.Args[2:]))
+		case "reconcile-media":
+			os.Exit(runMediaReconcile(serviceRegistry.MediaService, os.Args[2:]))
+		}
+	}
+	authService := auth.NewAuthService(cfg.JWT.Secret, cfg.JWT.AccessTokenTTL)
+	authService.SetRevocationChecker(serviceRegistry.TokenService)
+	authService.SetAPITokenAuthenticator(serviceRegistry.APITokenService)
+
+	// Periodically purge expired refresh tokens and revoked jtis
+	go func() {
+		ticker := time.NewTicker(time.Hour)
+		defer ticker.Stop()
+		for range ticker.C {
+			if n, err := serviceRegistry.TokenService.PurgeExpired(); err != nil {
+				log.Printf("Failed purging expired tokens: %v", err)
+			} else if n > 0 {
+				log.Printf("Purged %d expired tokens", n)
+			}
+		}
+	}()
+
+	// Promote/expire scheduled content; safe to run on every replica
+	if cfg.Scheduler.Enabled {
+		go serviceRegistry.ScheduleService.Start(context.Background())
+	}
+
+	// Generate image variants off the request path; also safe on every replica
+	if cfg.Media.ProcessingEnabled {
+		go serviceRegistry.MediaService.Start(context.Background())
+	}
+
+	// Remove abandoned resumable uploads and their chunks
+	go func() {
+		ticker := time.NewTicker(time.Hour)
+		defer ticker.Stop()
+		for range ticker.C {
+			if n, err := serviceRegistry.MediaService.ExpireUploads(); err != nil {
+				log.Printf("Failed expiring uploads: %v", err)
+			} else if n > 0 {
+				log.Printf("Expired %d abandoned uploads", n)
+			}
+		}
+	}()
 
 	// Initialize WebSocket manager
 	wsManager := websocket.NewManager()
 	go wsManager.Start() // Start WebSocket manager in a goroutine
 
 	// Initialize HTTP adapter
-	httpAdpt := httpAdapter.NewHTTPAdapter()
+	httpAdpt := httpAdapter.NewHTTPAdapter().WithCursorSecret(cfg.JWT.Secret)
 
 	// Connect WebSocket manager to analytics service
 	analyticsService, ok := serviceRegistry.AnalyticsService.(analyticsSrvc.Service)
@@ -108,7 +182,6 @@ func main() {
 		authService,
 		httpAdpt,
 		db,
-		getUploadDir(),
 		getBaseURL(cfg),
 	) // Setup Gin router
 	router := gin.Default()
@@ -158,11 +231,19 @@ func main() {
 	// Register WebSocket routes
 	routes.SetupWebSocketRoutes(router, wsManager)
 
-	// Serve static uploaded files
-	uploadDir := getUploadDir()
-	router.Static("/uploads", uploadDir)
+	// Serve files of the local storage backend; also keeps URLs of files
+	// already migrated elsewhere working until they are deleted. SVGs are
+	// sandboxed, or downloaded under the "download" SVG policy.
+	uploadDir := getUploadDir(cfg)
+	router.Group("/uploads", middleware.UploadHeaders(cfg.Media.SVGPolicy == mediaSrvc.SVGDownload)).Static("", uploadDir)
 	log.Printf("Serving uploads from: %s", uploadDir)
 
+	// Private media are only served for signed, unexpired URLs; the private
+	// storage itself is never exposed
+	privateFiles := router.Group("/files", middleware.UploadHeaders(cfg.Media.SVGPolicy == mediaSrvc.SVGDownload))
+	privateFiles.GET("/*key", handlerRegistry.MediaHandler.ServeFile)
+	privateFiles.HEAD("/*key", handlerRegistry.MediaHandler.ServeFile)
+
 	// Start server
 	port := strconv.Itoa(cfg.Server.Port)
 	log.Printf("Server starting on port %s", port)
@@ -171,27 +252,12 @@ func main() {
 	}
 }
 
-// getUploadDir returns the local directory for uploaded files
-func getUploadDir() string {
-	dir := os.Getenv("UPLOAD_DIR")
-	if dir == "" {
-		// Use current working directory for local development
-		cwd, err := os.Getwd()
-		if err != nil {
-			dir = "./uploads"
-		} else {
-			dir = filepath.Join(cwd, "uploads")
-		}
-	}
-	return dir
+// getUploadDir returns the directory of the local storage backend
+func getUploadDir(cfg *config.Config) string {
+	return cfg.Storage.Dir()
 }
 
 // getBaseURL returns the public base URL for building file URLs
 func getBaseURL(cfg *config.Config) string {
-	baseURL := os.Getenv("BASE_URL")
-	if baseURL != "" {
-		return baseURL
-	}
-	// Fallback: local server
-	return fmt.Sprintf("http://localhost:%d", cfg.Server.Port)
+	return cfg.Server.PublicURL()
 }
@@ -0,0 +1,84 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+	mediaSrvc "web-porto-backend/internal/services/media"
+)
+
+// runMediaReconcile implements the reconcile-media command, which reports
+// orphaned files, media with missing files and unreferenced media:
+//
+//	go run . reconcile-media [--cleanup] [--grace 168h] [--json]
+//
+// It returns the process exit code.
+func runMediaReconcile(mediaService mediaSrvc.Service, args []string) int {
+	fs := flag.NewFlagSet("reconcile-media", flag.ContinueOnError)
+	cleanup := fs.Bool("cleanup", false, "delete orphaned files and unreferenced media older than the grace period")
+	grace := fs.Duration("grace", 0, "grace period (default MEDIA_RECONCILE_GRACE)")
+	asJSON := fs.Bool("json", false, "print the full report as JSON")
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	report, err := mediaService.Reconcile(ctx, mediaSrvc.ReconcileOptions{Cleanup: *cleanup, Grace: *grace})
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "reconcile-media:", err)
+		return 1
+	}
+
+	if *asJSON {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		enc.Encode(report)
+	} else {
+		printReconcileReport(report)
+	}
+	if len(report.Errors) > 0 {
+		return 1
+	}
+	return 0
+}
+
+func printReconcileReport(report *mediaSrvc.ReconcileReport) {
+	mode := "dry run"
+	if report.Cleanup {
+		mode = "cleanup"
+	}
+	fmt.Printf("Scanned %d media and %d files (%s, grace %s)\n", report.ScannedMedia, report.ScannedFiles, mode, report.Grace)
+
+	fmt.Printf("\nOrphaned files: %d\n", len(report.Orphans))
+	for _, o := range report.Orphans {
+		fmt.Printf("  %s %s (%d bytes, %s)%s\n", o.Storage, o.Key, o.Size, o.ModTime.Format("2006-01-02"), deletedMark(o.Deleted))
+	}
+	fmt.Printf("\nMedia with missing files: %d\n", len(report.Missing))
+	for _, m := range report.Missing {
+		note := ""
+		if m.InUse {
+			note = " (in use)"
+		}
+		fmt.Printf("  #%d %s: %v%s\n", m.MediaID, m.FileURL, m.Keys, note)
+	}
+	fmt.Printf("\nUnreferenced media: %d\n", len(report.Unreferenced))
+	for _, u := range report.Unreferenced {
+		fmt.Printf("  #%d %s (%s, uploaded %s)%s\n", u.MediaID, u.FileURL, u.OriginalName, u.UploadedAt.Format("2006-01-02"), deletedMark(u.Deleted))
+	}
+	for _, e := range report.Errors {
+		fmt.Println("error:", e)
+	}
+}
+
+func deletedMark(deleted bool) string {
+	if deleted {
+		return " - deleted"
+	}
+	return ""
+}
@@ -47,8 +47,7 @@ func JWTAuth(authService *auth.AuthService) gin.HandlerFunc {
 		// Extract token
 		tokenString := strings.TrimPrefix(authHeader, "Bearer ")
 
-		// Validate token
-		claims, err := authService.ValidateToken(tokenString)
+		claims, err := validateToken(c, authService, tokenString)
 		if err != nil {
 			c.JSON(http.StatusUnauthorized, gin.H{
 				"error":  "Invalid token: " + err.Error(),
@@ -59,14 +58,53 @@ func JWTAuth(authService *auth.AuthService) gin.HandlerFunc {
 			return
 		}
 
-		// Store claims in context
-		c.Set("user_id", claims.UserID)
-		c.Set("username", claims.Username)
-		c.Set("role", claims.Role)
+		setClaims(c, claims, tokenString)
 		c.Next()
 	}
 }
 
+// OptionalJWTAuth identifies the user like JWTAuth when a valid bearer token
+// is sent, but lets anonymous requests (and invalid tokens) through without
+// user context. Used on public read routes that show more to CMS users.
+func OptionalJWTAuth(authService *auth.AuthService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		authHeader := c.GetHeader("Authorization")
+		if strings.HasPrefix(authHeader, "Bearer ") {
+			tokenString := strings.TrimPrefix(authHeader, "Bearer ")
+			if claims, err := validateToken(c, authService, tokenString); err == nil {
+				setClaims(c, claims, tokenString)
+			}
+		}
+		c.Next()
+	}
+}
+
+// validateToken checks a bearer token; personal access tokens are recognised
+// by their prefix
+func validateToken(c *gin.Context, authService *auth.AuthService, tokenString string) (*auth.Claims, error) {
+	if auth.IsAPIToken(tokenString) {
+		return authService.ValidateAPIToken(tokenString, c.ClientIP())
+	}
+	return authService.ValidateToken(tokenString)
+}
+
+// setClaims stores the authenticated user in the request context
+func setClaims(c *gin.Context, claims *auth.Claims, tokenString string) {
+	c.Set("user_id", claims.UserID)
+	c.Set("username", claims.Username)
+	c.Set("role", claims.Role)
+	c.Set("permissions", claims.Permissions)
+	c.Set("jti", claims.ID)
+	if claims.ExpiresAt != nil {
+		c.Set("token_expires_at", claims.ExpiresAt.Time)
+	}
+	if auth.IsAPIToken(tokenString) {
+		c.Set("auth_method", "api_token")
+	} else {
+		c.Set("auth_method", "jwt")
+	}
+}
+
 func RequireRole(role string) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		userRole, exists := c.Get("role")
@@ -85,3 +123,44 @@ func RequireRole(role string) gin.HandlerFunc {
 		c.Next()
 	}
 }
+
+// RequirePermission aborts with 403 unless the authenticated user holds every
+// listed permission. Must run after JWTAuth.
+func RequirePermission(permissions ...string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if _, exists := c.Get("user_id"); !exists {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+			c.Abort()
+			return
+		}
+
+		granted := c.GetStringSlice("permissions")
+		for _, p := range permissions {
+			if !auth.HasPermission(granted, p) {
+				c.JSON(http.StatusForbidden, gin.H{
+					"error":      "Insufficient permissions",
+					"permission": p,
+				})
+				c.Abort()
+				return
+			}
+		}
+
+		c.Next()
+	}
+}
+
+// RequireSession aborts with 403 when the request is authenticated with an API
+// token, for account security actions a scoped token must not perform. Must
+// run after JWTAuth.
+func RequireSession() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if c.GetString("auth_method") != "jwt" {
+			c.JSON(http.StatusForbidden, gin.H{"error": "This action requires logging in; API tokens are not accepted"})
+			c.Abort()
+			return
+		}
+
+		c.Next()
+	}
+}
@@ -0,0 +1,77 @@
+package middleware
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"web-porto-backend/internal/auth"
+
+	"github.com/gin-gonic/gin"
+)
+
+// fakeAPITokens accepts one token, scoped to writing articles
+type fakeAPITokens struct{}
+
+func (fakeAPITokens) AuthenticateAPIToken(raw, _ string) (*auth.Claims, error) {
+	if raw != auth.APITokenPrefix+"valid" {
+		return nil, errors.New("invalid or expired API token")
+	}
+	return &auth.Claims{UserID: 1, Permissions: []string{auth.PermArticleWrite}}, nil
+}
+
+func TestAPITokensAreScopedAndCannotManageTheAccount(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	authService := auth.NewAuthService("test secret", time.Minute)
+	authService.SetAPITokenAuthenticator(fakeAPITokens{})
+	session, err := authService.GenerateToken(1, "alice@example.com", "admin", []string{auth.PermissionAll})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ok := func(c *gin.Context) { c.Status(http.StatusNoContent) }
+	r := gin.New()
+	r.Use(JWTAuth(authService))
+	r.POST("/articles", RequirePermission(auth.PermArticleWrite), ok)
+	r.POST("/users", RequirePermission(auth.PermUsersManage), ok)
+	r.POST("/auth/password", RequireSession(), ok)
+
+	tests := []struct {
+		name  string
+		token string
+		path  string
+		want  int
+	}{
+		{"token within scope", auth.APITokenPrefix + "valid", "/articles", http.StatusNoContent},
+		{"token beyond scope", auth.APITokenPrefix + "valid", "/users", http.StatusForbidden},
+		{"token on a session route", auth.APITokenPrefix + "valid", "/auth/password", http.StatusForbidden},
+		{"unknown token", auth.APITokenPrefix + "stolen", "/articles", http.StatusUnauthorized},
+		{"session", session, "/users", http.StatusNoContent},
+		{"session on a session route", session, "/auth/password", http.StatusNoContent},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest(http.MethodPost, tt.path, nil)
+			req.Header.Set("Authorization", "Bearer "+tt.token)
+			r.ServeHTTP(w, req)
+			if w.Code != tt.want {
+				t.Fatalf("status = %d, want %d: %s", w.Code, tt.want, w.Body.String())
+			}
+		})
+	}
+}
+
+func TestRequireSessionWithoutAuthentication(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.POST("/auth/password", RequireSession(), func(c *gin.Context) { c.Status(http.StatusNoContent) })
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/auth/password", nil))
+	if w.Code != http.StatusForbidden {
+		t.Fatalf("status = %d, want %d", w.Code, http.StatusForbidden)
+	}
+}
@@ -0,0 +1,24 @@
+package middleware
+
+import (
+	"path"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// UploadHeaders hardens files served from the local storage backend. Browsers
+// must not guess types, and SVGs opened directly run in a sandbox without
+// scripts or external loads; with downloadSVG they are served as attachments.
+func UploadHeaders(downloadSVG bool) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Header("X-Content-Type-Options", "nosniff")
+		if strings.EqualFold(path.Ext(c.Request.URL.Path), ".svg") {
+			c.Header("Content-Security-Policy", "default-src 'none'; img-src data:; style-src 'unsafe-inline'; sandbox")
+			if downloadSVG {
+				c.Header("Content-Disposition", "attachment")
+			}
+		}
+		c.Next()
+	}
+}
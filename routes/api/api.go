@@ -1,6 +1,7 @@
 ﻿package api
 
 import (
+	"time"
 	"web-porto-backend/internal/auth"
 	"web-porto-backend/internal/handlers"
 	"web-porto-backend/middleware"
@@ -15,7 +16,7 @@ func SetupAPIRoutes(router *gin.Engine, handlerRegistry *handlers.HandlerRegistr
 	v1.Use(middleware.EncodeResponse())
 
 	// Setup routes using handler registry
-	setupPublicRoutesWithRegistry(v1, handlerRegistry)
+	setupPublicRoutesWithRegistry(v1, handlerRegistry, authService)
 	setupProtectedRoutesWithRegistry(v1, handlerRegistry, authService)
 
 	// Setup article and project routes
@@ -23,7 +24,7 @@ func SetupAPIRoutes(router *gin.Engine, handlerRegistry *handlers.HandlerRegistr
 }
 
 // setupPublicRoutesWithRegistry configures public API routes using handler registry
-func setupPublicRoutesWithRegistry(router *gin.RouterGroup, handlerRegistry *handlers.HandlerRegistry) {
+func setupPublicRoutesWithRegistry(router *gin.RouterGroup, handlerRegistry *handlers.HandlerRegistry, authService *auth.AuthService) {
 	// Analytics routes (public for client-side tracking; can be protected by API key header if needed)
 	analytics := router.Group("/analytics")
 	{
@@ -45,8 +46,14 @@ func setupPublicRoutesWithRegistry(router *gin.RouterGroup, handlerRegistry *han
 	// Authentication routes
 	auth := router.Group("/auth")
 	{
-		auth.POST("/register", handlerRegistry.AuthHandler.Register)
-		auth.POST("/login", handlerRegistry.AuthHandler.Login)
+		auth.POST("/register", middleware.RateLimit(5, time.Minute), handlerRegistry.AuthHandler.Register)
+		auth.POST("/login", middleware.RateLimit(10, time.Minute), handlerRegistry.AuthHandler.Login)
+		auth.POST("/refresh", middleware.RateLimit(30, time.Minute), handlerRegistry.AuthHandler.RefreshToken)
+		auth.POST("/2fa/verify", middleware.RateLimit(10, time.Minute), handlerRegistry.AuthHandler.VerifyTwoFactor)
+		auth.POST("/forgot-password", middleware.RateLimit(5, time.Minute), handlerRegistry.AuthHandler.ForgotPassword)
+		auth.POST("/reset-password", middleware.RateLimit(10, time.Minute), handlerRegistry.AuthHandler.ResetPassword)
+		auth.POST("/verify-email", middleware.RateLimit(10, time.Minute), handlerRegistry.AuthHandler.VerifyEmail)
+		auth.POST("/verify-email/resend", middleware.RateLimit(5, time.Minute), handlerRegistry.AuthHandler.ResendVerification)
 	}
 
 	// Public category routes
@@ -56,8 +63,9 @@ func setupPublicRoutesWithRegistry(router *gin.RouterGroup, handlerRegistry *han
 		categories.GET("/:id", handlerRegistry.CategoryHandler.GetByID)
 	}
 
-	// Public post routes
+	// Public post routes; CMS users may list drafts with ?status=
 	posts := router.Group("/posts")
+	posts.Use(middleware.OptionalJWTAuth(authService))
 	{
 		posts.GET("", handlerRegistry.PostHandler.GetAll)
 		posts.GET("/published", handlerRegistry.PostHandler.GetPublished)
@@ -66,8 +74,9 @@ func setupPublicRoutesWithRegistry(router *gin.RouterGroup, handlerRegistry *han
 		posts.GET("/author/:authorId", handlerRegistry.PostHandler.GetByAuthor)
 	}
 
-	// Public page routes
+	// Public page routes; CMS users may list drafts with ?status=
 	pages := router.Group("/pages")
+	pages.Use(middleware.OptionalJWTAuth(authService))
 	{
 		pages.GET("", handlerRegistry.PageHandler.GetAll)
 		pages.GET("/published", handlerRegistry.PageHandler.GetPublished)
@@ -75,6 +84,12 @@ func setupPublicRoutesWithRegistry(router *gin.RouterGroup, handlerRegistry *han
 		pages.GET("/slug/:slug", handlerRegistry.PageHandler.GetBySlug)
 	}
 
+	// Full-text search over published content
+	router.GET("/search", middleware.RateLimit(60, time.Minute), handlerRegistry.SearchHandler.Search)
+
+	// Head tags of published content for SSR and link previews
+	router.GET("/seo/:type/:slug", handlerRegistry.SEOHandler.Head)
+
 	// Public comment routes (read-only)
 	comments := router.Group("/comments")
 	{
@@ -97,46 +112,142 @@ func setupProtectedRoutesWithRegistry(router *gin.RouterGroup, handlerRegistry *
 	protected.Use(middleware.JWTAuth(authService))
 
 	// Auth profile routes
-	auth := protected.Group("/auth")
+	authRoutes := protected.Group("/auth")
 	{
-		auth.GET("/me", handlerRegistry.AuthHandler.Me)
+		authRoutes.GET("/me", handlerRegistry.AuthHandler.Me)
+		authRoutes.POST("/logout", handlerRegistry.AuthHandler.Logout)
+		authRoutes.POST("/logout-all", middleware.RequireSession(), handlerRegistry.AuthHandler.LogoutAll)
+
+		// Two-factor enrollment for the current user
+		authRoutes.GET("/2fa", handlerRegistry.AuthHandler.GetTwoFactorStatus)
+		authRoutes.POST("/2fa/setup", middleware.RequireSession(), handlerRegistry.AuthHandler.SetupTwoFactor)
+		authRoutes.POST("/2fa/enable", middleware.RequireSession(), handlerRegistry.AuthHandler.EnableTwoFactor)
+		authRoutes.POST("/2fa/disable", middleware.RequireSession(), handlerRegistry.AuthHandler.DisableTwoFactor)
+		authRoutes.POST("/2fa/recovery-codes", middleware.RequireSession(), handlerRegistry.AuthHandler.RegenerateRecoveryCodes)
+	}
+
+	// Personal access tokens of the current user
+	apiTokens := protected.Group("/api-tokens")
+	{
+		apiTokens.GET("", handlerRegistry.APITokenHandler.GetMine)
+		apiTokens.POST("", middleware.RequireSession(), handlerRegistry.APITokenHandler.Create)
+		apiTokens.DELETE("/:id", middleware.RequireSession(), handlerRegistry.APITokenHandler.RevokeMine)
 	}
 
 	// Protected category routes
 	categories := protected.Group("/categories")
 	{
-		categories.POST("", handlerRegistry.CategoryHandler.Create)
-		categories.PUT("/:id", handlerRegistry.CategoryHandler.Update)
-		categories.DELETE("/:id", handlerRegistry.CategoryHandler.Delete)
+		categories.POST("", middleware.RequirePermission(auth.PermCategoryWrite), handlerRegistry.CategoryHandler.Create)
+		categories.PUT("/:id", middleware.RequirePermission(auth.PermCategoryWrite), handlerRegistry.CategoryHandler.Update)
+		categories.DELETE("/:id", middleware.RequirePermission(auth.PermCategoryWrite), handlerRegistry.CategoryHandler.Delete)
 	}
 
 	// Protected post routes
 	posts := protected.Group("/posts")
 	{
-		posts.POST("", handlerRegistry.PostHandler.Create)
-		posts.PUT("/:id", handlerRegistry.PostHandler.Update)
-		posts.DELETE("/:id", handlerRegistry.PostHandler.Delete)
+		posts.POST("", middleware.RequirePermission(auth.PermArticleWrite), handlerRegistry.PostHandler.Create)
+		posts.PUT("/:id", middleware.RequirePermission(auth.PermArticleWrite), handlerRegistry.PostHandler.Update)
+		posts.DELETE("/:id", middleware.RequirePermission(auth.PermArticleDelete), handlerRegistry.PostHandler.Delete)
 	}
 
 	// Protected page routes
 	pages := protected.Group("/pages")
 	{
-		pages.POST("", handlerRegistry.PageHandler.Create)
-		pages.PUT("/:id", handlerRegistry.PageHandler.Update)
-		pages.DELETE("/:id", handlerRegistry.PageHandler.Delete)
+		pages.POST("", middleware.RequirePermission(auth.PermPageWrite), handlerRegistry.PageHandler.Create)
+		pages.PUT("/:id", middleware.RequirePermission(auth.PermPageWrite), handlerRegistry.PageHandler.Update)
+		pages.DELETE("/:id", middleware.RequirePermission(auth.PermPageDelete), handlerRegistry.PageHandler.Delete)
+		pages.GET("/:id/revisions", middleware.RequirePermission(auth.PermPageWrite), handlerRegistry.PageRevisionHandler.GetAll)
+		pages.GET("/:id/revisions/:revisionId", middleware.RequirePermission(auth.PermPageWrite), handlerRegistry.PageRevisionHandler.GetByID)
+		pages.GET("/:id/revisions/:revisionId/diff", middleware.RequirePermission(auth.PermPageWrite), handlerRegistry.PageRevisionHandler.Diff)
+		pages.POST("/:id/revisions/:revisionId/restore", middleware.RequirePermission(auth.PermPageWrite), handlerRegistry.PageRevisionHandler.Restore)
 	}
 
 	// Protected comment routes
 	comments := protected.Group("/comments")
 	{
 		comments.POST("", handlerRegistry.CommentHandler.Create)
-		comments.PUT("/:id", handlerRegistry.CommentHandler.Update)
-		comments.DELETE("/:id", handlerRegistry.CommentHandler.Delete)
+		comments.PUT("/:id", middleware.RequirePermission(auth.PermCommentModerate), handlerRegistry.CommentHandler.Update)
+		comments.DELETE("/:id", middleware.RequirePermission(auth.PermCommentModerate), handlerRegistry.CommentHandler.Delete)
 	}
 
 	// Protected setting routes
 	settings := protected.Group("/settings")
 	{
-		settings.PUT("", handlerRegistry.SettingHandler.Update)
+		settings.PUT("", middleware.RequirePermission(auth.PermSettingsWrite), handlerRegistry.SettingHandler.Update)
+	}
+
+	// Role and permission management
+	roles := protected.Group("/roles")
+	roles.Use(middleware.RequirePermission(auth.PermRolesManage))
+	{
+		roles.GET("", handlerRegistry.RoleHandler.GetAll)
+		roles.GET("/permissions", handlerRegistry.RoleHandler.GetPermissions)
+		roles.GET("/:id", handlerRegistry.RoleHandler.GetByID)
+		roles.POST("", handlerRegistry.RoleHandler.Create)
+		roles.PUT("/:id", handlerRegistry.RoleHandler.Update)
+		roles.DELETE("/:id", handlerRegistry.RoleHandler.Delete)
+		roles.GET("/:id/users", handlerRegistry.RoleHandler.GetUsers)
+		roles.POST("/:id/users", handlerRegistry.RoleHandler.AssignUser)
+		roles.DELETE("/:id/users/:userId", handlerRegistry.RoleHandler.RemoveUser)
+	}
+
+	// User management
+	users := protected.Group("/users")
+	users.Use(middleware.RequirePermission(auth.PermUsersManage))
+	{
+		users.GET("", handlerRegistry.UserHandler.GetUsers)
+		users.GET("/:id", handlerRegistry.UserHandler.GetUser)
+		users.POST("", handlerRegistry.UserHandler.CreateUser)
+		users.PUT("/:id", handlerRegistry.UserHandler.UpdateUser)
+		users.DELETE("/:id", handlerRegistry.UserHandler.DeleteUser)
+		users.PUT("/:id/role", handlerRegistry.UserHandler.ChangeRole)
+		users.POST("/:id/reset-password", handlerRegistry.UserHandler.ResetPassword)
+		users.POST("/:id/disable", handlerRegistry.UserHandler.Disable)
+		users.POST("/:id/enable", handlerRegistry.UserHandler.Enable)
+		users.DELETE("/:id/2fa", handlerRegistry.UserHandler.ResetTwoFactor)
+	}
+
+	// Login attempts and lockouts
+	security := protected.Group("/security")
+	security.Use(middleware.RequirePermission(auth.PermUsersManage))
+	{
+		security.GET("/login-attempts", handlerRegistry.LoginAttemptHandler.GetAttempts)
+		security.GET("/lockouts", handlerRegistry.LoginAttemptHandler.GetLockouts)
+		security.DELETE("/lockouts", handlerRegistry.LoginAttemptHandler.ClearLockout)
+		security.GET("/api-tokens", handlerRegistry.APITokenHandler.GetAll)
+		security.DELETE("/api-tokens/:id", handlerRegistry.APITokenHandler.Revoke)
+	}
+
+	// Registration invitations
+	invitations := protected.Group("/invitations")
+	invitations.Use(middleware.RequirePermission(auth.PermUsersManage))
+	{
+		invitations.GET("", handlerRegistry.InvitationHandler.GetAll)
+		invitations.POST("", handlerRegistry.InvitationHandler.Create)
+		invitations.DELETE("/:id", handlerRegistry.InvitationHandler.Revoke)
+	}
+
+	// Audit log of content mutations
+	audit := protected.Group("/audit")
+	audit.Use(middleware.RequirePermission(auth.PermAuditRead))
+	{
+		audit.GET("", handlerRegistry.AuditHandler.GetAll)
+		audit.GET("/:id", handlerRegistry.AuditHandler.GetByID)
+	}
+
+	// Scheduled publish/unpublish jobs; listing and cancelling check the
+	// publish permission of the jobs' content type
+	schedule := protected.Group("/schedule")
+	{
+		schedule.GET("", handlerRegistry.ScheduleHandler.GetAll)
+		schedule.DELETE("/:id", handlerRegistry.ScheduleHandler.Cancel)
+	}
+
+	// Raw analytics rows, cursor paginated
+	analytics := protected.Group("/analytics")
+	analytics.Use(middleware.RequirePermission(auth.PermAnalyticsRead))
+	{
+		analytics.GET("/page-views", handlerRegistry.AnalyticsHandler.ListViews)
+		analytics.GET("/content-views", handlerRegistry.AnalyticsHandler.ListContentViews)
 	}
 }
@@ -13,8 +13,9 @@ func SetupArticleProjectRoutes(router *gin.Engine, handlerRegistry *handlers.Han
 	// API v1 group
 	v1 := router.Group("/api/v1")
 
-	// Public article routes
+	// Public article routes; CMS users may list drafts with ?status=
 	articles := v1.Group("/articles")
+	articles.Use(middleware.OptionalJWTAuth(authService))
 	{
 		articles.GET("", handlerRegistry.ArticleHandler.GetAll)
 		articles.GET("/published", handlerRegistry.ArticleHandler.GetPublished)
@@ -24,8 +25,9 @@ func SetupArticleProjectRoutes(router *gin.Engine, handlerRegistry *handlers.Han
 		articles.GET("/tag/:name", handlerRegistry.ArticleHandler.GetByTag)
 	}
 
-	// Public project routes
+	// Public project routes; CMS users may list drafts with ?status=
 	projects := v1.Group("/projects")
+	projects.Use(middleware.OptionalJWTAuth(authService))
 	{
 		projects.GET("", handlerRegistry.ProjectHandler.GetAll)
 		projects.GET("/published", handlerRegistry.ProjectHandler.GetPublished)
@@ -50,62 +52,97 @@ func SetupArticleProjectRoutes(router *gin.Engine, handlerRegistry *handlers.Han
 	// Protected article routes
 	protectedArticles := protected.Group("/articles")
 	{
-		protectedArticles.POST("", handlerRegistry.ArticleHandler.Create)
-		protectedArticles.PUT("/:id", handlerRegistry.ArticleHandler.Update)
-		protectedArticles.PATCH("/:id", handlerRegistry.ArticleHandler.Patch)
-		protectedArticles.DELETE("/:id", handlerRegistry.ArticleHandler.Delete)
-		protectedArticles.POST("/:id/images", handlerRegistry.ArticleHandler.AddImage)
-		protectedArticles.POST("/:id/videos", handlerRegistry.ArticleHandler.AddVideo)
-		protectedArticles.DELETE("/:id/images/:imageId", handlerRegistry.ArticleHandler.DeleteImage)
-		protectedArticles.DELETE("/:id/videos/:videoId", handlerRegistry.ArticleHandler.DeleteVideo)
+		protectedArticles.POST("", middleware.RequirePermission(auth.PermArticleWrite), handlerRegistry.ArticleHandler.Create)
+		protectedArticles.PUT("/:id", middleware.RequirePermission(auth.PermArticleWrite), handlerRegistry.ArticleHandler.Update)
+		protectedArticles.PATCH("/:id", middleware.RequirePermission(auth.PermArticleWrite), handlerRegistry.ArticleHandler.Patch)
+		protectedArticles.DELETE("/:id", middleware.RequirePermission(auth.PermArticleDelete), handlerRegistry.ArticleHandler.Delete)
+		protectedArticles.POST("/:id/images", middleware.RequirePermission(auth.PermArticleWrite), handlerRegistry.ArticleHandler.AddImage)
+		protectedArticles.POST("/:id/videos", middleware.RequirePermission(auth.PermArticleWrite), handlerRegistry.ArticleHandler.AddVideo)
+		protectedArticles.DELETE("/:id/images/:imageId", middleware.RequirePermission(auth.PermArticleWrite), handlerRegistry.ArticleHandler.DeleteImage)
+		protectedArticles.DELETE("/:id/videos/:videoId", middleware.RequirePermission(auth.PermArticleWrite), handlerRegistry.ArticleHandler.DeleteVideo)
+		protectedArticles.GET("/:id/revisions", middleware.RequirePermission(auth.PermArticleWrite), handlerRegistry.ArticleRevisionHandler.GetAll)
+		protectedArticles.GET("/:id/revisions/:revisionId", middleware.RequirePermission(auth.PermArticleWrite), handlerRegistry.ArticleRevisionHandler.GetByID)
+		protectedArticles.GET("/:id/revisions/:revisionId/diff", middleware.RequirePermission(auth.PermArticleWrite), handlerRegistry.ArticleRevisionHandler.Diff)
+		protectedArticles.POST("/:id/revisions/:revisionId/restore", middleware.RequirePermission(auth.PermArticleWrite), handlerRegistry.ArticleRevisionHandler.Restore)
 	}
 
 	// Protected project routes
 	protectedProjects := protected.Group("/projects")
 	{
-		protectedProjects.POST("", handlerRegistry.ProjectHandler.Create)
-		protectedProjects.PUT("/:id", handlerRegistry.ProjectHandler.Update)
-		protectedProjects.PATCH("/:id", handlerRegistry.ProjectHandler.Patch)
-		protectedProjects.DELETE("/:id", handlerRegistry.ProjectHandler.Delete)
-		protectedProjects.POST("/:id/images", handlerRegistry.ProjectHandler.AddImage)
-		protectedProjects.POST("/:id/videos", handlerRegistry.ProjectHandler.AddVideo)
-		protectedProjects.DELETE("/:id/images/:imageId", handlerRegistry.ProjectHandler.DeleteImage)
-		protectedProjects.DELETE("/:id/videos/:videoId", handlerRegistry.ProjectHandler.DeleteVideo)
-		protectedProjects.POST("/:id/technologies", handlerRegistry.ProjectHandler.AddTechnology)
-		protectedProjects.DELETE("/:id/technologies/:techId", handlerRegistry.ProjectHandler.RemoveTechnology)
+		protectedProjects.POST("", middleware.RequirePermission(auth.PermProjectWrite), handlerRegistry.ProjectHandler.Create)
+		protectedProjects.PUT("/:id", middleware.RequirePermission(auth.PermProjectWrite), handlerRegistry.ProjectHandler.Update)
+		protectedProjects.PATCH("/:id", middleware.RequirePermission(auth.PermProjectWrite), handlerRegistry.ProjectHandler.Patch)
+		protectedProjects.DELETE("/:id", middleware.RequirePermission(auth.PermProjectDelete), handlerRegistry.ProjectHandler.Delete)
+		protectedProjects.POST("/:id/images", middleware.RequirePermission(auth.PermProjectWrite), handlerRegistry.ProjectHandler.AddImage)
+		protectedProjects.POST("/:id/videos", middleware.RequirePermission(auth.PermProjectWrite), handlerRegistry.ProjectHandler.AddVideo)
+		protectedProjects.DELETE("/:id/images/:imageId", middleware.RequirePermission(auth.PermProjectWrite), handlerRegistry.ProjectHandler.DeleteImage)
+		protectedProjects.DELETE("/:id/videos/:videoId", middleware.RequirePermission(auth.PermProjectWrite), handlerRegistry.ProjectHandler.DeleteVideo)
+		protectedProjects.POST("/:id/technologies", middleware.RequirePermission(auth.PermProjectWrite), handlerRegistry.ProjectHandler.AddTechnology)
+		protectedProjects.DELETE("/:id/technologies/:techId", middleware.RequirePermission(auth.PermProjectWrite), handlerRegistry.ProjectHandler.RemoveTechnology)
+		protectedProjects.GET("/:id/revisions", middleware.RequirePermission(auth.PermProjectWrite), handlerRegistry.ProjectRevisionHandler.GetAll)
+		protectedProjects.GET("/:id/revisions/:revisionId", middleware.RequirePermission(auth.PermProjectWrite), handlerRegistry.ProjectRevisionHandler.GetByID)
+		protectedProjects.GET("/:id/revisions/:revisionId/diff", middleware.RequirePermission(auth.PermProjectWrite), handlerRegistry.ProjectRevisionHandler.Diff)
+		protectedProjects.POST("/:id/revisions/:revisionId/restore", middleware.RequirePermission(auth.PermProjectWrite), handlerRegistry.ProjectRevisionHandler.Restore)
 	}
 
 	// Protected experience routes
 	protectedExperiences := protected.Group("/experiences")
 	{
-		protectedExperiences.POST("", handlerRegistry.ExperienceHandler.Create)
-		protectedExperiences.PUT("/:id", handlerRegistry.ExperienceHandler.Update)
-		protectedExperiences.PATCH("/:id", handlerRegistry.ExperienceHandler.Patch)
-		protectedExperiences.DELETE("/:id", handlerRegistry.ExperienceHandler.Delete)
+		protectedExperiences.POST("", middleware.RequirePermission(auth.PermExperienceWrite), handlerRegistry.ExperienceHandler.Create)
+		protectedExperiences.PUT("/:id", middleware.RequirePermission(auth.PermExperienceWrite), handlerRegistry.ExperienceHandler.Update)
+		protectedExperiences.PATCH("/:id", middleware.RequirePermission(auth.PermExperienceWrite), handlerRegistry.ExperienceHandler.Patch)
+		protectedExperiences.DELETE("/:id", middleware.RequirePermission(auth.PermExperienceDelete), handlerRegistry.ExperienceHandler.Delete)
 	}
 
 	// Tags management
 	tags := protected.Group("/tags")
 	{
 		tags.GET("", handlerRegistry.TagHandler.GetAll)
-		tags.POST("", handlerRegistry.TagHandler.Create)
-		tags.PUT("/:id", handlerRegistry.TagHandler.Update)
-		tags.DELETE("/:id", handlerRegistry.TagHandler.Delete)
+		tags.POST("", middleware.RequirePermission(auth.PermTagWrite), handlerRegistry.TagHandler.Create)
+		tags.PUT("/:id", middleware.RequirePermission(auth.PermTagWrite), handlerRegistry.TagHandler.Update)
+		tags.DELETE("/:id", middleware.RequirePermission(auth.PermTagWrite), handlerRegistry.TagHandler.Delete)
 	}
 
 	// Media upload routes
 	if handlerRegistry.MediaHandler != nil {
-		// Public: list media
+		// Public: list media; CMS users also see private media
 		media := v1.Group("/media")
+		media.Use(middleware.OptionalJWTAuth(authService))
 		{
 			media.GET("", handlerRegistry.MediaHandler.GetAll)
+			media.GET("/:id", handlerRegistry.MediaHandler.Get)
 		}
 
-		// Protected: upload and delete
+		// Protected: upload, library metadata, folders and delete
 		protectedMedia := protected.Group("/media")
 		{
-			protectedMedia.POST("/upload", handlerRegistry.MediaHandler.Upload)
-			protectedMedia.DELETE("/:id", handlerRegistry.MediaHandler.Delete)
+			protectedMedia.POST("/upload", middleware.RequirePermission(auth.PermMediaUpload), handlerRegistry.MediaHandler.Upload)
+
+			// Resumable uploads: create, send chunks, complete
+			protectedMedia.POST("/uploads", middleware.RequirePermission(auth.PermMediaUpload), handlerRegistry.MediaHandler.CreateUpload)
+			protectedMedia.GET("/uploads/:id", middleware.RequirePermission(auth.PermMediaUpload), handlerRegistry.MediaHandler.GetUpload)
+			protectedMedia.PUT("/uploads/:id/chunks/:index", middleware.RequirePermission(auth.PermMediaUpload), handlerRegistry.MediaHandler.PutChunk)
+			protectedMedia.POST("/uploads/:id/complete", middleware.RequirePermission(auth.PermMediaUpload), handlerRegistry.MediaHandler.CompleteUpload)
+			protectedMedia.DELETE("/uploads/:id", middleware.RequirePermission(auth.PermMediaUpload), handlerRegistry.MediaHandler.AbortUpload)
+
+			protectedMedia.PATCH("/:id", middleware.RequirePermission(auth.PermMediaUpload), handlerRegistry.MediaHandler.Update)
+			protectedMedia.GET("/:id/usage", middleware.RequirePermission(auth.PermMediaUpload), handlerRegistry.MediaHandler.Usage)
+			protectedMedia.GET("/:id/url", middleware.RequirePermission(auth.PermMediaUpload), handlerRegistry.MediaHandler.SignedURL)
+			protectedMedia.DELETE("/:id", middleware.RequirePermission(auth.PermMediaDelete), handlerRegistry.MediaHandler.Delete)
+
+			protectedMedia.GET("/folders", middleware.RequirePermission(auth.PermMediaUpload), handlerRegistry.MediaHandler.GetFolders)
+			protectedMedia.POST("/folders", middleware.RequirePermission(auth.PermMediaUpload), handlerRegistry.MediaHandler.CreateFolder)
+			protectedMedia.PUT("/folders/:id", middleware.RequirePermission(auth.PermMediaUpload), handlerRegistry.MediaHandler.UpdateFolder)
+			protectedMedia.DELETE("/folders/:id", middleware.RequirePermission(auth.PermMediaDelete), handlerRegistry.MediaHandler.DeleteFolder)
+
+			// Reconciliation report; POST also cleans up
+			protectedMedia.GET("/reconcile", middleware.RequirePermission(auth.PermMediaDelete), handlerRegistry.MediaHandler.Reconcile)
+			protectedMedia.POST("/reconcile", middleware.RequirePermission(auth.PermMediaDelete), handlerRegistry.MediaHandler.Reconcile)
+
+			// Upload quotas: own usage, and every user's for user managers
+			protectedMedia.GET("/quota", handlerRegistry.MediaHandler.Quota)
+			protectedMedia.GET("/quota/users", middleware.RequirePermission(auth.PermUsersManage), handlerRegistry.MediaHandler.ListQuotas)
+			protectedMedia.PUT("/quota/users/:id", middleware.RequirePermission(auth.PermUsersManage), handlerRegistry.MediaHandler.SetQuota)
 		}
 	}
 }
@@ -0,0 +1,22 @@
+package routes
+
+import (
+	"web-porto-backend/internal/handlers"
+	"web-porto-backend/internal/handlers/feed"
+
+	"github.com/gin-gonic/gin"
+)
+
+// setupFeedRoutes registers the RSS, Atom and JSON feeds of published
+// articles, overall and per category or tag
+func setupFeedRoutes(router *gin.Engine, handlerRegistry *handlers.HandlerRegistry) {
+	h := handlerRegistry.FeedHandler
+	feeds := router.Group("/feeds")
+
+	// The format doubles as the file extension
+	for _, format := range []string{feed.FormatRSS, feed.FormatAtom, feed.FormatJSON} {
+		feeds.GET("/articles."+format, h.Articles(format, ""))
+		feeds.GET("/categories/:slug/articles."+format, h.Articles(format, "category"))
+		feeds.GET("/tags/:slug/articles."+format, h.Articles(format, "tag"))
+	}
+}
@@ -29,6 +29,12 @@ func SetupRoutes(router *gin.Engine, handlerRegistry *handlers.HandlerRegistry,
 		})
 	})
 
+	// Public article feeds
+	setupFeedRoutes(router, handlerRegistry)
+
+	// Sitemap and robots.txt for search engines
+	setupSitemapRoutes(router, handlerRegistry)
+
 	// Setup API routes using the API package
 	api.SetupAPIRoutes(router, handlerRegistry, authService)
 }
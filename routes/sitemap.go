@@ -0,0 +1,16 @@
+package routes
+
+import (
+	"web-porto-backend/internal/handlers"
+
+	"github.com/gin-gonic/gin"
+)
+
+// setupSitemapRoutes registers /sitemap.xml, the files of a split sitemap and
+// /robots.txt
+func setupSitemapRoutes(router *gin.Engine, handlerRegistry *handlers.HandlerRegistry) {
+	h := handlerRegistry.SitemapHandler
+	router.GET("/sitemap.xml", h.Sitemap)
+	router.GET("/sitemaps/:file", h.File)
+	router.GET("/robots.txt", h.Robots)
+}
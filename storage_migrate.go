@@ -0,0 +1,62 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+	mediaSrvc "web-porto-backend/internal/services/media"
+)
+
+// runStorageMigration implements the migrate-storage command, which moves
+// media into another storage backend:
+//
+//	go run . migrate-storage --to s3 [--dry-run] [--delete-source] [--batch 50]
+//
+// It returns the process exit code.
+func runStorageMigration(mediaService mediaSrvc.Service, args []string) int {
+	fs := flag.NewFlagSet("migrate-storage", flag.ContinueOnError)
+	to := fs.String("to", "", "target storage backend (local or s3)")
+	dryRun := fs.Bool("dry-run", false, "check the source files and report what would be moved")
+	deleteSource := fs.Bool("delete-source", false, "delete files from the old backend once moved")
+	batch := fs.Int("batch", 50, "media rows loaded per batch")
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+	if *to == "" {
+		fmt.Fprintln(os.Stderr, "migrate-storage: --to is required")
+		fs.Usage()
+		return 2
+	}
+
+	// Stop between media items on Ctrl-C; finished items stay migrated
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	result, err := mediaService.Migrate(ctx, mediaSrvc.MigrateOptions{
+		To:           *to,
+		DryRun:       *dryRun,
+		DeleteSource: *deleteSource,
+		BatchSize:    *batch,
+	})
+	if result != nil {
+		verb := "moved"
+		if *dryRun {
+			verb = "to move"
+		}
+		fmt.Printf("%d %s (%d bytes), %d skipped, %d failed\n", result.Moved, verb, result.Bytes, result.Skipped, result.Failed)
+		for _, e := range result.Errors {
+			fmt.Println("  " + e)
+		}
+	}
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "migrate-storage:", err)
+		return 1
+	}
+	if result.Failed > 0 {
+		return 1
+	}
+	return 0
+}